@@ -0,0 +1,279 @@
+// Command bench measures simulation throughput along the training hot
+// paths, from bare physics to the full trainer, and records the results in
+// the metrics database with the git hash so regressions show up across
+// commits
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
+)
+
+// Check the clock every this many steps so timing stays off the hot path
+const clockInterval = 256
+
+// benchmark is one hot path, built fresh for each run
+type benchmark struct {
+	name  string
+	setup func(physics env.Config, scratch string) (step func(), cleanup func(), err error)
+}
+
+func main() {
+	durationFlag := flag.Duration("duration", 2*time.Second, "Wall-clock time to run each benchmark")
+	presetFlag := flag.String("preset", env.ClassicPreset, "Pendulum physics preset: "+strings.Join(env.PresetNames(), ", "))
+	dbFlag := flag.String("db", filepath.Join("data", "metrics.db"), "Metrics database to record results in (empty to skip)")
+	batchFlag := flag.Bool("batch-metrics", false, "Buffer metric rows and write them in batches in the metrics benchmark")
+	budgetFlag := flag.Duration("budget", 0, "Also time every pass of the control loop and fail unless its p99 latency fits this budget, e.g. 1ms (16.7ms is one frame at 60Hz; 0 to skip)")
+	onlyFlag := flag.String("only", "", "Comma-separated benchmarks to run: physics, network, metrics, trainer (default: all)")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Bench] ", log.LstdFlags)
+
+	physics, err := env.Preset(*presetFlag)
+	if err != nil {
+		logger.Fatalf("Invalid -preset: %v", err)
+	}
+	if *durationFlag <= 0 {
+		logger.Fatalf("-duration must be positive, got %v", *durationFlag)
+	}
+
+	selected := map[string]bool{}
+	for _, name := range strings.Split(*onlyFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[name] = true
+		}
+	}
+
+	// Metrics written by the benchmarks themselves go to a scratch database
+	scratch, err := os.MkdirTemp("", "pendulum-bench")
+	if err != nil {
+		logger.Fatalf("Failed to create scratch directory: %v", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	gitHash := metrics.GitHash()
+	var results []metrics.Benchmark
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(table, "benchmark\tsteps\tseconds\tsteps/sec\tµs/step\t\n")
+	for _, b := range benchmarks(*batchFlag) {
+		if len(selected) > 0 && !selected[b.name] {
+			continue
+		}
+		step, cleanup, err := b.setup(physics, scratch)
+		if err != nil {
+			logger.Fatalf("Failed to set up %s: %v", b.name, err)
+		}
+		steps, elapsed := run(step, *durationFlag)
+		cleanup()
+
+		seconds := elapsed.Seconds()
+		result := metrics.Benchmark{
+			GitHash:     gitHash,
+			Name:        b.name,
+			Steps:       steps,
+			Seconds:     seconds,
+			StepsPerSec: float64(steps) / seconds,
+		}
+		results = append(results, result)
+		fmt.Fprintf(table, "%s\t%d\t%.2f\t%.0f\t%.2f\t\n",
+			b.name, steps, seconds, result.StepsPerSec, 1e6*seconds/float64(steps))
+	}
+	table.Flush()
+
+	if len(results) == 0 {
+		logger.Fatalf("No benchmarks matched -only %q", *onlyFlag)
+	}
+	if *dbFlag != "" {
+		db, err := metrics.NewDB(*dbFlag)
+		if err != nil {
+			logger.Fatalf("Failed to open metrics database: %v", err)
+		}
+		for _, result := range results {
+			if err := db.RecordBenchmark(result); err != nil {
+				logger.Fatalf("Failed to record %s: %v", result.Name, err)
+			}
+		}
+		db.Close()
+		fmt.Printf("Recorded %d results for commit %q in %s\n", len(results), gitHash, *dbFlag)
+	}
+
+	if *budgetFlag > 0 && !checkBudget(physics, *durationFlag, *budgetFlag) {
+		os.Exit(1)
+	}
+}
+
+// run calls step until the duration has passed, returning how many steps ran
+func run(step func(), duration time.Duration) (int, time.Duration) {
+	start := time.Now()
+	steps := 0
+	for {
+		for i := 0; i < clockInterval; i++ {
+			step()
+		}
+		steps += clockInterval
+		if elapsed := time.Since(start); elapsed >= duration {
+			return steps, elapsed
+		}
+	}
+}
+
+// checkBudget runs the control loop, the network choosing each force and
+// the pendulum applying it, timing every forward pass, step and pass of the
+// loop. It prints their latencies and reports whether the loop's p99 fits
+// the budget
+func checkBudget(physics env.Config, duration, budget time.Duration) bool {
+	forward, step, loop := metrics.NewLatencyHistogram(), metrics.NewLatencyHistogram(), metrics.NewLatencyHistogram()
+	episode := newEpisode(physics)
+	episode.pendulum.SetLatencyRecorder(step)
+	network := quietNetwork()
+	network.SetLatencyRecorder(forward)
+	run(func() {
+		start := time.Now()
+		episode.advance(network.Forward(episode.state))
+		loop.Time(start)
+	}, duration)
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(table, "\ncall\tcalls\tmean\tp50\tp99\tmax\t\n")
+	for _, h := range []struct {
+		name string
+		h    *metrics.LatencyHistogram
+	}{{"forward", forward}, {"step", step}, {"control loop", loop}} {
+		s := h.h.Summary()
+		fmt.Fprintf(table, "%s\t%d\t%v\t%v\t%v\t%v\t\n", h.name, s.Count, s.Mean, s.P50, s.P99, s.Max)
+	}
+	table.Flush()
+
+	p99 := loop.Summary().P99
+	if p99 > budget {
+		fmt.Printf("FAIL: control loop p99 %v exceeds the %v budget\n", p99, budget)
+		return false
+	}
+	fmt.Printf("OK: control loop p99 %v fits the %v budget (%.1f%%)\n", p99, budget, 100*float64(p99)/float64(budget))
+	return true
+}
+
+// benchmarks lists the hot paths from cheapest to most expensive: bare
+// physics, a network choosing forces, the network's per-step metrics and
+// TD updates, and the full trainer
+func benchmarks(batchMetrics bool) []benchmark {
+	return []benchmark{
+		{"physics", func(physics env.Config, _ string) (func(), func(), error) {
+			episode := newEpisode(physics)
+			return func() { episode.advance(0) }, func() {}, nil
+		}},
+		{"network", func(physics env.Config, _ string) (func(), func(), error) {
+			episode := newEpisode(physics)
+			network := quietNetwork()
+			return func() { episode.advance(network.Forward(episode.state)) }, func() {}, nil
+		}},
+		{"metrics", func(physics env.Config, scratch string) (func(), func(), error) {
+			logger, err := metrics.NewLogger(filepath.Join(scratch, "metrics.db"), false, log.New(io.Discard, "", 0))
+			if err != nil {
+				return nil, nil, err
+			}
+			if batchMetrics {
+				logger.EnableBatching(metrics.NewDefaultBatchConfig())
+			}
+			episode := newEpisode(physics)
+			network := quietNetwork()
+			network.SetMetricsLogger(logger)
+			step := func() {
+				logger.IncrementStep()
+				state := episode.state
+				force := network.Forward(state)
+				next, _ := episode.advance(force)
+				network.UpdateTD(reward(next), next, false)
+			}
+			return step, func() { logger.Close() }, nil
+		}},
+		{"trainer", func(physics env.Config, scratch string) (func(), func(), error) {
+			config := training.NewDefaultConfig()
+			config.DeltaTime = physics.DeltaTime
+			trainer := training.NewTrainer(config, quietNetwork(), log.New(io.Discard, "", 0))
+			trainer.SetCheckpointDirectory(filepath.Join(scratch, "checkpoints"))
+			episode := newEpisode(physics)
+			ticks := 0
+			step := func() {
+				state := episode.state
+				force := trainer.Act(state)
+				next, done := episode.advance(force)
+				trainer.Observe(training.Experience{
+					State:     state,
+					Action:    force,
+					Reward:    reward(next),
+					NextState: next,
+					Done:      done,
+					TimeStep:  uint64(ticks),
+				})
+				ticks++
+				if done {
+					trainer.OnEpisodeEnd(ticks)
+					ticks = 0
+				}
+			}
+			return step, func() {}, nil
+		}},
+	}
+}
+
+// episode runs pendulum episodes back to back, starting a fresh one when
+// the last ends
+type episode struct {
+	pendulum *env.Pendulum
+	state    env.State
+}
+
+// newEpisode starts an episode near upright, where controllers act longest
+func newEpisode(physics env.Config) *episode {
+	physics.Termination.MaxSteps = 1000
+	e := &episode{pendulum: env.NewPendulum(physics, log.New(io.Discard, "", 0))}
+	e.reset()
+	return e
+}
+
+// reset restarts the episode slightly off upright
+func (e *episode) reset() {
+	e.pendulum.Reset(env.State{AngleRadians: 0.1})
+	e.state = e.pendulum.GetState()
+}
+
+// advance applies a force, starting a new episode when this one ends
+func (e *episode) advance(force float64) (env.State, bool) {
+	next, done, err := e.pendulum.Advance(force)
+	if err != nil {
+		log.Fatalf("Step failed: %v", err)
+	}
+	if done {
+		e.reset()
+		return next, true
+	}
+	e.state = next
+	return next, false
+}
+
+// quietNetwork returns a network that does not log to the console
+func quietNetwork() *neural.Network {
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+	return network
+}
+
+// reward scores a state like the evaluation harness, 1 upright and 0 hanging
+func reward(s env.State) float64 {
+	return 1.0 - eval.Deviation(s.AngleRadians)/math.Pi
+}
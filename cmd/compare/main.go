@@ -0,0 +1,275 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/control"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/scenario"
+)
+
+// entry pairs a checkpoint with its evaluation result
+type entry struct {
+	path       string
+	controller eval.Controller
+	result     eval.Result
+	cv         eval.CrossValidation // Set with -randomize
+}
+
+func main() {
+	suiteFlag := flag.String("suite", "standard", "Evaluation suite: standard or robustness")
+	dbFlag := flag.String("db", filepath.Join("data", "metrics.db"), "Metrics database to record results in (empty to skip)")
+	plannerFlag := flag.String("planner", "", "Also evaluate a model-based planner as a baseline: random or cem (empty to skip)")
+	presetFlag := flag.String("preset", env.ClassicPreset, "Pendulum physics preset to evaluate on: "+strings.Join(env.PresetNames(), ", "))
+	randomizeFlag := flag.Int("randomize", 0, "Also cross-validate over this many random physics draws around the preset (masses and length ±30%, gravity ±10%) and rank by worst case")
+	scenariosFlag := flag.String("scenarios", "", "Evaluate on these comma-separated scenario files (.json or .yaml) instead of -suite, on their own presets, and report the expectations each checkpoint passes")
+	physicsSeedFlag := flag.Int64("physics-seed", 1, "Seed of the -randomize physics draws, shared by every checkpoint")
+	soakFlag := flag.Duration("soak", 0, "Also run each checkpoint from near upright for this much simulated time, e.g. 6h, and report numerical drift, track creep and degradation (0 to skip)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [checkpoint.json ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Compare] ", log.LstdFlags)
+
+	if flag.NArg() == 0 && *plannerFlag == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	physics, err := env.Preset(*presetFlag)
+	if err != nil {
+		logger.Fatalf("Invalid -preset: %v", err)
+	}
+	var suite eval.Suite
+	var scenarios []scenario.File
+	if *scenariosFlag != "" {
+		suite, scenarios, err = scenario.LoadSuite("scenarios", strings.Split(*scenariosFlag, ","))
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		// The planner models the first file's physics
+		physics = suite.Scenarios[0].Config
+	} else {
+		var ok bool
+		if suite, ok = eval.Suites()[*suiteFlag]; !ok {
+			logger.Fatalf("Unknown suite %q", *suiteFlag)
+		}
+		suite = suite.WithPhysics(physics)
+	}
+
+	// Evaluate every checkpoint on the same seeded suite
+	entries := make([]entry, 0, flag.NArg())
+	quiet := log.New(io.Discard, "", 0)
+	for _, path := range flag.Args() {
+		network := neural.NewNetwork()
+		network.SetLogger(quiet)
+		network.SetEvalMode(true)
+		if err := network.LoadFromFile(path); err != nil {
+			logger.Fatalf("Failed to load %s: %v", path, err)
+		}
+		if preset := network.GetPreset(); preset != "" && preset != *presetFlag {
+			logger.Printf("%s was trained on the %q preset, evaluating on %q", path, preset, *presetFlag)
+		}
+		entries = append(entries, entry{path: path, controller: network, result: eval.Run(suite, network)})
+	}
+	if *plannerFlag != "" {
+		config := control.NewDefaultPlannerConfig()
+		config.Method = *plannerFlag
+		planner, err := control.NewPlanner(physics, config)
+		if err != nil {
+			logger.Fatalf("Failed to create planner: %v", err)
+		}
+		entries = append(entries, entry{path: "planner (" + config.Method + ")", controller: planner, result: eval.Run(suite, planner)})
+	}
+
+	// Rank by success rate, then average reward
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].result, entries[j].result
+		if a.SuccessRate != b.SuccessRate {
+			return a.SuccessRate > b.SuccessRate
+		}
+		return a.AvgReward > b.AvgReward
+	})
+
+	printTable(suite, entries)
+	if scenarios != nil {
+		printChallenges(scenarios, entries)
+	}
+	
+	if *soakFlag > 0 {
+		config := eval.NewSoakConfig(physics, soakFlag.Seconds())
+		for _, e := range entries {
+			printSoak(e.path, config, eval.Soak(config, e.controller))
+		}
+	}
+	
+	if *randomizeFlag > 0 {
+		draws := eval.RandomPhysics(physics, eval.NewDefaultPhysicsSpread(), *randomizeFlag, *physicsSeedFlag)
+		for i := range entries {
+			entries[i].cv = eval.CrossValidate(suite, entries[i].controller, draws)
+		}
+		printCrossValidation(suite, draws, entries)
+	}
+
+	if *dbFlag != "" {
+		sessionID, err := recordResults(*dbFlag, suite, entries)
+		if err != nil {
+			logger.Fatalf("Failed to record results: %v", err)
+		}
+		fmt.Printf("\nResults recorded in %s (session %s)\n", *dbFlag, sessionID)
+	}
+}
+
+// printTable prints the ranked comparison of all checkpoints
+func printTable(suite eval.Suite, entries []entry) {
+	fmt.Printf("\n=== CHECKPOINT COMPARISON (%s suite, %d scenarios) ===\n", suite.Name, len(suite.Scenarios))
+	fmt.Printf("%-4s %-40s %8s %8s %8s %8s %10s %9s\n",
+		"Rank", "Checkpoint", "Success", "Reward", "P10", "P90", "Balance(s)", "MaxAngle")
+	fmt.Println(strings.Repeat("-", 102))
+	for i, e := range entries {
+		r := e.result
+		fmt.Printf("%-4d %-40s %7.1f%% %8.4f %8.4f %8.4f %10.2f %8.1f°\n",
+			i+1, shorten(e.path, 40), r.SuccessRate*100, r.AvgReward,
+			r.Reward.P10, r.Reward.P90, r.AvgBalanceTime, r.MaxAngle*180/math.Pi)
+	}
+}
+
+// printChallenges reports, for each scenario file, whether each checkpoint
+// met its expectations and which it missed
+func printChallenges(scenarios []scenario.File, entries []entry) {
+	fmt.Printf("\n=== SCENARIO CHALLENGES (%d files) ===\n", len(scenarios))
+	for _, f := range scenarios {
+		fmt.Printf("%s: %s\n", f.Name, f.Description)
+		for _, e := range entries {
+			_, failures, err := f.Run(e.controller)
+			verdict := "PASS"
+			switch {
+			case err != nil:
+				verdict = "ERROR " + err.Error()
+			case len(failures) > 0:
+				verdict = "FAIL  " + strings.Join(failures, "; ")
+			}
+			fmt.Printf("  %-40s %s\n", shorten(e.path, 40), verdict)
+		}
+	}
+}
+
+// printCrossValidation ranks the checkpoints by their worst case over the
+// random physics draws, next to their score on the preset itself
+func printCrossValidation(suite eval.Suite, draws []env.Config, entries []entry) {
+	ranked := append([]entry(nil), entries...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].cv.WorstReward > ranked[j].cv.WorstReward
+	})
+
+	fmt.Printf("\n=== CROSS-VALIDATION (%s suite over %d random physics) ===\n", suite.Name, len(draws))
+	fmt.Printf("%-4s %-40s %8s %8s %8s %8s %9s %9s\n",
+		"Rank", "Checkpoint", "Preset", "Mean", "Std", "Worst", "Success", "Worst Succ")
+	fmt.Println(strings.Repeat("-", 102))
+	for i, e := range ranked {
+		cv := e.cv
+		fmt.Printf("%-4d %-40s %8.4f %8.4f %8.4f %8.4f %8.1f%% %8.1f%%\n",
+			i+1, shorten(e.path, 40), e.result.AvgReward, cv.MeanReward, cv.StdReward,
+			cv.WorstReward, cv.MeanSuccessRate*100, cv.WorstSuccessRate*100)
+	}
+	for _, e := range ranked {
+		worst := e.cv.Folds[e.cv.Worst].Physics
+		fmt.Printf("%s is weakest with cart %.2fkg, pole %.2fkg, length %.2fm, gravity %.2fm/s²\n",
+			shorten(e.path, 40), worst.CartMass, worst.PendulumMass, worst.Length, worst.Gravity)
+	}
+}
+
+// printSoak prints a checkpoint's soak test: whether it lasted, its
+// worst-case excursions and how its balance trended over the windows
+func printSoak(path string, config eval.SoakConfig, r eval.SoakReport) {
+	fmt.Printf("\n=== SOAK TEST %s (%s simulated, %d steps) ===\n", shorten(path, 40),
+		time.Duration(float64(config.Steps)*config.Config.DeltaTime*float64(time.Second)), config.Steps)
+	if r.Failure != "" {
+		fmt.Printf("FAILED: %s at step %d, after %s\n", r.Failure, r.FailureStep, time.Duration(r.SimulatedTime*float64(time.Second)))
+	} else {
+		fmt.Printf("Completed all %d steps\n", r.Steps)
+	}
+
+	at := func(e eval.Excursion) string {
+		return time.Duration(float64(e.Step) * config.Config.DeltaTime * float64(time.Second)).Round(time.Millisecond).String()
+	}
+	fmt.Printf("Worst angle from upright:  %8.2f°    at %s\n", r.MaxDeviation.Value*180/math.Pi, at(r.MaxDeviation))
+	fmt.Printf("Worst cart offset:         %8.3f m   at %s\n", r.MaxOffset.Value, at(r.MaxOffset))
+	fmt.Printf("Worst cart velocity:       %8.3f m/s at %s\n", r.MaxCartVelocity.Value, at(r.MaxCartVelocity))
+	fmt.Printf("Worst angular velocity:    %8.3f rad/s at %s\n", r.MaxAngularVel.Value, at(r.MaxAngularVel))
+	if r.EnergyChecked {
+		fmt.Printf("Worst energy error:        %8.5f J   at %s\n", r.MaxEnergyError.Value, at(r.MaxEnergyError))
+	}
+	fmt.Printf("Steps beyond %.0f°:         %8d (%.2f%%)\n", config.SuccessAngle*180/math.Pi, r.WobbleSteps,
+		100*float64(r.WobbleSteps)/float64(max(1, r.Steps)))
+	fmt.Printf("Angle trend:               %+8.4f° per hour\n", r.DeviationTrend*180/math.Pi)
+	fmt.Printf("Cart creep:                %+8.4f m per hour\n", r.CreepTrend)
+
+	if len(r.Windows) > 1 {
+		first, last := r.Windows[0], r.Windows[len(r.Windows)-1]
+		fmt.Printf("First minute: mean angle %.3f°, max %.3f°, mean cart %+.3f m\n",
+			first.MeanDeviation*180/math.Pi, first.MaxDeviation*180/math.Pi, first.MeanPosition)
+		fmt.Printf("Last minute:  mean angle %.3f°, max %.3f°, mean cart %+.3f m\n",
+			last.MeanDeviation*180/math.Pi, last.MaxDeviation*180/math.Pi, last.MeanPosition)
+	}
+}
+
+// recordResults stores every evaluation under a new comparison session
+func recordResults(dbPath string, suite eval.Suite, entries []entry) (string, error) {
+	db, err := metrics.NewDB(dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	sessionID := fmt.Sprintf("compare_%s", time.Now().Format("20060102_150405"))
+	if err := db.StartSession(metrics.SessionInfo{
+		SessionID:       sessionID,
+		GitHash:         metrics.GitHash(),
+		Hyperparameters: fmt.Sprintf(`{"suite":%q}`, suite.Name),
+	}); err != nil {
+		return "", err
+	}
+
+	for _, e := range entries {
+		r := e.result
+		if err := db.RecordEvaluation(metrics.Evaluation{
+			SessionID:      sessionID,
+			Checkpoint:     e.path,
+			Suite:          suite.Name,
+			AvgReward:      r.AvgReward,
+			AvgBalanceTime: r.AvgBalanceTime,
+			MaxAngle:       r.MaxAngle,
+			SuccessRate:    r.SuccessRate,
+			RewardP10:      r.Reward.P10,
+			RewardP50:      r.Reward.P50,
+			RewardP90:      r.Reward.P90,
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return sessionID, db.EndSession(sessionID)
+}
+
+// shorten trims long paths from the left so the file name stays visible
+func shorten(path string, width int) string {
+	if len(path) <= width {
+		return path
+	}
+	return "..." + path[len(path)-width+3:]
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+)
+
+//go:embed index.html
+var indexHTML string
+
+var indexTemplate = template.Must(template.New("index").Parse(indexHTML))
+
+// dashboard serves the page and the JSON it polls
+type dashboard struct {
+	db      *metrics.DB
+	refresh time.Duration
+	window  int // Episodes in the rolling success rate
+	logger  *log.Logger
+}
+
+// sessionJSON is one entry of the session picker
+type sessionJSON struct {
+	ID      string    `json:"id"`
+	Start   time.Time `json:"start"`
+	Running bool      `json:"running"`
+}
+
+// curvesJSON holds every chart series of one session, indexed by episode
+type curvesJSON struct {
+	Episodes    []int      `json:"episodes"`
+	Reward      []float64  `json:"reward"`
+	SuccessRate []float64  `json:"success_rate"`
+	WeightEps   []int      `json:"weight_episodes"`
+	AngleWeight []float64  `json:"angle_weight"`
+	AngularVel  []float64  `json:"angular_vel_weight"`
+	Bias        []float64  `json:"bias"`
+	TDEps       []int      `json:"td_episodes"`
+	TDError     []float64  `json:"td_error"`
+	CalibEps    []int      `json:"calibration_episodes"`
+	CalibBias   []float64  `json:"calibration_bias"`
+	CalibRMSE   []float64  `json:"calibration_rmse"`
+	CalibRank   []float64  `json:"calibration_rank"`
+	ComponentEps []int     `json:"component_episodes"`
+	AngleReward []float64  `json:"angle_reward"`
+	PositionPen []float64  `json:"position_penalty"`
+	EffortPen   []float64  `json:"effort_penalty"`
+	Budget      budgetJSON `json:"budget"`
+}
+
+// budgetJSON is the compute a session has spent so far
+type budgetJSON struct {
+	WallClock      float64 `json:"wall_clock_seconds"`
+	Steps          int64   `json:"steps"`
+	StepsPerSecond float64 `json:"steps_per_second"`
+	ForwardPasses  int64   `json:"forward_passes"`
+	BackwardPasses int64   `json:"backward_passes"`
+}
+
+// stepJSON is one step of an episode's trace
+type stepJSON struct {
+	Step             int     `json:"step"`
+	Angle            float64 `json:"angle"`
+	AngularVel       float64 `json:"angular_vel"`
+	Force            float64 `json:"force"`
+	StateValue       float64 `json:"state_value"`
+	Reward           float64 `json:"reward"`
+	TDError          float64 `json:"td_error"`
+	AngleUpdate      float64 `json:"angle_update"`
+	AngularVelUpdate float64 `json:"angular_vel_update"`
+	BiasUpdate       float64 `json:"bias_update"`
+	Updated          bool    `json:"updated"`
+}
+
+// metricJSON is one raw metric row of an episode
+type metricJSON struct {
+	Step     int     `json:"step"`
+	Type     string  `json:"type"`
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"`
+	Metadata string  `json:"metadata,omitempty"`
+}
+
+// metricsPageJSON is a page of an episode's raw metric rows. NextOffset is
+// where the next page starts, or -1 after the last
+type metricsPageJSON struct {
+	Rows       []metricJSON `json:"rows"`
+	NextOffset int          `json:"next_offset"`
+}
+
+// Page sizes of the raw metrics endpoint
+const (
+	defaultMetricsLimit = 1000
+	maxMetricsLimit     = 10000
+)
+
+// valuesJSON holds a session's value landscapes on their shared grid, each
+// snapshot row-major with angle varying fastest
+type valuesJSON struct {
+	Angles     []float64   `json:"angles"`
+	Velocities []float64   `json:"angular_vels"`
+	Episodes   []int       `json:"episodes"`
+	Values     [][]float64 `json:"values"`
+}
+
+func main() {
+	addrFlag := flag.String("addr", "localhost:8080", "Address to serve the dashboard on")
+	dbFlag := flag.String("db", filepath.Join("data", "metrics.db"), "Metrics database to read")
+	refreshFlag := flag.Duration("refresh", 5*time.Second, "How often the page re-queries the database")
+	windowFlag := flag.Int("window", 20, "Episodes in the rolling success rate")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Dashboard] ", log.LstdFlags)
+
+	db, err := metrics.NewDB(*dbFlag)
+	if err != nil {
+		logger.Fatalf("Failed to open metrics database: %v", err)
+	}
+	defer db.Close()
+
+	d := &dashboard{db: db, refresh: *refreshFlag, window: *windowFlag, logger: logger}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", d.handleIndex)
+	mux.HandleFunc("GET /api/sessions", d.handleSessions)
+	mux.HandleFunc("GET /api/sessions/{id}", d.handleSession)
+	mux.HandleFunc("GET /api/sessions/{id}/episodes/{episode}/trace", d.handleTrace)
+	mux.HandleFunc("GET /api/sessions/{id}/episodes/{episode}/metrics", d.handleMetrics)
+	mux.HandleFunc("GET /api/sessions/{id}/values", d.handleValues)
+
+	logger.Printf("Serving %s on http://%s", *dbFlag, *addrFlag)
+	if err := http.ListenAndServe(*addrFlag, mux); err != nil {
+		logger.Fatalf("Server error: %v", err)
+	}
+}
+
+func (d *dashboard) handleIndex(w http.ResponseWriter, r *http.Request) {
+	data := struct{ RefreshMillis int64 }{d.refresh.Milliseconds()}
+	if err := indexTemplate.Execute(w, data); err != nil {
+		d.logger.Printf("Failed to render page: %v", err)
+	}
+}
+
+func (d *dashboard) handleSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := d.db.ListSessions()
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+
+	// Newest first, so the picker defaults to the run in progress
+	out := make([]sessionJSON, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- {
+		s := sessions[i]
+		out = append(out, sessionJSON{ID: s.SessionID, Start: s.StartTime, Running: s.EndTime.IsZero()})
+	}
+	d.writeJSON(w, out)
+}
+
+func (d *dashboard) handleSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	info, err := d.db.GetSession(id)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+	episodes, err := d.db.GetEpisodeCurve(id)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+	weights, err := d.db.GetWeightCurve(id)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+	tdErrors, err := d.db.GetMetricCurve(id, "learning", "td_error")
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+	calibrations, err := d.db.GetCalibrations(id)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+	components, err := d.db.GetRewardComponents(id)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+
+	out := curvesJSON{
+		SuccessRate: metrics.RollingSuccessRate(episodes, d.window),
+		Budget: budgetJSON{
+			WallClock:      info.Budget.WallClock.Seconds(),
+			Steps:          info.Budget.Steps,
+			StepsPerSecond: info.Budget.StepsPerSecond(),
+			ForwardPasses:  info.Budget.ForwardPasses,
+			BackwardPasses: info.Budget.BackwardPasses,
+		},
+	}
+	for _, e := range episodes {
+		out.Episodes = append(out.Episodes, e.Episode)
+		out.Reward = append(out.Reward, e.TotalReward)
+	}
+	for _, p := range weights {
+		out.WeightEps = append(out.WeightEps, p.Episode)
+		out.AngleWeight = append(out.AngleWeight, p.AngleWeight)
+		out.AngularVel = append(out.AngularVel, p.AngularVelWeight)
+		out.Bias = append(out.Bias, p.Bias)
+	}
+	for _, p := range tdErrors {
+		out.TDEps = append(out.TDEps, p.Episode)
+		out.TDError = append(out.TDError, p.MeanAbs)
+	}
+	for _, c := range calibrations {
+		out.CalibEps = append(out.CalibEps, c.Episode)
+		out.CalibBias = append(out.CalibBias, c.Bias)
+		out.CalibRMSE = append(out.CalibRMSE, c.RMSE)
+		out.CalibRank = append(out.CalibRank, c.RankCorrelation)
+	}
+	for _, c := range components {
+		out.ComponentEps = append(out.ComponentEps, c.Episode)
+		out.AngleReward = append(out.AngleReward, c.Angle)
+		out.PositionPen = append(out.PositionPen, c.Position)
+		out.EffortPen = append(out.EffortPen, c.Effort)
+	}
+	d.writeJSON(w, out)
+}
+
+func (d *dashboard) handleTrace(w http.ResponseWriter, r *http.Request) {
+	episode, err := strconv.Atoi(r.PathValue("episode"))
+	if err != nil {
+		http.Error(w, "episode must be a number", http.StatusBadRequest)
+		return
+	}
+
+	trace, err := d.db.GetStepTrace(r.PathValue("id"), episode)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+
+	out := make([]stepJSON, 0, len(trace))
+	for _, s := range trace {
+		out = append(out, stepJSON{
+			Step:             s.Step,
+			Angle:            s.Angle,
+			AngularVel:       s.AngularVel,
+			Force:            s.Force,
+			StateValue:       s.StateValue,
+			Reward:           s.Reward,
+			TDError:          s.TDError,
+			AngleUpdate:      s.AngleUpdate,
+			AngularVelUpdate: s.AngularVelUpdate,
+			BiasUpdate:       s.BiasUpdate,
+			Updated:          s.Updated,
+		})
+	}
+	d.writeJSON(w, out)
+}
+
+// handleMetrics pages through an episode's raw metric rows with the offset
+// and limit query parameters, so long episodes never load at once
+func (d *dashboard) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	episode, err := strconv.Atoi(r.PathValue("episode"))
+	if err != nil {
+		http.Error(w, "episode must be a number", http.StatusBadRequest)
+		return
+	}
+	offset, limit := 0, defaultMetricsLimit
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			http.Error(w, "offset must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxMetricsLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxMetricsLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := d.db.GetStepMetrics(r.PathValue("id"), episode, offset, limit)
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+
+	out := metricsPageJSON{Rows: make([]metricJSON, 0, len(page)), NextOffset: -1}
+	for _, row := range page {
+		out.Rows = append(out.Rows, metricJSON{
+			Step:     row.Step,
+			Type:     row.MetricType,
+			Name:     row.MetricName,
+			Value:    row.Value,
+			Metadata: row.Metadata,
+		})
+	}
+	if len(page) == limit {
+		out.NextOffset = offset + limit
+	}
+	d.writeJSON(w, out)
+}
+
+func (d *dashboard) handleValues(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := d.db.GetValueSnapshots(r.PathValue("id"))
+	if err != nil {
+		d.fail(w, err)
+		return
+	}
+
+	out := valuesJSON{}
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			// Every snapshot samples the same grid, so the first one gives its axes
+			seenAngle, seenVel := map[float64]bool{}, map[float64]bool{}
+			for _, p := range snapshot.Points {
+				if !seenAngle[p.Angle] {
+					seenAngle[p.Angle] = true
+					out.Angles = append(out.Angles, p.Angle)
+				}
+				if !seenVel[p.AngularVel] {
+					seenVel[p.AngularVel] = true
+					out.Velocities = append(out.Velocities, p.AngularVel)
+				}
+			}
+		}
+		values := make([]float64, len(snapshot.Points))
+		for j, p := range snapshot.Points {
+			values[j] = p.Value
+		}
+		out.Episodes = append(out.Episodes, snapshot.Episode)
+		out.Values = append(out.Values, values)
+	}
+	d.writeJSON(w, out)
+}
+
+func (d *dashboard) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		d.logger.Printf("Failed to write response: %v", err)
+	}
+}
+
+func (d *dashboard) fail(w http.ResponseWriter, err error) {
+	d.logger.Printf("Query failed: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -5,7 +5,9 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -20,14 +22,52 @@ func main() {
 	episodeFlag := flag.Int("episode", -1, "Episode to analyze (default: latest episode)")
 	lastNEpisodesFlag := flag.Int("last", 10, "Number of recent episodes to analyze")
 	outputFlag := flag.String("output", "console", "Output format (console, json)")
-	analysisTypeFlag := flag.String("type", "all", "Type of analysis (all, learning, weights, predictions, issues)")
+	analysisTypeFlag := flag.String("type", "all", "Type of analysis (all, learning, weights, predictions, issues, trace, steps, generations, lineage, values, failures, difficulty, discount, calibration, rewards, saliency)")
 	verboseFlag := flag.Bool("verbose", false, "Enable verbose output")
+	sessionsFlag := flag.Bool("sessions", false, "List all sessions with their metadata and exit")
+	compareFlag := flag.String("compare", "", "Comma-separated session IDs to compare side by side, then exit")
+	genomeFlag := flag.Int("genome", -1, "Genome to trace with -type lineage (default: best of the latest generation)")
+	pruneFlag := flag.Bool("prune", false, "Down-sample old step metrics, delete abandoned sessions and vacuum the database (-session limits it to one session), then exit")
+	olderThanFlag := flag.Duration("older-than", 7*24*time.Hour, "With -prune, only thin step data recorded longer ago than this")
+	keepEveryFlag := flag.Int("keep-every", 10, "With -prune, keep every Nth step of old step data")
+	offsetFlag := flag.Int("offset", 0, "With -type steps, skip this many of the episode's metric rows")
+	limitFlag := flag.Int("limit", 100, "With -type steps, print at most this many metric rows")
+	reportFlag := flag.String("report", "", "Write a self-contained report of the session to this file, HTML for .html and Markdown otherwise, then exit (-last sets the final statistics window)")
+	networkFlag := flag.String("network", "", "With -type saliency, the network checkpoint to analyze")
+	gridFlag := flag.Int("grid", 9, "With -type saliency, states sampled across each of angle and angular velocity")
+	deadZoneFlag := flag.Float64("dead-zone", 0.1, "With -type saliency, |dForce/dInput| in N per unit below which the force counts as insensitive")
+	gammasFlag := flag.String("gammas", "", "With -type discount, comma-separated discounts to recompute returns under (default: 0,0.5,0.8,0.9,0.95,0.97,0.99,0.995)")
+	heatmapFlag := flag.String("heatmap", "", "With -type saliency, also write <prefix>_saliency_<input>.png heatmaps")
 	
 	flag.Parse()
 	
 	// Create logger for console output
 	logger := log.New(os.Stdout, "[Debug] ", log.LstdFlags)
 	
+	// Saliency analyzes a network checkpoint rather than a recorded session
+	if strings.ToLower(*analysisTypeFlag) == "saliency" {
+		if *networkFlag == "" {
+			logger.Fatalf("-type saliency needs a checkpoint: -network path/to/network.json")
+		}
+		network, report, err := analyzeSaliency(*networkFlag, *gridFlag, *deadZoneFlag)
+		if err != nil {
+			logger.Fatalf("Failed to analyze saliency: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, report)
+		} else {
+			printSaliency(report)
+		}
+		if *heatmapFlag != "" {
+			files, err := writeSaliencyHeatmaps(network, *heatmapFlag)
+			if err != nil {
+				logger.Fatalf("Failed to write saliency heatmaps: %v", err)
+			}
+			logger.Printf("Saliency heatmaps written to %s", strings.Join(files, ", "))
+		}
+		return
+	}
+	
 	// Connect to metrics database
 	db, err := metrics.NewDB(*dbPathFlag)
 	if err != nil {
@@ -35,6 +75,41 @@ func main() {
 	}
 	defer db.Close()
 	
+	if *sessionsFlag {
+		if err := printSessions(db); err != nil {
+			logger.Fatalf("Failed to list sessions: %v", err)
+		}
+		return
+	}
+	
+	if *pruneFlag {
+		if err := prune(db, *dbPathFlag, *sessionIDFlag, *olderThanFlag, *keepEveryFlag); err != nil {
+			logger.Fatalf("Failed to prune database: %v", err)
+		}
+		return
+	}
+	
+	if *compareFlag != "" {
+		sessionIDs := strings.Split(*compareFlag, ",")
+		for i := range sessionIDs {
+			sessionIDs[i] = strings.TrimSpace(sessionIDs[i])
+		}
+		comparison, err := db.CompareSessions(sessionIDs, *lastNEpisodesFlag)
+		if err != nil {
+			logger.Fatalf("Failed to compare sessions: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			jsonData, err := json.MarshalIndent(comparison, "", "  ")
+			if err != nil {
+				logger.Fatalf("Failed to marshal comparison to JSON: %v", err)
+			}
+			fmt.Println(string(jsonData))
+		} else {
+			printComparison(comparison, *verboseFlag)
+		}
+		return
+	}
+	
 	// If no session ID provided, use the latest session
 	sessionID := *sessionIDFlag
 	if sessionID == "" {
@@ -52,16 +127,136 @@ func main() {
 		logger.Printf("Using latest session: %s", sessionID)
 	}
 	
-	// Create metrics logger with the specified session
-	metricsLogger := &metrics.Logger{
-		GetSessionID: func() string { return sessionID },
+	// Attach read-only to the session being analyzed
+	session, err := metrics.OpenSession(db, sessionID)
+	if err != nil {
+		logger.Fatalf("Failed to open session: %v", err)
+	}
+	
+	if *reportFlag != "" {
+		if err := writeReport(session, *reportFlag, *lastNEpisodesFlag); err != nil {
+			logger.Fatalf("Failed to write report: %v", err)
+		}
+		logger.Printf("Report of session %s written to %s", sessionID, *reportFlag)
+		return
+	}
+	
+	// Ensemble sessions record generations instead of episodes
+	switch strings.ToLower(*analysisTypeFlag) {
+	case "generations":
+		generations, err := session.Generations()
+		if err != nil {
+			logger.Fatalf("Failed to get generations: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, generations)
+		} else {
+			printGenerations(generations)
+		}
+		return
+	case "lineage":
+		genome := *genomeFlag
+		if genome < 0 {
+			generations, err := session.Generations()
+			if err != nil {
+				logger.Fatalf("Failed to get generations: %v", err)
+			}
+			if len(generations) == 0 {
+				logger.Fatalf("No generations recorded for session %s", sessionID)
+			}
+			genome = generations[len(generations)-1].BestGenome
+			logger.Printf("Tracing best genome of generation %d: %d", generations[len(generations)-1].Generation, genome)
+		}
+		ancestry, err := session.Ancestry(genome)
+		if err != nil {
+			logger.Fatalf("Failed to trace lineage: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, ancestry)
+		} else {
+			printAncestry(genome, ancestry)
+		}
+		return
+	case "values":
+		snapshots, err := session.ValueSnapshots()
+		if err != nil {
+			logger.Fatalf("Failed to get value snapshots: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, snapshots)
+		} else {
+			printValueSnapshots(snapshots)
+		}
+		return
+	case "failures":
+		summary, err := session.FailureSummary()
+		if err != nil {
+			logger.Fatalf("Failed to summarize failures: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, summary)
+		} else {
+			printFailures(summary)
+		}
+		return
+	case "difficulty":
+		summary, err := session.Difficulty()
+		if err != nil {
+			logger.Fatalf("Failed to summarize difficulty: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, summary)
+		} else {
+			printDifficulty(summary)
+		}
+		return
+	case "calibration":
+		calibrations, err := session.Calibrations()
+		if err != nil {
+			logger.Fatalf("Failed to get value calibrations: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, calibrations)
+		} else {
+			printCalibrations(calibrations)
+		}
+		return
+	case "rewards":
+		components, err := session.RewardComponents()
+		if err != nil {
+			logger.Fatalf("Failed to get reward components: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, components)
+		} else {
+			printRewardComponents(components)
+		}
+		return
+	case "discount":
+		gammas := metrics.DefaultDiscounts
+		if *gammasFlag != "" {
+			var err error
+			if gammas, err = parseGammas(*gammasFlag); err != nil {
+				logger.Fatalf("Invalid -gammas: %v", err)
+			}
+		}
+		sweep, err := session.DiscountSweep(*lastNEpisodesFlag, gammas)
+		if err != nil {
+			logger.Fatalf("Failed to sweep discounts: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, sweep)
+		} else {
+			printDiscountSweep(sweep)
+		}
+		return
 	}
 	
 	// Determine episode to analyze
 	episode := *episodeFlag
 	if episode < 0 {
 		// Get the latest episode for this session
-		latestEpisode, err := getLatestEpisode(db, sessionID)
+		latestEpisode, err := session.LatestEpisode()
 		if err != nil {
 			logger.Fatalf("Failed to get latest episode: %v", err)
 		}
@@ -74,31 +269,57 @@ func main() {
 	
 	switch strings.ToLower(*analysisTypeFlag) {
 	case "all":
-		result = analyzeAll(db, sessionID, episode, *lastNEpisodesFlag, *verboseFlag)
+		result = analyzeAll(session, episode, *lastNEpisodesFlag, *verboseFlag)
 	case "learning":
-		learningProgress, err := db.GetLearningProgress(sessionID, *lastNEpisodesFlag)
+		learningProgress, err := session.LearningProgress(*lastNEpisodesFlag)
 		if err != nil {
 			logger.Fatalf("Failed to analyze learning progress: %v", err)
 		}
 		result = learningProgress
 	case "weights":
-		weightChanges, err := db.GetWeightChangeAnalysis(sessionID, episode)
+		weightChanges, err := session.WeightChanges(episode)
 		if err != nil {
 			logger.Fatalf("Failed to analyze weight changes: %v", err)
 		}
 		result = weightChanges
 	case "predictions":
-		predictionAccuracy, err := db.GetPredictionAccuracy(sessionID, episode)
+		predictionAccuracy, err := session.PredictionAccuracy(episode)
 		if err != nil {
 			logger.Fatalf("Failed to analyze prediction accuracy: %v", err)
 		}
 		result = predictionAccuracy
 	case "issues":
-		learningIssues, err := db.DetectLearningIssues(sessionID)
+		learningIssues, err := session.LearningIssues()
 		if err != nil {
 			logger.Fatalf("Failed to detect learning issues: %v", err)
 		}
 		result = learningIssues
+	case "trace":
+		trace, err := session.StepTrace(episode)
+		if err != nil {
+			logger.Fatalf("Failed to get step trace: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			jsonData, err := json.MarshalIndent(trace, "", "  ")
+			if err != nil {
+				logger.Fatalf("Failed to marshal trace to JSON: %v", err)
+			}
+			fmt.Println(string(jsonData))
+		} else {
+			printTrace(trace)
+		}
+		return
+	case "steps":
+		page, err := session.StepMetrics(episode, *offsetFlag, *limitFlag)
+		if err != nil {
+			logger.Fatalf("Failed to get step metrics: %v", err)
+		}
+		if strings.ToLower(*outputFlag) == "json" {
+			printJSON(logger, page)
+		} else {
+			printStepMetrics(page, *offsetFlag, *limitFlag)
+		}
+		return
 	default:
 		logger.Fatalf("Unknown analysis type: %s", *analysisTypeFlag)
 	}
@@ -118,55 +339,156 @@ func main() {
 	}
 }
 
-// getSessionList returns a list of all session IDs in the database
+// getSessionList returns a list of all session IDs in the database, oldest first
 func getSessionList(db *metrics.DB) ([]string, error) {
-	// This is a simplified implementation - in a real application,
-	// you would query the database for all unique session IDs
-	
-	// For now, we'll use a direct SQL query
-	rows, err := db.DB().Query(`
-		SELECT DISTINCT session_id FROM network_weights
-		ORDER BY id
-	`)
+	sessions, err := db.ListSessions()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query sessions: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 	
-	var sessions []string
-	for rows.Next() {
-		var sessionID string
-		if err := rows.Scan(&sessionID); err != nil {
-			return nil, fmt.Errorf("failed to scan session ID: %w", err)
-		}
-		sessions = append(sessions, sessionID)
+	ids := make([]string, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.SessionID
 	}
 	
-	return sessions, nil
+	return ids, nil
 }
 
-// getLatestEpisode returns the latest episode number for a session
-func getLatestEpisode(db *metrics.DB, sessionID string) (int, error) {
-	// Query the database for the maximum episode number
-	var latestEpisode int
-	err := db.DB().QueryRow(`
-		SELECT MAX(episode) FROM network_weights
-		WHERE session_id = ?
-	`, sessionID).Scan(&latestEpisode)
+// prune thins old step data, deletes abandoned sessions and vacuums the
+// database, reporting how much space was reclaimed
+// writeReport writes a shareable report of the session to path, as HTML
+// when the extension asks for it and as Markdown otherwise
+func writeReport(session *metrics.Session, path string, window int) error {
+	report, err := session.Report(window)
+	if err != nil {
+		return err
+	}
 	
+	var out strings.Builder
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		err = report.WriteHTML(&out)
+	default:
+		err = report.WriteMarkdown(&out)
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to get latest episode: %w", err)
+		return err
+	}
+	return os.WriteFile(path, []byte(out.String()), 0644)
+}
+
+func prune(db *metrics.DB, dbPath, sessionID string, olderThan time.Duration, keepEvery int) error {
+	before, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	
+	stats, err := db.Prune(sessionID, olderThan, keepEvery)
+	if err != nil {
+		return err
+	}
+	if err := db.Vacuum(); err != nil {
+		return err
+	}
+	
+	after, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	
+	fmt.Println("\n=== PRUNE ===")
+	fmt.Printf("Step metrics removed:       %d\n", stats.StepMetrics)
+	fmt.Printf("Step traces removed:        %d\n", stats.StepTraces)
+	fmt.Printf("Abandoned sessions deleted: %d\n", stats.Sessions)
+	fmt.Printf("Database size: %.1f MB -> %.1f MB\n",
+		float64(before.Size())/(1<<20), float64(after.Size())/(1<<20))
+	
+	return nil
+}
+
+// printSessions prints every recorded session with its metadata
+func printSessions(db *metrics.DB) error {
+	sessions, err := db.ListSessions()
+	if err != nil {
+		return err
+	}
+	
+	fmt.Println("\n=== SESSIONS ===")
+	if len(sessions) == 0 {
+		fmt.Println("No training sessions found.")
+		return nil
+	}
+	
+	for _, session := range sessions {
+		end := "running"
+		if !session.EndTime.IsZero() {
+			end = session.EndTime.Format(time.RFC3339)
+		}
+		gitHash := session.GitHash
+		if gitHash == "" {
+			gitHash = "unknown"
+		}
+		fmt.Printf("%s  start=%s  end=%s  git=%s\n",
+			session.SessionID, session.StartTime.Format(time.RFC3339), end, gitHash)
+		if session.Hyperparameters != "" {
+			fmt.Printf("  hyperparameters: %s\n", session.Hyperparameters)
+		}
+	}
+	
+	return nil
+}
+
+// printComparison prints final performance per session and, when verbose,
+// their learning curves side by side
+func printComparison(comparison metrics.SessionComparison, verbose bool) {
+	fmt.Printf("\n=== SESSION COMPARISON (last %d episodes) ===\n", comparison.Window)
+	fmt.Printf("%-32s %8s %12s %10s %12s %14s\n",
+		"Session", "Episodes", "Avg Reward", "Success%", "Avg Balance", "Best Balance")
+	for _, stats := range comparison.Stats {
+		fmt.Printf("%-32s %8d %12.4f %9.1f%% %12.1f %8d (#%d)\n",
+			stats.SessionID, stats.Episodes, stats.FinalAvgReward, stats.FinalSuccessRate*100,
+			stats.FinalBalanceTime, stats.BestBalanceTime, stats.BestEpisode)
+	}
+	
+	if !verbose {
+		return
+	}
+	
+	// Sample the rolling success rate at about 10 points along the longest session
+	longest := 0
+	for _, curve := range comparison.Curves {
+		longest = max(longest, len(curve.SuccessRate))
 	}
+	stride := max(1, longest/10)
 	
-	return latestEpisode, nil
+	fmt.Println("\nRolling Success Rate by Episode:")
+	fmt.Printf("%8s", "Episode")
+	for i := range comparison.Curves {
+		fmt.Printf(" %10s", fmt.Sprintf("#%d", i+1))
+	}
+	fmt.Println()
+	for ep := 0; ep < longest; ep += stride {
+		fmt.Printf("%8d", ep)
+		for _, curve := range comparison.Curves {
+			if ep < len(curve.SuccessRate) {
+				fmt.Printf(" %9.1f%%", curve.SuccessRate[ep]*100)
+			} else {
+				fmt.Printf(" %10s", "-")
+			}
+		}
+		fmt.Println()
+	}
+	for i, curve := range comparison.Curves {
+		fmt.Printf("  #%d = %s\n", i+1, curve.SessionID)
+	}
 }
 
 // analyzeAll performs all available analyses
-func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, verbose bool) map[string]interface{} {
+func analyzeAll(session *metrics.Session, episode, lastNEpisodes int, verbose bool) map[string]interface{} {
 	result := make(map[string]interface{})
 	
 	// Get session summary
-	sessionSummary, err := db.GetSessionSummary(sessionID)
+	sessionSummary, err := session.Summary()
 	if err == nil {
 		result["session_summary"] = sessionSummary
 	} else {
@@ -174,7 +496,7 @@ func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, ve
 	}
 	
 	// Get episode data
-	episodeData, err := db.GetEpisodeData(sessionID, episode)
+	episodeData, err := session.EpisodeData(episode)
 	if err == nil {
 		result["episode_data"] = episodeData
 	} else {
@@ -182,7 +504,7 @@ func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, ve
 	}
 	
 	// Get learning progress
-	learningProgress, err := db.GetLearningProgress(sessionID, lastNEpisodes)
+	learningProgress, err := session.LearningProgress(lastNEpisodes)
 	if err == nil {
 		result["learning_progress"] = learningProgress
 	} else {
@@ -190,7 +512,7 @@ func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, ve
 	}
 	
 	// Get prediction accuracy
-	predictionAccuracy, err := db.GetPredictionAccuracy(sessionID, episode)
+	predictionAccuracy, err := session.PredictionAccuracy(episode)
 	if err == nil {
 		result["prediction_accuracy"] = predictionAccuracy
 	} else {
@@ -198,7 +520,7 @@ func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, ve
 	}
 	
 	// Get weight change analysis
-	weightChanges, err := db.GetWeightChangeAnalysis(sessionID, episode)
+	weightChanges, err := session.WeightChanges(episode)
 	if err == nil {
 		result["weight_changes"] = weightChanges
 	} else {
@@ -206,7 +528,7 @@ func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, ve
 	}
 	
 	// Detect learning issues
-	learningIssues, err := db.DetectLearningIssues(sessionID)
+	learningIssues, err := session.LearningIssues()
 	if err == nil {
 		result["learning_issues"] = learningIssues
 	} else {
@@ -216,6 +538,296 @@ func analyzeAll(db *metrics.DB, sessionID string, episode, lastNEpisodes int, ve
 	return result
 }
 
+// printTrace prints one row per step of an episode's trace
+func printTrace(trace []metrics.StepTrace) {
+	fmt.Printf("\n=== STEP TRACE (%d steps) ===\n", len(trace))
+	fmt.Printf("%6s %8s %8s %8s %8s %8s %8s %10s\n",
+		"Step", "Angle", "AngVel", "Force", "Value", "Reward", "TDError", "Update")
+	for _, s := range trace {
+		update := "-"
+		if s.Updated {
+			update = fmt.Sprintf("%.5f", s.UpdateMagnitude())
+		}
+		fmt.Printf("%6d %8.4f %8.4f %8.4f %8.4f %8.4f %8.4f %10s\n",
+			s.Step, s.Angle, s.AngularVel, s.Force, s.StateValue, s.Reward, s.TDError, update)
+	}
+}
+
+// printStepMetrics prints a page of an episode's raw metric rows and how
+// to fetch the next
+func printStepMetrics(page []metrics.MetricRow, offset, limit int) {
+	fmt.Printf("\n=== STEP METRICS (rows %d-%d) ===\n", offset+1, offset+len(page))
+	fmt.Printf("%6s %-12s %-18s %12s  %s\n", "Step", "Type", "Name", "Value", "Metadata")
+	for _, r := range page {
+		fmt.Printf("%6d %-12s %-18s %12.5f  %s\n", r.Step, r.MetricType, r.MetricName, r.Value, r.Metadata)
+	}
+	if len(page) == limit {
+		fmt.Printf("More rows follow; continue with -offset %d\n", offset+limit)
+	}
+}
+
+// printJSON prints v as indented JSON
+func printJSON(logger *log.Logger, v interface{}) {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		logger.Fatalf("Failed to marshal results to JSON: %v", err)
+	}
+	fmt.Println(string(jsonData))
+}
+
+// printValueSnapshots prints how the value landscape changed between
+// snapshots: its range, mean and the value of the upright state
+func printValueSnapshots(snapshots []metrics.ValueSnapshot) {
+	fmt.Printf("\n=== VALUE SNAPSHOTS (%d) ===\n", len(snapshots))
+	if len(snapshots) == 0 {
+		fmt.Println("No value snapshots recorded; train with -value-snapshots N.")
+		return
+	}
+	
+	fmt.Printf("%8s %8s %10s %10s %10s %10s\n", "Episode", "States", "Min", "Mean", "Max", "Upright")
+	for _, snapshot := range snapshots {
+		lo, hi, sum := math.Inf(1), math.Inf(-1), 0.0
+		upright, closest := 0.0, math.Inf(1)
+		for _, p := range snapshot.Points {
+			lo = math.Min(lo, p.Value)
+			hi = math.Max(hi, p.Value)
+			sum += p.Value
+			if d := math.Hypot(p.Angle, p.AngularVel); d < closest {
+				upright, closest = p.Value, d
+			}
+		}
+		fmt.Printf("%8d %8d %10.4f %10.4f %10.4f %10.4f\n",
+			snapshot.Episode, len(snapshot.Points), lo, sum/float64(len(snapshot.Points)), hi, upright)
+	}
+}
+
+// printFailures prints how often each failure mode ended an episode and
+// whether the pole falling or the cart leaving the track dominates
+func printFailures(summary metrics.FailureSummary) {
+	fmt.Printf("\n=== FAILURE MODES (%d episodes) ===\n", summary.Episodes)
+	if summary.Episodes == 0 {
+		fmt.Println("No episode endings recorded.")
+		return
+	}
+	
+	fmt.Printf("%-22s %8s %8s\n", "Mode", "Episodes", "Share")
+	for _, c := range summary.Counts {
+		fmt.Printf("%-22s %8d %7.1f%%\n", c.Mode, c.Count, 100*c.Share)
+	}
+	fmt.Printf("\nAngular: %d  Positional: %d\n", summary.Angular, summary.Positional)
+	if summary.Dominant != "" {
+		fmt.Printf("Dominant failure: %s\n", summary.Dominant)
+	}
+}
+
+// printDifficulty prints the range of a session's difficulty and a bar
+// chart of it over the episodes, sampled down to at most 20 rows
+func printDifficulty(summary metrics.DifficultySummary) {
+	fmt.Printf("\n=== DIFFICULTY (%d episodes) ===\n", summary.Episodes)
+	if summary.Episodes == 0 {
+		fmt.Println("No difficulty recorded.")
+		return
+	}
+	
+	fmt.Printf("Source: %s\n", summary.Source)
+	fmt.Printf("First: %.3f  Last: %.3f  Min: %.3f  Max: %.3f  Mean: %.3f\n",
+		summary.First, summary.Last, summary.Min, summary.Max, summary.Mean)
+	fmt.Printf("Rises: %d  Falls: %d\n", summary.Rises, summary.Falls)
+	
+	const width, rows = 50, 20
+	fmt.Println("\nDifficulty over episodes:")
+	stride := max(1, (len(summary.Curve)+rows-1)/rows)
+	for i := 0; i < len(summary.Curve); i += stride {
+		p := summary.Curve[i]
+		bar := int(math.Round(math.Max(0, math.Min(1, p.Mean)) * width))
+		fmt.Printf("%8d %6.3f |%s\n", p.Episode, p.Mean, strings.Repeat("#", bar))
+	}
+}
+
+// printCalibrations prints how well each checkpoint's value predictions
+// matched the discounted returns its episodes realized
+func printCalibrations(calibrations []metrics.ValueCalibration) {
+	fmt.Printf("\n=== VALUE CALIBRATION (%d checkpoints) ===\n", len(calibrations))
+	if len(calibrations) == 0 {
+		fmt.Println("No value calibrations recorded.")
+		return
+	}
+	
+	fmt.Printf("%10s %8s %6s %8s %10s %10s %8s\n", "Checkpoint", "Episode", "Gamma", "Steps", "Bias", "RMSE", "Rank")
+	for _, c := range calibrations {
+		fmt.Printf("%10d %8d %6.3f %8d %10.4f %10.4f %8.3f\n",
+			c.Checkpoint, c.Episode, c.Gamma, c.Steps, c.Bias, c.RMSE, c.RankCorrelation)
+	}
+	last := calibrations[len(calibrations)-1]
+	switch {
+	case last.Bias < -last.RMSE/2:
+		fmt.Println("\nThe values underestimate the realized returns")
+	case last.Bias > last.RMSE/2:
+		fmt.Println("\nThe values overestimate the realized returns")
+	}
+	if last.RankCorrelation < 0.5 {
+		fmt.Println("The values rank states poorly by their returns")
+	}
+}
+
+// printRewardComponents prints each reward term's sum per episode,
+// averaged over groups of episodes down to at most 20 rows, and how the
+// terms moved between the first and last tenth of training
+func printRewardComponents(episodes []metrics.RewardComponents) {
+	fmt.Printf("\n=== REWARD COMPONENTS (%d episodes, penalties subtracted) ===\n", len(episodes))
+	if len(episodes) == 0 {
+		fmt.Println("No reward components recorded.")
+		return
+	}
+	
+	const rows = 20
+	fmt.Printf("%8s %8s %10s %10s %10s %10s\n", "Episode", "Steps", "Angle", "Position", "Effort", "Total")
+	stride := max(1, (len(episodes)+rows-1)/rows)
+	for i := 0; i < len(episodes); i += stride {
+		mean := meanComponents(episodes[i:min(i+stride, len(episodes))])
+		fmt.Printf("%8d %8d %10.3f %10.3f %10.3f %10.3f\n",
+			episodes[i].Episode, mean.Steps, mean.Angle, mean.Position, mean.Effort, mean.Total())
+	}
+	
+	// Per-step means, so episodes of different lengths compare
+	tenth := max(1, len(episodes)/10)
+	first, last := perStep(meanComponents(episodes[:tenth])), perStep(meanComponents(episodes[len(episodes)-tenth:]))
+	fmt.Printf("\nPer step, first vs last %d episodes:\n", tenth)
+	fmt.Printf("  Angle:    %8.4f → %8.4f\n", first.Angle, last.Angle)
+	fmt.Printf("  Position: %8.4f → %8.4f\n", first.Position, last.Position)
+	fmt.Printf("  Effort:   %8.4f → %8.4f\n", first.Effort, last.Effort)
+	if last.Angle > first.Angle && last.Effort > first.Effort {
+		fmt.Println("Balance improved at the cost of more control effort")
+	}
+}
+
+// meanComponents averages the reward components of episodes; its Steps is
+// the mean episode length
+func meanComponents(episodes []metrics.RewardComponents) metrics.RewardComponents {
+	var mean metrics.RewardComponents
+	steps := 0
+	for _, e := range episodes {
+		mean.Angle += e.Angle
+		mean.Position += e.Position
+		mean.Effort += e.Effort
+		steps += e.Steps
+	}
+	n := float64(len(episodes))
+	mean.Angle /= n
+	mean.Position /= n
+	mean.Effort /= n
+	mean.Steps = steps / len(episodes)
+	return mean
+}
+
+// perStep divides episode sums by their steps
+func perStep(c metrics.RewardComponents) metrics.RewardComponents {
+	steps := float64(max(1, c.Steps))
+	c.Angle /= steps
+	c.Position /= steps
+	c.Effort /= steps
+	return c
+}
+
+// parseGammas parses a comma-separated list of discounts in [0, 1]
+func parseGammas(list string) ([]float64, error) {
+	var gammas []float64
+	for _, field := range strings.Split(list, ",") {
+		gamma, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, err
+		}
+		if gamma < 0 || gamma > 1 {
+			return nil, fmt.Errorf("discount %g is outside [0, 1]", gamma)
+		}
+		gammas = append(gammas, gamma)
+	}
+	return gammas, nil
+}
+
+// printDiscountSweep prints how well the returns under each discount match
+// the logged value predictions, marking the best match and the discount the
+// TD targets were trained with
+func printDiscountSweep(sweep metrics.DiscountSweep) {
+	fmt.Printf("\n=== DISCOUNT SWEEP (%d episodes, %d steps) ===\n", sweep.Episodes, sweep.Steps)
+	if sweep.Skipped > 0 {
+		fmt.Printf("Skipped %d episodes with unlogged steps; log every step (-metrics-steps all) to include them\n", sweep.Skipped)
+	}
+	if sweep.Steps == 0 {
+		fmt.Println("No complete step traces recorded.")
+		return
+	}
+	
+	const width = 40
+	fmt.Printf("%8s %12s\n", "Gamma", "Correlation")
+	for _, fit := range sweep.Fits {
+		bar := int(math.Round(math.Max(0, fit.Correlation) * width))
+		var marks []string
+		if fit.Gamma == sweep.Best.Gamma {
+			marks = append(marks, "best")
+		}
+		if sweep.Trained > 0 && fit.Gamma == sweep.Trained {
+			marks = append(marks, "trained")
+		}
+		fmt.Printf("%8.3f %12.4f |%-*s| %s\n", fit.Gamma, fit.Correlation, width, strings.Repeat("#", bar), strings.Join(marks, ", "))
+	}
+	
+	fmt.Printf("\nThe value predictions best match returns discounted by γ=%.3f (r=%.3f)\n", sweep.Best.Gamma, sweep.Best.Correlation)
+	if sweep.Trained > 0 && sweep.Best.Gamma != sweep.Trained {
+		fmt.Printf("The TD targets were trained with γ=%.3f: the learned horizon differs from the intended one\n", sweep.Trained)
+	}
+}
+
+// printGenerations prints per-generation fitness statistics with a bar
+// chart of best and mean fitness over the generations
+func printGenerations(generations []metrics.GenerationStats) {
+	fmt.Printf("\n=== GENERATIONS (%d) ===\n", len(generations))
+	if len(generations) == 0 {
+		fmt.Println("No generations recorded.")
+		return
+	}
+	
+	fmt.Printf("%6s %10s %10s %10s %8s %8s %9s %10s\n",
+		"Gen", "Best", "Mean", "Std", "Genome", "Species", "Mutations", "Crossovers")
+	top := 0.0
+	for _, g := range generations {
+		fmt.Printf("%6d %10.2f %10.2f %10.2f %8d %8d %9d %10d\n",
+			g.Generation, g.BestFitness, g.MeanFitness, g.StdFitness, g.BestGenome, g.Species, g.Mutations, g.Crossovers)
+		top = math.Max(top, g.BestFitness)
+	}
+	
+	// '#' up to the mean, '=' from the mean to the best
+	const width = 50
+	fmt.Printf("\nFitness over generations (%s, # mean, = best):\n", generations[0].Fitness)
+	for _, g := range generations {
+		best, mean := 0, 0
+		if top > 0 {
+			best = int(math.Max(0, g.BestFitness) / top * width)
+			mean = min(best, int(math.Max(0, g.MeanFitness)/top*width))
+		}
+		fmt.Printf("%6d |%s%s %.2f\n", g.Generation, strings.Repeat("#", mean), strings.Repeat("=", best-mean), g.BestFitness)
+	}
+}
+
+// printAncestry prints how a genome was bred, back to the initial population
+func printAncestry(genome int, ancestry []metrics.Lineage) {
+	fmt.Printf("\n=== LINEAGE OF GENOME %d ===\n", genome)
+	if len(ancestry) == 0 {
+		fmt.Printf("Genome %d is from the initial population.\n", genome)
+		return
+	}
+	
+	fmt.Printf("%6s %8s %10s %8s %8s %14s\n", "Gen", "Genome", "Origin", "Parent", "Other", "Parent Fitness")
+	for _, l := range ancestry {
+		other := "-"
+		if l.SecondParent != metrics.NoParent {
+			other = strconv.Itoa(l.SecondParent)
+		}
+		fmt.Printf("%6d %8d %10s %8d %8s %14.2f\n", l.Generation, l.Genome, l.Origin, l.Parent, other, l.ParentFitness)
+	}
+	fmt.Printf("Genome %d is from the initial population.\n", ancestry[len(ancestry)-1].Parent)
+}
+
 // printResults prints analysis results to the console
 func printResults(results map[string]interface{}, verbose bool) {
 	// Print session summary if available
@@ -224,6 +836,10 @@ func printResults(results map[string]interface{}, verbose bool) {
 		fmt.Printf("Episode Count: %v\n", summary["episode_count"])
 		fmt.Printf("Success Rate: %.2f%%\n", summary["success_rate"].(float64)*100)
 		fmt.Printf("Average Reward: %.4f\n", summary["avg_reward"])
+		if steps, ok := summary["steps"].(int64); ok && steps > 0 {
+			fmt.Printf("Compute: %.1fs wall clock, %d steps, %d forward / %d backward passes\n",
+				summary["wall_clock_seconds"], steps, summary["forward_passes"], summary["backward_passes"])
+		}
 		
 		if changes, ok := summary["weight_changes"].(map[string]interface{}); ok {
 			fmt.Println("\nWeight Changes:")
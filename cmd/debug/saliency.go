@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/heatmap"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+// Range of the saliency sweep, the same as cmd/policyviz's defaults
+const (
+	saliencyAngleRange = math.Pi
+	saliencyVelRange   = 8.0
+)
+
+// heatmapSize is the width and height in pixels of saliency heatmaps
+const heatmapSize = 240
+
+// saliencyInput summarizes how the force responds to one network input
+// over the sampled states
+type saliencyInput struct {
+	Name    string  `json:"name"`
+	MeanAbs float64 `json:"mean_abs"` // Mean |∂force/∂input| in N per unit of input
+	MaxAbs  float64 `json:"max_abs"`
+	Dead    float64 `json:"dead"` // Fraction of states where |∂force/∂input| is below the threshold
+}
+
+// saliencyReport is the result of a saliency sweep
+type saliencyReport struct {
+	Grid       int             `json:"grid"`       // States sampled across each axis
+	AngleRange float64         `json:"angle_range"`
+	VelRange   float64         `json:"vel_range"`
+	Threshold  float64         `json:"threshold"`  // |∂force/∂input| below which a state counts as dead
+	Inputs     []saliencyInput `json:"inputs"`
+	DeadZone   float64         `json:"dead_zone"`  // Fraction of states where no input moves the force
+	DeadMap    []string        `json:"dead_map"`   // One row per angular velocity, highest first: '#' dead, '.' responsive
+	Gradients  [][][]float64   `json:"gradients"`  // [row][col][input] ∂force/∂input, rows from the highest angular velocity
+}
+
+// analyzeSaliency loads a network checkpoint and perturbs each of its inputs
+// around a grid × grid sweep of (angle, angular velocity), recording where
+// the force does not respond to any of them
+func analyzeSaliency(path string, grid int, threshold float64) (*neural.Network, saliencyReport, error) {
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+	if err := network.LoadFromFile(path); err != nil {
+		return nil, saliencyReport{}, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	if grid < 2 {
+		return nil, saliencyReport{}, fmt.Errorf("-grid must be at least 2, got %d", grid)
+	}
+
+	states := heatmap.States(grid, grid, saliencyAngleRange, saliencyVelRange)
+	gradients := network.SaliencyBatch(states, neural.DefaultSaliencyDelta)
+
+	report := saliencyReport{
+		Grid:       grid,
+		AngleRange: saliencyAngleRange,
+		VelRange:   saliencyVelRange,
+		Threshold:  threshold,
+		Gradients:  make([][][]float64, grid),
+	}
+	for _, name := range network.InputNames() {
+		report.Inputs = append(report.Inputs, saliencyInput{Name: name})
+	}
+	dead := 0
+	for row := 0; row < grid; row++ {
+		report.Gradients[row] = gradients[row*grid : (row+1)*grid]
+		var line strings.Builder
+		for _, gradient := range report.Gradients[row] {
+			responsive := false
+			for i, g := range gradient {
+				in := &report.Inputs[i]
+				in.MeanAbs += math.Abs(g) / float64(len(states))
+				in.MaxAbs = math.Max(in.MaxAbs, math.Abs(g))
+				if math.Abs(g) < threshold {
+					in.Dead += 1 / float64(len(states))
+				} else {
+					responsive = true
+				}
+			}
+			if responsive {
+				line.WriteByte('.')
+			} else {
+				line.WriteByte('#')
+				dead++
+			}
+		}
+		report.DeadMap = append(report.DeadMap, line.String())
+	}
+	report.DeadZone = float64(dead) / float64(len(states))
+	return network, report, nil
+}
+
+// writeSaliencyHeatmaps saves one PNG per network input of ∂force/∂input
+// over the sweep range, as <prefix>_saliency_<input>.png
+func writeSaliencyHeatmaps(network *neural.Network, prefix string) ([]string, error) {
+	states := heatmap.States(heatmapSize, heatmapSize, saliencyAngleRange, saliencyVelRange)
+	gradients := network.SaliencyBatch(states, neural.DefaultSaliencyDelta)
+
+	var files []string
+	values := make([]float64, len(states))
+	for i, name := range network.InputNames() {
+		limit := 0.0
+		for j, gradient := range gradients {
+			values[j] = gradient[i]
+			limit = math.Max(limit, math.Abs(gradient[i]))
+		}
+		grid := heatmap.FromValues(values, heatmapSize, heatmapSize, saliencyAngleRange, saliencyVelRange)
+		title := fmt.Sprintf("dForce/d%s (red pushes right, blue left)", name)
+		file := prefix + "_saliency_" + name + ".png"
+		if err := heatmap.WritePNG(file, heatmap.Render(grid, limit, title, "N/unit")); err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// printSaliency prints each input's sensitivity, tables of ∂force/∂angle
+// and ∂force/∂angular velocity over the sampled states, and the dead zones
+func printSaliency(report saliencyReport) {
+	fmt.Printf("\n=== SALIENCY (%d×%d states, |θ| ≤ %.2f, |ω| ≤ %.1f) ===\n",
+		report.Grid, report.Grid, report.AngleRange, report.VelRange)
+	fmt.Printf("%-18s %12s %12s %8s\n", "Input", "Mean |dF/dx|", "Max |dF/dx|", "Dead")
+	for _, in := range report.Inputs {
+		fmt.Printf("%-18s %12.4f %12.4f %7.1f%%\n", in.Name, in.MeanAbs, in.MaxAbs, 100*in.Dead)
+	}
+
+	for i, in := range report.Inputs[:2] {
+		fmt.Printf("\ndF/d%s in N per unit (rows ω high to low, columns θ left to right):\n", in.Name)
+		fmt.Printf("%8s", "ω \\ θ")
+		for col := 0; col < report.Grid; col++ {
+			fmt.Printf(" %7.2f", report.AngleRange*(2*(float64(col)+0.5)/float64(report.Grid)-1))
+		}
+		fmt.Println()
+		for row, gradients := range report.Gradients {
+			fmt.Printf("%8.2f", report.VelRange*(1-2*(float64(row)+0.5)/float64(report.Grid)))
+			for _, gradient := range gradients {
+				fmt.Printf(" %7.2f", gradient[i])
+			}
+			fmt.Println()
+		}
+	}
+
+	fmt.Printf("\nDead zones ('#' where no input moves the force by %g N per unit):\n", report.Threshold)
+	for _, line := range report.DeadMap {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Printf("%.1f%% of sampled states are dead\n", 100*report.DeadZone)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/evolution"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+func main() {
+	suiteFlag := flag.String("suite", "standard", "Evaluation suite scoring each candidate: standard or robustness")
+	presetFlag := flag.String("preset", env.ClassicPreset, "Pendulum physics preset: "+strings.Join(env.PresetNames(), ", "))
+	generationsFlag := flag.Int("generations", evolution.NewDefaultConfig().Generations, "Generations to run across all restarts")
+	sigmaFlag := flag.Float64("sigma", evolution.NewDefaultConfig().Sigma, "Initial CMA-ES step size")
+	populationFlag := flag.Int("population", 0, "Candidates per generation (0 = 4 + 3·ln(parameters))")
+	restartsFlag := flag.Int("restarts", evolution.NewDefaultConfig().Restarts, "Restarts with a doubled population after the search stagnates")
+	seedFlag := flag.Int64("seed", evolution.NewDefaultConfig().Seed, "Seed for sampling candidates")
+	sinCosFlag := flag.Bool("sincos", false, "Add sin and cos of the angle as network inputs")
+	cartFlag := flag.Bool("cart-features", false, "Add cart position and velocity as network inputs")
+	framesFlag := flag.Int("frames", 0, "Feed the network the features of this many recent observations (0 or 1 = current only)")
+	resumeFlag := flag.String("resume", "", "Start the search from this checkpoint's weights and inputs instead of zeros")
+	outputFlag := flag.String("output", filepath.Join("output", "evolve", "best.json"), "Save the best network here, for cmd/compare and -resume")
+	genomeFlag := flag.String("genome", "", "Also save the best network as a text genome here, to share it or seed cmd/window -seed-genomes")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Evolve] ", log.LstdFlags)
+
+	suite, ok := eval.Suites()[*suiteFlag]
+	if !ok {
+		logger.Fatalf("Unknown suite %q", *suiteFlag)
+	}
+	physics, err := env.Preset(*presetFlag)
+	if err != nil {
+		logger.Fatalf("Invalid -preset: %v", err)
+	}
+	suite = suite.WithPhysics(physics)
+
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+	network.SetEvalMode(true)
+	network.SetPreset(*presetFlag)
+	if *resumeFlag != "" {
+		if err := network.LoadFromFile(*resumeFlag); err != nil {
+			logger.Fatalf("Failed to load %s: %v", *resumeFlag, err)
+		}
+	} else {
+		features := neural.FeatureConfig{SinCos: *sinCosFlag, CartState: *cartFlag, Frames: *framesFlag}
+		if features != (neural.FeatureConfig{}) {
+			network.SetObservationTransformer(neural.NewObservationTransformer(features))
+		}
+	}
+
+	config := evolution.NewDefaultConfig()
+	config.Generations = *generationsFlag
+	config.Sigma = *sigmaFlag
+	config.PopulationSize = *populationFlag
+	config.Restarts = *restartsFlag
+	config.Seed = *seedFlag
+
+	initial := evolution.NetworkParams(network)
+	fitness := evolution.NetworkFitness(network, suite)
+	logger.Printf("Optimizing %d parameters (%s) on the %s suite, starting at fitness %.4f",
+		len(initial), strings.Join(paramNames(network), ", "), suite.Name, fitness(initial))
+
+	result, err := evolution.Optimize(config, initial, fitness, logger)
+	if err != nil {
+		logger.Fatalf("CMA-ES failed: %v", err)
+	}
+	if err := evolution.SetNetworkParams(network, result.Best); err != nil {
+		logger.Fatalf("Failed to load the best parameters: %v", err)
+	}
+
+	r := eval.Run(suite, network)
+	fmt.Printf("\n=== CMA-ES RESULT (%s suite) ===\n", suite.Name)
+	fmt.Printf("Evaluations:  %d over %d generations, %d restarts\n", result.Evaluations, len(result.History), result.Restarts)
+	fmt.Printf("Parameters:   %s\n", formatParams(result.Best))
+	fmt.Printf("Avg reward:   %.4f\n", r.AvgReward)
+	fmt.Printf("Success rate: %.1f%%\n", r.SuccessRate*100)
+	fmt.Printf("Balance time: %.2fs\n", r.AvgBalanceTime)
+	fmt.Printf("Max angle:    %.1f°\n", r.MaxAngle*180/math.Pi)
+
+	if *outputFlag != "" {
+		if err := os.MkdirAll(filepath.Dir(*outputFlag), 0755); err != nil {
+			logger.Fatalf("Failed to create output directory: %v", err)
+		}
+		if err := network.SaveToFile(*outputFlag); err != nil {
+			logger.Fatalf("Failed to save %s: %v", *outputFlag, err)
+		}
+		fmt.Printf("\nBest network saved to %s\n", *outputFlag)
+	}
+	if *genomeFlag != "" {
+		if err := evolution.SaveGenome(*genomeFlag, evolution.NewGenome(network, result.BestFitness, len(result.History))); err != nil {
+			logger.Fatalf("%v", err)
+		}
+		fmt.Printf("Best genome saved to %s\n", *genomeFlag)
+	}
+}
+
+// paramNames names the entries of evolution.NetworkParams: the two raw
+// input weights, the bias, then the feature weights
+func paramNames(network *neural.Network) []string {
+	inputs := network.InputNames()
+	return append([]string{inputs[0], inputs[1], "bias"}, inputs[2:]...)
+}
+
+// formatParams prints a parameter vector compactly
+func formatParams(params []float64) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = fmt.Sprintf("%.4f", p)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/ensemble"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/evolution"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+const usage = `Usage:
+  %[1]s export [options] <network.json | ensemble dir>
+      Write a network checkpoint, or the members of a saved ensemble, as text genomes
+  %[1]s import [options] <file.genome>
+      Rebuild a network checkpoint from a genome, for cmd/policyviz, cmd/compare or -resume
+
+Run "%[1]s <command> -h" for the command's options
+`
+
+func main() {
+	logger := log.New(os.Stderr, "[Genome] ", 0)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "export":
+		err = runExport(args)
+	case "import":
+		err = runImport(args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// runExport writes the genomes of a network checkpoint or saved ensemble
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	outputFlag := fs.String("o", "", "Write here instead of stdout; for an ensemble, a directory receiving member-N.genome, fittest first")
+	fitnessFlag := fs.Float64("fitness", 0, "Fitness to record for a network checkpoint")
+	generationFlag := fs.Int("generation", 0, "Generation to record for a network checkpoint")
+	bestFlag := fs.Bool("best", false, "Export only an ensemble's fittest member")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("export needs one network checkpoint or ensemble directory")
+	}
+	path := fs.Arg(0)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		network := neural.NewNetwork()
+		network.SetLogger(log.New(io.Discard, "", 0))
+		if err := network.LoadFromFile(path); err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		return writeGenome(*outputFlag, evolution.NewGenome(network, *fitnessFlag, *generationFlag))
+	}
+
+	e := ensemble.NewEnsemble(ensemble.NewDefaultConfig(), env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+	if err := e.LoadFromDir(path); err != nil {
+		return err
+	}
+	genomes := e.Genomes()
+	sort.SliceStable(genomes, func(i, j int) bool { return genomes[i].Fitness > genomes[j].Fitness })
+	if *bestFlag {
+		return writeGenome(*outputFlag, genomes[0])
+	}
+	if *outputFlag == "" {
+		for i, genome := range genomes {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(genome)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(*outputFlag, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", *outputFlag, err)
+	}
+	for i, genome := range genomes {
+		if err := writeGenome(filepath.Join(*outputFlag, fmt.Sprintf("member-%d%s", i+1, evolution.GenomeExtension)), genome); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runImport rebuilds a network checkpoint from a genome
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	outputFlag := fs.String("o", "network.json", "Network checkpoint to write")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import needs one genome file")
+	}
+
+	genome, err := evolution.LoadGenome(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	network, err := genome.NewNetwork(log.New(io.Discard, "", 0))
+	if err != nil {
+		return err
+	}
+	if err := network.SaveToFile(*outputFlag); err != nil {
+		return fmt.Errorf("failed to save %s: %w", *outputFlag, err)
+	}
+	fmt.Printf("Generation %d genome (fitness %g, inputs %v) saved to %s\n",
+		genome.Generation, genome.Fitness, genome.Inputs(), *outputFlag)
+	return nil
+}
+
+// writeGenome prints the genome, or saves it to path if one is given
+func writeGenome(path string, genome evolution.Genome) error {
+	if path == "" {
+		fmt.Print(genome)
+		return nil
+	}
+	if err := evolution.SaveGenome(path, genome); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved %s\n", path)
+	return nil
+}
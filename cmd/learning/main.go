@@ -6,14 +6,27 @@ import (
 	"io"
 	"log"
 	"math"
+	"math/rand"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 	"encoding/csv"
+	"encoding/json"
 
+	"github.com/zachbeta/go_inverted_pendulum/pkg/config"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/curriculum"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/exploration"
+	applog "github.com/zachbeta/go_inverted_pendulum/pkg/logger"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/replay"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/reward"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
 )
 
 const (
@@ -22,21 +35,156 @@ const (
 	defaultCheckpoints = 5
 	defaultOutputDir   = "./learning_output"
 	defaultLearningRate = 0.05
+	sessionFile        = "session.json"
+	interruptedFile    = "interrupted.json"
+	interruptedReason  = "interrupted"
 )
 
+// checkpointPerformance is a network's score on the evaluation suite
+type checkpointPerformance struct {
+	Reward      float64 `json:"reward"`
+	MaxAngle    float64 `json:"max_angle"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// sessionProgress is this tool's part of a session checkpoint
+type sessionProgress struct {
+	Checkpoint            int                     `json:"checkpoint"` // Last completed checkpoint
+	EpisodesPerCheckpoint int                     `json:"episodes_per_checkpoint"`
+	Performances          []checkpointPerformance `json:"performances"` // Initial score, then one per checkpoint
+}
+
 var (
 	episodes      = flag.Int("episodes", defaultEpisodes, "Number of training episodes to run")
 	stepsPerEp    = flag.Int("steps", defaultStepsPerEp, "Number of steps per episode")
 	checkpoints   = flag.Int("checkpoints", defaultCheckpoints, "Number of checkpoints to save")
 	outputDir     = flag.String("output", defaultOutputDir, "Directory to save checkpoints and metrics")
 	verbose       = flag.Bool("verbose", false, "Enable verbose output")
+	quiet         = flag.Bool("quiet", false, "Print checkpoint summaries only, without the live progress line")
 	csvOutput     = flag.Bool("csv", false, "Output metrics in CSV format for visualization")
-	adaptiveRate  = flag.Bool("adaptive", true, "Use adaptive learning rate based on success rate")
-	initialLR     = flag.Float64("lr", defaultLearningRate, "Initial learning rate")
+	initialLR     = flag.Float64("lr", defaultLearningRate, "Base learning rate of the -lr-schedule")
+	lrSchedule    = flag.String("lr-schedule", neural.AdaptiveSchedule, "Learning rate schedule: "+strings.Join(neural.ScheduleNames(), ", ")+" (cosine anneals over -episodes)")
+	lrWarmup      = flag.Int("lr-warmup", 0, "Ramp the learning rate up over this many episodes before the schedule (required by -lr-schedule warmup)")
+	batchMetrics  = flag.Bool("batch-metrics", true, "Buffer metrics and write them in background transactions")
+	valueEvery    = flag.Int("value-snapshots", 0, "Record the value landscape over a grid of states every N episodes for cmd/dashboard and cmd/debug -type values (0 disables)")
+	memoryMetrics = flag.Bool("memory-metrics", false, "Keep metrics in memory instead of <output>/metrics.db, for quick runs that need no analysis")
+	metricSteps   = flag.String("metrics-steps", metrics.RecordAllSteps, "Step-level metrics to record: all, every-n (one step in -metrics-every) or aggregate (per-episode mean/std/min/max)")
+	metricsEvery  = flag.Int("metrics-every", metrics.NewDefaultStepSampling().EveryN, "With -metrics-steps every-n, record one step in this many")
+	record        = flag.Bool("record", false, "Record every training episode trajectory to <output>/replays")
+	gamma         = flag.Float64("gamma", 0.99, "Discount factor for bootstrapped TD targets")
+	lambda        = flag.Float64("lambda", 0.0, "Eligibility trace decay for TD(λ) updates (0 = one-step TD)")
+	explore       = flag.String("explore", exploration.None, "Exploration strategy: none, epsilon, gaussian or ou")
+	exploreStart  = flag.Float64("explore-start", 0.2, "Initial epsilon or noise scale")
+	exploreEnd    = flag.Float64("explore-end", 0.01, "Minimum epsilon or noise scale")
+	exploreDecay  = flag.Float64("explore-decay", 0.995, "Per-episode decay of the exploration scale")
+	stochastic    = flag.Bool("stochastic", false, "Sample forces from a Gaussian policy head with a learned std while training; evaluation uses the mean")
+	policyStd     = flag.Float64("policy-std", neural.NewDefaultStochasticPolicy().InitialStd, "Initial std of the -stochastic policy in N")
+	entropyTemp   = flag.Float64("entropy-temp", neural.NewDefaultStochasticPolicy().Temperature, "Entropy bonus temperature of the -stochastic policy")
+	rewardShaping = flag.String("reward-shaping", "", "Penalize each step's force and force changes for smoother control, as term=weight pairs, e.g. force=0.1,jerk=0.05 (terms: "+strings.Join(reward.TermNames(), ", ")+")")
+	rewardScale   = flag.Bool("reward-scale", false, "Learn from rewards divided by the running std of the discounted return, so reward function changes need no learning rate re-tuning (statistics are saved with checkpoints)")
+	normalize     = flag.Bool("normalize", false, "Normalize network inputs by their running mean and std")
+	sinCos        = flag.Bool("sincos", false, "Add sin and cos of the angle as network inputs")
+	cartFeatures  = flag.Bool("cart-features", false, "Add cart position and velocity as network inputs")
+	stopSuccess   = flag.Float64("stop-success", 0, "Stop once the success rate over -stop-window episodes reaches this (0 = never)")
+	stopWindow    = flag.Int("stop-window", 20, "Episodes in the sliding window for stopping criteria")
+	plateau       = flag.Int("plateau", 0, "Stop after this many episodes without reward improvement (0 = never)")
+	timeBudget    = flag.Duration("time-budget", 0, "Stop training after this much wall-clock time (0 = no limit)")
+	optimizerName = flag.String("optimizer", neural.SGD, "Optimizer for weight updates: sgd, adam or rmsprop")
+	momentum      = flag.Float64("momentum", 0.0, "SGD momentum (0 = plain SGD)")
+	seed          = flag.Int64("seed", 0, "Seed for exploration randomness (0 = time-based)")
+	logSampling   = flag.String("log-sampling", string(applog.SampleEveryN), "Per-step log verbosity: all, sampled, episodes or errors")
+	logEvery      = flag.Int("log-every", 100, "With -log-sampling sampled, log one in this many per-step messages")
+	actionSpace   = flag.String("action-space", neural.ContinuousActions, "Forces the network may apply: continuous, bang-bang (-5/0/+5) or discrete")
+	actionBins    = flag.Int("action-bins", 5, "Number of evenly spaced forces for -action-space discrete")
+	resume        = flag.String("resume", "", "Resume training from a session checkpoint, e.g. <output>/checkpoints/session.json")
+	preset        = flag.String("preset", env.ClassicPreset, "Pendulum physics preset: "+strings.Join(env.PresetNames(), ", ")+" (default on resume: the checkpoint's)")
+	actionDelay   = flag.Int("action-delay", 0, "Apply each force this many steps after the network chooses it, simulating actuation latency")
+	controlHold   = flag.Int("control-hold", 0, "Hold each chosen force for this many steps, controlling at a lower rate than the physics (0 or 1 = every step)")
+	observation   = flag.String("observation", env.FullObservation, "What the network observes: full, or angle-only to hide velocities so they must be inferred (pair with -frames)")
+	encoderRes    = flag.Float64("encoder-resolution", 0, "Quantize the observed angle to steps of this many radians, like a rotary encoder (0 = exact)")
+	frames        = flag.Int("frames", 0, "Feed the network the features of this many recent observations, the current included (0 or 1 = current only)")
+	resetMode     = flag.String("reset", env.FixedReset, "Initial state of each episode: "+strings.Join(env.ResetModes(), ", ")+" (random starts center on hanging down at rest)")
+	resetAngle    = flag.Float64("reset-angle", 0.5, "Spread in radians of the initial angle for -reset uniform (±) or gaussian (std)")
+	resetVel      = flag.Float64("reset-angular-vel", 0.5, "Spread in rad/s of the initial angular velocity for random -reset")
+	resetPosition = flag.Float64("reset-position", 0, "Spread in meters of the initial cart position for random -reset")
+	useCurriculum = flag.Bool("curriculum", false, "Start each episode at the network's own progressive difficulty: near upright in still air when easy, further from upright through gusts and sensor noise as it succeeds (overrides -reset)")
+	profileLatency = flag.Bool("profile-latency", false, "Time every forward pass and pendulum step, logging their p50/p99 latency in µs to metrics at each checkpoint")
+	configFile    = flag.String("config", "", "Read settings from a .json, .yaml or .toml file keyed by flag name; flags given on the command line override it")
+)
+
+// The pendulum physics selected by -preset, or the resumed session's
+var (
+	presetName     string
+	pendulumConfig env.Config
 )
 
+// How each training episode starts, from -reset
+var resetOptions env.ResetOptions
+
+// flagSet reports whether a flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func main() {
 	flag.Parse()
+	if err := config.ApplyFile(flag.CommandLine, *configFile); err != nil {
+		log.Fatalf("Invalid -config: %v", err)
+	}
+	
+	// Control per-step logging of the pendulum, network and trainer in one place
+	samplingMode, err := applog.ParseSamplingMode(*logSampling)
+	if err != nil {
+		log.Fatalf("Invalid -log-sampling: %v", err)
+	}
+	sampling := applog.NewDefaultSamplingConfig()
+	sampling.Mode = samplingMode
+	sampling.Every = *logEvery
+	applog.SetDefaultSampling(sampling)
+	
+	// Load the session to resume before touching any output
+	var session *training.SessionState
+	if *resume != "" {
+		state, err := training.LoadSession(*resume)
+		if err != nil {
+			log.Fatalf("Failed to load session checkpoint: %v", err)
+		}
+		session = &state
+	}
+	
+	// A resumed session keeps the physics it was trained on unless -preset is given
+	presetName = *preset
+	if session != nil && session.Network.Preset != "" && !flagSet("preset") {
+		presetName = session.Network.Preset
+	}
+	pendulumConfig, err = env.Preset(presetName)
+	if err != nil {
+		log.Fatalf("Invalid -preset: %v", err)
+	}
+	if *actionDelay < 0 || *controlHold < 0 {
+		log.Fatalf("-action-delay and -control-hold must not be negative")
+	}
+	pendulumConfig.ActionDelay = *actionDelay
+	pendulumConfig.ControlHold = *controlHold
+	if *observation != env.FullObservation && *observation != env.AngleOnlyObservation {
+		log.Fatalf("Invalid -observation %q (want %s or %s)", *observation, env.FullObservation, env.AngleOnlyObservation)
+	}
+	pendulumConfig.Observation = *observation
+	pendulumConfig.EncoderResolution = *encoderRes
+	resetOptions = env.NewDefaultResetOptions()
+	resetOptions.Mode = *resetMode
+	resetOptions.AngleSpread = *resetAngle
+	resetOptions.AngularVelSpread = *resetVel
+	resetOptions.PositionSpread = *resetPosition
+	if err := resetOptions.Validate(); err != nil {
+		log.Fatalf("Invalid -reset: %v", err)
+	}
 	
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(*outputDir, 0755); err != nil {
@@ -44,16 +192,23 @@ func main() {
 	}
 	
 	// Set up logging
-	logFile, err := os.Create(filepath.Join(*outputDir, "learning.log"))
+	logFlags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if session != nil {
+		logFlags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	logFile, err := os.OpenFile(filepath.Join(*outputDir, "learning.log"), logFlags, 0644)
 	if err != nil {
 		log.Fatalf("Failed to create log file: %v", err)
 	}
 	defer logFile.Close()
 	
+	// Console output goes above the live progress line
+	reporter := applog.NewProgress(os.Stdout, !*quiet)
+	
 	var logger *log.Logger
 	if *verbose {
 		// Log to both file and stdout if verbose
-		multiWriter := io.MultiWriter(os.Stdout, logFile)
+		multiWriter := io.MultiWriter(reporter, logFile)
 		logger = log.New(multiWriter, "", log.LstdFlags)
 	} else {
 		// Log to file only if not verbose
@@ -62,11 +217,46 @@ func main() {
 	
 	// Set up metrics logger
 	metricsDBPath := filepath.Join(*outputDir, "metrics.db")
-	metricsLogger, err := metrics.NewLogger(metricsDBPath, *verbose, logger)
+	var metricsLogger *metrics.Logger
+	if *memoryMetrics {
+		metricsLogger, err = metrics.NewStoreLogger(metrics.NewMemoryStore(), *verbose, logger)
+	} else if session != nil && session.SessionID != "" {
+		// Keep appending to the resumed session so its curves stay continuous
+		metricsLogger, err = metrics.ResumeLogger(metricsDBPath, session.SessionID, *verbose, logger)
+	} else {
+		metricsLogger, err = metrics.NewLogger(metricsDBPath, *verbose, logger)
+	}
 	if err != nil {
 		logger.Fatalf("Failed to create metrics logger: %v", err)
 	}
 	defer metricsLogger.Close()
+	if *batchMetrics {
+		metricsLogger.EnableBatching(metrics.NewDefaultBatchConfig())
+	}
+	metricsLogger.SetValueSnapshots(*valueEvery, metrics.NewDefaultValueGrid())
+	if err := metricsLogger.SetStepSampling(metrics.StepSampling{Mode: *metricSteps, EveryN: *metricsEvery}); err != nil {
+		logger.Fatalf("Invalid -metrics-steps: %v", err)
+	}
+	if err := metricsLogger.SetSessionConfig(pendulumConfig, map[string]interface{}{
+		"preset":        presetName,
+		"learning_rate": *initialLR,
+		"lr_schedule":   *lrSchedule,
+		"lr_warmup":     *lrWarmup,
+		"gamma":         *gamma,
+		"lambda":        *lambda,
+		"optimizer":     *optimizerName,
+		"action_space":  *actionSpace,
+		"stochastic":    *stochastic,
+		"entropy_temp":  *entropyTemp,
+		"reward_shaping": *rewardShaping,
+		"reward_scale":  *rewardScale,
+		"action_delay":  *actionDelay,
+		"control_hold":  *controlHold,
+		"frames":        *frames,
+		"settings":      config.Resolved(flag.CommandLine, "config"),
+	}); err != nil {
+		logger.Printf("Failed to record session config: %v", err)
+	}
 	
 	// Create network
 	network := neural.NewNetwork()
@@ -81,17 +271,23 @@ func main() {
 	fmt.Printf("Episodes: %d, Steps per episode: %d, Checkpoints: %d\n", 
 		*episodes, *stepsPerEp, *checkpoints)
 	fmt.Printf("Output directory: %s\n", *outputDir)
+	fmt.Printf("Pendulum preset: %s\n", presetName)
 	fmt.Println("======================================")
 	
-	// Run learning tests
-	fmt.Println("\nRunning basic learning tests...")
-	runNetworkLearnsToBalance(network, logger)
-	
-	fmt.Println("\nRunning temporal difference prediction tests...")
-	runTemporalDifferencePredictions(network, logger)
+	// Run learning tests; a resumed session goes straight back to training
+	if session == nil {
+		fmt.Println("\nRunning basic learning tests...")
+		runNetworkLearnsToBalance(network, logger)
+		
+		fmt.Println("\nRunning temporal difference prediction tests...")
+		runTemporalDifferencePredictions(network, logger)
+	} else {
+		fmt.Printf("\nResuming session %s from %s (episode %d)\n", session.SessionID, *resume, session.Episode)
+	}
 	
 	fmt.Println("\nRunning checkpoint learning tests...")
-	runNetworkImprovesThroughCheckpoints(network, logger, *outputDir, *episodes, *stepsPerEp, *checkpoints)
+	interrupted := notifyInterrupt(reporter)
+	runNetworkImprovesThroughCheckpoints(network, logger, metricsLogger, reporter, interrupted, *outputDir, *episodes, *stepsPerEp, *checkpoints, session)
 	
 	fmt.Println("\nLearning tests completed successfully")
 	fmt.Printf("Results saved to %s\n", *outputDir)
@@ -264,10 +460,28 @@ func runTemporalDifferencePredictions(network *neural.Network, logger *log.Logge
 		successCount, totalTests, 100*float64(successCount)/float64(totalTests))
 }
 
+// notifyInterrupt closes the returned channel on the first SIGINT or
+// SIGTERM, so training can stop after the current episode and save its
+// progress. A second signal exits at once
+func notifyInterrupt(reporter *applog.Progress) <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	interrupted := make(chan struct{})
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		reporter.Printf("  Interrupted: stopping after the current episode (interrupt again to quit now)\n")
+		close(interrupted)
+	}()
+	return interrupted
+}
+
 // runNetworkImprovesThroughCheckpoints verifies that network performance improves
-// across saved and restored checkpoints
+// across saved and restored checkpoints. A non-nil session continues training
+// after its last completed checkpoint. Training progress is shown on reporter
 func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.Logger, 
-	outputDir string, totalEpisodes, stepsPerEpisode, numCheckpoints int) {
+	metricsLogger *metrics.Logger, reporter *applog.Progress, interrupted <-chan struct{}, outputDir string, totalEpisodes, stepsPerEpisode, numCheckpoints int,
+	session *training.SessionState) {
 	
 	if *verbose {
 		logger.Println("=== Testing Network Improves Through Checkpoints ===")
@@ -279,12 +493,22 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 		logger.Fatalf("Failed to create checkpoint directory: %v", err)
 	}
 	
+	// Keep the settings of the run with its checkpoints, to repeat it with -config
+	if err := config.Save(filepath.Join(checkpointDir, config.ResolvedFile), config.Resolved(flag.CommandLine, "config")); err != nil {
+		logger.Printf("Failed to save resolved config: %v", err)
+	}
+	
 	// Create CSV file for metrics if enabled
 	var csvFile *os.File
 	var csvWriter *csv.Writer
 	if *csvOutput {
 		var err error
-		csvFile, err = os.Create(filepath.Join(outputDir, "learning_metrics.csv"))
+		csvPath := filepath.Join(outputDir, "learning_metrics.csv")
+		if session != nil {
+			csvFile, err = os.OpenFile(csvPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		} else {
+			csvFile, err = os.Create(csvPath)
+		}
 		if err != nil {
 			logger.Fatalf("Failed to create CSV file: %v", err)
 		}
@@ -292,8 +516,10 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 		
 		csvWriter = csv.NewWriter(csvFile)
 		defer csvWriter.Flush()
-		
-		// Write header
+	}
+	
+	// Write header unless appending to a resumed session's file
+	if *csvOutput && session == nil {
 		if err := csvWriter.Write([]string{
 			"Episode", "Checkpoint", "AvgReward", "MaxAngle", "SuccessRate", 
 			"AngleWeight", "VelocityWeight", "Bias", "LearningRate",
@@ -305,92 +531,141 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 	// Reset network
 	network = neural.NewNetwork()
 	network.SetLogger(logger)
-	network.SetDebug(false)
+	network.SetLogSampling(applog.DefaultSampling())
 	
-	// Set initial learning rate
+	// Set initial learning rate and TD(λ) parameters
 	network.SetLearningRate(*initialLR)
+	network.SetDiscount(*gamma)
+	network.SetTraceDecay(*lambda)
 	
-	// Define evaluation function
-	evaluateNetwork := func(net *neural.Network) (float64, float64, float64) {
-		// Run 10 episodes and return average reward, max angle, and success rate
-		totalReward := 0.0
-		maxAngle := 0.0
-		successCount := 0
-		const episodes = 10
-		const timeSteps = 100
-		
-		for i := 0; i < episodes; i++ {
-			// Create a pendulum simulation
-			pendulum := env.NewPendulum(env.NewDefaultConfig(), nil)
-			episodeReward := 0.0
-			episodeMaxAngle := 0.0
-			episodeSuccess := true
-			
-			for j := 0; j < timeSteps; j++ {
-				state := pendulum.GetState()
-				
-				// Track max angle
-				absAngle := math.Abs(state.AngleRadians - math.Pi)
-				if absAngle > episodeMaxAngle {
-					episodeMaxAngle = absAngle
-				}
-				
-				// Check if pendulum has fallen too far
-				if absAngle > math.Pi/4 {
-					episodeSuccess = false
-				}
-				
-				force := net.Forward(state)
-				newState, err := pendulum.Step(force)
-				if err != nil {
-					// Skip this step if we hit a constraint
-					continue
-				}
-				
-				// Calculate reward (simplified for test)
-				// Higher reward for more upright pendulum
-				reward := 1.0 - math.Abs(newState.AngleRadians - math.Pi) / math.Pi
-				episodeReward += reward
-			}
-			
-			totalReward += episodeReward / float64(timeSteps)
-			if episodeMaxAngle > maxAngle {
-				maxAngle = episodeMaxAngle
-			}
-			if episodeSuccess {
-				successCount++
-			}
-		}
-		
-		avgReward := totalReward / float64(episodes)
-		successRate := float64(successCount) / float64(episodes)
-		return avgReward, maxAngle, successRate
+	// Configure the observation layer; it is saved with each checkpoint
+	features := neural.FeatureConfig{Normalize: *normalize, SinCos: *sinCos, CartState: *cartFeatures, Frames: *frames}
+	if features != (neural.FeatureConfig{}) {
+		network.SetObservationTransformer(neural.NewObservationTransformer(features))
 	}
 	
-	// Measure initial performance
-	var initialReward, initialMaxAngle, initialSuccessRate float64
-	initialReward, initialMaxAngle, initialSuccessRate = evaluateNetwork(network)
-	fmt.Printf("  Initial performance: Reward=%.4f, MaxAngle=%.4f, SuccessRate=%.1f%%\n", 
-		initialReward, initialMaxAngle, initialSuccessRate*100)
+	// Map the network's output to the selected forces; saved with each checkpoint
+	actions, err := neural.NewActionSpace(*actionSpace, *actionBins)
+	if err != nil {
+		logger.Fatalf("Invalid action space: %v", err)
+	}
+	network.SetActionSpace(actions)
+	network.SetPreset(presetName)
+	
+	// Wrap the network with exploration noise for training episodes only
+	config := training.NewDefaultConfig()
+	config.Exploration = *explore
+	config.ExplorationStart = *exploreStart
+	config.ExplorationEnd = *exploreEnd
+	config.ExplorationDecay = *exploreDecay
+	config.StopSuccessRate = *stopSuccess
+	config.StopWindow = *stopWindow
+	config.PlateauEpisodes = *plateau
+	config.TimeBudget = *timeBudget
+	config.Optimizer = *optimizerName
+	config.Momentum = *momentum
+	config.BaseLearningRate = *initialLR
+	config.LRSchedule = *lrSchedule
+	config.LRWarmup = *lrWarmup
+	config.MaxEpisodes = totalEpisodes
+	
+	// One schedule sets the learning rate of every episode
+	schedule, err := neural.NewSchedule(config.ScheduleConfig())
+	if err != nil {
+		logger.Fatalf("Invalid learning rate schedule: %v", err)
+	}
+	
+	// The optimizer's moments are saved with each checkpoint
+	optimizer, err := neural.NewOptimizer(config.OptimizerConfig())
+	if err != nil {
+		logger.Fatalf("Failed to create optimizer: %v", err)
+	}
+	network.SetOptimizer(optimizer)
+	stopper := training.NewStopper(config)
+	
+	// Steps, network passes and time spent, for comparing sample efficiency
+	var budget metrics.ComputeBudget
+	
+	// One notion of success for the network, stopping criteria and metrics
+	network.SetSuccessCriteria(config.Success)
+	metricsLogger.SetSuccessCriteria(config.Success)
+	
+	// Count random draws so a session checkpoint can continue the same stream
+	source := training.NewRandSource(*seed)
+	if *seed == 0 {
+		source = training.NewRandSource(time.Now().UnixNano())
+	}
 	
 	// Calculate episodes per checkpoint
 	episodesPerCheckpoint := totalEpisodes / numCheckpoints
 	
 	// Train and save checkpoints
-	checkpointPerformances := make([]struct {
-		reward      float64
-		maxAngle    float64
-		successRate float64
-	}, numCheckpoints+1)
+	checkpointPerformances := make([]checkpointPerformance, numCheckpoints+1)
+	firstCheckpoint := 1
 	
-	checkpointPerformances[0] = struct {
-		reward      float64
-		maxAngle    float64
-		successRate float64
-	}{
-		reward:      initialReward,
-		maxAngle:    initialMaxAngle,
-		successRate: initialSuccessRate,
+	// Restore the network, schedules and noise of a resumed session
+	if session != nil {
+		var progress sessionProgress
+		if err := json.Unmarshal(session.Progress, &progress); err != nil {
+			logger.Fatalf("Failed to read session progress: %v", err)
+		}
+		if err := network.RestoreState(session.Network); err != nil {
+			logger.Fatalf("Failed to restore network: %v", err)
+		}
+		if session.Stopper != nil {
+			stopper.Restore(*session.Stopper)
+		}
+		if session.RNG != nil {
+			source = training.RestoreRandSource(*session.RNG)
+		}
+		if session.Budget != nil {
+			budget = *session.Budget
+		}
+		episodesPerCheckpoint = progress.EpisodesPerCheckpoint
+		firstCheckpoint = progress.Checkpoint + 1
+		if len(checkpointPerformances) < len(progress.Performances) {
+			checkpointPerformances = make([]checkpointPerformance, len(progress.Performances))
+		}
+		copy(checkpointPerformances, progress.Performances)
+	}
+	
+	explorer, err := exploration.NewFromConfig(config, network, pendulumConfig.MaxForce, rand.New(source))
+	if err != nil {
+		logger.Fatalf("Failed to create explorer: %v", err)
+	}
+	
+	// A resumed stochastic policy keeps its learned std
+	if *stochastic {
+		policy := neural.NewDefaultStochasticPolicy()
+		policy.InitialStd = *policyStd
+		policy.Temperature = *entropyTemp
+		if err := network.EnableStochasticPolicy(policy, rand.New(source)); err != nil {
+			logger.Fatalf("Failed to enable stochastic policy: %v", err)
+		}
+	}
+	
+	// A resumed session keeps scaling by the return statistics it saved
+	if *rewardScale && network.RewardScaler() == nil {
+		network.SetRewardScaler(neural.NewRewardScaler(*gamma))
+	}
+	
+	// Optional force and jerk penalties on the training reward
+	shaping, err := reward.ParseShaping(*rewardShaping)
+	if err != nil {
+		logger.Fatalf("Invalid -reward-shaping: %v", err)
+	}
+	shaper, err := reward.NewShaper(shaping, pendulumConfig.MaxForce)
+	if err != nil {
+		logger.Fatalf("Failed to create reward shaper: %v", err)
+	}
+	
+	// Evaluate on the standard suite so numbers are comparable across tools
+	suite := eval.StandardSuite().WithPhysics(pendulumConfig)
+	evaluateNetwork := func(net *neural.Network) (float64, float64, float64) {
+		net.SetEvalMode(true)
+		defer net.SetEvalMode(false)
+		result := eval.Run(suite, net)
+		return result.AvgReward, result.MaxAngle, result.SuccessRate
 	}
 	
 	// Get initial weights for CSV
@@ -398,40 +673,131 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 	var angleWeight, velocityWeight, bias float64
 	angleWeight, velocityWeight, bias = weights[0], weights[1], weights[2]
 	
-	// Write initial metrics to CSV
-	if *csvOutput {
-		if err := csvWriter.Write([]string{
-			"0", "0", 
-			fmt.Sprintf("%.4f", initialReward),
-			fmt.Sprintf("%.4f", initialMaxAngle),
-			fmt.Sprintf("%.4f", initialSuccessRate),
-			fmt.Sprintf("%.4f", angleWeight),
-			fmt.Sprintf("%.4f", velocityWeight),
-			fmt.Sprintf("%.4f", bias),
-			fmt.Sprintf("%.4f", network.GetLearningRate()),
-		}); err != nil {
-			logger.Fatalf("Failed to write CSV row: %v", err)
+	// Measure initial performance; a resumed session already has it
+	if session == nil {
+		var initialReward, initialMaxAngle, initialSuccessRate float64
+		initialReward, initialMaxAngle, initialSuccessRate = evaluateNetwork(network)
+		fmt.Printf("  Initial performance: Reward=%.4f, MaxAngle=%.4f, SuccessRate=%.1f%%\n", 
+			initialReward, initialMaxAngle, initialSuccessRate*100)
+		
+		checkpointPerformances[0] = checkpointPerformance{
+			Reward:      initialReward,
+			MaxAngle:    initialMaxAngle,
+			SuccessRate: initialSuccessRate,
+		}
+		
+		// Write initial metrics to CSV
+		if *csvOutput {
+			if err := csvWriter.Write([]string{
+				"0", "0", 
+				fmt.Sprintf("%.4f", initialReward),
+				fmt.Sprintf("%.4f", initialMaxAngle),
+				fmt.Sprintf("%.4f", initialSuccessRate),
+				fmt.Sprintf("%.4f", angleWeight),
+				fmt.Sprintf("%.4f", velocityWeight),
+				fmt.Sprintf("%.4f", bias),
+				fmt.Sprintf("%.4f", network.GetLearningRate()),
+			}); err != nil {
+				logger.Fatalf("Failed to write CSV row: %v", err)
+			}
+			csvWriter.Flush()
 		}
-		csvWriter.Flush()
 	}
 	
-	for checkpoint := 1; checkpoint <= numCheckpoints; checkpoint++ {
-		fmt.Printf("  Training checkpoint %d/%d...\n", checkpoint, numCheckpoints)
+	// Stopping early ends training after the current checkpoint is saved
+	completedCheckpoints := max(numCheckpoints, firstCheckpoint-1)
+	stopReason := ""
+	reporter.Start(numCheckpoints*episodesPerCheckpoint, (firstCheckpoint-1)*episodesPerCheckpoint)
+	
+	// One pendulum runs every episode, restarted from -reset each time, or a
+	// fresh one per episode sampled at the network's difficulty
+	pendulum := env.NewPendulum(pendulumConfig, logger)
+	var progression *curriculum.Controller
+	if *useCurriculum {
+		progression = curriculum.NewController(curriculum.New(curriculum.NewDefaultConfig(), rand.New(source), logger), network)
+	}
+	
+	// Each checkpoint's value predictions are compared with the discounted
+	// returns its episodes actually earned
+	calibration := metrics.NewCalibrationTracker(network.GetDiscount())
+	
+	// Forward pass and step latencies, logged and restarted at each checkpoint
+	var forwardLatency, stepLatency *metrics.LatencyHistogram
+	if *profileLatency {
+		forwardLatency, stepLatency = metrics.NewLatencyHistogram(), metrics.NewLatencyHistogram()
+		network.SetLatencyRecorder(forwardLatency)
+		pendulum.SetLatencyRecorder(stepLatency)
+	}
+	
+	for checkpoint := firstCheckpoint; checkpoint <= numCheckpoints; checkpoint++ {
+		reporter.Printf("  Training checkpoint %d/%d...\n", checkpoint, numCheckpoints)
 		startTime := time.Now()
 		
 		// Train for this checkpoint phase
 		episodeSuccesses := 0
+		episodesRun := 0
 		
-		for i := 0; i < episodesPerCheckpoint; i++ {
+		for i := 0; i < episodesPerCheckpoint && stopReason == ""; i++ {
 			// Set episode number for metrics
 			episodeNum := (checkpoint-1)*episodesPerCheckpoint + i + 1
 			network.SetEpisode(episodeNum)
+			metricsLogger.SetEpisode(episodeNum)
+			if err := metricsLogger.LogValueLandscape(network.Value); err != nil {
+				logger.Printf("Failed to record value snapshot: %v", err)
+			}
+			if explorer != nil {
+				explorer.SetEpisode(episodeNum - 1)
+			}
+			
+			// Schedule this episode's learning rate from this phase's success so far
+			phaseSuccess := 0.0
+			if i > 0 {
+				phaseSuccess = float64(episodeSuccesses) / float64(i)
+			}
+			if rate := schedule.Rate(episodeNum-1, network.GetLearningRate(), phaseSuccess); rate != network.GetLearningRate() {
+				network.SetLearningRate(rate)
+			}
+			if err := metricsLogger.LogLearningRate(*lrSchedule, network.GetLearningRate()); err != nil {
+				logger.Printf("Failed to record learning rate: %v", err)
+			}
 			
 			// Generate experience and train
-			pendulum := env.NewPendulum(env.NewDefaultConfig(), nil)
+			episodeStart := time.Now()
+			if progression != nil {
+				pendulum = progression.NewPendulum(pendulumConfig, logger)
+				if stepLatency != nil {
+					pendulum.SetLatencyRecorder(stepLatency)
+				}
+				level, _ := progression.Last()
+				if err := metricsLogger.LogCurriculum(level.Difficulty, level.Values()); err != nil {
+					logger.Printf("Failed to log curriculum difficulty: %v", err)
+				}
+			} else if _, err := pendulum.ResetWith(resetOptions); err != nil {
+				logger.Fatalf("Failed to reset pendulum: %v", err)
+			}
+			shaper.Reset()
+			if scaler := network.RewardScaler(); scaler != nil {
+				scaler.EndEpisode() // Episodes cut off at the step limit never pass done
+			}
 			episodeReward := 0.0
+			var episodeComponents reward.Components
 			episodeMaxAngle := 0.0
-			episodeSuccess := true
+			episodeSteps := 0
+			balanceSteps := 0
+			
+			// Record the trajectory for later replay if requested
+			var recorder *replay.Recorder
+			if *record {
+				replayPath := filepath.Join(outputDir, "replays", fmt.Sprintf("episode_%d.jsonl", episodeNum))
+				var err error
+				recorder, err = replay.NewRecorder(replayPath, replay.Header{
+					Episode: episodeNum,
+					Config:  pendulum.GetConfig(),
+				})
+				if err != nil {
+					logger.Printf("Failed to start episode recording: %v", err)
+				}
+			}
 			
 			for j := 0; j < stepsPerEpisode; j++ {
 				network.IncrementStep()
@@ -440,86 +806,124 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 				state := pendulum.GetState()
 				
 				// Track max angle
-				absAngle := math.Abs(state.AngleRadians - math.Pi)
+				absAngle := env.UprightDeviation(state.AngleRadians)
 				if absAngle > episodeMaxAngle {
 					episodeMaxAngle = absAngle
 				}
 				
-				// Check if pendulum has fallen too far
-				if absAngle > math.Pi/4 {
-					episodeSuccess = false
+				if absAngle < config.Success.MaxAngle {
+					balanceSteps++
 				}
 				
-				// Get action from network
+				// Value of the state before this step's update, for calibration
+				prediction := network.Value(state.AngleRadians, state.AngularVel)
+				
+				// Get action from network, perturbed for exploration if enabled
 				force := network.Forward(state)
+				budget.Steps++
+				budget.ForwardPasses++
+				if explorer != nil {
+					force = explorer.Perturb(force)
+					if err := metricsLogger.LogExploration(explorer.Strategy(), explorer.Scale(), explorer.LastNoise()); err != nil {
+						logger.Printf("Failed to log exploration: %v", err)
+					}
+				}
 				
 				// Apply action to environment
-				newState, err := pendulum.Step(force)
+				newState, done, err := pendulum.Advance(force)
 				if err != nil {
-					// Skip this step if we hit a constraint
-					continue
+					logger.Printf("Step failed: %v", err)
+				}
+				energy := env.Energy(pendulum.GetState(), pendulumConfig)
+				if err := metricsLogger.LogEnergy(energy.Kinetic, energy.Potential, energy.Total); err != nil {
+					logger.Printf("Failed to log energy: %v", err)
 				}
 				
-				// Calculate reward
-				reward := 1.0 - math.Abs(newState.AngleRadians - math.Pi) / math.Pi
+				// Calculate reward, less any force and jerk penalties; the
+				// reward has no position term, so that component stays zero
+				components := reward.Components{
+					Angle:  1.0 - env.UprightDeviation(newState.AngleRadians) / math.Pi,
+					Effort: shaper.Penalty(force),
+				}
+				reward := components.Total()
 				episodeReward += reward
+				episodeComponents.Add(components)
+				
+				calibration.Step(prediction, reward)
 				
-				// Update network with reward
-				network.Update(reward)
+				if recorder != nil {
+					recorder.Record(state, force, reward, done)
+				}
 				
-				// TD learning update
-				currentValue := network.Predict(state.AngleRadians, state.AngularVel)
-				nextValue := network.Predict(newState.AngleRadians, newState.AngularVel)
+				// Update network toward the TD(λ) target; a terminal step has
+				// no future value to bootstrap from, which is how falling is learned
+				network.UpdateTD(reward, newState, done)
 				
-				// We're not using these values directly, but they trigger the TD learning internally
-				_ = currentValue
-				_ = nextValue
+				// UpdateTD evaluates the state, and the next one unless it is
+				// terminal, and takes one gradient step
+				budget.ForwardPasses++
+				if !done {
+					budget.ForwardPasses++
+				}
+				budget.BackwardPasses++
 				
 				if *verbose && j%100 == 0 {
 					logger.Printf("  Episode %d, Step %d: angle=%.4f, reward=%.4f, value=%.4f", 
-						i+1, j+1, state.AngleRadians, reward, currentValue)
+						i+1, j+1, state.AngleRadians, reward, prediction)
 				}
+				
+				if done {
+					if err := metricsLogger.LogTermination(string(pendulum.GetTermination()), j); err != nil {
+						logger.Printf("Failed to log termination: %v", err)
+					}
+					break
+				}
+				episodeSteps++
+			}
+			
+			calibration.EndEpisode()
+			
+			if recorder != nil {
+				if err := recorder.Close(); err != nil {
+					logger.Printf("Failed to save episode recording: %v", err)
+				}
+			}
+			
+			// Label how the episode ended; running out of steps is a timeout
+			if err := metricsLogger.LogFailure(pendulum.Failure(), episodeSteps, pendulum.GetState()); err != nil {
+				logger.Printf("Failed to log failure mode: %v", err)
+			}
+			
+			budget.WallClock += time.Since(episodeStart)
+			if err := metricsLogger.LogBudget(budget); err != nil {
+				logger.Printf("Failed to log compute budget: %v", err)
+			}
+			
+			// Keep each reward term's sum for trade-off curves
+			if err := metricsLogger.LogRewardComponents(episodeComponents.Angle, episodeComponents.Position, episodeComponents.Effort, episodeSteps); err != nil {
+				logger.Printf("Failed to log reward components: %v", err)
 			}
 			
 			// Track episode success
+			duration := float64(episodeSteps) * pendulumConfig.DeltaTime
+			episodeSuccess, err := metricsLogger.LogEpisode(episodeReward, balanceSteps, episodeMaxAngle, episodeSteps, duration)
+			if err != nil {
+				logger.Printf("Failed to log episode: %v", err)
+			}
+			episodesRun++
 			if episodeSuccess {
 				episodeSuccesses++
 			}
-			
-			// Adaptive learning rate if enabled
-			if *adaptiveRate && i > 0 && i%10 == 0 {
-				successRate := float64(episodeSuccesses) / float64(i+1)
-				
-				// Adjust learning rate based on success rate
-				currentLR := network.GetLearningRate()
-				var newLR float64
-				
-				if successRate < 0.3 {
-					// Poor performance, reduce learning rate
-					newLR = currentLR * 0.9
-				} else if successRate > 0.7 {
-					// Good performance, increase learning rate slightly
-					newLR = currentLR * 1.05
-				} else {
-					// Moderate performance, keep learning rate stable
-					newLR = currentLR
-				}
-				
-				// Ensure learning rate stays within reasonable bounds
-				if newLR < 0.001 {
-					newLR = 0.001
-				} else if newLR > 0.2 {
-					newLR = 0.2
-				}
-				
-				if newLR != currentLR {
-					network.SetLearningRate(newLR)
-					if *verbose {
-						logger.Printf("  Adjusted learning rate: %.4f -> %.4f (success rate: %.2f)", 
-							currentLR, newLR, successRate)
-					}
-				}
+			stopper.RecordEpisode(episodeReward, episodeSuccess)
+			if stop, reason := stopper.ShouldStop(); stop {
+				stopReason = reason
 			}
+			select {
+			case <-interrupted:
+				stopReason = interruptedReason
+			default:
+			}
+			reporter.Episode(episodeSteps, episodeSuccess)
 			
 			// Get current weights for CSV
 			weights := network.GetWeights()
@@ -548,19 +952,16 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 				}
 				csvWriter.Flush()
 			}
-			
-			// Log progress every 10 episodes or for the last episode
-			if i%10 == 0 || i == episodesPerCheckpoint-1 {
-				fmt.Printf("    Episode %d/%d completed\r", i+1, episodesPerCheckpoint)
-			}
 		}
-		fmt.Println() // End the progress line
 		
 		// Calculate checkpoint success rate
-		checkpointSuccessRate := float64(episodeSuccesses) / float64(episodesPerCheckpoint)
+		checkpointSuccessRate := float64(episodeSuccesses) / float64(max(1, episodesRun))
 		
-		// Save checkpoint
+		// Save checkpoint, marking one cut short by a signal
 		checkpointPath := filepath.Join(checkpointDir, fmt.Sprintf("checkpoint_%d.json", checkpoint))
+		if stopReason == interruptedReason {
+			checkpointPath = filepath.Join(checkpointDir, interruptedFile)
+		}
 		if err := network.SaveToFile(checkpointPath); err != nil {
 			logger.Fatalf("Failed to save checkpoint: %v", err)
 		}
@@ -568,46 +969,91 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 		// Evaluate performance
 		var reward, maxAngle, successRate float64
 		reward, maxAngle, successRate = evaluateNetwork(network)
-		checkpointPerformances[checkpoint] = struct {
-			reward      float64
-			maxAngle    float64
-			successRate float64
-		}{
-			reward:      reward,
-			maxAngle:    maxAngle,
-			successRate: successRate,
+		checkpointPerformances[checkpoint] = checkpointPerformance{
+			Reward:      reward,
+			MaxAngle:    maxAngle,
+			SuccessRate: successRate,
+		}
+		
+		// Calibrate this checkpoint's value predictions against the returns realized
+		calibrated := calibration.Calibration()
+		calibrated.Checkpoint = checkpoint
+		if err := metricsLogger.LogCalibration(calibrated); err != nil {
+			logger.Printf("Failed to log value calibration: %v", err)
+		}
+		calibration.Reset()
+		
+		var forwardSummary, stepSummary metrics.LatencySummary
+		if *profileLatency {
+			forwardSummary, stepSummary = forwardLatency.Summary(), stepLatency.Summary()
+			if err := metricsLogger.LogLatency("forward", forwardSummary); err != nil {
+				logger.Printf("Failed to log forward pass latency: %v", err)
+			}
+			if err := metricsLogger.LogLatency("step", stepSummary); err != nil {
+				logger.Printf("Failed to log step latency: %v", err)
+			}
+			forwardLatency.Reset()
+			stepLatency.Reset()
+		}
+		
+		// Save everything needed to continue training from here
+		sessionPath := filepath.Join(checkpointDir, sessionFile)
+		progress := sessionProgress{
+			Checkpoint:            checkpoint,
+			EpisodesPerCheckpoint: episodesPerCheckpoint,
+			Performances:          checkpointPerformances[:checkpoint+1],
+		}
+		if err := saveSession(sessionPath, network, metricsLogger, stopper, source, budget, checkpoint*episodesPerCheckpoint, progress); err != nil {
+			logger.Printf("Failed to save session checkpoint: %v", err)
 		}
 		
 		duration := time.Since(startTime)
-		fmt.Printf("  Checkpoint %d complete in %v\n", checkpoint, duration)
-		fmt.Printf("  Performance: Reward=%.4f, MaxAngle=%.4f, SuccessRate=%.1f%%\n", 
+		reporter.Printf("  Checkpoint %d complete in %v\n", checkpoint, duration)
+		reporter.Printf("  Performance: Reward=%.4f, MaxAngle=%.4f, SuccessRate=%.1f%%\n", 
 			reward, maxAngle, successRate*100)
-		fmt.Printf("  Training success rate: %.1f%%\n", checkpointSuccessRate*100)
+		reporter.Printf("  Training success rate: %.1f%%\n", checkpointSuccessRate*100)
+		reporter.Printf("  Value calibration (γ=%.2f): bias=%.4f, RMSE=%.4f, rank correlation=%.3f\n",
+			calibrated.Gamma, calibrated.Bias, calibrated.RMSE, calibrated.RankCorrelation)
+		if scaler := network.RewardScaler(); scaler != nil {
+			reporter.Printf("  Reward scale: rewards divided by return std %.4f\n", scaler.Std())
+		}
+		if *profileLatency {
+			reporter.Printf("  Latency: forward p50=%v p99=%v, step p50=%v p99=%v\n",
+				forwardSummary.P50, forwardSummary.P99, stepSummary.P50, stepSummary.P99)
+		}
+		
+		if stopReason != "" {
+			completedCheckpoints = checkpoint
+			reporter.Printf("  Stopping early after episode %d: %s\n", (checkpoint-1)*episodesPerCheckpoint+episodesRun, stopReason)
+			break
+		}
 	}
 	
+	reporter.Finish()
+	
 	// Print summary
 	fmt.Println("\n  Training Summary:")
 	fmt.Printf("  Initial: Reward=%.4f, MaxAngle=%.4f, SuccessRate=%.1f%%\n", 
-		checkpointPerformances[0].reward, 
-		checkpointPerformances[0].maxAngle,
-		checkpointPerformances[0].successRate*100)
+		checkpointPerformances[0].Reward, 
+		checkpointPerformances[0].MaxAngle,
+		checkpointPerformances[0].SuccessRate*100)
 	
 	var improved bool
 	improved = true
-	for i := 1; i <= numCheckpoints; i++ {
-		rewardChange := 100 * (checkpointPerformances[i].reward - checkpointPerformances[0].reward) / 
-			math.Abs(checkpointPerformances[0].reward)
-		angleChange := 100 * (checkpointPerformances[0].maxAngle - checkpointPerformances[i].maxAngle) / 
-			checkpointPerformances[0].maxAngle
-		successChange := 100 * (checkpointPerformances[i].successRate - checkpointPerformances[0].successRate)
+	for i := 1; i <= completedCheckpoints; i++ {
+		rewardChange := 100 * (checkpointPerformances[i].Reward - checkpointPerformances[0].Reward) / 
+			math.Abs(checkpointPerformances[0].Reward)
+		angleChange := 100 * (checkpointPerformances[0].MaxAngle - checkpointPerformances[i].MaxAngle) / 
+			checkpointPerformances[0].MaxAngle
+		successChange := 100 * (checkpointPerformances[i].SuccessRate - checkpointPerformances[0].SuccessRate)
 		
 		fmt.Printf("  Checkpoint %d: Reward=%.4f (%+.1f%%), MaxAngle=%.4f (%+.1f%%), SuccessRate=%.1f%% (%+.1f%%)\n", 
 			i, 
-			checkpointPerformances[i].reward, rewardChange,
-			checkpointPerformances[i].maxAngle, angleChange,
-			checkpointPerformances[i].successRate*100, successChange)
+			checkpointPerformances[i].Reward, rewardChange,
+			checkpointPerformances[i].MaxAngle, angleChange,
+			checkpointPerformances[i].SuccessRate*100, successChange)
 		
-		if i > 1 && checkpointPerformances[i].reward <= checkpointPerformances[i-1].reward {
+		if i > 1 && checkpointPerformances[i].Reward <= checkpointPerformances[i-1].Reward {
 			improved = false
 		}
 	}
@@ -620,17 +1066,14 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 	fmt.Printf("  Velocity Weight: %.4f\n", velocityWeight)
 	fmt.Printf("  Bias: %.4f\n", bias)
 	fmt.Printf("  Learning Rate: %.4f\n", network.GetLearningRate())
+	fmt.Printf("  Compute: %s\n", budget)
 	
 	// Verify overall improvement
-	var finalPerf, initialPerf struct {
-		reward      float64
-		maxAngle    float64
-		successRate float64
-	}
-	finalPerf = checkpointPerformances[numCheckpoints]
+	var finalPerf, initialPerf checkpointPerformance
+	finalPerf = checkpointPerformances[completedCheckpoints]
 	initialPerf = checkpointPerformances[0]
 	
-	if finalPerf.reward <= initialPerf.reward {
+	if finalPerf.Reward <= initialPerf.Reward {
 		fmt.Printf("\n  Network did not improve overall\n")
 	} else if !improved {
 		fmt.Printf("\n  Network improved overall but not consistently between checkpoints\n")
@@ -644,3 +1087,33 @@ func runNetworkImprovesThroughCheckpoints(network *neural.Network, logger *log.L
 		fmt.Printf("  You can visualize these metrics using any plotting tool or spreadsheet software\n")
 	}
 }
+
+// saveSession writes a session checkpoint after episodes training episodes,
+// flushing buffered metrics first so the database matches the checkpoint
+func saveSession(path string, network *neural.Network, metricsLogger *metrics.Logger,
+	stopper *training.Stopper, source *training.RandSource, budget metrics.ComputeBudget, episodes int, progress sessionProgress) error {
+	if err := metricsLogger.Flush(); err != nil {
+		return fmt.Errorf("failed to flush metrics: %w", err)
+	}
+	
+	progressJSON, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal progress: %w", err)
+	}
+	
+	stopperState := stopper.State()
+	rngState := source.State()
+	return training.SaveSession(path, training.SessionState{
+		Version:       training.SessionVersion,
+		SavedAt:       time.Now(),
+		SessionID:     metricsLogger.GetSessionID(),
+		Network:       network.State(),
+		Episode:       episodes,
+		TotalEpisodes: episodes,
+		LearningRate:  network.GetLearningRate(),
+		Stopper:       &stopperState,
+		RNG:           &rngState,
+		Budget:        &budget,
+		Progress:      progressJSON,
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+// finalCheckpoint returns the highest numbered checkpoint a run saved
+func finalCheckpoint(dir string) (string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "checkpoints", "checkpoint_*.json"))
+	if err != nil {
+		return "", err
+	}
+	final, finalNumber := "", -1
+	for _, path := range paths {
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "checkpoint_"), ".json"))
+		if err == nil && number > finalNumber {
+			final, finalNumber = path, number
+		}
+	}
+	if final == "" {
+		return "", fmt.Errorf("no checkpoints in %s", dir)
+	}
+	return final, nil
+}
+
+// evaluate runs a finished run's final checkpoint through the suite, on
+// the physics preset it was trained on
+func evaluate(r *run, suite eval.Suite) {
+	path, err := finalCheckpoint(r.Dir)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+	network.SetEvalMode(true)
+	if err := network.LoadFromFile(path); err != nil {
+		r.Error = fmt.Sprintf("failed to load %s: %v", path, err)
+		return
+	}
+	preset := network.GetPreset()
+	if preset == "" {
+		preset = env.ClassicPreset
+	}
+	physics, err := env.Preset(preset)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+
+	result := eval.Run(suite.WithPhysics(physics), network)
+	result.Episodes = nil // The summary keeps the aggregates only
+	r.Checkpoint = path
+	r.Eval = &result
+}
+
+// printSeedVariance reports each config's evaluation across its seeds with
+// confidence intervals, and whether each config differs from the first by
+// more than seed noise
+func printSeedVariance(runs []run, suite string) {
+	var names []string
+	results := make(map[string][]eval.Result)
+	for _, r := range runs {
+		if _, ok := results[r.Name]; !ok {
+			names = append(names, r.Name)
+			results[r.Name] = nil
+		}
+		if r.Eval != nil {
+			results[r.Name] = append(results[r.Name], *r.Eval)
+		}
+	}
+
+	fmt.Printf("\n=== SEED VARIANCE (%s suite, mean ± std [95%% CI] across seeds) ===\n", suite)
+	fmt.Printf("%-20s %6s %36s %36s\n", "Config", "Seeds", "Success", "Reward")
+	variances := make([]eval.SeedVariance, len(names))
+	for i, name := range names {
+		v := eval.SummarizeSeeds(name, results[name])
+		variances[i] = v
+		fmt.Printf("%-20s %6d %6.1f%% ± %5.1f%% [%6.1f%%, %6.1f%%] %8.4f ± %6.4f [%7.4f, %7.4f]\n",
+			name, len(v.Results), v.SuccessRate.Mean*100, v.SuccessRate.Std*100, v.SuccessRate.Low*100, v.SuccessRate.High*100,
+			v.Reward.Mean, v.Reward.Std, v.Reward.Low, v.Reward.High)
+	}
+	if len(variances) > 0 && len(variances[0].Results) < 2 {
+		fmt.Println("With a single seed there is no spread to judge differences by; train more -seeds")
+	}
+	if len(variances) < 2 {
+		return
+	}
+
+	fmt.Printf("\n=== VS %s (Welch's t-test, significant at p < %.2f) ===\n", variances[0].Name, eval.SignificanceLevel)
+	fmt.Printf("%-20s %10s %8s %10s %8s  %s\n", "Config", "ΔSuccess", "p", "ΔReward", "p", "Verdict")
+	for _, v := range variances[1:] {
+		c := eval.CompareSeeds(variances[0], v)
+		verdict := "significant"
+		switch {
+		case !c.SuccessRate.Significant && !c.Reward.Significant:
+			verdict = "NOT significant, could be seed noise"
+		case !c.SuccessRate.Significant:
+			verdict = "reward only, success rate could be seed noise"
+		case !c.Reward.Significant:
+			verdict = "success rate only, reward could be seed noise"
+		}
+		fmt.Printf("%-20s %+9.1f%% %8.4f %+10.4f %8.4f  %s\n",
+			v.Name, c.SuccessRate.Delta*100, c.SuccessRate.PValue, c.Reward.Delta, c.Reward.PValue, verdict)
+	}
+}
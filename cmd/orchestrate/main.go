@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+)
+
+// run is one training run: a config file trained with one seed in its own
+// output directory
+type run struct {
+	Name     string               `json:"name"`   // Config name, "default" without a config file
+	Config   string               `json:"config"` // Config file passed to cmd/learning -config, if any
+	Seed     int64                `json:"seed"`
+	Dir      string               `json:"dir"`
+	Error    string               `json:"error,omitempty"` // Why the run failed, empty on success
+	Duration time.Duration        `json:"duration"`
+	Stats    metrics.SessionStats `json:"stats"` // Final results from the run's metrics session
+
+	// Evaluation of the final checkpoint, with -suite
+	Checkpoint string       `json:"checkpoint,omitempty"`
+	Eval       *eval.Result `json:"eval,omitempty"`
+}
+
+// group aggregates the runs of one config across seeds
+type group struct {
+	Name        string
+	Runs        int
+	Failed      int
+	Reward      float64 // Mean over successful runs of the final average reward
+	RewardStd   float64
+	Success     float64 // Mean over successful runs of the final success rate
+	SuccessStd  float64
+	BestBalance int // Best balance time reached by any run
+}
+
+func main() {
+	seedsFlag := flag.String("seeds", "1,2,3", "Comma-separated seeds to train every config with")
+	configsFlag := flag.String("configs", "", "Comma-separated cmd/learning config files (.json, .yaml or .toml) to train, each with every seed (empty = defaults only)")
+	outputFlag := flag.String("output", filepath.Join("output", "orchestrate"), "Directory holding one subdirectory per run and the summary")
+	parallelFlag := flag.Int("parallel", runtime.NumCPU(), "Runs to train at once")
+	learnerFlag := flag.String("learner", "", "cmd/learning binary to run (empty = build it into <output>/bin)")
+	windowFlag := flag.Int("window", 20, "Episodes the final reward and success rate are averaged over")
+	suiteFlag := flag.String("suite", "standard", "Evaluate each run's final checkpoint on this suite (standard or robustness) and report the variance across seeds (empty to skip)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [-- cmd/learning flags for every run]\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Trains each config with each seed as a separate cmd/learning process, in its own")
+		fmt.Fprintln(flag.CommandLine.Output(), "directory with its own metrics database and checkpoints, then summarizes the runs.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Orchestrate] ", log.LstdFlags)
+
+	seeds, err := parseSeeds(*seedsFlag)
+	if err != nil {
+		logger.Fatalf("Invalid -seeds: %v", err)
+	}
+	var configs []string
+	if *configsFlag != "" {
+		configs = strings.Split(*configsFlag, ",")
+	}
+	runs, err := planRuns(*outputFlag, configs, seeds)
+	if err != nil {
+		logger.Fatalf("Invalid -configs: %v", err)
+	}
+	suite, ok := eval.Suites()[*suiteFlag]
+	if !ok && *suiteFlag != "" {
+		logger.Fatalf("Unknown suite %q", *suiteFlag)
+	}
+
+	learner := *learnerFlag
+	if learner == "" {
+		learner = filepath.Join(*outputFlag, "bin", "learning")
+		logger.Printf("Building cmd/learning into %s", learner)
+		build := exec.Command("go", "build", "-o", learner, "./cmd/learning")
+		build.Stdout, build.Stderr = os.Stdout, os.Stderr
+		if err := build.Run(); err != nil {
+			logger.Fatalf("Failed to build cmd/learning (run from the repository root, or pass -learner): %v", err)
+		}
+	}
+	learner, err = filepath.Abs(learner)
+	if err != nil {
+		logger.Fatalf("Failed to resolve -learner: %v", err)
+	}
+
+	// An interrupt reaches the running learners too, which save their
+	// progress; runs not yet started are skipped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Printf("Training %d runs, %d at a time", len(runs), max(1, *parallelFlag))
+	start := time.Now()
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(1, *parallelFlag))
+	for i := range runs {
+		wg.Add(1)
+		go func(r *run) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				r.Error = "not started: interrupted"
+				return
+			}
+			if ctx.Err() != nil {
+				r.Error = "not started: interrupted"
+				return
+			}
+			train(learner, r, flag.Args(), *windowFlag)
+			if r.Error == "" && *suiteFlag != "" {
+				evaluate(r, suite)
+			}
+			if r.Error != "" {
+				logger.Printf("%s seed %d failed after %s: %s", r.Name, r.Seed, r.Duration.Round(time.Second), r.Error)
+			} else {
+				logger.Printf("%s seed %d finished in %s", r.Name, r.Seed, r.Duration.Round(time.Second))
+			}
+		}(&runs[i])
+	}
+	wg.Wait()
+	logger.Printf("All runs done in %s", time.Since(start).Round(time.Second))
+
+	groups := summarize(runs)
+	printSummary(runs, groups, *windowFlag)
+	if *suiteFlag != "" {
+		printSeedVariance(runs, *suiteFlag)
+	}
+
+	summaryPath := filepath.Join(*outputFlag, "summary.json")
+	data, err := json.MarshalIndent(map[string]interface{}{"runs": runs, "window": *windowFlag}, "", "  ")
+	if err == nil {
+		err = os.WriteFile(summaryPath, data, 0644)
+	}
+	if err != nil {
+		logger.Fatalf("Failed to save summary: %v", err)
+	}
+	fmt.Printf("\nSummary saved to %s\n", summaryPath)
+
+	for _, g := range groups {
+		if g.Failed > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+// parseSeeds parses a comma-separated list of seeds
+func parseSeeds(s string) ([]int64, error) {
+	var seeds []int64
+	for _, field := range strings.Split(s, ",") {
+		seed, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a seed", field)
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+// planRuns lays out every config and seed pair in its own directory,
+// <output>/<config name>/seed-<seed>
+func planRuns(output string, configs []string, seeds []int64) ([]run, error) {
+	if len(configs) == 0 {
+		configs = []string{""}
+	}
+	names := make(map[string]string)
+	var runs []run
+	for _, config := range configs {
+		name := "default"
+		if config != "" {
+			if _, err := os.Stat(config); err != nil {
+				return nil, err
+			}
+			name = strings.TrimSuffix(filepath.Base(config), filepath.Ext(config))
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("%q and %q would share the run directory %s", other, config, name)
+		}
+		names[name] = config
+		for _, seed := range seeds {
+			runs = append(runs, run{
+				Name:   name,
+				Config: config,
+				Seed:   seed,
+				Dir:    filepath.Join(output, name, fmt.Sprintf("seed-%d", seed)),
+			})
+		}
+	}
+	return runs, nil
+}
+
+// train runs cmd/learning for r, logging its output to <dir>/run.log, and
+// reads its final results from <dir>/metrics.db. Flags in extra are passed
+// to every run; the run's own -output, -seed and -config come last so they win
+func train(learner string, r *run, extra []string, window int) {
+	start := time.Now()
+	defer func() { r.Duration = time.Since(start) }()
+
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		r.Error = err.Error()
+		return
+	}
+	logFile, err := os.Create(filepath.Join(r.Dir, "run.log"))
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+	defer logFile.Close()
+
+	args := append(append([]string{"-quiet"}, extra...), "-output", r.Dir, "-seed", strconv.FormatInt(r.Seed, 10))
+	if r.Config != "" {
+		args = append(args, "-config", r.Config)
+	}
+	cmd := exec.Command(learner, args...)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	if err := cmd.Run(); err != nil {
+		r.Error = fmt.Sprintf("%v, see %s", err, logFile.Name())
+		return
+	}
+
+	stats, err := finalStats(filepath.Join(r.Dir, "metrics.db"), window)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+	r.Stats = stats
+}
+
+// finalStats returns the final statistics of the latest session in a run's
+// metrics database
+func finalStats(path string, window int) (metrics.SessionStats, error) {
+	if _, err := os.Stat(path); err != nil {
+		return metrics.SessionStats{}, fmt.Errorf("no metrics database, was -memory-metrics passed? %w", err)
+	}
+	db, err := metrics.NewDB(path)
+	if err != nil {
+		return metrics.SessionStats{}, err
+	}
+	defer db.Close()
+
+	sessions, err := db.ListSessions()
+	if err != nil {
+		return metrics.SessionStats{}, err
+	}
+	if len(sessions) == 0 {
+		return metrics.SessionStats{}, fmt.Errorf("%s has no sessions", path)
+	}
+	latest := sessions[0]
+	for _, s := range sessions[1:] {
+		if s.StartTime.After(latest.StartTime) {
+			latest = s
+		}
+	}
+	comparison, err := db.CompareSessions([]string{latest.SessionID}, window)
+	if err != nil {
+		return metrics.SessionStats{}, err
+	}
+	return comparison.Stats[0], nil
+}
+
+// summarize groups the runs by config, in the order the configs were given
+func summarize(runs []run) []group {
+	var groups []group
+	index := make(map[string]int)
+	rewards := make(map[string][]float64)
+	successes := make(map[string][]float64)
+	for _, r := range runs {
+		i, ok := index[r.Name]
+		if !ok {
+			i = len(groups)
+			index[r.Name] = i
+			groups = append(groups, group{Name: r.Name})
+		}
+		groups[i].Runs++
+		if r.Error != "" {
+			groups[i].Failed++
+			continue
+		}
+		rewards[r.Name] = append(rewards[r.Name], r.Stats.FinalAvgReward)
+		successes[r.Name] = append(successes[r.Name], r.Stats.FinalSuccessRate)
+		groups[i].BestBalance = max(groups[i].BestBalance, r.Stats.BestBalanceTime)
+	}
+	for i := range groups {
+		g := &groups[i]
+		g.Reward, g.RewardStd = meanStd(rewards[g.Name])
+		g.Success, g.SuccessStd = meanStd(successes[g.Name])
+	}
+	return groups
+}
+
+// meanStd returns the mean and sample standard deviation of values
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// printSummary prints every run, then each config's results across seeds
+func printSummary(runs []run, groups []group, window int) {
+	fmt.Printf("\n=== RUNS (final stats over the last %d episodes) ===\n", window)
+	fmt.Printf("%-20s %8s %9s %12s %9s %8s %10s  %s\n", "Config", "Seed", "Episodes", "Final Reward", "Success", "Best", "Duration", "Status")
+	for _, r := range runs {
+		status := "ok"
+		if r.Error != "" {
+			status = r.Error
+		}
+		fmt.Printf("%-20s %8d %9d %12.2f %8.1f%% %8d %10s  %s\n",
+			r.Name, r.Seed, r.Stats.Episodes, r.Stats.FinalAvgReward, r.Stats.FinalSuccessRate*100,
+			r.Stats.BestBalanceTime, r.Duration.Round(time.Second), status)
+	}
+
+	fmt.Println("\n=== CONFIGS (mean ± std across seeds) ===")
+	fmt.Printf("%-20s %6s %7s %20s %18s %8s\n", "Config", "Runs", "Failed", "Final Reward", "Success", "Best")
+	for _, g := range groups {
+		fmt.Printf("%-20s %6d %7d %11.2f ± %6.2f %9.1f%% ± %4.1f%% %8d\n",
+			g.Name, g.Runs, g.Failed, g.Reward, g.RewardStd, g.Success*100, g.SuccessStd*100, g.BestBalance)
+	}
+}
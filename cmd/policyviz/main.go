@@ -0,0 +1,87 @@
+// Command policyviz renders what a network checkpoint learned: the force it
+// applies and the value Predict assigns across a grid of (angle, angular
+// velocity) states, each saved as a PNG heatmap
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/heatmap"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+func main() {
+	outFlag := flag.String("out", "", "Output path prefix; writes <prefix>_policy.png and <prefix>_value.png (default: the checkpoint path without .json)")
+	widthFlag := flag.Int("width", 360, "Heatmap width in pixels, one angle sample per pixel")
+	heightFlag := flag.Int("height", 240, "Heatmap height in pixels, one angular velocity sample per pixel")
+	angleFlag := flag.Float64("angle-range", math.Pi, "Largest angle from upright to sample, in radians")
+	velFlag := flag.Float64("vel-range", 8.0, "Largest angular velocity to sample, in rad/s")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] checkpoint.json\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[PolicyViz] ", log.LstdFlags)
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *widthFlag < 2 || *heightFlag < 2 || *angleFlag <= 0 || *velFlag <= 0 {
+		logger.Fatalf("-width and -height must be at least 2 and the ranges positive")
+	}
+
+	path := flag.Arg(0)
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+	if err := network.LoadFromFile(path); err != nil {
+		logger.Fatalf("Failed to load %s: %v", path, err)
+	}
+
+	prefix := *outFlag
+	if prefix == "" {
+		prefix = strings.TrimSuffix(path, ".json")
+	}
+
+	maxForce := network.GetActionSpace().MaxForce
+	policy := heatmap.Sweep(*widthFlag, *heightFlag, *angleFlag, *velFlag, network.ForwardBatch)
+	value := heatmap.Sweep(*widthFlag, *heightFlag, *angleFlag, *velFlag, network.PredictBatch)
+
+	outputs := []struct {
+		name  string
+		grid  heatmap.Grid
+		limit float64
+		title string
+		unit  string
+	}{
+		{"policy", policy, maxForce, "Force (red pushes right, blue left)", "N"},
+		{"value", value, 1, "Predicted value (red good, blue bad)", ""},
+	}
+	for _, out := range outputs {
+		file := prefix + "_" + out.name + ".png"
+		img := heatmap.Render(out.grid, out.limit, out.title, out.unit)
+		if err := heatmap.WritePNG(file, img); err != nil {
+			logger.Fatalf("Failed to write %s: %v", file, err)
+		}
+		lo, hi := out.grid.Bounds()
+		fmt.Println(strings.TrimSpace(fmt.Sprintf("%-6s %s  range [%+.3f, %+.3f] %s", out.name, file, lo, hi, out.unit)))
+	}
+
+	saturated := 0
+	for _, row := range policy.Values {
+		for _, force := range row {
+			if maxForce > 0 && math.Abs(force) >= 0.99*maxForce {
+				saturated++
+			}
+		}
+	}
+	fmt.Printf("%.1f%% of states use the full %.1fN force\n",
+		100*float64(saturated)/float64(*widthFlag**heightFlag), maxForce)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/replay"
+)
+
+func main() {
+	fileFlag := flag.String("file", "", "Path to a recorded episode (.jsonl)")
+	tickFlag := flag.Int("tick", -1, "Tick to inspect (default: last tick)")
+	windowFlag := flag.Int("window", 10, "Number of frames to show on each side of -tick")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Replay] ", log.LstdFlags)
+
+	if *fileFlag == "" {
+		logger.Fatalf("Missing required -file flag")
+	}
+
+	episode, err := replay.Load(*fileFlag)
+	if err != nil {
+		logger.Fatalf("Failed to load recording: %v", err)
+	}
+
+	printSummary(episode)
+
+	if len(episode.Frames) == 0 {
+		return
+	}
+
+	tick := *tickFlag
+	if tick < 0 || tick >= len(episode.Frames) {
+		tick = len(episode.Frames) - 1
+	}
+	printFrames(episode, tick, *windowFlag)
+}
+
+// printSummary prints the recording header and whole-episode statistics
+func printSummary(episode *replay.Episode) {
+	fmt.Println("\n=== RECORDING ===")
+	fmt.Printf("Episode: %d\n", episode.Header.Episode)
+	if episode.Header.Label != "" {
+		fmt.Printf("Label: %s\n", episode.Header.Label)
+	}
+	fmt.Printf("Recorded: %s\n", episode.Header.Recorded.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Frames: %d (%.2fs simulated)\n",
+		len(episode.Frames), float64(len(episode.Frames))*episode.Header.Config.DeltaTime)
+
+	var totalReward, maxAngle, maxForce float64
+	for _, frame := range episode.Frames {
+		totalReward += frame.Reward
+		maxAngle = math.Max(maxAngle, math.Abs(frame.State.AngleRadians))
+		maxForce = math.Max(maxForce, math.Abs(frame.Action))
+	}
+	fmt.Printf("Total Reward: %.4f\n", totalReward)
+	fmt.Printf("Max |Angle|: %.4f rad\n", maxAngle)
+	fmt.Printf("Max |Force|: %.4f N\n", maxForce)
+}
+
+// printFrames prints the frames surrounding tick as a table
+func printFrames(episode *replay.Episode, tick, window int) {
+	start := tick - window
+	if start < 0 {
+		start = 0
+	}
+	end := tick + window
+	if end >= len(episode.Frames) {
+		end = len(episode.Frames) - 1
+	}
+
+	fmt.Printf("\n=== FRAMES %d-%d ===\n", start, end)
+	fmt.Printf("%6s %10s %10s %10s %10s %9s %9s\n",
+		"Tick", "CartPos", "CartVel", "Angle", "AngVel", "Force", "Reward")
+	for i := start; i <= end; i++ {
+		frame := episode.Frames[i]
+		marker := " "
+		if i == tick {
+			marker = ">"
+		}
+		done := ""
+		if frame.Done {
+			done = " done"
+		}
+		fmt.Printf("%s%5d %10.4f %10.4f %10.4f %10.4f %9.4f %9.4f%s\n",
+			marker, frame.Tick,
+			frame.State.CartPosition, frame.State.CartVelocity,
+			frame.State.AngleRadians, frame.State.AngularVel,
+			frame.Action, frame.Reward, done)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/server"
+)
+
+func main() {
+	addrFlag := flag.String("addr", ":50051", "Address to listen on")
+	checkpointFlag := flag.String("checkpoint", "", "Network checkpoint to serve (default: freshly initialized network)")
+	noPolicyFlag := flag.Bool("no-policy", false, "Serve only the Environment service")
+	presetFlag := flag.String("preset", env.ClassicPreset, "Pendulum physics preset for Reset requests without a config: "+strings.Join(env.PresetNames(), ", "))
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[Server] ", log.LstdFlags)
+
+	defaults, err := env.Preset(*presetFlag)
+	if err != nil {
+		logger.Fatalf("Invalid -preset: %v", err)
+	}
+
+	var network *neural.Network
+	if !*noPolicyFlag {
+		network = neural.NewNetwork()
+		network.SetLogger(log.New(io.Discard, "", 0))
+		if *checkpointFlag != "" {
+			if err := network.LoadFromFile(*checkpointFlag); err != nil {
+				logger.Fatalf("Failed to load checkpoint: %v", err)
+			}
+			logger.Printf("Serving policy from %s", *checkpointFlag)
+		}
+	}
+
+	listener, err := net.Listen("tcp", *addrFlag)
+	if err != nil {
+		logger.Fatalf("Failed to listen on %s: %v", *addrFlag, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	srv := server.New(network, logger)
+	srv.SetDefaultConfig(defaults)
+	srv.Register(grpcServer)
+
+	// Stop accepting calls and drain in-flight ones on interrupt
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		logger.Printf("Shutting down")
+		grpcServer.GracefulStop()
+	}()
+
+	logger.Printf("Listening on %s", listener.Addr())
+	if err := grpcServer.Serve(listener); err != nil {
+		logger.Fatalf("Server error: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/ensemble"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/sound"
+)
+
+// Volumes of the audio cues, from 0 to 1; the tone plays continuously so it
+// stays in the background
+const (
+	toneVolume  = 0.15
+	clickVolume = 0.5
+	chimeVolume = 0.5
+)
+
+// soundCues plays audio feedback for the displayed network: a tone whose
+// pitch rises as the pendulum leans, a click when an episode fails and a
+// chime when an episode beats the ensemble's best. The audio device is only
+// opened the first time the cues are enabled
+type soundCues struct {
+	enabled    bool
+	context    *audio.Context
+	tone       *sound.Tone
+	tonePlayer *audio.Player
+	click      []byte
+	chime      []byte
+	networkID  int // Displayed network at the last update
+	episodes   int // Its episodes at the last update
+	bestTicks  int // Longest episode at the last update
+}
+
+// toggle turns the cues on or off
+func (s *soundCues) toggle() error {
+	if s.context == nil {
+		s.context = audio.CurrentContext()
+		if s.context == nil {
+			s.context = audio.NewContext(sound.SampleRate)
+		}
+		s.tone = sound.NewTone(s.context.SampleRate())
+		player, err := s.context.NewPlayerF32(s.tone)
+		if err != nil {
+			return err
+		}
+		player.SetVolume(toneVolume)
+		s.tonePlayer = player
+		s.click = sound.Click(s.context.SampleRate())
+		s.chime = sound.Chime(s.context.SampleRate())
+	}
+
+	s.enabled = !s.enabled
+	if s.enabled {
+		s.tonePlayer.Play()
+	} else {
+		s.tonePlayer.Pause()
+	}
+	return nil
+}
+
+// update follows the displayed network after the simulation advanced. A new
+// best episode chimes instead of clicking, though it also ended the episode
+func (s *soundCues) update(best *ensemble.NetworkInstance) {
+	ended := best.ID == s.networkID && best.Episodes > s.episodes
+	record := best.MaxTicks > s.bestTicks
+	s.networkID, s.episodes = best.ID, best.Episodes
+	s.bestTicks = max(s.bestTicks, best.MaxTicks)
+	if !s.enabled {
+		return
+	}
+
+	s.tone.SetFrequency(sound.Pitch(env.UprightOffset(best.Pendulum.GetState().AngleRadians)))
+	switch {
+	case record:
+		s.play(s.chime, chimeVolume)
+	case ended:
+		s.play(s.click, clickVolume)
+	}
+}
+
+// reset forgets the best episode, e.g. after the networks are reset, so the
+// next record chimes again
+func (s *soundCues) reset() {
+	s.bestTicks = 0
+}
+
+// play starts a one-shot cue, which is released once it has played
+func (s *soundCues) play(cue []byte, volume float64) {
+	player := s.context.NewPlayerF32FromBytes(cue)
+	player.SetVolume(volume)
+	player.Play()
+}
@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+// simClock tracks simulated time separately from wall-clock time. Every
+// step advances the simulation by the physics DeltaTime no matter how many
+// steps are run per rendered frame
+type simClock struct {
+	dt    float64   // Simulated seconds per step
+	steps int64     // Steps simulated so far
+	start time.Time // Wall-clock time the clock started
+}
+
+func newSimClock(dt float64) *simClock {
+	return &simClock{dt: dt, start: time.Now()}
+}
+
+// Advance records steps simulated since the last call
+func (c *simClock) Advance(steps int) {
+	c.steps += int64(steps)
+}
+
+// Steps returns the number of steps simulated so far
+func (c *simClock) Steps() int64 {
+	return c.steps
+}
+
+// SimTime returns the simulated time in seconds
+func (c *simClock) SimTime() float64 {
+	return float64(c.steps) * c.dt
+}
+
+// Speedup returns simulated seconds per wall-clock second
+func (c *simClock) Speedup() float64 {
+	elapsed := time.Since(c.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return c.SimTime() / elapsed
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"maps"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/curriculum"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/ensemble"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/render"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/reward"
+)
+
+// trainingSettings are the options a side of the window trains with; a
+// split-screen comparison overrides some of them for the right-hand side
+type trainingSettings struct {
+	curriculum   bool
+	actionSpace  string
+	actionBins   int
+	fitness      string
+	learningRate float64
+	shaping      reward.Shaping
+	frames       int
+	recurrent    bool
+	strategy     string
+	perturb      float64
+}
+
+// String summarizes the settings for the label above each pendulum
+func (s trainingSettings) String() string {
+	actions := s.actionSpace
+	if actions == neural.DiscreteActions {
+		actions = fmt.Sprintf("%s (%d bins)", actions, s.actionBins)
+	}
+	label := fmt.Sprintf("lr %g | %s fitness | %s actions", s.learningRate, s.fitness, actions)
+	if len(s.shaping) > 0 {
+		label += " | penalties " + s.shaping.String()
+	}
+	if s.frames > 1 {
+		label += fmt.Sprintf(" | %d frames", s.frames)
+	}
+	if s.recurrent {
+		label += " | recurrent"
+	}
+	if s.curriculum {
+		label += " | curriculum"
+	}
+	if s.strategy == ensemble.PBTStrategy {
+		label += fmt.Sprintf(" | pbt ±%g", s.perturb)
+	}
+	return label
+}
+
+// override returns the settings with a comma-separated list of key=value
+// changes applied, e.g. "lr=0.01,fitness=reward". Reward term names set
+// the term's weight, e.g. "jerk=0.1"
+func (s trainingSettings) override(spec string) (trainingSettings, error) {
+	s.shaping = maps.Clone(s.shaping)
+	for _, change := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(change), "=")
+		if !ok {
+			return s, fmt.Errorf("comparison setting %q is not key=value", change)
+		}
+		var err error
+		switch key {
+		case "lr":
+			s.learningRate, err = strconv.ParseFloat(value, 64)
+		case "fitness":
+			s.fitness = value
+		case "action-space":
+			s.actionSpace = value
+		case "action-bins":
+			s.actionBins, err = strconv.Atoi(value)
+		case "curriculum":
+			s.curriculum, err = strconv.ParseBool(value)
+		case "frames":
+			s.frames, err = strconv.Atoi(value)
+		case "recurrent":
+			s.recurrent, err = strconv.ParseBool(value)
+		case "strategy":
+			s.strategy = value
+		case "perturb":
+			s.perturb, err = strconv.ParseFloat(value, 64)
+		default:
+			if !reward.IsTerm(key) {
+				return s, fmt.Errorf("unknown comparison setting %q (want lr, fitness, action-space, action-bins, curriculum, frames, recurrent, strategy, perturb or a reward term: %s)",
+					key, strings.Join(reward.TermNames(), ", "))
+			}
+			if s.shaping == nil {
+				s.shaping = reward.Shaping{}
+			}
+			s.shaping[key], err = strconv.ParseFloat(value, 64)
+		}
+		if err != nil {
+			return s, fmt.Errorf("invalid comparison setting %s: %w", change, err)
+		}
+	}
+	return s, nil
+}
+
+// newEnsemble creates the window's ensemble of 10 networks for the settings
+func newEnsemble(settings trainingSettings, preset string, pendulumConfig env.Config, rng *rand.Rand, logger *log.Logger) (*ensemble.Ensemble, error) {
+	actionSpace, err := neural.NewActionSpace(settings.actionSpace, settings.actionBins)
+	if err != nil {
+		return nil, err
+	}
+	if settings.learningRate <= 0 {
+		return nil, fmt.Errorf("learning rate must be positive, got %g", settings.learningRate)
+	}
+
+	config := ensemble.NewDefaultConfig()
+	config.NetworkCount = 10 // Train 10 networks simultaneously
+	config.ActionSpace = actionSpace
+	config.Preset = preset
+	config.Fitness = settings.fitness
+	config.LearningRate = settings.learningRate
+	config.RewardShaping = settings.shaping
+	if settings.frames < 0 {
+		return nil, fmt.Errorf("stacked frames must not be negative, got %d", settings.frames)
+	}
+	config.Features.Frames = settings.frames
+	config.Recurrent = settings.recurrent
+	if err := ensemble.ValidateStrategy(settings.strategy); err != nil {
+		return nil, err
+	}
+	if settings.perturb < 0 || settings.perturb >= 1 {
+		return nil, fmt.Errorf("perturb factor must be in [0, 1), got %g", settings.perturb)
+	}
+	config.Strategy = settings.strategy
+	config.PerturbFactor = settings.perturb
+	if err := settings.shaping.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := ensemble.NewFitness(config); err != nil {
+		return nil, err
+	}
+
+	e := ensemble.NewEnsemble(config, pendulumConfig, logger)
+	if settings.curriculum {
+		e.SetCurriculum(curriculum.NewController(curriculum.New(curriculum.NewDefaultConfig(), rng, logger), nil))
+	}
+	return e, nil
+}
+
+// comparison is a second ensemble trained in lockstep with the main one and
+// drawn to its right, so the two configurations learn side by side
+type comparison struct {
+	ensemble *ensemble.Ensemble
+	drawer   *render.Drawer
+	view     view
+	left     *ebiten.Image // Offscreen canvas for the main ensemble
+	right    *ebiten.Image // Offscreen canvas for the comparison
+}
+
+func newComparison(e *ensemble.Ensemble, drawer *render.Drawer) *comparison {
+	return &comparison{
+		ensemble: e,
+		drawer:   drawer,
+		left:     ebiten.NewImage(render.ScreenWidth, render.ScreenHeight),
+		right:    ebiten.NewImage(render.ScreenWidth, render.ScreenHeight),
+	}
+}
+
+// draw renders both sides of the split screen, main ensemble on the left
+func (c *comparison) draw(screen *ebiten.Image, main *ensemble.Ensemble, mainDrawer *render.Drawer) {
+	c.left.Clear()
+	drawEnsemble(c.left, main, mainDrawer)
+	c.right.Clear()
+	drawEnsemble(c.right, c.ensemble, c.drawer)
+
+	screen.DrawImage(c.left, nil)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(render.ScreenWidth, 0)
+	screen.DrawImage(c.right, op)
+	ebitenutil.DrawRect(screen, render.ScreenWidth-1, 0, 2, render.ScreenHeight, color.RGBA{200, 200, 200, 255})
+}
+
+// view tracks which network and episode a drawer's strip chart and phase
+// portrait are showing
+type view struct {
+	stripID      int
+	phaseEpisode int
+}
+
+// record adds the displayed network's latest step to the drawer, clearing
+// the strip chart when another network takes over the display and the phase
+// portrait when a new episode starts
+func (v *view) record(drawer *render.Drawer, best *ensemble.NetworkInstance, simTime float64) {
+	switched := best.ID != v.stripID
+	if switched {
+		drawer.ClearStripChart()
+		v.stripID = best.ID
+	}
+	if switched || best.Episodes != v.phaseEpisode {
+		drawer.ClearTrajectory()
+		v.phaseEpisode = best.Episodes
+	}
+	drawer.RecordStep(simTime, best.Pendulum.GetState(), best.LastForce, best.LastStepReward)
+}
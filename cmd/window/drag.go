@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// dragFullScale is how many pixels the pointer must move from where it was
+// pressed to push the cart with the full MaxForce
+const dragFullScale = 200.0
+
+// drag turns a horizontal mouse or touch drag into a force on the cart,
+// proportional to how far the pointer has moved since it was pressed, so the
+// demo can be pushed around without keyboard focus
+type drag struct {
+	active  bool
+	touched bool           // Dragged by touch rather than the mouse
+	touch   ebiten.TouchID // Touch being followed when touched
+	originX int            // Where the pointer was pressed
+}
+
+// update follows the pointer and returns the force to apply in N, positive
+// to the right, or zero when nothing is being dragged
+func (d *drag) update(maxForce float64) float64 {
+	if !d.active {
+		d.start()
+	}
+	if !d.active {
+		return 0
+	}
+
+	var x int
+	if d.touched {
+		if inpututil.IsTouchJustReleased(d.touch) {
+			d.active = false
+			return 0
+		}
+		x, _ = ebiten.TouchPosition(d.touch)
+	} else {
+		if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			d.active = false
+			return 0
+		}
+		x, _ = ebiten.CursorPosition()
+	}
+	return math.Max(-1, math.Min(float64(x-d.originX)/dragFullScale, 1)) * maxForce
+}
+
+// start begins a drag at a new touch, or else at a left mouse press
+func (d *drag) start() {
+	if touches := inpututil.AppendJustPressedTouchIDs(nil); len(touches) > 0 {
+		d.active, d.touched, d.touch = true, true, touches[0]
+		d.originX, _ = ebiten.TouchPosition(d.touch)
+		return
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		d.active, d.touched = true, false
+		d.originX, _ = ebiten.CursorPosition()
+	}
+}
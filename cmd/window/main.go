@@ -2,24 +2,48 @@ package main
 
 import (
 	"errors"
+	"flag"
+	"math"
+	"math/rand"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/config"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/control"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/ensemble"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/evolution"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/render"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/replay"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/reward"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/scenario"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
 )
 
 var (
 	mplusNormalFont font.Face
 )
 
+// Interactive stress test settings
+const (
+	randomAngle      = 0.3 // Largest angle in radians for N (randomize)
+	randomAngularVel = 1.0 // Largest angular velocity in rad/s for N
+	disturbImpulse   = 0.5 // Impulse in N·s on the pendulum bob for D (disturb)
+)
+
 func init() {
 	tt, err := opentype.Parse(fonts.MPlus1pRegular_ttf)
 	if err != nil {
@@ -36,31 +60,51 @@ func init() {
 	}
 }
 
+// Errors that end the game normally, saving the ensemble on the way out
+var (
+	errWindowClosed = errors.New("window closed")
+	errInterrupted  = errors.New("interrupted")
+)
+
 type Game struct {
 	ensemble     *ensemble.Ensemble
 	drawer       *render.Drawer
 	logger       *logger.Logger
 	networkPath  string   // Path to save/load network state
+	ensembleDir  string   // Directory to save the whole ensemble to
+	player       *replay.Player // Set when replaying a recorded episode
+	playback     *playback      // Pause, single-step and speed controls for training
+	capture      *render.EpisodeCapture // Optional GIF capture of the displayed network's episodes
+	captureID    int            // Network whose episode is being captured
+	captureEp    int            // Episode number being captured
+	captureTicks int            // Latest tick count of the captured episode
+	advanced     bool           // Simulation stepped since the last frame was drawn
+	clock        *simClock      // Simulated time at the physics DeltaTime
+	rng          *rand.Rand     // Randomizes initial conditions and disturbance directions
+	view         view           // Network and episode the strip chart and phase portrait show
+	compare      *comparison    // Second ensemble shown to the right in split-screen mode
+	drag         drag           // Mouse or touch drag pushing the carts by hand
+	sound        soundCues      // Optional audio feedback on the displayed network
+	scenario     *scenarioRun   // Set when every episode plays a scenario file
+	demos        *replay.Recorder // Optional recording of the displayed network's steps pushed by hand
+	settings     config.Settings // Resolved command settings, saved with the ensemble
+	interrupted  <-chan struct{} // Closed on SIGINT or SIGTERM to end the game like closing the window
 }
 
-func NewGame(gameLogger *logger.Logger) *Game {
-	// Create pendulum configuration
-	pendulumConfig := env.Config{
-		CartMass:     5.0,   // kg
-		PendulumMass: 1.0,   // kg
-		Length:       1.0,    // m
-		Gravity:      9.81,   // m/s²
-		MaxForce:     10.0,   // N
-		DeltaTime:    0.016,  // s (60 fps)
-		TrackLength:  4.0,    // m
+func NewGame(gameLogger *logger.Logger, settings trainingSettings, preset string, termination env.TerminationConfig) (*Game, error) {
+	// Look up the pendulum configuration
+	pendulumConfig, err := env.Preset(preset)
+	if err != nil {
+		return nil, err
 	}
-	
-	// Create ensemble configuration
-	ensembleConfig := ensemble.NewDefaultConfig()
-	ensembleConfig.NetworkCount = 10 // Train 10 networks simultaneously
+	pendulumConfig.Termination = termination
 	
 	// Create ensemble
-	ensemble := ensemble.NewEnsemble(ensembleConfig, pendulumConfig, gameLogger.GetStandardLogger())
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ensemble, err := newEnsemble(settings, preset, pendulumConfig, rng, gameLogger.GetStandardLogger())
+	if err != nil {
+		return nil, err
+	}
 	
 	// Set up network save path in user's home directory
 	homeDir, err := os.UserHomeDir()
@@ -69,19 +113,44 @@ func NewGame(gameLogger *logger.Logger) *Game {
 		homeDir = "."
 	}
 	networkPath := filepath.Join(homeDir, ".inverted_pendulum", "network.json")
+	ensembleDir := filepath.Join(homeDir, ".inverted_pendulum", "ensemble")
 
 	return &Game{
 		ensemble:     ensemble,
 		drawer:       render.NewDrawer(mplusNormalFont),
 		logger:       gameLogger,
 		networkPath:  networkPath,
+		ensembleDir:  ensembleDir,
+		playback:     newPlayback(1),
+		capture:      render.NewEpisodeCapture(render.NewDefaultCaptureConfig(), gameLogger.GetStandardLogger()),
+		clock:        newSimClock(pendulumConfig.DeltaTime),
+		rng:          rng,
+	}, nil
+}
+
+// saveEnsemble saves the ensemble and the settings that produced it
+func (g *Game) saveEnsemble() error {
+	if err := g.ensemble.SaveToDir(g.ensembleDir); err != nil {
+		return err
 	}
+	return config.Save(filepath.Join(g.ensembleDir, config.ResolvedFile), g.settings)
 }
 
 func (g *Game) Update() error {
 	// Check for window close
 	if ebiten.IsWindowBeingClosed() {
-		return errors.New("window closed")
+		return errWindowClosed
+	}
+	select {
+	case <-g.interrupted:
+		return errInterrupted
+	default:
+	}
+
+	// Replay mode only drives the recorded episode
+	if g.player != nil {
+		g.updateReplay()
+		return nil
 	}
 
 	// Handle network save/load
@@ -92,6 +161,11 @@ func (g *Game) Update() error {
 		} else {
 			g.logger.Info("Best network saved to %s", g.networkPath)
 		}
+		if err := g.saveEnsemble(); err != nil {
+			g.logger.Error("Failed to save ensemble: %v", err)
+		} else {
+			g.logger.Info("Ensemble saved to %s", g.ensembleDir)
+		}
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
 		// Load network into the best network instance
@@ -100,13 +174,94 @@ func (g *Game) Update() error {
 			g.logger.Error("Failed to load network: %v", err)
 		} else {
 			g.logger.Info("Network loaded from %s", g.networkPath)
+			if preset := bestNetwork.Network.GetPreset(); preset != "" && preset != g.ensemble.Config.Preset {
+				g.logger.Info("Loaded network was trained on the %q preset, not %q", preset, g.ensemble.Config.Preset)
+			}
 		}
 	}
 
-	// Update all networks in the ensemble
-	if err := g.ensemble.Step(); err != nil {
-		g.logger.Error("Ensemble step error: %v", err)
+	// Stress test the networks without restarting the process; both sides
+	// of a comparison get the same initial conditions and disturbances
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		for _, e := range g.ensembles() {
+			e.Reset()
+		}
+		g.capture.Discard()
+		g.sound.reset()
+		g.logger.Info("Networks reset to fresh weights")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		seed := g.rng.Int63()
+		for _, e := range g.ensembles() {
+			e.RandomizeStates(rand.New(rand.NewSource(seed)), randomAngle, randomAngularVel)
+		}
+		g.logger.Info("Pendulums restarted from random initial conditions")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		impulse := disturbImpulse
+		if g.rng.Intn(2) == 0 {
+			impulse = -impulse
+		}
+		for _, e := range g.ensembles() {
+			e.Disturb(impulse)
+		}
+		g.logger.Info("Applied a %+.2f N·s disturbance", impulse)
+	}
+
+	// Dragging pushes every cart by hand, so the demo works without a keyboard
+	humanForce := g.drag.update(g.ensemble.PendulumConfig.MaxForce)
+	for _, e := range g.ensembles() {
+		e.SetHumanForce(humanForce)
+	}
+	for _, drawer := range g.drawers() {
+		drawer.SetHumanForce(humanForce)
+	}
+
+	// Handle playback controls
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		g.playback.TogglePause()
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		g.playback.StepOnce()
+	case inpututil.IsKeyJustPressed(ebiten.KeyM):
+		g.playback.ToggleMaxSpeed()
+	case inpututil.IsKeyJustPressed(ebiten.KeyH):
+		for _, drawer := range g.drawers() {
+			drawer.TogglePhaseHeatmap()
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyA):
+		g.toggleSound()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual), inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd):
+		g.playback.Faster()
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus), inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract):
+		g.playback.Slower()
+	}
+	for _, drawer := range g.drawers() {
+		drawer.SetPlayback(g.playback.paused, g.playback.Speed(), g.playback.MaxSpeed())
+	}
+
+	// Update all networks in the ensemble, several times per frame when fast-forwarding
+	steps := 0
+	if g.playback.MaxSpeed() {
+		// Train headless until the frame budget runs out; only the final state is drawn
+		deadline := time.Now().Add(maxSpeedBudget)
+		for time.Now().Before(deadline) {
+			g.runSteps(g.playback.stepsPerFrame)
+			steps += g.playback.stepsPerFrame
+		}
+	} else {
+		steps = g.playback.Steps()
+		g.runSteps(steps)
+	}
+	for _, drawer := range g.drawers() {
+		drawer.SetSimClock(g.clock.SimTime(), g.clock.Speedup())
 	}
+	if steps == 0 {
+		return nil
+	}
+	g.advanced = true
+	g.trackCapture()
+	g.sound.update(g.ensemble.GetBestNetwork())
 	
 	// Get best network for visualization
 	bestNetwork := g.ensemble.GetBestNetwork()
@@ -116,16 +271,167 @@ func (g *Game) Update() error {
 	
 	// Update ensemble statistics
 	g.drawer.UpdateEnsembleStats(g.ensemble.GetAllNetworkStats())
+	if g.compare != nil {
+		g.compare.drawer.UpdateTrainingStats(g.compare.ensemble.GetBestNetwork().Trainer)
+		g.compare.drawer.UpdateEnsembleStats(g.compare.ensemble.GetAllNetworkStats())
+	}
+	drawers := g.drawers()
+	for i, e := range g.ensembles() {
+		if difficulty, ok := e.CurriculumDifficulty(); ok {
+			drawers[i].SetDifficulty(difficulty)
+		}
+	}
 
 	return nil
 }
 
+// runSteps advances every network in the ensemble, and in the comparison
+// ensemble if any, by n fixed DeltaTime steps in lockstep, recording each
+// step of the displayed networks in their strip charts
+func (g *Game) runSteps(n int) {
+	for i := 0; i < n; i++ {
+		best := g.ensemble.GetBestNetwork()
+		state, running := best.Pendulum.GetState(), !best.Failed
+		if err := g.ensemble.Step(); err != nil {
+			g.logger.Error("Ensemble step error: %v", err)
+		}
+		if g.compare != nil {
+			if err := g.compare.ensemble.Step(); err != nil {
+				g.logger.Error("Comparison ensemble step error: %v", err)
+			}
+		}
+		g.clock.Advance(1)
+		if running {
+			g.recordDemonstration(best, state)
+		}
+
+		g.view.record(g.drawer, g.ensemble.GetBestNetwork(), g.clock.SimTime())
+		g.scoreScenario()
+		if g.compare != nil {
+			g.compare.view.record(g.compare.drawer, g.compare.ensemble.GetBestNetwork(), g.clock.SimTime())
+		}
+	}
+}
+
+// scoreScenario scores the step of the displayed network against the
+// scenario file, if any, and reports each finished episode's verdict
+func (g *Game) scoreScenario() {
+	if g.scenario == nil {
+		return
+	}
+	if verdict := g.scenario.record(g.ensemble.GetBestNetwork()); verdict != "" {
+		g.logger.Info("Scenario %s: %s", g.scenario.file.Name, verdict)
+		if g.compare == nil {
+			g.drawer.SetLabel(g.scenario.label())
+		}
+	}
+}
+
+// toggleSound turns the audio cues on or off
+func (g *Game) toggleSound() {
+	if err := g.sound.toggle(); err != nil {
+		g.logger.Error("Failed to start sound: %v", err)
+		return
+	}
+	if g.sound.enabled {
+		g.logger.Info("Sound on")
+	} else {
+		g.logger.Info("Sound off")
+	}
+}
+
+// recordDemonstration records a step of the displayed network that was
+// pushed by hand, with the state it started from and the force pushed, to
+// the -demos recording
+func (g *Game) recordDemonstration(best *ensemble.NetworkInstance, state env.State) {
+	if g.demos == nil || best.LastHumanForce == 0 {
+		return
+	}
+	if err := g.demos.Record(state, best.LastHumanForce, best.LastStepReward, best.Failed); err != nil {
+		g.logger.Error("Failed to record demonstration: %v", err)
+		g.demos.Close()
+		g.demos = nil
+	}
+}
+
+// ensembles returns the main ensemble followed by the comparison, if any
+func (g *Game) ensembles() []*ensemble.Ensemble {
+	if g.compare == nil {
+		return []*ensemble.Ensemble{g.ensemble}
+	}
+	return []*ensemble.Ensemble{g.ensemble, g.compare.ensemble}
+}
+
+// drawers returns the main drawer followed by the comparison's, if any
+func (g *Game) drawers() []*render.Drawer {
+	if g.compare == nil {
+		return []*render.Drawer{g.drawer}
+	}
+	return []*render.Drawer{g.drawer, g.compare.drawer}
+}
+
+// updateReplay handles playback controls for a recorded episode
+func (g *Game) updateReplay() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		g.player.TogglePause()
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		g.player.Next()
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		g.player.Prev()
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight), inpututil.IsKeyJustPressed(ebiten.KeyPageDown):
+		g.player.Scrub(50)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft), inpututil.IsKeyJustPressed(ebiten.KeyPageUp):
+		g.player.Scrub(-50)
+	default:
+		g.player.Update()
+	}
+}
+
+// trackCapture ends the captured episode when the displayed network
+// finishes it, and drops it when a different network takes over the display
+func (g *Game) trackCapture() {
+	best := g.ensemble.GetBestNetwork()
+	switch {
+	case best.ID != g.captureID:
+		g.capture.Discard()
+	case best.Episodes != g.captureEp:
+		g.capture.EndEpisode(g.captureEp, g.captureTicks)
+	}
+	g.captureID = best.ID
+	g.captureEp = best.Episodes
+	g.captureTicks = best.CurrentTicks
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
+	if g.player != nil {
+		episode := g.player.Episode()
+		g.drawer.DrawReplay(screen, g.player.Current(), episode.Header.Config,
+			g.player.Position(), g.player.Len(), g.player.Paused())
+		return
+	}
+
+	if g.compare != nil {
+		g.compare.draw(screen, g.ensemble, g.drawer)
+	} else {
+		drawEnsemble(screen, g.ensemble, g.drawer)
+	}
+	
+	// Capture only frames that show new simulation state
+	if g.advanced {
+		g.capture.AddFrame(screen)
+		g.advanced = false
+	}
+}
+
+// drawEnsemble draws an ensemble's best network, its training panels and
+// the ensemble statistics
+func drawEnsemble(screen *ebiten.Image, e *ensemble.Ensemble, drawer *render.Drawer) {
 	// Get the best network for visualization
-	bestNetwork := g.ensemble.GetBestNetwork()
+	bestNetwork := e.GetBestNetwork()
 	
 	// Draw the pendulum and network visualization
-	g.drawer.Draw(
+	drawer.Draw(
 		screen, 
 		bestNetwork.Pendulum, 
 		bestNetwork.Network, 
@@ -137,17 +443,70 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	)
 	
 	// Draw ensemble statistics
-	g.drawer.DrawEnsembleStats(screen)
+	drawer.DrawEnsembleStats(screen)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	if g.compare != nil {
+		return 2 * render.ScreenWidth, render.ScreenHeight
+	}
 	return render.ScreenWidth, render.ScreenHeight
 }
 
 func main() {
+	replayFlag := flag.String("replay", "", "Play back a recorded episode (.jsonl) instead of training")
+	curriculumFlag := flag.Bool("curriculum", false, "Start episodes near upright and raise difficulty as networks succeed")
+	captureFlag := flag.String("capture", render.CaptureNone, "Save episodes of the displayed network as GIFs: none, best or every")
+	captureEveryFlag := flag.Int("capture-every", render.NewDefaultCaptureConfig().EveryN, "Episode interval for -capture every")
+	captureDirFlag := flag.String("capture-dir", "", "Directory for captured GIFs (default: captures next to the saved network)")
+	logFormatFlag := flag.String("log-format", string(logger.TextFormat), "Log output format: text or json (one object per line)")
+	logSamplingFlag := flag.String("log-sampling", string(logger.SampleEveryN), "Per-step log verbosity: all, sampled, episodes or errors")
+	actionSpaceFlag := flag.String("action-space", neural.ContinuousActions, "Forces the networks may apply: continuous, bang-bang (-5/0/+5) or discrete")
+	actionBinsFlag := flag.Int("action-bins", 5, "Number of evenly spaced forces for -action-space discrete")
+	fitnessFlag := flag.String("fitness", ensemble.TicksFitness, "Selection criterion for evolution: ticks, reward, efficiency (ticks discounted by force used) or composite")
+	resumeFlag := flag.String("resume", "", "Resume an ensemble saved with S or on exit from this directory, e.g. ~/.inverted_pendulum/ensemble")
+	presetFlag := flag.String("preset", env.PezzzaPreset, "Pendulum physics preset: "+strings.Join(env.PresetNames(), ", "))
+	stepsPerFrameFlag := flag.Int("steps-per-frame", 1, "Training steps per rendered frame at 1x speed; physics still uses the fixed DeltaTime")
+	controllerFlag := flag.String("controller", "network", "What chooses each force: network, or planner (model-predictive baseline; the networks still train on its actions)")
+	metricsDBFlag := flag.String("metrics-db", "", "Record each generation's fitness and lineage in this metrics database for cmd/debug (empty to skip)")
+	failAngleFlag := flag.Float64("fail-angle", 0, "End an episode when the pendulum falls this many degrees from upright (0 to only end at the track edges)")
+	maxTicksFlag := flag.Uint64("max-ticks", 0, "End an episode after this many ticks (0 for no limit)")
+	maxSpeedFlag := flag.Bool("max-speed", false, "Start in max-speed mode: train headless for most of each frame and draw only the latest state (toggle with M)")
+	learningRateFlag := flag.Float64("lr", training.NewDefaultConfig().BaseLearningRate, "Base learning rate of every network's trainer")
+	compareFlag := flag.String("compare", "", "Train a second ensemble in lockstep to the right with these settings changed, e.g. lr=0.01,fitness=reward (keys: lr, fitness, action-space, action-bins, curriculum, frames, recurrent, strategy, perturb and the -reward-shaping terms)")
+	recurrentFlag := flag.Bool("recurrent", false, "Give each network a recurrent cell that remembers across steps, trained by truncated backpropagation through time")
+	framesFlag := flag.Int("frames", 0, "Feed each network the last N observations instead of just the current one (0 or 1 = current only)")
+	strategyFlag := flag.String("strategy", ensemble.GeneticStrategy, "How each generation evolves: genetic (breed new weights), or pbt (keep gradient training, the worst copy and perturb the best's weights and hyperparameters)")
+	perturbFlag := flag.Float64("perturb", ensemble.NewDefaultConfig().PerturbFactor, "With -strategy pbt, scale copied hyperparameters up or down by this fraction")
+	seedGenomesFlag := flag.String("seed-genomes", "", "Start the first networks from these comma-separated genome files, e.g. shared with cmd/genome export")
+	configFlag := flag.String("config", "", "Read settings from a .json, .yaml or .toml file keyed by flag name; flags given on the command line override it")
+	soundFlag := flag.Bool("sound", false, "Start with sound cues on: pitch follows the lean, a click on failure, a chime on a new best episode (toggle with A)")
+	scenarioFlag := flag.String("scenario", "", "Start every episode from a scenario file (.json or .yaml) with its scripted disturbances, and check the displayed network's episodes against its expectations; its preset and duration replace -preset and -max-ticks")
+	demosFlag := flag.String("demos", "", "Record the displayed network's states and the force pushed by dragging the mouse or a finger to this file (.jsonl), for -replay or control.LoadDemonstrations")
+	shapingFlag := flag.String("reward-shaping", "", "Penalize each step's force and force changes for smoother control, as term=weight pairs, e.g. force=0.1,jerk=0.05 (terms: "+strings.Join(reward.TermNames(), ", ")+")")
+	flag.Parse()
+	if err := config.ApplyFile(flag.CommandLine, *configFlag); err != nil {
+		panic(err)
+	}
+
+	// Pendulums and trainers created from here on follow this sampling
+	samplingMode, err := logger.ParseSamplingMode(*logSamplingFlag)
+	if err != nil {
+		panic(err)
+	}
+	sampling := logger.NewDefaultSamplingConfig()
+	sampling.Mode = samplingMode
+	logger.SetDefaultSampling(sampling)
+
 	// Set up custom logger
 	// Show INFO and ERROR on console, but log everything to file
-	gameLogger, err := logger.NewLogger(logger.INFO, logger.DEBUG)
+	logFormat, err := logger.ParseFormat(*logFormatFlag)
+	if err != nil {
+		panic(err)
+	}
+	logConfig := logger.NewDefaultConfig()
+	logConfig.Format = logFormat
+	gameLogger, err := logger.New(logConfig)
 	if err != nil {
 		panic(err)
 	}
@@ -155,12 +514,201 @@ func main() {
 	
 	gameLogger.Info("Starting Inverted Pendulum Neural Network Ensemble")
 
+	shaping, err := reward.ParseShaping(*shapingFlag)
+	if err != nil {
+		gameLogger.Fatal("%v", err)
+	}
+	settings := trainingSettings{
+		curriculum:   *curriculumFlag,
+		actionSpace:  *actionSpaceFlag,
+		actionBins:   *actionBinsFlag,
+		fitness:      *fitnessFlag,
+		learningRate: *learningRateFlag,
+		shaping:      shaping,
+		frames:       *framesFlag,
+		recurrent:    *recurrentFlag,
+		strategy:     *strategyFlag,
+		perturb:      *perturbFlag,
+	}
+
 	// Create and run game
-	game := NewGame(gameLogger)
-	ebiten.SetWindowSize(render.ScreenWidth, render.ScreenHeight)
+	termination := env.TerminationConfig{MaxAngle: *failAngleFlag * math.Pi / 180, MaxSteps: *maxTicksFlag}
+	preset := *presetFlag
+	var script scenario.File
+	if *scenarioFlag != "" {
+		if script, err = scenario.Load(*scenarioFlag); err != nil {
+			gameLogger.Fatal("%v", err)
+		}
+		if script.Preset != "" {
+			preset = script.Preset
+		}
+		config, _ := script.Config()
+		termination.MaxSteps = uint64(script.Steps(config.DeltaTime))
+	}
+	game, err := NewGame(gameLogger, settings, preset, termination)
+	if err != nil {
+		gameLogger.Fatal("%v", err)
+	}
+	if *stepsPerFrameFlag < 1 {
+		gameLogger.Fatal("-steps-per-frame must be at least 1, got %d", *stepsPerFrameFlag)
+	}
+	game.settings = config.Resolved(flag.CommandLine, "config")
+	game.playback = newPlayback(*stepsPerFrameFlag)
+	if *maxSpeedFlag {
+		game.playback.ToggleMaxSpeed()
+	}
+	if *replayFlag != "" {
+		episode, err := replay.Load(*replayFlag)
+		if err != nil {
+			gameLogger.Fatal("Failed to load replay: %v", err)
+		}
+		gameLogger.Info("Replaying %s (%d frames)", *replayFlag, len(episode.Frames))
+		game.player = replay.NewPlayer(episode)
+	}
+	if *resumeFlag != "" {
+		// The saved config and physics replace -preset and -action-space
+		if err := game.ensemble.LoadFromDir(*resumeFlag); err != nil {
+			gameLogger.Fatal("Failed to resume ensemble: %v", err)
+		}
+		game.ensembleDir = *resumeFlag
+		game.clock = newSimClock(game.ensemble.PendulumConfig.DeltaTime)
+		gameLogger.Info("Resumed ensemble from %s at generation %d", *resumeFlag, game.ensemble.Generation)
+	}
+	if *seedGenomesFlag != "" {
+		var genomes []evolution.Genome
+		for _, path := range strings.Split(*seedGenomesFlag, ",") {
+			genome, err := evolution.LoadGenome(strings.TrimSpace(path))
+			if err != nil {
+				gameLogger.Fatal("%v", err)
+			}
+			genomes = append(genomes, genome)
+		}
+		if err := game.ensemble.SeedGenomes(genomes); err != nil {
+			gameLogger.Fatal("Failed to seed ensemble: %v", err)
+		}
+	}
+	if *compareFlag != "" {
+		// The comparison always starts fresh on the main ensemble's physics
+		compareSettings, err := settings.override(*compareFlag)
+		if err != nil {
+			gameLogger.Fatal("%v", err)
+		}
+		e, err := newEnsemble(compareSettings, game.ensemble.Config.Preset, game.ensemble.PendulumConfig, game.rng, gameLogger.GetStandardLogger())
+		if err != nil {
+			gameLogger.Fatal("Failed to create comparison ensemble: %v", err)
+		}
+		game.compare = newComparison(e, render.NewDrawer(mplusNormalFont))
+		game.drawer.SetLabel(settings.String())
+		game.compare.drawer.SetLabel(compareSettings.String())
+		gameLogger.Info("Comparing %s (left) with %s (right)", settings, compareSettings)
+	}
+	if *scenarioFlag != "" {
+		schedule := script.Schedule(game.ensemble.PendulumConfig.DeltaTime)
+		for _, e := range game.ensembles() {
+			e.SetScenario(script.State(), schedule)
+		}
+		game.scenario = newScenarioRun(script, game.ensemble.PendulumConfig)
+		if game.compare == nil {
+			game.drawer.SetLabel(game.scenario.label())
+		}
+		gameLogger.Info("Playing scenario %s from %s: %s", script.Name, *scenarioFlag, script.Description)
+	}
+	switch *controllerFlag {
+	case "network":
+	case "planner":
+		plannerConfig := control.NewDefaultPlannerConfig()
+		if _, err := control.NewPlanner(game.ensemble.PendulumConfig, plannerConfig); err != nil {
+			gameLogger.Fatal("Failed to create planner: %v", err)
+		}
+		for _, e := range game.ensembles() {
+			e.SetController(func(instance *ensemble.NetworkInstance) agent.Controller {
+				// Seed each planner differently so the pendulums do not move in lockstep
+				config := plannerConfig
+				config.Seed += int64(instance.ID)
+				planner, _ := control.NewPlanner(e.PendulumConfig, config)
+				return planner
+			})
+		}
+	default:
+		gameLogger.Fatal("Unknown controller %q (want network or planner)", *controllerFlag)
+	}
+	if *metricsDBFlag != "" {
+		metricsLogger, err := metrics.NewLogger(*metricsDBFlag, false, gameLogger.GetStandardLogger())
+		if err != nil {
+			gameLogger.Fatal("Failed to open metrics database: %v", err)
+		}
+		defer metricsLogger.Close()
+		game.ensemble.SetMetricsLogger(metricsLogger)
+		gameLogger.Info("Recording generations to %s as session %s", *metricsDBFlag, metricsLogger.GetSessionID())
+	}
+	if *soundFlag && game.player == nil {
+		game.toggleSound()
+	}
+	if *demosFlag != "" && game.player == nil {
+		recorder, err := replay.NewRecorder(*demosFlag, replay.Header{
+			Config: game.ensemble.PendulumConfig,
+			Label:  "human demonstration",
+		})
+		if err != nil {
+			gameLogger.Fatal("Failed to create demonstration recording: %v", err)
+		}
+		game.demos = recorder
+		gameLogger.Info("Recording forces pushed by hand to %s", *demosFlag)
+	}
+	switch *captureFlag {
+	case render.CaptureNone, render.CaptureBest, render.CaptureEvery:
+	default:
+		gameLogger.Fatal("Unknown capture mode %q (want none, best or every)", *captureFlag)
+	}
+	captureConfig := render.NewDefaultCaptureConfig()
+	captureConfig.Mode = *captureFlag
+	captureConfig.EveryN = *captureEveryFlag
+	captureConfig.Dir = *captureDirFlag
+	if captureConfig.Dir == "" {
+		captureConfig.Dir = filepath.Join(filepath.Dir(game.networkPath), "captures")
+	}
+	game.capture = render.NewEpisodeCapture(captureConfig, gameLogger.GetStandardLogger())
+	if game.compare != nil {
+		// The split screen scales down to fit when the window is resized
+		ebiten.SetWindowSize(2*render.ScreenWidth, render.ScreenHeight)
+		ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	} else {
+		ebiten.SetWindowSize(render.ScreenWidth, render.ScreenHeight)
+	}
 	ebiten.SetWindowTitle("Inverted Pendulum Neural Network Ensemble")
 	
-	if err := ebiten.RunGame(game); err != nil {
+	// Interrupting ends the game cleanly, so the ensemble is saved and the
+	// metrics database closed; a second signal exits at once
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	interrupted := make(chan struct{})
+	game.interrupted = interrupted
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		gameLogger.Info("Interrupted: saving and exiting (interrupt again to quit now)")
+		close(interrupted)
+	}()
+
+	err = ebiten.RunGame(game)
+	game.capture.Wait()
+	if game.demos != nil {
+		if closeErr := game.demos.Close(); closeErr != nil {
+			gameLogger.Error("Failed to save demonstrations: %v", closeErr)
+		} else {
+			gameLogger.Info("Recorded %d demonstration steps to %s", game.demos.Ticks(), *demosFlag)
+		}
+	}
+	
+	// Keep the evolutionary run so it can be resumed
+	if game.player == nil {
+		if saveErr := game.saveEnsemble(); saveErr != nil {
+			gameLogger.Error("Failed to save ensemble: %v", saveErr)
+		} else {
+			gameLogger.Info("Ensemble saved to %s; continue with -resume %s", game.ensembleDir, game.ensembleDir)
+		}
+	}
+	if err != nil && !errors.Is(err, errWindowClosed) && !errors.Is(err, errInterrupted) {
 		gameLogger.Fatal("Game error: %v", err)
 	}
 }
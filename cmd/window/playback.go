@@ -0,0 +1,86 @@
+package main
+
+import "time"
+
+// speeds are the selectable simulation speeds as multiples of the base steps
+// per frame. Below 1 is slow motion; above 1 runs extra headless steps each frame
+var speeds = []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 10}
+
+const normalSpeed = 3 // Index of 1x in speeds
+
+// maxSpeedBudget is the share of each 60Hz frame spent training in max-speed
+// mode, leaving the rest for drawing
+const maxSpeedBudget = 12 * time.Millisecond
+
+// playback decides how many ensemble steps to run on each frame
+type playback struct {
+	paused        bool
+	speedIndex    int
+	budget        float64 // Fractional steps carried over between frames in slow motion
+	stepOnce      bool    // Run exactly one step on the next frame while paused
+	stepsPerFrame int     // Steps per frame at 1x
+	maxSpeed      bool    // Train for maxSpeedBudget each frame instead of a fixed step count
+}
+
+func newPlayback(stepsPerFrame int) *playback {
+	return &playback{speedIndex: normalSpeed, stepsPerFrame: max(1, stepsPerFrame)}
+}
+
+// Speed returns the current speed multiplier
+func (p *playback) Speed() float64 {
+	return speeds[p.speedIndex]
+}
+
+// TogglePause pauses or resumes the simulation
+func (p *playback) TogglePause() {
+	p.paused = !p.paused
+	p.budget = 0
+}
+
+// StepOnce pauses the simulation and advances it by a single step
+func (p *playback) StepOnce() {
+	p.paused = true
+	p.stepOnce = true
+}
+
+// ToggleMaxSpeed switches between the selected speed and training as fast
+// as the frame budget allows
+func (p *playback) ToggleMaxSpeed() {
+	p.maxSpeed = !p.maxSpeed
+	p.budget = 0
+}
+
+// MaxSpeed reports whether training runs as fast as the frame budget allows
+func (p *playback) MaxSpeed() bool {
+	return p.maxSpeed && !p.paused
+}
+
+// Faster raises the speed one notch, up to the maximum
+func (p *playback) Faster() {
+	if p.speedIndex < len(speeds)-1 {
+		p.speedIndex++
+	}
+}
+
+// Slower lowers the speed one notch, down to the minimum
+func (p *playback) Slower() {
+	if p.speedIndex > 0 {
+		p.speedIndex--
+	}
+}
+
+// Steps returns the number of steps to run this frame
+func (p *playback) Steps() int {
+	if p.paused {
+		if p.stepOnce {
+			p.stepOnce = false
+			return 1
+		}
+		return 0
+	}
+
+	p.budget += p.Speed() * float64(p.stepsPerFrame)
+	steps := int(p.budget)
+	p.budget -= float64(steps)
+	return steps
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/ensemble"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/scenario"
+)
+
+// scenarioRun scores the displayed network's episodes against a scenario
+// file's expectations, the way eval.RunScenario would
+type scenarioRun struct {
+	file      scenario.File
+	deltaTime float64       // Seconds per step
+	networkID int           // Network whose episode is being scored
+	episode   int           // Episode being scored
+	partial   bool          // The episode was joined after its first step and is not judged
+	pendulum  *env.Pendulum // Pendulum of the episode, kept after the ensemble replaces it
+	result    eval.EpisodeResult
+	verdict   string // Outcome of the last scored episode
+}
+
+func newScenarioRun(file scenario.File, config env.Config) *scenarioRun {
+	return &scenarioRun{
+		file:      file,
+		deltaTime: config.DeltaTime,
+		networkID: -1,
+		verdict:   "running",
+	}
+}
+
+// record scores the step the displayed network just took. When its episode
+// has ended it checks the expectations and returns the verdict, otherwise ""
+func (s *scenarioRun) record(best *ensemble.NetworkInstance) string {
+	if best.ID != s.networkID {
+		// Only whole episodes of one network are judged
+		s.networkID = best.ID
+		s.start(best, best.CurrentTicks > 1)
+		if best.CurrentTicks > 0 {
+			s.score(best.Pendulum.GetState())
+		}
+		return ""
+	}
+
+	if best.Episodes != s.episode {
+		// The ensemble starts the next episode on a new pendulum, so the
+		// last one still holds the terminal step. Reaching the step limit
+		// means the scenario's whole duration was survived
+		if s.pendulum.GetTermination() == env.StepLimit {
+			s.score(s.pendulum.GetState())
+		} else {
+			s.result.OutOfBounds = true
+		}
+		verdict := ""
+		if !s.partial {
+			if failures := s.file.Check(s.result); len(failures) > 0 {
+				s.verdict = "failed: " + strings.Join(failures, "; ")
+			} else {
+				s.verdict = "passed"
+			}
+			verdict = s.verdict
+		}
+		s.start(best, false)
+		return verdict
+	}
+
+	s.pendulum = best.Pendulum
+	s.score(best.Pendulum.GetState())
+	return ""
+}
+
+// start begins scoring the network's current episode
+func (s *scenarioRun) start(best *ensemble.NetworkInstance, partial bool) {
+	s.episode = best.Episodes
+	s.partial = partial
+	s.pendulum = best.Pendulum
+	s.result = eval.EpisodeResult{Scenario: s.file.Name, Success: true}
+}
+
+// score adds a step that ended in state to the episode's result
+func (s *scenarioRun) score(state env.State) {
+	deviation := eval.Deviation(state.AngleRadians)
+	s.result.Steps++
+	s.result.MaxAngle = max(s.result.MaxAngle, deviation)
+	if deviation <= s.file.SuccessAngle() {
+		s.result.BalanceTime += s.deltaTime
+	}
+}
+
+// label describes the scenario and the last verdict for the display
+func (s *scenarioRun) label() string {
+	return fmt.Sprintf("Scenario %s: %s", s.file.Name, s.verdict)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"log"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/ensemble"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/scenario"
+)
+
+// playScenario plays file on a one-network ensemble acting through
+// controller, set up as main does, and returns the first verdict
+func playScenario(t *testing.T, file scenario.File, controller agent.ControllerFunc) string {
+	t.Helper()
+	config, err := file.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	steps := file.Steps(config.DeltaTime)
+	config.Termination = env.TerminationConfig{MaxAngle: math.Pi / 2, MaxSteps: uint64(steps)}
+
+	ensembleConfig := ensemble.NewDefaultConfig()
+	ensembleConfig.NetworkCount = 1
+	e := ensemble.NewEnsemble(ensembleConfig, config, log.New(io.Discard, "", 0))
+	e.SetController(func(*ensemble.NetworkInstance) agent.Controller { return controller })
+	e.SetScenario(file.State(), file.Schedule(config.DeltaTime))
+
+	run := newScenarioRun(file, config)
+	for i := 0; i < 2*steps; i++ {
+		if err := e.Step(); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+		if verdict := run.record(e.GetBestNetwork()); verdict != "" {
+			return verdict
+		}
+	}
+	t.Fatalf("no verdict after %d steps", 2*steps)
+	return ""
+}
+
+func TestScenarioRunSurvive(t *testing.T) {
+	// Balanced exactly upright with no force, the pendulum never moves, so
+	// the episode runs the whole duration and ends at the step limit
+	file, err := scenario.Parse([]byte(`{
+		"name": "hold",
+		"duration": 1,
+		"expect": {"survive": true, "max_angle": 1, "min_balance_time": 0.99}
+	}`), scenario.JSONFormat)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	hold := agent.ControllerFunc(func(env.State) float64 { return 0 })
+
+	if verdict := playScenario(t, file, hold); verdict != "passed" {
+		t.Errorf("window verdict = %q, want passed", verdict)
+	}
+	if _, failures, err := file.Run(hold); err != nil || len(failures) > 0 {
+		t.Errorf("eval harness failures = %v (%v), want it to agree the scenario passes", failures, err)
+	}
+
+	// Tilted, the same controller lets it fall before the duration is up
+	file.Initial.Angle = 20
+	if verdict := playScenario(t, file, hold); !strings.HasPrefix(verdict, "failed: ended after") {
+		t.Errorf("window verdict after a fall = %q, want an early end", verdict)
+	}
+}
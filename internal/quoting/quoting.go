@@ -0,0 +1,47 @@
+// Package quoting handles the quoted scalars and # comments of the small
+// YAML and TOML subsets read by pkg/config and pkg/scenario, so both files
+// treat them the same way
+package quoting
+
+import (
+	"strconv"
+	"strings"
+)
+
+// StripComment drops a # comment that is not inside quotes. As in YAML, a #
+// only starts a comment at the start of the line or after whitespace, so
+// values such as colors or URL fragments keep theirs
+func StripComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// IsQuoted reports whether s is wrapped in double or single quotes
+func IsQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
+
+// Unquote removes double quotes, with escapes, or single quotes, inside
+// which a doubled single quote stands for one. Values that are not quoted
+// are returned unchanged
+func Unquote(s string) (string, error) {
+	switch {
+	case !IsQuoted(s):
+		return s, nil
+	case s[0] == '"':
+		return strconv.Unquote(s)
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+}
@@ -0,0 +1,38 @@
+package quoting
+
+import "testing"
+
+func TestStripComment(t *testing.T) {
+	for line, want := range map[string]string{
+		"# whole line":                "",
+		"lr: 0.05   # halved":         "lr: 0.05   ",
+		"name = 'a # b' # c":          "name = 'a # b' ",
+		`title: "x#1"`:                `title: "x#1"`,
+		"color: #fff":                 "color: ",
+		"url: http://host/page#intro": "url: http://host/page#intro",
+		"text: 'it''s # quoted'":      "text: 'it''s # quoted'",
+	} {
+		if got := StripComment(line); got != want {
+			t.Errorf("StripComment(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	for s, want := range map[string]string{
+		`"tab\there"`: "tab\there",
+		`'it''s'`:     "it's",
+		`'a\tb'`:      `a\tb`,
+		"plain":       "plain",
+		`"`:           `"`,
+		`'open`:       `'open`,
+	} {
+		got, err := Unquote(s)
+		if err != nil || got != want {
+			t.Errorf("Unquote(%q) = %q, %v; want %q", s, got, err, want)
+		}
+	}
+	if _, err := Unquote(`"bad \q"`); err == nil {
+		t.Error("Unquote accepted an invalid escape")
+	}
+}
@@ -0,0 +1,36 @@
+// Package agent defines the interfaces shared by everything that drives the
+// pendulum, so networks, planners and hand-written controllers can be
+// plugged into the window, ensemble, trainer and evaluation harness alike
+package agent
+
+import "github.com/zachbeta/go_inverted_pendulum/pkg/env"
+
+// Controller chooses the force to apply in a state
+type Controller interface {
+	Act(state env.State) float64
+}
+
+// Learner is a controller that improves from the transitions it observes
+type Learner interface {
+	Controller
+	Observe(exp Experience)
+}
+
+// Experience is one observed transition
+type Experience struct {
+	State     env.State
+	Action    float64
+	Reward    float64
+	NextState env.State
+	Done      bool
+	TimeStep  uint64
+}
+
+// ControllerFunc adapts a function to the Controller interface, e.g. for a
+// PID or bang-bang rule
+type ControllerFunc func(state env.State) float64
+
+// Act calls f(state)
+func (f ControllerFunc) Act(state env.State) float64 {
+	return f(state)
+}
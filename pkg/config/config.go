@@ -0,0 +1,218 @@
+// Package config loads command settings from a file, so a run can be
+// described once and repeated. Settings are keyed by flag name and may be
+// grouped in sections such as physics, network, trainer and metrics; flags
+// given on the command line override the file
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/internal/quoting"
+)
+
+// ResolvedFile is the name the resolved settings are saved under, next to
+// a command's checkpoints
+const ResolvedFile = "config.json"
+
+// Settings maps flag names to their values as given on a command line
+type Settings map[string]string
+
+// Load reads settings from a .json, .yaml/.yml or .toml file. Sections,
+// YAML mappings or TOML tables, only group settings: the keys inside them
+// are flag names, e.g.
+//
+//	physics:
+//	  preset: heavy
+//	trainer:
+//	  lr: 0.05
+//
+// Only scalar values are supported, as every setting is a flag
+func Load(path string) (Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var settings Settings
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		settings, err = parseJSON(data)
+	case ".yaml", ".yml":
+		settings, err = parseLines(data, ":")
+	case ".toml":
+		settings, err = parseLines(data, "=")
+	default:
+		return nil, fmt.Errorf("unsupported config format %q (want .json, .yaml, .yml or .toml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// parseJSON reads an object of scalars, optionally grouped in nested objects
+func parseJSON(data []byte) (Settings, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	settings := Settings{}
+	var collect func(prefix string, object map[string]interface{}) error
+	collect = func(prefix string, object map[string]interface{}) error {
+		for key, value := range object {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if prefix != "" {
+					return fmt.Errorf("section %s.%s nests too deeply", prefix, key)
+				}
+				if err := collect(key, v); err != nil {
+					return err
+				}
+			case string:
+				if err := settings.add(key, v); err != nil {
+					return err
+				}
+			case bool:
+				if err := settings.add(key, strconv.FormatBool(v)); err != nil {
+					return err
+				}
+			case float64:
+				if err := settings.add(key, strconv.FormatFloat(v, 'f', -1, 64)); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("setting %s must be a string, number or boolean", key)
+			}
+		}
+		return nil
+	}
+	if err := collect("", object); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// parseLines reads "key<sep>value" lines with # comments, the flat subset
+// of YAML (sep ":") and TOML (sep "="). Section headers, "name:" in YAML and
+// "[name]" in TOML, are skipped
+func parseLines(data []byte, sep string) (Settings, error) {
+	settings := Settings{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(quoting.StripComment(scanner.Text()))
+		if line == "" || line == "---" {
+			continue
+		}
+		if sep == "=" && strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			continue
+		}
+		key, value, found := strings.Cut(line, sep)
+		if !found {
+			return nil, fmt.Errorf("line %d: want key%s value, got %q", number, sep, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "" && sep == ":" {
+			continue
+		}
+		value, err := unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		if err := settings.add(key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+	}
+	return settings, scanner.Err()
+}
+
+// unquote strips the quotes around a string value
+func unquote(value string) (string, error) {
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+		return "", fmt.Errorf("value %s: lists and tables are not supported", value)
+	}
+	return quoting.Unquote(value)
+}
+
+// add sets key, rejecting one given twice, e.g. in two sections
+func (s Settings) add(key, value string) error {
+	if _, ok := s[key]; ok {
+		return fmt.Errorf("setting %s is given more than once", key)
+	}
+	s[key] = value
+	return nil
+}
+
+// Apply sets the flags of fs from settings, except those already given on
+// the command line, which take precedence. It must follow fs.Parse. Unknown
+// settings are an error, so typos do not pass silently
+func Apply(fs *flag.FlagSet, settings Settings) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if fs.Lookup(key) == nil {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		if explicit[key] {
+			continue
+		}
+		if err := fs.Set(key, settings[key]); err != nil {
+			return fmt.Errorf("invalid setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ApplyFile loads the config at path into fs, doing nothing if path is empty
+func ApplyFile(fs *flag.FlagSet, path string) error {
+	if path == "" {
+		return nil
+	}
+	settings, err := Load(path)
+	if err != nil {
+		return err
+	}
+	return Apply(fs, settings)
+}
+
+// Resolved returns the value of every flag of fs, after the config file and
+// command line are applied. Passing it back as a config repeats the run.
+// The flags named in omit, e.g. -config itself, are left out
+func Resolved(fs *flag.FlagSet, omit ...string) Settings {
+	skip := map[string]bool{}
+	for _, name := range omit {
+		skip[name] = true
+	}
+	settings := Settings{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if !skip[f.Name] {
+			settings[f.Name] = f.Value.String()
+		}
+	})
+	return settings
+}
+
+// Save writes settings as JSON, which Load reads back
+func Save(path string, settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
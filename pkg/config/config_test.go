@@ -0,0 +1,141 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfig(t *testing.T) {
+	write := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	newFlags := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("preset", "classic", "")
+		fs.Int("episodes", 100, "")
+		fs.Float64("lr", 0.1, "")
+		fs.Bool("normalize", false, "")
+		fs.Duration("time-budget", 0, "")
+		fs.String("config", "", "")
+		return fs
+	}
+	want := Settings{"preset": "heavy", "episodes": "1000", "lr": "0.05", "normalize": "true", "time-budget": "5m"}
+
+	files := map[string]string{
+		"run.json": `{
+  "physics": {"preset": "heavy"},
+  "trainer": {"episodes": 1000, "lr": 0.05, "time-budget": "5m"},
+  "network": {"normalize": true}
+}`,
+		"run.yaml": `# A heavy pendulum run
+physics:
+  preset: heavy
+trainer:
+  episodes: 1000
+  lr: 0.05   # halved
+  time-budget: "5m"
+network:
+  normalize: true
+`,
+		"run.toml": `[physics]
+preset = "heavy"
+
+[trainer]
+episodes = 1000
+lr = 0.05
+time-budget = '5m'
+
+[network]
+normalize = true
+`,
+	}
+	for name, content := range files {
+		t.Run("loads "+name, func(t *testing.T) {
+			settings, err := Load(write(t, name, content))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if len(settings) != len(want) {
+				t.Fatalf("settings = %v, want %v", settings, want)
+			}
+			for key, value := range want {
+				if settings[key] != value {
+					t.Errorf("%s = %q, want %q", key, settings[key], value)
+				}
+			}
+		})
+	}
+
+	t.Run("command line flags override the file", func(t *testing.T) {
+		fs := newFlags()
+		if err := fs.Parse([]string{"-lr", "0.2"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Apply(fs, want); err != nil {
+			t.Fatalf("Apply: %v", err)
+		}
+		if got := fs.Lookup("lr").Value.String(); got != "0.2" {
+			t.Errorf("lr = %s, want the command line's 0.2", got)
+		}
+		if got := fs.Lookup("time-budget").Value.(flag.Getter).Get(); got != 5*time.Minute {
+			t.Errorf("time-budget = %v, want the file's 5m", got)
+		}
+		if got := fs.Lookup("episodes").Value.String(); got != "1000" {
+			t.Errorf("episodes = %s, want the file's 1000", got)
+		}
+	})
+
+	t.Run("resolved settings round trip", func(t *testing.T) {
+		fs := newFlags()
+		if err := fs.Parse([]string{"-config", "run.yaml", "-episodes", "20"}); err != nil {
+			t.Fatal(err)
+		}
+		resolved := Resolved(fs, "config")
+		if _, ok := resolved["config"]; ok {
+			t.Error("resolved settings include the omitted -config")
+		}
+		path := filepath.Join(t.TempDir(), ResolvedFile)
+		if err := Save(path, resolved); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		replay := newFlags()
+		if err := replay.Parse(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := ApplyFile(replay, path); err != nil {
+			t.Fatalf("ApplyFile: %v", err)
+		}
+		for name, value := range resolved {
+			if got := replay.Lookup(name).Value.String(); got != value {
+				t.Errorf("%s = %s after reloading, want %s", name, got, value)
+			}
+		}
+	})
+
+	t.Run("invalid configs are rejected", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"unknown.yaml":   "learning-rate: 0.1\n",
+			"invalid.toml":   "episodes = many\n",
+			"duplicate.yaml": "physics:\n  preset: heavy\nenv:\n  preset: light\n",
+			"list.toml":      "episodes = [1, 2]\n",
+			"nested.json":    `{"trainer": {"adam": {"lr": 0.1}}}`,
+			"run.ini":        "episodes=10\n",
+		} {
+			fs := newFlags()
+			if err := fs.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := ApplyFile(fs, write(t, name, content)); err == nil {
+				t.Errorf("%s applied, want error", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,217 @@
+// Package control provides controllers that plan with the pendulum's
+// known dynamics instead of learning a policy
+package control
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
+)
+
+// Planning methods accepted by NewPlanner
+const (
+	RandomShooting = "random"
+	CEM            = "cem"
+)
+
+// PlannerConfig controls how candidate force sequences are searched
+type PlannerConfig struct {
+	Method         string  // "random" (random shooting) or "cem" (cross-entropy method)
+	Horizon        int     // Steps simulated per candidate sequence
+	Candidates     int     // Force sequences sampled per iteration
+	Iterations     int     // CEM refinement rounds; random shooting samples once
+	Elites         int     // CEM candidates kept to refit the sampling distribution
+	CartPenalty    float64 // Reward lost per meter of cart offset from center
+	SpeedPenalty   float64 // Reward lost per m/s of cart velocity
+	SpinPenalty    float64 // Reward lost per rad/s of angular velocity
+	FailurePenalty float64 // Reward lost for a sequence that leaves the track
+	Seed           int64   // Seed for candidate sampling, so plans are reproducible
+}
+
+// NewDefaultPlannerConfig returns a CEM planner looking 1.2s ahead
+func NewDefaultPlannerConfig() PlannerConfig {
+	return PlannerConfig{
+		Method:         CEM,
+		Horizon:        60,
+		Candidates:     64,
+		Iterations:     3,
+		Elites:         8,
+		CartPenalty:    0.1,
+		SpeedPenalty:   0.1,
+		SpinPenalty:    0.1,
+		FailurePenalty: 100.0,
+		Seed:           1,
+	}
+}
+
+// Planner is a model-predictive controller: each Act simulates candidate
+// force sequences on a disturbance-free copy of the environment, applies the
+// first force of the best one and replans on the next step
+type Planner struct {
+	config   PlannerConfig
+	model    *env.Pendulum
+	maxForce float64
+	rng      *rand.Rand
+	plan     []float64 // Best sequence from the last step, shifted to warm-start the next
+}
+
+// NewPlanner creates a planner for an environment with envConfig
+func NewPlanner(envConfig env.Config, config PlannerConfig) (*Planner, error) {
+	switch config.Method {
+	case RandomShooting, CEM:
+	default:
+		return nil, fmt.Errorf("unknown planning method %q (want random or cem)", config.Method)
+	}
+	if config.Horizon < 1 || config.Candidates < 1 {
+		return nil, fmt.Errorf("planner needs a positive horizon and candidate count, got %d and %d", config.Horizon, config.Candidates)
+	}
+	if config.Method == CEM && (config.Elites < 1 || config.Elites > config.Candidates || config.Iterations < 1) {
+		return nil, fmt.Errorf("cem needs 1 to %d elites and at least one iteration, got %d and %d", config.Candidates, config.Elites, config.Iterations)
+	}
+
+	// The model knows the physics but not the disturbances, which are unpredictable
+	modelConfig := envConfig
+	modelConfig.ImpulseProb = 0
+	modelConfig.WindForce = 0
+	modelConfig.WindNoise = 0
+	modelConfig.SensorNoise = 0
+
+	model := env.NewPendulum(modelConfig, log.New(io.Discard, "", 0))
+	model.SetLogSampling(logger.SamplingConfig{Mode: logger.SampleErrors})
+
+	return &Planner{
+		config:   config,
+		model:    model,
+		maxForce: envConfig.MaxForce,
+		rng:      rand.New(rand.NewSource(config.Seed)),
+	}, nil
+}
+
+// Config returns the planner's configuration
+func (p *Planner) Config() PlannerConfig {
+	return p.config
+}
+
+// Reset forgets the warm-start plan, e.g. between episodes
+func (p *Planner) Reset() {
+	p.plan = nil
+}
+
+// Act returns the first force of the best sequence found from state,
+// implementing agent.Controller
+func (p *Planner) Act(state env.State) float64 {
+	var best []float64
+	if p.config.Method == CEM {
+		best = p.crossEntropy(state)
+	} else {
+		best = p.randomShooting(state)
+	}
+
+	// Shift the plan one step so the next search starts where this one ended
+	p.plan = append(best[1:], best[len(best)-1])
+	return best[0]
+}
+
+// randomShooting samples uniform force sequences, plus the shifted previous
+// plan, and keeps the best
+func (p *Planner) randomShooting(state env.State) []float64 {
+	best, bestReward := p.plan, math.Inf(-1)
+	if best != nil {
+		bestReward = p.Rollout(state, best)
+	}
+	for i := 0; i < p.config.Candidates; i++ {
+		candidate := make([]float64, p.config.Horizon)
+		for t := range candidate {
+			candidate[t] = (2*p.rng.Float64() - 1) * p.maxForce
+		}
+		if reward := p.Rollout(state, candidate); reward > bestReward {
+			best, bestReward = candidate, reward
+		}
+	}
+	return best
+}
+
+// crossEntropy samples sequences from a per-step Gaussian, refits it to the
+// elite sequences each iteration and returns the best sequence seen
+func (p *Planner) crossEntropy(state env.State) []float64 {
+	mean := make([]float64, p.config.Horizon)
+	stddev := make([]float64, p.config.Horizon)
+	copy(mean, p.plan)
+	for t := range stddev {
+		stddev[t] = p.maxForce / 2
+	}
+
+	type scored struct {
+		forces []float64
+		reward float64
+	}
+	var best scored
+	best.reward = math.Inf(-1)
+
+	candidates := make([]scored, p.config.Candidates)
+	for iter := 0; iter < p.config.Iterations; iter++ {
+		for i := range candidates {
+			forces := make([]float64, p.config.Horizon)
+			for t := range forces {
+				forces[t] = clamp(mean[t]+stddev[t]*p.rng.NormFloat64(), p.maxForce)
+			}
+			// Always consider the mean itself so refinement never loses it
+			if i == 0 {
+				copy(forces, mean)
+			}
+			candidates[i] = scored{forces: forces, reward: p.Rollout(state, forces)}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].reward > candidates[j].reward
+		})
+		if candidates[0].reward > best.reward {
+			best = candidates[0]
+		}
+
+		elites := candidates[:p.config.Elites]
+		for t := range mean {
+			sum, sumSq := 0.0, 0.0
+			for _, e := range elites {
+				sum += e.forces[t]
+				sumSq += e.forces[t] * e.forces[t]
+			}
+			n := float64(len(elites))
+			mean[t] = sum / n
+			stddev[t] = math.Sqrt(math.Max(0, sumSq/n-mean[t]*mean[t]))
+		}
+	}
+	return best.forces
+}
+
+// Rollout returns the predicted reward of applying forces from state: the
+// eval per-step reward of 1 - deviation/π, less the cart offset and spin
+// penalties, with a sequence that leaves the track losing FailurePenalty
+func (p *Planner) Rollout(state env.State, forces []float64) float64 {
+	p.model.Reset(state)
+
+	total := 0.0
+	for i, force := range forces {
+		next, done, _ := p.model.Advance(force)
+		if done {
+			// Failing later is less bad than failing now
+			return total - p.config.FailurePenalty*float64(len(forces)-i)/float64(len(forces))
+		}
+		total += 1.0 - eval.Deviation(next.AngleRadians)/math.Pi -
+			p.config.CartPenalty*math.Abs(next.CartPosition) -
+			p.config.SpeedPenalty*math.Abs(next.CartVelocity) -
+			p.config.SpinPenalty*math.Abs(next.AngularVel)
+	}
+	return total
+}
+
+// clamp limits force to ±maxForce
+func clamp(force, maxForce float64) float64 {
+	return math.Max(-maxForce, math.Min(force, maxForce))
+}
@@ -0,0 +1,139 @@
+package control
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/replay"
+)
+
+func TestPlanner(t *testing.T) {
+	scenario := eval.Scenario{
+		Name:    "tilt",
+		Config:  env.NewDefaultConfig(),
+		Initial: env.State{AngleRadians: 0.3},
+		Steps:   200,
+	}
+
+	for _, method := range []string{RandomShooting, CEM} {
+		t.Run(method, func(t *testing.T) {
+			config := NewDefaultPlannerConfig()
+			config.Method = method
+			if method == RandomShooting {
+				config.Candidates = 200
+			}
+			planner, err := NewPlanner(scenario.Config, config)
+			if err != nil {
+				t.Fatalf("NewPlanner failed: %v", err)
+			}
+
+			result := eval.RunScenario(scenario, planner, math.Pi/4)
+			if !result.Success {
+				t.Errorf("planner failed to balance from a 0.3 rad tilt: %+v", result)
+			}
+		})
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		first, _ := NewPlanner(scenario.Config, NewDefaultPlannerConfig())
+		second, _ := NewPlanner(scenario.Config, NewDefaultPlannerConfig())
+		state := env.State{AngleRadians: 0.1}
+		if a, b := first.Act(state), second.Act(state); a != b {
+			t.Errorf("same seed gave forces %v and %v", a, b)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		config := NewDefaultPlannerConfig()
+		config.Method = "mcts"
+		if _, err := NewPlanner(scenario.Config, config); err == nil {
+			t.Error("expected error for an unknown method")
+		}
+		config = NewDefaultPlannerConfig()
+		config.Elites = config.Candidates + 1
+		if _, err := NewPlanner(scenario.Config, config); err == nil {
+			t.Error("expected error for more elites than candidates")
+		}
+	})
+}
+
+func TestRollout(t *testing.T) {
+	planner, err := NewPlanner(env.NewDefaultConfig(), NewDefaultPlannerConfig())
+	if err != nil {
+		t.Fatalf("NewPlanner failed: %v", err)
+	}
+
+	// Resting upright with no force earns the full reward every step
+	if got := planner.Rollout(env.State{}, make([]float64, 10)); math.Abs(got-10) > 1e-9 {
+		t.Errorf("Rollout at rest = %v, want 10", got)
+	}
+
+	// Driving off the track is penalized
+	push := make([]float64, 200)
+	for i := range push {
+		push[i] = 10
+	}
+	if got := planner.Rollout(env.State{}, push); got >= 0 {
+		t.Errorf("Rollout off the track = %v, want a penalty", got)
+	}
+}
+
+func TestCollectDemonstrations(t *testing.T) {
+	planner, err := NewPlanner(env.NewDefaultConfig(), NewDefaultPlannerConfig())
+	if err != nil {
+		t.Fatalf("NewPlanner failed: %v", err)
+	}
+
+	suite := eval.Suite{Scenarios: []eval.Scenario{
+		{Config: env.NewDefaultConfig(), Initial: env.State{AngleRadians: 0.1}, Steps: 20},
+		{Config: env.NewDefaultConfig(), Initial: env.State{AngleRadians: -0.1}, Steps: 20},
+	}}
+	demonstrations := CollectDemonstrations(planner, suite)
+	if len(demonstrations) != 40 {
+		t.Fatalf("got %d demonstrations, want 40", len(demonstrations))
+	}
+	if demonstrations[0].State.AngleRadians != 0.1 {
+		t.Errorf("first demonstration state = %+v, want the scenario's initial state", demonstrations[0].State)
+	}
+	// Pushing the cart under a pendulum leaning right means a positive force
+	if demonstrations[0].Force <= 0 {
+		t.Errorf("teacher force for a right tilt = %v, want positive", demonstrations[0].Force)
+	}
+}
+
+func TestLoadDemonstrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demos.jsonl")
+	recorder, err := replay.NewRecorder(path, replay.Header{Config: env.NewDefaultConfig(), Label: "human demonstration"})
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	states := []env.State{{AngleRadians: 0.1}, {AngleRadians: 0.2, CartPosition: 0.5}}
+	for i, state := range states {
+		if err := recorder.Record(state, float64(i+1)*2.5, 1, false); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	demonstrations, err := LoadDemonstrations(path)
+	if err != nil {
+		t.Fatalf("LoadDemonstrations failed: %v", err)
+	}
+	if len(demonstrations) != len(states) {
+		t.Fatalf("got %d demonstrations, want %d", len(demonstrations), len(states))
+	}
+	for i, d := range demonstrations {
+		if d.State != states[i] || d.Force != float64(i+1)*2.5 {
+			t.Errorf("demonstration %d = %+v, want state %+v and force %v", i, d, states[i], float64(i+1)*2.5)
+		}
+	}
+
+	if _, err := LoadDemonstrations(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("LoadDemonstrations of a missing file succeeded")
+	}
+}
@@ -0,0 +1,62 @@
+package control
+
+import (
+	"io"
+	"log"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/replay"
+)
+
+// Demonstration is one state and the force a teacher chose for it
+type Demonstration struct {
+	State env.State
+	Force float64
+}
+
+// CollectDemonstrations runs teacher on every scenario of suite and records
+// each observed state with the force it chose, as labels for imitation
+// learning. A Planner teacher is reset before each scenario
+func CollectDemonstrations(teacher agent.Controller, suite eval.Suite) []Demonstration {
+	var demonstrations []Demonstration
+	for _, scenario := range suite.Scenarios {
+		if planner, ok := teacher.(*Planner); ok {
+			planner.Reset()
+		}
+
+		pendulum := env.NewPendulum(scenario.Config, log.New(io.Discard, "", 0))
+		pendulum.SetLogSampling(logger.SamplingConfig{Mode: logger.SampleErrors})
+		pendulum.Reset(scenario.Initial)
+
+		state := pendulum.GetState()
+		for i := 0; i < scenario.Steps; i++ {
+			force := teacher.Act(state)
+			demonstrations = append(demonstrations, Demonstration{State: state, Force: force})
+
+			next, done, _ := pendulum.Advance(force)
+			if done {
+				break
+			}
+			state = next
+		}
+	}
+	return demonstrations
+}
+
+// LoadDemonstrations reads a replay recording, e.g. one made by pushing the
+// cart by hand in cmd/window -demos, as demonstrations of each recorded
+// state and the force applied in it
+func LoadDemonstrations(path string) ([]Demonstration, error) {
+	episode, err := replay.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	demonstrations := make([]Demonstration, len(episode.Frames))
+	for i, frame := range episode.Frames {
+		demonstrations[i] = Demonstration{State: frame.State, Force: frame.Action}
+	}
+	return demonstrations, nil
+}
@@ -0,0 +1,86 @@
+package curriculum
+
+import (
+	"log"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// DifficultySource is a learner that tracks its own difficulty, e.g.
+// neural.Network, whose success rate raises and lowers GetDifficulty
+type DifficultySource interface {
+	GetDifficulty() float64
+}
+
+// historySize is how many recent levels a Controller keeps
+const historySize = 1000
+
+// Level is the difficulty and conditions of one episode a Controller started
+type Level struct {
+	Episode int // 0-based index among the episodes the controller started
+	Conditions
+}
+
+// Controller starts every episode from a curriculum and remembers the
+// conditions of each one. With a source it closes the progressive
+// difficulty loop: each episode is sampled at the source's current
+// difficulty, so the learner's own success decides how hard the next
+// episode is. Without one the curriculum progresses from the outcomes
+// passed to RecordEpisode
+type Controller struct {
+	curriculum *Curriculum
+	source     DifficultySource
+	levels     []Level // The most recent historySize levels
+	episodes   int     // Episodes started
+}
+
+// NewController creates a controller over curriculum; source may be nil
+func NewController(curriculum *Curriculum, source DifficultySource) *Controller {
+	return &Controller{curriculum: curriculum, source: source}
+}
+
+// Difficulty returns the difficulty the next episode will be sampled at
+func (c *Controller) Difficulty() float64 {
+	if c.source != nil {
+		return clamp(c.source.GetDifficulty())
+	}
+	return c.curriculum.Difficulty()
+}
+
+// NewPendulum creates a pendulum for the next episode at the current
+// difficulty, see Curriculum.NewPendulum
+func (c *Controller) NewPendulum(base env.Config, logger *log.Logger) *env.Pendulum {
+	if c.source != nil {
+		c.curriculum.SetDifficulty(c.source.GetDifficulty())
+	}
+	pendulum, conditions := c.curriculum.newPendulum(base, logger)
+	c.levels = append(c.levels, Level{Episode: c.episodes, Conditions: conditions})
+	if len(c.levels) > historySize {
+		c.levels = c.levels[1:]
+	}
+	c.episodes++
+	return pendulum
+}
+
+// RecordEpisode passes an episode outcome to the curriculum, unless the
+// difficulty follows a source, which judges its own episodes. Returns true
+// if the difficulty changed
+func (c *Controller) RecordEpisode(success bool) bool {
+	if c.source != nil {
+		return false
+	}
+	return c.curriculum.RecordEpisode(success)
+}
+
+// Last returns the level of the most recent episode, false before the first
+func (c *Controller) Last() (Level, bool) {
+	if len(c.levels) == 0 {
+		return Level{}, false
+	}
+	return c.levels[len(c.levels)-1], true
+}
+
+// History returns the levels of the most recent episodes, oldest first
+func (c *Controller) History() []Level {
+	return append([]Level(nil), c.levels...)
+}
@@ -0,0 +1,212 @@
+// Package curriculum maps a difficulty level to the pendulum's initial
+// conditions and disturbances, so training starts near upright in still air
+// and progresses to swinging up from hanging down through gusts and sensor
+// noise as the success rate improves
+package curriculum
+
+import (
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// Config holds the curriculum's ranges and progression thresholds.
+// Each range is interpolated linearly from difficulty 0 to difficulty 1
+type Config struct {
+	MinAngle          float64 // Maximum start deviation from upright at difficulty 0 (radians)
+	MaxAngle          float64 // Maximum start deviation from upright at difficulty 1 (radians)
+	EasyTrackLength   float64 // Track length at difficulty 0 (meters)
+	HardTrackLength   float64 // Track length at difficulty 1 (meters)
+	MaxDisturbance    float64 // Maximum initial angular velocity kick at difficulty 1 (rad/s)
+	MaxImpulseProb    float64 // Probability per step of an impulse on the cart at difficulty 1
+	ImpulseForce      float64 // Magnitude of each impulse (N)
+	MaxWindNoise      float64 // Standard deviation of the wind at difficulty 1 (N)
+	MaxSensorNoise    float64 // Standard deviation of the observation noise at difficulty 1
+	InitialDifficulty float64 // Starting difficulty level [0.0, 1.0]
+	DifficultyStep    float64 // Change in difficulty per progression or regression
+	WindowSize        int     // Number of recent episodes used for the success rate
+	ProgressThresh    float64 // Success rate threshold for progression
+	RegressThresh     float64 // Success rate threshold for regression
+}
+
+// NewDefaultConfig returns a Config with reasonable default values
+func NewDefaultConfig() Config {
+	return Config{
+		MinAngle:          0.05,    // ~3 degrees
+		MaxAngle:          math.Pi, // Hanging straight down
+		EasyTrackLength:   8.0,
+		HardTrackLength:   4.0,
+		MaxDisturbance:    1.0,
+		MaxImpulseProb:    0.01,
+		ImpulseForce:      5.0,
+		MaxWindNoise:      0.5,
+		MaxSensorNoise:    0.01,
+		InitialDifficulty: 0.0,
+		DifficultyStep:    0.1,
+		WindowSize:        20,
+		ProgressThresh:    0.8,
+		RegressThresh:     0.2,
+	}
+}
+
+// Conditions are the initial conditions and disturbance levels of one episode
+type Conditions struct {
+	Difficulty  float64
+	Angle       float64 // Initial angle (0 is upright)
+	AngularVel  float64 // Initial angular velocity from the disturbance kick
+	TrackLength float64
+	ImpulseProb float64 // Probability per step of an impulse on the cart
+	WindNoise   float64 // Standard deviation of the wind (N)
+	SensorNoise float64 // Standard deviation of the observation noise
+}
+
+// Values returns the conditions keyed by name, e.g. as metrics metadata
+func (c Conditions) Values() map[string]float64 {
+	return map[string]float64{
+		"angle":        c.Angle,
+		"angular_vel":  c.AngularVel,
+		"track_length": c.TrackLength,
+		"impulse_prob": c.ImpulseProb,
+		"wind_noise":   c.WindNoise,
+		"sensor_noise": c.SensorNoise,
+	}
+}
+
+// Curriculum samples initial conditions and adjusts difficulty from episode outcomes
+type Curriculum struct {
+	config     Config
+	difficulty float64
+	window     []bool
+	rng        *rand.Rand
+	logger     *log.Logger
+}
+
+// New creates a curriculum starting at config.InitialDifficulty
+func New(config Config, rng *rand.Rand, logger *log.Logger) *Curriculum {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Curriculum{
+		config:     config,
+		difficulty: clamp(config.InitialDifficulty),
+		window:     make([]bool, 0, config.WindowSize),
+		rng:        rng,
+		logger:     logger,
+	}
+}
+
+// Difficulty returns the current difficulty level
+func (c *Curriculum) Difficulty() float64 {
+	return c.difficulty
+}
+
+// SetDifficulty overrides the difficulty level, e.g. to follow the network's own difficulty
+func (c *Curriculum) SetDifficulty(difficulty float64) {
+	c.difficulty = clamp(difficulty)
+}
+
+// SuccessRate returns the success rate over the recent episode window
+func (c *Curriculum) SuccessRate() float64 {
+	if len(c.window) == 0 {
+		return 0
+	}
+	successes := 0
+	for _, s := range c.window {
+		if s {
+			successes++
+		}
+	}
+	return float64(successes) / float64(len(c.window))
+}
+
+// Sample draws initial conditions for the current difficulty
+func (c *Curriculum) Sample() Conditions {
+	d := c.difficulty
+	maxAngle := lerp(c.config.MinAngle, c.config.MaxAngle, d)
+	disturbance := c.config.MaxDisturbance * d
+
+	return Conditions{
+		Difficulty:  d,
+		Angle:       (c.rng.Float64()*2 - 1) * maxAngle,
+		AngularVel:  (c.rng.Float64()*2 - 1) * disturbance,
+		TrackLength: lerp(c.config.EasyTrackLength, c.config.HardTrackLength, d),
+		ImpulseProb: c.config.MaxImpulseProb * d,
+		WindNoise:   c.config.MaxWindNoise * d,
+		SensorNoise: c.config.MaxSensorNoise * d,
+	}
+}
+
+// NewPendulum creates a pendulum from the base config with freshly sampled
+// initial conditions
+func (c *Curriculum) NewPendulum(base env.Config, logger *log.Logger) *env.Pendulum {
+	pendulum, _ := c.newPendulum(base, logger)
+	return pendulum
+}
+
+// newPendulum creates a pendulum with freshly sampled initial conditions,
+// with the disturbances added to any the base config already has
+func (c *Curriculum) newPendulum(base env.Config, logger *log.Logger) (*env.Pendulum, Conditions) {
+	conditions := c.Sample()
+
+	config := base
+	config.TrackLength = conditions.TrackLength
+	if conditions.ImpulseProb > 0 {
+		config.ImpulseProb = math.Min(1, config.ImpulseProb+conditions.ImpulseProb)
+		config.ImpulseForce = math.Max(config.ImpulseForce, c.config.ImpulseForce)
+	}
+	config.WindNoise += conditions.WindNoise
+	config.SensorNoise += conditions.SensorNoise
+	if conditions.ImpulseProb > 0 || conditions.WindNoise > 0 || conditions.SensorNoise > 0 {
+		// Every episode gets its own gusts
+		config.Seed = c.rng.Int63()
+	}
+
+	pendulum := env.NewPendulum(config, logger)
+	pendulum.Reset(env.State{
+		AngleRadians: conditions.Angle,
+		AngularVel:   conditions.AngularVel,
+	})
+	return pendulum, conditions
+}
+
+// RecordEpisode adds an episode outcome and progresses or regresses the
+// difficulty once the window is full. Returns true if the difficulty changed
+func (c *Curriculum) RecordEpisode(success bool) bool {
+	c.window = append(c.window, success)
+	if len(c.window) > c.config.WindowSize {
+		c.window = c.window[1:]
+	}
+	if len(c.window) < c.config.WindowSize {
+		return false
+	}
+
+	old := c.difficulty
+	rate := c.SuccessRate()
+	switch {
+	case rate >= c.config.ProgressThresh:
+		c.difficulty = clamp(c.difficulty + c.config.DifficultyStep)
+	case rate <= c.config.RegressThresh:
+		c.difficulty = clamp(c.difficulty - c.config.DifficultyStep)
+	}
+	if c.difficulty == old {
+		return false
+	}
+
+	// Judge the new difficulty on fresh episodes only
+	c.window = c.window[:0]
+	c.logger.Printf("[Curriculum] Difficulty %.2f → %.2f (success rate %.1f%%)", old, c.difficulty, rate*100)
+	return true
+}
+
+// lerp interpolates linearly between a and b
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// clamp limits a difficulty to [0, 1]
+func clamp(d float64) float64 {
+	return math.Max(0, math.Min(1, d))
+}
@@ -0,0 +1,128 @@
+package curriculum
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestSampleScalesWithDifficulty(t *testing.T) {
+	config := NewDefaultConfig()
+	c := New(config, rand.New(rand.NewSource(1)), nil)
+
+	for _, difficulty := range []float64{0, 0.5, 1} {
+		c.SetDifficulty(difficulty)
+		maxAngle := config.MinAngle + (config.MaxAngle-config.MinAngle)*difficulty
+
+		for i := 0; i < 100; i++ {
+			conditions := c.Sample()
+			if math.Abs(conditions.Angle) > maxAngle {
+				t.Fatalf("difficulty %.1f: angle %.3f exceeds %.3f", difficulty, conditions.Angle, maxAngle)
+			}
+			if math.Abs(conditions.AngularVel) > config.MaxDisturbance*difficulty {
+				t.Fatalf("difficulty %.1f: angular velocity %.3f too large", difficulty, conditions.AngularVel)
+			}
+		}
+	}
+
+	c.SetDifficulty(0)
+	if got := c.Sample().TrackLength; got != config.EasyTrackLength {
+		t.Errorf("easy track length = %.1f, want %.1f", got, config.EasyTrackLength)
+	}
+	c.SetDifficulty(1)
+	if got := c.Sample().TrackLength; got != config.HardTrackLength {
+		t.Errorf("hard track length = %.1f, want %.1f", got, config.HardTrackLength)
+	}
+}
+
+func TestNewPendulumAppliesConditions(t *testing.T) {
+	c := New(NewDefaultConfig(), rand.New(rand.NewSource(1)), nil)
+	pendulum := c.NewPendulum(env.NewDefaultConfig(), nil)
+
+	state := pendulum.GetState()
+	deviation := env.UprightDeviation(state.AngleRadians)
+	if deviation > NewDefaultConfig().MinAngle {
+		t.Errorf("easy episode starts %.3f rad from upright", deviation)
+	}
+	if pendulum.GetConfig().TrackLength != NewDefaultConfig().EasyTrackLength {
+		t.Errorf("track length = %.1f, want easy length", pendulum.GetConfig().TrackLength)
+	}
+}
+
+func TestProgression(t *testing.T) {
+	config := NewDefaultConfig()
+	config.WindowSize = 5
+	c := New(config, rand.New(rand.NewSource(1)), nil)
+
+	// No change until the window is full
+	for i := 0; i < config.WindowSize-1; i++ {
+		if c.RecordEpisode(true) {
+			t.Fatalf("difficulty changed after %d episodes", i+1)
+		}
+	}
+	if !c.RecordEpisode(true) || c.Difficulty() != config.DifficultyStep {
+		t.Fatalf("difficulty = %.2f after full successful window, want %.2f", c.Difficulty(), config.DifficultyStep)
+	}
+
+	// Repeated failure regresses, but never below zero
+	for i := 0; i < 3*config.WindowSize; i++ {
+		c.RecordEpisode(false)
+	}
+	if c.Difficulty() != 0 {
+		t.Errorf("difficulty = %.2f after repeated failure, want 0", c.Difficulty())
+	}
+}
+
+func TestDisturbancesScaleWithDifficulty(t *testing.T) {
+	config := NewDefaultConfig()
+	c := New(config, rand.New(rand.NewSource(1)), nil)
+
+	easy := c.NewPendulum(env.NewDefaultConfig(), nil).GetConfig()
+	if easy.ImpulseProb != 0 || easy.WindNoise != 0 || easy.SensorNoise != 0 {
+		t.Errorf("easy episode has disturbances: %+v", easy)
+	}
+
+	c.SetDifficulty(1)
+	hard := c.NewPendulum(env.NewDefaultConfig(), nil).GetConfig()
+	if hard.ImpulseProb != config.MaxImpulseProb || hard.ImpulseForce != config.ImpulseForce ||
+		hard.WindNoise != config.MaxWindNoise || hard.SensorNoise != config.MaxSensorNoise {
+		t.Errorf("hard episode disturbances = %+v, want the curriculum's maximums", hard)
+	}
+}
+
+// fixedDifficulty is a DifficultySource that reports a set difficulty
+type fixedDifficulty float64
+
+func (f fixedDifficulty) GetDifficulty() float64 { return float64(f) }
+
+func TestControllerFollowsSource(t *testing.T) {
+	source := fixedDifficulty(0.5)
+	c := NewController(New(NewDefaultConfig(), rand.New(rand.NewSource(1)), nil), &source)
+
+	if _, ok := c.Last(); ok {
+		t.Error("Last reported a level before the first episode")
+	}
+	c.NewPendulum(env.NewDefaultConfig(), nil)
+	source = 0.8
+	c.NewPendulum(env.NewDefaultConfig(), nil)
+
+	history := c.History()
+	if len(history) != 2 || history[0].Difficulty != 0.5 || history[1].Difficulty != 0.8 {
+		t.Fatalf("history = %+v, want episodes at 0.5 then 0.8", history)
+	}
+	if last, _ := c.Last(); last.Episode != 1 {
+		t.Errorf("last episode = %d, want 1", last.Episode)
+	}
+
+	// The source judges its own episodes
+	for i := 0; i < 3*NewDefaultConfig().WindowSize; i++ {
+		if c.RecordEpisode(true) {
+			t.Fatal("RecordEpisode changed a followed difficulty")
+		}
+	}
+	if c.Difficulty() != 0.8 {
+		t.Errorf("difficulty = %.2f, want the source's 0.8", c.Difficulty())
+	}
+}
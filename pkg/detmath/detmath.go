@@ -0,0 +1,258 @@
+// Package detmath provides the elementary functions used on the simulation
+// and policy hot paths with results that are bit-for-bit identical on every
+// OS and architecture, so recorded episodes and checkpoint replays match
+// wherever they run.
+//
+// The standard library does not guarantee this: math.Exp, and math.Tanh
+// through it, run assembly on amd64 that switches to fused multiply-adds
+// when the CPU has them; s390x has assembly for nearly every function; and
+// the compiler may fuse x*y+z into one FMA instruction on arm64, ppc64,
+// s390x and amd64 with GOAMD64=v3, rounding once instead of twice. These
+// ports of the standard library's pure Go algorithms convert every product
+// to float64 explicitly, which the Go spec guarantees prevents fusion.
+//
+// Code on the hot paths (env dynamics, reward, Network.Forward) follows
+// the same rules:
+//   - use detmath.Sin, Cos, Exp and Tanh instead of the math functions
+//   - write squares as x*x, not math.Pow(x, 2)
+//   - wrap a product in float64() when it is added to or subtracted from
+//     something, e.g. float64(w*x) + b
+//
+// Sqrt, Abs, Floor, Mod, Min and Max are exact in IEEE 754 and may be used
+// from math directly. The golden fixtures in testdata pin the results
+package detmath
+
+import "math"
+
+// reduceThreshold is where the simple Cody-Waite argument reduction of
+// Sin and Cos loses precision. Larger arguments, which never occur on the
+// hot paths, fall back to the math package
+const reduceThreshold = 1 << 29
+
+// Pi/4 split into three parts for extended precision argument reduction
+const (
+	pi4A = 7.85398125648498535156e-1  // 0x3fe921fb40000000
+	pi4B = 3.77489470793079817668e-8  // 0x3e64442d00000000
+	pi4C = 2.69515142907905952645e-15 // 0x3ce8469898cc5170
+)
+
+// Polynomial coefficients of sin and cos on [-π/4, π/4], from Cephes
+var sinCoeffs = [...]float64{
+	1.58962301576546568060e-10,
+	-2.50507477628578072866e-8,
+	2.75573136213857245213e-6,
+	-1.98412698295895385996e-4,
+	8.33333333332211858878e-3,
+	-1.66666666666666307295e-1,
+}
+
+var cosCoeffs = [...]float64{
+	-1.13585365213876817300e-11,
+	2.08757008419747316778e-9,
+	-2.75573141792967388112e-7,
+	2.48015872888517045348e-5,
+	-1.38888888888730564116e-3,
+	4.16666666666665929218e-2,
+}
+
+// Rational approximation of tanh on [0, 0.625), from Cephes
+var tanhP = [...]float64{
+	-9.64399179425052238628e-1,
+	-9.92877231001918586564e1,
+	-1.61468768441708447952e3,
+}
+
+var tanhQ = [...]float64{
+	1.12811678491632931402e2,
+	2.23548839060100448583e3,
+	4.84406305325125486048e3,
+}
+
+// Sin returns the sine of the radian argument x
+func Sin(x float64) float64 {
+	switch {
+	case x == 0 || math.IsNaN(x):
+		return x
+	case math.IsInf(x, 0):
+		return math.NaN()
+	}
+	sign := false
+	if x < 0 {
+		x = -x
+		sign = true
+	}
+	if x >= reduceThreshold {
+		if sign {
+			return -math.Sin(x)
+		}
+		return math.Sin(x)
+	}
+
+	j, z := reduce(x)
+	if j > 3 {
+		sign = !sign
+		j -= 4
+	}
+	var y float64
+	if j == 1 || j == 2 {
+		y = cosPoly(z)
+	} else {
+		y = sinPoly(z)
+	}
+	if sign {
+		y = -y
+	}
+	return y
+}
+
+// Cos returns the cosine of the radian argument x
+func Cos(x float64) float64 {
+	switch {
+	case math.IsNaN(x) || math.IsInf(x, 0):
+		return math.NaN()
+	}
+	x = math.Abs(x)
+	if x >= reduceThreshold {
+		return math.Cos(x)
+	}
+
+	j, z := reduce(x)
+	sign := false
+	if j > 3 {
+		j -= 4
+		sign = !sign
+	}
+	if j > 1 {
+		sign = !sign
+	}
+	var y float64
+	if j == 1 || j == 2 {
+		y = sinPoly(z)
+	} else {
+		y = cosPoly(z)
+	}
+	if sign {
+		y = -y
+	}
+	return y
+}
+
+// reduce maps x ≥ 0 to its octant j modulo 2π and the remainder z in
+// [-π/4, π/4]
+func reduce(x float64) (uint64, float64) {
+	j := uint64(float64(x * (4 / math.Pi)))
+	y := float64(j)
+
+	// Map zeros to the origin
+	if j&1 == 1 {
+		j++
+		y++
+	}
+	j &= 7
+	z := ((x - float64(y*pi4A)) - float64(y*pi4B)) - float64(y*pi4C)
+	return j, z
+}
+
+// sinPoly approximates sin(z) for z in [-π/4, π/4]
+func sinPoly(z float64) float64 {
+	zz := float64(z * z)
+	return z + float64(float64(z*zz)*horner(zz, sinCoeffs[:]))
+}
+
+// cosPoly approximates cos(z) for z in [-π/4, π/4]
+func cosPoly(z float64) float64 {
+	zz := float64(z * z)
+	return 1.0 - float64(0.5*zz) + float64(float64(zz*zz)*horner(zz, cosCoeffs[:]))
+}
+
+// horner evaluates ((c0·x + c1)·x + c2)... without fused multiply-adds
+func horner(x float64, coeffs []float64) float64 {
+	sum := float64(coeffs[0] * x)
+	for _, c := range coeffs[1 : len(coeffs)-1] {
+		sum = float64((sum + c) * x)
+	}
+	return sum + coeffs[len(coeffs)-1]
+}
+
+// Exp returns e**x
+func Exp(x float64) float64 {
+	const (
+		ln2Hi = 6.93147180369123816490e-01
+		ln2Lo = 1.90821492927058770002e-10
+		log2e = 1.44269504088896338700e+00
+
+		overflow  = 7.09782712893383973096e+02
+		underflow = -7.45133219101941108420e+02
+		nearZero  = 1.0 / (1 << 28)
+	)
+	switch {
+	case math.IsNaN(x):
+		return x
+	case x > overflow:
+		return math.Inf(1)
+	case x < underflow:
+		return 0
+	case -nearZero < x && x < nearZero:
+		return 1 + x
+	}
+
+	// Reduce to r = hi - lo with |r| ≤ ln(2)/2
+	var k int
+	switch {
+	case x < 0:
+		k = int(float64(log2e*x) - 0.5)
+	case x > 0:
+		k = int(float64(log2e*x) + 0.5)
+	}
+	hi := x - float64(float64(k)*ln2Hi)
+	lo := float64(float64(k) * ln2Lo)
+	return expMulti(hi, lo, k)
+}
+
+// expMulti returns e**r × 2**k where r = hi - lo and |r| ≤ ln(2)/2
+func expMulti(hi, lo float64, k int) float64 {
+	const (
+		p1 = 1.66666666666666657415e-01
+		p2 = -2.77777777770155933842e-03
+		p3 = 6.61375632143793436117e-05
+		p4 = -1.65339022054652515390e-06
+		p5 = 4.13813679705723846039e-08
+	)
+	r := hi - lo
+	t := float64(r * r)
+	poly := p4 + float64(t*p5)
+	poly = p3 + float64(t*poly)
+	poly = p2 + float64(t*poly)
+	poly = p1 + float64(t*poly)
+	c := r - float64(t*poly)
+	y := 1 - ((lo - float64(r*c)/(2-c)) - hi)
+	return math.Ldexp(y, k)
+}
+
+// Tanh returns the hyperbolic tangent of x
+func Tanh(x float64) float64 {
+	const maxLog = 8.8029691931113054295988e+01 // log(2**127)
+	z := math.Abs(x)
+	switch {
+	case z > 0.5*maxLog:
+		if x < 0 {
+			return -1
+		}
+		return 1
+	case z >= 0.625:
+		s := Exp(2 * z)
+		z = 1 - 2/(s+1)
+		if x < 0 {
+			z = -z
+		}
+	default:
+		if x == 0 {
+			return x
+		}
+		s := float64(x * x)
+		num := float64((float64(tanhP[0]*s)+tanhP[1])*s) + tanhP[2]
+		den := float64((float64((s+tanhQ[0])*s)+tanhQ[1])*s) + tanhQ[2]
+		z = x + float64(float64(x*s)*num)/den
+	}
+	return z
+}
@@ -0,0 +1,138 @@
+package detmath
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateVectors = flag.Bool("update", false, "rewrite test vector fixtures in testdata")
+
+// vector is one input and the exact output expected for it
+type vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// functions are the ports under test, by fixture name
+var functions = map[string]struct {
+	port, std func(float64) float64
+}{
+	"sin":  {Sin, math.Sin},
+	"cos":  {Cos, math.Cos},
+	"exp":  {Exp, math.Exp},
+	"tanh": {Tanh, math.Tanh},
+}
+
+// inputs covers every octant of sin and cos, both branches of tanh, and
+// the special cases
+func inputs() []float64 {
+	xs := []float64{0, math.Copysign(0, -1), 1e-300, -1e-9, 0.625, -0.625, 0.6249999999, 44.1, -44.1, 700, -740}
+	for x := -12.0; x <= 12; x += 0.173 {
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+func TestVectors(t *testing.T) {
+	recorded := make(map[string][]vector)
+	for name, f := range functions {
+		for _, x := range inputs() {
+			recorded[name] = append(recorded[name], vector{X: x, Y: f.port(x)})
+		}
+	}
+
+	path := filepath.Join("testdata", "vectors.json")
+	if *updateVectors {
+		data, err := json.MarshalIndent(recorded, "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to marshal vectors: %v", err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("Failed to write vectors: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read vectors (run with -update to create them): %v", err)
+	}
+	var golden map[string][]vector
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("Failed to unmarshal vectors: %v", err)
+	}
+	for name, f := range functions {
+		if len(golden[name]) == 0 {
+			t.Errorf("%s: no vectors", name)
+		}
+		for _, v := range golden[name] {
+			if got := f.port(v.X); math.Float64bits(got) != math.Float64bits(v.Y) {
+				t.Errorf("%s(%v) = %v, want exactly %v", name, v.X, got, v.Y)
+			}
+		}
+	}
+}
+
+func TestAgreesWithMath(t *testing.T) {
+	for name, f := range functions {
+		for _, x := range inputs() {
+			got, want := f.port(x), f.std(x)
+			if got == want {
+				continue
+			}
+			// The standard library may differ by rounding on some platforms
+			if ulp := math.Nextafter(math.Abs(want), math.Inf(1)) - math.Abs(want); math.Abs(got-want) > ulp {
+				t.Errorf("%s(%v) = %v, math gives %v", name, x, got, want)
+			}
+		}
+	}
+	if !math.IsNaN(Sin(math.Inf(1))) || !math.IsNaN(Cos(math.NaN())) || Exp(math.Inf(-1)) != 0 || Tanh(math.Inf(-1)) != -1 {
+		t.Errorf("special cases differ from the math package")
+	}
+}
+
+// hotPaths are the files every simulated step and forward pass runs through
+var hotPaths = []string{
+	"../env/integrator.go",
+	"../env/pendulum.go",
+	"../env/disturbance.go",
+	"../env/bounds.go",
+	"../reward/reward.go",
+	"../reward/shaping.go",
+	"../neural/network.go",
+	"../neural/observation.go",
+	"../neural/action.go",
+	"../neural/recurrent.go",
+}
+
+// platformDependent are math functions whose results vary by platform
+var platformDependent = map[string]bool{
+	"Sin": true, "Cos": true, "Tan": true, "Exp": true, "Tanh": true,
+	"Pow": true, "Sinh": true, "Cosh": true, "Log": true, "FMA": true,
+}
+
+func TestHotPathsUsePortableMath(t *testing.T) {
+	fset := token.NewFileSet()
+	for _, path := range hotPaths {
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "math" && platformDependent[sel.Sel.Name] {
+				t.Errorf("%s: math.%s varies by platform; use detmath or plain arithmetic", fset.Position(sel.Pos()), sel.Sel.Name)
+			}
+			return true
+		})
+	}
+}
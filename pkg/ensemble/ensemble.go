@@ -9,15 +9,21 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/curriculum"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/reward"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
 )
 
 // NetworkInstance represents a single neural network with its trainer and metrics
 type NetworkInstance struct {
 	ID            int
+	Genome        int // Identifies the current weights in the lineage; changes when the network is bred
 	Network       *neural.Network
+	Controller    agent.Controller // Chooses each force; the network unless replaced with SetController
 	Trainer       *training.Trainer
 	Pendulum      *env.Pendulum
 	CurrentTicks  int
@@ -26,8 +32,21 @@ type NetworkInstance struct {
 	SuccessRate   float64
 	AvgReward     float64
 	LastHiddenActivation float64
+	LastForce     float64 // Force applied in the most recent step
+	LastStepReward float64 // Reward of the most recent step
+	LastHumanForce float64 // Force added by hand in the most recent step, see SetHumanForce
 	PrevState     env.State
 	Failed        bool
+
+	// Running totals of the current episode, and the results of the last
+	// finished one scored by the ensemble's fitness
+	EpisodeReward float64
+	EpisodeEffort float64 // Sum of (force/MaxForce)²
+	LastTicks     int
+	LastReward    float64
+	LastEffort    float64 // Mean of (force/MaxForce)² over the last episode
+
+	shaper        *reward.Shaper // Optional force and jerk penalties; nil without reward shaping
 }
 
 // Ensemble manages multiple neural networks trained in parallel
@@ -36,16 +55,38 @@ type Ensemble struct {
 	BestNetworkIdx int
 	Logger         *log.Logger
 	Config         Config
+	PendulumConfig env.Config             // Base pendulum config before curriculum adjustments
+	Curriculum     *curriculum.Controller // Optional initial-condition and disturbance curriculum
+	Generation     int                    // Completed rounds of evolution
+	fitness        Fitness                // Ranks networks for selection
+	nextGenome     int                    // Genome ID given to the next bred network
+	metrics        *metrics.Logger        // Optional generation and lineage recorder
+	newController  func(instance *NetworkInstance) agent.Controller // Set by SetController
+	humanForce     float64                // Set by SetHumanForce
+	start          *env.State             // Initial state of every episode, set by SetScenario
+	events         env.Schedule           // Disturbances of every episode, set by SetScenario
 	mutex          sync.RWMutex
 }
 
 // Config holds ensemble configuration parameters
 type Config struct {
-	NetworkCount    int
-	MutationRate    float64
-	CrossoverRate   float64
-	SelectionRate   float64
-	ReplacementRate float64
+	NetworkCount     int
+	MutationRate     float64
+	CrossoverRate    float64 // Probability that a non-elite child is bred from two parents instead of cloned
+	SelectionRate    float64
+	ReplacementRate  float64
+	CrossoverAverage bool // Average matching genes instead of copying each from a random parent
+	ActionSpace      neural.ActionSpace // Forces every network may apply
+	Preset           string             // Pendulum preset name recorded with each network's checkpoints
+	Fitness          string             // Selection criterion: "ticks", "reward", "efficiency" or "composite"
+	FitnessWeights   FitnessComponents  // Weights of each component in the composite fitness
+	Success          env.SuccessCriteria // Decides which episodes succeed, for the trainers, curriculum and metrics
+	LearningRate     float64            // Base learning rate of every trainer (0 keeps the training default)
+	RewardShaping    reward.Shaping     // Optional reward terms, e.g. force and jerk penalties for smoother control
+	Features         neural.FeatureConfig // Inputs every network sees, e.g. stacked frames (zero for raw angle and angular velocity)
+	Recurrent        bool               // Give every network a recurrent cell, trained by truncated backpropagation through time
+	Strategy         string             // How each generation improves the networks: "genetic" or "pbt"
+	PerturbFactor    float64            // With the pbt strategy, how far copied hyperparameters are scaled up or down
 }
 
 // NewDefaultConfig returns a default ensemble configuration
@@ -56,6 +97,12 @@ func NewDefaultConfig() Config {
 		CrossoverRate:   0.2,
 		SelectionRate:   0.3,
 		ReplacementRate: 0.1,
+		ActionSpace:     neural.NewDefaultActionSpace(),
+		Fitness:         TicksFitness,
+		FitnessWeights:  FitnessComponents{Ticks: 1, Reward: 1, Efficiency: 1},
+		Success:         env.NewDefaultSuccessCriteria(),
+		Strategy:        GeneticStrategy,
+		PerturbFactor:   0.2,
 	}
 }
 
@@ -68,34 +115,7 @@ func NewEnsemble(config Config, pendulumConfig env.Config, logger *log.Logger) *
 	networks := make([]*NetworkInstance, config.NetworkCount)
 	
 	for i := 0; i < config.NetworkCount; i++ {
-		// Create a new network with slightly different initial weights
-		network := neural.NewNetwork()
-		network.SetDebug(i == 0) // Only enable debug for the first network
-		
-		// Add some variation to initial weights
-		weights := network.GetWeights()
-		for j := range weights {
-			// Add small random variations to each network
-			variation := (float64(i) / float64(config.NetworkCount) - 0.5) * 0.2
-			weights[j] += variation
-		}
-		network.SetWeights(weights)
-		
-		// Create trainer with default config
-		trainingConfig := training.NewDefaultConfig()
-		trainer := training.NewTrainer(trainingConfig, network, logger)
-		
-		// Create pendulum instance
-		pendulum := env.NewPendulum(pendulumConfig, logger)
-		
-		networks[i] = &NetworkInstance{
-			ID:       i,
-			Network:  network,
-			Trainer:  trainer,
-			Pendulum: pendulum,
-			PrevState: pendulum.GetState(),
-			Failed:   false,
-		}
+		networks[i] = newNetworkInstance(i, config, env.NewPendulum(pendulumConfig, logger), logger)
 	}
 	
 	return &Ensemble{
@@ -103,6 +123,209 @@ func NewEnsemble(config Config, pendulumConfig env.Config, logger *log.Logger) *
 		BestNetworkIdx: 0,
 		Logger:         logger,
 		Config:         config,
+		PendulumConfig: pendulumConfig,
+		fitness:        newFitnessOrTicks(config, logger),
+		nextGenome:     config.NetworkCount,
+	}
+}
+
+// newFitnessOrTicks returns the configured fitness, falling back to ticks
+// when the name is unknown
+func newFitnessOrTicks(config Config, logger *log.Logger) Fitness {
+	fitness, err := NewFitness(config)
+	if err != nil {
+		logger.Printf("%v; ranking by ticks", err)
+		fitness, _ = NewFitness(Config{Fitness: TicksFitness})
+	}
+	return fitness
+}
+
+// newNetworkInstance creates network i of the ensemble with fresh weights,
+// a new trainer and the given pendulum
+func newNetworkInstance(i int, config Config, pendulum *env.Pendulum, logger *log.Logger) *NetworkInstance {
+	// Create a new network with slightly different initial weights
+	network := neural.NewNetwork()
+	network.SetDebug(i == 0) // Only enable debug for the first network
+	network.SetPreset(config.Preset)
+	if config.Features != (neural.FeatureConfig{}) {
+		network.SetObservationTransformer(neural.NewObservationTransformer(config.Features))
+	}
+	if config.Recurrent {
+		network.EnableRecurrent()
+	}
+	
+	// Add some variation to initial weights
+	weights := network.GetWeights()
+	for j := range weights {
+		// Add small random variations to each network
+		variation := (float64(i) / float64(config.NetworkCount) - 0.5) * 0.2
+		weights[j] += variation
+	}
+	network.SetWeights(weights)
+	
+	// Create trainer with default config and the ensemble's action space
+	trainingConfig := training.NewDefaultConfig()
+	trainingConfig.ActionSpace = config.ActionSpace
+	trainingConfig.Success = config.Success
+	if config.LearningRate > 0 {
+		trainingConfig.BaseLearningRate = config.LearningRate
+	}
+	trainer := training.NewTrainer(trainingConfig, network, logger)
+	
+	var shaper *reward.Shaper
+	if len(config.RewardShaping) > 0 {
+		var err error
+		if shaper, err = reward.NewShaper(config.RewardShaping, config.ActionSpace.MaxForce); err != nil {
+			logger.Printf("Network %d trains without reward shaping: %v", i, err)
+		}
+	}
+	
+	return &NetworkInstance{
+		ID:       i,
+		Genome:   i,
+		Network:  network,
+		Controller: network,
+		Trainer:  trainer,
+		Pendulum: pendulum,
+		PrevState: pendulum.GetState(),
+		Failed:   false,
+		shaper:   shaper,
+	}
+}
+
+// Reset discards everything the ensemble has learned: every network gets
+// fresh initial weights, a new trainer and a new episode. The config and
+// curriculum are kept
+func (e *Ensemble) Reset() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i := range e.Networks {
+		e.Networks[i] = newNetworkInstance(i, e.Config, e.newPendulum(), e.Logger)
+		e.attachController(e.Networks[i])
+	}
+	e.BestNetworkIdx = 0
+	e.Generation = 0
+	e.nextGenome = len(e.Networks)
+	e.Logger.Printf("Ensemble reset to fresh weights")
+}
+
+// RandomizeStates restarts the current episode of every running network
+// from a centered cart with a random angle in [-maxAngle, maxAngle] and
+// angular velocity in [-maxAngularVel, maxAngularVel]. Training continues
+// without ending the episode
+func (e *Ensemble) RandomizeStates(rng *rand.Rand, maxAngle, maxAngularVel float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, instance := range e.Networks {
+		if instance.Failed {
+			continue
+		}
+		instance.Pendulum.Reset(env.State{
+			AngleRadians: (rng.Float64()*2 - 1) * maxAngle,
+			AngularVel:   (rng.Float64()*2 - 1) * maxAngularVel,
+		})
+		instance.PrevState = instance.Pendulum.GetState()
+		instance.Network.ResetHistory()
+		instance.CurrentTicks = 0
+	}
+}
+
+// Disturb applies the same horizontal impulse in N·s to the bob of every
+// running network's pendulum, positive to the right
+func (e *Ensemble) Disturb(impulse float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, instance := range e.Networks {
+		if !instance.Failed {
+			instance.Pendulum.ApplyImpulse(impulse)
+		}
+	}
+}
+
+// SetHumanForce adds a force in N, positive to the right, to the force of
+// every running network from the next step on, e.g. while the cart is
+// dragged by hand. The networks still learn from their own forces; zero
+// hands the cart back to them
+func (e *Ensemble) SetHumanForce(force float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.humanForce = force
+}
+
+// HumanForce returns the force set with SetHumanForce
+func (e *Ensemble) HumanForce() float64 {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.humanForce
+}
+
+// SetScenario restarts every running network's episode from initial, and
+// starts every later episode there too, with the events applied at their
+// ticks of each episode, e.g. to train on a scenario file. It takes
+// precedence over a curriculum's initial conditions
+func (e *Ensemble) SetScenario(initial env.State, events env.Schedule) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.start = &initial
+	e.events = events
+	for _, instance := range e.Networks {
+		if instance.Failed {
+			continue
+		}
+		instance.Pendulum = e.newPendulum()
+		instance.PrevState = instance.Pendulum.GetState()
+		instance.Network.ResetHistory()
+		instance.CurrentTicks = 0
+	}
+}
+
+// SetCurriculum makes every new episode start from initial conditions and
+// disturbances sampled by the curriculum, which progresses with the
+// ensemble's success unless it follows a difficulty source
+func (e *Ensemble) SetCurriculum(c *curriculum.Controller) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.Curriculum = c
+}
+
+// CurriculumDifficulty returns the difficulty new episodes are sampled at,
+// false without a curriculum
+func (e *Ensemble) CurriculumDifficulty() (float64, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	if e.Curriculum == nil {
+		return 0, false
+	}
+	return e.Curriculum.Difficulty(), true
+}
+
+// newPendulum creates a pendulum for a new episode
+func (e *Ensemble) newPendulum() *env.Pendulum {
+	if e.start != nil {
+		pendulum := env.NewPendulum(e.PendulumConfig, e.Logger)
+		pendulum.Reset(*e.start)
+		return pendulum
+	}
+	if e.Curriculum != nil {
+		return e.Curriculum.NewPendulum(e.PendulumConfig, e.Logger)
+	}
+	return env.NewPendulum(e.PendulumConfig, e.Logger)
+}
+
+// logCurriculum records the difficulty the curriculum started the latest
+// episode at
+func (e *Ensemble) logCurriculum() {
+	if e.metrics == nil || e.Curriculum == nil || e.start != nil {
+		return
+	}
+	if level, ok := e.Curriculum.Last(); ok {
+		if err := e.metrics.LogCurriculum(level.Difficulty, level.Values()); err != nil {
+			e.Logger.Printf("Failed to record curriculum difficulty: %v", err)
+		}
 	}
 }
 
@@ -126,15 +349,29 @@ func (e *Ensemble) Step() error {
 		// Get current state
 		state := instance.Pendulum.GetState()
 		
-		// Get force from network and store hidden activation
-		force, hiddenActivation := instance.Network.ForwardWithActivation(state)
+		// Get force from the controller and store hidden activation
+		force, hiddenActivation := act(instance.Controller, state)
 		instance.LastHiddenActivation = hiddenActivation
 		
-		// Apply force and get new state
-		newState, err := instance.Pendulum.Step(force)
+		// Apply force, with any pushed by hand or scripted, and get new state
+		e.events.Apply(instance.Pendulum, instance.CurrentTicks)
+		newState, done, err := instance.Pendulum.Advance(force + e.humanForce)
+		instance.LastHumanForce = e.humanForce
+		if err != nil {
+			e.Logger.Printf("Network %d step failed: %v", instance.ID, err)
+		}
 		
-		// Calculate reward for this step
+		// Calculate reward for this step, less any force and jerk penalties
 		stepReward := calculateReward(instance.PrevState, state)
+		if instance.shaper != nil {
+			stepReward -= instance.shaper.Penalty(force)
+		}
+		instance.EpisodeReward += stepReward
+		instance.LastForce = force
+		instance.LastStepReward = stepReward
+		if maxForce := instance.Network.GetActionSpace().MaxForce; maxForce > 0 {
+			instance.EpisodeEffort += (force / maxForce) * (force / maxForce)
+		}
 		
 		// Create experience for training
 		experience := training.Experience{
@@ -142,28 +379,57 @@ func (e *Ensemble) Step() error {
 			Action:    force,
 			Reward:    stepReward,
 			NextState: newState,
-			Done:      err != nil,
+			Done:      done,
 			TimeStep:  uint64(instance.CurrentTicks),
 		}
 		
 		// Add experience to trainer
-		instance.Trainer.AddExperience(experience)
+		instance.Trainer.Observe(experience)
 		
-		if err != nil {
+		if done {
 			// Mark as failed
 			instance.Failed = true
+			if e.metrics != nil {
+				if err := e.metrics.LogTermination(string(instance.Pendulum.GetTermination()), instance.CurrentTicks); err != nil {
+					e.Logger.Printf("Failed to record termination: %v", err)
+				}
+				if err := e.metrics.LogFailure(instance.Pendulum.Failure(), instance.CurrentTicks, instance.Pendulum.GetState()); err != nil {
+					e.Logger.Printf("Failed to record failure mode: %v", err)
+				}
+			}
 			
 			// Handle end of episode
-			instance.Trainer.OnEpisodeEnd(instance.CurrentTicks)
+			success := instance.Trainer.OnEpisodeEnd(instance.CurrentTicks)
+			if e.metrics != nil {
+				if err := e.metrics.LogLearningRate(instance.Trainer.LearningRateSchedule(), instance.Trainer.LearningRate()); err != nil {
+					e.Logger.Printf("Failed to record learning rate: %v", err)
+				}
+			}
+			if instance.shaper != nil {
+				instance.shaper.Reset()
+			}
+			instance.Network.ResetHistory()
 			
 			// Update max ticks if this was the best episode
 			if instance.CurrentTicks > instance.MaxTicks {
 				instance.MaxTicks = instance.CurrentTicks
 			}
 			
+			// Keep the episode's results for selection
+			instance.LastTicks = instance.CurrentTicks
+			instance.LastReward = instance.EpisodeReward
+			instance.LastEffort = instance.EpisodeEffort / float64(instance.CurrentTicks+1)
+			instance.EpisodeReward = 0
+			instance.EpisodeEffort = 0
+			
+			// Let the curriculum judge the episode before sampling the next one
+			if e.Curriculum != nil {
+				e.Curriculum.RecordEpisode(success)
+			}
+			
 			// Reset pendulum for next episode
-			config := instance.Pendulum.GetConfig()
-			instance.Pendulum = env.NewPendulum(config, e.Logger)
+			instance.Pendulum = e.newPendulum()
+			e.logCurriculum()
 			instance.PrevState = instance.Pendulum.GetState()
 			instance.Episodes++
 			instance.CurrentTicks = 0
@@ -198,6 +464,37 @@ func (e *Ensemble) Step() error {
 	return nil
 }
 
+// act asks the controller for a force, along with the hidden activation
+// when the controller is a network
+func act(controller agent.Controller, state env.State) (float64, float64) {
+	if network, ok := controller.(*neural.Network); ok {
+		return network.ForwardWithActivation(state)
+	}
+	return controller.Act(state), 0
+}
+
+// SetController makes every network act through the controller built by
+// newController, e.g. an explorer around instance.Network or a planner
+// baseline. Training and evolution still update each Network. Pass nil to
+// act with the networks again
+func (e *Ensemble) SetController(newController func(instance *NetworkInstance) agent.Controller) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.newController = newController
+	for _, instance := range e.Networks {
+		e.attachController(instance)
+	}
+}
+
+// attachController sets the instance's controller from the SetController
+// factory, or to its network
+func (e *Ensemble) attachController(instance *NetworkInstance) {
+	instance.Controller = instance.Network
+	if e.newController != nil {
+		instance.Controller = e.newController(instance)
+	}
+}
+
 // GetBestNetwork returns the best performing network instance
 func (e *Ensemble) GetBestNetwork() *NetworkInstance {
 	e.mutex.RLock()
@@ -230,16 +527,25 @@ func (e *Ensemble) GetAllNetworkStats() []map[string]interface{} {
 
 // evolveNetworks implements a simple evolutionary algorithm to improve networks
 func (e *Ensemble) evolveNetworks() {
-	e.Logger.Printf("Evolving networks after generation completed")
+	e.Generation++
+	e.Logger.Printf("Evolving networks after generation %d completed", e.Generation)
 	
-	// Sort networks by performance (max ticks)
-	sort.Slice(e.Networks, func(i, j int) bool {
-		return e.Networks[i].MaxTicks > e.Networks[j].MaxTicks
-	})
+	// Sort networks by fitness
+	fitness := e.currentFitness()
+	scores := e.rankByFitness(fitness)
+	var lineage []metrics.Lineage
 	
 	// Update best network index (should be 0 after sorting)
 	e.BestNetworkIdx = 0
 	
+	if e.Config.Strategy == PBTStrategy {
+		lineage = e.exploitAndExplore(scores)
+		e.recordGeneration(fitness.Name(), scores, lineage)
+		e.Logger.Printf("Population-based training round completed. Best network #%d with %d ticks",
+			e.Networks[0].ID, e.Networks[0].MaxTicks)
+		return
+	}
+	
 	// Keep the top networks unchanged
 	eliteCount := int(float64(len(e.Networks)) * e.Config.SelectionRate)
 	if eliteCount < 1 {
@@ -270,11 +576,20 @@ func (e *Ensemble) evolveNetworks() {
 		
 		// Update network weights
 		e.Networks[i].Network.SetWeights(childWeights)
+		e.Networks[i].Genome = e.newGenome()
+		lineage = append(lineage, metrics.Lineage{
+			Genome:        e.Networks[i].Genome,
+			Generation:    e.Generation,
+			Parent:        e.Networks[parentIdx].Genome,
+			SecondParent:  metrics.NoParent,
+			ParentFitness: scores[parentIdx],
+			Origin:        MutationOrigin,
+		})
 		
 		// Reset pendulum and stats
-		config := e.Networks[i].Pendulum.GetConfig()
-		e.Networks[i].Pendulum = env.NewPendulum(config, e.Logger)
+		e.Networks[i].Pendulum = e.newPendulum()
 		e.Networks[i].PrevState = e.Networks[i].Pendulum.GetState()
+		e.Networks[i].Network.ResetHistory()
 		e.Networks[i].CurrentTicks = 0
 		e.Networks[i].Episodes = 0
 		e.Networks[i].Failed = false
@@ -286,17 +601,33 @@ func (e *Ensemble) evolveNetworks() {
 		parent1Idx := i % eliteCount
 		parent2Idx := (i + 1) % eliteCount
 		
-		// Get parent weights
-		parent1Weights := e.Networks[parent1Idx].Network.GetWeights()
-		parent2Weights := e.Networks[parent2Idx].Network.GetWeights()
+		// Networks are sorted by performance, so the lower index is the fitter parent
+		fitterIdx, otherIdx := parent1Idx, parent2Idx
+		if otherIdx < fitterIdx {
+			fitterIdx, otherIdx = otherIdx, fitterIdx
+		}
+		fitterWeights := e.Networks[fitterIdx].Network.GetWeights()
+		otherWeights := e.Networks[otherIdx].Network.GetWeights()
+		
+		// Breed with probability CrossoverRate, otherwise clone the fitter parent
+		var childWeights []float64
+		origin := metrics.Lineage{
+			Generation:    e.Generation,
+			Parent:        e.Networks[fitterIdx].Genome,
+			SecondParent:  metrics.NoParent,
+			ParentFitness: scores[fitterIdx],
+			Origin:        MutationOrigin,
+		}
+		if rand.Float64() < e.Config.CrossoverRate {
+			childWeights = crossover(fitterWeights, otherWeights, e.Config.CrossoverAverage)
+			origin.SecondParent = e.Networks[otherIdx].Genome
+			origin.Origin = CrossoverOrigin
+		} else {
+			childWeights = make([]float64, len(fitterWeights))
+			copy(childWeights, fitterWeights)
+		}
 		
-		// Create child weights through crossover
-		childWeights := make([]float64, len(parent1Weights))
 		for j := range childWeights {
-			// Crossover with random weighting
-			alpha := rand.Float64()
-			childWeights[j] = alpha*parent1Weights[j] + (1-alpha)*parent2Weights[j]
-			
 			// Apply small mutation
 			mutation := (rand.Float64() * 2 - 1) * e.Config.MutationRate * 0.5
 			childWeights[j] += mutation
@@ -304,20 +635,84 @@ func (e *Ensemble) evolveNetworks() {
 		
 		// Update network weights
 		e.Networks[i].Network.SetWeights(childWeights)
+		e.Networks[i].Genome = e.newGenome()
+		origin.Genome = e.Networks[i].Genome
+		lineage = append(lineage, origin)
 		
 		// Reset pendulum and stats
-		config := e.Networks[i].Pendulum.GetConfig()
-		e.Networks[i].Pendulum = env.NewPendulum(config, e.Logger)
+		e.Networks[i].Pendulum = e.newPendulum()
 		e.Networks[i].PrevState = e.Networks[i].Pendulum.GetState()
+		e.Networks[i].Network.ResetHistory()
 		e.Networks[i].CurrentTicks = 0
 		e.Networks[i].Episodes = 0
 		e.Networks[i].Failed = false
 	}
 	
+	e.recordGeneration(fitness.Name(), scores, lineage)
+	
 	e.Logger.Printf("Network evolution completed. Best network #%d with %d ticks", 
 		e.BestNetworkIdx, e.Networks[e.BestNetworkIdx].MaxTicks)
 }
 
+// currentFitness returns the fitness networks are ranked by
+func (e *Ensemble) currentFitness() Fitness {
+	if e.fitness == nil {
+		return newFitnessOrTicks(Config{}, e.Logger)
+	}
+	return e.fitness
+}
+
+// rankByFitness sorts the networks fittest first, logs the fitness
+// components of the best network and the population average, and returns
+// the scores in rank order
+func (e *Ensemble) rankByFitness(fitness Fitness) []float64 {
+	scores := make(map[*NetworkInstance]float64, len(e.Networks))
+	var mean FitnessComponents
+	for _, instance := range e.Networks {
+		stats := instance.FitnessStats()
+		scores[instance] = fitness.Score(stats)
+		c := stats.Components()
+		n := float64(len(e.Networks))
+		mean.Ticks += c.Ticks / n
+		mean.Reward += c.Reward / n
+		mean.Efficiency += c.Efficiency / n
+	}
+	sort.SliceStable(e.Networks, func(i, j int) bool {
+		return scores[e.Networks[i]] > scores[e.Networks[j]]
+	})
+
+	best := e.Networks[0]
+	c := best.FitnessStats().Components()
+	e.Logger.Printf("Generation %d %s fitness: best #%d score %.2f (ticks %.0f, reward %.2f, efficiency %.1f); mean ticks %.1f, reward %.2f, efficiency %.1f",
+		e.Generation, fitness.Name(), best.ID, scores[best], c.Ticks, c.Reward, c.Efficiency,
+		mean.Ticks, mean.Reward, mean.Efficiency)
+
+	ranked := make([]float64, len(e.Networks))
+	for i, instance := range e.Networks {
+		ranked[i] = scores[instance]
+	}
+	return ranked
+}
+
+// crossover combines two parent genomes gene by gene. Genes are aligned by
+// index, which plays the role of an innovation number for the fixed topology:
+// matching genes are averaged or copied from a randomly chosen parent, and any
+// disjoint or excess genes are inherited from the fitter parent.
+func crossover(fitter, other []float64, average bool) []float64 {
+	child := make([]float64, len(fitter))
+	copy(child, fitter)
+	
+	for j := 0; j < len(fitter) && j < len(other); j++ {
+		if average {
+			child[j] = (fitter[j] + other[j]) / 2
+		} else if rand.Float64() < 0.5 {
+			child[j] = other[j]
+		}
+	}
+	
+	return child
+}
+
 // getNetworkStatus returns a string describing the network's status
 func getNetworkStatus(instance *NetworkInstance) string {
 	if instance.Failed {
@@ -0,0 +1,611 @@
+package ensemble
+
+import (
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/curriculum"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/reward"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
+)
+
+func TestCrossover(t *testing.T) {
+	fitter := []float64{1.0, 2.0, 3.0}
+	other := []float64{-1.0, -2.0, -3.0}
+
+	t.Run("average", func(t *testing.T) {
+		child := crossover(fitter, other, true)
+		for i, w := range child {
+			if w != 0 {
+				t.Errorf("gene %d: got %.4f, want 0 (average of parents)", i, w)
+			}
+		}
+	})
+
+	t.Run("random_parent", func(t *testing.T) {
+		child := crossover(fitter, other, false)
+		for i, w := range child {
+			if w != fitter[i] && w != other[i] {
+				t.Errorf("gene %d: got %.4f, want a gene from either parent", i, w)
+			}
+		}
+	})
+
+	t.Run("excess_from_fitter", func(t *testing.T) {
+		child := crossover([]float64{1, 2, 3, 4}, []float64{5, 6}, false)
+		if len(child) != 4 {
+			t.Fatalf("child has %d genes, want 4", len(child))
+		}
+		if child[2] != 3 || child[3] != 4 {
+			t.Errorf("excess genes %v, want inherited from fitter parent", child[2:])
+		}
+	})
+}
+
+func TestInteractiveControls(t *testing.T) {
+	newEnsemble := func() *Ensemble {
+		config := NewDefaultConfig()
+		config.NetworkCount = 3
+		return NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		e := newEnsemble()
+		fresh := e.Networks[1].Network.GetWeights()
+		e.Networks[1].Network.SetWeights([]float64{9, 9, 9})
+		e.Networks[1].Episodes = 7
+		e.Networks[1].MaxTicks = 300
+		e.BestNetworkIdx = 1
+
+		e.Reset()
+		if got := e.Networks[1].Network.GetWeights(); got[0] != fresh[0] || got[2] != fresh[2] {
+			t.Errorf("weights after reset = %v, want %v", got, fresh)
+		}
+		if n := e.Networks[1]; n.Episodes != 0 || n.MaxTicks != 0 || e.BestNetworkIdx != 0 {
+			t.Errorf("stats after reset: episodes %d, max ticks %d, best %d", n.Episodes, n.MaxTicks, e.BestNetworkIdx)
+		}
+	})
+
+	t.Run("randomize", func(t *testing.T) {
+		e := newEnsemble()
+		for i := 0; i < 5; i++ {
+			e.Step()
+		}
+		e.RandomizeStates(rand.New(rand.NewSource(1)), 0.3, 1)
+		for _, n := range e.Networks {
+			state := n.Pendulum.GetState()
+			angle := env.UprightOffset(state.AngleRadians)
+			if math.Abs(angle) > 0.3 || math.Abs(state.AngularVel) > 1 || state.CartPosition != 0 {
+				t.Errorf("network %d state %+v outside the randomized bounds", n.ID, state)
+			}
+			if n.CurrentTicks != 0 || n.PrevState != state {
+				t.Errorf("network %d episode was not restarted: ticks %d", n.ID, n.CurrentTicks)
+			}
+		}
+	})
+
+	t.Run("learning rate", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.NetworkCount = 2
+		config.LearningRate = 0.01
+		e := NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+		e.Reset()
+		for _, n := range e.Networks {
+			if lr := n.Trainer.GetTrainingStats()["learningRate"]; lr != 0.01 {
+				t.Errorf("network %d learning rate = %v, want 0.01", n.ID, lr)
+			}
+		}
+	})
+
+	t.Run("disturb", func(t *testing.T) {
+		e := newEnsemble()
+		config := env.NewDefaultConfig()
+		e.RandomizeStates(rand.New(rand.NewSource(1)), 0, 0) // Upright and at rest
+		e.Disturb(0.05)
+		want := 0.05 / (config.PendulumMass * config.Length)
+		for _, n := range e.Networks {
+			if got := n.Pendulum.GetState().AngularVel; math.Abs(got-want) > 1e-12 {
+				t.Errorf("network %d angular velocity = %v after impulse, want %v", n.ID, got, want)
+			}
+		}
+	})
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	config := NewDefaultConfig()
+	config.NetworkCount = 3
+	quiet := log.New(io.Discard, "", 0)
+	e := NewEnsemble(config, env.NewDefaultConfig(), quiet)
+	for i := 0; i < 20; i++ {
+		e.Step()
+	}
+	e.Networks[2].Network.SetWeights([]float64{1.5, -0.5, 0.25})
+	e.Networks[2].MaxTicks = 120
+	e.Networks[2].Episodes = 4
+	e.BestNetworkIdx = 2
+	e.Generation = 3
+	e.Networks[1].Genome = e.newGenome()
+
+	dir := t.TempDir()
+	if err := e.SaveToDir(dir); err != nil {
+		t.Fatalf("SaveToDir failed: %v", err)
+	}
+
+	// Load into an ensemble built with different settings
+	otherConfig := NewDefaultConfig()
+	otherConfig.NetworkCount = 5
+	loaded := NewEnsemble(otherConfig, env.NewDefaultConfig(), quiet)
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatalf("LoadFromDir failed: %v", err)
+	}
+
+	if len(loaded.Networks) != 3 || loaded.Config.NetworkCount != 3 {
+		t.Fatalf("loaded %d networks with config %+v, want 3", len(loaded.Networks), loaded.Config)
+	}
+	if loaded.nextGenome != e.nextGenome {
+		t.Errorf("next genome %d, want %d", loaded.nextGenome, e.nextGenome)
+	}
+	if loaded.Generation != 3 || loaded.BestNetworkIdx != 2 {
+		t.Errorf("generation %d, best %d; want 3 and 2", loaded.Generation, loaded.BestNetworkIdx)
+	}
+	for i, n := range loaded.Networks {
+		want := e.Networks[i].Network.GetWeights()
+		for j, w := range n.Network.GetWeights() {
+			if w != want[j] {
+				t.Errorf("network %d weight %d = %v, want %v", i, j, w, want[j])
+			}
+		}
+		if n.Genome != e.Networks[i].Genome {
+			t.Errorf("network %d genome = %d, want %d", i, n.Genome, e.Networks[i].Genome)
+		}
+		if n.MaxTicks != e.Networks[i].MaxTicks || n.Episodes != e.Networks[i].Episodes {
+			t.Errorf("network %d stats: max ticks %d, episodes %d; want %d and %d",
+				i, n.MaxTicks, n.Episodes, e.Networks[i].MaxTicks, e.Networks[i].Episodes)
+		}
+	}
+
+	if err := loaded.LoadFromDir(t.TempDir()); err == nil {
+		t.Error("expected error loading from an empty directory")
+	}
+	if len(loaded.Networks) != 3 {
+		t.Errorf("failed load changed the ensemble to %d networks", len(loaded.Networks))
+	}
+}
+
+func TestFitness(t *testing.T) {
+	// A long but forceful episode against a shorter, gentler one
+	forceful := FitnessStats{MaxTicks: 200, Ticks: 200, Reward: 10, MeanEffort: 0.9}
+	gentle := FitnessStats{MaxTicks: 150, Ticks: 150, Reward: 30, MeanEffort: 0.1}
+
+	cases := []struct {
+		name   string
+		fitter FitnessStats
+	}{
+		{TicksFitness, forceful},
+		{RewardFitness, gentle},
+		{EfficiencyFitness, gentle},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Fitness = c.name
+			fitness, err := NewFitness(config)
+			if err != nil {
+				t.Fatalf("NewFitness failed: %v", err)
+			}
+			other := forceful
+			if c.fitter == forceful {
+				other = gentle
+			}
+			if fitness.Score(c.fitter) <= fitness.Score(other) {
+				t.Errorf("%s scored %+v (%.2f) no higher than %+v (%.2f)", c.name,
+					c.fitter, fitness.Score(c.fitter), other, fitness.Score(other))
+			}
+		})
+	}
+
+	t.Run("composite", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.Fitness = CompositeFitness
+		config.FitnessWeights = FitnessComponents{Ticks: 1, Reward: 2, Efficiency: 0.5}
+		fitness, _ := NewFitness(config)
+		want := 200 + 2*10.0 + 0.5*200*(1-0.9)
+		if got := fitness.Score(forceful); math.Abs(got-want) > 1e-9 {
+			t.Errorf("composite score = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := NewFitness(Config{Fitness: "style"}); err == nil {
+			t.Error("expected error for an unknown fitness")
+		}
+	})
+
+	t.Run("selection ranks by fitness", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.NetworkCount = 3
+		config.Fitness = RewardFitness
+		e := NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+		for i, n := range e.Networks {
+			n.MaxTicks = 100 * (i + 1) // Ticks alone would pick the last network
+			n.LastReward = float64(10 - i)
+		}
+
+		e.evolveNetworks()
+		if e.Networks[0].ID != 0 || e.Generation != 1 {
+			t.Errorf("fittest network #%d after generation %d, want #0 after 1", e.Networks[0].ID, e.Generation)
+		}
+	})
+}
+
+func TestGenerationMetrics(t *testing.T) {
+	quiet := log.New(io.Discard, "", 0)
+	db := metrics.NewMemoryStore()
+	logger, err := metrics.NewStoreLogger(db, false, quiet)
+	if err != nil {
+		t.Fatalf("NewStoreLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	config := NewDefaultConfig()
+	config.NetworkCount = 4
+	e := NewEnsemble(config, env.NewDefaultConfig(), quiet)
+	e.SetMetricsLogger(logger)
+	for generation := 0; generation < 3; generation++ {
+		for i, n := range e.Networks {
+			n.MaxTicks = 10 * (i + 1)
+		}
+		e.evolveNetworks()
+	}
+
+	generations, err := db.GetGenerations(logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("GetGenerations failed: %v", err)
+	}
+	if len(generations) != 3 {
+		t.Fatalf("recorded %d generations, want 3", len(generations))
+	}
+	for i, g := range generations {
+		if g.Generation != i+1 || g.Population != 4 || g.Fitness != TicksFitness {
+			t.Errorf("generation %d recorded as %+v", i+1, g)
+		}
+		if g.BestFitness != 40 || g.MeanFitness != 25 || math.Abs(g.StdFitness-math.Sqrt(125)) > 1e-9 {
+			t.Errorf("generation %d fitness best %v, mean %v, std %v; want 40, 25, %.4f",
+				g.Generation, g.BestFitness, g.MeanFitness, g.StdFitness, math.Sqrt(125))
+		}
+		if g.Mutations+g.Crossovers != 3 {
+			t.Errorf("generation %d bred %d mutations and %d crossovers, want 3 children",
+				g.Generation, g.Mutations, g.Crossovers)
+		}
+	}
+
+	// The last slot is bred every generation, so its ancestry reaches back
+	// through earlier children to the initial population
+	child := e.Networks[3].Genome
+	if child < config.NetworkCount {
+		t.Fatalf("bred network kept initial genome %d", child)
+	}
+	ancestry, err := db.GetAncestry(logger.GetSessionID(), child)
+	if err != nil {
+		t.Fatalf("GetAncestry failed: %v", err)
+	}
+	if len(ancestry) == 0 || ancestry[0].Genome != child || ancestry[0].Generation != 3 {
+		t.Fatalf("ancestry of genome %d = %+v", child, ancestry)
+	}
+	for i := 1; i < len(ancestry); i++ {
+		if ancestry[i].Genome != ancestry[i-1].Parent || ancestry[i].Generation >= ancestry[i-1].Generation {
+			t.Errorf("ancestry link %d does not lead to an earlier parent: %+v", i, ancestry)
+		}
+	}
+	if root := ancestry[len(ancestry)-1].Parent; root < 0 || root >= config.NetworkCount {
+		t.Errorf("ancestry ends at genome %d, want one of the initial population", root)
+	}
+}
+
+func TestSetController(t *testing.T) {
+	config := NewDefaultConfig()
+	config.NetworkCount = 2
+	e := NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+
+	calls := 0
+	e.SetController(func(instance *NetworkInstance) agent.Controller {
+		return agent.ControllerFunc(func(state env.State) float64 {
+			calls++
+			return 1.5
+		})
+	})
+	e.Step()
+	if calls != 2 {
+		t.Errorf("controller called %d times in one step, want once per network", calls)
+	}
+
+	// Networks rebuilt by Reset keep the custom controller
+	e.Reset()
+	calls = 0
+	e.Step()
+	if calls != 2 {
+		t.Errorf("controller called %d times after Reset, want 2", calls)
+	}
+
+	e.SetController(nil)
+	for _, n := range e.Networks {
+		if n.Controller != agent.Controller(n.Network) {
+			t.Errorf("network #%d does not act through its network after SetController(nil)", n.ID)
+		}
+	}
+}
+
+func TestHumanForce(t *testing.T) {
+	config := NewDefaultConfig()
+	config.NetworkCount = 2
+	e := NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+	e.SetController(func(instance *NetworkInstance) agent.Controller {
+		return agent.ControllerFunc(func(state env.State) float64 { return 0 })
+	})
+	e.RandomizeStates(rand.New(rand.NewSource(1)), 0, 0) // Upright and at rest
+
+	e.SetHumanForce(5)
+	if got := e.HumanForce(); got != 5 {
+		t.Errorf("HumanForce() = %v, want 5", got)
+	}
+	e.Step()
+	for _, n := range e.Networks {
+		if n.LastHumanForce != 5 || n.LastForce != 0 {
+			t.Errorf("network #%d: human force %v, own force %v; want 5 and 0", n.ID, n.LastHumanForce, n.LastForce)
+		}
+		if v := n.Pendulum.GetState().CartVelocity; v <= 0 {
+			t.Errorf("network #%d cart velocity = %v after a push to the right, want positive", n.ID, v)
+		}
+	}
+
+	e.SetHumanForce(0)
+	e.Step()
+	for _, n := range e.Networks {
+		if n.LastHumanForce != 0 {
+			t.Errorf("network #%d human force = %v after release, want 0", n.ID, n.LastHumanForce)
+		}
+	}
+}
+
+func TestSetScenario(t *testing.T) {
+	config := NewDefaultConfig()
+	config.NetworkCount = 2
+	e := NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+	e.SetController(func(instance *NetworkInstance) agent.Controller {
+		return agent.ControllerFunc(func(state env.State) float64 { return 0 })
+	})
+
+	initial := env.State{AngleRadians: 0.1}
+	e.SetScenario(initial, env.Schedule{{Tick: 2, Force: 3}})
+	for _, n := range e.Networks {
+		if n.Pendulum.GetState() != initial || n.CurrentTicks != 0 {
+			t.Errorf("network #%d starts from %+v at tick %d, want %+v at 0", n.ID, n.Pendulum.GetState(), n.CurrentTicks, initial)
+		}
+	}
+	for tick := 0; tick < 3; tick++ {
+		e.Step()
+		want := 0.0
+		if tick == 2 {
+			want = 3
+		}
+		for _, n := range e.Networks {
+			if got := n.Pendulum.GetLastDisturbance().Pushed; got != want {
+				t.Errorf("tick %d network #%d: pushed %v, want %v", tick, n.ID, got, want)
+			}
+		}
+	}
+
+	// Later episodes start from the scenario too
+	if p := e.newPendulum(); p.GetState() != initial {
+		t.Errorf("new episode starts from %+v, want %+v", p.GetState(), initial)
+	}
+}
+
+// constantDifficulty is a curriculum.DifficultySource at a fixed difficulty
+type constantDifficulty float64
+
+func (c constantDifficulty) GetDifficulty() float64 { return float64(c) }
+
+func TestCurriculum(t *testing.T) {
+	quiet := log.New(io.Discard, "", 0)
+	db := metrics.NewMemoryStore()
+	logger, err := metrics.NewStoreLogger(db, false, quiet)
+	if err != nil {
+		t.Fatalf("NewStoreLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	config := NewDefaultConfig()
+	config.NetworkCount = 2
+	e := NewEnsemble(config, env.NewDefaultConfig(), quiet)
+	e.SetMetricsLogger(logger)
+	for _, n := range e.Networks {
+		n.Trainer.SetCheckpointDirectory(t.TempDir())
+	}
+	if _, ok := e.CurriculumDifficulty(); ok {
+		t.Error("CurriculumDifficulty reported a difficulty without a curriculum")
+	}
+	e.SetController(func(instance *NetworkInstance) agent.Controller {
+		return agent.ControllerFunc(func(state env.State) float64 { return 5 })
+	})
+	c := curriculum.NewController(curriculum.New(curriculum.NewDefaultConfig(), rand.New(rand.NewSource(1)), quiet), constantDifficulty(0.6))
+	e.SetCurriculum(c)
+
+	// Pushing one way drives the cart off the track, ending episodes
+	for step := 0; step < 2000; step++ {
+		e.Step()
+		if _, ok := c.Last(); ok {
+			break
+		}
+	}
+	if _, ok := c.Last(); !ok {
+		t.Fatal("no episode was started by the curriculum")
+	}
+	if difficulty, ok := e.CurriculumDifficulty(); !ok || difficulty != 0.6 {
+		t.Errorf("CurriculumDifficulty() = %v, %v, want the source's 0.6", difficulty, ok)
+	}
+	if got := e.Networks[0].Pendulum.GetConfig().WindNoise; got <= 0 {
+		t.Errorf("next episode's wind noise = %v, want the curriculum's disturbances", got)
+	}
+
+	curve, err := db.GetMetricCurve(logger.GetSessionID(), metrics.CurriculumDifficulty, "difficulty")
+	if err != nil {
+		t.Fatalf("GetMetricCurve failed: %v", err)
+	}
+	if len(curve) == 0 || curve[0].Mean != 0.6 {
+		t.Errorf("recorded difficulty curve %+v, want episodes at 0.6", curve)
+	}
+}
+
+func TestRewardShaping(t *testing.T) {
+	newEnsemble := func(shaping reward.Shaping) *Ensemble {
+		config := NewDefaultConfig()
+		config.NetworkCount = 2
+		config.RewardShaping = shaping
+		return NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+	}
+	plain := newEnsemble(nil)
+	shaped := newEnsemble(reward.Shaping{reward.ForceTerm: 0.5, reward.JerkTerm: 1})
+
+	for step := 0; step < 3; step++ {
+		plain.Step()
+		shaped.Step()
+		for i, n := range shaped.Networks {
+			p := plain.Networks[i]
+			if n.LastForce != p.LastForce {
+				t.Fatalf("step %d: network %d applied %v, want the unshaped %v", step, i, n.LastForce, p.LastForce)
+			}
+			f := n.LastForce / n.Network.GetActionSpace().MaxForce
+			if penalty := p.LastStepReward - n.LastStepReward; penalty < 0.5*f*f-1e-12 {
+				t.Errorf("step %d: network %d penalty %v is less than the force term %v", step, i, penalty, 0.5*f*f)
+			}
+		}
+	}
+}
+
+func TestStackedFrames(t *testing.T) {
+	config := NewDefaultConfig()
+	config.NetworkCount = 2
+	config.Features = neural.FeatureConfig{Frames: 3}
+	e := NewEnsemble(config, env.NewDefaultConfig(), log.New(io.Discard, "", 0))
+
+	for step := 0; step < 3; step++ {
+		e.Step()
+	}
+	for i, n := range e.Networks {
+		names := n.Network.InputNames()
+		inputs := n.Network.GetLastInputs()
+		if len(names) != 6 || len(inputs) != 6 {
+			t.Fatalf("network %d has inputs %v (%v), want 3 frames of angle and angular velocity", i, names, inputs)
+		}
+		if inputs[2] == inputs[4] && inputs[3] == inputs[5] {
+			t.Errorf("network %d stacked %v, want the previous frame to differ from the one before", i, inputs)
+		}
+	}
+}
+
+func TestPopulationBasedTraining(t *testing.T) {
+	quiet := log.New(io.Discard, "", 0)
+	config := NewDefaultConfig()
+	config.NetworkCount = 5
+	config.Strategy = PBTStrategy
+	config.SelectionRate = 0.4
+	config.ReplacementRate = 0.4
+	e := NewEnsemble(config, env.NewDefaultConfig(), quiet)
+
+	// Network i is the (i+1)th fittest, with its own weights and hyperparameters
+	for i, n := range e.Networks {
+		n.MaxTicks = 100 - 10*i
+		n.Failed = true
+		n.Network.SetWeights([]float64{float64(i), float64(i), float64(i)})
+		n.Trainer.SetHyperparameters(training.Hyperparameters{LearningRate: 0.01 * float64(i+1), Gamma: 0.9})
+	}
+	e.evolveNetworks()
+
+	// The middle network keeps training as it was
+	middle := e.Networks[2]
+	if w := middle.Network.GetWeights(); w[0] != 2 || middle.Trainer.LearningRate() != 0.03 {
+		t.Errorf("middle network changed: weights %v, learning rate %v", w, middle.Trainer.LearningRate())
+	}
+
+	// The worst two copy an elite and perturb its hyperparameters
+	for _, n := range e.Networks[3:] {
+		parent := int(n.Network.GetWeights()[0])
+		if parent != 0 && parent != 1 {
+			t.Fatalf("network #%d copied weights %v, want an elite's", n.ID, n.Network.GetWeights())
+		}
+		h := n.Trainer.Hyperparameters()
+		base := 0.01 * float64(parent+1)
+		if math.Abs(h.LearningRate-0.8*base) > 1e-12 && math.Abs(h.LearningRate-1.2*base) > 1e-12 {
+			t.Errorf("network #%d learning rate %v, want %v scaled by 0.8 or 1.2", n.ID, h.LearningRate, base)
+		}
+		if math.Abs(h.Gamma-0.72) > 1e-12 && math.Abs(h.Gamma-0.999) > 1e-12 {
+			t.Errorf("network #%d gamma %v, want 0.9 scaled down or capped below 1", n.ID, h.Gamma)
+		}
+		if n.Genome < config.NetworkCount {
+			t.Errorf("network #%d kept genome %d after copying", n.ID, n.Genome)
+		}
+	}
+	for _, n := range e.Networks {
+		if n.Failed {
+			t.Errorf("network #%d did not start the next generation", n.ID)
+		}
+	}
+
+	// Perturbed hyperparameters survive saving
+	dir := t.TempDir()
+	if err := e.SaveToDir(dir); err != nil {
+		t.Fatalf("SaveToDir failed: %v", err)
+	}
+	loaded := NewEnsemble(NewDefaultConfig(), env.NewDefaultConfig(), quiet)
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatalf("LoadFromDir failed: %v", err)
+	}
+	if loaded.Config.Strategy != PBTStrategy {
+		t.Errorf("loaded strategy %q, want %q", loaded.Config.Strategy, PBTStrategy)
+	}
+	for i, n := range loaded.Networks {
+		if got, want := n.Trainer.Hyperparameters(), e.Networks[i].Trainer.Hyperparameters(); got != want {
+			t.Errorf("network #%d loaded hyperparameters %+v, want %+v", n.ID, got, want)
+		}
+	}
+}
+
+func TestSeedGenomes(t *testing.T) {
+	quiet := log.New(io.Discard, "", 0)
+	source := NewEnsemble(NewDefaultConfig(), env.NewDefaultConfig(), quiet)
+	source.Networks[0].Network.SetWeights([]float64{1, 2, 3})
+	genomes := source.Genomes()
+	if len(genomes) != len(source.Networks) {
+		t.Fatalf("got %d genomes, want one per network", len(genomes))
+	}
+
+	target := NewEnsemble(NewDefaultConfig(), env.NewDefaultConfig(), quiet)
+	before := target.Networks[1].Network.GetWeights()
+	if err := target.SeedGenomes(genomes[:1]); err != nil {
+		t.Fatalf("SeedGenomes: %v", err)
+	}
+	if w := target.Networks[0].Network.GetWeights(); w[0] != 1 || w[1] != 2 || w[2] != 3 {
+		t.Errorf("seeded network has weights %v, want [1 2 3]", w)
+	}
+	if w := target.Networks[1].Network.GetWeights(); w[0] != before[0] {
+		t.Errorf("unseeded network changed from %v to %v", before, w)
+	}
+
+	config := NewDefaultConfig()
+	config.Features.SinCos = true
+	other := NewEnsemble(config, env.NewDefaultConfig(), quiet)
+	if err := other.SeedGenomes(genomes[:1]); err == nil {
+		t.Errorf("SeedGenomes accepted genomes with other inputs")
+	}
+	if err := target.SeedGenomes(append(genomes, genomes...)); err == nil {
+		t.Errorf("SeedGenomes accepted more genomes than networks")
+	}
+}
@@ -0,0 +1,97 @@
+package ensemble
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fitness names accepted in Config.Fitness
+const (
+	TicksFitness      = "ticks"      // Longest episode so far, the original selection
+	RewardFitness     = "reward"     // Cumulative reward of the generation's episode
+	EfficiencyFitness = "efficiency" // Episode length discounted by the force used
+	CompositeFitness  = "composite"  // Weighted sum of the other three
+)
+
+// FitnessStats are the measurements a fitness function can score. Each
+// generation every network runs one episode, summarized here
+type FitnessStats struct {
+	MaxTicks   int     // Longest episode so far
+	Ticks      int     // Length of the generation's episode
+	Reward     float64 // Cumulative reward of the generation's episode
+	MeanEffort float64 // Mean of (force/MaxForce)² over the episode, in [0, 1]
+}
+
+// Components returns the value of each built-in fitness for the stats
+func (s FitnessStats) Components() FitnessComponents {
+	return FitnessComponents{
+		Ticks:      float64(s.MaxTicks),
+		Reward:     s.Reward,
+		Efficiency: float64(s.Ticks) * (1 - s.MeanEffort),
+	}
+}
+
+// FitnessComponents holds one value per built-in fitness, and doubles as
+// the weights of the composite fitness
+type FitnessComponents struct {
+	Ticks      float64 `json:"ticks"`
+	Reward     float64 `json:"reward"`
+	Efficiency float64 `json:"efficiency"`
+}
+
+// Fitness scores a network for selection; higher is fitter. Implement it
+// to plug a custom criterion into the ensemble with SetFitness
+type Fitness interface {
+	Name() string
+	Score(stats FitnessStats) float64
+}
+
+// componentFitness is a built-in fitness: a weighted sum of the components
+type componentFitness struct {
+	name    string
+	weights FitnessComponents
+}
+
+func (f componentFitness) Name() string {
+	return f.name
+}
+
+func (f componentFitness) Score(stats FitnessStats) float64 {
+	c := stats.Components()
+	return f.weights.Ticks*c.Ticks + f.weights.Reward*c.Reward + f.weights.Efficiency*c.Efficiency
+}
+
+// NewFitness returns the built-in fitness named in the config. The
+// composite fitness weighs the components by config.FitnessWeights
+func NewFitness(config Config) (Fitness, error) {
+	name := strings.ToLower(config.Fitness)
+	switch name {
+	case "", TicksFitness:
+		return componentFitness{name: TicksFitness, weights: FitnessComponents{Ticks: 1}}, nil
+	case RewardFitness:
+		return componentFitness{name: name, weights: FitnessComponents{Reward: 1}}, nil
+	case EfficiencyFitness:
+		return componentFitness{name: name, weights: FitnessComponents{Efficiency: 1}}, nil
+	case CompositeFitness:
+		return componentFitness{name: name, weights: config.FitnessWeights}, nil
+	default:
+		return nil, fmt.Errorf("unknown fitness %q (want ticks, reward, efficiency or composite)", config.Fitness)
+	}
+}
+
+// SetFitness replaces the fitness used to rank networks for selection
+func (e *Ensemble) SetFitness(fitness Fitness) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.fitness = fitness
+}
+
+// FitnessStats returns the measurements of the network's latest episode
+func (n *NetworkInstance) FitnessStats() FitnessStats {
+	return FitnessStats{
+		MaxTicks:   n.MaxTicks,
+		Ticks:      n.LastTicks,
+		Reward:     n.LastReward,
+		MeanEffort: n.LastEffort,
+	}
+}
@@ -0,0 +1,47 @@
+package ensemble
+
+import (
+	"fmt"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/evolution"
+)
+
+// Genomes exports every member in member order, scored by the ensemble's
+// fitness on its latest episode
+func (e *Ensemble) Genomes() []evolution.Genome {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	fitness := e.currentFitness()
+	genomes := make([]evolution.Genome, len(e.Networks))
+	for i, instance := range e.Networks {
+		genomes[i] = evolution.NewGenome(instance.Network, fitness.Score(instance.FitnessStats()), e.Generation)
+		if genomes[i].Preset == "" {
+			genomes[i].Preset = e.Config.Preset
+		}
+	}
+	return genomes
+}
+
+// SeedGenomes starts the first len(genomes) members from shared genomes
+// instead of random weights, e.g. to evolve a population from controllers
+// found elsewhere. The genomes must match the members' inputs and
+// recurrent cell; the rest of the population is unchanged
+func (e *Ensemble) SeedGenomes(genomes []evolution.Genome) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(genomes) > len(e.Networks) {
+		return fmt.Errorf("%d genomes do not fit an ensemble of %d networks", len(genomes), len(e.Networks))
+	}
+	for i, genome := range genomes {
+		instance := e.Networks[i]
+		if err := genome.ApplyWeights(instance.Network); err != nil {
+			return fmt.Errorf("failed to seed network #%d: %w", instance.ID, err)
+		}
+		instance.Trainer.ResetOptimizer()
+		e.Logger.Printf("Seeded network #%d from a generation %d genome with fitness %.4f",
+			instance.ID, genome.Generation, genome.Fitness)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package ensemble
+
+import (
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+)
+
+// Origins recorded in the lineage of bred genomes
+const (
+	MutationOrigin  = "mutation"  // Mutated copy of a single elite
+	CrossoverOrigin = "crossover" // Mutated crossover of two elites
+	ExploitOrigin   = "exploit"   // Copy of an elite with perturbed hyperparameters, by population-based training
+)
+
+// SetMetricsLogger records each generation's fitness statistics, the
+// lineage of every bred genome, the compute budget spent and why each
+// episode ended to the logger's session. Pass nil to stop
+func (e *Ensemble) SetMetricsLogger(logger *metrics.Logger) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.metrics = logger
+	if logger != nil {
+		logger.SetSuccessCriteria(e.Config.Success)
+	}
+}
+
+// newGenome returns an unused genome ID. A network slot keeps its ID across
+// generations while the genome in it changes each time it is bred
+func (e *Ensemble) newGenome() int {
+	genome := e.nextGenome
+	e.nextGenome++
+	return genome
+}
+
+// recordGeneration logs the statistics of the generation ranked by scores,
+// fittest first, along with the lineage bred from it
+func (e *Ensemble) recordGeneration(fitness string, scores []float64, lineage []metrics.Lineage) {
+	if e.metrics == nil || len(scores) == 0 {
+		return
+	}
+
+	stats := metrics.GenerationStats{
+		Generation:  e.Generation,
+		Fitness:     fitness,
+		Population:  len(scores),
+		BestFitness: scores[0],
+		BestGenome:  e.Networks[0].Genome,
+	}
+	for _, score := range scores {
+		stats.MeanFitness += score / float64(len(scores))
+	}
+	for _, score := range scores {
+		d := score - stats.MeanFitness
+		stats.StdFitness += d * d / float64(len(scores))
+	}
+	stats.StdFitness = math.Sqrt(stats.StdFitness)
+	for _, l := range lineage {
+		if l.Origin == CrossoverOrigin {
+			stats.Crossovers++
+		} else {
+			stats.Mutations++
+		}
+	}
+
+	if err := e.metrics.LogGeneration(stats, lineage); err != nil {
+		e.Logger.Printf("Failed to record generation %d metrics: %v", e.Generation, err)
+	}
+
+	// The session's effort is the sum of every slot's trainer
+	var budget metrics.ComputeBudget
+	for _, instance := range e.Networks {
+		budget = budget.Add(instance.Trainer.Budget())
+	}
+	if err := e.metrics.LogBudget(budget); err != nil {
+		e.Logger.Printf("Failed to record compute budget: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package ensemble
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+)
+
+// Strategies accepted in Config.Strategy
+const (
+	GeneticStrategy = "genetic" // Breed new weights by mutation and crossover each generation (default)
+	PBTStrategy     = "pbt"     // Population-based training: keep training by gradient descent, copying and perturbing only the worst
+)
+
+// StrategyNames lists the accepted Config.Strategy values
+func StrategyNames() []string {
+	return []string{GeneticStrategy, PBTStrategy}
+}
+
+// ValidateStrategy checks the configured strategy
+func ValidateStrategy(strategy string) error {
+	switch strategy {
+	case "", GeneticStrategy, PBTStrategy:
+		return nil
+	}
+	return fmt.Errorf("unknown strategy %q (want %s)", strategy, strings.Join(StrategyNames(), " or "))
+}
+
+// exploitAndExplore is one round of population-based training. Every
+// network keeps its weights and trainer, still learning by gradient
+// descent, except the worst ReplacementRate of them: each of those copies
+// the weights and hyperparameters of a random member of the top
+// SelectionRate (exploit), then scales each hyperparameter up or down by
+// PerturbFactor (explore). The networks must be ranked fittest first
+func (e *Ensemble) exploitAndExplore(scores []float64) []metrics.Lineage {
+	eliteCount := max(1, int(float64(len(e.Networks))*e.Config.SelectionRate))
+	replaceCount := max(1, int(float64(len(e.Networks))*e.Config.ReplacementRate))
+	replaceCount = min(replaceCount, len(e.Networks)-eliteCount)
+
+	var lineage []metrics.Lineage
+	for i := len(e.Networks) - replaceCount; i < len(e.Networks); i++ {
+		parentIdx := rand.Intn(eliteCount)
+		parent, child := e.Networks[parentIdx], e.Networks[i]
+
+		child.Network.SetWeights(parent.Network.GetWeights())
+		hyperparameters := parent.Trainer.Hyperparameters().Perturb(e.Config.PerturbFactor, nil)
+		child.Trainer.SetHyperparameters(hyperparameters)
+		child.Trainer.ResetOptimizer()
+		child.Genome = e.newGenome()
+		lineage = append(lineage, metrics.Lineage{
+			Genome:        child.Genome,
+			Generation:    e.Generation,
+			Parent:        parent.Genome,
+			SecondParent:  metrics.NoParent,
+			ParentFitness: scores[parentIdx],
+			Origin:        ExploitOrigin,
+		})
+		e.Logger.Printf("PBT: network #%d copies #%d, learning rate %.4f, gamma %.3f",
+			child.ID, parent.ID, hyperparameters.LearningRate, hyperparameters.Gamma)
+	}
+
+	// Everyone starts the next generation's episode together
+	for _, instance := range e.Networks {
+		instance.Pendulum = e.newPendulum()
+		instance.PrevState = instance.Pendulum.GetState()
+		instance.Network.ResetHistory()
+		instance.CurrentTicks = 0
+		instance.Failed = false
+	}
+	return lineage
+}
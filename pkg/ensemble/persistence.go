@@ -0,0 +1,173 @@
+package ensemble
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
+)
+
+// StateVersion is the current saved ensemble format
+const StateVersion = 1
+
+// ManifestFile is the ensemble-wide state written by SaveToDir. Each
+// member's network and trainer go in a session checkpoint next to it
+const ManifestFile = "ensemble.json"
+
+// State is the ensemble-wide part of a saved evolutionary run
+type State struct {
+	Version        int           `json:"version"`
+	SavedAt        time.Time     `json:"saved_at"`
+	Generation     int           `json:"generation"`
+	NextGenome     int           `json:"next_genome"`
+	BestNetworkIdx int           `json:"best_network_idx"`
+	Config         Config        `json:"config"`
+	PendulumConfig env.Config    `json:"pendulum_config"`
+	Members        []MemberState `json:"members"`
+}
+
+// MemberState is one network's stats and the session checkpoint holding
+// its weights and trainer
+type MemberState struct {
+	ID          int     `json:"id"`
+	Genome      int     `json:"genome"`
+	File        string  `json:"file"` // Session checkpoint relative to the directory
+	MaxTicks    int     `json:"max_ticks"`
+	Episodes    int     `json:"episodes"`
+	SuccessRate float64 `json:"success_rate"`
+	AvgReward   float64 `json:"avg_reward"`
+
+	// Hyperparameters the trainer runs with, which population-based training perturbs
+	Hyperparameters *training.Hyperparameters `json:"hyperparameters,omitempty"`
+}
+
+// SaveToDir writes every member's session checkpoint and the ensemble
+// manifest to dir. The manifest is replaced last, so an interrupted save
+// leaves the previous one loadable
+func (e *Ensemble) SaveToDir(dir string) error {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create ensemble directory: %w", err)
+	}
+
+	state := State{
+		Version:        StateVersion,
+		SavedAt:        time.Now(),
+		Generation:     e.Generation,
+		NextGenome:     e.nextGenome,
+		BestNetworkIdx: e.BestNetworkIdx,
+		Config:         e.Config,
+		PendulumConfig: e.PendulumConfig,
+	}
+	for i, instance := range e.Networks {
+		file := fmt.Sprintf("member_%02d.json", i)
+		hyperparameters := instance.Trainer.Hyperparameters()
+		if err := training.SaveSession(filepath.Join(dir, file), instance.Trainer.SessionState()); err != nil {
+			return fmt.Errorf("failed to save network #%d: %w", instance.ID, err)
+		}
+		state.Members = append(state.Members, MemberState{
+			ID:          instance.ID,
+			Genome:      instance.Genome,
+			File:        file,
+			MaxTicks:    instance.MaxTicks,
+			Episodes:    instance.Episodes,
+			SuccessRate: instance.SuccessRate,
+			AvgReward:   instance.AvgReward,
+			Hyperparameters: &hyperparameters,
+		})
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ensemble state: %w", err)
+	}
+	path := filepath.Join(dir, ManifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write ensemble state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace ensemble state: %w", err)
+	}
+
+	e.Logger.Printf("Saved ensemble of %d networks (generation %d) to %s", len(e.Networks), e.Generation, dir)
+	return nil
+}
+
+// LoadFromDir replaces the ensemble's members, config and generation with
+// a run saved by SaveToDir. Every member starts a fresh episode; the
+// curriculum, if any, is kept
+func (e *Ensemble) LoadFromDir(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return fmt.Errorf("failed to read ensemble state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to unmarshal ensemble state: %w", err)
+	}
+	if state.Version > StateVersion {
+		return fmt.Errorf("ensemble state version %d is newer than supported version %d", state.Version, StateVersion)
+	}
+	if len(state.Members) == 0 {
+		return fmt.Errorf("%s has no ensemble members", dir)
+	}
+	if state.Config.Success == (env.SuccessCriteria{}) {
+		// Saved before success criteria were configurable
+		state.Config.Success = env.NewDefaultSuccessCriteria()
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// Build the new members completely before replacing the running ones
+	previous := e.PendulumConfig
+	e.PendulumConfig = state.PendulumConfig
+	networks := make([]*NetworkInstance, len(state.Members))
+	for i, member := range state.Members {
+		session, err := training.LoadSession(filepath.Join(dir, member.File))
+		if err != nil {
+			e.PendulumConfig = previous
+			return fmt.Errorf("failed to load network #%d: %w", member.ID, err)
+		}
+		instance := newNetworkInstance(i, state.Config, e.newPendulum(), e.Logger)
+		if err := instance.Trainer.RestoreSession(session); err != nil {
+			e.PendulumConfig = previous
+			return fmt.Errorf("failed to restore network #%d: %w", member.ID, err)
+		}
+		if member.Hyperparameters != nil {
+			instance.Trainer.SetHyperparameters(*member.Hyperparameters)
+		}
+		instance.ID = member.ID
+		e.attachController(instance)
+		if state.NextGenome > 0 {
+			instance.Genome = member.Genome
+		}
+		instance.MaxTicks = member.MaxTicks
+		instance.Episodes = member.Episodes
+		instance.SuccessRate = member.SuccessRate
+		instance.AvgReward = member.AvgReward
+		networks[i] = instance
+	}
+
+	e.Networks = networks
+	e.Config = state.Config
+	if _, builtIn := e.fitness.(componentFitness); builtIn || e.fitness == nil {
+		e.fitness = newFitnessOrTicks(state.Config, e.Logger)
+	}
+	e.Generation = state.Generation
+	e.nextGenome = max(state.NextGenome, len(networks))
+	e.BestNetworkIdx = 0
+	if state.BestNetworkIdx >= 0 && state.BestNetworkIdx < len(networks) {
+		e.BestNetworkIdx = state.BestNetworkIdx
+	}
+
+	e.Logger.Printf("Loaded ensemble of %d networks (generation %d) from %s", len(networks), e.Generation, dir)
+	return nil
+}
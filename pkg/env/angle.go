@@ -0,0 +1,36 @@
+package env
+
+import "math"
+
+// State.AngleRadians is measured from upright: 0 is balanced and π hangs
+// straight down. Pendulum keeps it in [0, 2π), so an angle just left of
+// upright is stored near 2π. Code asking how far the pole is from balanced
+// converts with UprightOffset or UprightDeviation rather than wrapping or
+// subtracting π itself
+const (
+	UprightAngle = 0.0     // Balanced
+	HangingAngle = math.Pi // Hanging straight down, where a new Pendulum starts
+)
+
+// UprightOffset returns the signed angle from upright in [-π, π], the form
+// controllers, rewards and plots use. math.Remainder is exact, so the
+// result is the same on every platform
+func UprightOffset(angle float64) float64 {
+	return math.Remainder(angle-UprightAngle, 2*math.Pi)
+}
+
+// UprightDeviation returns the absolute angle from upright in [0, π]
+func UprightDeviation(angle float64) float64 {
+	return math.Abs(UprightOffset(angle))
+}
+
+// AngleFromUpright converts a signed offset from upright back to the
+// stored form in [0, 2π)
+func AngleFromUpright(offset float64) float64 {
+	return NormalizeAngle(UprightAngle + offset)
+}
+
+// IsBalanced reports whether angle lies within tolerance radians of upright
+func IsBalanced(angle, tolerance float64) bool {
+	return UprightDeviation(angle) <= tolerance
+}
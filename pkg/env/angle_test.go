@@ -0,0 +1,54 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+func TestUprightOffset(t *testing.T) {
+	tests := []struct {
+		angle float64
+		want  float64
+	}{
+		{UprightAngle, 0},
+		{0.2, 0.2},
+		{-0.2, -0.2},
+		{2*math.Pi - 0.2, -0.2},
+		{4*math.Pi + 0.2, 0.2},
+		{HangingAngle, math.Pi},
+	}
+	for _, tt := range tests {
+		got := UprightOffset(tt.angle)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("UprightOffset(%.3f) = %.4f, want %.4f", tt.angle, got, tt.want)
+		}
+		if back := AngleFromUpright(got); math.Abs(UprightOffset(back-tt.angle)) > 1e-9 || back < 0 || back >= 2*math.Pi {
+			t.Errorf("AngleFromUpright(%.4f) = %.4f, not %.3f in [0, 2π)", got, back, tt.angle)
+		}
+	}
+}
+
+func TestPendulumStartsHangingDown(t *testing.T) {
+	p := NewPendulum(NewDefaultConfig(), log.New(&bytes.Buffer{}, "", 0))
+	if got := UprightDeviation(p.GetState().AngleRadians); got != math.Pi {
+		t.Errorf("New pendulum is %.4f rad from upright, want π", got)
+	}
+	if IsBalanced(p.GetState().AngleRadians, NewDefaultSuccessCriteria().MaxAngle) {
+		t.Error("Expected a hanging pendulum not to count as balanced")
+	}
+
+	// An upright pole released at rest stays balanced for a moment on
+	// either side of 0
+	for _, offset := range []float64{-0.01, 0.01} {
+		p.Reset(State{AngleRadians: AngleFromUpright(offset)})
+		state, err := p.Step(0)
+		if err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+		if !IsBalanced(state.AngleRadians, 0.05) {
+			t.Errorf("Offset %.2f drifted to %.4f rad from upright in one step", offset, UprightOffset(state.AngleRadians))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package env
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds policies accepted in Config.Bounds
+const (
+	TerminateBounds = "terminate" // Leaving the track ends the episode (default)
+	ClampBounds     = "clamp"     // The cart stops dead at the edge
+	BounceBounds    = "bounce"    // The cart bounces elastically off the edge
+)
+
+// applyBounds enforces the configured bounds policy on a state that may
+// have left the track, reporting out when the episode should terminate
+func (p *Pendulum) applyBounds(s State) (State, bool, error) {
+	switch p.config.Bounds {
+	case "", TerminateBounds, ClampBounds, BounceBounds:
+	default:
+		return s, false, fmt.Errorf("unknown bounds policy %q", p.config.Bounds)
+	}
+
+	edge := p.config.TrackLength / 2
+	if math.Abs(s.CartPosition) <= edge {
+		return s, false, nil
+	}
+	wall := math.Copysign(edge, s.CartPosition)
+
+	switch p.config.Bounds {
+	case ClampBounds:
+		s.CartPosition = wall
+		s.CartVelocity = 0
+	case BounceBounds:
+		// Reflect the overshoot back onto the track, clamping in case it
+		// overshot by more than the whole track
+		s.CartPosition = math.Max(-edge, math.Min(2*wall-s.CartPosition, edge))
+		s.CartVelocity = -s.CartVelocity
+	default:
+		return s, true, nil
+	}
+
+	// The cart jumped, so rounding error carried from before no longer applies
+	p.carry.CartPosition = 0
+	p.carry.CartVelocity = 0
+	return s, false, nil
+}
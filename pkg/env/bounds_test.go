@@ -0,0 +1,139 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+// pushToEdge drives the cart right at full force on a short track with
+// Advance, returning every state and whether the episode ended
+func pushToEdge(t *testing.T, bounds string, steps int) ([]State, bool) {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.TrackLength = 2.0
+	config.Bounds = bounds
+	p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+
+	var states []State
+	for i := 0; i < steps; i++ {
+		state, done, err := p.Advance(config.MaxForce)
+		if err != nil {
+			t.Fatalf("%s step %d failed: %v", bounds, i, err)
+		}
+		states = append(states, state)
+		if done {
+			return states, true
+		}
+	}
+	return states, false
+}
+
+func TestBoundsPolicies(t *testing.T) {
+	t.Run("terminate", func(t *testing.T) {
+		for _, bounds := range []string{"", TerminateBounds} {
+			states, done := pushToEdge(t, bounds, 200)
+			if !done {
+				t.Fatalf("%q: expected the episode to end at the track edge", bounds)
+			}
+			last := states[len(states)-1]
+			if last.CartPosition <= 1.0 {
+				t.Errorf("%q: final position %.3f, want the state past the edge to be kept", bounds, last.CartPosition)
+			}
+			if last.TimeStep != uint64(len(states)) {
+				t.Errorf("%q: TimeStep = %d, want %d", bounds, last.TimeStep, len(states))
+			}
+		}
+	})
+
+	t.Run("clamp", func(t *testing.T) {
+		states, done := pushToEdge(t, ClampBounds, 200)
+		if done {
+			t.Fatal("clamped episode should not end")
+		}
+		clamped := false
+		for _, s := range states {
+			if s.CartPosition > 1.0 {
+				t.Fatalf("position %.3f beyond the edge", s.CartPosition)
+			}
+			if s.CartPosition == 1.0 {
+				clamped = true
+				if s.CartVelocity != 0 {
+					t.Errorf("velocity %.3f at the edge, want 0", s.CartVelocity)
+				}
+			}
+		}
+		if !clamped {
+			t.Error("expected the cart to be held at the edge")
+		}
+	})
+
+	t.Run("bounce", func(t *testing.T) {
+		states, done := pushToEdge(t, BounceBounds, 200)
+		if done {
+			t.Fatal("bouncing episode should not end")
+		}
+		bounced := false
+		for i := 1; i < len(states); i++ {
+			if math.Abs(states[i].CartPosition) > 1.0 {
+				t.Fatalf("position %.3f beyond the edge", states[i].CartPosition)
+			}
+			if states[i-1].CartVelocity > 0 && states[i].CartVelocity < 0 {
+				bounced = true
+			}
+		}
+		if !bounced {
+			t.Error("expected the cart to bounce back off the edge")
+		}
+	})
+
+	t.Run("step keeps the error", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.TrackLength = 2.0
+		config.WindNoise = 0.5
+		config.Seed = 3
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		twin := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		for i := 0; i < 200; i++ {
+			before, force, disturbance := p.GetState(), p.GetLastForce(), p.GetLastDisturbance()
+			if _, err := p.Step(config.MaxForce); err != nil {
+				if p.GetState() != before {
+					t.Error("Step should discard the state that left the track")
+				}
+				if p.GetLastForce() != force || p.GetLastDisturbance() != disturbance {
+					t.Error("Step should discard the force and disturbances of the step that left the track")
+				}
+
+				// The next step draws what the twin, which never tried the
+				// discarded one, draws
+				p.Reset(State{})
+				twin.Reset(State{})
+				if _, err := p.Step(0); err != nil {
+					t.Fatalf("Step after the reset failed: %v", err)
+				}
+				if _, _, err := twin.Advance(0); err != nil {
+					t.Fatalf("twin Advance failed: %v", err)
+				}
+				if p.GetState() != twin.GetState() || p.GetLastDisturbance() != twin.GetLastDisturbance() {
+					t.Error("the discarded step shifted the disturbance stream")
+				}
+				return
+			}
+			if _, _, err := twin.Advance(config.MaxForce); err != nil {
+				t.Fatalf("twin Advance failed: %v", err)
+			}
+		}
+		t.Error("Expected Step to fail at the track edge")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.Bounds = "wrap"
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		if _, done, err := p.Advance(0); err == nil || !done {
+			t.Errorf("Expected an error ending the episode, got done=%v err=%v", done, err)
+		}
+	})
+}
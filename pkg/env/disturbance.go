@@ -0,0 +1,56 @@
+package env
+
+import (
+	"math/rand"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
+)
+
+// Disturbance records the external effects applied during one Step,
+// so robustness evaluations can log what the controller was up against
+type Disturbance struct {
+	Impulse          float64 // impulse force on the cart in N (0 if none this step)
+	Wind             float64 // wind force on the cart in N
+	Pushed           float64 // scripted force on the cart in N, see Push
+	SensorAngle      float64 // noise added to the observed angle
+	SensorAngularVel float64 // noise added to the observed angular velocity
+}
+
+// Force returns the total external force on the cart
+func (d Disturbance) Force() float64 {
+	return d.Impulse + d.Wind + d.Pushed
+}
+
+// hasDisturbances reports whether any disturbance is enabled in the config
+func (c Config) hasDisturbances() bool {
+	return c.ImpulseProb > 0 || c.WindForce != 0 || c.WindNoise > 0 || c.SensorNoise > 0
+}
+
+// sampleDisturbance draws the disturbances for the next step
+func sampleDisturbance(config Config, rng *rand.Rand) Disturbance {
+	var d Disturbance
+	if !config.hasDisturbances() {
+		return d
+	}
+
+	if config.ImpulseProb > 0 && rng.Float64() < config.ImpulseProb {
+		d.Impulse = config.ImpulseForce
+		if rng.Intn(2) == 0 {
+			d.Impulse = -d.Impulse
+		}
+	}
+	d.Wind = config.WindForce + float64(config.WindNoise*rng.NormFloat64())
+	if config.SensorNoise > 0 {
+		d.SensorAngle = config.SensorNoise * rng.NormFloat64()
+		d.SensorAngularVel = config.SensorNoise * rng.NormFloat64()
+	}
+	return d
+}
+
+// ApplyImpulse kicks the pendulum bob with a horizontal impulse in N·s,
+// positive to the right, e.g. to stress test a controller by hand. The cart
+// is treated as fixed for the instant of the kick, so only the tangential
+// part of the impulse changes the angular velocity
+func (p *Pendulum) ApplyImpulse(impulse float64) {
+	p.state.AngularVel += impulse * detmath.Cos(p.state.AngleRadians) / (p.config.PendulumMass * p.config.Length)
+}
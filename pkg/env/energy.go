@@ -0,0 +1,29 @@
+package env
+
+import "math"
+
+// SystemEnergy is the mechanical energy of the cart and pendulum in joules
+type SystemEnergy struct {
+	Kinetic   float64 // Cart and bob, including the bob's share of the cart's motion
+	Potential float64 // Gravitational, zero hanging straight down
+	Total     float64
+}
+
+// Energy returns the mechanical energy of state for a cart with a point-mass
+// bob under config's masses, length and gravity. The bob moves at
+// ẋ + L·θ̇·cos θ horizontally, so the kinetic energy is
+// ½(m+M)ẋ² + M·L·ẋ·θ̇·cos θ + ½·M·L²·θ̇². Angle 0 is upright, where the
+// bob is L above the pivot, so the potential is MgL(1+cos θ). The
+// simulation's equations of motion are simplified and do not conserve this
+// total, so its changes are not a measure of integrator error
+func Energy(state State, config Config) SystemEnergy {
+	m, M, l := config.CartMass, config.PendulumMass, config.Length
+	v, w := state.CartVelocity, state.AngularVel
+	kinetic := 0.5*(m+M)*v*v + M*l*v*w*math.Cos(state.AngleRadians) + 0.5*M*l*l*w*w
+	potential := M * config.Gravity * l * (1 + math.Cos(state.AngleRadians))
+	return SystemEnergy{
+		Kinetic:   kinetic,
+		Potential: potential,
+		Total:     kinetic + potential,
+	}
+}
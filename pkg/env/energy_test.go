@@ -0,0 +1,33 @@
+package env
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnergy(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Length = 2
+
+	// Upright, the bob moves at the cart's 2 m/s plus length × angular velocity
+	energy := Energy(State{CartVelocity: 2, AngularVel: 1}, config)
+	wantKinetic := 0.5*config.CartMass*4 + 0.5*config.PendulumMass*4*4
+	wantPotential := config.PendulumMass * config.Gravity * config.Length * 2 // Mass 2L above hanging
+	if math.Abs(energy.Kinetic-wantKinetic) > 1e-12 || math.Abs(energy.Potential-wantPotential) > 1e-12 {
+		t.Errorf("Energy = %+v, want kinetic %v and potential %v", energy, wantKinetic, wantPotential)
+	}
+	if energy.Total != energy.Kinetic+energy.Potential {
+		t.Errorf("Total %v is not kinetic plus potential", energy.Total)
+	}
+	if rest := Energy(State{AngleRadians: math.Pi}, config); math.Abs(rest.Total) > 1e-12 {
+		t.Errorf("Energy at rest hanging down = %+v, want zero", rest)
+	}
+	// Swinging back at the cart's speed, the upright bob stands still
+	if still := Energy(State{CartVelocity: 2, AngularVel: -1}, config); math.Abs(still.Kinetic-0.5*config.CartMass*4) > 1e-12 {
+		t.Errorf("Kinetic with the bob at rest = %v, want only the cart's %v", still.Kinetic, 0.5*config.CartMass*4)
+	}
+	level := Energy(State{AngleRadians: math.Pi / 2}, config)
+	if want := config.PendulumMass * config.Gravity * config.Length; math.Abs(level.Potential-want) > 1e-12 {
+		t.Errorf("Potential horizontal = %v, want MgL = %v", level.Potential, want)
+	}
+}
@@ -0,0 +1,58 @@
+package env
+
+// FailureMode labels how an episode ended, so an agent's dominant failure
+// can be told apart as angular or positional
+type FailureMode string
+
+// Failure modes. Positive offsets from upright lean the pole toward +x,
+// which on screen is a clockwise fall
+const (
+	FellClockwise        FailureMode = "fell_clockwise"        // The pole fell past the angle limit toward +x
+	FellCounterClockwise FailureMode = "fell_counterclockwise" // The pole fell past the angle limit toward -x
+	LeftEdge             FailureMode = "left_edge"             // The cart ran off the left end of the track
+	RightEdge            FailureMode = "right_edge"            // The cart ran off the right end of the track
+	Timeout              FailureMode = "timeout"               // The episode hit its step limit, or the caller stopped stepping
+	StepFailed           FailureMode = "step_failed"           // Advance returned an error
+)
+
+// FailureModes lists every FailureMode, angular ones first
+func FailureModes() []FailureMode {
+	return []FailureMode{FellClockwise, FellCounterClockwise, LeftEdge, RightEdge, Timeout, StepFailed}
+}
+
+// Angular reports whether the mode is the pole falling
+func (m FailureMode) Angular() bool {
+	return m == FellClockwise || m == FellCounterClockwise
+}
+
+// Positional reports whether the mode is the cart leaving the track
+func (m FailureMode) Positional() bool {
+	return m == LeftEdge || m == RightEdge
+}
+
+// ClassifyFailure labels an episode that ended for reason in state final.
+// An episode the caller stopped without a termination reason timed out
+func ClassifyFailure(reason TerminationReason, final State) FailureMode {
+	switch reason {
+	case OutOfBounds:
+		if final.CartPosition < 0 {
+			return LeftEdge
+		}
+		return RightEdge
+	case AngleLimit:
+		if UprightOffset(final.AngleRadians) < 0 {
+			return FellCounterClockwise
+		}
+		return FellClockwise
+	case InvalidConfig:
+		return StepFailed
+	default:
+		return Timeout
+	}
+}
+
+// Failure classifies how the current episode ended, from the last
+// termination reason and the true state
+func (p *Pendulum) Failure() FailureMode {
+	return ClassifyFailure(p.termination, p.state)
+}
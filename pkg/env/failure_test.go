@@ -0,0 +1,49 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason TerminationReason
+		final  State
+		want   FailureMode
+	}{
+		{"leaned toward +x", AngleLimit, State{AngleRadians: 0.6}, FellClockwise},
+		{"leaned toward -x", AngleLimit, State{AngleRadians: 2*math.Pi - 0.6}, FellCounterClockwise},
+		{"unwrapped", AngleLimit, State{AngleRadians: -0.6}, FellCounterClockwise},
+		{"left edge", OutOfBounds, State{CartPosition: -1.1}, LeftEdge},
+		{"right edge", OutOfBounds, State{CartPosition: 1.1}, RightEdge},
+		{"step limit", StepLimit, State{AngleRadians: 0.6}, Timeout},
+		{"stopped by caller", NotTerminated, State{}, Timeout},
+		{"step failed", InvalidConfig, State{}, StepFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyFailure(tt.reason, tt.final); got != tt.want {
+				t.Errorf("ClassifyFailure(%q, %+v) = %q, want %q", tt.reason, tt.final, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("pendulum", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.TrackLength = 1000.0
+		config.Termination = TerminationConfig{MaxAngle: 0.5}
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Reset(State{AngleRadians: -0.1})
+		for i := 0; i < 500; i++ {
+			if _, done, _ := p.Advance(0); done {
+				break
+			}
+		}
+		if got := p.Failure(); got != FellCounterClockwise || !got.Angular() {
+			t.Errorf("Failure() = %q after falling toward -x, want %q", got, FellCounterClockwise)
+		}
+	})
+}
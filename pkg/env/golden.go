@@ -0,0 +1,135 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Trajectory is a golden recording of the states produced by a scripted
+// force sequence, used to catch unintended changes to the physics
+type Trajectory struct {
+	Config  Config    `json:"config"`
+	Initial State     `json:"initial"`
+	Forces  []float64 `json:"forces"`
+	States  []State   `json:"states"`          // True state after each force
+	Error   string    `json:"error,omitempty"` // Step error that ended the run early, if any
+}
+
+// Tolerance bounds the per-field difference allowed when comparing trajectories
+type Tolerance struct {
+	CartPosition float64
+	CartVelocity float64
+	Angle        float64 // Compared on the shortest arc, so 0 and 2π match
+	AngularVel   float64
+}
+
+// DefaultTolerance allows only floating point noise
+func DefaultTolerance() Tolerance {
+	return Tolerance{
+		CartPosition: 1e-9,
+		CartVelocity: 1e-9,
+		Angle:        1e-9,
+		AngularVel:   1e-9,
+	}
+}
+
+// RecordTrajectory runs the force sequence from the initial state and
+// records the true state after each step. A step error ends the run and
+// is kept in the trajectory, since where the run ends is part of the behavior
+func RecordTrajectory(config Config, initial State, forces []float64) Trajectory {
+	p := NewPendulum(config, log.New(io.Discard, "", 0))
+	p.Reset(initial)
+
+	trajectory := Trajectory{
+		Config:  config,
+		Initial: p.GetState(),
+		Forces:  forces,
+		States:  make([]State, 0, len(forces)),
+	}
+	for _, force := range forces {
+		if _, err := p.Step(force); err != nil {
+			trajectory.Error = err.Error()
+			break
+		}
+		trajectory.States = append(trajectory.States, p.GetState())
+	}
+	return trajectory
+}
+
+// SaveTrajectory writes a trajectory fixture as indented JSON
+func SaveTrajectory(path string, trajectory Trajectory) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create trajectory directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(trajectory, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trajectory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write trajectory file: %w", err)
+	}
+	return nil
+}
+
+// LoadTrajectory reads a trajectory fixture from disk
+func LoadTrajectory(path string) (Trajectory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Trajectory{}, fmt.Errorf("failed to read trajectory file: %w", err)
+	}
+
+	var trajectory Trajectory
+	if err := json.Unmarshal(data, &trajectory); err != nil {
+		return Trajectory{}, fmt.Errorf("failed to unmarshal trajectory: %w", err)
+	}
+	return trajectory, nil
+}
+
+// CompareTrajectory replays the golden trajectory's forces with its config
+// and reports the first step that deviates beyond the tolerance
+func CompareTrajectory(golden Trajectory, tol Tolerance) error {
+	return golden.Compare(RecordTrajectory(golden.Config, golden.Initial, golden.Forces), tol)
+}
+
+// Compare checks another trajectory against this one step by step
+func (t Trajectory) Compare(other Trajectory, tol Tolerance) error {
+	if len(other.States) != len(t.States) {
+		return fmt.Errorf("trajectory has %d states, golden has %d (error %q, golden error %q)",
+			len(other.States), len(t.States), other.Error, t.Error)
+	}
+	if other.Error != t.Error {
+		return fmt.Errorf("trajectory ended with %q, golden ended with %q", other.Error, t.Error)
+	}
+
+	for i := range t.States {
+		want, got := t.States[i], other.States[i]
+		checks := []struct {
+			name      string
+			diff, tol float64
+		}{
+			{"cart position", math.Abs(got.CartPosition - want.CartPosition), tol.CartPosition},
+			{"cart velocity", math.Abs(got.CartVelocity - want.CartVelocity), tol.CartVelocity},
+			{"angle", angleDiff(got.AngleRadians, want.AngleRadians), tol.Angle},
+			{"angular velocity", math.Abs(got.AngularVel - want.AngularVel), tol.AngularVel},
+		}
+		for _, c := range checks {
+			if c.diff > c.tol {
+				return fmt.Errorf("step %d: %s differs by %.3g (tolerance %.3g)\n  got:  %+v\n  want: %+v",
+					i+1, c.name, c.diff, c.tol, got, want)
+			}
+		}
+	}
+	return nil
+}
+
+// angleDiff returns the shortest arc between two angles
+func angleDiff(a, b float64) float64 {
+	d := NormalizeAngle(a - b)
+	return math.Min(d, 2*math.Pi-d)
+}
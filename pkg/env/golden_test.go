@@ -0,0 +1,125 @@
+package env
+
+import (
+	"flag"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden trajectory fixtures in testdata")
+
+// scriptedForces returns a fixed force sequence mixing smooth and bang-bang control
+func scriptedForces(n int) []float64 {
+	forces := make([]float64, n)
+	for i := range forces {
+		forces[i] = 6 * math.Sin(float64(i)*0.15)
+		if i%25 < 3 {
+			forces[i] = -10
+		}
+	}
+	return forces
+}
+
+func goldenCases() map[string]Trajectory {
+	cases := make(map[string]Trajectory)
+	start := State{AngleRadians: 0.1}
+
+	for _, integrator := range []string{SemiImplicitEuler, Euler, RK4} {
+		config := NewDefaultConfig()
+		config.Integrator = integrator
+		config.TrackLength = 20.0
+		cases[integrator] = RecordTrajectory(config, start, scriptedForces(200))
+	}
+
+	// Every optional physics feature at once
+	config := NewDefaultConfig()
+	config.TrackLength = 20.0
+	config.SubSteps = 4
+	config.CartFriction = 0.1
+	config.AngularDamping = 0.05
+	config.ImpulseProb = 0.05
+	config.ImpulseForce = 5.0
+	config.WindForce = 0.5
+	config.WindNoise = 0.2
+	config.Seed = 42
+	cases["full-physics"] = RecordTrajectory(config, start, scriptedForces(200))
+
+	// A constant push that runs off the track
+	push := make([]float64, 200)
+	for i := range push {
+		push[i] = 10
+	}
+	cases["track-bounds"] = RecordTrajectory(NewDefaultConfig(), start, push)
+
+	return cases
+}
+
+func TestGoldenTrajectories(t *testing.T) {
+	for name, recorded := range goldenCases() {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("testdata", "golden_"+name+".json")
+			if *updateGolden {
+				if err := SaveTrajectory(path, recorded); err != nil {
+					t.Fatalf("Failed to save golden trajectory: %v", err)
+				}
+			}
+
+			golden, err := LoadTrajectory(path)
+			if err != nil {
+				t.Fatalf("Failed to load golden trajectory (run with -update to create it): %v", err)
+			}
+			if err := CompareTrajectory(golden, DefaultTolerance()); err != nil {
+				t.Errorf("Physics changed; rerun with -update if intended:\n%v", err)
+			}
+		})
+	}
+}
+
+func TestCompareTrajectory(t *testing.T) {
+	golden := RecordTrajectory(NewDefaultConfig(), State{AngleRadians: 0.1}, scriptedForces(50))
+	if golden.Error != "" {
+		t.Fatalf("Scripted run ended early: %s", golden.Error)
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		if err := CompareTrajectory(golden, DefaultTolerance()); err != nil {
+			t.Errorf("Identical run reported a difference: %v", err)
+		}
+	})
+
+	t.Run("perturbed", func(t *testing.T) {
+		other := golden
+		other.States = append([]State(nil), golden.States...)
+		other.States[10].AngularVel += 1e-3
+
+		err := golden.Compare(other, DefaultTolerance())
+		if err == nil || !strings.Contains(err.Error(), "step 11: angular velocity") {
+			t.Errorf("Expected angular velocity deviation at step 11, got %v", err)
+		}
+
+		loose := DefaultTolerance()
+		loose.AngularVel = 1e-2
+		if err := golden.Compare(other, loose); err != nil {
+			t.Errorf("Deviation within tolerance reported: %v", err)
+		}
+	})
+
+	t.Run("angle wraps", func(t *testing.T) {
+		other := golden
+		other.States = append([]State(nil), golden.States...)
+		other.States[0].AngleRadians += 2 * math.Pi
+		if err := golden.Compare(other, DefaultTolerance()); err != nil {
+			t.Errorf("Angles a full turn apart reported as different: %v", err)
+		}
+	})
+
+	t.Run("different length", func(t *testing.T) {
+		other := golden
+		other.States = golden.States[:len(golden.States)-1]
+		if err := golden.Compare(other, DefaultTolerance()); err == nil {
+			t.Error("Expected error for truncated trajectory")
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
+)
+
+// Integrator names accepted in Config.Integrator
+const (
+	SemiImplicitEuler = "semi-implicit-euler" // Velocities first, then positions (default)
+	Euler             = "euler"               // Explicit Euler, positions from old velocities
+	RK4               = "rk4"                 // Classic fourth-order Runge-Kutta
+)
+
+// Accumulation modes accepted in Config.Accumulation
+const (
+	PlainAccumulation       = "plain"       // Add each increment to the state directly (default)
+	CompensatedAccumulation = "compensated" // Kahan summation, so rounding error does not build up over long runs
+)
+
+// Derivative is the time derivative of the pendulum's continuous state
+type Derivative struct {
+	CartVel, CartAcc       float64
+	AngularVel, AngularAcc float64
+}
+
+// Increment is the change of the continuous state over one integration step
+type Increment struct {
+	CartPosition float64
+	CartVelocity float64
+	AngleRadians float64
+	AngularVel   float64
+}
+
+// Dynamics evaluates the equations of motion for a state under a cart force
+type Dynamics func(s State, force float64) Derivative
+
+// Stepper computes how the state changes over dt under a constant force.
+// The built-in integrators are Steppers; SetStepper injects another, e.g.
+// to test the pendulum's bookkeeping against scripted increments
+type Stepper interface {
+	Increment(dynamics Dynamics, s State, force, dt float64) Increment
+}
+
+// StepperFunc adapts a function to the Stepper interface
+type StepperFunc func(dynamics Dynamics, s State, force, dt float64) Increment
+
+// Increment calls f
+func (f StepperFunc) Increment(dynamics Dynamics, s State, force, dt float64) Increment {
+	return f(dynamics, s, force, dt)
+}
+
+// NewStepper returns the built-in integrator with the given name
+func NewStepper(integrator string) (Stepper, error) {
+	switch integrator {
+	case "", SemiImplicitEuler:
+		return StepperFunc(semiImplicitEuler), nil
+	case Euler:
+		return StepperFunc(euler), nil
+	case RK4:
+		return StepperFunc(rk4), nil
+	default:
+		return nil, fmt.Errorf("unknown integrator %q", integrator)
+	}
+}
+
+// derivatives evaluates the equations of motion for a state under the given
+// cart force. Products are converted to float64 explicitly so the compiler
+// cannot fuse them into multiply-adds, which round differently and would
+// make trajectories depend on the architecture; see package detmath
+func (p *Pendulum) derivatives(s State, force float64) Derivative {
+	sinTheta := detmath.Sin(s.AngleRadians)
+	cosTheta := detmath.Cos(s.AngleRadians)
+
+	// Helpful constants
+	g := p.config.Gravity
+	m := p.config.CartMass
+	M := p.config.PendulumMass
+	l := p.config.Length
+
+	// Viscous friction opposes cart motion and damping opposes rotation
+	friction := float64(p.config.CartFriction * s.CartVelocity)
+	damping := float64(p.config.AngularDamping * s.AngularVel)
+
+	// Calculate accelerations using the full nonlinear equations
+	den := m + float64(M*float64(sinTheta*sinTheta))
+
+	cartAcc := (force - friction + float64(M*g*sinTheta*cosTheta) - float64(M*l*float64(s.AngularVel*s.AngularVel)*sinTheta)) / den
+	angularAcc := (float64(g*sinTheta*cosTheta)-float64(cartAcc*cosTheta))/l - damping
+
+	return Derivative{
+		CartVel:    s.CartVelocity,
+		CartAcc:    cartAcc,
+		AngularVel: s.AngularVel,
+		AngularAcc: angularAcc,
+	}
+}
+
+// semiImplicitEuler updates the velocities first and moves the positions
+// with the new velocities
+func semiImplicitEuler(dynamics Dynamics, s State, force, dt float64) Increment {
+	d := dynamics(s, force)
+	cartVel := float64(d.CartAcc * dt)
+	angularVel := float64(d.AngularAcc * dt)
+	return Increment{
+		CartPosition: float64((s.CartVelocity + cartVel) * dt),
+		CartVelocity: cartVel,
+		AngleRadians: float64((s.AngularVel + angularVel) * dt),
+		AngularVel:   angularVel,
+	}
+}
+
+// euler moves the state along its derivative at the start of the step
+func euler(dynamics Dynamics, s State, force, dt float64) Increment {
+	return scale(dynamics(s, force), dt)
+}
+
+// rk4 moves the state along the weighted average of four derivatives
+// sampled across the step
+func rk4(dynamics Dynamics, s State, force, dt float64) Increment {
+	k1 := dynamics(s, force)
+	k2 := dynamics(advance(s, scale(k1, dt/2)), force)
+	k3 := dynamics(advance(s, scale(k2, dt/2)), force)
+	k4 := dynamics(advance(s, scale(k3, dt)), force)
+	return scale(Derivative{
+		CartVel:    (k1.CartVel + float64(2*k2.CartVel) + float64(2*k3.CartVel) + k4.CartVel) / 6,
+		CartAcc:    (k1.CartAcc + float64(2*k2.CartAcc) + float64(2*k3.CartAcc) + k4.CartAcc) / 6,
+		AngularVel: (k1.AngularVel + float64(2*k2.AngularVel) + float64(2*k3.AngularVel) + k4.AngularVel) / 6,
+		AngularAcc: (k1.AngularAcc + float64(2*k2.AngularAcc) + float64(2*k3.AngularAcc) + k4.AngularAcc) / 6,
+	}, dt)
+}
+
+// scale returns the increment of moving along a derivative for dt
+func scale(d Derivative, dt float64) Increment {
+	return Increment{
+		CartPosition: float64(d.CartVel * dt),
+		CartVelocity: float64(d.CartAcc * dt),
+		AngleRadians: float64(d.AngularVel * dt),
+		AngularVel:   float64(d.AngularAcc * dt),
+	}
+}
+
+// advance adds an increment to a state
+func advance(s State, inc Increment) State {
+	s.CartPosition += inc.CartPosition
+	s.CartVelocity += inc.CartVelocity
+	s.AngleRadians += inc.AngleRadians
+	s.AngularVel += inc.AngularVel
+	return s
+}
+
+// SetStepper replaces the integrator selected by Config.Integrator. Pass
+// nil to return to the configured one
+func (p *Pendulum) SetStepper(stepper Stepper) {
+	p.stepper = stepper
+}
+
+// integrate advances the continuous state by dt with the pendulum's stepper
+// and accumulation mode. The angle is not normalized and TimeStep is left
+// unchanged
+func (p *Pendulum) integrate(s State, force, dt float64) (State, error) {
+	stepper := p.stepper
+	if stepper == nil {
+		var err error
+		if stepper, err = NewStepper(p.config.Integrator); err != nil {
+			return s, err
+		}
+	}
+	inc := stepper.Increment(p.derivatives, s, force, dt)
+
+	switch p.config.Accumulation {
+	case "", PlainAccumulation:
+		return advance(s, inc), nil
+	case CompensatedAccumulation:
+		s.CartPosition = kahanAdd(s.CartPosition, inc.CartPosition, &p.carry.CartPosition)
+		s.CartVelocity = kahanAdd(s.CartVelocity, inc.CartVelocity, &p.carry.CartVelocity)
+		s.AngleRadians = kahanAdd(s.AngleRadians, inc.AngleRadians, &p.carry.AngleRadians)
+		s.AngularVel = kahanAdd(s.AngularVel, inc.AngularVel, &p.carry.AngularVel)
+		return s, nil
+	default:
+		return s, fmt.Errorf("unknown accumulation %q", p.config.Accumulation)
+	}
+}
+
+// kahanAdd returns sum+x, first adding back the rounding error of earlier
+// additions kept in carry and then storing this addition's error there
+func kahanAdd(sum, x float64, carry *float64) float64 {
+	y := x - *carry
+	t := sum + y
+	*carry = (t - sum) - y
+	return t
+}
@@ -0,0 +1,228 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+// simulate runs a free swing and returns the final state
+func simulate(t *testing.T, integrator string, dt float64, duration float64) State {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.Integrator = integrator
+	config.DeltaTime = dt
+	config.TrackLength = 1000.0 // Keep the cart away from the bounds
+	p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+	p.Reset(State{AngleRadians: math.Pi - 1.0})
+
+	steps := int(math.Round(duration / dt))
+	var state State
+	for i := 0; i < steps; i++ {
+		var err error
+		state, err = p.Step(0)
+		if err != nil {
+			t.Fatalf("%s step %d failed: %v", integrator, i, err)
+		}
+	}
+	return state
+}
+
+func TestIntegratorEnergyDrift(t *testing.T) {
+	const duration = 2.0
+	const dt = 0.05 // Large timestep where Euler drifts
+
+	// A fine RK4 run stands in for the exact solution
+	reference := Energy(simulate(t, RK4, 0.0005, duration), NewDefaultConfig()).Total
+
+	drift := map[string]float64{}
+	for _, integrator := range []string{Euler, SemiImplicitEuler, RK4} {
+		energy := Energy(simulate(t, integrator, dt, duration), NewDefaultConfig()).Total
+		drift[integrator] = math.Abs(energy - reference)
+		t.Logf("%s energy drift at dt=%.3f: %.6f", integrator, dt, drift[integrator])
+	}
+
+	if drift[RK4] >= drift[SemiImplicitEuler] || drift[RK4] >= drift[Euler] {
+		t.Errorf("Expected RK4 to drift least, got %v", drift)
+	}
+	if drift[RK4] > 1e-3 {
+		t.Errorf("RK4 energy drift %.6f exceeds 1e-3", drift[RK4])
+	}
+}
+
+func TestUnknownIntegrator(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Integrator = "verlet"
+	p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+
+	if _, err := p.Step(0); err == nil {
+		t.Error("Expected error for unknown integrator")
+	}
+}
+
+func TestSubSteps(t *testing.T) {
+	logger := log.New(&bytes.Buffer{}, "", 0)
+	run := func(subSteps int, steps int) State {
+		config := NewDefaultConfig()
+		config.DeltaTime = 0.05 // 20Hz control
+		config.SubSteps = subSteps
+		config.TrackLength = 1000.0
+		p := NewPendulum(config, logger)
+		p.Reset(State{AngleRadians: math.Pi - 1.0})
+		var state State
+		for i := 0; i < steps; i++ {
+			var err error
+			state, err = p.Step(1.0)
+			if err != nil {
+				t.Fatalf("Step %d failed: %v", i, err)
+			}
+		}
+		return state
+	}
+
+	// One sub-step is the same as none
+	if run(0, 20) != run(1, 20) {
+		t.Error("SubSteps=1 should match SubSteps=0")
+	}
+
+	// 1kHz physics under 20Hz control should match a 1kHz control loop
+	// holding the same force, and be far closer than a single 20Hz step
+	fine := simulateForce(t, 0.001, 1000, 1.0)
+	coarse := run(1, 20)
+	subStepped := run(50, 20)
+	if subStepped.TimeStep != 20 {
+		t.Errorf("TimeStep = %d, want one per control step", subStepped.TimeStep)
+	}
+	fineErr := math.Abs(subStepped.AngleRadians - fine.AngleRadians)
+	coarseErr := math.Abs(coarse.AngleRadians - fine.AngleRadians)
+	if fineErr > 1e-9 {
+		t.Errorf("sub-stepped angle differs from 1kHz run by %.3g", fineErr)
+	}
+	if coarseErr <= fineErr {
+		t.Errorf("expected sub-stepping to reduce error: coarse=%.3g, sub-stepped=%.3g", coarseErr, fineErr)
+	}
+}
+
+// simulateForce runs the default integrator at dt with a constant force
+func simulateForce(t *testing.T, dt float64, steps int, force float64) State {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.DeltaTime = dt
+	config.TrackLength = 1000.0
+	p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+	p.Reset(State{AngleRadians: math.Pi - 1.0})
+	var state State
+	for i := 0; i < steps; i++ {
+		var err error
+		state, err = p.Step(force)
+		if err != nil {
+			t.Fatalf("step %d failed: %v", i, err)
+		}
+	}
+	return state
+}
+
+func TestInjectedStepper(t *testing.T) {
+	config := NewDefaultConfig()
+	p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+	p.Reset(State{AngleRadians: 1.0})
+
+	// A scripted stepper moves the state by fixed amounts, ignoring the dynamics
+	var forces []float64
+	p.SetStepper(StepperFunc(func(dynamics Dynamics, s State, force, dt float64) Increment {
+		forces = append(forces, force)
+		return Increment{CartPosition: 0.5, CartVelocity: 1, AngleRadians: 0.25, AngularVel: -1}
+	}))
+	state, err := p.Step(2.0)
+	if err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+	want := State{CartPosition: 0.5, CartVelocity: 1, AngleRadians: 1.25, AngularVel: -1, TimeStep: 1}
+	if state != want {
+		t.Errorf("state = %+v, want %+v", state, want)
+	}
+	if len(forces) != 1 || forces[0] != 2.0 {
+		t.Errorf("stepper saw forces %v, want [2]", forces)
+	}
+
+	// Clearing the stepper returns to the configured integrator
+	p.SetStepper(nil)
+	stepper, err := NewStepper(config.Integrator)
+	if err != nil {
+		t.Fatalf("NewStepper failed: %v", err)
+	}
+	expected := advance(state, stepper.Increment(p.derivatives, state, 0, config.DeltaTime))
+	next, err := p.Step(0)
+	if err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+	if next.CartVelocity != expected.CartVelocity || next.AngularVel != expected.AngularVel {
+		t.Errorf("state after SetStepper(nil) = %+v, want %+v", next, expected)
+	}
+
+	if _, err := NewStepper("verlet"); err == nil {
+		t.Error("Expected error for unknown integrator")
+	}
+}
+
+func TestCompensatedAccumulation(t *testing.T) {
+	const steps = 100000
+	const increment = 1e-7
+
+	// Tiny increments onto a large position round away in plain summation
+	run := func(accumulation string) float64 {
+		config := NewDefaultConfig()
+		config.Accumulation = accumulation
+		config.TrackLength = 1000.0
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Reset(State{CartPosition: 100})
+		p.SetStepper(StepperFunc(func(Dynamics, State, float64, float64) Increment {
+			return Increment{CartPosition: increment}
+		}))
+		for i := 0; i < steps; i++ {
+			if _, err := p.Step(0); err != nil {
+				t.Fatalf("%s step %d failed: %v", accumulation, i, err)
+			}
+		}
+		return p.GetState().CartPosition
+	}
+
+	want := 100 + steps*increment
+	plainErr := math.Abs(run(PlainAccumulation) - want)
+	compensatedErr := math.Abs(run(CompensatedAccumulation) - want)
+	t.Logf("error after %d steps: plain %.3g, compensated %.3g", steps, plainErr, compensatedErr)
+	if compensatedErr > 1e-12 {
+		t.Errorf("compensated position off by %.3g", compensatedErr)
+	}
+	if plainErr <= compensatedErr {
+		t.Errorf("expected plain summation to drift more: plain=%.3g, compensated=%.3g", plainErr, compensatedErr)
+	}
+
+	// Compensation leaves ordinary trajectories essentially unchanged
+	for _, accumulation := range []string{"", CompensatedAccumulation} {
+		config := NewDefaultConfig()
+		config.Accumulation = accumulation
+		config.TrackLength = 1000.0
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Reset(State{AngleRadians: math.Pi - 1.0})
+		for i := 0; i < 100; i++ {
+			if _, err := p.Step(1.0); err != nil {
+				t.Fatalf("%q step %d failed: %v", accumulation, i, err)
+			}
+		}
+		if accumulation == "" {
+			want = p.GetState().AngleRadians
+		} else if diff := math.Abs(p.GetState().AngleRadians - want); diff > 1e-9 {
+			t.Errorf("compensated angle differs from plain by %.3g", diff)
+		}
+	}
+
+	config := NewDefaultConfig()
+	config.Accumulation = "fixed"
+	if _, err := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0)).Step(0); err == nil {
+		t.Error("Expected error for unknown accumulation")
+	}
+}
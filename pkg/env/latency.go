@@ -0,0 +1,39 @@
+package env
+
+// actuator delays and holds the controller's forces before they reach the
+// cart, as configured by Config.ActionDelay and Config.ControlHold
+type actuator struct {
+	pending  []float64 // Forces chosen but not yet applied, oldest first
+	held     float64   // Force sampled at the start of the current hold
+	holdLeft int       // Steps the held force still applies
+}
+
+// hasLatency reports whether forces are delayed or held
+func (c Config) hasLatency() bool {
+	return c.ActionDelay > 0 || c.ControlHold > 1
+}
+
+// next returns the force applied this step for the force the controller
+// chose, and the actuator state after the step. The receiver is left
+// unchanged so a discarded step leaves no trace. Until ActionDelay forces
+// have been chosen, the cart gets no force
+func (a actuator) next(config Config, force float64) (float64, actuator) {
+	if config.ControlHold > 1 {
+		if a.holdLeft == 0 {
+			a.held = force
+			a.holdLeft = config.ControlHold
+		}
+		a.holdLeft--
+		force = a.held
+	}
+
+	if delay := config.ActionDelay; delay > 0 {
+		pending := make([]float64, 0, delay+1)
+		if len(a.pending) == 0 {
+			pending = append(pending, make([]float64, delay)...)
+		}
+		pending = append(append(pending, a.pending...), force)
+		force, a.pending = pending[0], pending[1:]
+	}
+	return force, a
+}
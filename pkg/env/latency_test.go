@@ -0,0 +1,61 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+)
+
+func TestLatency(t *testing.T) {
+	applied := func(delay, hold int, forces []float64) []float64 {
+		config := NewDefaultConfig()
+		config.TrackLength = 1000.0
+		config.ActionDelay = delay
+		config.ControlHold = hold
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		var got []float64
+		for _, f := range forces {
+			if _, _, err := p.Advance(f); err != nil {
+				t.Fatalf("Advance failed: %v", err)
+			}
+			got = append(got, p.GetLastForce())
+		}
+		return got
+	}
+	forces := []float64{1, 2, 3, 4, 5, 6}
+
+	tests := []struct {
+		name        string
+		delay, hold int
+		want        []float64
+	}{
+		{"immediate", 0, 0, []float64{1, 2, 3, 4, 5, 6}},
+		{"delay", 2, 0, []float64{0, 0, 1, 2, 3, 4}},
+		{"hold", 0, 3, []float64{1, 1, 1, 4, 4, 4}},
+		{"delayed hold", 1, 2, []float64{0, 1, 1, 3, 3, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applied(tt.delay, tt.hold, forces); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("applied forces = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.ActionDelay = 1
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Advance(7)
+		p.Reset(State{})
+		p.Advance(3)
+		if got := p.GetLastForce(); got != 0 {
+			t.Errorf("first force after reset = %v, want 0 with the buffer cleared", got)
+		}
+		p.Advance(0)
+		if got := p.GetLastForce(); got != 3 {
+			t.Errorf("applied force = %v, want 3", got)
+		}
+	})
+}
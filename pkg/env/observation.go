@@ -0,0 +1,42 @@
+package env
+
+import (
+	"fmt"
+	"math"
+)
+
+// Observation models accepted in Config.Observation
+const (
+	FullObservation      = "full"       // The controller sees the whole state (default)
+	AngleOnlyObservation = "angle-only" // Velocities read zero, so the controller must infer them from positions over time
+)
+
+// validateObservation checks the configured observation model
+func (c Config) validateObservation() error {
+	switch c.Observation {
+	case "", FullObservation, AngleOnlyObservation:
+	default:
+		return fmt.Errorf("unknown observation model %q", c.Observation)
+	}
+	if c.EncoderResolution < 0 {
+		return fmt.Errorf("encoder resolution must not be negative, got %g", c.EncoderResolution)
+	}
+	return nil
+}
+
+// observe returns what the controller sees of a true state through noisy
+// sensors, a quantizing angle encoder and the configured observation model
+func (c Config) observe(s State, d Disturbance) State {
+	angle := s.AngleRadians + d.SensorAngle
+	if c.EncoderResolution > 0 {
+		angle = math.Round(angle/c.EncoderResolution) * c.EncoderResolution
+	}
+	s.AngleRadians = NormalizeAngle(angle)
+	s.AngularVel += d.SensorAngularVel
+
+	if c.Observation == AngleOnlyObservation {
+		s.AngularVel = 0
+		s.CartVelocity = 0
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+func TestObservation(t *testing.T) {
+	logger := log.New(&bytes.Buffer{}, "", 0)
+	step := func(config Config) (State, State) {
+		p := NewPendulum(config, logger)
+		observed, _, err := p.Advance(1.0)
+		if err != nil {
+			t.Fatalf("Advance failed: %v", err)
+		}
+		return observed, p.GetState()
+	}
+
+	t.Run("full", func(t *testing.T) {
+		observed, truth := step(NewDefaultConfig())
+		if observed != truth {
+			t.Errorf("Expected the full observation to equal the state: %+v vs %+v", observed, truth)
+		}
+	})
+
+	t.Run("angle-only", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.Observation = AngleOnlyObservation
+		observed, truth := step(config)
+		if observed.AngularVel != 0 || observed.CartVelocity != 0 {
+			t.Errorf("Expected velocities to be hidden, got %+v", observed)
+		}
+		if truth.AngularVel == 0 || truth.CartVelocity == 0 {
+			t.Errorf("Expected the true state to keep its velocities, got %+v", truth)
+		}
+		if observed.AngleRadians != truth.AngleRadians || observed.CartPosition != truth.CartPosition {
+			t.Errorf("Expected positions to be observed: %+v vs %+v", observed, truth)
+		}
+	})
+
+	t.Run("encoder", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.EncoderResolution = 2 * math.Pi / 1024
+		observed, truth := step(config)
+		steps := observed.AngleRadians / config.EncoderResolution
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			t.Errorf("Expected the angle on an encoder step, got %.6f", observed.AngleRadians)
+		}
+		if diff := math.Abs(observed.AngleRadians - truth.AngleRadians); diff > config.EncoderResolution/2+1e-12 {
+			t.Errorf("Expected the angle within half a step of the truth, off by %.6f", diff)
+		}
+	})
+
+	t.Run("unknown model", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.Observation = "telepathy"
+		p := NewPendulum(config, logger)
+		if _, done, err := p.Advance(1.0); err == nil || !done {
+			t.Errorf("Expected an unknown observation model to fail, got done=%v err=%v", done, err)
+		}
+	})
+}
@@ -4,14 +4,38 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
 )
 
 // Pendulum represents the inverted pendulum system
 type Pendulum struct {
-	config Config
-	state  State
-	logger *log.Logger
-	lastForce float64 // Track last applied force
+	config          Config
+	state           State
+	logger          *log.Logger
+	lastForce       float64     // Track last applied force
+	rng             *rand.Rand  // Source of disturbances, seeded from config.Seed
+	source          *randSource // Generator behind rng, copied by snapshots; nil after SetRNG
+	lastDisturbance Disturbance // Disturbances applied during the last step
+	drawn           bool        // Whether pending holds the draw of a step Step discarded
+	pending         Disturbance // Disturbances drawn for the next step
+	sampler         *logger.Sampler // Decides which steps are logged
+	stepper         Stepper         // Replaces the configured integrator when set
+	carry           Increment       // Rounding error held back by compensated accumulation
+	verbose         bool            // Whether the step in progress is logged
+	termination     TerminationReason // Why the last Advance ended the episode
+	actuator        actuator          // Delayed and held forces, with Config.ActionDelay or ControlHold
+	nextActuator    actuator          // Actuator state after the step in progress
+	push            float64           // External force for the next step, see Push
+	latency         LatencyRecorder   // Receives how long each Step and Advance took, when set
+}
+
+// LatencyRecorder receives how long each call of an instrumented method
+// took, e.g. a metrics.LatencyHistogram
+type LatencyRecorder interface {
+	Record(time.Duration)
 }
 
 // NewPendulum creates a new pendulum system with given config and logger
@@ -25,17 +49,32 @@ func NewPendulum(config Config, logger *log.Logger) *Pendulum {
 		state: State{
 			CartPosition: 0,
 			CartVelocity: 0,
-			AngleRadians: math.Pi, // starting hanging down
+			AngleRadians: HangingAngle, // starting hanging down
 			AngularVel:   0,
 			TimeStep:     0,
 		},
-		logger: logger,
+		logger:  logger,
+		source:  newRandSource(config.Seed),
+		sampler: defaultSampler(),
 	}
+	p.rng = rand.New(p.source)
 	
-	p.logger.Printf("Initialized pendulum with config: %+v\n", config)
+	if p.sampler.Episode() {
+		p.logger.Printf("Initialized pendulum with config: %+v\n", config)
+	}
 	return p
 }
 
+// defaultSampler follows the process-wide log sampling
+func defaultSampler() *logger.Sampler {
+	return logger.NewSampler(logger.DefaultSampling())
+}
+
+// SetLogSampling controls how many steps are logged
+func (p *Pendulum) SetLogSampling(config logger.SamplingConfig) {
+	p.sampler = logger.NewSampler(config)
+}
+
 // GetState returns the current state (immutable)
 func (p *Pendulum) GetState() State {
 	return p.state
@@ -46,65 +85,174 @@ func (p *Pendulum) GetConfig() Config {
 	return p.config
 }
 
+// Reset restarts the simulation from the given state, e.g. the initial
+// conditions chosen by a curriculum
+func (p *Pendulum) Reset(state State) {
+	state.AngleRadians = NormalizeAngle(state.AngleRadians)
+	state.TimeStep = 0
+	p.state = state
+	p.lastForce = 0
+	p.carry = Increment{}
+	p.termination = NotTerminated
+	p.actuator = actuator{}
+}
+
 // GetLastForce returns the last force applied to the pendulum
 func (p *Pendulum) GetLastForce() float64 {
 	return p.lastForce
 }
 
+// GetLastDisturbance returns the disturbances applied during the last step
+func (p *Pendulum) GetLastDisturbance() Disturbance {
+	return p.lastDisturbance
+}
+
+// SetRNG replaces the disturbance RNG, e.g. to share one seeded source
+// across episodes instead of repeating the same disturbances
+func (p *Pendulum) SetRNG(rng *rand.Rand) {
+	p.rng = rng
+	p.source = nil
+}
+
+// SetLatencyRecorder times every Step and Advance into recorder, to check
+// the simulation fits a real-time control budget. Nil stops timing
+func (p *Pendulum) SetLatencyRecorder(recorder LatencyRecorder) {
+	p.latency = recorder
+}
+
 // Step advances the simulation by one timestep with the given force
-// Returns new state and error if any constraints are violated
+// Returns new state and error if any constraints are violated.
+// With sensor noise or a partial observation model configured, the
+// returned state is the observation while GetState keeps returning the
+// true state.
+// Leaving the track under the terminate bounds policy is an error and the
+// step is discarded: the next step applies the same disturbances and any
+// pending push, as if it had never been tried. Advance reports it as the
+// end of the episode instead
 func (p *Pendulum) Step(force float64) (State, error) {
+	if p.latency != nil {
+		defer p.timeStep(time.Now())
+	}
+	carry, lastForce, lastDisturbance := p.carry, p.lastForce, p.lastDisturbance
+	next, out, err := p.simulate(force)
+	if err == nil && out {
+		err = fmt.Errorf("cart position %.2f exceeds track bounds ±%.2f", 
+			next.CartPosition, p.config.TrackLength/2)
+	}
+	if err != nil {
+		p.carry, p.lastForce, p.lastDisturbance = carry, lastForce, lastDisturbance
+		return p.state, err
+	}
+	return p.commit(next), nil
+}
+
+// Advance advances the simulation by one timestep like Step, and reports
+// whether the episode is done: the cart left the track under the terminate
+// bounds policy or a Config.Termination condition was met. GetTermination
+// says which. The state where the cart left is kept rather than discarded.
+// Errors are only returned for an invalid configuration
+func (p *Pendulum) Advance(force float64) (State, bool, error) {
+	if p.latency != nil {
+		defer p.timeStep(time.Now())
+	}
+	next, out, err := p.simulate(force)
+	if err != nil {
+		p.termination = InvalidConfig
+		return p.state, true, err
+	}
+	observed := p.commit(next)
+	if out {
+		p.termination = OutOfBounds
+	} else {
+		p.termination = p.config.Termination.terminationReason(p.state)
+	}
+	return observed, p.termination != NotTerminated, nil
+}
+
+// timeStep records the latency of a step that started at start
+func (p *Pendulum) timeStep(start time.Time) {
+	p.latency.Record(time.Since(start))
+}
+
+// simulate integrates one control period from the current state without
+// committing it. It stops early and reports out when the cart leaves the
+// track under the terminate bounds policy
+func (p *Pendulum) simulate(force float64) (State, bool, error) {
+	if err := p.config.validateObservation(); err != nil {
+		return p.state, false, err
+	}
+	
+	// With actuation latency the cart gets an earlier or held force
+	if p.config.hasLatency() {
+		force, p.nextActuator = p.actuator.next(p.config, force)
+	}
 	p.lastForce = force // Store force for visualization
 	
 	// Clamp force to allowed range
 	force = math.Max(-p.config.MaxForce, math.Min(force, p.config.MaxForce))
 	
-	p.logger.Printf("Step %d: Applying force: %.2f\n", p.state.TimeStep, force)
+	p.verbose = p.sampler.Step()
+	if p.verbose {
+		p.logger.Printf("Step %d: Applying force: %.2f\n", p.state.TimeStep, force)
+	}
 
-	// Calculate derivatives using equations of motion
-	sinTheta := math.Sin(p.state.AngleRadians)
-	cosTheta := math.Cos(p.state.AngleRadians)
-	
-	// Helpful constants
-	g := p.config.Gravity
-	m := p.config.CartMass
-	M := p.config.PendulumMass
-	l := p.config.Length
-	dt := p.config.DeltaTime
-	
-	// Calculate accelerations using the full nonlinear equations
-	den := m + M*math.Pow(sinTheta, 2)
-	
-	cartAcc := (force + M*g*sinTheta*cosTheta - M*l*math.Pow(p.state.AngularVel, 2)*sinTheta) / den
-	angularAcc := (g*sinTheta*cosTheta - cartAcc*cosTheta) / l
+	// External disturbances act on the cart on top of the clamped control
+	// force. A step Step discarded has already drawn this one's
+	if !p.drawn {
+		p.pending, p.drawn = sampleDisturbance(p.config, p.rng), true
+	}
+	disturbance := p.pending
+	disturbance.Pushed = p.push
+	p.lastDisturbance = disturbance
+	force += disturbance.Force()
 
-	// Update velocities
-	newCartVel := p.state.CartVelocity + cartAcc*dt
-	newAngularVel := p.state.AngularVel + angularAcc*dt
-	
-	// Update positions
-	newCartPos := p.state.CartPosition + newCartVel*dt
-	newAngle := NormalizeAngle(p.state.AngleRadians + newAngularVel*dt)
-	
-	// Check track bounds
-	if math.Abs(newCartPos) > p.config.TrackLength/2 {
-		return p.state, fmt.Errorf("cart position %.2f exceeds track bounds ±%.2f", 
-			newCartPos, p.config.TrackLength/2)
+	// Integrate the equations of motion over one control period, holding
+	// the force constant across physics sub-steps
+	next := p.state
+	carry := p.carry
+	subSteps := max(1, p.config.SubSteps)
+	for i := 0; i < subSteps; i++ {
+		var err error
+		next, err = p.integrate(next, force, p.config.PhysicsDeltaTime())
+		if err != nil {
+			p.carry = carry
+			return p.state, false, err
+		}
+		
+		// Check track bounds
+		var out bool
+		if next, out, err = p.applyBounds(next); err != nil {
+			p.carry = carry
+			return p.state, false, err
+		}
+		if out {
+			return next, true, nil
+		}
 	}
-	
+	return next, false, nil
+}
+
+// commit makes a simulated state current and returns what the controller
+// observes of it
+func (p *Pendulum) commit(next State) State {
+	p.actuator = p.nextActuator
+	p.drawn, p.push = false, 0
 	// Create new immutable state
 	newState := State{
-		CartPosition: newCartPos,
-		CartVelocity: newCartVel,
-		AngleRadians: newAngle,
-		AngularVel:   newAngularVel,
+		CartPosition: next.CartPosition,
+		CartVelocity: next.CartVelocity,
+		AngleRadians: NormalizeAngle(next.AngleRadians),
+		AngularVel:   next.AngularVel,
 		TimeStep:     p.state.TimeStep + 1,
 	}
 	
-	p.logger.Printf("New state: %+v\n", newState)
+	if p.verbose {
+		p.logger.Printf("New state: %+v\n", newState)
+	}
 	
 	// Update internal state
 	p.state = newState
 	
-	return newState, nil
+	// Controllers only see the state through their sensors
+	return p.config.observe(newState, p.lastDisturbance)
 }
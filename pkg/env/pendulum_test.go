@@ -6,6 +6,9 @@ import (
 	"math"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
 )
 
 func TestNewPendulum(t *testing.T) {
@@ -83,6 +86,33 @@ func TestPendulumStep(t *testing.T) {
 	}
 }
 
+func TestPendulumLogSampling(t *testing.T) {
+	var logBuf bytes.Buffer
+	p := NewPendulum(NewDefaultConfig(), log.New(&logBuf, "", 0))
+	p.SetLogSampling(logger.SamplingConfig{Mode: logger.SampleEveryN, Every: 10})
+
+	for i := 0; i < 25; i++ {
+		if _, err := p.Step(0); err != nil {
+			t.Fatalf("Step() error = %v", err)
+		}
+	}
+
+	// Steps 0, 10 and 20 are logged, each with its force and state lines
+	if got := strings.Count(logBuf.String(), "Applying force"); got != 3 {
+		t.Errorf("logged %d steps, want 3", got)
+	}
+	if got := strings.Count(logBuf.String(), "New state"); got != 3 {
+		t.Errorf("logged %d states, want 3", got)
+	}
+
+	logBuf.Reset()
+	p.SetLogSampling(logger.SamplingConfig{Mode: logger.SampleErrors})
+	p.Step(0)
+	if logBuf.Len() != 0 {
+		t.Errorf("errors-only sampling logged %q", logBuf.String())
+	}
+}
+
 func TestNormalizeAngle(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -285,8 +315,8 @@ func TestStateTransitions(t *testing.T) {
 			workDone += force * dx
 
 			// Verify energy conservation including work done
-			prevEnergy := calculateSystemEnergy(prevState)
-			currentEnergy := calculateSystemEnergy(state)
+			prevEnergy := Energy(prevState, config).Total
+			currentEnergy := Energy(state, config).Total
 			energyDiff := math.Abs((currentEnergy - prevEnergy) - (force * dx))
 			if energyDiff > 1e-2 {
 				t.Errorf("Step %d: Energy not conserved (accounting for work), diff: %v", i, energyDiff)
@@ -297,13 +327,125 @@ func TestStateTransitions(t *testing.T) {
 	}
 }
 
-func calculateSystemEnergy(s State) float64 {
-	config := NewDefaultConfig()
-	// Kinetic + Potential energy
-	cartKE := 0.5 * config.CartMass * s.CartVelocity * s.CartVelocity
-	pendulumKE := 0.5 * config.PendulumMass * s.AngularVel * s.AngularVel
-	potentialE := config.PendulumMass * config.Gravity * (1 - math.Cos(s.AngleRadians))
-	return cartKE + pendulumKE + potentialE
+func TestFrictionAndDamping(t *testing.T) {
+	logger := log.New(&bytes.Buffer{}, "", 0)
+
+	t.Run("Cart friction slows the cart", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.CartFriction = 2.0
+		p := NewPendulum(config, logger)
+		p.Reset(State{AngleRadians: math.Pi, CartVelocity: 1.0})
+
+		for i := 0; i < 10; i++ {
+			state, err := p.Step(0)
+			if err != nil {
+				t.Fatalf("Step %d failed: %v", i, err)
+			}
+			if state.CartVelocity >= 1.0 {
+				t.Fatalf("Step %d: cart velocity %.4f not reduced by friction", i, state.CartVelocity)
+			}
+		}
+	})
+
+	t.Run("Angular damping dissipates energy", func(t *testing.T) {
+		// Average angular speed over the second half of a free swing
+		run := func(damping float64) float64 {
+			config := NewDefaultConfig()
+			config.AngularDamping = damping
+			config.TrackLength = 100.0 // Keep the cart away from the bounds
+			p := NewPendulum(config, logger)
+			p.Reset(State{AngleRadians: math.Pi - 0.5})
+			total := 0.0
+			for i := 0; i < 200; i++ {
+				state, err := p.Step(0)
+				if err != nil {
+					t.Fatalf("Step %d failed: %v", i, err)
+				}
+				if i >= 100 {
+					total += math.Abs(state.AngularVel)
+				}
+			}
+			return total / 100
+		}
+
+		lossless, damped := run(0), run(1.0)
+		if damped >= lossless {
+			t.Errorf("Expected damped swing (%.4f) to decay below lossless swing (%.4f)", damped, lossless)
+		}
+	})
+}
+
+func TestDisturbances(t *testing.T) {
+	logger := log.New(&bytes.Buffer{}, "", 0)
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		p := NewPendulum(NewDefaultConfig(), logger)
+		for i := 0; i < 10; i++ {
+			if _, err := p.Step(1.0); err != nil {
+				t.Fatalf("Step failed: %v", err)
+			}
+			if d := p.GetLastDisturbance(); d != (Disturbance{}) {
+				t.Fatalf("Unexpected disturbance with default config: %+v", d)
+			}
+		}
+	})
+
+	t.Run("Seeded runs are reproducible", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.ImpulseProb = 0.3
+		config.ImpulseForce = 5.0
+		config.WindNoise = 1.0
+		config.SensorNoise = 0.01
+		config.Seed = 42
+
+		p1 := NewPendulum(config, logger)
+		p2 := NewPendulum(config, logger)
+		impulses := 0
+		for i := 0; i < 50; i++ {
+			s1, err1 := p1.Step(0)
+			s2, err2 := p2.Step(0)
+			if err1 != nil || err2 != nil {
+				t.Fatalf("Step failed: %v, %v", err1, err2)
+			}
+			if s1 != s2 || p1.GetLastDisturbance() != p2.GetLastDisturbance() {
+				t.Fatalf("Runs diverged at step %d", i)
+			}
+			if p1.GetLastDisturbance().Impulse != 0 {
+				impulses++
+			}
+		}
+		if impulses == 0 {
+			t.Error("Expected at least one impulse in 50 steps")
+		}
+	})
+
+	t.Run("Wind pushes the cart", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.WindForce = 2.0
+		p := NewPendulum(config, logger)
+		if _, err := p.Step(0); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+		if p.GetState().CartVelocity <= 0 {
+			t.Errorf("Expected positive cart velocity from wind, got %.4f", p.GetState().CartVelocity)
+		}
+	})
+
+	t.Run("Sensor noise only affects observations", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.SensorNoise = 0.1
+		noisy := NewPendulum(config, logger)
+		clean := NewPendulum(NewDefaultConfig(), logger)
+
+		observed, _ := noisy.Step(1.0)
+		truth, _ := clean.Step(1.0)
+		if noisy.GetState() != truth {
+			t.Errorf("Sensor noise changed the true state: %+v vs %+v", noisy.GetState(), truth)
+		}
+		if observed == truth {
+			t.Error("Expected observed state to include sensor noise")
+		}
+	})
 }
 
 func BenchmarkPendulumStep(b *testing.B) {
@@ -327,3 +469,21 @@ func BenchmarkPendulumStep(b *testing.B) {
 		}
 	})
 }
+
+// countingRecorder counts the latencies it is given
+type countingRecorder struct{ calls int }
+
+func (r *countingRecorder) Record(time.Duration) { r.calls++ }
+
+func TestLatencyRecorder(t *testing.T) {
+	p := NewPendulum(NewDefaultConfig(), log.New(&bytes.Buffer{}, "", 0))
+	recorder := &countingRecorder{}
+	p.SetLatencyRecorder(recorder)
+	p.Step(0)
+	p.Advance(0)
+	p.SetLatencyRecorder(nil)
+	p.Advance(0)
+	if recorder.calls != 2 {
+		t.Errorf("recorded %d calls, want Step and Advance until the recorder was removed", recorder.calls)
+	}
+}
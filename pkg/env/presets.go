@@ -0,0 +1,66 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Preset names accepted by Preset
+const (
+	ClassicPreset    = "classic"     // NewDefaultConfig: light pole on a light cart at 50Hz
+	HeavyCartPreset  = "heavy-cart"  // Classic with a 5kg cart that responds slowly to force
+	LongPolePreset   = "long-pole"   // Classic with a 2m pole that falls slower but swings wider
+	ShortTrackPreset = "short-track" // Classic with a 2m track that leaves little room to recover
+	PezzzaPreset     = "pezzza"      // The window app's heavy pendulum at 60Hz, as in Pezzza's demo
+)
+
+// presets builds the Config for each named preset
+var presets = map[string]func() Config{
+	ClassicPreset: NewDefaultConfig,
+	HeavyCartPreset: func() Config {
+		config := NewDefaultConfig()
+		config.CartMass = 5.0
+		return config
+	},
+	LongPolePreset: func() Config {
+		config := NewDefaultConfig()
+		config.Length = 2.0
+		return config
+	},
+	ShortTrackPreset: func() Config {
+		config := NewDefaultConfig()
+		config.TrackLength = 2.0
+		return config
+	},
+	PezzzaPreset: func() Config {
+		return Config{
+			CartMass:     5.0,
+			PendulumMass: 1.0,
+			Length:       1.0,
+			Gravity:      9.81,
+			MaxForce:     10.0,
+			DeltaTime:    0.016, // 60 fps
+			TrackLength:  4.0,
+		}
+	},
+}
+
+// Preset returns the Config of a named preset
+func Preset(name string) (Config, error) {
+	build, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown pendulum preset %q (want %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	return build(), nil
+}
+
+// PresetNames returns the names of all presets in sorted order
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
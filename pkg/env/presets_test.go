@@ -0,0 +1,50 @@
+package env
+
+import (
+	"io"
+	"log"
+	"math"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	for _, name := range PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			config, err := Preset(name)
+			if err != nil {
+				t.Fatalf("Preset(%q) failed: %v", name, err)
+			}
+
+			// Every preset must simulate a short uncontrolled fall without errors
+			pendulum := NewPendulum(config, log.New(io.Discard, "", 0))
+			pendulum.Reset(State{AngleRadians: 0.1})
+			for i := 0; i < 10; i++ {
+				state, err := pendulum.Step(0)
+				if err != nil {
+					t.Fatalf("step %d failed: %v", i, err)
+				}
+				if math.IsNaN(state.AngleRadians) {
+					t.Fatalf("step %d produced a NaN angle", i)
+				}
+			}
+		})
+	}
+
+	t.Run("classic is the default config", func(t *testing.T) {
+		if config, _ := Preset(ClassicPreset); config != NewDefaultConfig() {
+			t.Errorf("classic = %+v, want NewDefaultConfig()", config)
+		}
+	})
+
+	t.Run("names are case-insensitive", func(t *testing.T) {
+		if _, err := Preset("Heavy-Cart"); err != nil {
+			t.Errorf("Preset(\"Heavy-Cart\") failed: %v", err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := Preset("moon"); err == nil {
+			t.Error("expected error for an unknown preset")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Initial state distributions accepted in ResetOptions.Mode
+const (
+	FixedReset    = "fixed"    // Always start from ResetOptions.State (default)
+	UniformReset  = "uniform"  // Start uniformly within ±spread of it
+	GaussianReset = "gaussian" // Start normally distributed around it, with the spreads as std
+)
+
+// ResetOptions chooses the initial state of each episode. Randomizing it
+// keeps a controller from overfitting to a single start, e.g. hanging down
+type ResetOptions struct {
+	Mode  string // FixedReset, UniformReset or GaussianReset
+	State State  // The fixed start, or the center of the random ones
+
+	// Spreads of the random starts, ignored by FixedReset
+	AngleSpread      float64 // Radians around State.AngleRadians
+	AngularVelSpread float64 // rad/s around State.AngularVel
+	PositionSpread   float64 // Meters around State.CartPosition, kept on the track
+}
+
+// NewDefaultResetOptions starts every episode hanging down at rest, the
+// start of a new Pendulum
+func NewDefaultResetOptions() ResetOptions {
+	return ResetOptions{Mode: FixedReset, State: State{AngleRadians: HangingAngle}}
+}
+
+// ResetModes lists the accepted ResetOptions.Mode values
+func ResetModes() []string {
+	return []string{FixedReset, UniformReset, GaussianReset}
+}
+
+// Validate checks the mode and spreads
+func (o ResetOptions) Validate() error {
+	switch o.Mode {
+	case "", FixedReset, UniformReset, GaussianReset:
+	default:
+		return fmt.Errorf("unknown reset mode %q", o.Mode)
+	}
+	if o.AngleSpread < 0 || o.AngularVelSpread < 0 || o.PositionSpread < 0 {
+		return fmt.Errorf("reset spreads must not be negative")
+	}
+	return nil
+}
+
+// Sample draws an initial state from rng. Cart positions are kept inside
+// a track of trackLength meters
+func (o ResetOptions) Sample(rng *rand.Rand, trackLength float64) (State, error) {
+	if err := o.Validate(); err != nil {
+		return State{}, err
+	}
+	var draw func(spread float64) float64
+	switch o.Mode {
+	case UniformReset:
+		draw = func(spread float64) float64 { return (2*rng.Float64() - 1) * spread }
+	case GaussianReset:
+		draw = func(spread float64) float64 { return rng.NormFloat64() * spread }
+	default:
+		return o.State, nil
+	}
+
+	state := o.State
+	state.AngleRadians += draw(o.AngleSpread)
+	state.AngularVel += draw(o.AngularVelSpread)
+	state.CartPosition += draw(o.PositionSpread)
+	if limit := trackLength / 2; trackLength > 0 {
+		state.CartPosition = math.Max(-limit, math.Min(limit, state.CartPosition))
+	}
+	return state, nil
+}
+
+// ResetWith restarts the simulation from an initial state drawn from opts,
+// using the pendulum's random source. One pendulum can then run every
+// episode, instead of a new one being built for each. Returns the state
+func (p *Pendulum) ResetWith(opts ResetOptions) (State, error) {
+	state, err := opts.Sample(p.rng, p.config.TrackLength)
+	if err != nil {
+		return State{}, err
+	}
+	p.Reset(state)
+	return p.state, nil
+}
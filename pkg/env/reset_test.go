@@ -0,0 +1,100 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestResetOptions(t *testing.T) {
+	center := State{AngleRadians: math.Pi, AngularVel: 0.5}
+
+	t.Run("fixed restarts from the given state", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.ActionDelay = 2
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		for i := 0; i < 5; i++ {
+			if _, _, err := p.Advance(5); err != nil {
+				t.Fatalf("Advance failed: %v", err)
+			}
+		}
+
+		state, err := p.ResetWith(ResetOptions{Mode: FixedReset, State: center, AngleSpread: 1})
+		if err != nil {
+			t.Fatalf("ResetWith: %v", err)
+		}
+		if state != center || p.GetState() != center {
+			t.Errorf("state = %+v, want %+v", p.GetState(), center)
+		}
+		if p.GetLastForce() != 0 || p.actuator.pending != nil {
+			t.Errorf("reset kept the last episode's forces")
+		}
+	})
+
+	t.Run("default starts hanging down at rest", func(t *testing.T) {
+		p := NewPendulum(NewDefaultConfig(), log.New(&bytes.Buffer{}, "", 0))
+		initial := p.GetState()
+		if state, err := p.ResetWith(NewDefaultResetOptions()); err != nil || state != initial {
+			t.Errorf("ResetWith(default) = %+v, %v, want a new pendulum's %+v", state, err, initial)
+		}
+	})
+
+	t.Run("uniform stays within the spreads", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		opts := ResetOptions{Mode: UniformReset, State: center, AngleSpread: 0.2, AngularVelSpread: 0.1, PositionSpread: 0.5}
+		distinct := map[float64]bool{}
+		for i := 0; i < 200; i++ {
+			state, err := opts.Sample(rng, 4)
+			if err != nil {
+				t.Fatalf("Sample: %v", err)
+			}
+			if math.Abs(state.AngleRadians-math.Pi) > 0.2 || math.Abs(state.AngularVel-0.5) > 0.1 || math.Abs(state.CartPosition) > 0.5 {
+				t.Fatalf("sample %+v outside the spreads", state)
+			}
+			distinct[state.AngleRadians] = true
+		}
+		if len(distinct) < 100 {
+			t.Errorf("only %d distinct angles in 200 samples", len(distinct))
+		}
+	})
+
+	t.Run("gaussian matches the spreads and stays on the track", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(2))
+		opts := ResetOptions{Mode: GaussianReset, State: center, AngleSpread: 0.1, PositionSpread: 10}
+		const n = 5000
+		sum, sumSq := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			state, err := opts.Sample(rng, 4)
+			if err != nil {
+				t.Fatalf("Sample: %v", err)
+			}
+			if math.Abs(state.CartPosition) > 2 {
+				t.Fatalf("cart position %v left the 4m track", state.CartPosition)
+			}
+			if state.AngularVel != 0.5 {
+				t.Fatalf("angular velocity %v moved without a spread", state.AngularVel)
+			}
+			d := state.AngleRadians - math.Pi
+			sum += d
+			sumSq += d * d
+		}
+		mean := sum / n
+		std := math.Sqrt(sumSq/n - mean*mean)
+		if math.Abs(mean) > 0.01 || math.Abs(std-0.1) > 0.01 {
+			t.Errorf("angle offsets have mean %v and std %v, want 0 and 0.1", mean, std)
+		}
+	})
+
+	t.Run("invalid options are rejected", func(t *testing.T) {
+		for _, opts := range []ResetOptions{
+			{Mode: "random"},
+			{Mode: UniformReset, AngleSpread: -1},
+		} {
+			if _, err := opts.Sample(rand.New(rand.NewSource(1)), 4); err == nil {
+				t.Errorf("Sample(%+v) succeeded, want error", opts)
+			}
+		}
+	})
+}
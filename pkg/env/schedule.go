@@ -0,0 +1,33 @@
+package env
+
+// Event is a disturbance scheduled at a control step of an episode, e.g. a
+// gust scripted by a scenario file
+type Event struct {
+	Tick    int     // Control step of the episode the event starts at, from 0
+	Ticks   int     // Control steps Force lasts for, 1 when 0
+	Force   float64 // External force on the cart in N, positive to the right
+	Impulse float64 // Impulse on the bob in N·s at Tick, see ApplyImpulse
+}
+
+// Schedule is the events of one episode, in any order
+type Schedule []Event
+
+// Push adds an external force in N on the cart for the next step only,
+// positive to the right. Like wind it acts on top of the clamped control
+// force, so it can overpower the controller
+func (p *Pendulum) Push(force float64) {
+	p.push += force
+}
+
+// Apply applies the impulses due at tick to p and pushes it with the forces
+// active during tick. Call it just before the step with that tick
+func (s Schedule) Apply(p *Pendulum, tick int) {
+	for _, event := range s {
+		if tick == event.Tick && event.Impulse != 0 {
+			p.ApplyImpulse(event.Impulse)
+		}
+		if tick >= event.Tick && tick < event.Tick+max(1, event.Ticks) && event.Force != 0 {
+			p.Push(event.Force)
+		}
+	}
+}
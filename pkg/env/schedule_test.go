@@ -0,0 +1,74 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	newPendulum := func() *Pendulum {
+		config := NewDefaultConfig()
+		config.MaxForce = 1
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Reset(State{AngleRadians: UprightAngle})
+		return p
+	}
+
+	t.Run("forces last their ticks and overpower MaxForce", func(t *testing.T) {
+		p := newPendulum()
+		schedule := Schedule{{Tick: 2, Ticks: 3, Force: 10}}
+		for tick := 0; tick < 7; tick++ {
+			schedule.Apply(p, tick)
+			if _, _, err := p.Advance(0); err != nil {
+				t.Fatalf("Advance failed: %v", err)
+			}
+			want := 0.0
+			if tick >= 2 && tick < 5 {
+				want = 10
+			}
+			if got := p.GetLastDisturbance().Pushed; got != want {
+				t.Errorf("tick %d: pushed %v, want %v", tick, got, want)
+			}
+		}
+		if v := p.GetState().CartVelocity; v <= 0 {
+			t.Errorf("cart velocity = %v after a push to the right, want positive", v)
+		}
+	})
+
+	t.Run("impulses apply once", func(t *testing.T) {
+		p := newPendulum()
+		schedule := Schedule{{Tick: 1, Impulse: 0.05}}
+		schedule.Apply(p, 0)
+		if p.GetState().AngularVel != 0 {
+			t.Fatalf("impulse applied before its tick")
+		}
+		schedule.Apply(p, 1)
+		want := 0.05 / (p.GetConfig().PendulumMass * p.GetConfig().Length)
+		if got := p.GetState().AngularVel; math.Abs(got-want) > 1e-12 {
+			t.Errorf("angular velocity = %v after the impulse, want %v", got, want)
+		}
+		schedule.Apply(p, 2)
+		if got := p.GetState().AngularVel; math.Abs(got-want) > 1e-12 {
+			t.Errorf("angular velocity = %v a tick later, want the impulse applied once", got)
+		}
+	})
+	t.Run("pushes survive a discarded step", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.TrackLength = 2.0
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Reset(State{CartPosition: 0.99, CartVelocity: 1})
+		p.Push(3)
+		if _, err := p.Step(0); err == nil {
+			t.Fatal("expected the step off the track to fail")
+		}
+		p.Reset(State{})
+		if _, err := p.Step(0); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+		if got := p.GetLastDisturbance().Pushed; got != 3 {
+			t.Errorf("pushed %v after the discarded step, want the pending 3", got)
+		}
+	})
+}
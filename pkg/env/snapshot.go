@@ -0,0 +1,104 @@
+package env
+
+import (
+	"math/rand"
+	randv2 "math/rand/v2"
+)
+
+// Snapshot is everything that decides how a pendulum evolves from a point
+// in its simulation: the state, the forces still in the actuator, the
+// rounding carry and the position in the disturbance stream. Restoring it
+// branches the simulation from that point, e.g. for planners and what-if
+// rollouts, without building a new pendulum and replaying its history
+type Snapshot struct {
+	State State // True state at the snapshot
+
+	lastForce       float64
+	lastDisturbance Disturbance
+	carry           Increment
+	termination     TerminationReason
+	actuator        actuator
+	push            float64
+	drawn           bool
+	pending         Disturbance
+	rng             *rand.Rand // RNG set by SetRNG, which can't be rewound
+	source          randv2.PCG // Generator state of the pendulum's own disturbance stream
+}
+
+// Snapshot captures the simulation so Restore can return to it. Snapshots
+// are values and stay valid however far the pendulum runs on
+func (p *Pendulum) Snapshot() Snapshot {
+	s := Snapshot{
+		State:           p.state,
+		lastForce:       p.lastForce,
+		lastDisturbance: p.lastDisturbance,
+		carry:           p.carry,
+		termination:     p.termination,
+		actuator:        p.actuator,
+		push:            p.push,
+		drawn:           p.drawn,
+		pending:         p.pending,
+	}
+	s.actuator.pending = append([]float64(nil), p.actuator.pending...)
+	if p.source != nil {
+		s.source = p.source.pcg
+	} else {
+		s.rng = p.rng
+	}
+	return s
+}
+
+// Restore returns the simulation to a snapshot, so the same forces again
+// produce the same states, disturbances and observations. The snapshot may
+// come from another pendulum with the same config. An RNG given to SetRNG
+// is shared with its other users and can't be rewound, so with one set,
+// restored runs draw fresh disturbances
+func (p *Pendulum) Restore(s Snapshot) {
+	p.state = s.State
+	p.lastForce = s.lastForce
+	p.lastDisturbance = s.lastDisturbance
+	p.carry = s.carry
+	p.termination = s.termination
+	p.actuator = s.actuator
+	p.actuator.pending = append([]float64(nil), s.actuator.pending...)
+	p.push = s.push
+	p.drawn, p.pending = s.drawn, s.pending
+
+	if s.rng != nil {
+		p.source, p.rng = nil, s.rng
+		return
+	}
+	if p.source == nil {
+		p.source = &randSource{}
+		p.rng = rand.New(p.source)
+	}
+	p.source.pcg = s.source
+}
+
+// randSource is a rand.Source over a PCG generator. Its whole state is two
+// words, so snapshots copy it rather than replaying the stream from the seed
+type randSource struct {
+	pcg randv2.PCG
+}
+
+// newRandSource creates a source seeded with seed
+func newRandSource(seed int64) *randSource {
+	s := &randSource{}
+	s.Seed(seed)
+	return s
+}
+
+// Int63 implements rand.Source
+func (s *randSource) Int63() int64 {
+	return int64(s.pcg.Uint64() >> 1)
+}
+
+// Uint64 implements rand.Source64
+func (s *randSource) Uint64() uint64 {
+	return s.pcg.Uint64()
+}
+
+// Seed implements rand.Source, restarting the stream
+func (s *randSource) Seed(seed int64) {
+	s.pcg.Seed(uint64(seed), 0)
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	config := NewDefaultConfig()
+	config.TrackLength = 1000.0
+	config.ActionDelay = 2
+	config.Accumulation = "compensated"
+	config.ImpulseProb = 0.2
+	config.ImpulseForce = 5
+	config.WindNoise = 0.5
+	config.SensorNoise = 0.01
+	config.Seed = 7
+
+	type step struct {
+		observed, state State
+		force           float64
+		disturbance     Disturbance
+	}
+	run := func(p *Pendulum, forces []float64) []step {
+		var steps []step
+		for _, f := range forces {
+			observed, _, err := p.Advance(f)
+			if err != nil {
+				t.Fatalf("Advance failed: %v", err)
+			}
+			steps = append(steps, step{observed, p.GetState(), p.GetLastForce(), p.GetLastDisturbance()})
+		}
+		return steps
+	}
+	same := func(t *testing.T, got, want []step) {
+		t.Helper()
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("step %d after restore = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	}
+	forces := []float64{3, -1, 4, -1, 5, -9, 2, 6}
+
+	p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+	p.Reset(State{AngleRadians: 0.1})
+	run(p, forces)
+	snapshot := p.Snapshot()
+	want := run(p, forces)
+
+	t.Run("rewind", func(t *testing.T) {
+		p.Restore(snapshot)
+		same(t, run(p, forces), want)
+		p.Restore(snapshot)
+		if p.GetState() != snapshot.State {
+			t.Errorf("state after restore = %+v, want %+v", p.GetState(), snapshot.State)
+		}
+	})
+
+	t.Run("branch", func(t *testing.T) {
+		p.Restore(snapshot)
+		// The delayed forces already chosen still arrive first
+		other := run(p, []float64{-3, -3, -3})
+		if other[1] != want[1] || other[2].state == want[2].state {
+			t.Error("different forces after restore didn't branch once the delay passed")
+		}
+		p.Restore(snapshot)
+		same(t, run(p, forces), want)
+	})
+
+	t.Run("other pendulum", func(t *testing.T) {
+		q := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		q.Restore(snapshot)
+		same(t, run(q, forces), want)
+	})
+}
@@ -0,0 +1,32 @@
+package env
+
+import "math"
+
+// SuccessCriteria decides when an episode or a single step counts as a
+// success. Configure one and hand it to the network, trainer and metrics
+// logger so the success rates they report agree
+type SuccessCriteria struct {
+	MaxAngle    float64 // Largest deviation from upright in radians a successful episode may reach
+	MinDuration float64 // Seconds a successful episode must last (0 for any length)
+	StepReward  float64 // Smallest reward of a successful step, where only rewards are seen
+}
+
+// NewDefaultSuccessCriteria returns 5 seconds within 30 degrees of upright
+func NewDefaultSuccessCriteria() SuccessCriteria {
+	return SuccessCriteria{
+		MaxAngle:    math.Pi / 6,
+		MinDuration: 5.0,
+		StepReward:  0.5,
+	}
+}
+
+// Episode reports whether an episode that lasted duration seconds and
+// strayed at most maxDeviation radians from upright succeeded
+func (c SuccessCriteria) Episode(maxDeviation, duration float64) bool {
+	return maxDeviation < c.MaxAngle && duration >= c.MinDuration
+}
+
+// Step reports whether a step's reward counts as a success
+func (c SuccessCriteria) Step(reward float64) bool {
+	return reward > c.StepReward
+}
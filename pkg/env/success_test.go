@@ -0,0 +1,37 @@
+package env
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSuccessCriteria(t *testing.T) {
+	criteria := NewDefaultSuccessCriteria()
+
+	tests := []struct {
+		name         string
+		maxDeviation float64
+		duration     float64
+		want         bool
+	}{
+		{"upright long enough", 0.1, 5.0, true},
+		{"fell too far", math.Pi / 4, 10.0, false},
+		{"too short", 0.1, 4.9, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := criteria.Episode(tc.maxDeviation, tc.duration); got != tc.want {
+				t.Errorf("Episode(%.2f, %.1f) = %v, want %v", tc.maxDeviation, tc.duration, got, tc.want)
+			}
+		})
+	}
+
+	if !criteria.Step(0.6) || criteria.Step(0.5) {
+		t.Error("Expected steps to succeed only above a reward of 0.5")
+	}
+
+	criteria.MinDuration = 0
+	if !criteria.Episode(0.1, 0) {
+		t.Error("Expected any length to succeed with MinDuration 0")
+	}
+}
@@ -0,0 +1,37 @@
+package env
+
+// TerminationReason says why Advance ended an episode
+type TerminationReason string
+
+// Termination reasons, empty while the episode continues
+const (
+	NotTerminated TerminationReason = ""
+	OutOfBounds   TerminationReason = "out_of_bounds"  // The cart left the track under the terminate bounds policy
+	AngleLimit    TerminationReason = "angle_limit"    // The pendulum fell past Termination.MaxAngle
+	StepLimit     TerminationReason = "step_limit"     // The episode reached Termination.MaxSteps
+	InvalidConfig TerminationReason = "invalid_config" // The step failed, e.g. on an unknown integrator
+)
+
+// TerminationConfig decides when Advance ends an episode besides leaving
+// the track. Zero values disable each condition
+type TerminationConfig struct {
+	MaxAngle float64 // Largest deviation from upright in radians
+	MaxSteps uint64  // Steps before the episode is cut off
+}
+
+// terminationReason checks the true state against the termination config
+func (c TerminationConfig) terminationReason(s State) TerminationReason {
+	if c.MaxAngle > 0 && UprightDeviation(s.AngleRadians) > c.MaxAngle {
+		return AngleLimit
+	}
+	if c.MaxSteps > 0 && s.TimeStep >= c.MaxSteps {
+		return StepLimit
+	}
+	return NotTerminated
+}
+
+// GetTermination returns why the last call to Advance ended the episode,
+// or NotTerminated if it did not
+func (p *Pendulum) GetTermination() TerminationReason {
+	return p.termination
+}
@@ -0,0 +1,70 @@
+package env
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"testing"
+)
+
+func TestTermination(t *testing.T) {
+	run := func(termination TerminationConfig, initial State, steps int) (*Pendulum, int) {
+		config := NewDefaultConfig()
+		config.TrackLength = 1000.0
+		config.Termination = termination
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		p.Reset(initial)
+		for i := 0; i < steps; i++ {
+			_, done, err := p.Advance(0)
+			if err != nil {
+				t.Fatalf("step %d failed: %v", i, err)
+			}
+			if done {
+				return p, i + 1
+			}
+		}
+		return p, steps
+	}
+
+	t.Run("angle", func(t *testing.T) {
+		p, steps := run(TerminationConfig{MaxAngle: 0.5}, State{AngleRadians: 0.1}, 500)
+		if p.GetTermination() != AngleLimit {
+			t.Fatalf("termination = %q after %d steps, want %q", p.GetTermination(), steps, AngleLimit)
+		}
+		if deviation := UprightDeviation(p.GetState().AngleRadians); deviation <= 0.5 {
+			t.Errorf("ended at deviation %.3f, within the limit", deviation)
+		}
+	})
+
+	t.Run("steps", func(t *testing.T) {
+		p, steps := run(TerminationConfig{MaxSteps: 25}, State{AngleRadians: math.Pi}, 100)
+		if p.GetTermination() != StepLimit || steps != 25 {
+			t.Errorf("termination = %q after %d steps, want %q after 25", p.GetTermination(), steps, StepLimit)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		p, steps := run(TerminationConfig{}, State{AngleRadians: 0.1}, 100)
+		if p.GetTermination() != NotTerminated || steps != 100 {
+			t.Errorf("termination = %q after %d steps, want none", p.GetTermination(), steps)
+		}
+	})
+
+	t.Run("bounds", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.TrackLength = 2.0
+		p := NewPendulum(config, log.New(&bytes.Buffer{}, "", 0))
+		for i := 0; i < 200; i++ {
+			if _, done, _ := p.Advance(config.MaxForce); done {
+				break
+			}
+		}
+		if p.GetTermination() != OutOfBounds {
+			t.Errorf("termination = %q, want %q", p.GetTermination(), OutOfBounds)
+		}
+		p.Reset(State{})
+		if p.GetTermination() != NotTerminated {
+			t.Error("Reset should clear the termination reason")
+		}
+	})
+}
@@ -18,8 +18,33 @@ type Config struct {
 	Length       float64 // length of pendulum in meters
 	Gravity      float64 // gravitational acceleration in m/s²
 	MaxForce     float64 // maximum force that can be applied to cart
-	DeltaTime    float64 // simulation timestep in seconds
+	DeltaTime    float64 // control period in seconds (one Step)
+	SubSteps     int     // physics integration steps per control Step (0 or 1 for one)
 	TrackLength  float64 // length of the track in meters
+	Integrator   string  // integration scheme: "semi-implicit-euler" (default), "euler" or "rk4"
+	Accumulation string  // how increments are summed into the state: "plain" (default) or "compensated"
+	Bounds       string  // what happens at the track edges: "terminate" (default), "clamp" or "bounce"
+	Termination  TerminationConfig // when Advance ends an episode besides leaving the track
+
+	// Actuation latency, both immediate at zero
+	ActionDelay int // control steps between choosing a force and the cart receiving it
+	ControlHold int // control steps each chosen force is held for (zero-order hold); forces chosen in between are ignored
+
+	// Observation model, the full exact state at the zero values
+	Observation       string  // what the controller observes: "full" (default) or "angle-only"
+	EncoderResolution float64 // step in radians the observed angle is quantized to, like a rotary encoder (0 for exact)
+
+	// Energy losses, both lossless at zero
+	CartFriction   float64 // viscous friction on the cart in N·s/m
+	AngularDamping float64 // pivot damping as angular deceleration per rad/s, in 1/s
+
+	// Disturbances, all disabled at zero
+	ImpulseProb  float64 // probability per step of an impulse on the cart
+	ImpulseForce float64 // magnitude in N of an impulse, applied for one step
+	WindForce    float64 // mean wind force on the cart in N
+	WindNoise    float64 // standard deviation of the wind force in N
+	SensorNoise  float64 // standard deviation of noise added to observed angle and angular velocity
+	Seed         int64   // seed for the disturbance RNG, so runs are reproducible
 }
 
 // NewDefaultConfig returns a Config with reasonable default values
@@ -35,6 +60,15 @@ func NewDefaultConfig() Config {
 	}
 }
 
+// PhysicsDeltaTime returns the integration timestep, DeltaTime split
+// evenly across SubSteps
+func (c Config) PhysicsDeltaTime() float64 {
+	if c.SubSteps <= 1 {
+		return c.DeltaTime
+	}
+	return c.DeltaTime / float64(c.SubSteps)
+}
+
 // NormalizeAngle ensures angle stays within [0, 2π) while maintaining continuity
 func NormalizeAngle(angle float64) float64 {
 	// Get the raw modulo
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stepVector is one canonical Step: a state and force, and the exact next state
+type stepVector struct {
+	Integrator string  `json:"integrator"`
+	SubSteps   int     `json:"sub_steps"`
+	State      State   `json:"state"`
+	Force      float64 `json:"force"`
+	Next       State   `json:"next"`
+}
+
+// stepVectors steps every integrator from states around the circle under
+// forces past both limits
+func stepVectors() []stepVector {
+	var vectors []stepVector
+	for _, integrator := range []string{SemiImplicitEuler, Euler, RK4} {
+		for _, subSteps := range []int{0, 4} {
+			for i, angle := range []float64{0, 0.05, -0.3, math.Pi / 2, 2.5, math.Pi, -3.1, 5.9} {
+				for _, force := range []float64{0, 3.7, -10, 12.5} {
+					vectors = append(vectors, stepVector{
+						Integrator: integrator,
+						SubSteps:   subSteps,
+						State:      State{CartPosition: 0.3 - 0.1*float64(i), CartVelocity: 0.2 * float64(i%3-1), AngleRadians: angle, AngularVel: 1.3 - 0.4*float64(i)},
+						Force:      force,
+					})
+				}
+			}
+		}
+	}
+	for i := range vectors {
+		v := &vectors[i]
+		config := NewDefaultConfig()
+		config.Integrator = v.Integrator
+		config.SubSteps = v.SubSteps
+		p := NewPendulum(config, log.New(io.Discard, "", 0))
+		p.Reset(v.State)
+		v.Next, _ = p.Step(v.Force)
+	}
+	return vectors
+}
+
+// TestStepVectors pins Pendulum.Step bit for bit, so replays of recorded
+// episodes match on every platform
+func TestStepVectors(t *testing.T) {
+	path := filepath.Join("testdata", "step_vectors.json")
+	if *updateGolden {
+		data, err := json.MarshalIndent(stepVectors(), "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to marshal step vectors: %v", err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("Failed to write step vectors: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read step vectors (run with -update to create them): %v", err)
+	}
+	var golden []stepVector
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("Failed to unmarshal step vectors: %v", err)
+	}
+	if len(golden) == 0 {
+		t.Fatal("No step vectors")
+	}
+	for _, want := range golden {
+		config := NewDefaultConfig()
+		config.Integrator = want.Integrator
+		config.SubSteps = want.SubSteps
+		p := NewPendulum(config, log.New(io.Discard, "", 0))
+		p.Reset(want.State)
+		got, _ := p.Step(want.Force)
+		if !sameBits(got, want.Next) {
+			t.Errorf("%s x%d from %+v with force %v:\n  got:  %+v\n  want: %+v",
+				want.Integrator, want.SubSteps, want.State, want.Force, got, want.Next)
+		}
+	}
+}
+
+// sameBits reports whether two states are identical to the last bit
+func sameBits(a, b State) bool {
+	return math.Float64bits(a.CartPosition) == math.Float64bits(b.CartPosition) &&
+		math.Float64bits(a.CartVelocity) == math.Float64bits(b.CartVelocity) &&
+		math.Float64bits(a.AngleRadians) == math.Float64bits(b.AngleRadians) &&
+		math.Float64bits(a.AngularVel) == math.Float64bits(b.AngularVel) &&
+		a.TimeStep == b.TimeStep
+}
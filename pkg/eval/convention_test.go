@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"io"
+	"log"
+	"math"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/reward"
+)
+
+// TestBalancedAgreesAcrossPackages checks that env, reward, the network
+// and evaluation all treat the same angle as upright, however it is stored
+func TestBalancedAgreesAcrossPackages(t *testing.T) {
+	rewards := reward.NewRewardCalculator()
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+
+	tests := []struct {
+		name     string
+		angle    float64
+		balanced bool
+	}{
+		{"upright", env.UprightAngle, true},
+		{"just right of upright", 0.05, true},
+		{"just left of upright, stored near 2π", env.AngleFromUpright(-0.05), true},
+		{"a full turn past upright", 2*math.Pi + 0.05, true},
+		{"horizontal", math.Pi / 2, false},
+		{"hanging", env.HangingAngle, false},
+	}
+	tolerance := env.NewDefaultSuccessCriteria().MaxAngle
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := env.State{AngleRadians: tt.angle}
+			deviation := env.UprightDeviation(tt.angle)
+
+			if got := env.IsBalanced(tt.angle, tolerance); got != tt.balanced {
+				t.Errorf("env.IsBalanced = %v, want %v", got, tt.balanced)
+			}
+			if got := Deviation(tt.angle); got != deviation {
+				t.Errorf("Deviation = %.4f, env says %.4f", got, deviation)
+			}
+			if got, want := rewards.Calculate(state), math.Cos(deviation); math.Abs(got-want) > 1e-12 {
+				t.Errorf("reward = %.4f, want cos of the deviation %.4f", got, want)
+			}
+			network.Forward(state)
+			if got := network.GetLastInputs()[0]; math.Abs(got-env.UprightOffset(tt.angle)) > 1e-12 {
+				t.Errorf("network angle input = %.4f, want the offset from upright %.4f", got, env.UprightOffset(tt.angle))
+			}
+		})
+	}
+
+	// Rewards and values peak at upright and fall toward hanging
+	upright, hanging := env.State{AngleRadians: env.UprightAngle}, env.State{AngleRadians: env.HangingAngle}
+	if rewards.Calculate(upright) != 1 || rewards.Calculate(hanging) != -1 {
+		t.Errorf("reward upright %.4f, hanging %.4f; want 1 and -1", rewards.Calculate(upright), rewards.Calculate(hanging))
+	}
+	if network.Value(env.UprightAngle, 0) <= network.Value(env.HangingAngle, 0) {
+		t.Error("Expected the network to value upright above hanging")
+	}
+}
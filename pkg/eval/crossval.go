@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// PhysicsSpread bounds the random physics RandomPhysics draws. Each
+// parameter is scaled by a factor drawn uniformly from [1-spread, 1+spread]
+// of its own spread; a zero spread leaves the parameter fixed
+type PhysicsSpread struct {
+	CartMass     float64
+	PendulumMass float64
+	Length       float64
+	Gravity      float64
+}
+
+// NewDefaultPhysicsSpread varies the masses and pole length by ±30% and
+// gravity by ±10%
+func NewDefaultPhysicsSpread() PhysicsSpread {
+	return PhysicsSpread{CartMass: 0.3, PendulumMass: 0.3, Length: 0.3, Gravity: 0.1}
+}
+
+// RandomPhysics draws n physics configurations around base, seeded so the
+// same seed gives every controller the same draws. Everything but the
+// masses, length and gravity is kept from base
+func RandomPhysics(base env.Config, spread PhysicsSpread, n int, seed int64) []env.Config {
+	rng := rand.New(rand.NewSource(seed))
+	scale := func(value, spread float64) float64 {
+		spread = math.Min(math.Abs(spread), 0.95) // Keep every parameter positive
+		return value * (1 + spread*(2*rng.Float64()-1))
+	}
+
+	configs := make([]env.Config, n)
+	for i := range configs {
+		config := base
+		config.CartMass = scale(base.CartMass, spread.CartMass)
+		config.PendulumMass = scale(base.PendulumMass, spread.PendulumMass)
+		config.Length = scale(base.Length, spread.Length)
+		config.Gravity = scale(base.Gravity, spread.Gravity)
+		configs[i] = config
+	}
+	return configs
+}
+
+// Fold is a controller's result on a suite run with one physics configuration
+type Fold struct {
+	Physics env.Config
+	Result  Result
+}
+
+// CrossValidation scores a controller on one suite over several physics
+// configurations. A controller overfit to one configuration shows a worst
+// case far below its mean
+type CrossValidation struct {
+	Suite            string
+	Folds            []Fold
+	MeanReward       float64 // Mean over the folds of their average reward
+	StdReward        float64
+	WorstReward      float64 // Lowest average reward of any fold
+	MeanSuccessRate  float64
+	WorstSuccessRate float64
+	Worst            int // Index of the fold with the lowest average reward
+}
+
+// CrossValidate runs the suite once on each physics configuration, keeping
+// each scenario's own disturbances and seed as WithPhysics does
+func CrossValidate(suite Suite, controller Controller, physics []env.Config) CrossValidation {
+	cv := CrossValidation{Suite: suite.Name}
+	if len(physics) == 0 {
+		return cv
+	}
+
+	cv.WorstReward = math.Inf(1)
+	cv.WorstSuccessRate = math.Inf(1)
+	for i, config := range physics {
+		result := Run(suite.WithPhysics(config), controller)
+		cv.Folds = append(cv.Folds, Fold{Physics: config, Result: result})
+
+		cv.MeanReward += result.AvgReward
+		cv.MeanSuccessRate += result.SuccessRate
+		if result.AvgReward < cv.WorstReward {
+			cv.WorstReward = result.AvgReward
+			cv.Worst = i
+		}
+		cv.WorstSuccessRate = math.Min(cv.WorstSuccessRate, result.SuccessRate)
+	}
+
+	n := float64(len(cv.Folds))
+	cv.MeanReward /= n
+	cv.MeanSuccessRate /= n
+	for _, fold := range cv.Folds {
+		cv.StdReward += (fold.Result.AvgReward - cv.MeanReward) * (fold.Result.AvgReward - cv.MeanReward)
+	}
+	cv.StdReward = math.Sqrt(cv.StdReward / n)
+	return cv
+}
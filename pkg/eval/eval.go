@@ -0,0 +1,173 @@
+// Package eval runs controllers against standardized, seeded scenario
+// suites so results from different commands and checkpoints are comparable
+package eval
+
+import (
+	"io"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// Controller is anything that maps a state to a force
+type Controller = agent.Controller
+
+// Scenario is one evaluation episode with fixed initial conditions and,
+// optionally, disturbances scripted at fixed ticks
+type Scenario struct {
+	Name    string
+	Config  env.Config
+	Initial env.State
+	Steps   int
+	Events  env.Schedule
+}
+
+// Suite is a named, fixed collection of scenarios
+type Suite struct {
+	Name         string
+	Scenarios    []Scenario
+	SuccessAngle float64 // Maximum deviation from upright (radians) for an episode to count as a success
+}
+
+// EpisodeResult holds the outcome of a single scenario
+type EpisodeResult struct {
+	Scenario    string
+	Steps       int // Steps completed before the episode ended
+	TotalReward float64
+	AvgReward   float64 // Mean per-step reward in [0, 1]
+	BalanceTime float64 // Seconds spent within SuccessAngle of upright
+	MaxAngle    float64 // Largest deviation from upright in radians
+	Success     bool    // Stayed within SuccessAngle for the whole episode
+	OutOfBounds bool    // Episode ended early at the track bounds
+}
+
+// Percentiles summarizes a distribution of per-episode values
+type Percentiles struct {
+	P10 float64
+	P50 float64
+	P90 float64
+}
+
+// Result aggregates a controller's performance on a suite
+type Result struct {
+	Suite          string
+	Episodes       []EpisodeResult
+	AvgReward      float64
+	AvgBalanceTime float64
+	MaxAngle       float64
+	SuccessRate    float64
+	Reward         Percentiles
+	BalanceTime    Percentiles
+}
+
+// Run evaluates the controller on every scenario of the suite
+func Run(suite Suite, controller Controller) Result {
+	result := Result{Suite: suite.Name}
+	if len(suite.Scenarios) == 0 {
+		return result
+	}
+
+	rewards := make([]float64, 0, len(suite.Scenarios))
+	balanceTimes := make([]float64, 0, len(suite.Scenarios))
+	successes := 0
+
+	for _, scenario := range suite.Scenarios {
+		episode := RunScenario(scenario, controller, suite.SuccessAngle)
+		result.Episodes = append(result.Episodes, episode)
+
+		rewards = append(rewards, episode.AvgReward)
+		balanceTimes = append(balanceTimes, episode.BalanceTime)
+		result.AvgReward += episode.AvgReward
+		result.AvgBalanceTime += episode.BalanceTime
+		result.MaxAngle = math.Max(result.MaxAngle, episode.MaxAngle)
+		if episode.Success {
+			successes++
+		}
+	}
+
+	n := float64(len(suite.Scenarios))
+	result.AvgReward /= n
+	result.AvgBalanceTime /= n
+	result.SuccessRate = float64(successes) / n
+	result.Reward = percentiles(rewards)
+	result.BalanceTime = percentiles(balanceTimes)
+	return result
+}
+
+// historyController is a controller that remembers earlier steps of an
+// episode, e.g. a network with stacked frames or a recurrent cell
+type historyController interface {
+	ResetHistory()
+}
+
+// RunScenario evaluates the controller on a single scenario, starting any
+// history it keeps afresh
+func RunScenario(scenario Scenario, controller Controller, successAngle float64) EpisodeResult {
+	if c, ok := controller.(historyController); ok {
+		c.ResetHistory()
+	}
+	pendulum := env.NewPendulum(scenario.Config, log.New(io.Discard, "", 0))
+	pendulum.Reset(scenario.Initial)
+
+	result := EpisodeResult{Scenario: scenario.Name, Success: true}
+	state := pendulum.GetState()
+	for i := 0; i < scenario.Steps; i++ {
+		force := controller.Act(state)
+		scenario.Events.Apply(pendulum, i)
+		next, done, _ := pendulum.Advance(force)
+		if done {
+			result.OutOfBounds = true
+			result.Success = false
+			break
+		}
+		state = next
+
+		// Score the true state so sensor noise does not skew results
+		deviation := Deviation(pendulum.GetState().AngleRadians)
+		result.Steps++
+		result.TotalReward += 1.0 - deviation/math.Pi
+		result.MaxAngle = math.Max(result.MaxAngle, deviation)
+		if deviation <= successAngle {
+			result.BalanceTime += scenario.Config.DeltaTime
+		} else {
+			result.Success = false
+		}
+	}
+
+	if scenario.Steps > 0 {
+		// Steps lost to an early exit count as zero reward
+		result.AvgReward = result.TotalReward / float64(scenario.Steps)
+	}
+	return result
+}
+
+// Deviation returns the absolute angle from upright in [0, π]
+func Deviation(angle float64) float64 {
+	return env.UprightDeviation(angle)
+}
+
+// percentiles computes the 10th, 50th and 90th percentiles with linear interpolation
+func percentiles(values []float64) Percentiles {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return Percentiles{
+		P10: percentile(sorted, 0.1),
+		P50: percentile(sorted, 0.5),
+		P90: percentile(sorted, 0.9),
+	}
+}
+
+// percentile returns the p-th quantile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower]*(1-frac) + sorted[upper]*frac
+}
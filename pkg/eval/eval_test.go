@@ -0,0 +1,225 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestRunIsDeterministic(t *testing.T) {
+	controller := agent.ControllerFunc(func(s env.State) float64 { return 0 })
+
+	for name, suite := range Suites() {
+		first := Run(suite, controller)
+		second := Run(suite, controller)
+		if first.AvgReward != second.AvgReward || first.MaxAngle != second.MaxAngle {
+			t.Errorf("%s suite results differ between runs: %+v vs %+v", name, first, second)
+		}
+		if len(first.Episodes) != len(suite.Scenarios) {
+			t.Errorf("%s suite: got %d episodes, want %d", name, len(first.Episodes), len(suite.Scenarios))
+		}
+	}
+}
+
+func TestWithPhysics(t *testing.T) {
+	physics, err := env.Preset(env.LongPolePreset)
+	if err != nil {
+		t.Fatalf("Preset failed: %v", err)
+	}
+
+	suite := RobustnessSuite()
+	moved := suite.WithPhysics(physics)
+	for i, scenario := range moved.Scenarios {
+		original := suite.Scenarios[i].Config
+		if scenario.Config.Length != physics.Length {
+			t.Errorf("%s: length = %v, want the preset's %v", scenario.Name, scenario.Config.Length, physics.Length)
+		}
+		if scenario.Config.Seed != original.Seed || scenario.Config.ImpulseProb != original.ImpulseProb {
+			t.Errorf("%s: disturbances %+v not kept from %+v", scenario.Name, scenario.Config, original)
+		}
+	}
+	if suite.Scenarios[0].Config.Length == physics.Length {
+		t.Error("WithPhysics modified the original suite")
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	scenario := Scenario{
+		Name:    "upright",
+		Config:  env.NewDefaultConfig(),
+		Initial: env.State{},
+		Steps:   10,
+	}
+
+	// Resting exactly upright with no force stays balanced
+	result := RunScenario(scenario, agent.ControllerFunc(func(s env.State) float64 { return 0 }), math.Pi/4)
+	if !result.Success || result.Steps != 10 {
+		t.Errorf("expected a full successful episode, got %+v", result)
+	}
+	if math.Abs(result.AvgReward-1.0) > 1e-9 {
+		t.Errorf("AvgReward = %.4f, want 1.0", result.AvgReward)
+	}
+	if math.Abs(result.BalanceTime-10*scenario.Config.DeltaTime) > 1e-9 {
+		t.Errorf("BalanceTime = %.4f, want %.4f", result.BalanceTime, 10*scenario.Config.DeltaTime)
+	}
+
+	// Driving into the track bounds ends the episode early
+	scenario.Steps = 1000
+	result = RunScenario(scenario, agent.ControllerFunc(func(s env.State) float64 { return 10 }), math.Pi/4)
+	if !result.OutOfBounds || result.Success {
+		t.Errorf("expected an out-of-bounds failure, got %+v", result)
+	}
+}
+
+// countingController counts its steps since the last history reset
+type countingController struct{ steps, resets int }
+
+func (c *countingController) Act(env.State) float64 { c.steps++; return 0 }
+func (c *countingController) ResetHistory()         { c.steps = 0; c.resets++ }
+
+func TestRunResetsHistory(t *testing.T) {
+	suite := StandardSuite()
+	controller := &countingController{}
+	Run(suite, controller)
+	if controller.resets != len(suite.Scenarios) {
+		t.Errorf("history reset %d times, want once per each of %d scenarios", controller.resets, len(suite.Scenarios))
+	}
+	if last := suite.Scenarios[len(suite.Scenarios)-1]; controller.steps > last.Steps {
+		t.Errorf("controller kept %d steps of history, more than the last scenario's %d", controller.steps, last.Steps)
+	}
+}
+
+func TestDeviation(t *testing.T) {
+	tests := []struct {
+		angle float64
+		want  float64
+	}{
+		{0, 0},
+		{0.2, 0.2},
+		{-0.2, 0.2},
+		{2*math.Pi - 0.2, 0.2},
+		{math.Pi, math.Pi},
+	}
+	for _, tt := range tests {
+		if got := Deviation(tt.angle); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Deviation(%.3f) = %.4f, want %.4f", tt.angle, got, tt.want)
+		}
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	p := percentiles([]float64{5, 1, 4, 2, 3})
+	if p.P50 != 3 {
+		t.Errorf("P50 = %.2f, want 3", p.P50)
+	}
+	if math.Abs(p.P10-1.4) > 1e-9 || math.Abs(p.P90-4.6) > 1e-9 {
+		t.Errorf("P10/P90 = %.2f/%.2f, want 1.4/4.6", p.P10, p.P90)
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	base := env.NewDefaultConfig()
+	physics := RandomPhysics(base, NewDefaultPhysicsSpread(), 4, 7)
+	if again := RandomPhysics(base, NewDefaultPhysicsSpread(), 4, 7); again[3] != physics[3] {
+		t.Errorf("draws differ for the same seed: %+v vs %+v", physics[3], again[3])
+	}
+	for i, config := range physics {
+		if math.Abs(config.Length/base.Length-1) > 0.3 || math.Abs(config.Gravity/base.Gravity-1) > 0.1 {
+			t.Errorf("physics %d = %+v, outside the spread around %+v", i, config, base)
+		}
+		if config.DeltaTime != base.DeltaTime || config.TrackLength != base.TrackLength {
+			t.Errorf("physics %d changed more than masses, length and gravity: %+v", i, config)
+		}
+	}
+	if fixed := RandomPhysics(base, PhysicsSpread{}, 1, 7)[0]; fixed != base {
+		t.Errorf("zero spread drew %+v, want %+v", fixed, base)
+	}
+
+	// Whatever a PD controller scores, no fold can beat the mean
+	controller := agent.ControllerFunc(func(s env.State) float64 {
+		return 30*env.UprightOffset(s.AngleRadians) + 5*s.AngularVel
+	})
+	cv := CrossValidate(StandardSuite(), controller, physics)
+	if len(cv.Folds) != 4 || cv.Suite != "standard" {
+		t.Fatalf("got %d folds of %q, want 4 of the standard suite", len(cv.Folds), cv.Suite)
+	}
+	worst := cv.Folds[cv.Worst].Result.AvgReward
+	if worst != cv.WorstReward || cv.WorstReward > cv.MeanReward || cv.WorstSuccessRate > cv.MeanSuccessRate {
+		t.Errorf("worst reward %v (fold %v) and success %v should not beat the means %v and %v",
+			cv.WorstReward, worst, cv.WorstSuccessRate, cv.MeanReward, cv.MeanSuccessRate)
+	}
+	for i, fold := range cv.Folds {
+		if want := Run(StandardSuite().WithPhysics(physics[i]), controller).AvgReward; fold.Result.AvgReward != want {
+			t.Errorf("fold %d reward = %v, want %v from running it alone", i, fold.Result.AvgReward, want)
+		}
+	}
+}
+
+func TestSeedVariance(t *testing.T) {
+	if q := tQuantile(0.975, 4); math.Abs(q-2.7764) > 1e-4 {
+		t.Errorf("t quantile 0.975 with 4 df = %v, want 2.7764", q)
+	}
+	if p := tCDF(2, 10); math.Abs(p-0.96330) > 1e-5 {
+		t.Errorf("t CDF at 2 with 10 df = %v, want 0.96330", p)
+	}
+
+	seeds := func(name string, rewards ...float64) SeedVariance {
+		results := make([]Result, len(rewards))
+		for i, r := range rewards {
+			results[i] = Result{AvgReward: r, SuccessRate: 0.5}
+		}
+		return SummarizeSeeds(name, results)
+	}
+	low := seeds("low", 1, 2, 3, 4, 5)
+	if e := low.Reward; e.Mean != 3 || math.Abs(e.Std-math.Sqrt(2.5)) > 1e-12 || math.Abs(e.High-e.Mean-2.7764*math.Sqrt(0.5)) > 1e-3 {
+		t.Errorf("reward estimate = %+v, want mean 3, std √2.5 and a t interval", e)
+	}
+
+	// Means 5 apart with unit standard error: t = 5 on 8 df
+	c := CompareSeeds(low, seeds("high", 6, 7, 8, 9, 10))
+	if c.Reward.Delta != 5 || math.Abs(c.Reward.PValue-0.00105) > 1e-4 || !c.Reward.Significant {
+		t.Errorf("well separated rewards = %+v, want a significant difference with p ≈ 0.00105", c.Reward)
+	}
+	if c.SuccessRate.Significant || c.SuccessRate.PValue != 1 {
+		t.Errorf("identical success rates = %+v, want no significant difference", c.SuccessRate)
+	}
+	if c := CompareSeeds(seeds("a", 1, 2, 3), seeds("b", 1.5, 2.5, 3.5)); c.Reward.Significant {
+		t.Errorf("overlapping rewards = %+v, want no significant difference", c.Reward)
+	}
+	if c := CompareSeeds(seeds("a", 1), seeds("b", 100)); c.Reward.Significant {
+		t.Errorf("single seeds = %+v, want no significant difference", c.Reward)
+	}
+}
+
+func TestSoak(t *testing.T) {
+	controller := func(cartGain float64) Controller {
+		return agent.ControllerFunc(func(s env.State) float64 {
+			return 30*env.UprightOffset(s.AngleRadians) + 5*s.AngularVel + cartGain*(s.CartPosition+2*s.CartVelocity)
+		})
+	}
+	config := NewSoakConfig(env.NewDefaultConfig(), 600)
+
+	report := Soak(config, controller(1))
+	if report.Failure != "" || report.Steps != 30000 || report.SimulatedTime != 600 {
+		t.Fatalf("full state controller ended with %q after %d steps (%.0fs), want 10 minutes", report.Failure, report.Steps, report.SimulatedTime)
+	}
+	if len(report.Windows) != 10 || report.Windows[9].Start != 27000 {
+		t.Errorf("got %d windows, want 10 one minute windows", len(report.Windows))
+	}
+	if !report.EnergyChecked || report.MaxEnergyError.Value > 0.05 {
+		t.Errorf("energy error = %+v, want it tracked and small", report.MaxEnergyError)
+	}
+	if report.MaxDeviation.Value > config.Initial.AngleRadians || math.Abs(report.CreepTrend) > 0.1 {
+		t.Errorf("report = %+v, want no excursion beyond the start and no creep", report)
+	}
+
+	// Balancing the pole alone lets the cart creep off the track
+	if report := Soak(config, controller(0)); report.Failure != string(env.OutOfBounds) || report.MaxOffset.Step != report.FailureStep {
+		t.Errorf("angle-only controller ended with %q at step %d, want it to leave the track", report.Failure, report.FailureStep)
+	}
+	if report := Soak(config, agent.ControllerFunc(func(s env.State) float64 { return 0 })); report.Failure != string(env.AngleLimit) {
+		t.Errorf("idle controller ended with %q, want a fall", report.Failure)
+	}
+}
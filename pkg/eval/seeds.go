@@ -0,0 +1,192 @@
+package eval
+
+import (
+	"math"
+)
+
+// SignificanceLevel is the p-value below which CompareSeeds calls a
+// difference significant
+const SignificanceLevel = 0.05
+
+// Estimate is a mean over seeds with its spread and 95% confidence interval
+type Estimate struct {
+	Mean float64
+	Std  float64 // Sample standard deviation across seeds
+	Low  float64 // 95% confidence interval of the mean, Student's t; the mean itself with fewer than two seeds
+	High float64
+}
+
+// SeedVariance is one config's results on a suite across independently
+// seeded training runs. A single seed says little about a config: seeds of
+// the same config often differ more than configs do
+type SeedVariance struct {
+	Name        string
+	Results     []Result // One per seed
+	Reward      Estimate // Of each seed's average reward
+	SuccessRate Estimate
+}
+
+// SummarizeSeeds estimates a config's reward and success rate from the
+// results of its seeds
+func SummarizeSeeds(name string, results []Result) SeedVariance {
+	rewards := make([]float64, len(results))
+	successRates := make([]float64, len(results))
+	for i, r := range results {
+		rewards[i] = r.AvgReward
+		successRates[i] = r.SuccessRate
+	}
+	return SeedVariance{
+		Name:        name,
+		Results:     results,
+		Reward:      estimate(rewards),
+		SuccessRate: estimate(successRates),
+	}
+}
+
+// estimate returns the mean of values with a Student's t confidence interval
+func estimate(values []float64) Estimate {
+	n := float64(len(values))
+	if n == 0 {
+		return Estimate{}
+	}
+	var e Estimate
+	for _, v := range values {
+		e.Mean += v
+	}
+	e.Mean /= n
+	e.Low, e.High = e.Mean, e.Mean
+	if n < 2 {
+		return e
+	}
+	for _, v := range values {
+		e.Std += (v - e.Mean) * (v - e.Mean)
+	}
+	e.Std = math.Sqrt(e.Std / (n - 1))
+	margin := tQuantile(0.975, n-1) * e.Std / math.Sqrt(n)
+	e.Low, e.High = e.Mean-margin, e.Mean+margin
+	return e
+}
+
+// Difference is how far a candidate's mean lies from a baseline's, and
+// whether seed noise alone plausibly explains it
+type Difference struct {
+	Delta       float64 // Candidate mean minus baseline mean
+	PValue      float64 // Two-sided Welch's t-test
+	Significant bool    // PValue below SignificanceLevel
+}
+
+// SeedComparison compares two configs across their seeds
+type SeedComparison struct {
+	Baseline    string
+	Candidate   string
+	Reward      Difference
+	SuccessRate Difference
+}
+
+// CompareSeeds tests whether candidate's reward and success rate differ
+// from baseline's by more than their seeds vary. Configs with fewer than
+// two seeds each are never significantly different
+func CompareSeeds(baseline, candidate SeedVariance) SeedComparison {
+	return SeedComparison{
+		Baseline:    baseline.Name,
+		Candidate:   candidate.Name,
+		Reward:      welch(baseline.Reward, candidate.Reward, len(baseline.Results), len(candidate.Results)),
+		SuccessRate: welch(baseline.SuccessRate, candidate.SuccessRate, len(baseline.Results), len(candidate.Results)),
+	}
+}
+
+// welch runs Welch's unequal variances t-test on two estimates from na and
+// nb seeds
+func welch(a, b Estimate, na, nb int) Difference {
+	d := Difference{Delta: b.Mean - a.Mean, PValue: 1}
+	if na < 2 || nb < 2 {
+		return d
+	}
+	va, vb := a.Std*a.Std/float64(na), b.Std*b.Std/float64(nb)
+	if va+vb == 0 {
+		// Every seed agreed, so any difference at all is real
+		if d.Delta != 0 {
+			d.PValue = 0
+		}
+	} else {
+		t := d.Delta / math.Sqrt(va+vb)
+		df := (va + vb) * (va + vb) / (va*va/float64(na-1) + vb*vb/float64(nb-1))
+		d.PValue = 2 * (1 - tCDF(math.Abs(t), df))
+	}
+	d.Significant = d.PValue < SignificanceLevel
+	return d
+}
+
+// tCDF is the cumulative distribution function of Student's t with df
+// degrees of freedom
+func tCDF(t, df float64) float64 {
+	tail := 0.5 * incompleteBeta(df/2, 0.5, df/(df+t*t))
+	if t < 0 {
+		return tail
+	}
+	return 1 - tail
+}
+
+// tQuantile inverts tCDF by bisection, for p in (0, 1)
+func tQuantile(p, df float64) float64 {
+	low, high := -1e3, 1e3
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if tCDF(mid, df) < p {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// incompleteBeta is the regularized incomplete beta function I_x(a, b),
+// evaluated by its continued fraction
+func incompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	// The continued fraction converges quickly only below the mean
+	if x > (a+1)/(a+b+2) {
+		return 1 - incompleteBeta(b, a, 1-x)
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab-la-lb+a*math.Log(x)+b*math.Log(1-x)) / a
+
+	// Lentz's method
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	f := d
+	for m := 1; m <= 200; m++ {
+		fm := float64(m)
+		for _, numerator := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + numerator*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + numerator/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			f *= c * d
+		}
+		if math.Abs(c*d-1) < 1e-12 {
+			break
+		}
+	}
+	return front * f
+}
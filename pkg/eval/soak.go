@@ -0,0 +1,207 @@
+package eval
+
+import (
+	"io"
+	"log"
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// SoakConfig sets up a soak test: one episode far longer than any suite
+// scenario, to check a controller keeps balancing rather than slowly
+// drifting, creeping along the track or degrading
+type SoakConfig struct {
+	Config       env.Config
+	Initial      env.State
+	Steps        int     // Control steps to run, e.g. 180000 for an hour at 50Hz
+	WindowSteps  int     // Steps per window of the trend analysis
+	SuccessAngle float64 // Deviation from upright in radians counted as a wobble
+	FailAngle    float64 // Deviation from upright in radians that ends the test as a fall, unless Config.Termination sets one (0 = never)
+}
+
+// NewSoakConfig returns a soak test of duration simulated seconds from
+// near upright, analyzed in one minute windows
+func NewSoakConfig(config env.Config, duration float64) SoakConfig {
+	return SoakConfig{
+		Config:       config,
+		Initial:      env.State{AngleRadians: 0.05},
+		Steps:        int(duration / config.DeltaTime),
+		WindowSteps:  max(1, int(60/config.DeltaTime)),
+		SuccessAngle: StandardSuite().SuccessAngle,
+		FailAngle:    math.Pi / 2,
+	}
+}
+
+// Excursion is the worst value a quantity reached and when
+type Excursion struct {
+	Value float64
+	Step  int
+}
+
+// update keeps value if it is the worst so far
+func (e *Excursion) update(value float64, step int) {
+	if value > e.Value {
+		e.Value, e.Step = value, step
+	}
+}
+
+// SoakWindow summarizes one window of a soak test
+type SoakWindow struct {
+	Start         int     // First step of the window
+	MeanDeviation float64 // Mean deviation from upright in radians
+	MaxDeviation  float64
+	MeanPosition  float64 // Mean cart position, signed
+	MaxOffset     float64 // Largest cart distance from center
+}
+
+// SoakReport is the stability report of a soak test. A controller that
+// balances indefinitely completes every step with flat trends
+type SoakReport struct {
+	Steps         int     // Steps completed
+	SimulatedTime float64 // Seconds simulated
+	Failure       string  // Why the test ended early: a termination reason or "non_finite_state"; empty when it completed
+	FailureStep   int
+
+	// Worst-case excursions
+	MaxDeviation    Excursion // From upright, in radians
+	MaxOffset       Excursion // Cart distance from center
+	MaxCartVelocity Excursion
+	MaxAngularVel   Excursion
+	MaxEnergyError  Excursion // Energy not accounted for by the control force's work, in J; only without friction, damping or disturbances
+	EnergyChecked   bool      // Whether MaxEnergyError was tracked
+
+	WobbleSteps int // Steps beyond SuccessAngle
+
+	// Trends, fit over the window means as change per simulated hour
+	Windows        []SoakWindow
+	DeviationTrend float64 // Radians per hour; positive means balance is degrading
+	CreepTrend     float64 // Meters per hour the cart's mean position moves
+}
+
+// Soak runs the controller for the configured steps and reports its worst
+// excursions and trends. The test ends early only when the pendulum
+// terminates, falls past FailAngle or its state stops being finite
+func Soak(config SoakConfig, controller Controller) SoakReport {
+	if c, ok := controller.(historyController); ok {
+		c.ResetHistory()
+	}
+	physics := config.Config
+	if physics.Termination.MaxAngle == 0 {
+		physics.Termination.MaxAngle = config.FailAngle
+	}
+	pendulum := env.NewPendulum(physics, log.New(io.Discard, "", 0))
+	pendulum.Reset(config.Initial)
+
+	var report SoakReport
+	report.EnergyChecked = physics.CartFriction == 0 && physics.AngularDamping == 0 &&
+		physics.ImpulseProb == 0 && physics.WindForce == 0 && physics.WindNoise == 0
+	windowSteps := max(1, config.WindowSteps)
+
+	state := pendulum.GetState()
+	truth := state
+	energy := env.Energy(truth, physics).Total
+	var window SoakWindow
+	windowCount := 0
+	for i := 0; i < config.Steps; i++ {
+		force := controller.Act(state)
+		next, done, _ := pendulum.Advance(force)
+		previous := truth
+		truth = pendulum.GetState()
+		if !finite(truth) {
+			report.Failure, report.FailureStep = "non_finite_state", i
+			break
+		}
+		state = next
+		report.Steps++
+
+		deviation := Deviation(truth.AngleRadians)
+		report.MaxDeviation.update(deviation, i)
+		report.MaxOffset.update(math.Abs(truth.CartPosition), i)
+		report.MaxCartVelocity.update(math.Abs(truth.CartVelocity), i)
+		report.MaxAngularVel.update(math.Abs(truth.AngularVel), i)
+		if deviation > config.SuccessAngle {
+			report.WobbleSteps++
+		}
+		if report.EnergyChecked {
+			applied := math.Max(-physics.MaxForce, math.Min(pendulum.GetLastForce(), physics.MaxForce))
+			energy += applied * (truth.CartPosition - previous.CartPosition)
+			report.MaxEnergyError.update(math.Abs(env.Energy(truth, physics).Total-energy), i)
+		}
+
+		if windowCount == 0 {
+			window = SoakWindow{Start: i}
+		}
+		windowCount++
+		window.MeanDeviation += deviation
+		window.MaxDeviation = math.Max(window.MaxDeviation, deviation)
+		window.MeanPosition += truth.CartPosition
+		window.MaxOffset = math.Max(window.MaxOffset, math.Abs(truth.CartPosition))
+		if windowCount == windowSteps {
+			report.Windows = append(report.Windows, closeWindow(window, windowCount))
+			windowCount = 0
+		}
+
+		if done {
+			report.Failure, report.FailureStep = string(pendulum.GetTermination()), i
+			break
+		}
+	}
+	if windowCount > 0 {
+		report.Windows = append(report.Windows, closeWindow(window, windowCount))
+	}
+
+	report.SimulatedTime = float64(report.Steps) * physics.DeltaTime
+	hours := make([]float64, len(report.Windows))
+	deviations := make([]float64, len(report.Windows))
+	positions := make([]float64, len(report.Windows))
+	for i, w := range report.Windows {
+		hours[i] = float64(w.Start) * physics.DeltaTime / 3600
+		deviations[i] = w.MeanDeviation
+		positions[i] = w.MeanPosition
+	}
+	report.DeviationTrend = slope(hours, deviations)
+	report.CreepTrend = slope(hours, positions)
+	return report
+}
+
+// closeWindow turns a window's sums into means
+func closeWindow(w SoakWindow, steps int) SoakWindow {
+	w.MeanDeviation /= float64(steps)
+	w.MeanPosition /= float64(steps)
+	return w
+}
+
+// finite reports whether every field of the state is a number
+func finite(s env.State) bool {
+	for _, v := range []float64{s.CartPosition, s.CartVelocity, s.AngleRadians, s.AngularVel} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// slope is the least squares slope of y over x, 0 with fewer than two points
+func slope(x, y []float64) float64 {
+	n := float64(len(x))
+	if n < 2 {
+		return 0
+	}
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+	var cov, varX float64
+	for i := range x {
+		cov += (x[i] - meanX) * (y[i] - meanY)
+		varX += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if varX == 0 {
+		return 0
+	}
+	return cov / varX
+}
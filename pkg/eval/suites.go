@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// Fixed starting deviations from upright shared by the standard suites
+var standardAngles = []float64{0.05, -0.05, 0.1, -0.1, 0.2, -0.2, 0.3, -0.3, 0.5, -0.5}
+
+// StandardSuite starts the pendulum at fixed tilts from upright with no
+// disturbances, for 500 steps (10 seconds at 50Hz) each
+func StandardSuite() Suite {
+	suite := Suite{Name: "standard", SuccessAngle: math.Pi / 4}
+	for i, angle := range standardAngles {
+		suite.Scenarios = append(suite.Scenarios, Scenario{
+			Name:    scenarioName("tilt", i),
+			Config:  env.NewDefaultConfig(),
+			Initial: env.State{AngleRadians: angle},
+			Steps:   500,
+		})
+	}
+	return suite
+}
+
+// RobustnessSuite repeats the standard tilts with seeded impulses, wind
+// and sensor noise
+func RobustnessSuite() Suite {
+	suite := Suite{Name: "robustness", SuccessAngle: math.Pi / 4}
+	for i, angle := range standardAngles {
+		config := env.NewDefaultConfig()
+		config.ImpulseProb = 0.02
+		config.ImpulseForce = 5.0
+		config.WindNoise = 0.5
+		config.SensorNoise = 0.01
+		config.Seed = int64(i + 1)
+
+		suite.Scenarios = append(suite.Scenarios, Scenario{
+			Name:    scenarioName("disturbed", i),
+			Config:  config,
+			Initial: env.State{AngleRadians: angle},
+			Steps:   500,
+		})
+	}
+	return suite
+}
+
+// Suites returns the named standard suites
+func Suites() map[string]Suite {
+	return map[string]Suite{
+		"standard":   StandardSuite(),
+		"robustness": RobustnessSuite(),
+	}
+}
+
+// WithPhysics returns a copy of the suite whose scenarios run on the given
+// physical parameters, e.g. a pendulum preset. Each scenario keeps its own
+// disturbances and seed so results stay comparable across presets
+func (s Suite) WithPhysics(config env.Config) Suite {
+	scenarios := make([]Scenario, len(s.Scenarios))
+	for i, scenario := range s.Scenarios {
+		physics := config
+		physics.ImpulseProb = scenario.Config.ImpulseProb
+		physics.ImpulseForce = scenario.Config.ImpulseForce
+		physics.WindForce = scenario.Config.WindForce
+		physics.WindNoise = scenario.Config.WindNoise
+		physics.SensorNoise = scenario.Config.SensorNoise
+		physics.Seed = scenario.Config.Seed
+		scenario.Config = physics
+		scenarios[i] = scenario
+	}
+	s.Scenarios = scenarios
+	return s
+}
+
+// scenarioName builds a stable scenario name like "tilt_03"
+func scenarioName(prefix string, i int) string {
+	return fmt.Sprintf("%s_%02d", prefix, i)
+}
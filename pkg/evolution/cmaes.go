@@ -0,0 +1,482 @@
+// Package evolution optimizes a network's weights directly against episodic
+// fitness, without gradients
+package evolution
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Fitness scores a parameter vector; higher is better
+type Fitness func(params []float64) float64
+
+// Config controls a CMA-ES search and its restarts
+type Config struct {
+	Sigma          float64 // Initial step size: the std of the first samples around the start
+	PopulationSize int     // Candidates per generation (0 = 4 + 3·ln(dimensions))
+	Generations    int     // Generations across all restarts
+	Restarts       int     // Searches started over, each with twice the population, after one stagnates
+	TolFun         float64 // Restart once best fitness varies less than this over the last stagnation window
+	TolSigma       float64 // Restart once the std along every axis falls below this
+	MaxCondition   float64 // Restart once the covariance's condition number exceeds this
+	Seed           int64   // Seed for sampling, so searches are reproducible
+}
+
+// NewDefaultConfig returns a search of 200 generations with up to 2 restarts
+func NewDefaultConfig() Config {
+	return Config{
+		Sigma:        0.5,
+		Generations:  200,
+		Restarts:     2,
+		TolFun:       1e-9,
+		TolSigma:     1e-8,
+		MaxCondition: 1e14,
+		Seed:         1,
+	}
+}
+
+// Generation summarizes one generation, including the shape of the
+// sampling distribution the next one draws from
+type Generation struct {
+	Restart     int     // Which search this generation belongs to, 0 for the first
+	Generation  int     // Generations run so far across all searches
+	Evaluations int     // Fitness evaluations so far across all searches
+	Population  int     // Candidates sampled this generation
+	BestFitness float64 // Best fitness this generation
+	MeanFitness float64
+	Sigma       float64 // Step size
+	MinStd      float64 // Smallest std along a coordinate axis, σ·√min(Cii)
+	MaxStd      float64 // Largest std along a coordinate axis, σ·√max(Cii)
+	AxisRatio   float64 // Longest over shortest principal axis of the covariance, √cond(C)
+}
+
+// Result is the outcome of Optimize
+type Result struct {
+	Best        []float64 // Best parameters evaluated in any search
+	BestFitness float64
+	Evaluations int
+	Restarts    int // Searches started over after stagnating
+	History     []Generation
+}
+
+// CMAES is one covariance matrix adaptation evolution strategy search. It
+// samples each generation from a multivariate normal distribution and
+// moves its mean toward the fitter candidates, while the covariance learns
+// the correlations between parameters and the step size adapts to how far
+// consecutive steps travel
+type CMAES struct {
+	config Config
+	rng    *rand.Rand
+	n      int
+	lambda int
+	mu     int
+
+	// Strategy constants, from the population size and dimensions
+	weights []float64
+	mueff   float64
+	cc      float64
+	cs      float64
+	c1      float64
+	cmu     float64
+	damps   float64
+	chiN    float64
+
+	// Distribution state
+	mean  []float64
+	sigma float64
+	pc    []float64   // Evolution path of the covariance
+	ps    []float64   // Conjugate evolution path of the step size
+	c     [][]float64 // Covariance matrix
+	b     [][]float64 // Eigenvectors of c, as columns
+	d     []float64   // Square roots of the eigenvalues of c
+
+	generation int
+	recentBest []float64 // Best fitness of the latest generations, oldest first
+}
+
+// New starts a search around initial with the configured population, or
+// the default one for its dimensions
+func New(config Config, initial []float64) (*CMAES, error) {
+	n := len(initial)
+	if n == 0 {
+		return nil, fmt.Errorf("cma-es needs at least one parameter")
+	}
+	if config.Sigma <= 0 {
+		return nil, fmt.Errorf("cma-es step size must be positive, got %g", config.Sigma)
+	}
+	lambda := config.PopulationSize
+	if lambda == 0 {
+		lambda = 4 + int(3*math.Log(float64(n)))
+	}
+	if lambda < 2 {
+		return nil, fmt.Errorf("cma-es needs a population of at least 2, got %d", lambda)
+	}
+
+	c := &CMAES{
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)),
+		n:      n,
+		lambda: lambda,
+		mu:     lambda / 2,
+		mean:   append([]float64(nil), initial...),
+		sigma:  config.Sigma,
+		pc:     make([]float64, n),
+		ps:     make([]float64, n),
+		d:      make([]float64, n),
+	}
+
+	// Log-linearly decreasing recombination weights of the best half
+	c.weights = make([]float64, c.mu)
+	sum := 0.0
+	for i := range c.weights {
+		c.weights[i] = math.Log(float64(c.mu)+0.5) - math.Log(float64(i+1))
+		sum += c.weights[i]
+	}
+	sumSq := 0.0
+	for i := range c.weights {
+		c.weights[i] /= sum
+		sumSq += c.weights[i] * c.weights[i]
+	}
+	c.mueff = 1 / sumSq
+
+	fn := float64(n)
+	c.cc = (4 + c.mueff/fn) / (fn + 4 + 2*c.mueff/fn)
+	c.cs = (c.mueff + 2) / (fn + c.mueff + 5)
+	c.c1 = 2 / ((fn+1.3)*(fn+1.3) + c.mueff)
+	c.cmu = math.Min(1-c.c1, 2*(c.mueff-2+1/c.mueff)/((fn+2)*(fn+2)+c.mueff))
+	c.damps = 1 + 2*math.Max(0, math.Sqrt((c.mueff-1)/(fn+1))-1) + c.cs
+	c.chiN = math.Sqrt(fn) * (1 - 1/(4*fn) + 1/(21*fn*fn))
+
+	c.c = identity(n)
+	c.b = identity(n)
+	for i := range c.d {
+		c.d[i] = 1
+	}
+	return c, nil
+}
+
+// PopulationSize returns the number of candidates each Ask samples
+func (c *CMAES) PopulationSize() int {
+	return c.lambda
+}
+
+// Mean returns the center of the sampling distribution, the search's
+// current estimate of the optimum
+func (c *CMAES) Mean() []float64 {
+	return append([]float64(nil), c.mean...)
+}
+
+// Sigma returns the current step size
+func (c *CMAES) Sigma() float64 {
+	return c.sigma
+}
+
+// Ask samples a generation of candidates
+func (c *CMAES) Ask() [][]float64 {
+	candidates := make([][]float64, c.lambda)
+	z := make([]float64, c.n)
+	for k := range candidates {
+		for i := range z {
+			z[i] = c.d[i] * c.rng.NormFloat64()
+		}
+		x := make([]float64, c.n)
+		for i := range x {
+			y := 0.0
+			for j := range z {
+				y += c.b[i][j] * z[j]
+			}
+			x[i] = c.mean[i] + c.sigma*y
+		}
+		candidates[k] = x
+	}
+	return candidates
+}
+
+// Tell updates the distribution from the fitness of the candidates of the
+// last Ask and returns a summary of the generation
+func (c *CMAES) Tell(candidates [][]float64, fitness []float64) (Generation, error) {
+	if len(candidates) != c.lambda || len(fitness) != c.lambda {
+		return Generation{}, fmt.Errorf("expected %d candidates and fitness values, got %d and %d", c.lambda, len(candidates), len(fitness))
+	}
+	order := make([]int, c.lambda)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return fitness[order[i]] > fitness[order[j]] })
+	c.generation++
+
+	// Steps of the best half from the old mean, in units of σ
+	old := c.mean
+	steps := make([][]float64, c.mu)
+	c.mean = make([]float64, c.n)
+	yw := make([]float64, c.n)
+	for k := range steps {
+		x := candidates[order[k]]
+		steps[k] = make([]float64, c.n)
+		for i := range x {
+			steps[k][i] = (x[i] - old[i]) / c.sigma
+			c.mean[i] += c.weights[k] * x[i]
+			yw[i] += c.weights[k] * steps[k][i]
+		}
+	}
+
+	// Step size path, in the isotropic coordinates C^-1/2 · yw
+	inv := c.invSqrt(yw)
+	psScale := math.Sqrt(c.cs * (2 - c.cs) * c.mueff)
+	for i := range c.ps {
+		c.ps[i] = (1-c.cs)*c.ps[i] + psScale*inv[i]
+	}
+	psNorm := norm(c.ps)
+	hsig := 0.0
+	if psNorm/math.Sqrt(1-math.Pow(1-c.cs, 2*float64(c.generation)))/c.chiN < 1.4+2/float64(c.n+1) {
+		hsig = 1
+	}
+	pcScale := hsig * math.Sqrt(c.cc*(2-c.cc)*c.mueff)
+	for i := range c.pc {
+		c.pc[i] = (1-c.cc)*c.pc[i] + pcScale*yw[i]
+	}
+
+	// Rank-one update from the path plus rank-μ update from this generation
+	keep := 1 - c.c1 - c.cmu + (1-hsig)*c.c1*c.cc*(2-c.cc)
+	for i := 0; i < c.n; i++ {
+		for j := 0; j <= i; j++ {
+			rankMu := 0.0
+			for k, y := range steps {
+				rankMu += c.weights[k] * y[i] * y[j]
+			}
+			v := keep*c.c[i][j] + c.c1*c.pc[i]*c.pc[j] + c.cmu*rankMu
+			c.c[i][j], c.c[j][i] = v, v
+		}
+	}
+	c.sigma *= math.Exp(c.cs / c.damps * (psNorm/c.chiN - 1))
+	c.b, c.d = eigen(c.c)
+	for i, v := range c.d {
+		c.d[i] = math.Sqrt(math.Max(v, 0))
+	}
+
+	best := fitness[order[0]]
+	c.recentBest = append(c.recentBest, best)
+	if window := c.stagnationWindow(); len(c.recentBest) > window {
+		c.recentBest = c.recentBest[len(c.recentBest)-window:]
+	}
+	mean := 0.0
+	for _, f := range fitness {
+		mean += f
+	}
+	minVar, maxVar := math.Inf(1), 0.0
+	for i := range c.c {
+		minVar = math.Min(minVar, c.c[i][i])
+		maxVar = math.Max(maxVar, c.c[i][i])
+	}
+	return Generation{
+		Generation:  c.generation,
+		Population:  c.lambda,
+		BestFitness: best,
+		MeanFitness: mean / float64(c.lambda),
+		Sigma:       c.sigma,
+		MinStd:      c.sigma * math.Sqrt(minVar),
+		MaxStd:      c.sigma * math.Sqrt(maxVar),
+		AxisRatio:   c.axisRatio(),
+	}, nil
+}
+
+// Stagnated reports why the search should start over, or "" if it is
+// still making progress
+func (c *CMAES) Stagnated() string {
+	if c.generation >= c.stagnationWindow() {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, f := range c.recentBest {
+			lo, hi = math.Min(lo, f), math.Max(hi, f)
+		}
+		if hi-lo < c.config.TolFun {
+			return fmt.Sprintf("best fitness varied %.3g over %d generations", hi-lo, len(c.recentBest))
+		}
+	}
+	maxStd := 0.0
+	for i := range c.c {
+		maxStd = math.Max(maxStd, c.sigma*math.Sqrt(c.c[i][i]))
+	}
+	if maxStd < c.config.TolSigma {
+		return fmt.Sprintf("std %.3g along every axis", maxStd)
+	}
+	if ratio := c.axisRatio(); c.config.MaxCondition > 0 && ratio*ratio > c.config.MaxCondition {
+		return fmt.Sprintf("covariance condition number %.3g", ratio*ratio)
+	}
+	return ""
+}
+
+// stagnationWindow is how many generations of best fitness Stagnated
+// compares, longer for larger problems and smaller populations
+func (c *CMAES) stagnationWindow() int {
+	return 10 + int(math.Ceil(30*float64(c.n)/float64(c.lambda)))
+}
+
+// axisRatio returns the longest over the shortest principal axis
+func (c *CMAES) axisRatio() float64 {
+	lo, hi := math.Inf(1), 0.0
+	for _, v := range c.d {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == 0 {
+		return math.Inf(1)
+	}
+	return hi / lo
+}
+
+// invSqrt returns C^-1/2 · v = B · D^-1 · Bᵀ · v
+func (c *CMAES) invSqrt(v []float64) []float64 {
+	scaled := make([]float64, c.n)
+	for j := range scaled {
+		dot := 0.0
+		for i := range v {
+			dot += c.b[i][j] * v[i]
+		}
+		if c.d[j] > 0 {
+			scaled[j] = dot / c.d[j]
+		}
+	}
+	out := make([]float64, c.n)
+	for i := range out {
+		for j := range scaled {
+			out[i] += c.b[i][j] * scaled[j]
+		}
+	}
+	return out
+}
+
+// Optimize maximizes fitness with CMA-ES starting from initial. A search
+// that stagnates starts over from initial with twice the population (IPOP),
+// up to config.Restarts times, trading speed for a wider look at the
+// landscape. Each generation's step size and covariance shape are logged
+// and kept in the result's history. A nil logger discards them
+func Optimize(config Config, initial []float64, fitness Fitness, logger *log.Logger) (Result, error) {
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	search, err := New(config, initial)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{BestFitness: math.Inf(-1)}
+	for generation := 0; generation < config.Generations; generation++ {
+		candidates := search.Ask()
+		scores := make([]float64, len(candidates))
+		for i, x := range candidates {
+			scores[i] = fitness(x)
+			if scores[i] > result.BestFitness {
+				result.BestFitness = scores[i]
+				result.Best = append([]float64(nil), x...)
+			}
+		}
+		result.Evaluations += len(candidates)
+
+		stats, err := search.Tell(candidates, scores)
+		if err != nil {
+			return result, err
+		}
+		stats.Restart = result.Restarts
+		stats.Generation = generation + 1
+		stats.Evaluations = result.Evaluations
+		result.History = append(result.History, stats)
+		logger.Printf("Generation %d (restart %d, λ=%d): best %.4f, mean %.4f, σ %.4g, axis std %.3g-%.3g, axis ratio %.3g",
+			stats.Generation, stats.Restart, stats.Population, stats.BestFitness, stats.MeanFitness,
+			stats.Sigma, stats.MinStd, stats.MaxStd, stats.AxisRatio)
+
+		reason := search.Stagnated()
+		if reason == "" || generation+1 == config.Generations {
+			continue
+		}
+		if result.Restarts == config.Restarts {
+			logger.Printf("Stopping after %d generations: %s", generation+1, reason)
+			break
+		}
+		result.Restarts++
+		restart := config
+		restart.PopulationSize = 2 * search.PopulationSize()
+		restart.Seed = config.Seed + int64(result.Restarts)
+		if search, err = New(restart, initial); err != nil {
+			return result, err
+		}
+		logger.Printf("Restart %d with population %d: %s", result.Restarts, restart.PopulationSize, reason)
+	}
+	return result, nil
+}
+
+// eigen returns the eigenvectors, as columns, and eigenvalues of the
+// symmetric matrix a by cyclic Jacobi rotations
+func eigen(a [][]float64) ([][]float64, []float64) {
+	n := len(a)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = append([]float64(nil), a[i]...)
+	}
+	v := identity(n)
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += m[i][j] * m[i][j]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				cos := 1 / math.Sqrt(t*t+1)
+				sin := t * cos
+				for k := 0; k < n; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p] = cos*mkp - sin*mkq
+					m[k][q] = sin*mkp + cos*mkq
+				}
+				for k := 0; k < n; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k] = cos*mpk - sin*mqk
+					m[q][k] = sin*mpk + cos*mqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = cos*vkp - sin*vkq
+					v[k][q] = sin*vkp + cos*vkq
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	return v, values
+}
+
+// identity returns the n×n identity matrix
+func identity(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		m[i][i] = 1
+	}
+	return m
+}
+
+// norm returns the Euclidean length of v
+func norm(v []float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,132 @@
+package evolution
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+func TestEigen(t *testing.T) {
+	a := [][]float64{{4, 1, 0.5}, {1, 3, 0.2}, {0.5, 0.2, 1}}
+	vectors, values := eigen(a)
+	for j, lambda := range values {
+		for i := range a {
+			av := 0.0
+			for k := range a {
+				av += a[i][k] * vectors[k][j]
+			}
+			if math.Abs(av-lambda*vectors[i][j]) > 1e-9 {
+				t.Fatalf("column %d is not an eigenvector of eigenvalue %v", j, lambda)
+			}
+		}
+	}
+	if trace := values[0] + values[1] + values[2]; math.Abs(trace-8) > 1e-9 {
+		t.Errorf("eigenvalues sum to %v, want the trace 8", trace)
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	t.Run("finds the optimum of a rotated ellipsoid", func(t *testing.T) {
+		// Strongly correlated parameters, which naive mutation handles poorly
+		target := []float64{1, -2, 0.5, 3, -1}
+		fitness := func(x []float64) float64 {
+			sum := 0.0
+			for i := range x {
+				d := x[i] - target[i]
+				if i > 0 {
+					d += x[i-1] - target[i-1]
+				}
+				sum += math.Pow(10, float64(i)) * d * d
+			}
+			return -sum
+		}
+
+		config := NewDefaultConfig()
+		config.Generations = 400
+		result, err := Optimize(config, make([]float64, len(target)), fitness, nil)
+		if err != nil {
+			t.Fatalf("Optimize: %v", err)
+		}
+		for i := range target {
+			if math.Abs(result.Best[i]-target[i]) > 1e-3 {
+				t.Fatalf("best %v, want %v", result.Best, target)
+			}
+		}
+		if len(result.History) == 0 || result.History[len(result.History)-1].Sigma >= config.Sigma {
+			t.Errorf("step size did not shrink toward the optimum")
+		}
+	})
+
+	t.Run("restarts with a larger population when stuck", func(t *testing.T) {
+		var out bytes.Buffer
+		config := NewDefaultConfig()
+		config.Generations = 500
+		config.Restarts = 2
+		result, err := Optimize(config, []float64{0, 0, 0}, func([]float64) float64 { return 1 }, log.New(&out, "", 0))
+		if err != nil {
+			t.Fatalf("Optimize: %v", err)
+		}
+		if result.Restarts != 2 {
+			t.Fatalf("restarts = %d, want 2", result.Restarts)
+		}
+		first, last := result.History[0], result.History[len(result.History)-1]
+		if last.Restart != 2 || last.Population != 4*first.Population {
+			t.Errorf("last generation was restart %d with population %d, want 2 and %d", last.Restart, last.Population, 4*first.Population)
+		}
+		if len(result.History) == config.Generations {
+			t.Errorf("search ran all %d generations instead of stopping", config.Generations)
+		}
+		if !strings.Contains(out.String(), "Restart 1") || !strings.Contains(out.String(), "σ") {
+			t.Errorf("log missing restarts or step sizes:\n%s", out.String())
+		}
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		for _, config := range []Config{{Sigma: 0}, {Sigma: 1, PopulationSize: 1}} {
+			if _, err := New(config, []float64{0}); err == nil {
+				t.Errorf("New(%+v) succeeded, want error", config)
+			}
+		}
+		if _, err := New(NewDefaultConfig(), nil); err == nil {
+			t.Errorf("New with no parameters succeeded, want error")
+		}
+	})
+}
+
+func TestNetworkFitness(t *testing.T) {
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(&bytes.Buffer{}, "", 0))
+	network.SetEvalMode(true)
+	network.SetObservationTransformer(neural.NewObservationTransformer(neural.FeatureConfig{SinCos: true}))
+
+	params := NetworkParams(network)
+	if len(params) != 5 {
+		t.Fatalf("got %d parameters, want 3 weights and 2 feature weights", len(params))
+	}
+	suite := eval.StandardSuite()
+	suite.Scenarios = suite.Scenarios[:4]
+
+	config := NewDefaultConfig()
+	config.Generations = 15
+	result, err := Optimize(config, params, NetworkFitness(network, suite), nil)
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if start := NetworkFitness(network, suite)(params); result.BestFitness <= start {
+		t.Errorf("best fitness %v did not improve on the start's %v", result.BestFitness, start)
+	}
+	if err := SetNetworkParams(network, result.Best); err != nil {
+		t.Fatalf("SetNetworkParams: %v", err)
+	}
+	if got := eval.Run(suite, network).AvgReward; got != result.BestFitness {
+		t.Errorf("best parameters score %v when loaded, want %v", got, result.BestFitness)
+	}
+	if err := SetNetworkParams(network, []float64{1, 2}); err == nil {
+		t.Errorf("SetNetworkParams accepted too few parameters")
+	}
+}
@@ -0,0 +1,378 @@
+package evolution
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+// GenomeVersion is the version of the genome text format written by String
+const GenomeVersion = 1
+
+// GenomeExtension is the conventional file extension of genomes
+const GenomeExtension = ".genome"
+
+// Genome is everything needed to rebuild an evolved controller: its
+// topology (input features, recurrent cell and action space), its weights,
+// and how it was found. Its text form is small and line based, so
+// controllers can be shared as gists and read by eye:
+//
+//	# inverted pendulum genome
+//	version 1
+//	preset classic
+//	generation 12
+//	fitness 0.4178
+//	features sincos
+//	actions continuous max=5
+//	inputs angle angular_vel sin_angle cos_angle
+//	params -30.2613 -24.4863 1.1195 -55.3359 1.3107
+type Genome struct {
+	Preset     string  // Pendulum preset the controller evolved on, if known
+	Generation int     // Generation or search step that produced it
+	Fitness    float64 // Fitness it was selected with, in whatever units produced it
+
+	Features  neural.FeatureConfig
+	Actions   neural.ActionSpace
+	Params    []float64 // Weights laid out like NetworkParams
+	Recurrent []float64 // Recurrent cell weights, nil without a cell
+
+	// Running input statistics of normalized features, nil otherwise
+	Normalization *neural.ObservationState
+}
+
+// NewGenome captures the network's topology and weights
+func NewGenome(network *neural.Network, fitness float64, generation int) Genome {
+	g := Genome{
+		Preset:     network.GetPreset(),
+		Generation: generation,
+		Fitness:    fitness,
+		Actions:    network.GetActionSpace(),
+		Params:     NetworkParams(network),
+		Recurrent:  network.GetRecurrentWeights(),
+	}
+	if observer := network.GetObservationTransformer(); observer != nil {
+		g.Features = observer.Features()
+		if g.Features.Normalize {
+			state := observer.State()
+			g.Normalization = &state
+		}
+	}
+	return g
+}
+
+// Inputs names the network inputs the genome's features produce
+func (g Genome) Inputs() []string {
+	if g.Features == (neural.FeatureConfig{}) {
+		return []string{"angle", "angular_vel"}
+	}
+	return neural.NewObservationTransformer(g.Features).Names()
+}
+
+// NewNetwork builds a network with the genome's topology and weights, in
+// evaluation mode, e.g. to visualize it or save it as a checkpoint
+func (g Genome) NewNetwork(logger *log.Logger) (*neural.Network, error) {
+	network := neural.NewNetwork()
+	if logger != nil {
+		network.SetLogger(logger)
+	}
+	network.SetEvalMode(true)
+	network.SetPreset(g.Preset)
+	network.SetActionSpace(g.Actions)
+	if g.Features != (neural.FeatureConfig{}) {
+		observer := neural.NewObservationTransformer(g.Features)
+		if g.Normalization != nil {
+			restored, err := neural.RestoreObservationTransformer(*g.Normalization)
+			if err != nil {
+				return nil, fmt.Errorf("invalid genome normalization: %w", err)
+			}
+			observer = restored
+		}
+		network.SetObservationTransformer(observer)
+	}
+	if g.Recurrent != nil {
+		network.EnableRecurrent()
+	}
+	if err := g.ApplyWeights(network); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// ApplyWeights copies the genome's weights into a network of the same
+// topology, e.g. to seed a member of a new population. The network keeps
+// its optimizer, schedule and other training state
+func (g Genome) ApplyWeights(network *neural.Network) error {
+	if inputs := network.InputNames(); !slices.Equal(inputs, g.Inputs()) {
+		return fmt.Errorf("genome inputs %v do not match the network's %v", g.Inputs(), inputs)
+	}
+	if network.Recurrent() != (g.Recurrent != nil) {
+		return fmt.Errorf("genome and network disagree on having a recurrent cell")
+	}
+	if err := SetNetworkParams(network, g.Params); err != nil {
+		return fmt.Errorf("invalid genome weights: %w", err)
+	}
+	if g.Recurrent != nil {
+		if err := network.SetRecurrentWeights(g.Recurrent); err != nil {
+			return fmt.Errorf("invalid genome recurrent weights: %w", err)
+		}
+	}
+	return nil
+}
+
+// String writes the genome in its text format. Numbers are written
+// exactly, so parsing the text rebuilds an identical controller
+func (g Genome) String() string {
+	var b strings.Builder
+	b.WriteString("# inverted pendulum genome\n")
+	fmt.Fprintf(&b, "version %d\n", GenomeVersion)
+	if g.Preset != "" {
+		fmt.Fprintf(&b, "preset %s\n", g.Preset)
+	}
+	fmt.Fprintf(&b, "generation %d\n", g.Generation)
+	fmt.Fprintf(&b, "fitness %s\n", formatFloat(g.Fitness))
+	fmt.Fprintf(&b, "features %s\n", formatFeatures(g.Features))
+	fmt.Fprintf(&b, "actions %s max=%s", g.Actions.Type, formatFloat(g.Actions.MaxForce))
+	if g.Actions.Type == neural.DiscreteActions {
+		fmt.Fprintf(&b, " bins=%d", g.Actions.Bins)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "inputs %s\n", strings.Join(g.Inputs(), " "))
+	fmt.Fprintf(&b, "params %s\n", formatFloats(g.Params))
+	if g.Recurrent != nil {
+		fmt.Fprintf(&b, "recurrent %s\n", formatFloats(g.Recurrent))
+	}
+	if n := g.Normalization; n != nil {
+		fmt.Fprintf(&b, "norm-count %s\n", formatFloat(n.Count))
+		fmt.Fprintf(&b, "norm-mean %s\n", formatFloats(n.Mean))
+		fmt.Fprintf(&b, "norm-m2 %s\n", formatFloats(n.M2))
+	}
+	return b.String()
+}
+
+// ParseGenome reads a genome in the text format of String. Blank lines and
+// lines starting with # are ignored; the inputs line, if present, must
+// match the features
+func ParseGenome(r io.Reader) (Genome, error) {
+	g := Genome{Actions: neural.NewDefaultActionSpace()}
+	var inputs []string
+	var normalization neural.ObservationState
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		key, values := fields[0], fields[1:]
+		if seen[key] {
+			return g, fmt.Errorf("genome line %d: duplicate %s", line, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "version":
+			var version int
+			if version, err = parseInt(values); err == nil && version > GenomeVersion {
+				err = fmt.Errorf("version %d is newer than supported version %d", version, GenomeVersion)
+			}
+		case "preset":
+			g.Preset = strings.Join(values, " ")
+		case "generation":
+			g.Generation, err = parseInt(values)
+		case "fitness":
+			g.Fitness, err = parseFloat(values)
+		case "features":
+			g.Features, err = parseFeatures(values)
+		case "actions":
+			g.Actions, err = parseActions(values)
+		case "inputs":
+			inputs = values
+		case "params":
+			g.Params, err = parseFloats(values)
+		case "recurrent":
+			g.Recurrent, err = parseFloats(values)
+		case "norm-count":
+			normalization.Count, err = parseFloat(values)
+		case "norm-mean":
+			normalization.Mean, err = parseFloats(values)
+		case "norm-m2":
+			normalization.M2, err = parseFloats(values)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return g, fmt.Errorf("genome line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return g, fmt.Errorf("failed to read genome: %w", err)
+	}
+
+	if !seen["params"] {
+		return g, fmt.Errorf("genome has no params")
+	}
+	if inputs != nil && !slices.Equal(inputs, g.Inputs()) {
+		return g, fmt.Errorf("genome inputs %v do not match its features, which produce %v", inputs, g.Inputs())
+	}
+	if want := 1 + len(g.Inputs()); len(g.Params) != want {
+		return g, fmt.Errorf("genome has %d params, want %d for its inputs and bias", len(g.Params), want)
+	}
+	if g.Features.Normalize && seen["norm-mean"] {
+		normalization.Features = g.Features
+		g.Normalization = &normalization
+	}
+	return g, nil
+}
+
+// SaveGenome writes the genome to path in its text format
+func SaveGenome(path string, g Genome) error {
+	if err := os.WriteFile(path, []byte(g.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write genome: %w", err)
+	}
+	return nil
+}
+
+// LoadGenome reads a genome written by SaveGenome
+func LoadGenome(path string) (Genome, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Genome{}, fmt.Errorf("failed to open genome: %w", err)
+	}
+	defer f.Close()
+	g, err := ParseGenome(f)
+	if err != nil {
+		return g, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// formatFeatures writes features as space-separated flags, e.g.
+// "sincos frames=3", or "none"
+func formatFeatures(f neural.FeatureConfig) string {
+	var parts []string
+	if f.Normalize {
+		parts = append(parts, "normalize")
+	}
+	if f.SinCos {
+		parts = append(parts, "sincos")
+	}
+	if f.CartState {
+		parts = append(parts, "cart")
+	}
+	if f.Frames > 1 {
+		parts = append(parts, fmt.Sprintf("frames=%d", f.Frames))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseFeatures reads features written by formatFeatures
+func parseFeatures(values []string) (neural.FeatureConfig, error) {
+	var f neural.FeatureConfig
+	for _, v := range values {
+		switch {
+		case v == "none":
+		case v == "normalize":
+			f.Normalize = true
+		case v == "sincos":
+			f.SinCos = true
+		case v == "cart":
+			f.CartState = true
+		case strings.HasPrefix(v, "frames="):
+			frames, err := strconv.Atoi(strings.TrimPrefix(v, "frames="))
+			if err != nil || frames < 0 {
+				return f, fmt.Errorf("invalid feature %q", v)
+			}
+			f.Frames = frames
+		default:
+			return f, fmt.Errorf("unknown feature %q (want normalize, sincos, cart or frames=N)", v)
+		}
+	}
+	return f, nil
+}
+
+// parseActions reads an action space line, e.g. "discrete max=5 bins=7"
+func parseActions(values []string) (neural.ActionSpace, error) {
+	if len(values) == 0 {
+		return neural.ActionSpace{}, fmt.Errorf("actions needs a type")
+	}
+	bins := 0
+	maxForce := neural.NewDefaultActionSpace().MaxForce
+	for _, v := range values[1:] {
+		key, value, _ := strings.Cut(v, "=")
+		var err error
+		switch key {
+		case "max":
+			maxForce, err = strconv.ParseFloat(value, 64)
+		case "bins":
+			bins, err = strconv.Atoi(value)
+		default:
+			err = fmt.Errorf("unknown action setting %q", v)
+		}
+		if err != nil {
+			return neural.ActionSpace{}, err
+		}
+	}
+	space, err := neural.NewActionSpace(values[0], bins)
+	if err != nil {
+		return space, err
+	}
+	space.MaxForce = maxForce
+	return space, nil
+}
+
+// formatFloat writes v with the fewest digits that parse back exactly
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatFloats writes values space-separated
+func formatFloats(values []float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatFloat(v)
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseFloats reads space-separated values
+func parseFloats(values []string) ([]float64, error) {
+	floats := make([]float64, len(values))
+	for i, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		floats[i] = f
+	}
+	return floats, nil
+}
+
+// parseFloat reads a single value
+func parseFloat(values []string) (float64, error) {
+	if len(values) != 1 {
+		return 0, fmt.Errorf("expected one value, got %d", len(values))
+	}
+	return strconv.ParseFloat(values[0], 64)
+}
+
+// parseInt reads a single integer
+func parseInt(values []string) (int, error) {
+	if len(values) != 1 {
+		return 0, fmt.Errorf("expected one value, got %d", len(values))
+	}
+	return strconv.Atoi(values[0])
+}
@@ -0,0 +1,100 @@
+package evolution
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+func TestGenomeRoundTrip(t *testing.T) {
+	quiet := log.New(&bytes.Buffer{}, "", 0)
+	network := neural.NewNetwork()
+	network.SetLogger(quiet)
+	network.SetPreset(env.LongPolePreset)
+	network.SetObservationTransformer(neural.NewObservationTransformer(neural.FeatureConfig{Normalize: true, SinCos: true, Frames: 2}))
+	network.EnableRecurrent()
+	actions, err := neural.NewActionSpace(neural.DiscreteActions, 7)
+	if err != nil {
+		t.Fatalf("NewActionSpace: %v", err)
+	}
+	network.SetActionSpace(actions)
+	params := NetworkParams(network)
+	for i := range params {
+		params[i] = 0.1*float64(i) - 1.0/3
+	}
+	if err := SetNetworkParams(network, params); err != nil {
+		t.Fatalf("SetNetworkParams: %v", err)
+	}
+	for _, s := range []env.State{{AngleRadians: 0.2}, {AngleRadians: -0.1, AngularVel: 1}} {
+		network.Forward(s) // Gather normalization statistics
+	}
+
+	genome := NewGenome(network, 0.4178, 12)
+	path := filepath.Join(t.TempDir(), "best"+GenomeExtension)
+	if err := SaveGenome(path, genome); err != nil {
+		t.Fatalf("SaveGenome: %v", err)
+	}
+	loaded, err := LoadGenome(path)
+	if err != nil {
+		t.Fatalf("LoadGenome: %v", err)
+	}
+	if loaded.String() != genome.String() {
+		t.Errorf("round trip changed the genome:\n%s\nwant\n%s", loaded, genome)
+	}
+
+	rebuilt, err := loaded.NewNetwork(quiet)
+	if err != nil {
+		t.Fatalf("NewNetwork: %v", err)
+	}
+	if !slices.Equal(NetworkParams(rebuilt), params) || !slices.Equal(rebuilt.GetRecurrentWeights(), network.GetRecurrentWeights()) {
+		t.Errorf("rebuilt network has different weights")
+	}
+	if rebuilt.GetActionSpace() != actions || rebuilt.GetPreset() != env.LongPolePreset {
+		t.Errorf("rebuilt network acts in %v on %q, want %v on %q", rebuilt.GetActionSpace(), rebuilt.GetPreset(), actions, env.LongPolePreset)
+	}
+	state := env.State{AngleRadians: 0.3, AngularVel: -0.5}
+	network.SetEvalMode(true)
+	network.ResetHistory()
+	if got, want := rebuilt.Forward(state), network.Forward(state); got != want {
+		t.Errorf("rebuilt network applies %v, want %v", got, want)
+	}
+}
+
+func TestParseGenome(t *testing.T) {
+	valid := "# shared controller\nversion 1\nfeatures sincos\ninputs angle angular_vel sin_angle cos_angle\nparams 1 2 3 4 5\n"
+	genome, err := ParseGenome(strings.NewReader(valid))
+	if err != nil {
+		t.Fatalf("ParseGenome: %v", err)
+	}
+	if genome.Actions != neural.NewDefaultActionSpace() || len(genome.Params) != 5 {
+		t.Errorf("parsed %+v, want 5 params and the default action space", genome)
+	}
+
+	for name, text := range map[string]string{
+		"no params":       "version 1\n",
+		"newer version":   "version 2\nparams 1 2 3\n",
+		"unknown key":     "params 1 2 3\ncolor red\n",
+		"duplicate key":   "params 1 2 3\nparams 1 2 3\n",
+		"wrong length":    "features sincos\nparams 1 2 3\n",
+		"inputs mismatch": "inputs angle\nparams 1 2 3\n",
+		"bad number":      "params 1 two 3\n",
+		"unknown feature": "features wings\nparams 1 2 3\n",
+		"bad actions":     "actions discrete bins=1\nparams 1 2 3\n",
+	} {
+		if _, err := ParseGenome(strings.NewReader(text)); err == nil {
+			t.Errorf("%s: ParseGenome succeeded, want error", name)
+		}
+	}
+
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(&bytes.Buffer{}, "", 0))
+	if err := genome.ApplyWeights(network); err == nil {
+		t.Errorf("ApplyWeights accepted a genome with other inputs")
+	}
+}
@@ -0,0 +1,41 @@
+package evolution
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+// NetworkParams returns the network's angle weight, angular velocity
+// weight and bias followed by its feature weights, the vector CMA-ES
+// searches over: 3 values for the raw inputs, up to about 50 with
+// engineered and stacked features. A recurrent cell is not included
+func NetworkParams(network *neural.Network) []float64 {
+	return append(network.GetWeights(), network.GetFeatureWeights()...)
+}
+
+// SetNetworkParams loads a vector laid out like NetworkParams into the network
+func SetNetworkParams(network *neural.Network, params []float64) error {
+	if len(params) < 3 {
+		return fmt.Errorf("expected at least 3 parameters, got %d", len(params))
+	}
+	if err := network.SetWeights(params[:3]); err != nil {
+		return err
+	}
+	return network.SetFeatureWeights(params[3:])
+}
+
+// NetworkFitness scores parameter vectors by the average per-step reward
+// of the network running them on every scenario of suite. Vectors of the
+// wrong length score -Inf. The network's weights are left at the last
+// vector scored
+func NetworkFitness(network *neural.Network, suite eval.Suite) Fitness {
+	return func(params []float64) float64 {
+		if err := SetNetworkParams(network, params); err != nil {
+			return math.Inf(-1)
+		}
+		return eval.Run(suite, network).AvgReward
+	}
+}
@@ -0,0 +1,148 @@
+// Package exploration wraps deterministic controllers with exploration
+// strategies (epsilon-greedy and Gaussian/Ornstein–Uhlenbeck noise) so
+// training does not get stuck repeating the same trajectory
+package exploration
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
+)
+
+// Exploration strategy names accepted in training.Config.Exploration
+const (
+	None     = "none"
+	Epsilon  = "epsilon"
+	Gaussian = "gaussian"
+	OU       = "ou"
+)
+
+// Controller is anything that maps a state to a force
+type Controller = agent.Controller
+
+// Schedule decays an exploration parameter exponentially per episode
+type Schedule struct {
+	Start float64 // Value at episode 0
+	End   float64 // Floor the value decays towards
+	Decay float64 // Multiplier applied each episode
+}
+
+// At returns the scheduled value for the given episode
+func (s Schedule) At(episode int) float64 {
+	return math.Max(s.End, s.Start*math.Pow(s.Decay, float64(episode)))
+}
+
+// Explorer perturbs the actions of a controller
+type Explorer struct {
+	controller Controller
+	strategy   string
+	schedule   Schedule
+	noise      Noise
+	actions    []float64 // Discrete actions for epsilon-greedy
+	maxForce   float64
+	rng        *rand.Rand
+
+	scale     float64 // Current epsilon or noise scale
+	lastNoise float64 // Perturbation applied to the last action
+}
+
+// NewEpsilonGreedy creates an explorer that replaces the controller's action
+// with a random one from actions with probability epsilon
+func NewEpsilonGreedy(controller Controller, actions []float64, schedule Schedule, rng *rand.Rand) *Explorer {
+	return &Explorer{
+		controller: controller,
+		strategy:   Epsilon,
+		schedule:   schedule,
+		actions:    actions,
+		rng:        rng,
+		scale:      schedule.At(0),
+	}
+}
+
+// NewNoisy creates an explorer that adds scaled noise to the controller's
+// force, clipped to [-maxForce, maxForce]
+func NewNoisy(controller Controller, noise Noise, schedule Schedule, maxForce float64) *Explorer {
+	return &Explorer{
+		controller: controller,
+		strategy:   OU,
+		schedule:   schedule,
+		noise:      noise,
+		maxForce:   maxForce,
+		scale:      schedule.At(0),
+	}
+}
+
+// NewFromConfig builds the explorer selected by config.Exploration, or
+// returns nil when exploration is disabled
+func NewFromConfig(config training.Config, controller Controller, maxForce float64, rng *rand.Rand) (*Explorer, error) {
+	schedule := Schedule{
+		Start: config.ExplorationStart,
+		End:   config.ExplorationEnd,
+		Decay: config.ExplorationDecay,
+	}
+
+	switch config.Exploration {
+	case "", None:
+		return nil, nil
+	case Epsilon:
+		return NewEpsilonGreedy(controller, []float64{-maxForce, 0, maxForce}, schedule, rng), nil
+	case Gaussian:
+		explorer := NewNoisy(controller, NewGaussianNoise(rng), schedule, maxForce)
+		explorer.strategy = Gaussian
+		return explorer, nil
+	case OU:
+		return NewNoisy(controller, NewOUNoise(0.15, 1.0, config.DeltaTime, rng), schedule, maxForce), nil
+	default:
+		return nil, fmt.Errorf("unknown exploration strategy %q", config.Exploration)
+	}
+}
+
+// Act returns the controller's force with exploration applied
+func (e *Explorer) Act(state env.State) float64 {
+	return e.Perturb(e.controller.Act(state))
+}
+
+// Perturb applies exploration to a force already computed by the controller
+func (e *Explorer) Perturb(force float64) float64 {
+	if e.strategy == Epsilon {
+		e.lastNoise = 0
+		if e.rng.Float64() < e.scale {
+			explored := e.actions[e.rng.Intn(len(e.actions))]
+			e.lastNoise = explored - force
+			return explored
+		}
+		return force
+	}
+
+	explored := force + e.scale*e.noise.Sample()
+	explored = math.Max(-e.maxForce, math.Min(e.maxForce, explored))
+	e.lastNoise = explored - force
+	return explored
+}
+
+// SetEpisode advances the decay schedule and resets noise between episodes
+func (e *Explorer) SetEpisode(episode int) {
+	e.scale = e.schedule.At(episode)
+	if e.noise != nil {
+		e.noise.Reset()
+	}
+}
+
+// Strategy returns the name of the exploration strategy
+func (e *Explorer) Strategy() string {
+	return e.strategy
+}
+
+// Scale returns the current epsilon or noise scale
+func (e *Explorer) Scale() float64 {
+	return e.scale
+}
+
+// LastNoise returns the force difference applied to the last action
+func (e *Explorer) LastNoise() float64 {
+	return e.lastNoise
+}
@@ -0,0 +1,115 @@
+package exploration
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
+)
+
+// constantController always returns the same force
+type constantController float64
+
+func (c constantController) Act(state env.State) float64 {
+	return float64(c)
+}
+
+func TestScheduleDecaysToFloor(t *testing.T) {
+	schedule := Schedule{Start: 1.0, End: 0.1, Decay: 0.5}
+
+	tests := []struct {
+		episode int
+		want    float64
+	}{
+		{0, 1.0},
+		{1, 0.5},
+		{3, 0.125},
+		{10, 0.1},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.At(tt.episode); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("At(%d) = %v, want %v", tt.episode, got, tt.want)
+		}
+	}
+}
+
+func TestEpsilonGreedy(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	actions := []float64{-5, 5}
+
+	// Epsilon of 1 always explores
+	explorer := NewEpsilonGreedy(constantController(1), actions, Schedule{Start: 1, End: 1, Decay: 1}, rng)
+	for i := 0; i < 20; i++ {
+		force := explorer.Act(env.State{})
+		if force != -5 && force != 5 {
+			t.Fatalf("explored force %v not in action set", force)
+		}
+		if explorer.LastNoise() != force-1 {
+			t.Errorf("LastNoise() = %v, want %v", explorer.LastNoise(), force-1)
+		}
+	}
+
+	// Epsilon of 0 never explores
+	explorer = NewEpsilonGreedy(constantController(1), actions, Schedule{}, rng)
+	for i := 0; i < 20; i++ {
+		if force := explorer.Act(env.State{}); force != 1 {
+			t.Fatalf("force = %v, want controller force 1", force)
+		}
+	}
+}
+
+func TestNoisyClipsToMaxForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	explorer := NewNoisy(constantController(4.9), NewGaussianNoise(rng), Schedule{Start: 100, End: 100, Decay: 1}, 5)
+
+	for i := 0; i < 50; i++ {
+		if force := explorer.Act(env.State{}); math.Abs(force) > 5 {
+			t.Fatalf("force %v exceeds max force", force)
+		}
+	}
+}
+
+func TestOUNoiseResetsToMean(t *testing.T) {
+	noise := NewOUNoise(0.15, 1.0, 0.02, rand.New(rand.NewSource(1)))
+	noise.Mu = 0.5
+	for i := 0; i < 10; i++ {
+		noise.Sample()
+	}
+
+	noise.Reset()
+	if noise.x != 0.5 {
+		t.Errorf("after Reset x = %v, want mean 0.5", noise.x)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	config := training.NewDefaultConfig()
+
+	explorer, err := NewFromConfig(config, constantController(0), 5, rng)
+	if err != nil || explorer != nil {
+		t.Fatalf("default config should disable exploration, got %v, %v", explorer, err)
+	}
+
+	for _, strategy := range []string{Epsilon, Gaussian, OU} {
+		config.Exploration = strategy
+		explorer, err := NewFromConfig(config, constantController(0), 5, rng)
+		if err != nil {
+			t.Fatalf("NewFromConfig(%q) failed: %v", strategy, err)
+		}
+		if explorer.Strategy() != strategy {
+			t.Errorf("Strategy() = %q, want %q", explorer.Strategy(), strategy)
+		}
+		if explorer.Scale() != config.ExplorationStart {
+			t.Errorf("Scale() = %v, want %v", explorer.Scale(), config.ExplorationStart)
+		}
+	}
+
+	config.Exploration = "boltzmann"
+	if _, err := NewFromConfig(config, constantController(0), 5, rng); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
@@ -0,0 +1,66 @@
+package exploration
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Noise produces a stream of perturbations for continuous actions
+type Noise interface {
+	// Sample returns the next perturbation with unit scale
+	Sample() float64
+	// Reset restores the process to its initial state, e.g. between episodes
+	Reset()
+}
+
+// GaussianNoise samples independent standard normal perturbations
+type GaussianNoise struct {
+	rng *rand.Rand
+}
+
+// NewGaussianNoise creates uncorrelated Gaussian noise
+func NewGaussianNoise(rng *rand.Rand) *GaussianNoise {
+	return &GaussianNoise{rng: rng}
+}
+
+// Sample returns a standard normal value
+func (g *GaussianNoise) Sample() float64 {
+	return g.rng.NormFloat64()
+}
+
+// Reset is a no-op since Gaussian noise has no state
+func (g *GaussianNoise) Reset() {}
+
+// OUNoise is an Ornstein–Uhlenbeck process, which produces temporally
+// correlated noise so that pushes persist for several ticks instead of
+// cancelling out at 50Hz
+type OUNoise struct {
+	Theta float64 // Rate of mean reversion
+	Mu    float64 // Long-run mean
+	Sigma float64 // Volatility
+	DT    float64 // Time step in seconds
+
+	x   float64
+	rng *rand.Rand
+}
+
+// NewOUNoise creates an Ornstein–Uhlenbeck process starting at its mean
+func NewOUNoise(theta, sigma, dt float64, rng *rand.Rand) *OUNoise {
+	return &OUNoise{
+		Theta: theta,
+		Sigma: sigma,
+		DT:    dt,
+		rng:   rng,
+	}
+}
+
+// Sample advances the process one step and returns its value
+func (o *OUNoise) Sample() float64 {
+	o.x += o.Theta*(o.Mu-o.x)*o.DT + o.Sigma*math.Sqrt(o.DT)*o.rng.NormFloat64()
+	return o.x
+}
+
+// Reset returns the process to its mean
+func (o *OUNoise) Reset() {
+	o.x = o.Mu
+}
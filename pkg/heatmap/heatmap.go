@@ -0,0 +1,144 @@
+// Package heatmap samples functions of the pendulum state over a grid of
+// (angle, angular velocity) and renders them as PNG images, e.g. a
+// network's force, predicted value or sensitivity
+package heatmap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// Margins around the heatmap for the title and axis labels
+const (
+	marginLeft   = 50
+	marginRight  = 10
+	marginTop    = 24
+	marginBottom = 40
+)
+
+// Grid holds one sampled value per pixel, row 0 at the highest angular velocity
+type Grid struct {
+	Values     [][]float64
+	AngleRange float64 // Angles span [-AngleRange, AngleRange]
+	VelRange   float64 // Angular velocities span [-VelRange, VelRange]
+}
+
+// States returns the state at the center of every pixel of a width × height
+// grid, row by row from the highest angular velocity
+func States(width, height int, angleRange, velRange float64) []env.State {
+	states := make([]env.State, 0, width*height)
+	for row := 0; row < height; row++ {
+		vel := velRange * (1 - 2*(float64(row)+0.5)/float64(height))
+		for col := 0; col < width; col++ {
+			angle := angleRange * (2*(float64(col)+0.5)/float64(width) - 1)
+			states = append(states, env.State{AngleRadians: angle, AngularVel: vel})
+		}
+	}
+	return states
+}
+
+// FromValues arranges one value per state of States(width, height, ...)
+// into a grid
+func FromValues(values []float64, width, height int, angleRange, velRange float64) Grid {
+	g := Grid{Values: make([][]float64, height), AngleRange: angleRange, VelRange: velRange}
+	for row := range g.Values {
+		g.Values[row] = values[row*width : (row+1)*width]
+	}
+	return g
+}
+
+// Sweep evaluates f at the center of every pixel of a width × height grid
+func Sweep(width, height int, angleRange, velRange float64, f func(states []env.State) []float64) Grid {
+	return FromValues(f(States(width, height, angleRange, velRange)), width, height, angleRange, velRange)
+}
+
+// Bounds returns the smallest and largest sampled values
+func (g Grid) Bounds() (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, row := range g.Values {
+		for _, v := range row {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	return lo, hi
+}
+
+// Render draws the grid with a diverging colormap over [-limit, limit],
+// axes through upright and zero velocity, and labelled ranges
+func Render(g Grid, limit float64, title, unit string) *image.RGBA {
+	height, width := len(g.Values), len(g.Values[0])
+	img := image.NewRGBA(image.Rect(0, 0, marginLeft+width+marginRight, marginTop+height+marginBottom))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{30, 30, 30, 255}), image.Point{}, draw.Src)
+
+	if limit <= 0 {
+		limit = 1
+	}
+	for row, values := range g.Values {
+		for col, v := range values {
+			img.Set(marginLeft+col, marginTop+row, Diverging(v/limit))
+		}
+	}
+
+	axis := color.RGBA{80, 80, 80, 255}
+	for row := 0; row < height; row++ {
+		img.Set(marginLeft+width/2, marginTop+row, axis)
+	}
+	for col := 0; col < width; col++ {
+		img.Set(marginLeft+col, marginTop+height/2, axis)
+	}
+
+	label(img, title, marginLeft, 16)
+	label(img, fmt.Sprintf("%+.1f", g.VelRange), 4, marginTop+10)
+	label(img, "omega", 4, marginTop+height/2+4)
+	label(img, fmt.Sprintf("%+.1f", -g.VelRange), 4, marginTop+height)
+	label(img, fmt.Sprintf("%+.2f", -g.AngleRange), marginLeft, marginTop+height+14)
+	label(img, "theta", marginLeft+width/2-17, marginTop+height+14)
+	label(img, fmt.Sprintf("%+.2f", g.AngleRange), marginLeft+width-35, marginTop+height+14)
+	label(img, fmt.Sprintf("color: %+.1f (blue) to %+.1f (red) %s", -limit, limit, unit), marginLeft, marginTop+height+32)
+	return img
+}
+
+// Diverging maps t in [-1, 1] to blue through white to red
+func Diverging(t float64) color.RGBA {
+	t = math.Max(-1, math.Min(1, t))
+	fade := uint8(255 * (1 - math.Abs(t)))
+	if t > 0 {
+		return color.RGBA{255, fade, fade, 255}
+	}
+	return color.RGBA{fade, fade, 255, 255}
+}
+
+// label draws text with its baseline at (x, y)
+func label(img *image.RGBA, s string, x, y int) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.White),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// WritePNG encodes img to path
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
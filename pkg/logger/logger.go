@@ -1,13 +1,17 @@
 // Package logger provides a custom logging system for the inverted pendulum application
-// with support for different log levels and file output
+// with support for different log levels, file output and a structured JSON format
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -39,44 +43,151 @@ func (l LogLevel) String() string {
 	}
 }
 
-// Logger is a custom logger with support for log levels and file output
+// Format selects how log records are written
+type Format string
+
+const (
+	// TextFormat writes "[LEVEL] message key=value" lines with a timestamp prefix
+	TextFormat Format = "text"
+	// JSONFormat writes one JSON object per line with level, timestamp,
+	// module, message and fields
+	JSONFormat Format = "json"
+)
+
+// ParseFormat converts a flag value such as "json" into a Format
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case TextFormat, "":
+		return TextFormat, nil
+	case JSONFormat:
+		return JSONFormat, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (want text or json)", s)
+	}
+}
+
+// Fields holds structured key/value context for a log record
+type Fields map[string]interface{}
+
+// Config holds the settings a Logger is constructed with
+type Config struct {
+	ConsoleLevel LogLevel
+	FileLevel    LogLevel  // NONE skips creating a log file
+	Format       Format
+	Dir          string    // Directory for the timestamped log file
+	Console      io.Writer // Console output, os.Stdout if nil
+}
+
+// NewDefaultConfig returns a text logger showing INFO on the console and
+// everything in a file under logs/
+func NewDefaultConfig() Config {
+	return Config{
+		ConsoleLevel: INFO,
+		FileLevel:    DEBUG,
+		Format:       TextFormat,
+		Dir:          "logs",
+		Console:      os.Stdout,
+	}
+}
+
+// Logger is a custom logger with support for log levels and file output.
+// Loggers derived with WithModule or WithFields share their outputs
 type Logger struct {
+	*output
+	module string
+	fields Fields
+}
+
+// output is the state shared by a logger and the loggers derived from it
+type output struct {
+	mu            sync.Mutex
 	consoleLogger *log.Logger
 	fileLogger    *log.Logger
+	console       io.Writer
 	consoleLevel  LogLevel
 	fileLevel     LogLevel
+	format        Format
 	file          *os.File
 }
 
 // NewLogger creates a new logger with the specified console and file log levels
 func NewLogger(consoleLevel, fileLevel LogLevel) (*Logger, error) {
-	// Create console logger
-	consoleLogger := log.New(os.Stdout, "", log.LstdFlags)
+	config := NewDefaultConfig()
+	config.ConsoleLevel = consoleLevel
+	config.FileLevel = fileLevel
+	return New(config)
+}
 
-	// Create logs directory if it doesn't exist
-	logsDir := "logs"
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create logs directory: %v", err)
+// New creates a logger from config
+func New(config Config) (*Logger, error) {
+	format, err := ParseFormat(string(config.Format))
+	if err != nil {
+		return nil, err
+	}
+	console := config.Console
+	if console == nil {
+		console = os.Stdout
 	}
 
-	// Create log file with timestamp in name
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	logFilePath := filepath.Join(logsDir, fmt.Sprintf("pendulum_%s.log", timestamp))
-	file, err := os.Create(logFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log file: %v", err)
+	// JSON records carry their own timestamp
+	flags := log.LstdFlags
+	if format == JSONFormat {
+		flags = 0
 	}
+	out := &output{
+		consoleLogger: log.New(console, "", flags),
+		console:       console,
+		consoleLevel:  config.ConsoleLevel,
+		fileLevel:     config.FileLevel,
+		format:        format,
+	}
+
+	if config.FileLevel != NONE {
+		// Create logs directory if it doesn't exist
+		logsDir := config.Dir
+		if logsDir == "" {
+			logsDir = "logs"
+		}
+		if err := os.MkdirAll(logsDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create logs directory: %v", err)
+		}
 
-	// Create file logger
-	fileLogger := log.New(file, "", log.LstdFlags)
+		// Create log file with timestamp in name
+		ext := ".log"
+		if format == JSONFormat {
+			ext = ".jsonl"
+		}
+		timestamp := time.Now().Format("2006-01-02_15-04-05")
+		logFilePath := filepath.Join(logsDir, fmt.Sprintf("pendulum_%s%s", timestamp, ext))
+		file, err := os.Create(logFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create log file: %v", err)
+		}
+
+		// Create file logger
+		out.file = file
+		out.fileLogger = log.New(file, "", flags)
+	}
 
-	return &Logger{
-		consoleLogger: consoleLogger,
-		fileLogger:    fileLogger,
-		consoleLevel:  consoleLevel,
-		fileLevel:     fileLevel,
-		file:          file,
-	}, nil
+	return &Logger{output: out}, nil
+}
+
+// WithModule returns a logger that tags its records with module
+func (l *Logger) WithModule(module string) *Logger {
+	return &Logger{output: l.output, module: module, fields: l.fields}
+}
+
+// WithFields returns a logger that adds fields to each of its records,
+// on top of any fields this logger already carries
+func (l *Logger) WithFields(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{output: l.output, module: l.module, fields: merged}
 }
 
 // Close closes the log file
@@ -125,28 +236,125 @@ func (l *Logger) Fatalf(format string, v ...interface{}) {
 
 // log logs a message with the specified level
 func (l *Logger) log(level LogLevel, format string, v ...interface{}) {
-	// Format message with level prefix
-	message := fmt.Sprintf("[%s] %s", level.String(), format)
-	
+	consoleOn := level >= l.consoleLevel
+	fileOn := level >= l.fileLevel && l.fileLogger != nil
+	if !consoleOn && !fileOn {
+		return
+	}
+
+	var line string
+	if l.format == JSONFormat {
+		line = l.jsonRecord(level, fmt.Sprintf(format, v...))
+	} else {
+		line = l.textRecord(level, fmt.Sprintf(format, v...))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	// Log to console if level is high enough
-	if level >= l.consoleLevel {
-		l.consoleLogger.Printf(message, v...)
+	if consoleOn {
+		l.consoleLogger.Print(line)
 	}
-	
+
 	// Log to file if level is high enough
-	if level >= l.fileLevel {
-		l.fileLogger.Printf(message, v...)
+	if fileOn {
+		l.fileLogger.Print(line)
+	}
+}
+
+// textRecord formats a message with level prefix, module and sorted fields
+func (l *Logger) textRecord(level LogLevel, message string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] ", level.String())
+	if l.module != "" {
+		fmt.Fprintf(&b, "[%s] ", l.module)
+	}
+	b.WriteString(strings.TrimRight(message, "\n"))
+	for _, k := range sortedKeys(l.fields) {
+		fmt.Fprintf(&b, " %s=%v", k, l.fields[k])
+	}
+	return b.String()
+}
+
+// jsonRecord formats a message as a single-line JSON object
+func (l *Logger) jsonRecord(level LogLevel, message string) string {
+	module := l.module
+	message = strings.TrimSpace(message)
+
+	// Messages written the repo's "[Tag] message" way take the tag as module
+	if module == "" && strings.HasPrefix(message, "[") {
+		if end := strings.Index(message, "]"); end > 1 {
+			module = message[1:end]
+			message = strings.TrimSpace(message[end+1:])
+		}
+	}
+
+	record := struct {
+		Level     string `json:"level"`
+		Timestamp string `json:"timestamp"`
+		Module    string `json:"module,omitempty"`
+		Message   string `json:"message"`
+		Fields    Fields `json:"fields,omitempty"`
+	}{
+		Level:     level.String(),
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Module:    module,
+		Message:   message,
+		Fields:    l.fields,
 	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		// Values such as NaN or channels have no JSON form; record them as text
+		record.Fields = make(Fields, len(l.fields))
+		for k, v := range l.fields {
+			record.Fields[k] = fmt.Sprint(v)
+		}
+		data, _ = json.Marshal(record)
+	}
+	return string(data)
+}
+
+// sortedKeys returns the field names in a stable order
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // GetStandardLogger returns a standard log.Logger that writes to both console and file
-// This is useful for compatibility with libraries that expect a standard logger
+// This is useful for compatibility with libraries that expect a standard logger.
+// In JSON format each line becomes an INFO record
 func (l *Logger) GetStandardLogger() *log.Logger {
+	if l.format == JSONFormat {
+		return log.New(lineWriter{l}, "", 0)
+	}
+
 	// Create a multi-writer that writes to both console and file
-	multiWriter := io.MultiWriter(os.Stdout, l.file)
+	writers := []io.Writer{l.console}
+	if l.file != nil {
+		writers = append(writers, l.file)
+	}
+	multiWriter := io.MultiWriter(writers...)
 	return log.New(multiWriter, "", log.LstdFlags)
 }
 
+// lineWriter turns each write from a standard logger into a log record
+type lineWriter struct {
+	logger *Logger
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.logger.log(INFO, "%s", line)
+	}
+	return len(p), nil
+}
+
 // SetConsoleLevel sets the minimum log level for console output
 func (l *Logger) SetConsoleLevel(level LogLevel) {
 	l.consoleLevel = level
@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger(t *testing.T, format Format) (*Logger, *bytes.Buffer) {
+	t.Helper()
+	var console bytes.Buffer
+	config := NewDefaultConfig()
+	config.Format = format
+	config.ConsoleLevel = DEBUG
+	config.Dir = t.TempDir()
+	config.Console = &console
+
+	l, err := New(config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, &console
+}
+
+func TestJSONFormat(t *testing.T) {
+	t.Run("record fields", func(t *testing.T) {
+		l, console := newTestLogger(t, JSONFormat)
+		l.WithModule("Trainer").WithFields(Fields{"episode": 12}).WithFields(Fields{"reward": 0.5}).Info("batch %d done", 3)
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(console.Bytes(), &record); err != nil {
+			t.Fatalf("console output %q is not one JSON object: %v", console.String(), err)
+		}
+		if record["level"] != "INFO" || record["module"] != "Trainer" || record["message"] != "batch 3 done" {
+			t.Errorf("record = %v", record)
+		}
+		if record["timestamp"] == "" {
+			t.Error("record has no timestamp")
+		}
+		fields, _ := record["fields"].(map[string]interface{})
+		if fields["episode"] != 12.0 || fields["reward"] != 0.5 {
+			t.Errorf("fields = %v, want episode and reward", fields)
+		}
+	})
+
+	t.Run("tag prefix becomes module", func(t *testing.T) {
+		l, console := newTestLogger(t, JSONFormat)
+		l.Printf("[Network] Saved to %s", "net.json")
+		l.GetStandardLogger().Printf("[Capture] first\nsecond")
+
+		lines := strings.Split(strings.TrimSpace(console.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("got %d lines, want 3: %q", len(lines), console.String())
+		}
+		var record map[string]interface{}
+		json.Unmarshal([]byte(lines[0]), &record)
+		if record["module"] != "Network" || record["message"] != "Saved to net.json" {
+			t.Errorf("record = %v", record)
+		}
+	})
+
+	t.Run("unencodable field values", func(t *testing.T) {
+		l, console := newTestLogger(t, JSONFormat)
+		l.WithFields(Fields{"loss": math.NaN()}).Error("diverged")
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(console.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON %q: %v", console.String(), err)
+		}
+		if fields := record["fields"].(map[string]interface{}); fields["loss"] != "NaN" {
+			t.Errorf("loss = %v, want \"NaN\"", fields["loss"])
+		}
+	})
+
+	t.Run("levels and file", func(t *testing.T) {
+		var console bytes.Buffer
+		config := NewDefaultConfig()
+		config.Format = JSONFormat
+		config.Dir = t.TempDir()
+		config.Console = &console
+		l, err := New(config)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		l.Debug("file only")
+		l.Close()
+
+		if console.Len() != 0 {
+			t.Errorf("DEBUG record reached the INFO console: %q", console.String())
+		}
+		files, _ := filepath.Glob(filepath.Join(config.Dir, "*.jsonl"))
+		if len(files) != 1 {
+			t.Fatalf("log files = %v, want one .jsonl file", files)
+		}
+		data, _ := os.ReadFile(files[0])
+		if !strings.Contains(string(data), `"message":"file only"`) {
+			t.Errorf("log file = %q", data)
+		}
+	})
+}
+
+func TestTextFormat(t *testing.T) {
+	l, console := newTestLogger(t, TextFormat)
+	l.WithModule("Trainer").WithFields(Fields{"b": 2, "a": 1}).Info("saved")
+
+	if !strings.HasSuffix(strings.TrimSpace(console.String()), "[INFO] [Trainer] saved a=1 b=2") {
+		t.Errorf("text record = %q", console.String())
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestSampler(t *testing.T) {
+	count := func(s *Sampler, n int) int {
+		logged := 0
+		for i := 0; i < n; i++ {
+			if s.Step() {
+				logged++
+			}
+		}
+		return logged
+	}
+
+	t.Run("modes", func(t *testing.T) {
+		tests := []struct {
+			mode        SamplingMode
+			wantSteps   int
+			wantEpisode bool
+		}{
+			{SampleAll, 250, true},
+			{SampleEveryN, 3, true},
+			{SampleEpisodes, 0, true},
+			{SampleErrors, 0, false},
+		}
+		for _, tt := range tests {
+			s := NewSampler(SamplingConfig{Mode: tt.mode, Every: 100})
+			if got := count(s, 250); got != tt.wantSteps {
+				t.Errorf("%s: logged %d of 250 steps, want %d", tt.mode, got, tt.wantSteps)
+			}
+			if s.Episode() != tt.wantEpisode {
+				t.Errorf("%s: Episode() = %v, want %v", tt.mode, s.Episode(), tt.wantEpisode)
+			}
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		s := NewSampler(SamplingConfig{Mode: SampleEveryN, Every: 1, MaxPerSecond: 1})
+		if got := count(s, 1000); got != 1 {
+			t.Errorf("logged %d steps within a second, want 1", got)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		if mode, err := ParseSamplingMode("Episodes"); err != nil || mode != SampleEpisodes {
+			t.Errorf("ParseSamplingMode(Episodes) = %q, %v", mode, err)
+		}
+		if _, err := ParseSamplingMode("sometimes"); err == nil {
+			t.Error("expected error for unknown mode")
+		}
+	})
+}
+
+func TestProgress(t *testing.T) {
+	newProgress := func(live bool) (*Progress, *bytes.Buffer, *time.Time) {
+		var out bytes.Buffer
+		p := NewProgress(&out, live)
+		clock := time.Unix(0, 0)
+		p.now = func() time.Time { return clock }
+		return p, &out, &clock
+	}
+
+	t.Run("line reports rates and estimate", func(t *testing.T) {
+		p, _, clock := newProgress(true)
+		p.Start(100, 20)
+		for i := 0; i < 20; i++ {
+			*clock = clock.Add(time.Second)
+			p.Episode(500, i%4 == 0)
+		}
+		want := "Episode 40/100 [####------] 40% | 500 steps/s | success 25% | ETA 1m0s"
+		if got := p.Line(); got != want {
+			t.Errorf("Line() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("logs print above the live line", func(t *testing.T) {
+		p, out, clock := newProgress(true)
+		p.Start(10, 0)
+		*clock = clock.Add(time.Second)
+		p.Episode(10, true)
+		p.Printf("checkpoint saved\n")
+		p.Finish()
+
+		lines := strings.Split(out.String(), "\n")
+		if len(lines) != 3 || lines[2] != "" {
+			t.Fatalf("output has lines %q, want the message and the final progress line", lines)
+		}
+		if !strings.HasSuffix(lines[0], "\rcheckpoint saved") {
+			t.Errorf("message %q does not start at a cleared line", lines[0])
+		}
+		if !strings.Contains(lines[1], "Episode 1/10") {
+			t.Errorf("progress line %q not redrawn after the message", lines[1])
+		}
+	})
+
+	t.Run("quiet writes only logs", func(t *testing.T) {
+		p, out, _ := newProgress(false)
+		p.Start(10, 0)
+		p.Episode(10, true)
+		p.Printf("checkpoint saved\n")
+		p.Finish()
+		if got := out.String(); got != "checkpoint saved\n" {
+			t.Errorf("quiet output = %q, want only the message", got)
+		}
+	})
+}
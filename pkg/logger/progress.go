@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressRedraw is the shortest time between redraws of the progress line
+const progressRedraw = 100 * time.Millisecond
+
+// progressWindow is how many recent episodes the success rate covers
+const progressWindow = 100
+
+// Progress reports training progress as a single console line that is
+// rewritten in place: episodes done, steps per second, the success rate of
+// recent episodes and the estimated time remaining. It is also an
+// io.Writer, so logs written through it appear above the line instead of
+// breaking it. Without a live line, e.g. for -quiet, only those logs are
+// written
+type Progress struct {
+	mutex     sync.Mutex
+	out       io.Writer
+	live      bool
+	total     int
+	done      int
+	startDone int // Episodes done before this run, e.g. when resuming
+	steps     int64
+	recent    []bool // Successes of the latest episodes, oldest first
+	start     time.Time
+	lastDraw  time.Time
+	width     int // Length of the line on screen, to blank what a shorter one leaves
+	now       func() time.Time
+}
+
+// NewProgress reports progress on out, drawing the live line only if live
+// is set. Start begins the count
+func NewProgress(out io.Writer, live bool) *Progress {
+	return &Progress{out: out, live: live, start: time.Now(), now: time.Now}
+}
+
+// Start begins a run toward total episodes, done of which finished before
+// it, e.g. in a resumed session. Those count toward the total but not the
+// rates and estimate
+func (p *Progress) Start(total, done int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.total, p.done, p.startDone = total, done, done
+	p.steps = 0
+	p.recent = nil
+	p.start = p.now()
+}
+
+// Episode records a finished episode of steps steps and redraws the line,
+// at most every 100ms
+func (p *Progress) Episode(steps int, success bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.done++
+	p.steps += int64(steps)
+	p.recent = append(p.recent, success)
+	if len(p.recent) > progressWindow {
+		p.recent = p.recent[1:]
+	}
+	if now := p.now(); now.Sub(p.lastDraw) >= progressRedraw || p.done == p.total {
+		p.draw(now)
+	}
+}
+
+// Write prints log output above the progress line, implementing io.Writer
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.clear()
+	n, err := p.out.Write(b)
+	if p.width > 0 {
+		p.draw(p.now())
+	}
+	return n, err
+}
+
+// Printf prints a message above the progress line
+func (p *Progress) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(p, format, args...)
+}
+
+// Finish leaves the last progress line on screen and moves past it
+func (p *Progress) Finish() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.width > 0 {
+		p.draw(p.now())
+		fmt.Fprintln(p.out)
+		p.width = 0
+	}
+}
+
+// Line formats the progress line, e.g.
+// "Episode 120/1000 [##--------] 12% | 5230 steps/s | success 45% | ETA 1m32s"
+func (p *Progress) Line() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.line(p.now())
+}
+
+func (p *Progress) line(now time.Time) string {
+	fraction := 0.0
+	if p.total > 0 {
+		fraction = min(1, float64(p.done)/float64(p.total))
+	}
+	const barWidth = 10
+	filled := int(fraction * barWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled)
+
+	elapsed := now.Sub(p.start)
+	stepsPerSec := 0.0
+	if elapsed > 0 {
+		stepsPerSec = float64(p.steps) / elapsed.Seconds()
+	}
+	successes := 0
+	for _, s := range p.recent {
+		if s {
+			successes++
+		}
+	}
+	success := 0.0
+	if len(p.recent) > 0 {
+		success = float64(successes) / float64(len(p.recent))
+	}
+
+	eta := "--"
+	if run := p.done - p.startDone; run > 0 && p.done < p.total {
+		remaining := time.Duration(float64(elapsed) / float64(run) * float64(p.total-p.done))
+		eta = remaining.Round(time.Second).String()
+	} else if p.total > 0 && p.done >= p.total {
+		eta = "0s"
+	}
+
+	return fmt.Sprintf("Episode %d/%d [%s] %.0f%% | %.0f steps/s | success %.0f%% | ETA %s",
+		p.done, p.total, bar, 100*fraction, stepsPerSec, 100*success, eta)
+}
+
+// draw rewrites the line in place
+func (p *Progress) draw(now time.Time) {
+	if !p.live {
+		return
+	}
+	line := p.line(now)
+	padding := ""
+	if len(line) < p.width {
+		padding = strings.Repeat(" ", p.width-len(line))
+	}
+	fmt.Fprintf(p.out, "\r%s%s", line, padding)
+	p.width = len(line)
+	p.lastDraw = now
+}
+
+// clear blanks the line so other output starts at the beginning of it
+func (p *Progress) clear() {
+	if p.width > 0 {
+		fmt.Fprintf(p.out, "\r%s\r", strings.Repeat(" ", p.width))
+	}
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SamplingMode selects which messages a component logs
+type SamplingMode string
+
+const (
+	// SampleAll logs every message, including per-step detail
+	SampleAll SamplingMode = "all"
+	// SampleEveryN logs every Nth per-step message, rate limited, plus
+	// episode boundaries and errors
+	SampleEveryN SamplingMode = "sampled"
+	// SampleEpisodes logs episode boundaries and errors only
+	SampleEpisodes SamplingMode = "episodes"
+	// SampleErrors logs errors only
+	SampleErrors SamplingMode = "errors"
+)
+
+// ParseSamplingMode converts a flag value such as "episodes" into a SamplingMode
+func ParseSamplingMode(s string) (SamplingMode, error) {
+	switch mode := SamplingMode(strings.ToLower(s)); mode {
+	case SampleAll, SampleEveryN, SampleEpisodes, SampleErrors:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown log sampling mode %q (want all, sampled, episodes or errors)", s)
+	}
+}
+
+// SamplingConfig controls the verbosity of high-frequency logs in env,
+// neural and training
+type SamplingConfig struct {
+	Mode         SamplingMode
+	Every        int     // In sampled mode, log one in Every per-step messages
+	MaxPerSecond float64 // In sampled mode, cap per-step messages per second (0 = no cap)
+}
+
+// NewDefaultSamplingConfig logs one in 100 per-step messages, at most 5 per second
+func NewDefaultSamplingConfig() SamplingConfig {
+	return SamplingConfig{
+		Mode:         SampleEveryN,
+		Every:        100,
+		MaxPerSecond: 5,
+	}
+}
+
+var (
+	defaultSamplingMu sync.Mutex
+	defaultSampling   = NewDefaultSamplingConfig()
+)
+
+// SetDefaultSampling sets the sampling used by components created
+// afterwards, so a command can control verbosity in one place
+func SetDefaultSampling(config SamplingConfig) {
+	defaultSamplingMu.Lock()
+	defer defaultSamplingMu.Unlock()
+	defaultSampling = config
+}
+
+// DefaultSampling returns the sampling set by SetDefaultSampling
+func DefaultSampling() SamplingConfig {
+	defaultSamplingMu.Lock()
+	defer defaultSamplingMu.Unlock()
+	return defaultSampling
+}
+
+// Sampler decides which of a component's messages to log. It is safe for
+// concurrent use
+type Sampler struct {
+	config SamplingConfig
+	mu     sync.Mutex
+	steps  uint64    // Per-step messages seen
+	last   time.Time // When the last per-step message was allowed
+}
+
+// NewSampler creates a sampler for config
+func NewSampler(config SamplingConfig) *Sampler {
+	return &Sampler{config: config}
+}
+
+// Config returns the sampler's configuration
+func (s *Sampler) Config() SamplingConfig {
+	return s.config
+}
+
+// Step reports whether a per-step message should be logged. Call it once
+// per step and reuse the answer for all of that step's lines
+func (s *Sampler) Step() bool {
+	switch s.config.Mode {
+	case SampleAll:
+		return true
+	case SampleEveryN:
+	default:
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.steps
+	s.steps++
+	if every := uint64(max(1, s.config.Every)); n%every != 0 {
+		return false
+	}
+	if s.config.MaxPerSecond > 0 {
+		now := time.Now()
+		interval := time.Duration(float64(time.Second) / s.config.MaxPerSecond)
+		if !s.last.IsZero() && now.Sub(s.last) < interval {
+			return false
+		}
+		s.last = now
+	}
+	return true
+}
+
+// Episode reports whether an episode boundary message should be logged
+func (s *Sampler) Episode() bool {
+	return s.config.Mode != SampleErrors
+}
@@ -0,0 +1,279 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MetricRow is a single network_metrics row
+type MetricRow struct {
+	SessionID  string
+	Episode    int
+	Step       int
+	MetricType string
+	MetricName string
+	Value      float64
+	Metadata   string
+}
+
+// BatchConfig controls how the BatchWriter groups inserts
+type BatchConfig struct {
+	FlushInterval time.Duration // Maximum time a row waits before being written
+	MaxBatchSize  int           // Rows per transaction; reaching it triggers a flush
+	QueueSize     int           // Pending rows allowed before producers block (backpressure)
+}
+
+// NewDefaultBatchConfig returns a BatchConfig suited to 60+ Hz training loops
+func NewDefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		FlushInterval: 500 * time.Millisecond,
+		MaxBatchSize:  1000,
+		QueueSize:     10000,
+	}
+}
+
+// ErrWriterClosed is returned when recording to a closed BatchWriter
+var ErrWriterClosed = errors.New("batch writer closed")
+
+// BatchWriter buffers metric rows and step traces and writes them in
+// transactions on a background goroutine
+type BatchWriter struct {
+	db       Store
+	config   BatchConfig
+	rows     chan MetricRow
+	steps    chan stepRow
+	flushReq chan chan error
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.RWMutex
+	closed  bool
+	errMu   sync.Mutex
+	lastErr error // First write error since the last Flush
+}
+
+// NewBatchWriter starts a background writer for the given store
+func NewBatchWriter(db Store, config BatchConfig) *BatchWriter {
+	defaults := NewDefaultBatchConfig()
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaults.MaxBatchSize
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+
+	w := &BatchWriter{
+		db:       db,
+		config:   config,
+		rows:     make(chan MetricRow, config.QueueSize),
+		steps:    make(chan stepRow, config.QueueSize),
+		flushReq: make(chan chan error),
+		done:     make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w
+}
+
+// RecordMetric queues a metric row, blocking when the queue is full
+func (w *BatchWriter) RecordMetric(sessionID string, episode, step int, metricType, metricName string, value float64, metadata string) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	w.rows <- MetricRow{
+		SessionID:  sessionID,
+		Episode:    episode,
+		Step:       step,
+		MetricType: metricType,
+		MetricName: metricName,
+		Value:      value,
+		Metadata:   metadata,
+	}
+
+	return nil
+}
+
+// recordStep queues a partial step trace row, blocking when the queue is full
+func (w *BatchWriter) recordStep(row stepRow) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	w.steps <- row
+	return nil
+}
+
+// Flush writes every queued row and returns the first write error since the last Flush
+func (w *BatchWriter) Flush() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return ErrWriterClosed
+	}
+	reply := make(chan error)
+	w.flushReq <- reply
+	w.mu.RUnlock()
+
+	return <-reply
+}
+
+// Close flushes pending rows and stops the background goroutine
+func (w *BatchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	w.wg.Wait()
+
+	return w.takeErr()
+}
+
+// run collects rows and writes them when the batch fills, the interval
+// elapses, a flush is requested, or the writer is closed
+func (w *BatchWriter) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	b := &pending{
+		rows:  make([]MetricRow, 0, w.config.MaxBatchSize),
+		steps: make([]stepRow, 0, w.config.MaxBatchSize),
+	}
+
+	for {
+		select {
+		case row := <-w.rows:
+			b.rows = append(b.rows, row)
+			if b.size() >= w.config.MaxBatchSize {
+				w.write(b)
+			}
+		case row := <-w.steps:
+			b.steps = append(b.steps, row)
+			if b.size() >= w.config.MaxBatchSize {
+				w.write(b)
+			}
+		case <-ticker.C:
+			w.write(b)
+		case reply := <-w.flushReq:
+			w.drain(b)
+			w.write(b)
+			reply <- w.takeErr()
+		case <-w.done:
+			w.drain(b)
+			w.write(b)
+			return
+		}
+	}
+}
+
+// pending holds the rows collected since the last write
+type pending struct {
+	rows  []MetricRow
+	steps []stepRow
+}
+
+func (b *pending) size() int {
+	return len(b.rows) + len(b.steps)
+}
+
+// drain moves every row already queued into the batch without blocking
+func (w *BatchWriter) drain(b *pending) {
+	for {
+		select {
+		case row := <-w.rows:
+			b.rows = append(b.rows, row)
+		case row := <-w.steps:
+			b.steps = append(b.steps, row)
+		default:
+			return
+		}
+	}
+}
+
+// write inserts the batch and empties it for reuse
+func (w *BatchWriter) write(b *pending) {
+	if len(b.rows) > 0 {
+		w.setErr(w.db.RecordMetrics(b.rows))
+		b.rows = b.rows[:0]
+	}
+	if len(b.steps) > 0 {
+		w.setErr(w.db.recordSteps(b.steps))
+		b.steps = b.steps[:0]
+	}
+}
+
+// setErr keeps the first write error until the next takeErr
+func (w *BatchWriter) setErr(err error) {
+	if err == nil {
+		return
+	}
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	if w.lastErr == nil {
+		w.lastErr = err
+	}
+}
+
+// takeErr returns and clears the stored write error
+func (w *BatchWriter) takeErr() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	err := w.lastErr
+	w.lastErr = nil
+	return err
+}
+
+// RecordMetrics inserts many metric rows in a single transaction
+func (m *DB) RecordMetrics(rows []MetricRow) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin metrics batch: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO network_metrics (
+			session_id, episode, step, metric_type, metric_name, value, metadata
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare metrics batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.Exec(r.SessionID, r.Episode, r.Step, r.MetricType, r.MetricName, r.Value, r.Metadata); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record metric batch: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit metrics batch: %w", err)
+	}
+
+	return nil
+}
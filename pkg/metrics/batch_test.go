@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBatchWriter(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Long interval and small queue so only Flush and batch size trigger writes
+	writer := NewBatchWriter(db, BatchConfig{
+		FlushInterval: time.Hour,
+		MaxBatchSize:  7,
+		QueueSize:     4,
+	})
+
+	const rows = 25
+	for i := 0; i < rows; i++ {
+		if err := writer.RecordMetric("session_batch", 1, i, "input", "angle", float64(i), ""); err != nil {
+			t.Fatalf("RecordMetric failed: %v", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	var count int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM network_metrics WHERE session_id = ?`,
+		"session_batch").Scan(&count); err != nil {
+		t.Fatalf("Failed to count rows: %v", err)
+	}
+	if count != rows {
+		t.Errorf("after Flush found %d rows, want %d", count, rows)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := writer.RecordMetric("session_batch", 1, 0, "input", "angle", 0, ""); err != ErrWriterClosed {
+		t.Errorf("RecordMetric after Close returned %v, want ErrWriterClosed", err)
+	}
+}
+
+func TestEnableBatchingWhileLogging(t *testing.T) {
+	logger, err := NewLogger(filepath.Join(t.TempDir(), "metrics.db"), false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	// Run with -race: rows recorded while batching is switched on must see
+	// the writer safely
+	const rows = 50
+	done := make(chan error)
+	go func() {
+		for i := 0; i < rows; i++ {
+			if err := logger.LogReward("step", float64(i)); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	logger.EnableBatching(NewDefaultBatchConfig())
+	if err := <-done; err != nil {
+		t.Fatalf("LogReward failed: %v", err)
+	}
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"fmt"
+)
+
+// Benchmark is the measured throughput of one hot path at a commit
+type Benchmark struct {
+	GitHash     string
+	Name        string
+	Steps       int
+	Seconds     float64
+	StepsPerSec float64
+}
+
+// RecordBenchmark stores a benchmark result
+func (m *DB) RecordBenchmark(b Benchmark) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		INSERT INTO benchmarks (git_hash, name, steps, seconds, steps_per_sec)
+		VALUES (?, ?, ?, ?, ?)
+	`, b.GitHash, b.Name, b.Steps, b.Seconds, b.StepsPerSec)
+
+	if err != nil {
+		return fmt.Errorf("failed to record benchmark: %w", err)
+	}
+
+	return nil
+}
+
+// GetBenchmarks returns every recorded result of the named benchmark, oldest
+// first, so throughput can be compared across commits
+func (m *DB) GetBenchmarks(name string) ([]Benchmark, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT git_hash, name, steps, seconds, steps_per_sec
+		FROM benchmarks
+		WHERE name = ?
+		ORDER BY id
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query benchmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var benchmarks []Benchmark
+	for rows.Next() {
+		var b Benchmark
+		if err := rows.Scan(&b.GitHash, &b.Name, &b.Steps, &b.Seconds, &b.StepsPerSec); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark row: %w", err)
+		}
+		benchmarks = append(benchmarks, b)
+	}
+
+	return benchmarks, rows.Err()
+}
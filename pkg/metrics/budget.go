@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// ComputeBudget is the training effort a session has spent, for comparing
+// algorithms by sample and time efficiency rather than by episode count
+type ComputeBudget struct {
+	WallClock      time.Duration `json:"wall_clock"`      // Time spent running episodes
+	Steps          int64         `json:"steps"`           // Environment steps simulated
+	ForwardPasses  int64         `json:"forward_passes"`  // Approximate network evaluations
+	BackwardPasses int64         `json:"backward_passes"` // Approximate gradient computations
+}
+
+// Add returns the sum of two budgets, e.g. of an ensemble's trainers
+func (b ComputeBudget) Add(other ComputeBudget) ComputeBudget {
+	return ComputeBudget{
+		WallClock:      b.WallClock + other.WallClock,
+		Steps:          b.Steps + other.Steps,
+		ForwardPasses:  b.ForwardPasses + other.ForwardPasses,
+		BackwardPasses: b.BackwardPasses + other.BackwardPasses,
+	}
+}
+
+// StepsPerSecond returns the simulation throughput, or 0 before any time is spent
+func (b ComputeBudget) StepsPerSecond() float64 {
+	if b.WallClock <= 0 {
+		return 0
+	}
+	return float64(b.Steps) / b.WallClock.Seconds()
+}
+
+// String formats the budget for logs and reports
+func (b ComputeBudget) String() string {
+	return fmt.Sprintf("%s, %d steps (%.0f/s), %d forward, %d backward passes",
+		b.WallClock.Round(time.Millisecond), b.Steps, b.StepsPerSecond(), b.ForwardPasses, b.BackwardPasses)
+}
+
+// LogBudget records the session's cumulative compute budget, replacing the
+// previous total
+func (l *Logger) LogBudget(budget ComputeBudget) error {
+	return l.db.UpdateSessionBudget(l.sessionID, budget)
+}
+
+// UpdateSessionBudget stores a session's cumulative compute budget
+func (m *DB) UpdateSessionBudget(sessionID string, budget ComputeBudget) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		UPDATE sessions
+		SET wall_clock_seconds = ?, steps = ?, forward_passes = ?, backward_passes = ?
+		WHERE session_id = ?
+	`, budget.WallClock.Seconds(), budget.Steps, budget.ForwardPasses, budget.BackwardPasses, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update session budget: %w", err)
+	}
+
+	return nil
+}
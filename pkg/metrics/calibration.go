@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ValueCalibration compares value predictions with the discounted returns
+// that were actually realized from the same states. A calibrated value
+// function has no bias, a small RMSE and ranks states like their returns
+type ValueCalibration struct {
+	Checkpoint      int
+	Episode         int     // Episode the calibration was logged at
+	Gamma           float64 // Discount of the realized returns
+	Steps           int
+	Bias            float64 // Mean of prediction minus realized return
+	RMSE            float64
+	RankCorrelation float64 // Spearman correlation of predictions and returns, in [-1, 1]
+}
+
+// CalibrateValues computes the bias, RMSE and rank correlation of
+// predictions against the returns realized from the same states
+func CalibrateValues(predictions, returns []float64) ValueCalibration {
+	c := ValueCalibration{Steps: len(predictions)}
+	if len(predictions) == 0 {
+		return c
+	}
+	var sum, squares float64
+	for i, p := range predictions {
+		e := p - returns[i]
+		sum += e
+		squares += e * e
+	}
+	n := float64(len(predictions))
+	c.Bias = sum / n
+	c.RMSE = math.Sqrt(squares / n)
+	c.RankCorrelation = correlation(ranks(predictions), ranks(returns))
+	return c
+}
+
+// ranks returns the rank of each value from 1, ties sharing their mean rank
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	r := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		mean := float64(start+end+1) / 2
+		for _, i := range order[start:end] {
+			r[i] = mean
+		}
+		start = end
+	}
+	return r
+}
+
+// CalibrationTracker collects the value predicted at every step of a run
+// of episodes with the step's reward, so the predictions can be calibrated
+// against the discounted returns once each episode's rewards are known
+type CalibrationTracker struct {
+	gamma       float64
+	predictions []float64
+	returns     []float64
+	pending     []float64 // Predictions of the running episode
+	rewards     []float64 // Rewards of the running episode
+}
+
+// NewCalibrationTracker creates a tracker that discounts rewards by gamma
+func NewCalibrationTracker(gamma float64) *CalibrationTracker {
+	return &CalibrationTracker{gamma: gamma}
+}
+
+// Step records the value predicted for a step's state and the reward the
+// step earned
+func (t *CalibrationTracker) Step(prediction, reward float64) {
+	t.pending = append(t.pending, prediction)
+	t.rewards = append(t.rewards, reward)
+}
+
+// EndEpisode computes the running episode's returns. Rewards after the
+// episode's end count as zero, so episodes cut off by a step limit
+// understate their returns
+func (t *CalibrationTracker) EndEpisode() {
+	t.predictions = append(t.predictions, t.pending...)
+	t.returns = append(t.returns, DiscountedReturns(t.rewards, t.gamma)...)
+	t.pending, t.rewards = t.pending[:0], t.rewards[:0]
+}
+
+// Calibration calibrates the predictions of the finished episodes
+func (t *CalibrationTracker) Calibration() ValueCalibration {
+	c := CalibrateValues(t.predictions, t.returns)
+	c.Gamma = t.gamma
+	return c
+}
+
+// Reset forgets every episode, e.g. to calibrate each checkpoint separately
+func (t *CalibrationTracker) Reset() {
+	t.predictions, t.returns = t.predictions[:0], t.returns[:0]
+	t.pending, t.rewards = t.pending[:0], t.rewards[:0]
+}
+
+// calibrationMetadata is stored with each calibration metric
+type calibrationMetadata struct {
+	Checkpoint int     `json:"checkpoint"`
+	Gamma      float64 `json:"gamma"`
+	Steps      int     `json:"steps"`
+}
+
+// LogCalibration records a value calibration at the current episode, as
+// calibration/bias, calibration/rmse and calibration/rank_correlation
+func (l *Logger) LogCalibration(c ValueCalibration) error {
+	metadataJSON, err := json.Marshal(calibrationMetadata{Checkpoint: c.Checkpoint, Gamma: c.Gamma, Steps: c.Steps})
+	if err != nil {
+		return fmt.Errorf("failed to marshal calibration metadata: %w", err)
+	}
+	for _, m := range []struct {
+		name  string
+		value float64
+	}{
+		{"bias", c.Bias},
+		{"rmse", c.RMSE},
+		{"rank_correlation", c.RankCorrelation},
+	} {
+		if err := l.recordMetric(l.sessionID, l.episode, 0, "calibration", m.name, m.value, string(metadataJSON)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Calibrations returns the session's value calibrations, oldest first
+func (s *Session) Calibrations() ([]ValueCalibration, error) {
+	return s.db.GetCalibrations(s.info.SessionID)
+}
+
+// GetCalibrations returns a session's value calibrations, see LogCalibration
+func (m *DB) GetCalibrations(sessionID string) ([]ValueCalibration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, metric_name, value, metadata
+		FROM network_metrics
+		WHERE session_id = ? AND metric_type = 'calibration'
+		ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calibrations: %w", err)
+	}
+	defer rows.Close()
+
+	// The three metrics of one calibration share their metadata
+	var calibrations []ValueCalibration
+	for rows.Next() {
+		var episode int
+		var name, metadata string
+		var value float64
+		if err := rows.Scan(&episode, &name, &value, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan calibration row: %w", err)
+		}
+		var meta calibrationMetadata
+		if err := json.Unmarshal([]byte(metadata), &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse calibration metadata: %w", err)
+		}
+		last := len(calibrations) - 1
+		if last < 0 || calibrations[last].Episode != episode || calibrations[last].Checkpoint != meta.Checkpoint {
+			calibrations = append(calibrations, ValueCalibration{
+				Checkpoint: meta.Checkpoint,
+				Episode:    episode,
+				Gamma:      meta.Gamma,
+				Steps:      meta.Steps,
+			})
+			last++
+		}
+		switch name {
+		case "bias":
+			calibrations[last].Bias = value
+		case "rmse":
+			calibrations[last].RMSE = value
+		case "rank_correlation":
+			calibrations[last].RankCorrelation = value
+		}
+	}
+
+	return calibrations, rows.Err()
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"fmt"
+)
+
+// SessionCurve holds one session's learning curves. Index i is the
+// session's i-th episode, so curves of different sessions line up even
+// when their episode numbering differs
+type SessionCurve struct {
+	SessionID   string
+	Reward      []float64
+	BalanceTime []int
+	SuccessRate []float64 // Rolling over the comparison window
+}
+
+// SessionStats summarizes how a session ended up performing
+type SessionStats struct {
+	SessionID        string
+	Episodes         int
+	FinalAvgReward   float64 // Mean reward over the last window episodes
+	FinalSuccessRate float64 // Success rate over the last window episodes
+	FinalBalanceTime float64 // Mean balance time over the last window episodes
+	BestBalanceTime  int
+	BestEpisode      int // Ordinal of the episode with the best balance time
+}
+
+// SessionComparison lines up several sessions for side-by-side analysis
+type SessionComparison struct {
+	Window int // Episodes used for rolling and final statistics
+	Curves []SessionCurve
+	Stats  []SessionStats
+}
+
+// CompareSessions returns aligned learning curves and final-performance
+// statistics for each session, in the order given
+func (m *DB) CompareSessions(sessionIDs []string, window int) (SessionComparison, error) {
+	window = max(1, window)
+	comparison := SessionComparison{Window: window}
+
+	for _, id := range sessionIDs {
+		episodes, err := m.GetEpisodeCurve(id)
+		if err != nil {
+			return SessionComparison{}, err
+		}
+		if len(episodes) == 0 {
+			return SessionComparison{}, fmt.Errorf("session %s has no recorded episodes", id)
+		}
+
+		curve := SessionCurve{
+			SessionID:   id,
+			Reward:      make([]float64, len(episodes)),
+			BalanceTime: make([]int, len(episodes)),
+			SuccessRate: RollingSuccessRate(episodes, window),
+		}
+		stats := SessionStats{SessionID: id, Episodes: len(episodes)}
+
+		final := episodes[max(0, len(episodes)-window):]
+		for i, e := range episodes {
+			curve.Reward[i] = e.TotalReward
+			curve.BalanceTime[i] = e.BalanceTime
+			if e.BalanceTime > stats.BestBalanceTime {
+				stats.BestBalanceTime = e.BalanceTime
+				stats.BestEpisode = i
+			}
+		}
+		for _, e := range final {
+			stats.FinalAvgReward += e.TotalReward / float64(len(final))
+			stats.FinalBalanceTime += float64(e.BalanceTime) / float64(len(final))
+		}
+		stats.FinalSuccessRate = curve.SuccessRate[len(episodes)-1]
+
+		comparison.Curves = append(comparison.Curves, curve)
+		comparison.Stats = append(comparison.Stats, stats)
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RewardComponents is the sum of each term of the reward over one episode,
+// for trade-off curves between balancing, centering and control effort.
+// Penalties are positive and subtracted from Angle
+type RewardComponents struct {
+	Episode  int
+	Steps    int     // Steps the sums cover
+	Angle    float64 // Reward for the pendulum's angle
+	Position float64 // Penalty for the cart's distance from center
+	Effort   float64 // Penalty for the force and force changes
+}
+
+// Total returns the episode reward the components add up to
+func (c RewardComponents) Total() float64 {
+	return c.Angle - c.Position - c.Effort
+}
+
+// componentsMetadata is stored with each reward component metric
+type componentsMetadata struct {
+	Steps int `json:"steps"`
+}
+
+// LogRewardComponents records the current episode's reward summed per term,
+// as reward_components/angle, reward_components/position and
+// reward_components/effort
+func (l *Logger) LogRewardComponents(angle, position, effort float64, steps int) error {
+	metadataJSON, err := json.Marshal(componentsMetadata{Steps: steps})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reward component metadata: %w", err)
+	}
+	for _, m := range []struct {
+		name  string
+		value float64
+	}{
+		{"angle", angle},
+		{"position", position},
+		{"effort", effort},
+	} {
+		if err := l.recordMetric(l.sessionID, l.episode, 0, "reward_components", m.name, m.value, string(metadataJSON)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RewardComponents returns the session's per-episode reward components,
+// oldest first
+func (s *Session) RewardComponents() ([]RewardComponents, error) {
+	return s.db.GetRewardComponents(s.info.SessionID)
+}
+
+// GetRewardComponents returns a session's per-episode reward components,
+// see LogRewardComponents
+func (m *DB) GetRewardComponents(sessionID string) ([]RewardComponents, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, metric_name, value, metadata
+		FROM network_metrics
+		WHERE session_id = ? AND metric_type = 'reward_components'
+		ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reward components: %w", err)
+	}
+	defer rows.Close()
+
+	// The three metrics of one episode are recorded together
+	var episodes []RewardComponents
+	for rows.Next() {
+		var episode int
+		var name, metadata string
+		var value float64
+		if err := rows.Scan(&episode, &name, &value, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan reward component row: %w", err)
+		}
+		var meta componentsMetadata
+		if err := json.Unmarshal([]byte(metadata), &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse reward component metadata: %w", err)
+		}
+		last := len(episodes) - 1
+		if last < 0 || episodes[last].Episode != episode {
+			episodes = append(episodes, RewardComponents{Episode: episode, Steps: meta.Steps})
+			last++
+		}
+		switch name {
+		case "angle":
+			episodes[last].Angle = value
+		case "position":
+			episodes[last].Position = value
+		case "effort":
+			episodes[last].Effort = value
+		}
+	}
+
+	return episodes, rows.Err()
+}
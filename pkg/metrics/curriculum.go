@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Sources of a DifficultySummary
+const (
+	CurriculumDifficulty = "curriculum" // Difficulty each episode was sampled at, see LogCurriculum
+	TrainingDifficulty   = "training"   // The network's own difficulty, see LogUpdate
+)
+
+// DifficultySummary describes how a session's difficulty moved over its
+// episodes
+type DifficultySummary struct {
+	Source   string  // CurriculumDifficulty, TrainingDifficulty, or empty when neither was recorded
+	Episodes int     // Episodes with a recorded difficulty
+	First    float64 // Difficulty of the first such episode
+	Last     float64 // Difficulty of the last such episode
+	Min      float64
+	Max      float64
+	Mean     float64
+	Rises    int           // Episodes harder than the one before
+	Falls    int           // Episodes easier than the one before
+	Curve    []MetricPoint // Per-episode mean difficulty, oldest first
+}
+
+// LogCurriculum records the difficulty the current episode was sampled at,
+// with its initial conditions and disturbance levels as metadata
+func (l *Logger) LogCurriculum(difficulty float64, conditions map[string]float64) error {
+	metadataJSON, err := json.Marshal(conditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal curriculum metadata: %w", err)
+	}
+	return l.recordMetric(l.sessionID, l.episode, 0, "curriculum", "difficulty", difficulty, string(metadataJSON))
+}
+
+// Difficulty summarizes the session's difficulty over time: the
+// curriculum's when one recorded episodes, otherwise the network's own
+func (s *Session) Difficulty() (DifficultySummary, error) {
+	for _, source := range []string{CurriculumDifficulty, TrainingDifficulty} {
+		curve, err := s.db.GetMetricCurve(s.info.SessionID, source, "difficulty")
+		if err != nil {
+			return DifficultySummary{}, err
+		}
+		if len(curve) > 0 {
+			summary := SummarizeDifficulty(curve)
+			summary.Source = source
+			return summary, nil
+		}
+	}
+	return DifficultySummary{}, nil
+}
+
+// SummarizeDifficulty computes the range, mean and direction changes of a
+// per-episode difficulty curve
+func SummarizeDifficulty(curve []MetricPoint) DifficultySummary {
+	summary := DifficultySummary{Episodes: len(curve), Curve: curve}
+	if len(curve) == 0 {
+		return summary
+	}
+
+	summary.First, summary.Last = curve[0].Mean, curve[len(curve)-1].Mean
+	summary.Min, summary.Max = curve[0].Mean, curve[0].Mean
+	total := 0.0
+	for i, p := range curve {
+		summary.Min = min(summary.Min, p.Mean)
+		summary.Max = max(summary.Max, p.Mean)
+		total += p.Mean
+		if i == 0 {
+			continue
+		}
+		switch {
+		case p.Mean > curve[i-1].Mean:
+			summary.Rises++
+		case p.Mean < curve[i-1].Mean:
+			summary.Falls++
+		}
+	}
+	summary.Mean = total / float64(len(curve))
+	return summary
+}
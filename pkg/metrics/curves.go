@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"fmt"
+)
+
+// EpisodePoint is one recorded episode of a session
+type EpisodePoint struct {
+	Episode     int
+	TotalReward float64
+	BalanceTime int
+	Steps       int
+	Success     bool
+}
+
+// WeightPoint holds the network weights at the end of an episode
+type WeightPoint struct {
+	Episode          int
+	AngleWeight      float64
+	AngularVelWeight float64
+	Bias             float64
+	LearningRate     float64
+}
+
+// MetricPoint aggregates one metric over an episode
+type MetricPoint struct {
+	Episode int
+	Mean    float64
+	MeanAbs float64
+	Count   int
+}
+
+// GetEpisodeCurve returns a session's episodes in order
+func (m *DB) GetEpisodeCurve(sessionID string) ([]EpisodePoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, total_reward, balance_time, steps, success
+		FROM training_episodes
+		WHERE session_id = ?
+		ORDER BY episode, id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var points []EpisodePoint
+	for rows.Next() {
+		var p EpisodePoint
+		if err := rows.Scan(&p.Episode, &p.TotalReward, &p.BalanceTime, &p.Steps, &p.Success); err != nil {
+			return nil, fmt.Errorf("failed to scan episode row: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// GetWeightCurve returns the last recorded weights of each episode in a session
+func (m *DB) GetWeightCurve(sessionID string) ([]WeightPoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, angle_weight, angular_vel_weight, bias, learning_rate
+		FROM network_weights
+		WHERE id IN (
+			SELECT MAX(id) FROM network_weights WHERE session_id = ? GROUP BY episode
+		)
+		ORDER BY episode
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weights: %w", err)
+	}
+	defer rows.Close()
+
+	var points []WeightPoint
+	for rows.Next() {
+		var p WeightPoint
+		if err := rows.Scan(&p.Episode, &p.AngleWeight, &p.AngularVelWeight, &p.Bias, &p.LearningRate); err != nil {
+			return nil, fmt.Errorf("failed to scan weight row: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// GetMetricCurve returns the per-episode mean of a metric, e.g. learning/td_error
+func (m *DB) GetMetricCurve(sessionID, metricType, metricName string) ([]MetricPoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, AVG(value), AVG(ABS(value)), COUNT(*)
+		FROM network_metrics
+		WHERE session_id = ? AND metric_type = ? AND metric_name = ?
+		GROUP BY episode
+		ORDER BY episode
+	`, sessionID, metricType, metricName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s/%s: %w", metricType, metricName, err)
+	}
+	defer rows.Close()
+
+	var points []MetricPoint
+	for rows.Next() {
+		var p MetricPoint
+		if err := rows.Scan(&p.Episode, &p.Mean, &p.MeanAbs, &p.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan metric row: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// RollingSuccessRate returns, for each episode, the success rate over the
+// last window episodes up to and including it
+func RollingSuccessRate(points []EpisodePoint, window int) []float64 {
+	window = max(1, window)
+	rates := make([]float64, len(points))
+	successes := 0
+	for i, p := range points {
+		if p.Success {
+			successes++
+		}
+		if i >= window && points[i-window].Success {
+			successes--
+		}
+		rates[i] = float64(successes) / float64(min(i+1, window))
+	}
+	return rates
+}
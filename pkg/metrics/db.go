@@ -7,8 +7,6 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3" // SQLite driver
 	"math"
 )
 
@@ -28,7 +26,7 @@ func NewDB(dbPath string) (*DB, error) {
 	}
 
 	// Open database connection
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(driverName, dataSourceName(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -39,8 +37,8 @@ func NewDB(dbPath string) (*DB, error) {
 		dbPath: dbPath,
 	}
 
-	// Initialize schema
-	if err := metricsDB.initSchema(); err != nil {
+	// Bring schema up to date
+	if err := metricsDB.migrate(); err != nil {
 		db.Close()
 		return nil, err
 	}
@@ -53,77 +51,6 @@ func (m *DB) Close() error {
 	return m.db.Close()
 }
 
-// initSchema creates the necessary tables if they don't exist
-func (m *DB) initSchema() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	// Create network_metrics table
-	_, err := m.db.Exec(`
-		CREATE TABLE IF NOT EXISTS network_metrics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			session_id TEXT,
-			episode INTEGER,
-			step INTEGER,
-			metric_type TEXT,
-			metric_name TEXT,
-			value REAL,
-			metadata TEXT
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create network_metrics table: %w", err)
-	}
-
-	// Create network_weights table
-	_, err = m.db.Exec(`
-		CREATE TABLE IF NOT EXISTS network_weights (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			session_id TEXT,
-			episode INTEGER,
-			angle_weight REAL,
-			angular_vel_weight REAL,
-			bias REAL,
-			learning_rate REAL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create network_weights table: %w", err)
-	}
-
-	// Create training_episodes table
-	_, err = m.db.Exec(`
-		CREATE TABLE IF NOT EXISTS training_episodes (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			session_id TEXT,
-			episode INTEGER,
-			total_reward REAL,
-			balance_time INTEGER,
-			max_angle REAL,
-			steps INTEGER,
-			success BOOLEAN
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create training_episodes table: %w", err)
-	}
-
-	// Create indices for faster queries
-	_, err = m.db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_network_metrics_session_episode ON network_metrics(session_id, episode);
-		CREATE INDEX IF NOT EXISTS idx_network_weights_session_episode ON network_weights(session_id, episode);
-		CREATE INDEX IF NOT EXISTS idx_training_episodes_session ON training_episodes(session_id);
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create indices: %w", err)
-	}
-
-	return nil
-}
-
 // RecordMetric records a single metric value
 func (m *DB) RecordMetric(sessionID string, episode, step int, metricType, metricName string, value float64, metadata string) error {
 	m.mu.Lock()
@@ -230,6 +157,20 @@ func (m *DB) GetSessionSummary(sessionID string) (map[string]interface{}, error)
 	}
 	result["avg_balance_time"] = avgBalanceTime
 
+	// Get the compute budget; sessions recorded before it was tracked report zeros
+	var wallClock float64
+	var steps, forwardPasses, backwardPasses int64
+	err = m.db.QueryRow(`
+		SELECT wall_clock_seconds, steps, forward_passes, backward_passes FROM sessions WHERE session_id = ?
+	`, sessionID).Scan(&wallClock, &steps, &forwardPasses, &backwardPasses)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get compute budget: %w", err)
+	}
+	result["wall_clock_seconds"] = wallClock
+	result["steps"] = steps
+	result["forward_passes"] = forwardPasses
+	result["backward_passes"] = backwardPasses
+
 	// Get first and last weights to measure learning progress
 	var firstEpisode, lastEpisode int
 	err = m.db.QueryRow(`
@@ -467,7 +408,9 @@ func (m *DB) GetLearningProgress(sessionID string, lastNEpisodes int) (map[strin
 	return result, nil
 }
 
-// GetPredictionAccuracy analyzes how well the network's predictions match actual outcomes
+// GetPredictionAccuracy compares an episode's value predictions with each
+// step's immediate reward. Values estimate discounted returns, so see
+// GetCalibrations for how well they match the returns actually realized
 func (m *DB) GetPredictionAccuracy(sessionID string, episode int) (map[string]interface{}, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -530,61 +473,28 @@ func (m *DB) GetPredictionAccuracy(sessionID string, episode int) (map[string]in
 
 // GetWeightChangeAnalysis provides detailed analysis of how weights change in response to inputs
 func (m *DB) GetWeightChangeAnalysis(sessionID string, episode int) (map[string]interface{}, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	var result map[string]interface{} = make(map[string]interface{})
-
-	// Get input values and corresponding weight updates
-	rows, err := m.db.Query(`
-		SELECT 
-			m1.step, 
-			m1.value as angle, 
-			m2.value as angular_vel,
-			m3.value as angle_update,
-			m4.value as angular_vel_update,
-			m5.value as bias_update,
-			m6.value as reward
-		FROM network_metrics m1
-		JOIN network_metrics m2 ON m1.session_id = m2.session_id AND m1.episode = m2.episode AND m1.step = m2.step
-		JOIN network_metrics m3 ON m1.session_id = m3.session_id AND m1.episode = m3.episode AND m1.step = m3.step
-		JOIN network_metrics m4 ON m1.session_id = m4.session_id AND m1.episode = m4.episode AND m1.step = m4.step
-		JOIN network_metrics m5 ON m1.session_id = m5.session_id AND m1.episode = m5.episode AND m1.step = m5.step
-		JOIN network_metrics m6 ON m1.session_id = m6.session_id AND m1.episode = m6.episode AND m1.step = m6.step
-		WHERE m1.session_id = ? 
-			AND m1.episode = ? 
-			AND m1.metric_type = 'input' AND m1.metric_name = 'angle'
-			AND m2.metric_type = 'input' AND m2.metric_name = 'angular_vel'
-			AND m3.metric_type = 'update' AND m3.metric_name = 'angle_weight'
-			AND m4.metric_type = 'update' AND m4.metric_name = 'angular_vel_weight'
-			AND m5.metric_type = 'update' AND m5.metric_name = 'bias'
-			AND m6.metric_type = 'reward' AND m6.metric_name = 'immediate'
-		ORDER BY m1.step
-	`, sessionID, episode)
-	
+	trace, err := m.GetStepTrace(sessionID, episode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get weight update data: %w", err)
 	}
-	defer rows.Close()
 
+	var result map[string]interface{} = make(map[string]interface{})
 	var updates []map[string]interface{}
 
-	for rows.Next() {
-		var step int
-		var angle, angularVel, angleUpdate, angularVelUpdate, biasUpdate, reward float64
-		if err := rows.Scan(&step, &angle, &angularVel, &angleUpdate, &angularVelUpdate, &biasUpdate, &reward); err != nil {
-			return nil, fmt.Errorf("failed to scan update row: %w", err)
+	// Each step's inputs, reward and updates share one trace row
+	for _, s := range trace {
+		if !s.Updated {
+			continue
 		}
-
 		updates = append(updates, map[string]interface{}{
-			"step":                step,
-			"angle":               angle,
-			"angular_vel":         angularVel,
-			"angle_weight_update": angleUpdate,
-			"angular_vel_weight_update": angularVelUpdate,
-			"bias_update":         biasUpdate,
-			"reward":              reward,
-			"update_magnitude":    math.Abs(angleUpdate) + math.Abs(angularVelUpdate) + math.Abs(biasUpdate),
+			"step":                s.Step,
+			"angle":               s.Angle,
+			"angular_vel":         s.AngularVel,
+			"angle_weight_update": s.AngleUpdate,
+			"angular_vel_weight_update": s.AngularVelUpdate,
+			"bias_update":         s.BiasUpdate,
+			"reward":              s.Reward,
+			"update_magnitude":    s.UpdateMagnitude(),
 		})
 	}
 
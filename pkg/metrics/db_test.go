@@ -0,0 +1,688 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestMigrations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metrics.db")
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if want := migrations[len(migrations)-1].version; version != want {
+		t.Errorf("schema version = %d, want %d", version, want)
+	}
+	db.Close()
+
+	// Reopening must not re-apply migrations
+	db, err = NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	var applied int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&applied); err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("applied %d migrations, want %d", applied, len(migrations))
+	}
+}
+
+func TestSessions(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.StartSession(SessionInfo{SessionID: "session_a", GitHash: "abc123"}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if err := db.UpdateSessionConfig("session_a", `{"batch_size":32}`, `{"lr":0.05}`); err != nil {
+		t.Fatalf("UpdateSessionConfig failed: %v", err)
+	}
+	if err := db.EndSession("session_a"); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+
+	info, err := db.GetSession("session_a")
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if info.GitHash != "abc123" {
+		t.Errorf("GitHash = %q, want %q", info.GitHash, "abc123")
+	}
+	if info.Hyperparameters != `{"lr":0.05}` {
+		t.Errorf("Hyperparameters = %q, want %q", info.Hyperparameters, `{"lr":0.05}`)
+	}
+	if info.EndTime.IsZero() {
+		t.Error("EndTime not recorded")
+	}
+
+	// The budget is a running total, so each update replaces the last
+	budget := ComputeBudget{WallClock: 1500 * time.Millisecond, Steps: 300, ForwardPasses: 900, BackwardPasses: 250}
+	for _, b := range []ComputeBudget{{Steps: 100}, budget} {
+		if err := db.UpdateSessionBudget("session_a", b); err != nil {
+			t.Fatalf("UpdateSessionBudget failed: %v", err)
+		}
+	}
+	if info, _ := db.GetSession("session_a"); info.Budget != budget {
+		t.Errorf("Budget = %+v, want %+v", info.Budget, budget)
+	}
+	if got := budget.StepsPerSecond(); got != 200 {
+		t.Errorf("StepsPerSecond = %v, want 200", got)
+	}
+
+	// Resuming a session clears its end time until it ends again
+	if err := db.ReopenSession("session_a"); err != nil {
+		t.Fatalf("ReopenSession failed: %v", err)
+	}
+	if info, _ := db.GetSession("session_a"); !info.EndTime.IsZero() {
+		t.Error("EndTime still set after ReopenSession")
+	}
+	if err := db.ReopenSession("missing"); err == nil {
+		t.Error("expected error reopening an unknown session")
+	}
+}
+
+func TestEvaluations(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for i, checkpoint := range []string{"checkpoint_3.json", "checkpoint_5.json"} {
+		if err := db.RecordEvaluation(Evaluation{
+			SessionID:   "compare_a",
+			Checkpoint:  checkpoint,
+			Suite:       "standard",
+			AvgReward:   float64(i),
+			SuccessRate: 0.5,
+		}); err != nil {
+			t.Fatalf("RecordEvaluation failed: %v", err)
+		}
+	}
+
+	evaluations, err := db.GetEvaluations("compare_a")
+	if err != nil {
+		t.Fatalf("GetEvaluations failed: %v", err)
+	}
+	if len(evaluations) != 2 {
+		t.Fatalf("got %d evaluations, want 2", len(evaluations))
+	}
+	if evaluations[1].Checkpoint != "checkpoint_5.json" || evaluations[1].AvgReward != 1 {
+		t.Errorf("unexpected evaluation: %+v", evaluations[1])
+	}
+}
+
+func TestCurves(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for episode := 0; episode < 3; episode++ {
+		if err := db.RecordEpisode("session_a", episode, float64(episode), 10*episode, 0.1, 100, episode == 2); err != nil {
+			t.Fatalf("RecordEpisode failed: %v", err)
+		}
+		// Only the last weights of each episode belong on the curve
+		for i := 0; i < 2; i++ {
+			if err := db.RecordWeights("session_a", episode, float64(episode+i), 0, 0, 0.01); err != nil {
+				t.Fatalf("RecordWeights failed: %v", err)
+			}
+		}
+		for _, tdError := range []float64{-1, 3} {
+			if err := db.RecordMetric("session_a", episode, 0, "learning", "td_error", tdError, ""); err != nil {
+				t.Fatalf("RecordMetric failed: %v", err)
+			}
+		}
+	}
+	if err := db.RecordEpisode("session_b", 0, 99, 0, 0, 0, false); err != nil {
+		t.Fatalf("RecordEpisode failed: %v", err)
+	}
+
+	episodes, err := db.GetEpisodeCurve("session_a")
+	if err != nil {
+		t.Fatalf("GetEpisodeCurve failed: %v", err)
+	}
+	if len(episodes) != 3 || episodes[2].BalanceTime != 20 || !episodes[2].Success {
+		t.Errorf("unexpected episode curve: %+v", episodes)
+	}
+
+	weights, err := db.GetWeightCurve("session_a")
+	if err != nil {
+		t.Fatalf("GetWeightCurve failed: %v", err)
+	}
+	if len(weights) != 3 || weights[1].AngleWeight != 2 {
+		t.Errorf("unexpected weight curve: %+v", weights)
+	}
+
+	tdErrors, err := db.GetMetricCurve("session_a", "learning", "td_error")
+	if err != nil {
+		t.Fatalf("GetMetricCurve failed: %v", err)
+	}
+	if len(tdErrors) != 3 || tdErrors[0].Mean != 1 || tdErrors[0].MeanAbs != 2 || tdErrors[0].Count != 2 {
+		t.Errorf("unexpected td error curve: %+v", tdErrors)
+	}
+}
+
+func TestCompareSessions(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// session_a improves over 6 episodes, session_b numbers its 3 from 1
+	for i := 0; i < 6; i++ {
+		if err := db.RecordEpisode("session_a", i, float64(i), 10*i, 0, 100, i >= 4); err != nil {
+			t.Fatalf("RecordEpisode failed: %v", err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		if err := db.RecordEpisode("session_b", i, 1, 5, 0, 100, false); err != nil {
+			t.Fatalf("RecordEpisode failed: %v", err)
+		}
+	}
+
+	comparison, err := db.CompareSessions([]string{"session_a", "session_b"}, 2)
+	if err != nil {
+		t.Fatalf("CompareSessions failed: %v", err)
+	}
+	if len(comparison.Curves) != 2 || len(comparison.Stats) != 2 {
+		t.Fatalf("got %d curves and %d stats, want 2 each", len(comparison.Curves), len(comparison.Stats))
+	}
+
+	a, b := comparison.Stats[0], comparison.Stats[1]
+	if a.Episodes != 6 || a.FinalAvgReward != 4.5 || a.FinalSuccessRate != 1 || a.BestBalanceTime != 50 || a.BestEpisode != 5 {
+		t.Errorf("unexpected stats for session_a: %+v", a)
+	}
+	if b.Episodes != 3 || b.FinalSuccessRate != 0 {
+		t.Errorf("unexpected stats for session_b: %+v", b)
+	}
+	if got := comparison.Curves[0].SuccessRate; got[3] != 0 || got[4] != 0.5 || got[5] != 1 {
+		t.Errorf("rolling success rate = %v", got)
+	}
+	if got := comparison.Curves[1].Reward; len(got) != 3 || got[0] != 1 {
+		t.Errorf("session_b rewards not aligned from its first episode: %v", got)
+	}
+
+	if _, err := db.CompareSessions([]string{"missing"}, 2); err == nil {
+		t.Error("Expected error for a session without episodes")
+	}
+}
+
+func TestGenerations(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Genome 10 is bred from initial genome 0, then 12 from 10 and 11
+	first := GenerationStats{Generation: 1, Fitness: "ticks", Population: 4, BestFitness: 50, MeanFitness: 20, StdFitness: 15, BestGenome: 0, Mutations: 1}
+	if err := db.RecordGeneration("session", first, []Lineage{
+		{Genome: 10, Generation: 1, Parent: 0, SecondParent: NoParent, ParentFitness: 50, Origin: "mutation"},
+	}); err != nil {
+		t.Fatalf("RecordGeneration failed: %v", err)
+	}
+	second := GenerationStats{Generation: 2, Fitness: "ticks", Population: 4, BestFitness: 80, MeanFitness: 40, StdFitness: 25, BestGenome: 12, Mutations: 1, Crossovers: 1}
+	if err := db.RecordGeneration("session", second, []Lineage{
+		{Genome: 11, Generation: 2, Parent: 0, SecondParent: NoParent, ParentFitness: 60, Origin: "mutation"},
+		{Genome: 12, Generation: 2, Parent: 10, SecondParent: 0, ParentFitness: 70, Origin: "crossover"},
+	}); err != nil {
+		t.Fatalf("RecordGeneration failed: %v", err)
+	}
+
+	generations, err := db.GetGenerations("session")
+	if err != nil {
+		t.Fatalf("GetGenerations failed: %v", err)
+	}
+	if len(generations) != 2 || generations[0] != first || generations[1] != second {
+		t.Errorf("GetGenerations = %+v, want %+v and %+v", generations, first, second)
+	}
+
+	ancestry, err := db.GetAncestry("session", 12)
+	if err != nil {
+		t.Fatalf("GetAncestry failed: %v", err)
+	}
+	if len(ancestry) != 2 || ancestry[0].Genome != 12 || ancestry[0].SecondParent != 0 || ancestry[1].Genome != 10 || ancestry[1].Parent != 0 {
+		t.Errorf("GetAncestry(12) = %+v, want genomes 12 then 10 back to initial genome 0", ancestry)
+	}
+
+	if ancestry, err := db.GetAncestry("session", 0); err != nil || len(ancestry) != 0 {
+		t.Errorf("GetAncestry of an initial genome = %+v, %v; want no records", ancestry, err)
+	}
+}
+
+func TestBenchmarks(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for _, b := range []Benchmark{
+		{GitHash: "abc123", Name: "physics", Steps: 1000, Seconds: 0.01, StepsPerSec: 100000},
+		{GitHash: "abc123", Name: "trainer", Steps: 100, Seconds: 0.01, StepsPerSec: 10000},
+		{GitHash: "def456", Name: "physics", Steps: 2000, Seconds: 0.01, StepsPerSec: 200000},
+	} {
+		if err := db.RecordBenchmark(b); err != nil {
+			t.Fatalf("RecordBenchmark failed: %v", err)
+		}
+	}
+
+	physics, err := db.GetBenchmarks("physics")
+	if err != nil {
+		t.Fatalf("GetBenchmarks failed: %v", err)
+	}
+	if len(physics) != 2 || physics[0].GitHash != "abc123" || physics[1].StepsPerSec != 200000 {
+		t.Errorf("unexpected physics benchmarks: %+v", physics)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// A finished session with six steps of metrics and traces, one with an
+	// episode still running, and one that never got past starting
+	for _, id := range []string{"finished", "running", "abandoned"} {
+		if err := db.StartSession(SessionInfo{SessionID: id}); err != nil {
+			t.Fatalf("StartSession failed: %v", err)
+		}
+	}
+	if err := db.EndSession("finished"); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	for step := 0; step <= 6; step++ {
+		if err := db.RecordMetric("finished", 0, step, "learning", "td_error", 1, ""); err != nil {
+			t.Fatalf("RecordMetric failed: %v", err)
+		}
+		if err := db.recordStep(stepRow{SessionID: "finished", Step: step}); err != nil {
+			t.Fatalf("recordStep failed: %v", err)
+		}
+	}
+	if err := db.RecordMetric("finished", 0, 5, "reward", "shaped", 1, `{"aggregate":true,"count":5}`); err != nil {
+		t.Fatalf("RecordMetric failed: %v", err)
+	}
+	if err := db.RecordEpisode("running", 0, 1, 10, 0.1, 10, false); err != nil {
+		t.Fatalf("RecordEpisode failed: %v", err)
+	}
+	if err := db.RecordMetric("abandoned", 0, 1, "learning", "td_error", 1, ""); err != nil {
+		t.Fatalf("RecordMetric failed: %v", err)
+	}
+
+	t.Run("keeps recent data", func(t *testing.T) {
+		stats, err := db.Prune("", time.Hour, 3)
+		if err != nil {
+			t.Fatalf("Prune failed: %v", err)
+		}
+		if stats != (PruneStats{}) {
+			t.Errorf("Prune of data newer than the cutoff = %+v, want nothing removed", stats)
+		}
+	})
+
+	t.Run("down-samples and deletes abandoned sessions", func(t *testing.T) {
+		stats, err := db.Prune("", 0, 3)
+		if err != nil {
+			t.Fatalf("Prune failed: %v", err)
+		}
+		// Steps 1, 2, 4 and 5 go; step 0, 3, 6 and the aggregate stay
+		want := PruneStats{StepMetrics: 4, StepTraces: 4, Sessions: 1}
+		if stats != want {
+			t.Errorf("Prune = %+v, want %+v", stats, want)
+		}
+		if err := db.Vacuum(); err != nil {
+			t.Fatalf("Vacuum failed: %v", err)
+		}
+
+		trace, err := db.GetStepTrace("finished", 0)
+		if err != nil {
+			t.Fatalf("GetStepTrace failed: %v", err)
+		}
+		var steps []int
+		for _, s := range trace {
+			steps = append(steps, s.Step)
+		}
+		if fmt.Sprint(steps) != "[0 3 6]" {
+			t.Errorf("kept trace steps %v, want [0 3 6]", steps)
+		}
+		if aggregate, err := db.GetMetricCurve("finished", "reward", "shaped"); err != nil || len(aggregate) != 1 {
+			t.Errorf("aggregate curve = %+v, %v; want the aggregate kept", aggregate, err)
+		}
+
+		sessions, err := db.ListSessions()
+		if err != nil {
+			t.Fatalf("ListSessions failed: %v", err)
+		}
+		var ids []string
+		for _, s := range sessions {
+			ids = append(ids, s.SessionID)
+		}
+		if fmt.Sprint(ids) != "[finished running]" {
+			t.Errorf("sessions after prune = %v, want [finished running]", ids)
+		}
+	})
+
+	if _, err := db.Prune("", 0, 0); err == nil {
+		t.Error("Prune with keepEveryNth 0 succeeded, want error")
+	}
+}
+
+func TestOpenSession(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.StartSession(SessionInfo{SessionID: "session", GitHash: "abc123"}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := OpenSession(db, "missing"); err == nil {
+		t.Error("OpenSession of an unknown session succeeded, want error")
+	}
+	session, err := OpenSession(db, "session")
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+	if session.GetSessionID() != "session" || session.Info().GitHash != "abc123" {
+		t.Errorf("opened %+v, want session recorded at abc123", session.Info())
+	}
+	if _, err := session.LatestEpisode(); err == nil {
+		t.Error("LatestEpisode of an empty session succeeded, want error")
+	}
+
+	if err := db.RecordEpisode("session", 2, 1, 10, 0.1, 10, true); err != nil {
+		t.Fatalf("RecordEpisode failed: %v", err)
+	}
+	if err := db.RecordWeights("session", 2, 0.1, 0.2, 0.3, 0.01); err != nil {
+		t.Fatalf("RecordWeights failed: %v", err)
+	}
+	if err := db.RecordMetric("session", 3, 1, "learning", "td_error", 0.5, ""); err != nil {
+		t.Fatalf("RecordMetric failed: %v", err)
+	}
+	if latest, err := session.LatestEpisode(); err != nil || latest != 3 {
+		t.Errorf("LatestEpisode = %d, %v; want 3", latest, err)
+	}
+
+	// Analyses read without recording anything or ending the session
+	if _, err := session.Summary(); err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if _, err := session.LearningIssues(); err != nil {
+		t.Fatalf("LearningIssues failed: %v", err)
+	}
+	var rows int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM network_metrics`).Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("network_metrics has %d rows after analysis (%v), want 1", rows, err)
+	}
+	if info, err := db.GetSession("session"); err != nil || !info.EndTime.IsZero() {
+		t.Errorf("session after analysis = %+v, %v; want still running", info, err)
+	}
+}
+
+func TestValueSnapshots(t *testing.T) {
+	grid := ValueGrid{AngleRange: 1, VelRange: 2, AngleSteps: 3, VelSteps: 2}
+	points := grid.Sample(func(angle, angularVel float64) float64 { return angle + 10*angularVel })
+	if len(points) != 6 || points[0] != (ValuePoint{-1, -2, -21}) || points[5] != (ValuePoint{1, 2, 21}) {
+		t.Fatalf("Sample = %+v, want 3 angles by 2 velocities from (-1, -2) to (1, 2)", points)
+	}
+
+	t.Run("database", func(t *testing.T) {
+		db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+		if err != nil {
+			t.Fatalf("Failed to create database: %v", err)
+		}
+		defer db.Close()
+
+		for _, episode := range []int{10, 0} {
+			if err := db.RecordValueSnapshot("session", episode, points); err != nil {
+				t.Fatalf("RecordValueSnapshot failed: %v", err)
+			}
+		}
+		snapshots, err := db.GetValueSnapshots("session")
+		if err != nil {
+			t.Fatalf("GetValueSnapshots failed: %v", err)
+		}
+		if len(snapshots) != 2 || snapshots[0].Episode != 0 || snapshots[1].Episode != 10 {
+			t.Fatalf("snapshots = %+v, want episodes 0 and 10", snapshots)
+		}
+		if fmt.Sprint(snapshots[1].Points) != fmt.Sprint(points) {
+			t.Errorf("points = %v, want %v in grid order", snapshots[1].Points, points)
+		}
+	})
+
+	t.Run("logger", func(t *testing.T) {
+		store := NewMemoryStore()
+		logger, err := NewStoreLogger(store, false, nil)
+		if err != nil {
+			t.Fatalf("NewStoreLogger failed: %v", err)
+		}
+		defer logger.Close()
+
+		value := func(angle, angularVel float64) float64 { return -angle * angle }
+		if err := logger.LogValueLandscape(value); err != nil {
+			t.Fatalf("LogValueLandscape failed: %v", err)
+		}
+		logger.SetValueSnapshots(5, grid)
+		for episode := 0; episode <= 10; episode++ {
+			logger.SetEpisode(episode)
+			if err := logger.LogValueLandscape(value); err != nil {
+				t.Fatalf("LogValueLandscape failed: %v", err)
+			}
+		}
+
+		snapshots, _ := store.GetValueSnapshots(logger.GetSessionID())
+		var episodes []int
+		for _, s := range snapshots {
+			episodes = append(episodes, s.Episode)
+			if len(s.Points) != 6 {
+				t.Errorf("episode %d snapshot has %d points, want 6", s.Episode, len(s.Points))
+			}
+		}
+		if fmt.Sprint(episodes) != "[0 5 10]" {
+			t.Errorf("snapshots taken at episodes %v, want [0 5 10]", episodes)
+		}
+	})
+}
+
+func TestReport(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.StartSession(SessionInfo{SessionID: "session", ConfigJSON: `{"gamma":0.99}`}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	for episode := 0; episode < 6; episode++ {
+		if err := db.RecordEpisode("session", episode, float64(episode), 10*episode, 0.1, 10*episode, episode >= 3); err != nil {
+			t.Fatalf("RecordEpisode failed: %v", err)
+		}
+		if err := db.RecordWeights("session", episode, 0, 0, 0, 0.01); err != nil {
+			t.Fatalf("RecordWeights failed: %v", err)
+		}
+	}
+	operations := []struct {
+		value    float64
+		metadata string
+	}{
+		{1, `{"operation":"checkpoint","path":"checkpoints/ep5.json"}`},
+		{0, `{"operation":"checkpoint","path":"checkpoints/failed.json"}`},
+		{1, `{"operation":"load","path":"network.json"}`},
+	}
+	for _, op := range operations {
+		if err := db.RecordMetric("session", 5, 0, "system", "network_operation", op.value, op.metadata); err != nil {
+			t.Fatalf("RecordMetric failed: %v", err)
+		}
+	}
+
+	session, err := OpenSession(db, "session")
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+	report, err := session.Report(3)
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.Stats.Episodes != 6 || report.Stats.FinalSuccessRate != 1 {
+		t.Errorf("stats = %+v, want 6 episodes ending at 100%% success", report.Stats)
+	}
+	if report.Weights == nil || report.Weights.Episode != 5 {
+		t.Errorf("final weights = %+v, want episode 5", report.Weights)
+	}
+	if len(report.Issues) == 0 {
+		t.Error("unchanging weights were not reported as an issue")
+	}
+	if len(report.Checkpoints) != 1 || report.Checkpoints[0].Path != "checkpoints/ep5.json" {
+		t.Errorf("checkpoints = %+v, want only the successful checkpoint", report.Checkpoints)
+	}
+
+	var markdown, html strings.Builder
+	if err := report.WriteMarkdown(&markdown); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	for _, want := range []string{"# Training report: session", "data:image/svg+xml;base64,", "[checkpoints/ep5.json](checkpoints/ep5.json)", `"gamma": 0.99`} {
+		if !strings.Contains(markdown.String(), want) {
+			t.Errorf("Markdown report is missing %q", want)
+		}
+	}
+	for _, want := range []string{"<svg", "<polyline", `<a href="checkpoints/ep5.json">`, "Weight stagnation"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML report is missing %q", want)
+		}
+	}
+}
+
+func TestFailures(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	logger, err := NewStoreLogger(db, false, nil)
+	if err != nil {
+		t.Fatalf("NewStoreLogger failed: %v", err)
+	}
+	defer logger.Close()
+	endings := []struct {
+		mode  env.FailureMode
+		final env.State
+	}{
+		{env.FellClockwise, env.State{AngleRadians: 0.6}},
+		{env.RightEdge, env.State{CartPosition: 1.2}},
+		{env.FellCounterClockwise, env.State{AngleRadians: 2*math.Pi - 0.6}},
+		{env.FellClockwise, env.State{AngleRadians: 0.7}},
+		{env.Timeout, env.State{AngleRadians: 0.1}},
+	}
+	for i, e := range endings {
+		logger.SetEpisode(i)
+		if err := logger.LogFailure(e.mode, 100+i, e.final); err != nil {
+			t.Fatalf("LogFailure failed: %v", err)
+		}
+	}
+
+	session, err := OpenSession(db, logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+	failures, err := session.Failures()
+	if err != nil {
+		t.Fatalf("Failures failed: %v", err)
+	}
+	if len(failures) != len(endings) {
+		t.Fatalf("got %d failures, want %d", len(failures), len(endings))
+	}
+	if f := failures[2]; f.Episode != 2 || f.Step != 102 || f.Mode != env.FellCounterClockwise || math.Abs(f.Angle+0.6) > 1e-9 {
+		t.Errorf("failure 2 = %+v, want episode 2 falling toward -x at offset -0.6", f)
+	}
+
+	summary, err := session.FailureSummary()
+	if err != nil {
+		t.Fatalf("FailureSummary failed: %v", err)
+	}
+	if summary.Episodes != 5 || summary.Angular != 3 || summary.Positional != 1 || summary.Dominant != AngularFailures {
+		t.Errorf("summary = %+v, want 3 angular and 1 positional of 5, angular dominant", summary)
+	}
+	if top := summary.Counts[0]; top.Mode != env.FellClockwise || top.Count != 2 || top.Share != 0.4 {
+		t.Errorf("most frequent = %+v, want fell_clockwise twice", top)
+	}
+
+	if empty := SummarizeFailures(nil); empty.Dominant != "" || len(empty.Counts) != 0 {
+		t.Errorf("empty summary = %+v, want no dominant kind", empty)
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	logger, err := NewStoreLogger(db, false, nil)
+	if err != nil {
+		t.Fatalf("NewStoreLogger failed: %v", err)
+	}
+	defer logger.Close()
+	session, err := OpenSession(db, logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+
+	if summary, err := session.Difficulty(); err != nil || summary.Source != "" || summary.Episodes != 0 {
+		t.Errorf("Difficulty() = %+v, %v before any episodes, want an empty summary", summary, err)
+	}
+
+	// The network's own difficulty is the fallback
+	logger.SetEpisode(1)
+	if err := logger.LogUpdate(0.1, 0, 0, 0, 0.3, 0.5); err != nil {
+		t.Fatalf("LogUpdate failed: %v", err)
+	}
+	if summary, _ := session.Difficulty(); summary.Source != TrainingDifficulty || summary.Last != 0.3 {
+		t.Errorf("summary = %+v, want the training difficulty 0.3", summary)
+	}
+
+	for i, difficulty := range []float64{0.1, 0.2, 0.4, 0.3} {
+		logger.SetEpisode(i + 1)
+		if err := logger.LogCurriculum(difficulty, map[string]float64{"wind_noise": difficulty}); err != nil {
+			t.Fatalf("LogCurriculum failed: %v", err)
+		}
+	}
+	summary, err := session.Difficulty()
+	if err != nil {
+		t.Fatalf("Difficulty failed: %v", err)
+	}
+	if summary.Source != CurriculumDifficulty || summary.Episodes != 4 || summary.First != 0.1 || summary.Last != 0.3 ||
+		summary.Max != 0.4 || summary.Rises != 2 || summary.Falls != 1 || math.Abs(summary.Mean-0.25) > 1e-9 {
+		t.Errorf("summary = %+v, want the curriculum's 4 episodes from 0.1 to 0.3", summary)
+	}
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// DefaultDiscounts are the discounts a sweep tries unless told otherwise
+var DefaultDiscounts = []float64{0, 0.5, 0.8, 0.9, 0.95, 0.97, 0.99, 0.995}
+
+// DiscountFit is how closely the returns under one discount track the
+// value predictions logged in the same steps
+type DiscountFit struct {
+	Gamma       float64
+	Correlation float64 // Pearson correlation of returns and predicted values, in [-1, 1]
+}
+
+// DiscountSweep compares the returns recomputed from logged rewards under
+// several discounts with the logged value predictions. The discount whose
+// returns correlate best is the horizon the learned value function actually
+// matches; far from the trained discount, the TD targets are mis-specified
+type DiscountSweep struct {
+	Episodes int           // Episodes whose every step was logged
+	Skipped  int           // Episodes with missing steps, e.g. from sampled step logging
+	Steps    int           // Steps compared
+	Trained  float64       // Discount of the logged TD targets, 0 when none were logged
+	Fits     []DiscountFit // One per discount swept, in the order given
+	Best     DiscountFit   // The fit with the highest correlation
+}
+
+// DiscountedReturns returns, for every step of an episode, the sum of the
+// rewards from that step to the end discounted by gamma per step
+func DiscountedReturns(rewards []float64, gamma float64) []float64 {
+	returns := make([]float64, len(rewards))
+	future := 0.0
+	for i := len(rewards) - 1; i >= 0; i-- {
+		future = rewards[i] + gamma*future
+		returns[i] = future
+	}
+	return returns
+}
+
+// SweepDiscounts recomputes each episode's returns under every gamma and
+// correlates them with the episode's logged state values. Episodes with
+// gaps between logged steps are skipped, since their returns would be
+// missing rewards
+func SweepDiscounts(episodes [][]StepTrace, gammas []float64) DiscountSweep {
+	var sweep DiscountSweep
+	var rewards, values [][]float64
+	for _, trace := range episodes {
+		if len(trace) == 0 {
+			continue
+		}
+		if trace[len(trace)-1].Step-trace[0].Step != len(trace)-1 {
+			sweep.Skipped++
+			continue
+		}
+		r := make([]float64, len(trace))
+		v := make([]float64, len(trace))
+		for i, s := range trace {
+			r[i], v[i] = s.Reward, s.StateValue
+		}
+		rewards = append(rewards, r)
+		values = append(values, v)
+		sweep.Episodes++
+		sweep.Steps += len(trace)
+	}
+
+	var predicted []float64
+	for _, v := range values {
+		predicted = append(predicted, v...)
+	}
+	for _, gamma := range gammas {
+		returns := make([]float64, 0, sweep.Steps)
+		for _, r := range rewards {
+			returns = append(returns, DiscountedReturns(r, gamma)...)
+		}
+		fit := DiscountFit{Gamma: gamma, Correlation: correlation(returns, predicted)}
+		sweep.Fits = append(sweep.Fits, fit)
+		if len(sweep.Fits) == 1 || fit.Correlation > sweep.Best.Correlation {
+			sweep.Best = fit
+		}
+	}
+	return sweep
+}
+
+// DiscountSweep sweeps gammas over the step traces of the session's last
+// lastNEpisodes episodes, see SweepDiscounts
+func (s *Session) DiscountSweep(lastNEpisodes int, gammas []float64) (DiscountSweep, error) {
+	latest, err := s.db.GetLatestEpisode(s.info.SessionID)
+	if err != nil {
+		return DiscountSweep{}, err
+	}
+	var episodes [][]StepTrace
+	for episode := max(0, latest-lastNEpisodes+1); episode <= latest; episode++ {
+		trace, err := s.db.GetStepTrace(s.info.SessionID, episode)
+		if err != nil {
+			return DiscountSweep{}, err
+		}
+		episodes = append(episodes, trace)
+	}
+
+	sweep := SweepDiscounts(episodes, gammas)
+	if sweep.Trained, err = s.db.getTrainedDiscount(s.info.SessionID); err != nil {
+		return DiscountSweep{}, err
+	}
+	return sweep, nil
+}
+
+// getTrainedDiscount returns the gamma of the session's latest logged TD
+// target, see Logger.LogTDTarget, or 0 when none was logged
+func (m *DB) getTrainedDiscount(sessionID string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var metadata string
+	err := m.db.QueryRow(`
+		SELECT metadata FROM network_metrics
+		WHERE session_id = ? AND metric_type = 'learning' AND metric_name = 'lambda_return'
+		ORDER BY id DESC LIMIT 1
+	`, sessionID).Scan(&metadata)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query TD target discount: %w", err)
+	}
+	var target struct {
+		Gamma float64 `json:"gamma"`
+	}
+	if err := json.Unmarshal([]byte(metadata), &target); err != nil {
+		return 0, fmt.Errorf("failed to parse TD target metadata: %w", err)
+	}
+	return target.Gamma, nil
+}
+
+// correlation returns the Pearson correlation of x and y, 0 when either is
+// constant or they are empty
+func correlation(x, y []float64) float64 {
+	n := float64(len(x))
+	if n == 0 {
+		return 0
+	}
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
@@ -0,0 +1,15 @@
+//go:build !sqlite_purego
+
+package metrics
+
+import (
+	_ "github.com/mattn/go-sqlite3" // SQLite driver, needs cgo
+)
+
+// driverName is the database/sql driver the metrics database opens
+const driverName = "sqlite3"
+
+// dataSourceName returns the DSN that opens the database at dbPath
+func dataSourceName(dbPath string) string {
+	return dbPath
+}
@@ -0,0 +1,20 @@
+//go:build sqlite_purego
+
+package metrics
+
+import (
+	"net/url"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, for CGO_ENABLED=0 builds
+)
+
+// driverName is the database/sql driver the metrics database opens
+const driverName = "sqlite"
+
+// dataSourceName returns the DSN that opens the database at dbPath. Times
+// are written in the same layout as go-sqlite3 so databases can move
+// between builds and SQLite's date functions can parse them. The path is
+// escaped so a ? or # in it does not end the URI's path
+func dataSourceName(dbPath string) string {
+	return "file:" + (&url.URL{Path: dbPath}).EscapedPath() + "?_time_format=sqlite"
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Evaluation is the result of scoring one checkpoint on an evaluation suite
+type Evaluation struct {
+	SessionID      string
+	Checkpoint     string // Path of the evaluated checkpoint
+	Suite          string
+	AvgReward      float64
+	AvgBalanceTime float64 // Seconds
+	MaxAngle       float64 // Radians from upright
+	SuccessRate    float64
+	RewardP10      float64
+	RewardP50      float64
+	RewardP90      float64
+}
+
+// LogEvaluation records an evaluation run between training episodes as
+// "eval" metrics of the episode it followed, kept apart from the training
+// metrics so GetMetricCurve(sessionID, "eval", "success_rate") gives the
+// eval curve
+func (l *Logger) LogEvaluation(episode int, e Evaluation) error {
+	metadataJSON, err := json.Marshal(map[string]interface{}{"suite": e.Suite})
+	if err != nil {
+		return fmt.Errorf("failed to marshal evaluation metadata: %w", err)
+	}
+
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"avg_reward", e.AvgReward},
+		{"avg_balance_time", e.AvgBalanceTime},
+		{"max_angle", e.MaxAngle},
+		{"success_rate", e.SuccessRate},
+		{"reward_p10", e.RewardP10},
+		{"reward_p50", e.RewardP50},
+		{"reward_p90", e.RewardP90},
+	}
+	for _, v := range values {
+		if err := l.recordMetric(l.sessionID, episode, 0, "eval", v.name, v.value, string(metadataJSON)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordEvaluation stores a checkpoint evaluation
+func (m *DB) RecordEvaluation(e Evaluation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		INSERT INTO checkpoint_evaluations (
+			session_id, checkpoint, suite, avg_reward, avg_balance_time, max_angle,
+			success_rate, reward_p10, reward_p50, reward_p90
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.SessionID, e.Checkpoint, e.Suite, e.AvgReward, e.AvgBalanceTime, e.MaxAngle,
+		e.SuccessRate, e.RewardP10, e.RewardP50, e.RewardP90)
+
+	if err != nil {
+		return fmt.Errorf("failed to record evaluation: %w", err)
+	}
+
+	return nil
+}
+
+// GetEvaluations returns the checkpoint evaluations of a session in the order they were recorded
+func (m *DB) GetEvaluations(sessionID string) ([]Evaluation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT session_id, checkpoint, suite, avg_reward, avg_balance_time, max_angle,
+			success_rate, reward_p10, reward_p50, reward_p90
+		FROM checkpoint_evaluations
+		WHERE session_id = ?
+		ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query evaluations: %w", err)
+	}
+	defer rows.Close()
+
+	var evaluations []Evaluation
+	for rows.Next() {
+		var e Evaluation
+		if err := rows.Scan(&e.SessionID, &e.Checkpoint, &e.Suite, &e.AvgReward, &e.AvgBalanceTime,
+			&e.MaxAngle, &e.SuccessRate, &e.RewardP10, &e.RewardP50, &e.RewardP90); err != nil {
+			return nil, fmt.Errorf("failed to scan evaluation row: %w", err)
+		}
+		evaluations = append(evaluations, e)
+	}
+
+	return evaluations, rows.Err()
+}
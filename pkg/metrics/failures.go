@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// EpisodeFailure records how one episode ended
+type EpisodeFailure struct {
+	Episode      int
+	Step         int // Steps the episode lasted
+	Mode         env.FailureMode
+	Angle        float64 // Signed offset from upright when the episode ended
+	CartPosition float64
+}
+
+// FailureCount is how often one failure mode ended a session's episodes
+type FailureCount struct {
+	Mode  env.FailureMode
+	Count int
+	Share float64 // Fraction of the session's classified episodes
+}
+
+// Dominant failure kinds reported by FailureSummary
+const (
+	AngularFailures    = "angular"
+	PositionalFailures = "positional"
+)
+
+// FailureSummary breaks a session's episode endings down by failure mode
+type FailureSummary struct {
+	Episodes   int            // Classified episodes
+	Counts     []FailureCount // Modes that occurred, most frequent first
+	Angular    int            // Episodes ended by the pole falling
+	Positional int            // Episodes ended by the cart leaving the track
+	Dominant   string         // AngularFailures, PositionalFailures, or empty when neither ended an episode
+}
+
+// LogFailure records how the current episode ended, e.g. from
+// Pendulum.Failure and the state it ended in
+func (l *Logger) LogFailure(mode env.FailureMode, steps int, final env.State) error {
+	return l.db.RecordFailure(l.sessionID, EpisodeFailure{
+		Episode:      l.episode,
+		Step:         steps,
+		Mode:         mode,
+		Angle:        env.UprightOffset(final.AngleRadians),
+		CartPosition: final.CartPosition,
+	})
+}
+
+// RecordFailure stores how an episode ended
+func (m *DB) RecordFailure(sessionID string, failure EpisodeFailure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		INSERT INTO episode_failures (session_id, episode, step, mode, angle, cart_position)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, sessionID, failure.Episode, failure.Step, string(failure.Mode), failure.Angle, failure.CartPosition)
+	if err != nil {
+		return fmt.Errorf("failed to record failure of episode %d: %w", failure.Episode, err)
+	}
+	return nil
+}
+
+// GetFailures returns how each of a session's episodes ended, in the order
+// they were recorded
+func (m *DB) GetFailures(sessionID string) ([]EpisodeFailure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, step, mode, angle, cart_position
+		FROM episode_failures
+		WHERE session_id = ?
+		ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []EpisodeFailure
+	for rows.Next() {
+		var f EpisodeFailure
+		var mode string
+		if err := rows.Scan(&f.Episode, &f.Step, &mode, &f.Angle, &f.CartPosition); err != nil {
+			return nil, fmt.Errorf("failed to scan failure row: %w", err)
+		}
+		f.Mode = env.FailureMode(mode)
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
+
+// SummarizeFailures counts failures by mode and names the dominant kind
+func SummarizeFailures(failures []EpisodeFailure) FailureSummary {
+	summary := FailureSummary{Episodes: len(failures)}
+	counts := make(map[env.FailureMode]int)
+	for _, f := range failures {
+		counts[f.Mode]++
+		switch {
+		case f.Mode.Angular():
+			summary.Angular++
+		case f.Mode.Positional():
+			summary.Positional++
+		}
+	}
+	for mode, count := range counts {
+		summary.Counts = append(summary.Counts, FailureCount{
+			Mode:  mode,
+			Count: count,
+			Share: float64(count) / float64(len(failures)),
+		})
+	}
+	sort.Slice(summary.Counts, func(i, j int) bool {
+		if summary.Counts[i].Count != summary.Counts[j].Count {
+			return summary.Counts[i].Count > summary.Counts[j].Count
+		}
+		return summary.Counts[i].Mode < summary.Counts[j].Mode
+	})
+
+	switch {
+	case summary.Angular == 0 && summary.Positional == 0:
+	case summary.Angular >= summary.Positional:
+		summary.Dominant = AngularFailures
+	default:
+		summary.Dominant = PositionalFailures
+	}
+	return summary
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// NoParent marks a missing parent in a Lineage record
+const NoParent = -1
+
+// GenerationStats summarizes the population after one round of evolution
+type GenerationStats struct {
+	Generation  int
+	Fitness     string // Name of the fitness the population was ranked by
+	Population  int
+	BestFitness float64
+	MeanFitness float64
+	StdFitness  float64
+	BestGenome  int // Genome of the fittest network
+	Species     int // Species in the population; 0 when it is not speciated
+	Mutations   int // Children bred from a single parent
+	Crossovers  int // Children bred from two parents
+}
+
+// Lineage records how a genome was bred
+type Lineage struct {
+	Genome        int
+	Generation    int     // Generation the genome was bred in
+	Parent        int     // Fitter parent, or NoParent
+	SecondParent  int     // Other parent of a crossover, or NoParent
+	ParentFitness float64 // Fitness of Parent when the genome was bred
+	Origin        string  // How the genome was bred, e.g. "mutation" or "crossover"
+}
+
+// RecordGeneration stores a generation's statistics and the lineage of the
+// genomes bred in it in a single transaction
+func (m *DB) RecordGeneration(sessionID string, stats GenerationStats, lineage []Lineage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin generation record: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO generations (
+			session_id, generation, fitness, population, best_fitness, mean_fitness,
+			std_fitness, best_genome, species, mutations, crossovers
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, stats.Generation, stats.Fitness, stats.Population, stats.BestFitness, stats.MeanFitness,
+		stats.StdFitness, stats.BestGenome, stats.Species, stats.Mutations, stats.Crossovers)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record generation %d: %w", stats.Generation, err)
+	}
+
+	for _, l := range lineage {
+		_, err := tx.Exec(`
+			INSERT OR REPLACE INTO lineage (
+				session_id, genome, generation, parent, second_parent, parent_fitness, origin
+			) VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, sessionID, l.Genome, l.Generation, l.Parent, l.SecondParent, l.ParentFitness, l.Origin)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record lineage of genome %d: %w", l.Genome, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit generation %d: %w", stats.Generation, err)
+	}
+	return nil
+}
+
+// GetGenerations returns the statistics of every recorded generation of a
+// session, oldest first
+func (m *DB) GetGenerations(sessionID string) ([]GenerationStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT generation, fitness, population, best_fitness, mean_fitness, std_fitness,
+			best_genome, species, mutations, crossovers
+		FROM generations
+		WHERE session_id = ?
+		ORDER BY generation
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query generations: %w", err)
+	}
+	defer rows.Close()
+
+	var generations []GenerationStats
+	for rows.Next() {
+		var g GenerationStats
+		if err := rows.Scan(&g.Generation, &g.Fitness, &g.Population, &g.BestFitness, &g.MeanFitness,
+			&g.StdFitness, &g.BestGenome, &g.Species, &g.Mutations, &g.Crossovers); err != nil {
+			return nil, fmt.Errorf("failed to scan generation row: %w", err)
+		}
+		generations = append(generations, g)
+	}
+
+	return generations, rows.Err()
+}
+
+// GetAncestry follows a genome's fitter parents back to the initial
+// population. The genome's own record comes first; genomes that were never
+// bred, like the initial population, have no record and end the chain
+func (m *DB) GetAncestry(sessionID string, genome int) ([]Lineage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var ancestry []Lineage
+	seen := make(map[int]bool)
+	for genome != NoParent && !seen[genome] {
+		seen[genome] = true
+
+		l := Lineage{Genome: genome}
+		err := m.db.QueryRow(`
+			SELECT generation, parent, second_parent, parent_fitness, origin
+			FROM lineage
+			WHERE session_id = ? AND genome = ?
+		`, sessionID, genome).Scan(&l.Generation, &l.Parent, &l.SecondParent, &l.ParentFitness, &l.Origin)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query lineage of genome %d: %w", genome, err)
+		}
+
+		ancestry = append(ancestry, l)
+		genome = l.Parent
+	}
+
+	return ancestry, nil
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Buckets per doubling of latency; each bucket spans about 19%
+const latencyBucketsPerOctave = 4
+
+// LatencyHistogram counts call latencies in logarithmic buckets, so
+// percentiles cost the same however many calls were recorded. It is safe
+// for concurrent use
+type LatencyHistogram struct {
+	mu      sync.Mutex
+	buckets []int64
+	count   int64
+	total   time.Duration
+	max     time.Duration
+}
+
+// NewLatencyHistogram creates an empty histogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{}
+}
+
+// Record adds one call's latency
+func (h *LatencyHistogram) Record(d time.Duration) {
+	i := latencyBucket(d)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i >= len(h.buckets) {
+		h.buckets = append(h.buckets, make([]int64, i+1-len(h.buckets))...)
+	}
+	h.buckets[i]++
+	h.count++
+	h.total += d
+	h.max = max(h.max, d)
+}
+
+// Time records the time since start, e.g. deferred at the top of a call
+func (h *LatencyHistogram) Time(start time.Time) {
+	h.Record(time.Since(start))
+}
+
+// Reset forgets every recorded call, e.g. to profile each checkpoint separately
+func (h *LatencyHistogram) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buckets = h.buckets[:0]
+	h.count, h.total, h.max = 0, 0, 0
+}
+
+// latencyBucket returns the bucket of a latency in nanoseconds
+func latencyBucket(d time.Duration) int {
+	return int(latencyBucketsPerOctave * math.Log2(float64(max(d, 0))+1))
+}
+
+// latencyBucketBound returns the largest latency bucket i holds
+func latencyBucketBound(i int) time.Duration {
+	return time.Duration(math.Exp2(float64(i+1)/latencyBucketsPerOctave) - 1)
+}
+
+// LatencySummary is a latency distribution's percentiles. Percentiles are
+// the upper bound of their bucket, so they overstate by at most 19%, and
+// never exceed Max
+type LatencySummary struct {
+	Count int64
+	Mean  time.Duration
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// Summary returns the percentiles of the recorded calls
+func (h *LatencyHistogram) Summary() LatencySummary {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := LatencySummary{Count: h.count, Max: h.max}
+	if h.count == 0 {
+		return s
+	}
+	s.Mean = h.total / time.Duration(h.count)
+	s.P50 = h.percentile(0.5)
+	s.P99 = h.percentile(0.99)
+	return s
+}
+
+// percentile returns the bound of the bucket holding the p-th quantile
+func (h *LatencyHistogram) percentile(p float64) time.Duration {
+	rank := int64(math.Ceil(p * float64(h.count)))
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= max(rank, 1) {
+			return min(latencyBucketBound(i), h.max)
+		}
+	}
+	return h.max
+}
+
+// latencyMetadata is stored with each latency percentile
+type latencyMetadata struct {
+	Count  int64   `json:"count"`
+	MeanUS float64 `json:"mean_us"`
+	MaxUS  float64 `json:"max_us"`
+}
+
+// LogLatency records a call's latency percentiles at the current episode,
+// as latency/<name>_p50 and latency/<name>_p99 in microseconds. Nothing is
+// recorded before the first call
+func (l *Logger) LogLatency(name string, s LatencySummary) error {
+	if s.Count == 0 {
+		return nil
+	}
+	metadataJSON, err := json.Marshal(latencyMetadata{
+		Count:  s.Count,
+		MeanUS: microseconds(s.Mean),
+		MaxUS:  microseconds(s.Max),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal latency metadata: %w", err)
+	}
+	if err := l.recordMetric(l.sessionID, l.episode, 0, "latency", name+"_p50", microseconds(s.P50), string(metadataJSON)); err != nil {
+		return err
+	}
+	return l.recordMetric(l.sessionID, l.episode, 0, "latency", name+"_p99", microseconds(s.P99), string(metadataJSON))
+}
+
+// microseconds converts a duration to fractional microseconds
+func microseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
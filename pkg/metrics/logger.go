@@ -7,12 +7,15 @@ import (
 	"math"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 )
 
 // Logger provides a structured interface for recording neural network performance metrics
 type Logger struct {
-	db                *DB
+	db                Store
 	sessionID         string
 	episode           int
 	step              int
@@ -23,6 +26,14 @@ type Logger struct {
 	logStepFrequency  int  // Log to console every N steps within an episode
 	lastConsoleLog    time.Time
 	minLogInterval    time.Duration // Minimum time between console logs
+	writer            atomic.Pointer[BatchWriter] // Optional buffered writer for metric rows; atomic as rows are also recorded with mu held
+	success           env.SuccessCriteria // Judges the episodes recorded with LogEpisode
+	sampling          StepSampling  // How step-level metrics are recorded
+	aggregates        map[metricKey]*aggregate // Step-level values of the current episode under RecordAggregate
+	aggregateStep     int           // Last step added to the aggregates
+	pendingWeights    *[4]float64   // Latest weights under RecordAggregate, written with the aggregates
+	valueEvery        int           // Episodes between value landscape snapshots; 0 records none
+	valueGrid         ValueGrid     // States sampled by value landscape snapshots
 }
 
 // NewLogger creates a new metrics logger with SQLite storage
@@ -38,9 +49,50 @@ func NewLogger(dbPath string, debug bool, stdLogger *log.Logger) (*Logger, error
 		return nil, fmt.Errorf("failed to create metrics database: %w", err)
 	}
 
+	return NewStoreLogger(db, debug, stdLogger)
+}
+
+// NewStoreLogger creates a metrics logger recording a new session to the
+// given store, e.g. a MemoryStore in tests. Closing the logger closes the store
+func NewStoreLogger(store Store, debug bool, stdLogger *log.Logger) (*Logger, error) {
 	// Generate a unique session ID
 	sessionID := GenerateSessionID()
 
+	// Record the session so analysis tools can list it with context
+	if err := store.StartSession(SessionInfo{
+		SessionID: sessionID,
+		StartTime: time.Now(),
+		GitHash:   GitHash(),
+	}); err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	return newLogger(store, sessionID, debug, stdLogger), nil
+}
+
+// ResumeLogger opens the metrics database and continues recording to an
+// existing session, e.g. when training resumes from a session checkpoint
+func ResumeLogger(dbPath, sessionID string, debug bool, stdLogger *log.Logger) (*Logger, error) {
+	if dbPath == "" {
+		dbPath = filepath.Join("data", "metrics.db")
+	}
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics database: %w", err)
+	}
+
+	if err := db.ReopenSession(sessionID); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return newLogger(db, sessionID, debug, stdLogger), nil
+}
+
+// newLogger creates a logger recording to sessionID with default frequencies
+func newLogger(db Store, sessionID string, debug bool, stdLogger *log.Logger) *Logger {
 	return &Logger{
 		db:               db,
 		sessionID:        sessionID,
@@ -52,7 +104,16 @@ func NewLogger(dbPath string, debug bool, stdLogger *log.Logger) (*Logger, error
 		logStepFrequency: 100, // Default: log to console every 100 steps
 		lastConsoleLog:   time.Now(),
 		minLogInterval:   2 * time.Second, // Minimum 2 seconds between console logs
-	}, nil
+		success:          env.NewDefaultSuccessCriteria(),
+		sampling:         NewDefaultStepSampling(),
+		valueGrid:        NewDefaultValueGrid(),
+	}
+}
+
+// SetSuccessCriteria decides which episodes LogEpisode records as
+// successes; pass the criteria given to the trainer so the rates agree
+func (l *Logger) SetSuccessCriteria(criteria env.SuccessCriteria) {
+	l.success = criteria
 }
 
 // SetLogFrequency sets how often to log to the console (in episodes)
@@ -63,15 +124,101 @@ func (l *Logger) SetLogFrequency(episodeFreq, stepFreq int) {
 	l.logStepFrequency = stepFreq
 }
 
-// Close closes the underlying database connection
+// EnableBatching routes metric rows through a background BatchWriter instead
+// of issuing one synchronous INSERT per value
+func (l *Logger) EnableBatching(config BatchConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer.Load() == nil {
+		l.writer.Store(NewBatchWriter(l.db, config))
+	}
+}
+
+// analyzer flushes buffered metric rows and returns the store's analyses
+func (l *Logger) analyzer() (Analyzer, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+	analyzer, ok := l.db.(Analyzer)
+	if !ok {
+		return nil, fmt.Errorf("metrics store %T does not support analysis", l.db)
+	}
+	return analyzer, nil
+}
+
+// Flush writes any buffered metric rows to the database
+func (l *Logger) Flush() error {
+	writer := l.writer.Load()
+	if writer == nil {
+		return nil
+	}
+	return writer.Flush()
+}
+
+// recordMetric writes a metric row through the batch writer when enabled
+func (l *Logger) recordMetric(sessionID string, episode, step int, metricType, metricName string, value float64, metadata string) error {
+	if writer := l.writer.Load(); writer != nil {
+		return writer.RecordMetric(sessionID, episode, step, metricType, metricName, value, metadata)
+	}
+	return l.db.RecordMetric(sessionID, episode, step, metricType, metricName, value, metadata)
+}
+
+// recordStep merges a partial row into the current step's trace, through
+// the batch writer when enabled
+func (l *Logger) recordStep(row stepRow) error {
+	if !l.stepSampled() {
+		if l.sampling.Mode == RecordAggregate && row.Force.Valid {
+			l.aggregateValue("action", "force", row.Force.Float64)
+		}
+		return nil
+	}
+	row.SessionID, row.Episode, row.Step = l.sessionID, l.episode, l.step
+	if writer := l.writer.Load(); writer != nil {
+		return writer.recordStep(row)
+	}
+	return l.db.recordStep(row)
+}
+
+// Close flushes buffered and aggregated metrics, marks the session as
+// finished and closes the underlying database connection
 func (l *Logger) Close() error {
+	l.mu.Lock()
+	if err := l.flushAggregatesLocked(); err != nil && l.stdLogger != nil {
+		l.stdLogger.Printf("[Metrics] Failed to write aggregated metrics: %v", err)
+	}
+	l.mu.Unlock()
+	if writer := l.writer.Load(); writer != nil {
+		if err := writer.Close(); err != nil && l.stdLogger != nil {
+			l.stdLogger.Printf("[Metrics] Failed to flush buffered metrics: %v", err)
+		}
+	}
+	if err := l.db.EndSession(l.sessionID); err != nil && l.stdLogger != nil {
+		l.stdLogger.Printf("[Metrics] Failed to record session end: %v", err)
+	}
 	return l.db.Close()
 }
 
-// SetEpisode sets the current episode number
+// SetSessionConfig records the configuration and hyperparameters for this session
+func (l *Logger) SetSessionConfig(config interface{}, hyperparameters map[string]interface{}) error {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session config: %w", err)
+	}
+	hyperJSON, err := json.Marshal(hyperparameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session hyperparameters: %w", err)
+	}
+	return l.db.UpdateSessionConfig(l.sessionID, string(configJSON), string(hyperJSON))
+}
+
+// SetEpisode sets the current episode number, first writing the aggregated
+// step metrics of the episode that ended
 func (l *Logger) SetEpisode(episode int) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if err := l.flushAggregatesLocked(); err != nil && l.stdLogger != nil {
+		l.stdLogger.Printf("[Metrics] Failed to write aggregated metrics: %v", err)
+	}
 	l.episode = episode
 	l.step = 0 // Reset step counter for new episode
 	
@@ -81,7 +228,7 @@ func (l *Logger) SetEpisode(episode int) {
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 	metadataJSON, _ := json.Marshal(metadata)
-	l.db.RecordMetric(l.sessionID, episode, 0, "system", "episode_start", float64(episode), string(metadataJSON))
+	l.recordMetric(l.sessionID, episode, 0, "system", "episode_start", float64(episode), string(metadataJSON))
 	
 	// Log episode start to console only at specified frequency
 	if l.debug && episode%l.logFrequency == 0 {
@@ -151,19 +298,15 @@ func (l *Logger) LogWeights(angleWeight, angularVelWeight, bias, learningRate fl
 	return err
 }
 
-// LogForwardPass records metrics from a forward pass
+// LogForwardPass records the inputs and output of a forward pass in the step trace
 func (l *Logger) LogForwardPass(angle, angularVel, force, hidden float64) error {
 	// Always log to database
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "input", "angle", angle, ""); err != nil {
-		return err
-	}
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "input", "angular_vel", angularVel, ""); err != nil {
-		return err
-	}
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "output", "force", force, ""); err != nil {
-		return err
-	}
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "hidden", "activation", hidden, ""); err != nil {
+	if err := l.recordStep(stepRow{
+		Angle:      value(angle),
+		AngularVel: value(angularVel),
+		Force:      value(force),
+		Hidden:     value(hidden),
+	}); err != nil {
 		return err
 	}
 	
@@ -176,10 +319,25 @@ func (l *Logger) LogForwardPass(angle, angularVel, force, hidden float64) error
 	return nil
 }
 
+// LogAttribution records how the angle, angular velocity, bias and other
+// terms added up to the hidden pre-activation at the current step
+func (l *Logger) LogAttribution(angle, angularVel, bias, other, hidden float64) error {
+	metadataJSON, err := json.Marshal(map[string]interface{}{
+		"angle":       angle,
+		"angular_vel": angularVel,
+		"bias":        bias,
+		"other":       other,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal attribution metadata: %w", err)
+	}
+	return l.recordStepMetric("attribution", "hidden", hidden, string(metadataJSON))
+}
+
 // LogPrediction records a state value prediction
 func (l *Logger) LogPrediction(angle, angularVel, stateValue float64) error {
 	// Always log to database
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "prediction", "state_value", stateValue, ""); err != nil {
+	if err := l.recordStepMetric("prediction", "state_value", stateValue, ""); err != nil {
 		return err
 	}
 	
@@ -193,7 +351,10 @@ func (l *Logger) LogPrediction(angle, angularVel, stateValue float64) error {
 		return fmt.Errorf("failed to marshal prediction metadata: %w", err)
 	}
 	
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "prediction", "state_context", stateValue, string(metadataJSON)); err != nil {
+	if err := l.recordStepMetric("prediction", "state_context", stateValue, string(metadataJSON)); err != nil {
+		return err
+	}
+	if err := l.recordStep(stepRow{StateValue: value(stateValue)}); err != nil {
 		return err
 	}
 	
@@ -209,20 +370,26 @@ func (l *Logger) LogPrediction(angle, angularVel, stateValue float64) error {
 // LogUpdate records a weight update with progressive training metrics
 func (l *Logger) LogUpdate(error, angleWeight, angularVelWeight, bias, difficulty, successRate float64) error {
 	// Record basic metrics
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "update", "error", error, ""); err != nil {
+	if err := l.recordStepMetric("update", "error", error, ""); err != nil {
 		return err
 	}
 	
-	// Record weights
-	if err := l.db.RecordWeights(l.sessionID, l.episode, angleWeight, angularVelWeight, bias, 0); err != nil {
-		return err
+	// Record weights, only the episode's last under RecordAggregate
+	if l.sampling.Mode == RecordAggregate {
+		l.mu.Lock()
+		l.pendingWeights = &[4]float64{angleWeight, angularVelWeight, bias, 0}
+		l.mu.Unlock()
+	} else if l.stepSampled() {
+		if err := l.db.RecordWeights(l.sessionID, l.episode, angleWeight, angularVelWeight, bias, 0); err != nil {
+			return err
+		}
 	}
 	
 	// Record progressive training metrics
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "training", "difficulty", difficulty, ""); err != nil {
+	if err := l.recordStepMetric("training", "difficulty", difficulty, ""); err != nil {
 		return err
 	}
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "training", "success_rate", successRate, ""); err != nil {
+	if err := l.recordStepMetric("training", "success_rate", successRate, ""); err != nil {
 		return err
 	}
 	
@@ -235,6 +402,108 @@ func (l *Logger) LogUpdate(error, angleWeight, angularVelWeight, bias, difficult
 	return nil
 }
 
+// LogTDTarget records the bootstrapped TD(λ) target and error of an update
+func (l *Logger) LogTDTarget(target, tdError, gamma, lambda float64) error {
+	metadata := map[string]interface{}{
+		"gamma":  gamma,
+		"lambda": lambda,
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal td target metadata: %w", err)
+	}
+	
+	if err := l.recordStepMetric("learning", "lambda_return", target, string(metadataJSON)); err != nil {
+		return err
+	}
+	if err := l.recordStepMetric("learning", "td_error", tdError, ""); err != nil {
+		return err
+	}
+	if err := l.recordStep(stepRow{TDError: value(tdError)}); err != nil {
+		return err
+	}
+	
+	// Selectively log to console
+	if l.shouldLogToConsole() {
+		l.stdLogger.Printf("[Metrics] TD Target (ep:%d,step:%d): target=%.4f, error=%.4f, γ=%.2f, λ=%.2f",
+			l.episode, l.step, target, tdError, gamma, lambda)
+	}
+	
+	return nil
+}
+
+// LogExploration records the exploration scale and the perturbation applied to an action
+func (l *Logger) LogExploration(strategy string, scale, noise float64) error {
+	metadata := map[string]interface{}{
+		"strategy": strategy,
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal exploration metadata: %w", err)
+	}
+	
+	if err := l.recordStepMetric("exploration", "scale", scale, string(metadataJSON)); err != nil {
+		return err
+	}
+	if err := l.recordStepMetric("exploration", "noise", noise, string(metadataJSON)); err != nil {
+		return err
+	}
+	
+	// Selectively log to console
+	if l.shouldLogToConsole() {
+		l.stdLogger.Printf("[Metrics] Exploration (ep:%d,step:%d): strategy=%s, scale=%.4f, noise=%.4f",
+			l.episode, l.step, strategy, scale, noise)
+	}
+	
+	return nil
+}
+
+// LogEnergy records the mechanical energy of the pendulum system at the current step
+func (l *Logger) LogEnergy(kinetic, potential, total float64) error {
+	metadataJSON, err := json.Marshal(map[string]interface{}{
+		"kinetic":   kinetic,
+		"potential": potential,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal energy metadata: %w", err)
+	}
+	return l.recordStepMetric("energy", "total", total, string(metadataJSON))
+}
+
+// LogLearningRate records the learning rate a schedule set for the current episode
+func (l *Logger) LogLearningRate(schedule string, rate float64) error {
+	metadataJSON, err := json.Marshal(map[string]interface{}{"schedule": schedule})
+	if err != nil {
+		return fmt.Errorf("failed to marshal learning rate metadata: %w", err)
+	}
+	return l.recordMetric(l.sessionID, l.episode, 0, "training", "learning_rate", rate, string(metadataJSON))
+}
+
+// LogRewardBreakdown records the components of a state transition reward
+func (l *Logger) LogRewardBreakdown(improvement, positionPenalty, boundsPenalty, total float64) error {
+	metadata := map[string]interface{}{
+		"improvement":      improvement,
+		"position_penalty": positionPenalty,
+		"bounds_penalty":   boundsPenalty,
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reward metadata: %w", err)
+	}
+	
+	if err := l.recordStepMetric("reward", "total", total, string(metadataJSON)); err != nil {
+		return err
+	}
+	
+	// Selectively log to console
+	if l.shouldLogToConsole() {
+		l.stdLogger.Printf("[Metrics] Reward (ep:%d,step:%d): improvement=%.4f, positionPenalty=%.4f, boundsPenalty=%.4f, total=%.4f",
+			l.episode, l.step, improvement, positionPenalty, boundsPenalty, total)
+	}
+	
+	return nil
+}
+
 // LogDifficultyChange records a change in training difficulty
 func (l *Logger) LogDifficultyChange(oldDifficulty, newDifficulty float64, reason string) error {
 	metadata := map[string]interface{}{
@@ -249,7 +518,7 @@ func (l *Logger) LogDifficultyChange(oldDifficulty, newDifficulty float64, reaso
 	}
 	
 	// Record the change event
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "training", "difficulty_change", 
+	if err := l.recordMetric(l.sessionID, l.episode, l.step, "training", "difficulty_change", 
 		newDifficulty, string(metadataJSON)); err != nil {
 		return err
 	}
@@ -278,7 +547,7 @@ func (l *Logger) LogEpisodeResult(totalReward float64, balanceTime int, maxAngle
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 	metadataJSON, _ := json.Marshal(metadata)
-	l.db.RecordMetric(l.sessionID, l.episode, steps, "system", "episode_complete", totalReward, string(metadataJSON))
+	l.recordMetric(l.sessionID, l.episode, steps, "system", "episode_complete", totalReward, string(metadataJSON))
 	
 	// Selectively log to console
 	if l.shouldLogToConsole() {
@@ -289,16 +558,36 @@ func (l *Logger) LogEpisodeResult(totalReward float64, balanceTime int, maxAngle
 	return nil
 }
 
+// LogEpisode records an episode judged by the logger's success criteria
+// and reports whether it succeeded
+func (l *Logger) LogEpisode(totalReward float64, balanceTime int, maxDeviation float64, steps int, duration float64) (bool, error) {
+	success := l.success.Episode(maxDeviation, duration)
+	return success, l.LogEpisodeResult(totalReward, balanceTime, maxDeviation, steps, success)
+}
+
+// LogTermination records why an episode ended and after how many steps
+func (l *Logger) LogTermination(reason string, steps int) error {
+	metadataJSON, err := json.Marshal(map[string]interface{}{"reason": reason})
+	if err != nil {
+		return fmt.Errorf("failed to marshal termination metadata: %w", err)
+	}
+	return l.recordMetric(l.sessionID, l.episode, steps, "episode", "termination", float64(steps), string(metadataJSON))
+}
+
 // GetSessionSummary returns a summary of the current training session
 func (l *Logger) GetSessionSummary() (map[string]interface{}, error) {
-	summary, err := l.db.GetSessionSummary(l.sessionID)
+	analyzer, err := l.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	summary, err := analyzer.GetSessionSummary(l.sessionID)
 	if err != nil {
 		return nil, err
 	}
 	
 	// Record summary to database for persistence
 	summaryJSON, _ := json.Marshal(summary)
-	l.db.RecordMetric(l.sessionID, l.episode, l.step, "system", "session_summary", 
+	l.recordMetric(l.sessionID, l.episode, l.step, "system", "session_summary", 
 		float64(summary["episode_count"].(int)), string(summaryJSON))
 	
 	// Only log to console if in debug mode and at appropriate frequency
@@ -313,7 +602,11 @@ func (l *Logger) GetSessionSummary() (map[string]interface{}, error) {
 
 // GetEpisodeData returns detailed data for a specific episode
 func (l *Logger) GetEpisodeData(episode int) (map[string]interface{}, error) {
-	data, err := l.db.GetEpisodeData(l.sessionID, episode)
+	analyzer, err := l.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	data, err := analyzer.GetEpisodeData(l.sessionID, episode)
 	
 	// Record retrieval to database
 	if err == nil {
@@ -323,7 +616,7 @@ func (l *Logger) GetEpisodeData(episode int) (map[string]interface{}, error) {
 			"timestamp": time.Now().Format(time.RFC3339),
 		}
 		metadataJSON, _ := json.Marshal(metadata)
-		l.db.RecordMetric(l.sessionID, l.episode, l.step, "system", "data_retrieval", float64(episode), string(metadataJSON))
+		l.recordMetric(l.sessionID, l.episode, l.step, "system", "data_retrieval", float64(episode), string(metadataJSON))
 	}
 	
 	// Only log to console if in debug mode and at appropriate frequency
@@ -352,7 +645,7 @@ func (l *Logger) LogNetworkOperation(operation string, path string, success bool
 		value = 0.0
 	}
 	
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "system", "network_operation", value, string(metadataJSON)); err != nil {
+	if err := l.recordMetric(l.sessionID, l.episode, l.step, "system", "network_operation", value, string(metadataJSON)); err != nil {
 		return err
 	}
 	
@@ -378,7 +671,7 @@ func (l *Logger) LogTrainingProgress(episodeCount int, successRate float64, avgR
 		return fmt.Errorf("failed to marshal training progress metadata: %w", err)
 	}
 	
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "system", "training_progress", successRate, string(metadataJSON)); err != nil {
+	if err := l.recordMetric(l.sessionID, l.episode, l.step, "system", "training_progress", successRate, string(metadataJSON)); err != nil {
 		return err
 	}
 	
@@ -394,7 +687,10 @@ func (l *Logger) LogTrainingProgress(episodeCount int, successRate float64, avgR
 // LogLearningDetail records detailed information about the learning process
 func (l *Logger) LogLearningDetail(stateAngle, stateVelocity, predictedValue, actualReward, tdError float64) error {
 	// Always log to database
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "learning", "td_error", tdError, ""); err != nil {
+	if err := l.recordStepMetric("learning", "td_error", tdError, ""); err != nil {
+		return err
+	}
+	if err := l.recordStep(stepRow{TDError: value(tdError)}); err != nil {
 		return err
 	}
 	
@@ -410,7 +706,7 @@ func (l *Logger) LogLearningDetail(stateAngle, stateVelocity, predictedValue, ac
 		return fmt.Errorf("failed to marshal learning metadata: %w", err)
 	}
 	
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "learning", "state_reward_comparison", actualReward-predictedValue, string(metadataJSON)); err != nil {
+	if err := l.recordStepMetric("learning", "state_reward_comparison", actualReward-predictedValue, string(metadataJSON)); err != nil {
 		return err
 	}
 	
@@ -423,39 +719,26 @@ func (l *Logger) LogLearningDetail(stateAngle, stateVelocity, predictedValue, ac
 	return nil
 }
 
-// LogWeightUpdateDetails records detailed information about weight updates
+// LogWeightUpdateDetails records a step's weight updates with the inputs,
+// force and reward that caused them in the step trace
 func (l *Logger) LogWeightUpdateDetails(angle, angularVel, force, reward float64, 
 	angleUpdate, angularVelUpdate, biasUpdate float64, learningRate float64) error {
 	
-	// Create detailed metadata
-	metadata := map[string]interface{}{
-		"angle": angle,
-		"angular_vel": angularVel,
-		"force": force,
-		"reward": reward,
-		"learning_rate": learningRate,
-	}
-	metadataJSON, err := json.Marshal(metadata)
-	if err != nil {
-		return fmt.Errorf("failed to marshal weight update metadata: %w", err)
-	}
-	
-	// Log individual weight updates with context
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "update", "angle_weight", angleUpdate, string(metadataJSON)); err != nil {
-		return err
-	}
-	
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "update", "angular_vel_weight", angularVelUpdate, string(metadataJSON)); err != nil {
-		return err
-	}
-	
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "update", "bias", biasUpdate, string(metadataJSON)); err != nil {
+	if err := l.recordStep(stepRow{
+		Angle:            value(angle),
+		AngularVel:       value(angularVel),
+		Force:            value(force),
+		Reward:           value(reward),
+		AngleUpdate:      value(angleUpdate),
+		AngularVelUpdate: value(angularVelUpdate),
+		BiasUpdate:       value(biasUpdate),
+	}); err != nil {
 		return err
 	}
 	
 	// Log total update magnitude
 	updateMagnitude := math.Abs(angleUpdate) + math.Abs(angularVelUpdate) + math.Abs(biasUpdate)
-	if err := l.db.RecordMetric(l.sessionID, l.episode, l.step, "update", "magnitude", updateMagnitude, ""); err != nil {
+	if err := l.recordStepMetric("update", "magnitude", updateMagnitude, ""); err != nil {
 		return err
 	}
 	
@@ -470,25 +753,53 @@ func (l *Logger) LogWeightUpdateDetails(angle, angularVel, force, reward float64
 
 // AnalyzeLearningProgress performs analysis on the learning progress
 func (l *Logger) AnalyzeLearningProgress(lastNEpisodes int) (map[string]interface{}, error) {
-	return l.db.GetLearningProgress(l.sessionID, lastNEpisodes)
+	analyzer, err := l.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.GetLearningProgress(l.sessionID, lastNEpisodes)
 }
 
 // AnalyzePredictionAccuracy analyzes prediction accuracy for a specific episode
 func (l *Logger) AnalyzePredictionAccuracy(episode int) (map[string]interface{}, error) {
-	return l.db.GetPredictionAccuracy(l.sessionID, episode)
+	analyzer, err := l.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.GetPredictionAccuracy(l.sessionID, episode)
 }
 
 // AnalyzeWeightChanges analyzes weight changes for a specific episode
 func (l *Logger) AnalyzeWeightChanges(episode int) (map[string]interface{}, error) {
-	return l.db.GetWeightChangeAnalysis(l.sessionID, episode)
+	analyzer, err := l.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.GetWeightChangeAnalysis(l.sessionID, episode)
 }
 
 // DetectLearningIssues identifies potential learning problems
 func (l *Logger) DetectLearningIssues() (map[string]interface{}, error) {
-	return l.db.DetectLearningIssues(l.sessionID)
+	analyzer, err := l.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.DetectLearningIssues(l.sessionID)
+}
+
+// LogReward records a reward value; the immediate reward also goes in the step trace
+func (l *Logger) LogReward(rewardType string, reward float64) error {
+	if err := l.recordStepMetric("reward", rewardType, reward, ""); err != nil {
+		return err
+	}
+	if rewardType == "immediate" {
+		return l.recordStep(stepRow{Reward: value(reward)})
+	}
+	return nil
 }
 
-// LogReward records a reward value
-func (l *Logger) LogReward(rewardType string, value float64) error {
-	return l.db.RecordMetric(l.sessionID, l.episode, l.step, "reward", rewardType, value, "")
+// LogGeneration records a round of evolution and the lineage of the genomes
+// bred in it
+func (l *Logger) LogGeneration(stats GenerationStats, lineage []Lineage) error {
+	return l.db.RecordGeneration(l.sessionID, stats, lineage)
 }
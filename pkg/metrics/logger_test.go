@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestLogEpisode(t *testing.T) {
+	store := NewMemoryStore()
+	logger, err := NewStoreLogger(store, false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+	logger.SetSuccessCriteria(env.SuccessCriteria{MaxAngle: 0.2, MinDuration: 2})
+
+	episodes := []struct {
+		maxDeviation, duration float64
+		want                   bool
+	}{
+		{0.1, 3, true},
+		{0.3, 3, false}, // Fell too far
+		{0.1, 1, false}, // Too short
+	}
+	for i, ep := range episodes {
+		logger.SetEpisode(i)
+		success, err := logger.LogEpisode(1, 50, ep.maxDeviation, 100, ep.duration)
+		if err != nil {
+			t.Fatalf("LogEpisode failed: %v", err)
+		}
+		if success != ep.want {
+			t.Errorf("episode %d success = %v, want %v", i, success, ep.want)
+		}
+	}
+
+	curve, err := store.GetEpisodeCurve(logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("GetEpisodeCurve failed: %v", err)
+	}
+	if len(curve) != len(episodes) {
+		t.Fatalf("got %d episodes, want %d", len(curve), len(episodes))
+	}
+	for i, point := range curve {
+		if point.Success != episodes[i].want {
+			t.Errorf("recorded episode %d success = %v, want %v", i, point.Success, episodes[i].want)
+		}
+	}
+}
+
+func TestStepSampling(t *testing.T) {
+	rewards := []float64{1, 2, 3, 4, 5, 6}
+	record := func(t *testing.T, sampling StepSampling) (*Logger, *MemoryStore, []MetricPoint) {
+		store := NewMemoryStore()
+		logger, err := NewStoreLogger(store, false, log.New(io.Discard, "", 0))
+		if err != nil {
+			t.Fatalf("NewLogger failed: %v", err)
+		}
+		t.Cleanup(func() { logger.Close() })
+		if err := logger.SetStepSampling(sampling); err != nil {
+			t.Fatalf("SetStepSampling failed: %v", err)
+		}
+		for episode := 0; episode < 2; episode++ {
+			logger.SetEpisode(episode)
+			for _, r := range rewards {
+				logger.IncrementStep()
+				if err := logger.LogReward("shaped", r); err != nil {
+					t.Fatalf("LogReward failed: %v", err)
+				}
+			}
+		}
+		logger.SetEpisode(2) // Ends the last episode
+		curve, err := store.GetMetricCurve(logger.GetSessionID(), "reward", "shaped")
+		if err != nil {
+			t.Fatalf("GetMetricCurve failed: %v", err)
+		}
+		if len(curve) != 2 {
+			t.Fatalf("got %d episodes, want 2", len(curve))
+		}
+		return logger, store, curve
+	}
+
+	t.Run("every-n", func(t *testing.T) {
+		_, _, curve := record(t, StepSampling{Mode: RecordEveryN, EveryN: 3})
+		for _, point := range curve {
+			if point.Count != 2 || point.Mean != 4.5 {
+				t.Errorf("episode %d: count %d mean %v, want steps 3 and 6 only", point.Episode, point.Count, point.Mean)
+			}
+		}
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		logger, store, curve := record(t, StepSampling{Mode: RecordAggregate})
+		for _, point := range curve {
+			if point.Count != 1 || point.Mean != 3.5 {
+				t.Errorf("episode %d: count %d mean %v, want one row at the mean", point.Episode, point.Count, point.Mean)
+			}
+		}
+
+		var metadata string
+		for _, row := range store.Metrics(logger.GetSessionID()) {
+			if row.MetricType == "reward" && row.Episode == 0 {
+				metadata = row.Metadata
+			}
+		}
+		var summary struct {
+			Count    int
+			Min, Max float64
+			Std      float64
+		}
+		if err := json.Unmarshal([]byte(metadata), &summary); err != nil {
+			t.Fatalf("bad metadata %q: %v", metadata, err)
+		}
+		if summary.Count != len(rewards) || summary.Min != 1 || summary.Max != 6 || math.Abs(summary.Std-math.Sqrt(35.0/12)) > 1e-9 {
+			t.Errorf("summary = %+v, want count 6, min 1, max 6, std %.3f", summary, math.Sqrt(35.0/12))
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		logger, err := NewStoreLogger(NewMemoryStore(), false, log.New(io.Discard, "", 0))
+		if err != nil {
+			t.Fatalf("NewLogger failed: %v", err)
+		}
+		defer logger.Close()
+		for _, sampling := range []StepSampling{{Mode: "sometimes"}, {Mode: RecordEveryN}} {
+			if err := logger.SetStepSampling(sampling); err == nil {
+				t.Errorf("SetStepSampling(%+v) succeeded, want error", sampling)
+			}
+		}
+	})
+}
+
+func TestLogEvaluation(t *testing.T) {
+	store := NewMemoryStore()
+	logger, err := NewStoreLogger(store, false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	for _, episode := range []int{10, 20} {
+		e := Evaluation{Suite: "standard", SuccessRate: float64(episode) / 40, AvgReward: 0.5}
+		if err := logger.LogEvaluation(episode, e); err != nil {
+			t.Fatalf("LogEvaluation failed: %v", err)
+		}
+	}
+
+	curve, err := store.GetMetricCurve(logger.GetSessionID(), "eval", "success_rate")
+	if err != nil {
+		t.Fatalf("GetMetricCurve failed: %v", err)
+	}
+	if len(curve) != 2 || curve[0].Episode != 10 || curve[1].Mean != 0.5 {
+		t.Errorf("eval success curve = %+v, want 0.25 at episode 10 and 0.5 at 20", curve)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	h := NewLatencyHistogram()
+	if s := h.Summary(); s != (LatencySummary{}) {
+		t.Errorf("empty summary = %+v, want zero", s)
+	}
+	for i := 0; i < 98; i++ {
+		h.Record(time.Microsecond)
+	}
+	h.Record(100 * time.Microsecond)
+	h.Record(time.Millisecond)
+	s := h.Summary()
+	// Percentiles round up to their bucket's bound, at most 19% over
+	if s.Count != 100 || s.Max != time.Millisecond || s.P50 < time.Microsecond || s.P50 > 1190*time.Nanosecond ||
+		s.P99 < 100*time.Microsecond || s.P99 > 119*time.Microsecond {
+		t.Errorf("summary = %+v, want p50 ≈ 1µs, p99 ≈ 100µs and max 1ms", s)
+	}
+	if s.Mean != (98*time.Microsecond+1100*time.Microsecond)/100 {
+		t.Errorf("mean = %v, want the exact mean", s.Mean)
+	}
+
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	logger, err := NewStoreLogger(db, false, nil)
+	if err != nil {
+		t.Fatalf("NewStoreLogger failed: %v", err)
+	}
+	defer logger.Close()
+	logger.SetEpisode(3)
+	if err := logger.LogLatency("forward", s); err != nil {
+		t.Fatalf("LogLatency failed: %v", err)
+	}
+	h.Reset()
+	if err := logger.LogLatency("forward", h.Summary()); err != nil {
+		t.Fatalf("LogLatency failed: %v", err)
+	}
+	curve, err := db.GetMetricCurve(logger.GetSessionID(), "latency", "forward_p99")
+	if err != nil {
+		t.Fatalf("GetMetricCurve failed: %v", err)
+	}
+	if len(curve) != 1 || curve[0].Episode != 3 || curve[0].Mean != float64(s.P99)/1000 {
+		t.Errorf("p99 curve = %+v, want one point of %v µs, nothing after the reset", curve, float64(s.P99)/1000)
+	}
+}
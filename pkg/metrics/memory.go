@@ -0,0 +1,426 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps everything in memory. Records stay
+// readable after Close, so tests can inspect what a Logger wrote without a
+// database file
+type MemoryStore struct {
+	mu          sync.Mutex
+	sessions    []SessionInfo
+	metrics     []MetricRow
+	weights     []memoryWeights
+	episodes    []memoryEpisode
+	steps       map[stepKey]stepRow
+	generations map[string]map[int]GenerationStats
+	lineage     map[string]map[int]Lineage
+	values      map[string]map[int][]ValuePoint
+	failures    map[string][]EpisodeFailure
+}
+
+// memoryWeights is a network_weights row
+type memoryWeights struct {
+	sessionID string
+	WeightPoint
+}
+
+// memoryEpisode is a training_episodes row
+type memoryEpisode struct {
+	sessionID string
+	EpisodePoint
+}
+
+// stepKey identifies a step trace row
+type stepKey struct {
+	sessionID     string
+	episode, step int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		steps:       make(map[stepKey]stepRow),
+		generations: make(map[string]map[int]GenerationStats),
+		lineage:     make(map[string]map[int]Lineage),
+		values:      make(map[string]map[int][]ValuePoint),
+		failures:    make(map[string][]EpisodeFailure),
+	}
+}
+
+// session returns the recorded session with the given ID, or nil
+func (s *MemoryStore) session(sessionID string) *SessionInfo {
+	for i := range s.sessions {
+		if s.sessions[i].SessionID == sessionID {
+			return &s.sessions[i]
+		}
+	}
+	return nil
+}
+
+// StartSession records the start of a training session
+func (s *MemoryStore) StartSession(info SessionInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing := s.session(info.SessionID); existing != nil {
+		existing.GitHash = info.GitHash
+		existing.ConfigJSON = info.ConfigJSON
+		existing.Hyperparameters = info.Hyperparameters
+		return nil
+	}
+	if info.StartTime.IsZero() {
+		info.StartTime = time.Now()
+	}
+	s.sessions = append(s.sessions, info)
+	return nil
+}
+
+// ReopenSession marks a recorded session as running again
+func (s *MemoryStore) ReopenSession(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.session(sessionID)
+	if info == nil {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	info.EndTime = time.Time{}
+	return nil
+}
+
+// UpdateSessionConfig stores the configuration and hyperparameters used by a session
+func (s *MemoryStore) UpdateSessionConfig(sessionID, configJSON, hyperparameters string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info := s.session(sessionID); info != nil {
+		info.ConfigJSON = configJSON
+		info.Hyperparameters = hyperparameters
+	}
+	return nil
+}
+
+// EndSession records the end time of a training session
+func (s *MemoryStore) EndSession(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info := s.session(sessionID); info != nil {
+		info.EndTime = time.Now()
+	}
+	return nil
+}
+
+// UpdateSessionBudget stores a session's cumulative compute budget
+func (s *MemoryStore) UpdateSessionBudget(sessionID string, budget ComputeBudget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info := s.session(sessionID); info != nil {
+		info.Budget = budget
+	}
+	return nil
+}
+
+// RecordMetric records a single metric value
+func (s *MemoryStore) RecordMetric(sessionID string, episode, step int, metricType, metricName string, value float64, metadata string) error {
+	return s.RecordMetrics([]MetricRow{{sessionID, episode, step, metricType, metricName, value, metadata}})
+}
+
+// RecordMetrics records many metric values
+func (s *MemoryStore) RecordMetrics(rows []MetricRow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics = append(s.metrics, rows...)
+	return nil
+}
+
+// RecordWeights records the network weights
+func (s *MemoryStore) RecordWeights(sessionID string, episode int, angleWeight, angularVelWeight, bias, learningRate float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.weights = append(s.weights, memoryWeights{sessionID, WeightPoint{
+		Episode:          episode,
+		AngleWeight:      angleWeight,
+		AngularVelWeight: angularVelWeight,
+		Bias:             bias,
+		LearningRate:     learningRate,
+	}})
+	return nil
+}
+
+// RecordEpisode records the result of an episode
+func (s *MemoryStore) RecordEpisode(sessionID string, episode int, totalReward float64, balanceTime int, maxAngle float64, steps int, success bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.episodes = append(s.episodes, memoryEpisode{sessionID, EpisodePoint{
+		Episode:     episode,
+		TotalReward: totalReward,
+		BalanceTime: balanceTime,
+		Steps:       steps,
+		Success:     success,
+	}})
+	return nil
+}
+
+// RecordGeneration stores a generation's statistics and the lineage of the
+// genomes bred in it, replacing earlier records of the same generation and genomes
+func (s *MemoryStore) RecordGeneration(sessionID string, stats GenerationStats, lineage []Lineage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generations[sessionID] == nil {
+		s.generations[sessionID] = make(map[int]GenerationStats)
+		s.lineage[sessionID] = make(map[int]Lineage)
+	}
+	s.generations[sessionID][stats.Generation] = stats
+	for _, l := range lineage {
+		s.lineage[sessionID][l.Genome] = l
+	}
+	return nil
+}
+
+// RecordValueSnapshot stores a value landscape, replacing an earlier
+// snapshot of the same episode
+func (s *MemoryStore) RecordValueSnapshot(sessionID string, episode int, points []ValuePoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values[sessionID] == nil {
+		s.values[sessionID] = make(map[int][]ValuePoint)
+	}
+	s.values[sessionID][episode] = append([]ValuePoint(nil), points...)
+	return nil
+}
+
+// RecordFailure stores how an episode ended
+func (s *MemoryStore) RecordFailure(sessionID string, failure EpisodeFailure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[sessionID] = append(s.failures[sessionID], failure)
+	return nil
+}
+
+// recordStep merges a partial row into its step's trace
+func (s *MemoryStore) recordStep(row stepRow) error {
+	return s.recordSteps([]stepRow{row})
+}
+
+// recordSteps merges many partial rows into their steps' traces; like the
+// SQLite upsert, unset values keep what an earlier write stored
+func (s *MemoryStore) recordSteps(rows []stepRow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range rows {
+		key := stepKey{row.SessionID, row.Episode, row.Step}
+		merged := s.steps[key]
+		merged.SessionID, merged.Episode, merged.Step = row.SessionID, row.Episode, row.Step
+		for _, f := range []struct{ dst, src *sql.NullFloat64 }{
+			{&merged.Angle, &row.Angle},
+			{&merged.AngularVel, &row.AngularVel},
+			{&merged.Force, &row.Force},
+			{&merged.Hidden, &row.Hidden},
+			{&merged.StateValue, &row.StateValue},
+			{&merged.Reward, &row.Reward},
+			{&merged.TDError, &row.TDError},
+			{&merged.AngleUpdate, &row.AngleUpdate},
+			{&merged.AngularVelUpdate, &row.AngularVelUpdate},
+			{&merged.BiasUpdate, &row.BiasUpdate},
+		} {
+			if f.src.Valid {
+				*f.dst = *f.src
+			}
+		}
+		s.steps[key] = merged
+	}
+	return nil
+}
+
+// Close does nothing; the records stay readable
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// ListSessions returns all recorded sessions in the order they started
+func (s *MemoryStore) ListSessions() ([]SessionInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SessionInfo(nil), s.sessions...), nil
+}
+
+// Metrics returns a session's metric rows in the order they were recorded
+func (s *MemoryStore) Metrics(sessionID string) []MetricRow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []MetricRow
+	for _, r := range s.metrics {
+		if r.SessionID == sessionID {
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// GetEpisodeCurve returns a session's episodes in order
+func (s *MemoryStore) GetEpisodeCurve(sessionID string) ([]EpisodePoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var points []EpisodePoint
+	for _, e := range s.episodes {
+		if e.sessionID == sessionID {
+			points = append(points, e.EpisodePoint)
+		}
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Episode < points[j].Episode })
+	return points, nil
+}
+
+// GetWeightCurve returns the last recorded weights of each episode in a session
+func (s *MemoryStore) GetWeightCurve(sessionID string) ([]WeightPoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := make(map[int]WeightPoint)
+	for _, w := range s.weights {
+		if w.sessionID == sessionID {
+			last[w.Episode] = w.WeightPoint
+		}
+	}
+	points := make([]WeightPoint, 0, len(last))
+	for _, p := range last {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Episode < points[j].Episode })
+	return points, nil
+}
+
+// GetMetricCurve returns the per-episode mean of a metric, e.g. learning/td_error
+func (s *MemoryStore) GetMetricCurve(sessionID, metricType, metricName string) ([]MetricPoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byEpisode := make(map[int]*MetricPoint)
+	for _, r := range s.metrics {
+		if r.SessionID != sessionID || r.MetricType != metricType || r.MetricName != metricName {
+			continue
+		}
+		p, ok := byEpisode[r.Episode]
+		if !ok {
+			p = &MetricPoint{Episode: r.Episode}
+			byEpisode[r.Episode] = p
+		}
+		p.Mean += r.Value
+		p.MeanAbs += math.Abs(r.Value)
+		p.Count++
+	}
+	points := make([]MetricPoint, 0, len(byEpisode))
+	for _, p := range byEpisode {
+		p.Mean /= float64(p.Count)
+		p.MeanAbs /= float64(p.Count)
+		points = append(points, *p)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Episode < points[j].Episode })
+	return points, nil
+}
+
+// GetStepTrace returns every logged step of an episode in order
+func (s *MemoryStore) GetStepTrace(sessionID string, episode int) ([]StepTrace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var trace []StepTrace
+	for key, r := range s.steps {
+		if key.sessionID != sessionID || key.episode != episode {
+			continue
+		}
+		trace = append(trace, StepTrace{
+			Episode:          r.Episode,
+			Step:             r.Step,
+			Angle:            r.Angle.Float64,
+			AngularVel:       r.AngularVel.Float64,
+			Force:            r.Force.Float64,
+			Hidden:           r.Hidden.Float64,
+			StateValue:       r.StateValue.Float64,
+			Reward:           r.Reward.Float64,
+			TDError:          r.TDError.Float64,
+			AngleUpdate:      r.AngleUpdate.Float64,
+			AngularVelUpdate: r.AngularVelUpdate.Float64,
+			BiasUpdate:       r.BiasUpdate.Float64,
+			Updated:          r.AngleUpdate.Valid || r.AngularVelUpdate.Valid || r.BiasUpdate.Valid,
+		})
+	}
+	sort.Slice(trace, func(i, j int) bool { return trace[i].Step < trace[j].Step })
+	return trace, nil
+}
+
+// GetGenerations returns the statistics of every recorded generation of a
+// session, oldest first
+func (s *MemoryStore) GetGenerations(sessionID string) ([]GenerationStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	generations := make([]GenerationStats, 0, len(s.generations[sessionID]))
+	for _, g := range s.generations[sessionID] {
+		generations = append(generations, g)
+	}
+	sort.Slice(generations, func(i, j int) bool { return generations[i].Generation < generations[j].Generation })
+	return generations, nil
+}
+
+// GetValueSnapshots returns every value landscape recorded for a session,
+// oldest first
+func (s *MemoryStore) GetValueSnapshots(sessionID string) ([]ValueSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots := make([]ValueSnapshot, 0, len(s.values[sessionID]))
+	for episode, points := range s.values[sessionID] {
+		snapshots = append(snapshots, ValueSnapshot{Episode: episode, Points: append([]ValuePoint(nil), points...)})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Episode < snapshots[j].Episode })
+	return snapshots, nil
+}
+
+// GetFailures returns how each of a session's episodes ended, in the order
+// they were recorded
+func (s *MemoryStore) GetFailures(sessionID string) ([]EpisodeFailure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]EpisodeFailure(nil), s.failures[sessionID]...), nil
+}
+
+// GetAncestry follows a genome's fitter parents back to the initial
+// population, like DB.GetAncestry
+func (s *MemoryStore) GetAncestry(sessionID string, genome int) ([]Lineage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ancestry []Lineage
+	seen := make(map[int]bool)
+	for genome != NoParent && !seen[genome] {
+		seen[genome] = true
+		l, ok := s.lineage[sessionID][genome]
+		if !ok {
+			break
+		}
+		ancestry = append(ancestry, l)
+		genome = l.Parent
+	}
+	return ancestry, nil
+}
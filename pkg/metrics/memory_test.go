@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"io"
+	"log"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	logger, err := NewStoreLogger(store, false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewStoreLogger failed: %v", err)
+	}
+
+	logger.SetEpisode(1)
+	logger.IncrementStep()
+	logger.LogForwardPass(0.1, -0.2, 1.5, 0.3)
+	logger.LogReward("immediate", 0.8)
+	logger.LogWeightUpdateDetails(0.1, -0.2, 1.5, 0.8, 0.01, -0.02, 0.005, 0.05)
+	logger.IncrementStep()
+	logger.LogForwardPass(0.2, 0.1, -1.0, -0.2)
+	logger.LogEpisodeResult(3, 2, 0.1, 2, true)
+	logger.LogGeneration(GenerationStats{Generation: 0, BestGenome: 5}, []Lineage{
+		{Genome: 5, Parent: 2, SecondParent: NoParent},
+	})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	sessionID := logger.GetSessionID()
+
+	t.Run("step traces merge", func(t *testing.T) {
+		trace, err := store.GetStepTrace(sessionID, 1)
+		if err != nil {
+			t.Fatalf("GetStepTrace failed: %v", err)
+		}
+		if len(trace) != 2 {
+			t.Fatalf("got %d steps, want 2: %+v", len(trace), trace)
+		}
+		if first := trace[0]; first.Force != 1.5 || first.Reward != 0.8 || first.AngleUpdate != 0.01 || !first.Updated {
+			t.Errorf("step 1 = %+v, want forward pass, reward and update merged", first)
+		}
+		if second := trace[1]; second.Updated || second.Force != -1.0 {
+			t.Errorf("step 2 = %+v, want a forward pass without updates", second)
+		}
+	})
+
+	t.Run("records stay readable after close", func(t *testing.T) {
+		sessions, err := store.ListSessions()
+		if err != nil || len(sessions) != 1 || sessions[0].EndTime.IsZero() {
+			t.Errorf("sessions = %+v, %v; want one ended session", sessions, err)
+		}
+		if episodes, _ := store.GetEpisodeCurve(sessionID); len(episodes) != 1 || !episodes[0].Success {
+			t.Errorf("episodes = %+v, want one success", episodes)
+		}
+		if ancestry, _ := store.GetAncestry(sessionID, 5); len(ancestry) != 1 || ancestry[0].Parent != 2 {
+			t.Errorf("ancestry = %+v, want genome 5 bred from 2", ancestry)
+		}
+	})
+
+	t.Run("analysis needs SQLite", func(t *testing.T) {
+		if _, err := logger.DetectLearningIssues(); err == nil {
+			t.Error("DetectLearningIssues on a memory store succeeded, want error")
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"fmt"
+)
+
+// migration is a single, ordered schema change
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// migrations lists every schema change in the order it must be applied.
+// Append new migrations to the end; never edit one that has shipped.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "initial metrics tables",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS network_metrics (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				session_id TEXT,
+				episode INTEGER,
+				step INTEGER,
+				metric_type TEXT,
+				metric_name TEXT,
+				value REAL,
+				metadata TEXT
+			)`,
+			`CREATE TABLE IF NOT EXISTS network_weights (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				session_id TEXT,
+				episode INTEGER,
+				angle_weight REAL,
+				angular_vel_weight REAL,
+				bias REAL,
+				learning_rate REAL
+			)`,
+			`CREATE TABLE IF NOT EXISTS training_episodes (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				session_id TEXT,
+				episode INTEGER,
+				total_reward REAL,
+				balance_time INTEGER,
+				max_angle REAL,
+				steps INTEGER,
+				success BOOLEAN
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_network_metrics_session_episode ON network_metrics(session_id, episode)`,
+			`CREATE INDEX IF NOT EXISTS idx_network_weights_session_episode ON network_weights(session_id, episode)`,
+			`CREATE INDEX IF NOT EXISTS idx_training_episodes_session ON training_episodes(session_id)`,
+		},
+	},
+	{
+		version:     2,
+		description: "sessions metadata table",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS sessions (
+				session_id TEXT PRIMARY KEY,
+				start_time DATETIME NOT NULL,
+				end_time DATETIME,
+				git_hash TEXT NOT NULL DEFAULT '',
+				config_json TEXT NOT NULL DEFAULT '',
+				hyperparameters TEXT NOT NULL DEFAULT ''
+			)`,
+			// Backfill sessions recorded before this table existed
+			`INSERT OR IGNORE INTO sessions (session_id, start_time, end_time)
+				SELECT session_id, MIN(timestamp), MAX(timestamp)
+				FROM network_weights
+				WHERE session_id IS NOT NULL
+				GROUP BY session_id`,
+		},
+	},
+	{
+		version:     3,
+		description: "checkpoint evaluations table",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS checkpoint_evaluations (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				session_id TEXT NOT NULL,
+				checkpoint TEXT NOT NULL,
+				suite TEXT NOT NULL,
+				avg_reward REAL,
+				avg_balance_time REAL,
+				max_angle REAL,
+				success_rate REAL,
+				reward_p10 REAL,
+				reward_p50 REAL,
+				reward_p90 REAL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_checkpoint_evaluations_session ON checkpoint_evaluations(session_id)`,
+		},
+	},
+	{
+		version:     4,
+		description: "step traces table",
+		statements: []string{
+			// One wide row per step replaces joining per-value network_metrics rows
+			`CREATE TABLE IF NOT EXISTS step_traces (
+				session_id TEXT NOT NULL,
+				episode INTEGER NOT NULL,
+				step INTEGER NOT NULL,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				angle REAL,
+				angular_vel REAL,
+				force REAL,
+				hidden REAL,
+				state_value REAL,
+				reward REAL,
+				td_error REAL,
+				angle_update REAL,
+				angular_vel_update REAL,
+				bias_update REAL,
+				PRIMARY KEY (session_id, episode, step)
+			)`,
+		},
+	},
+	{
+		version:     5,
+		description: "evolution generations and lineage tables",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS generations (
+				session_id TEXT NOT NULL,
+				generation INTEGER NOT NULL,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				fitness TEXT,
+				population INTEGER,
+				best_fitness REAL,
+				mean_fitness REAL,
+				std_fitness REAL,
+				best_genome INTEGER,
+				species INTEGER,
+				mutations INTEGER,
+				crossovers INTEGER,
+				PRIMARY KEY (session_id, generation)
+			)`,
+			`CREATE TABLE IF NOT EXISTS lineage (
+				session_id TEXT NOT NULL,
+				genome INTEGER NOT NULL,
+				generation INTEGER NOT NULL,
+				parent INTEGER NOT NULL,
+				second_parent INTEGER NOT NULL,
+				parent_fitness REAL,
+				origin TEXT,
+				PRIMARY KEY (session_id, genome)
+			)`,
+		},
+	},
+	{
+		version:     6,
+		description: "benchmark results table",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS benchmarks (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				git_hash TEXT,
+				name TEXT,
+				steps INTEGER,
+				seconds REAL,
+				steps_per_sec REAL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_benchmarks_name ON benchmarks(name)`,
+		},
+	},
+	{
+		version:     7,
+		description: "value landscape snapshots table",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS value_snapshots (
+				session_id TEXT NOT NULL,
+				episode INTEGER NOT NULL,
+				angle REAL NOT NULL,
+				angular_vel REAL NOT NULL,
+				value REAL,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (session_id, episode, angle, angular_vel)
+			)`,
+		},
+	},
+	{
+		version:     8,
+		description: "session compute budget",
+		statements: []string{
+			`ALTER TABLE sessions ADD COLUMN wall_clock_seconds REAL NOT NULL DEFAULT 0`,
+			`ALTER TABLE sessions ADD COLUMN steps INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE sessions ADD COLUMN forward_passes INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE sessions ADD COLUMN backward_passes INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version:     9,
+		description: "network metrics step index",
+		statements: []string{
+			// Pages of an episode's step metrics are read in step order
+			`CREATE INDEX IF NOT EXISTS idx_network_metrics_session_episode_step ON network_metrics(session_id, episode, step, id)`,
+		},
+	},
+	{
+		version:     10,
+		description: "episode failure modes table",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS episode_failures (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+				session_id TEXT NOT NULL,
+				episode INTEGER NOT NULL,
+				step INTEGER,
+				mode TEXT NOT NULL,
+				angle REAL,
+				cart_position REAL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_episode_failures_session ON episode_failures(session_id)`,
+		},
+	},
+}
+
+// migrate brings the schema up to the latest version, applying each pending
+// migration in its own transaction
+func (m *DB) migrate() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description TEXT,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := m.schemaVersionLocked()
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range migrations {
+		if mig.version <= current {
+			continue
+		}
+
+		tx, err := m.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", mig.version, err)
+		}
+
+		for _, stmt := range mig.statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", mig.version, mig.description, err)
+			}
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_version (version, description) VALUES (?, ?)`,
+			mig.version, mig.description); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", mig.version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", mig.version, err)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the latest applied migration version
+func (m *DB) SchemaVersion() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.schemaVersionLocked()
+}
+
+// schemaVersionLocked reads the schema version; callers must hold m.mu
+func (m *DB) schemaVersionLocked() (int, error) {
+	var version int
+	err := m.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,397 @@
+package metrics
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// NetworkOperation is a network save, load or checkpoint recorded in a session
+type NetworkOperation struct {
+	Episode   int
+	Operation string // e.g. "save", "load" or "checkpoint"
+	Path      string
+	Success   bool
+}
+
+// GetNetworkOperations returns the network operations a session recorded, in order
+func (m *DB) GetNetworkOperations(sessionID string) ([]NetworkOperation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, value, metadata
+		FROM network_metrics
+		WHERE session_id = ? AND metric_type = 'system' AND metric_name = 'network_operation'
+		ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query network operations: %w", err)
+	}
+	defer rows.Close()
+
+	var operations []NetworkOperation
+	for rows.Next() {
+		var op NetworkOperation
+		var value float64
+		var metadata sql.NullString
+		if err := rows.Scan(&op.Episode, &value, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan network operation row: %w", err)
+		}
+		var details struct {
+			Operation string `json:"operation"`
+			Path      string `json:"path"`
+		}
+		if err := json.Unmarshal([]byte(metadata.String), &details); err != nil {
+			return nil, fmt.Errorf("failed to parse network operation metadata: %w", err)
+		}
+		op.Operation = details.Operation
+		op.Path = details.Path
+		op.Success = value > 0
+		operations = append(operations, op)
+	}
+
+	return operations, rows.Err()
+}
+
+// Report is a self-contained summary of a session for sharing results
+// without the database: its configuration, learning curves, final weights,
+// detected issues and checkpoints
+type Report struct {
+	Info        SessionInfo
+	Generated   time.Time
+	Window      int          // Episodes in the rolling success rate and final statistics
+	Stats       SessionStats // Zero when the session recorded no episodes
+	Curve       SessionCurve
+	Weights     *WeightPoint // Last recorded weights, nil if none were recorded
+	Generations []GenerationStats
+	Issues      []string
+	Checkpoints []NetworkOperation // Successful saves and checkpoints
+	Evaluations []Evaluation
+}
+
+// Report gathers everything a shareable report of the session shows,
+// with final statistics over the last window episodes
+func (s *Session) Report(window int) (Report, error) {
+	report := Report{Info: s.info, Generated: time.Now(), Window: max(1, window)}
+
+	episodes, err := s.EpisodeCurve()
+	if err != nil {
+		return Report{}, err
+	}
+	if len(episodes) > 0 {
+		comparison, err := s.db.CompareSessions([]string{s.info.SessionID}, report.Window)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Curve = comparison.Curves[0]
+		report.Stats = comparison.Stats[0]
+	}
+
+	weights, err := s.db.GetWeightCurve(s.info.SessionID)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(weights) > 0 {
+		report.Weights = &weights[len(weights)-1]
+	}
+
+	if report.Generations, err = s.Generations(); err != nil {
+		return Report{}, err
+	}
+
+	issues, err := s.LearningIssues()
+	if err != nil {
+		return Report{}, err
+	}
+	report.Issues, _ = issues["issues"].([]string)
+
+	operations, err := s.db.GetNetworkOperations(s.info.SessionID)
+	if err != nil {
+		return Report{}, err
+	}
+	for _, op := range operations {
+		if op.Success && (op.Operation == "save" || op.Operation == "checkpoint") {
+			report.Checkpoints = append(report.Checkpoints, op)
+		}
+	}
+
+	if report.Evaluations, err = s.db.GetEvaluations(s.info.SessionID); err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+// reportChart is a titled inline SVG chart
+type reportChart struct {
+	Title string
+	SVG   string
+}
+
+// charts draws the report's learning curves
+func (r Report) charts() []reportChart {
+	var charts []reportChart
+	if len(r.Curve.Reward) > 0 {
+		balance := make([]float64, len(r.Curve.BalanceTime))
+		for i, b := range r.Curve.BalanceTime {
+			balance[i] = float64(b)
+		}
+		charts = append(charts,
+			reportChart{"Reward per episode", svgChart(chartSeries{"reward", "#1f77b4", r.Curve.Reward})},
+			reportChart{fmt.Sprintf("Success rate over the last %d episodes", r.Window), svgChart(chartSeries{"success rate", "#2ca02c", r.Curve.SuccessRate})},
+			reportChart{"Balance time per episode", svgChart(chartSeries{"balance time", "#ff7f0e", balance})},
+		)
+	}
+	if len(r.Generations) > 0 {
+		best := make([]float64, len(r.Generations))
+		mean := make([]float64, len(r.Generations))
+		for i, g := range r.Generations {
+			best[i] = g.BestFitness
+			mean[i] = g.MeanFitness
+		}
+		charts = append(charts, reportChart{"Fitness per generation",
+			svgChart(chartSeries{"best", "#d62728", best}, chartSeries{"mean", "#7f7f7f", mean})})
+	}
+	return charts
+}
+
+// chartSeries is one line of a chart, indexed by episode or generation ordinal
+type chartSeries struct {
+	Name   string
+	Color  string
+	Values []float64
+}
+
+// svgChart draws the series as lines on shared axes, labelled with the
+// value range and a legend
+func svgChart(series ...chartSeries) string {
+	const width, height, pad = 640.0, 200.0, 40.0
+
+	lo, hi, n := math.Inf(1), math.Inf(-1), 0
+	for _, s := range series {
+		for _, v := range s.Values {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		n = max(n, len(s.Values))
+	}
+	if hi <= lo {
+		lo, hi = lo-1, hi+1
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="11">`,
+		width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`)
+	fmt.Fprintf(&b, `<rect x="%.0f" y="%.0f" width="%.0f" height="%.0f" fill="none" stroke="#ccc"/>`, pad, pad/2, width-1.5*pad, height-1.5*pad)
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" text-anchor="end">%.4g</text>`, pad-4, pad/2+4, hi)
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" text-anchor="end">%.4g</text>`, pad-4, height-pad+4, lo)
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" text-anchor="end">%d</text>`, width-pad/2, height-pad+14, max(0, n-1))
+	for i, s := range series {
+		var points []string
+		for j, v := range s.Values {
+			x := pad + (width-1.5*pad)*float64(j)/math.Max(1, float64(n-1))
+			y := height - pad - (height-1.5*pad)*(v-lo)/(hi-lo)
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`, s.Color, strings.Join(points, " "))
+		fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" fill="%s">%s</text>`, pad+float64(i)*100, height-pad+14, s.Color, s.Name)
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// reportFuncs formats report values in both templates
+var reportFuncs = map[string]interface{}{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "still running"
+		}
+		return t.Format(time.RFC3339)
+	},
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", 100*v) },
+	"json": func(s string) string {
+		var out bytes.Buffer
+		if err := json.Indent(&out, []byte(s), "", "  "); err != nil {
+			return s
+		}
+		return out.String()
+	},
+	"dataURI": func(svg string) string {
+		return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+	},
+	"svg": func(svg string) htmltemplate.HTML { return htmltemplate.HTML(svg) },
+}
+
+// reportView is what the report templates render
+type reportView struct {
+	Report
+	Charts []reportChart
+}
+
+// WriteMarkdown writes the report as Markdown, with the charts embedded as
+// SVG data URIs so the file stands alone
+func (r Report) WriteMarkdown(w io.Writer) error {
+	if err := markdownReport.Execute(w, reportView{r, r.charts()}); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML writes the report as a single HTML page with inline SVG charts
+func (r Report) WriteHTML(w io.Writer) error {
+	if err := htmlReport.Execute(w, reportView{r, r.charts()}); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+var markdownReport = template.Must(template.New("markdown").Funcs(reportFuncs).Parse(`# Training report: {{.Info.SessionID}}
+
+- Started: {{time .Info.StartTime}}
+- Ended: {{time .Info.EndTime}}
+{{- if .Info.GitHash}}
+- Commit: {{.Info.GitHash}}
+{{- end}}
+- Compute: {{.Info.Budget}}
+- Generated: {{time .Generated}}
+
+## Results
+{{if .Stats.Episodes}}
+| Episodes | Avg reward (last {{.Window}}) | Success rate (last {{.Window}}) | Balance time (last {{.Window}}) | Best balance time |
+|---|---|---|---|---|
+| {{.Stats.Episodes}} | {{printf "%.3f" .Stats.FinalAvgReward}} | {{percent .Stats.FinalSuccessRate}} | {{printf "%.1f" .Stats.FinalBalanceTime}} | {{.Stats.BestBalanceTime}} (episode {{.Stats.BestEpisode}}) |
+{{else}}
+No episodes recorded.
+{{end}}
+{{- range .Charts}}
+### {{.Title}}
+
+![{{.Title}}]({{dataURI .SVG}})
+{{end}}
+## Final weights
+{{with .Weights}}
+| Episode | Angle | Angular velocity | Bias | Learning rate |
+|---|---|---|---|---|
+| {{.Episode}} | {{printf "%.4f" .AngleWeight}} | {{printf "%.4f" .AngularVelWeight}} | {{printf "%.4f" .Bias}} | {{printf "%.4g" .LearningRate}} |
+{{else}}
+No weights recorded.
+{{end}}
+## Detected issues
+{{range .Issues}}
+- {{.}}
+{{- else}}
+None detected.
+{{- end}}
+
+## Checkpoints
+{{range .Checkpoints}}
+- [{{.Path}}]({{.Path}}) ({{.Operation}} in episode {{.Episode}})
+{{- else}}
+No checkpoints recorded.
+{{- end}}
+{{if .Evaluations}}
+## Checkpoint evaluations
+
+| Checkpoint | Suite | Success rate | Avg reward | Balance time |
+|---|---|---|---|---|
+{{- range .Evaluations}}
+| {{.Checkpoint}} | {{.Suite}} | {{percent .SuccessRate}} | {{printf "%.3f" .AvgReward}} | {{printf "%.1fs" .AvgBalanceTime}} |
+{{- end}}
+{{end}}
+## Configuration
+{{if .Info.ConfigJSON}}
+` + "```json\n{{json .Info.ConfigJSON}}\n```" + `
+{{else}}
+No configuration recorded.
+{{end}}
+{{- if .Info.Hyperparameters}}
+### Hyperparameters
+
+` + "```json\n{{json .Info.Hyperparameters}}\n```" + `
+{{end}}`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Training report: {{.Info.SessionID}}</title>
+<style>
+body { font-family: sans-serif; max-width: 720px; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+pre { background: #f4f4f4; padding: 8px; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>Training report: {{.Info.SessionID}}</h1>
+<ul>
+<li>Started: {{time .Info.StartTime}}</li>
+<li>Ended: {{time .Info.EndTime}}</li>
+{{if .Info.GitHash}}<li>Commit: {{.Info.GitHash}}</li>{{end}}
+<li>Compute: {{.Info.Budget}}</li>
+<li>Generated: {{time .Generated}}</li>
+</ul>
+
+<h2>Results</h2>
+{{if .Stats.Episodes}}
+<table>
+<tr><th>Episodes</th><th>Avg reward (last {{.Window}})</th><th>Success rate (last {{.Window}})</th><th>Balance time (last {{.Window}})</th><th>Best balance time</th></tr>
+<tr><td>{{.Stats.Episodes}}</td><td>{{printf "%.3f" .Stats.FinalAvgReward}}</td><td>{{percent .Stats.FinalSuccessRate}}</td><td>{{printf "%.1f" .Stats.FinalBalanceTime}}</td><td>{{.Stats.BestBalanceTime}} (episode {{.Stats.BestEpisode}})</td></tr>
+</table>
+{{else}}
+<p>No episodes recorded.</p>
+{{end}}
+{{range .Charts}}
+<h3>{{.Title}}</h3>
+{{svg .SVG}}
+{{end}}
+
+<h2>Final weights</h2>
+{{with .Weights}}
+<table>
+<tr><th>Episode</th><th>Angle</th><th>Angular velocity</th><th>Bias</th><th>Learning rate</th></tr>
+<tr><td>{{.Episode}}</td><td>{{printf "%.4f" .AngleWeight}}</td><td>{{printf "%.4f" .AngularVelWeight}}</td><td>{{printf "%.4f" .Bias}}</td><td>{{printf "%.4g" .LearningRate}}</td></tr>
+</table>
+{{else}}
+<p>No weights recorded.</p>
+{{end}}
+
+<h2>Detected issues</h2>
+{{if .Issues}}
+<ul>{{range .Issues}}<li>{{.}}</li>{{end}}</ul>
+{{else}}
+<p>None detected.</p>
+{{end}}
+
+<h2>Checkpoints</h2>
+{{if .Checkpoints}}
+<ul>{{range .Checkpoints}}<li><a href="{{.Path}}">{{.Path}}</a> ({{.Operation}} in episode {{.Episode}})</li>{{end}}</ul>
+{{else}}
+<p>No checkpoints recorded.</p>
+{{end}}
+{{if .Evaluations}}
+<h2>Checkpoint evaluations</h2>
+<table>
+<tr><th>Checkpoint</th><th>Suite</th><th>Success rate</th><th>Avg reward</th><th>Balance time</th></tr>
+{{range .Evaluations}}<tr><td>{{.Checkpoint}}</td><td>{{.Suite}}</td><td>{{percent .SuccessRate}}</td><td>{{printf "%.3f" .AvgReward}}</td><td>{{printf "%.1fs" .AvgBalanceTime}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>Configuration</h2>
+{{if .Info.ConfigJSON}}<pre>{{json .Info.ConfigJSON}}</pre>{{else}}<p>No configuration recorded.</p>{{end}}
+{{if .Info.Hyperparameters}}
+<h3>Hyperparameters</h3>
+<pre>{{json .Info.Hyperparameters}}</pre>
+{{end}}
+</body>
+</html>
+`))
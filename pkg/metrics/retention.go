@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sessionTables lists every table whose rows belong to a session
+var sessionTables = []string{
+	"network_metrics",
+	"network_weights",
+	"training_episodes",
+	"checkpoint_evaluations",
+	"step_traces",
+	"generations",
+	"lineage",
+	"value_snapshots",
+	"episode_failures",
+	"sessions",
+}
+
+// PruneStats counts the rows removed by Prune
+type PruneStats struct {
+	StepMetrics int64 // Down-sampled network_metrics rows
+	StepTraces  int64 // Down-sampled step_traces rows
+	Sessions    int64 // Abandoned sessions deleted with all their rows
+}
+
+// Prune shrinks the database by thinning step-level data recorded more than
+// olderThan ago, keeping only every keepEveryNth step (1 keeps them all).
+// Episode-level rows (step 0) and per-episode aggregates are always kept.
+// Abandoned sessions, started before the cutoff and never ended without
+// recording an episode or generation, are deleted outright. An empty
+// sessionID prunes every session. Run Vacuum afterwards to return the freed
+// pages to the filesystem
+func (m *DB) Prune(sessionID string, olderThan time.Duration, keepEveryNth int) (PruneStats, error) {
+	if keepEveryNth < 1 {
+		return PruneStats{}, fmt.Errorf("keepEveryNth must be at least 1, got %d", keepEveryNth)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan).UTC().Format("2006-01-02 15:04:05")
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return PruneStats{}, fmt.Errorf("failed to begin prune: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Abandoned sessions go first so their rows are not counted as down-sampled
+	rows, err := tx.Query(`
+		SELECT session_id FROM sessions s
+		WHERE (? = '' OR session_id = ?)
+			AND end_time IS NULL
+			AND datetime(start_time) <= datetime(?)
+			AND NOT EXISTS (SELECT 1 FROM training_episodes e WHERE e.session_id = s.session_id)
+			AND NOT EXISTS (SELECT 1 FROM generations g WHERE g.session_id = s.session_id)
+	`, sessionID, sessionID, cutoff)
+	if err != nil {
+		return PruneStats{}, fmt.Errorf("failed to find abandoned sessions: %w", err)
+	}
+	var abandoned []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return PruneStats{}, fmt.Errorf("failed to scan session row: %w", err)
+		}
+		abandoned = append(abandoned, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return PruneStats{}, fmt.Errorf("failed to find abandoned sessions: %w", err)
+	}
+
+	for _, id := range abandoned {
+		for _, table := range sessionTables {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE session_id = ?`, id); err != nil {
+				return PruneStats{}, fmt.Errorf("failed to delete session %s from %s: %w", id, table, err)
+			}
+		}
+	}
+	stats := PruneStats{Sessions: int64(len(abandoned))}
+
+	if stats.StepMetrics, err = execCount(tx, `
+		DELETE FROM network_metrics
+		WHERE (? = '' OR session_id = ?)
+			AND datetime(timestamp) <= datetime(?)
+			AND step > 0 AND step % ? != 0
+			AND NOT COALESCE(CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.aggregate') END, 0)
+	`, sessionID, sessionID, cutoff, keepEveryNth); err != nil {
+		return PruneStats{}, fmt.Errorf("failed to down-sample step metrics: %w", err)
+	}
+	if stats.StepTraces, err = execCount(tx, `
+		DELETE FROM step_traces
+		WHERE (? = '' OR session_id = ?)
+			AND datetime(timestamp) <= datetime(?)
+			AND step > 0 AND step % ? != 0
+	`, sessionID, sessionID, cutoff, keepEveryNth); err != nil {
+		return PruneStats{}, fmt.Errorf("failed to down-sample step traces: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return PruneStats{}, fmt.Errorf("failed to commit prune: %w", err)
+	}
+	return stats, nil
+}
+
+// Vacuum rebuilds the database file, returning pages freed by deletes to
+// the filesystem
+func (m *DB) Vacuum() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// execCount executes a statement and returns how many rows it affected
+func execCount(tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
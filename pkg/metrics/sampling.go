@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Step recording modes accepted in StepSampling.Mode
+const (
+	RecordAllSteps  = "all"       // One row per step-level value (default)
+	RecordEveryN    = "every-n"   // Only steps that are a multiple of EveryN
+	RecordAggregate = "aggregate" // Per-episode count, mean, std, min and max of each value
+)
+
+// StepSampling controls how step-level metrics (forward passes, predictions,
+// TD errors, rewards, exploration and updates) reach the database. Episode
+// results, terminations, generations and other events are always recorded
+type StepSampling struct {
+	Mode   string
+	EveryN int // Step interval for RecordEveryN
+}
+
+// NewDefaultStepSampling records every step
+func NewDefaultStepSampling() StepSampling {
+	return StepSampling{Mode: RecordAllSteps, EveryN: 100}
+}
+
+// metricKey identifies an aggregated metric
+type metricKey struct {
+	metricType, metricName string
+}
+
+// aggregate accumulates one metric's values over an episode
+type aggregate struct {
+	count      int
+	sum, sumSq float64
+	sumAbs     float64
+	min, max   float64
+}
+
+// add includes a value in the aggregate
+func (a *aggregate) add(v float64) {
+	if a.count == 0 {
+		a.min, a.max = v, v
+	}
+	a.count++
+	a.sum += v
+	a.sumSq += v * v
+	a.sumAbs += math.Abs(v)
+	a.min = math.Min(a.min, v)
+	a.max = math.Max(a.max, v)
+}
+
+// SetStepSampling changes how step-level metrics are recorded. Values
+// aggregated so far are written first
+func (l *Logger) SetStepSampling(sampling StepSampling) error {
+	switch sampling.Mode {
+	case "", RecordAllSteps, RecordAggregate:
+	case RecordEveryN:
+		if sampling.EveryN < 1 {
+			return fmt.Errorf("every-n step sampling needs EveryN >= 1, got %d", sampling.EveryN)
+		}
+	default:
+		return fmt.Errorf("unknown step sampling mode %q", sampling.Mode)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.flushAggregatesLocked(); err != nil {
+		return err
+	}
+	l.sampling = sampling
+	return nil
+}
+
+// stepSampled reports whether the current step's rows are written
+func (l *Logger) stepSampled() bool {
+	switch l.sampling.Mode {
+	case RecordEveryN:
+		return l.step%l.sampling.EveryN == 0
+	case RecordAggregate:
+		return false
+	default:
+		return true
+	}
+}
+
+// recordStepMetric records a step-level value as a row, skips it, or adds
+// it to the episode's aggregate, depending on the step sampling
+func (l *Logger) recordStepMetric(metricType, metricName string, v float64, metadata string) error {
+	if l.sampling.Mode == RecordAggregate {
+		l.aggregateValue(metricType, metricName, v)
+		return nil
+	}
+	if !l.stepSampled() {
+		return nil
+	}
+	return l.recordMetric(l.sessionID, l.episode, l.step, metricType, metricName, v, metadata)
+}
+
+// aggregateValue adds a value to the current episode's aggregate
+func (l *Logger) aggregateValue(metricType, metricName string, v float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.aggregates == nil {
+		l.aggregates = make(map[metricKey]*aggregate)
+	}
+	key := metricKey{metricType, metricName}
+	a, ok := l.aggregates[key]
+	if !ok {
+		a = &aggregate{}
+		l.aggregates[key] = a
+	}
+	a.add(v)
+	l.aggregateStep = l.step
+}
+
+// flushAggregatesLocked writes one row per aggregated metric for the
+// current episode, valued at the mean so per-episode curves and analyses
+// keep working, with the full summary in the metadata. Callers must hold l.mu
+func (l *Logger) flushAggregatesLocked() error {
+	if l.pendingWeights != nil {
+		w := l.pendingWeights
+		l.pendingWeights = nil
+		if err := l.db.RecordWeights(l.sessionID, l.episode, w[0], w[1], w[2], w[3]); err != nil {
+			return err
+		}
+	}
+	if len(l.aggregates) == 0 {
+		return nil
+	}
+
+	keys := make([]metricKey, 0, len(l.aggregates))
+	for key := range l.aggregates {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].metricType != keys[j].metricType {
+			return keys[i].metricType < keys[j].metricType
+		}
+		return keys[i].metricName < keys[j].metricName
+	})
+
+	for _, key := range keys {
+		a := l.aggregates[key]
+		n := float64(a.count)
+		mean := a.sum / n
+		metadata, err := json.Marshal(map[string]interface{}{
+			"aggregate": true,
+			"count":     a.count,
+			"mean":      mean,
+			"std":       math.Sqrt(math.Max(0, a.sumSq/n-mean*mean)),
+			"min":       a.min,
+			"max":       a.max,
+			"mean_abs":  a.sumAbs / n,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal aggregate metadata: %w", err)
+		}
+		if err := l.recordMetric(l.sessionID, l.episode, l.aggregateStep, key.metricType, key.metricName, mean, string(metadata)); err != nil {
+			return err
+		}
+	}
+	l.aggregates = nil
+	return nil
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Session gives analysis tools read-only access to a session recorded in a
+// metrics database, e.g. by a finished or still running training process.
+// Unlike a Logger it never writes, so attaching does not add rows to the
+// session or end it
+type Session struct {
+	db   *DB
+	info SessionInfo
+}
+
+// OpenSession attaches to a recorded session
+func OpenSession(db *DB, sessionID string) (*Session, error) {
+	info, err := db.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{db: db, info: info}, nil
+}
+
+// GetSessionID returns the session's ID
+func (s *Session) GetSessionID() string {
+	return s.info.SessionID
+}
+
+// Info returns the session's metadata as of when it was opened
+func (s *Session) Info() SessionInfo {
+	return s.info
+}
+
+// LatestEpisode returns the highest episode the session recorded anything for
+func (s *Session) LatestEpisode() (int, error) {
+	return s.db.GetLatestEpisode(s.info.SessionID)
+}
+
+// Summary returns the session's episode count, success rate, averages and
+// weight changes
+func (s *Session) Summary() (map[string]interface{}, error) {
+	return s.db.GetSessionSummary(s.info.SessionID)
+}
+
+// EpisodeData returns detailed data for an episode
+func (s *Session) EpisodeData(episode int) (map[string]interface{}, error) {
+	return s.db.GetEpisodeData(s.info.SessionID, episode)
+}
+
+// LearningProgress analyzes the last lastNEpisodes episodes
+func (s *Session) LearningProgress(lastNEpisodes int) (map[string]interface{}, error) {
+	return s.db.GetLearningProgress(s.info.SessionID, lastNEpisodes)
+}
+
+// PredictionAccuracy analyzes the value predictions of an episode
+func (s *Session) PredictionAccuracy(episode int) (map[string]interface{}, error) {
+	return s.db.GetPredictionAccuracy(s.info.SessionID, episode)
+}
+
+// WeightChanges analyzes the weight updates of an episode
+func (s *Session) WeightChanges(episode int) (map[string]interface{}, error) {
+	return s.db.GetWeightChangeAnalysis(s.info.SessionID, episode)
+}
+
+// LearningIssues identifies potential learning problems
+func (s *Session) LearningIssues() (map[string]interface{}, error) {
+	return s.db.DetectLearningIssues(s.info.SessionID)
+}
+
+// StepTrace returns every logged step of an episode in order
+func (s *Session) StepTrace(episode int) ([]StepTrace, error) {
+	return s.db.GetStepTrace(s.info.SessionID, episode)
+}
+
+// StepMetrics returns a page of an episode's raw metric rows in step order
+func (s *Session) StepMetrics(episode, offset, limit int) ([]MetricRow, error) {
+	return s.db.GetStepMetrics(s.info.SessionID, episode, offset, limit)
+}
+
+// EpisodeCurve returns the session's episodes in order
+func (s *Session) EpisodeCurve() ([]EpisodePoint, error) {
+	return s.db.GetEpisodeCurve(s.info.SessionID)
+}
+
+// MetricCurve returns the per-episode mean of a metric
+func (s *Session) MetricCurve(metricType, metricName string) ([]MetricPoint, error) {
+	return s.db.GetMetricCurve(s.info.SessionID, metricType, metricName)
+}
+
+// Generations returns the session's recorded generations, oldest first
+func (s *Session) Generations() ([]GenerationStats, error) {
+	return s.db.GetGenerations(s.info.SessionID)
+}
+
+// Ancestry follows a genome's fitter parents back to the initial population
+func (s *Session) Ancestry(genome int) ([]Lineage, error) {
+	return s.db.GetAncestry(s.info.SessionID, genome)
+}
+
+// Failures returns how each of the session's episodes ended
+func (s *Session) Failures() ([]EpisodeFailure, error) {
+	return s.db.GetFailures(s.info.SessionID)
+}
+
+// FailureSummary breaks the session's episode endings down by failure mode
+func (s *Session) FailureSummary() (FailureSummary, error) {
+	failures, err := s.db.GetFailures(s.info.SessionID)
+	if err != nil {
+		return FailureSummary{}, err
+	}
+	return SummarizeFailures(failures), nil
+}
+
+// ValueSnapshots returns the session's value landscapes, oldest first
+func (s *Session) ValueSnapshots() ([]ValueSnapshot, error) {
+	return s.db.GetValueSnapshots(s.info.SessionID)
+}
+
+// GetLatestEpisode returns the highest episode a session recorded episodes,
+// weights or metrics for
+func (m *DB) GetLatestEpisode(sessionID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest sql.NullInt64
+	err := m.db.QueryRow(`
+		SELECT MAX(episode) FROM (
+			SELECT episode FROM training_episodes WHERE session_id = ?
+			UNION ALL SELECT episode FROM network_weights WHERE session_id = ?
+			UNION ALL SELECT episode FROM network_metrics WHERE session_id = ?
+		)
+	`, sessionID, sessionID, sessionID).Scan(&latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest episode: %w", err)
+	}
+	if !latest.Valid {
+		return 0, fmt.Errorf("no episodes recorded for session %s", sessionID)
+	}
+	return int(latest.Int64), nil
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// SessionInfo describes a training session and the context it ran in
+type SessionInfo struct {
+	SessionID       string
+	StartTime       time.Time
+	EndTime         time.Time // Zero while the session is still running
+	GitHash         string
+	ConfigJSON      string        // Resolved configuration as JSON
+	Hyperparameters string        // Hyperparameters as a JSON object
+	Budget          ComputeBudget // Training effort spent so far
+}
+
+// StartSession records the start of a training session
+func (m *DB) StartSession(info SessionInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if info.StartTime.IsZero() {
+		info.StartTime = time.Now()
+	}
+
+	_, err := m.db.Exec(`
+		INSERT INTO sessions (session_id, start_time, git_hash, config_json, hyperparameters)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET
+			git_hash = excluded.git_hash,
+			config_json = excluded.config_json,
+			hyperparameters = excluded.hyperparameters
+	`, info.SessionID, info.StartTime, info.GitHash, info.ConfigJSON, info.Hyperparameters)
+	if err != nil {
+		return fmt.Errorf("failed to record session start: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSessionConfig stores the configuration and hyperparameters used by a session
+func (m *DB) UpdateSessionConfig(sessionID, configJSON, hyperparameters string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		UPDATE sessions SET config_json = ?, hyperparameters = ? WHERE session_id = ?
+	`, configJSON, hyperparameters, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to update session config: %w", err)
+	}
+
+	return nil
+}
+
+// EndSession records the end time of a training session
+func (m *DB) EndSession(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.Exec(`
+		UPDATE sessions SET end_time = ? WHERE session_id = ?
+	`, time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to record session end: %w", err)
+	}
+
+	return nil
+}
+
+// ReopenSession marks a recorded session as running again so that a
+// resumed training run can keep appending to it
+func (m *DB) ReopenSession(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result, err := m.db.Exec(`
+		UPDATE sessions SET end_time = NULL WHERE session_id = ?
+	`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to reopen session: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	return nil
+}
+
+// ListSessions returns all recorded sessions ordered by start time
+func (m *DB) ListSessions() ([]SessionInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT session_id, start_time, end_time, git_hash, config_json, hyperparameters,
+			wall_clock_seconds, steps, forward_passes, backward_passes
+		FROM sessions
+		ORDER BY start_time, session_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []SessionInfo
+	for rows.Next() {
+		var info SessionInfo
+		var endTime sql.NullTime
+		var wallClock float64
+		if err := rows.Scan(&info.SessionID, &info.StartTime, &endTime,
+			&info.GitHash, &info.ConfigJSON, &info.Hyperparameters,
+			&wallClock, &info.Budget.Steps, &info.Budget.ForwardPasses, &info.Budget.BackwardPasses); err != nil {
+			return nil, fmt.Errorf("failed to scan session row: %w", err)
+		}
+		info.Budget.WallClock = time.Duration(wallClock * float64(time.Second))
+		if endTime.Valid {
+			info.EndTime = endTime.Time
+		}
+		sessions = append(sessions, info)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetSession returns the metadata for a single session
+func (m *DB) GetSession(sessionID string) (SessionInfo, error) {
+	sessions, err := m.ListSessions()
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	for _, info := range sessions {
+		if info.SessionID == sessionID {
+			return info, nil
+		}
+	}
+
+	return SessionInfo{}, fmt.Errorf("session not found: %s", sessionID)
+}
+
+// GitHash returns the current git commit hash, or an empty string when
+// not running inside a git checkout
+func GitHash() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
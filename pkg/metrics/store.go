@@ -0,0 +1,42 @@
+package metrics
+
+// Store persists what a Logger records. DB keeps it in SQLite; MemoryStore
+// keeps it in memory for tests and throwaway runs. The step trace methods
+// are unexported, so only this package provides Stores
+type Store interface {
+	StartSession(info SessionInfo) error
+	ReopenSession(sessionID string) error
+	UpdateSessionConfig(sessionID, configJSON, hyperparameters string) error
+	EndSession(sessionID string) error
+	UpdateSessionBudget(sessionID string, budget ComputeBudget) error
+
+	RecordMetric(sessionID string, episode, step int, metricType, metricName string, value float64, metadata string) error
+	RecordMetrics(rows []MetricRow) error
+	RecordWeights(sessionID string, episode int, angleWeight, angularVelWeight, bias, learningRate float64) error
+	RecordEpisode(sessionID string, episode int, totalReward float64, balanceTime int, maxAngle float64, steps int, success bool) error
+	RecordGeneration(sessionID string, stats GenerationStats, lineage []Lineage) error
+	RecordValueSnapshot(sessionID string, episode int, points []ValuePoint) error
+	RecordFailure(sessionID string, failure EpisodeFailure) error
+	recordStep(row stepRow) error
+	recordSteps(rows []stepRow) error
+
+	Close() error
+}
+
+// Analyzer runs the session analyses behind the Logger's Get and Analyze
+// methods. DB implements it with SQL; the Logger reports an error for
+// Stores that do not
+type Analyzer interface {
+	GetSessionSummary(sessionID string) (map[string]interface{}, error)
+	GetEpisodeData(sessionID string, episode int) (map[string]interface{}, error)
+	GetLearningProgress(sessionID string, lastNEpisodes int) (map[string]interface{}, error)
+	GetPredictionAccuracy(sessionID string, episode int) (map[string]interface{}, error)
+	GetWeightChangeAnalysis(sessionID string, episode int) (map[string]interface{}, error)
+	DetectLearningIssues(sessionID string) (map[string]interface{}, error)
+}
+
+var (
+	_ Store    = (*DB)(nil)
+	_ Analyzer = (*DB)(nil)
+	_ Store    = (*MemoryStore)(nil)
+)
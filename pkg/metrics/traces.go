@@ -0,0 +1,194 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// StepTrace is everything logged for one step of an episode, read from a
+// single step_traces row. Values that were not logged for the step are 0
+type StepTrace struct {
+	Episode          int
+	Step             int
+	Angle            float64 // Network input angle
+	AngularVel       float64 // Network input angular velocity
+	Force            float64 // Network output
+	Hidden           float64 // Hidden node pre-activation
+	StateValue       float64 // Predicted state value
+	Reward           float64 // Immediate reward
+	TDError          float64
+	AngleUpdate      float64
+	AngularVelUpdate float64
+	BiasUpdate       float64
+	Updated          bool // Weight updates were logged for this step
+}
+
+// UpdateMagnitude returns the summed absolute weight change of the step
+func (s StepTrace) UpdateMagnitude() float64 {
+	return math.Abs(s.AngleUpdate) + math.Abs(s.AngularVelUpdate) + math.Abs(s.BiasUpdate)
+}
+
+// stepRow is a partial step_traces row; NULL columns keep whatever an
+// earlier write for the same step stored
+type stepRow struct {
+	SessionID        string
+	Episode          int
+	Step             int
+	Angle            sql.NullFloat64
+	AngularVel       sql.NullFloat64
+	Force            sql.NullFloat64
+	Hidden           sql.NullFloat64
+	StateValue       sql.NullFloat64
+	Reward           sql.NullFloat64
+	TDError          sql.NullFloat64
+	AngleUpdate      sql.NullFloat64
+	AngularVelUpdate sql.NullFloat64
+	BiasUpdate       sql.NullFloat64
+}
+
+// value marks v as logged
+func value(v float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: v, Valid: true}
+}
+
+// upsertStepSQL merges a partial row into the step's single row, so the
+// forward pass, prediction, reward and update of a step can be logged
+// separately without a join to put them back together
+const upsertStepSQL = `
+	INSERT INTO step_traces (
+		session_id, episode, step, angle, angular_vel, force, hidden,
+		state_value, reward, td_error, angle_update, angular_vel_update, bias_update
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (session_id, episode, step) DO UPDATE SET
+		angle = COALESCE(excluded.angle, angle),
+		angular_vel = COALESCE(excluded.angular_vel, angular_vel),
+		force = COALESCE(excluded.force, force),
+		hidden = COALESCE(excluded.hidden, hidden),
+		state_value = COALESCE(excluded.state_value, state_value),
+		reward = COALESCE(excluded.reward, reward),
+		td_error = COALESCE(excluded.td_error, td_error),
+		angle_update = COALESCE(excluded.angle_update, angle_update),
+		angular_vel_update = COALESCE(excluded.angular_vel_update, angular_vel_update),
+		bias_update = COALESCE(excluded.bias_update, bias_update)
+`
+
+// args returns the row's values in upsertStepSQL order
+func (r stepRow) args() []interface{} {
+	return []interface{}{
+		r.SessionID, r.Episode, r.Step, r.Angle, r.AngularVel, r.Force, r.Hidden,
+		r.StateValue, r.Reward, r.TDError, r.AngleUpdate, r.AngularVelUpdate, r.BiasUpdate,
+	}
+}
+
+// recordStep merges a partial row into its step's trace
+func (m *DB) recordStep(row stepRow) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.db.Exec(upsertStepSQL, row.args()...); err != nil {
+		return fmt.Errorf("failed to record step trace: %w", err)
+	}
+	return nil
+}
+
+// recordSteps merges many partial rows in a single transaction
+func (m *DB) recordSteps(rows []stepRow) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin step trace batch: %w", err)
+	}
+
+	stmt, err := tx.Prepare(upsertStepSQL)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare step trace batch: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.Exec(r.args()...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record step trace batch: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit step trace batch: %w", err)
+	}
+	return nil
+}
+
+// GetStepTrace returns every logged step of an episode in order
+func (m *DB) GetStepTrace(sessionID string, episode int) ([]StepTrace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, step,
+			COALESCE(angle, 0), COALESCE(angular_vel, 0), COALESCE(force, 0), COALESCE(hidden, 0),
+			COALESCE(state_value, 0), COALESCE(reward, 0), COALESCE(td_error, 0),
+			COALESCE(angle_update, 0), COALESCE(angular_vel_update, 0), COALESCE(bias_update, 0),
+			angle_update IS NOT NULL OR angular_vel_update IS NOT NULL OR bias_update IS NOT NULL
+		FROM step_traces
+		WHERE session_id = ? AND episode = ?
+		ORDER BY step
+	`, sessionID, episode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query step trace: %w", err)
+	}
+	defer rows.Close()
+
+	var trace []StepTrace
+	for rows.Next() {
+		var s StepTrace
+		if err := rows.Scan(&s.Episode, &s.Step,
+			&s.Angle, &s.AngularVel, &s.Force, &s.Hidden,
+			&s.StateValue, &s.Reward, &s.TDError,
+			&s.AngleUpdate, &s.AngularVelUpdate, &s.BiasUpdate, &s.Updated); err != nil {
+			return nil, fmt.Errorf("failed to scan step trace row: %w", err)
+		}
+		trace = append(trace, s)
+	}
+
+	return trace, rows.Err()
+}
+
+// GetStepMetrics returns up to limit of an episode's raw metric rows, of
+// every type, ordered by step and then by when they were logged, skipping
+// the first offset. Paging through long episodes this way keeps memory
+// bounded; a page shorter than limit is the last
+func (m *DB) GetStepMetrics(sessionID string, episode, offset, limit int) ([]MetricRow, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("invalid page: offset %d, limit %d", offset, limit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT session_id, episode, step, metric_type, metric_name, value, COALESCE(metadata, '')
+		FROM network_metrics
+		WHERE session_id = ? AND episode = ?
+		ORDER BY step, id
+		LIMIT ? OFFSET ?
+	`, sessionID, episode, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query step metrics: %w", err)
+	}
+	defer rows.Close()
+
+	page := make([]MetricRow, 0, min(limit, 1024))
+	for rows.Next() {
+		var r MetricRow
+		if err := rows.Scan(&r.SessionID, &r.Episode, &r.Step, &r.MetricType, &r.MetricName, &r.Value, &r.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan step metric row: %w", err)
+		}
+		page = append(page, r)
+	}
+
+	return page, rows.Err()
+}
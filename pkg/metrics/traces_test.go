@@ -0,0 +1,257 @@
+package metrics
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+)
+
+func TestStepTrace(t *testing.T) {
+	for _, batched := range []bool{false, true} {
+		name := "direct"
+		if batched {
+			name = "batched"
+		}
+		t.Run(name, func(t *testing.T) {
+			logger, err := NewLogger(filepath.Join(t.TempDir(), "metrics.db"), false, log.New(io.Discard, "", 0))
+			if err != nil {
+				t.Fatalf("NewLogger failed: %v", err)
+			}
+			defer logger.Close()
+			if batched {
+				logger.EnableBatching(NewDefaultBatchConfig())
+			}
+
+			// Step 1 is logged piecemeal and must come back as one row
+			logger.SetEpisode(3)
+			logger.IncrementStep()
+			logger.LogForwardPass(0.1, -0.2, 1.5, 0.3)
+			logger.LogPrediction(0.1, -0.2, 0.9)
+			logger.LogReward("immediate", 0.8)
+			logger.LogTDTarget(1.0, 0.1, 0.99, 0)
+			logger.LogWeightUpdateDetails(0.1, -0.2, 1.5, 0.8, 0.01, -0.02, 0.005, 0.05)
+
+			// Step 2 has a forward pass but no update
+			logger.IncrementStep()
+			logger.LogForwardPass(0.2, 0.1, -1.0, -0.2)
+
+			if err := logger.Flush(); err != nil {
+				t.Fatalf("Flush failed: %v", err)
+			}
+
+			trace, err := logger.db.(*DB).GetStepTrace(logger.GetSessionID(), 3)
+			if err != nil {
+				t.Fatalf("GetStepTrace failed: %v", err)
+			}
+			if len(trace) != 2 {
+				t.Fatalf("got %d steps, want 2: %+v", len(trace), trace)
+			}
+
+			first := trace[0]
+			want := StepTrace{
+				Episode: 3, Step: 1,
+				Angle: 0.1, AngularVel: -0.2, Force: 1.5, Hidden: 0.3,
+				StateValue: 0.9, Reward: 0.8, TDError: 0.1,
+				AngleUpdate: 0.01, AngularVelUpdate: -0.02, BiasUpdate: 0.005,
+				Updated: true,
+			}
+			if first != want {
+				t.Errorf("step 1 = %+v, want %+v", first, want)
+			}
+			if second := trace[1]; second.Updated || second.Force != -1.0 {
+				t.Errorf("step 2 = %+v, want a forward pass without updates", second)
+			}
+
+			analysis, err := logger.AnalyzeWeightChanges(3)
+			if err != nil {
+				t.Fatalf("AnalyzeWeightChanges failed: %v", err)
+			}
+			updates, _ := analysis["weight_updates"].([]map[string]interface{})
+			if len(updates) != 1 || updates[0]["step"] != 1 || updates[0]["reward"] != 0.8 {
+				t.Errorf("weight_updates = %v, want step 1 only", analysis["weight_updates"])
+			}
+		})
+	}
+}
+
+func TestGetStepMetrics(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "metrics.db"))
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	// Rows arrive out of step order, with another episode interleaved
+	var rows []MetricRow
+	for step := 5; step >= 1; step-- {
+		rows = append(rows,
+			MetricRow{SessionID: "s", Episode: 2, Step: step, MetricType: "reward", MetricName: "total", Value: float64(step)},
+			MetricRow{SessionID: "s", Episode: 2, Step: step, MetricType: "energy", MetricName: "total", Value: -float64(step), Metadata: `{"kinetic":1}`},
+			MetricRow{SessionID: "s", Episode: 1, Step: step, MetricType: "reward", MetricName: "total"},
+		)
+	}
+	if err := db.RecordMetrics(rows); err != nil {
+		t.Fatalf("RecordMetrics failed: %v", err)
+	}
+
+	var all []MetricRow
+	for offset := 0; ; offset += 3 {
+		page, err := db.GetStepMetrics("s", 2, offset, 3)
+		if err != nil {
+			t.Fatalf("GetStepMetrics(offset %d) failed: %v", offset, err)
+		}
+		all = append(all, page...)
+		if len(page) < 3 {
+			break
+		}
+	}
+	if len(all) != 10 {
+		t.Fatalf("paged %d rows, want the episode's 10", len(all))
+	}
+	for i, r := range all {
+		if r.Episode != 2 || r.Step != i/2+1 {
+			t.Fatalf("row %d = %+v, want episode 2 step %d", i, r, i/2+1)
+		}
+		if want := []string{"reward", "energy"}[i%2]; r.MetricType != want {
+			t.Errorf("row %d has type %s, want %s in logging order", i, r.MetricType, want)
+		}
+	}
+	if all[1].Metadata != `{"kinetic":1}` || all[1].Value != -1 {
+		t.Errorf("row 1 = %+v, want the energy value and metadata", all[1])
+	}
+
+	if _, err := db.GetStepMetrics("s", 2, 0, 0); err == nil {
+		t.Error("GetStepMetrics with limit 0 succeeded, want error")
+	}
+}
+
+func TestDiscountSweep(t *testing.T) {
+	if got := DiscountedReturns([]float64{1, 1, 1}, 0.5); got[0] != 1.75 || got[1] != 1.5 || got[2] != 1 {
+		t.Errorf("DiscountedReturns = %v, want [1.75 1.5 1]", got)
+	}
+
+	logger, err := NewLogger(filepath.Join(t.TempDir(), "metrics.db"), false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	// Values that predict the returns under γ=0.9 exactly, from rewards that
+	// vary enough for the discounts to disagree
+	for episode := 1; episode <= 3; episode++ {
+		rewards := make([]float64, 40)
+		for i := range rewards {
+			rewards[i] = float64((i*episode)%7) - 3
+		}
+		returns := DiscountedReturns(rewards, 0.9)
+		logger.SetEpisode(episode)
+		for i := range rewards {
+			logger.IncrementStep()
+			logger.LogPrediction(0, 0, returns[i])
+			logger.LogReward("immediate", rewards[i])
+			logger.LogTDTarget(0, 0, 0.99, 0)
+		}
+	}
+	session, err := OpenSession(logger.db.(*DB), logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+
+	sweep, err := session.DiscountSweep(10, DefaultDiscounts)
+	if err != nil {
+		t.Fatalf("DiscountSweep failed: %v", err)
+	}
+	if sweep.Episodes != 3 || sweep.Steps != 120 || len(sweep.Fits) != len(DefaultDiscounts) {
+		t.Errorf("swept %d episodes, %d steps, %d fits; want 3, 120, %d", sweep.Episodes, sweep.Steps, len(sweep.Fits), len(DefaultDiscounts))
+	}
+	if sweep.Best.Gamma != 0.9 || sweep.Best.Correlation < 0.999 {
+		t.Errorf("best fit = %+v, want γ=0.9 with a perfect correlation", sweep.Best)
+	}
+	if sweep.Trained != 0.99 {
+		t.Errorf("trained discount = %v, want the TD targets' 0.99", sweep.Trained)
+	}
+
+	// Missing steps make an episode's returns meaningless
+	gappy := [][]StepTrace{{{Step: 1, Reward: 1}, {Step: 3, Reward: 1}}}
+	if s := SweepDiscounts(gappy, []float64{0.9}); s.Skipped != 1 || s.Steps != 0 {
+		t.Errorf("sweep of an episode with a gap = %+v, want it skipped", s)
+	}
+}
+
+func TestValueCalibration(t *testing.T) {
+	c := CalibrateValues([]float64{1, 2, 3, 4}, []float64{3, 4, 5, 6})
+	if c.Steps != 4 || c.Bias != -2 || c.RMSE != 2 || c.RankCorrelation != 1 {
+		t.Errorf("calibration of values 2 below their returns = %+v, want bias -2, RMSE 2, rank 1", c)
+	}
+	if got := ranks([]float64{3, 1, 3, 2}); got[0] != 3.5 || got[1] != 1 || got[2] != 3.5 || got[3] != 2 {
+		t.Errorf("ranks = %v, want ties sharing rank 3.5", got)
+	}
+
+	tracker := NewCalibrationTracker(0.5)
+	for _, reward := range []float64{1, 1, 1} {
+		tracker.Step(0, reward)
+	}
+	tracker.EndEpisode()
+	tracker.Step(5, 2) // Unfinished episodes are left out
+	if c := tracker.Calibration(); c.Steps != 3 || c.Gamma != 0.5 || c.Bias != -(1.75+1.5+1)/3 {
+		t.Errorf("tracked calibration = %+v, want 3 steps against returns 1.75, 1.5, 1", c)
+	}
+	tracker.Reset()
+	if c := tracker.Calibration(); c.Steps != 0 {
+		t.Errorf("calibration after Reset = %+v, want no steps", c)
+	}
+
+	logger, err := NewLogger(filepath.Join(t.TempDir(), "metrics.db"), false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+	for checkpoint := 1; checkpoint <= 2; checkpoint++ {
+		logger.SetEpisode(10 * checkpoint)
+		if err := logger.LogCalibration(ValueCalibration{Checkpoint: checkpoint, Gamma: 0.99, Steps: 100, Bias: -float64(checkpoint), RMSE: 3, RankCorrelation: 0.5}); err != nil {
+			t.Fatalf("LogCalibration failed: %v", err)
+		}
+	}
+	session, err := OpenSession(logger.db.(*DB), logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+	calibrations, err := session.Calibrations()
+	if err != nil {
+		t.Fatalf("Calibrations failed: %v", err)
+	}
+	want := ValueCalibration{Checkpoint: 2, Episode: 20, Gamma: 0.99, Steps: 100, Bias: -2, RMSE: 3, RankCorrelation: 0.5}
+	if len(calibrations) != 2 || calibrations[1] != want {
+		t.Errorf("calibrations = %+v, want the second to be %+v", calibrations, want)
+	}
+}
+
+func TestRewardComponents(t *testing.T) {
+	logger, err := NewLogger(filepath.Join(t.TempDir(), "metrics.db"), false, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+	for episode := 1; episode <= 3; episode++ {
+		logger.SetEpisode(episode)
+		if err := logger.LogRewardComponents(100*float64(episode), 2, float64(episode), 200); err != nil {
+			t.Fatalf("LogRewardComponents failed: %v", err)
+		}
+	}
+	session, err := OpenSession(logger.db.(*DB), logger.GetSessionID())
+	if err != nil {
+		t.Fatalf("OpenSession failed: %v", err)
+	}
+	episodes, err := session.RewardComponents()
+	if err != nil {
+		t.Fatalf("RewardComponents failed: %v", err)
+	}
+	want := RewardComponents{Episode: 3, Steps: 200, Angle: 300, Position: 2, Effort: 3}
+	if len(episodes) != 3 || episodes[2] != want {
+		t.Fatalf("reward components = %+v, want the third to be %+v", episodes, want)
+	}
+	if got := episodes[2].Total(); got != 295 {
+		t.Errorf("total = %v, want 295", got)
+	}
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+)
+
+// ValueGrid is the fixed set of (angle, angular velocity) states a value
+// snapshot samples, so snapshots taken at different episodes line up
+type ValueGrid struct {
+	AngleRange float64 // Angles span [-AngleRange, AngleRange] around upright
+	VelRange   float64 // Angular velocities span [-VelRange, VelRange]
+	AngleSteps int     // Samples across the angle range, at least 2
+	VelSteps   int     // Samples across the angular velocity range, at least 2
+}
+
+// NewDefaultValueGrid covers every angle and the angular velocities seen
+// while swinging up, matching cmd/policyviz's default ranges
+func NewDefaultValueGrid() ValueGrid {
+	return ValueGrid{AngleRange: math.Pi, VelRange: 8.0, AngleSteps: 25, VelSteps: 17}
+}
+
+// ValuePoint is the value predicted for one grid state
+type ValuePoint struct {
+	Angle      float64
+	AngularVel float64
+	Value      float64
+}
+
+// ValueSnapshot is the value landscape at the start of an episode
+type ValueSnapshot struct {
+	Episode int
+	Points  []ValuePoint // Angle varies fastest, then angular velocity, both ascending
+}
+
+// Sample evaluates value at every grid state
+func (g ValueGrid) Sample(value func(angle, angularVel float64) float64) []ValuePoint {
+	angleSteps, velSteps := max(2, g.AngleSteps), max(2, g.VelSteps)
+	points := make([]ValuePoint, 0, angleSteps*velSteps)
+	for j := 0; j < velSteps; j++ {
+		vel := -g.VelRange + 2*g.VelRange*float64(j)/float64(velSteps-1)
+		for i := 0; i < angleSteps; i++ {
+			angle := -g.AngleRange + 2*g.AngleRange*float64(i)/float64(angleSteps-1)
+			points = append(points, ValuePoint{Angle: angle, AngularVel: vel, Value: value(angle, vel)})
+		}
+	}
+	return points
+}
+
+// SetValueSnapshots records the value landscape over grid every episodes
+// episodes, when the network reports it with LogValueLandscape. Zero, the
+// default, records none
+func (l *Logger) SetValueSnapshots(every int, grid ValueGrid) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.valueEvery = every
+	l.valueGrid = grid
+}
+
+// LogValueLandscape samples value over the snapshot grid and records it for
+// the current episode, if a snapshot is due
+func (l *Logger) LogValueLandscape(value func(angle, angularVel float64) float64) error {
+	l.mu.Lock()
+	every, grid, episode := l.valueEvery, l.valueGrid, l.episode
+	l.mu.Unlock()
+	if every <= 0 || episode%every != 0 {
+		return nil
+	}
+	return l.db.RecordValueSnapshot(l.sessionID, episode, grid.Sample(value))
+}
+
+// RecordValueSnapshot stores a value landscape in a single transaction,
+// replacing an earlier snapshot of the same episode
+func (m *DB) RecordValueSnapshot(sessionID string, episode int, points []ValuePoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin value snapshot: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO value_snapshots (session_id, episode, angle, angular_vel, value)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare value snapshot: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		if _, err := stmt.Exec(sessionID, episode, p.Angle, p.AngularVel, p.Value); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record value snapshot of episode %d: %w", episode, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit value snapshot of episode %d: %w", episode, err)
+	}
+	return nil
+}
+
+// GetValueSnapshots returns every value landscape recorded for a session,
+// oldest first
+func (m *DB) GetValueSnapshots(sessionID string) ([]ValueSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.db.Query(`
+		SELECT episode, angle, angular_vel, value
+		FROM value_snapshots
+		WHERE session_id = ?
+		ORDER BY episode, angular_vel, angle
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query value snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []ValueSnapshot
+	for rows.Next() {
+		var episode int
+		var p ValuePoint
+		if err := rows.Scan(&episode, &p.Angle, &p.AngularVel, &p.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan value snapshot row: %w", err)
+		}
+		if n := len(snapshots); n == 0 || snapshots[n-1].Episode != episode {
+			snapshots = append(snapshots, ValueSnapshot{Episode: episode})
+		}
+		last := &snapshots[len(snapshots)-1]
+		last.Points = append(last.Points, p)
+	}
+
+	return snapshots, rows.Err()
+}
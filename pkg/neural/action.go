@@ -0,0 +1,91 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Action space types accepted by NewActionSpace
+const (
+	ContinuousActions = "continuous" // Any force in [-MaxForce, MaxForce]
+	BangBangActions   = "bang-bang"  // -MaxForce, 0 or +MaxForce, as in Pezzza's original
+	DiscreteActions   = "discrete"   // Bins forces evenly spaced across [-MaxForce, MaxForce]
+)
+
+// ActionSpace maps the network's continuous output to the forces it may apply
+type ActionSpace struct {
+	Type     string  `json:"type"`           // "continuous", "bang-bang" or "discrete"
+	Bins     int     `json:"bins,omitempty"` // Number of forces in discrete mode, at least 2
+	MaxForce float64 `json:"max_force"`      // Largest force magnitude in N
+}
+
+// NewDefaultActionSpace returns the continuous ±5N output the network has always used
+func NewDefaultActionSpace() ActionSpace {
+	return ActionSpace{Type: ContinuousActions, MaxForce: 5.0}
+}
+
+// NewActionSpace creates an action space of the given type with forces up to
+// ±5N; bins is only used in discrete mode
+func NewActionSpace(kind string, bins int) (ActionSpace, error) {
+	space := ActionSpace{Type: strings.ToLower(kind), MaxForce: 5.0}
+	switch space.Type {
+	case ContinuousActions, BangBangActions:
+	case DiscreteActions:
+		if bins < 2 {
+			return ActionSpace{}, fmt.Errorf("discrete action space needs at least 2 bins, got %d", bins)
+		}
+		space.Bins = bins
+	default:
+		return ActionSpace{}, fmt.Errorf("unknown action space %q (want continuous, bang-bang or discrete)", kind)
+	}
+	return space, nil
+}
+
+// Map converts a continuous force in [-MaxForce, MaxForce] to the nearest
+// force allowed by the action space. The zero ActionSpace passes forces through
+func (a ActionSpace) Map(force float64) float64 {
+	if a.MaxForce <= 0 {
+		return force
+	}
+	force = clip(force, -a.MaxForce, a.MaxForce)
+	switch a.Type {
+	case BangBangActions:
+		// Equal thirds of the output range push left, coast or push right
+		if math.Abs(force) < a.MaxForce/3 {
+			return 0
+		}
+		return sign(force) * a.MaxForce
+	case DiscreteActions:
+		if a.Bins < 2 {
+			return force
+		}
+		step := 2 * a.MaxForce / float64(a.Bins-1)
+		bin := math.Round((force + a.MaxForce) / step)
+		return -a.MaxForce + float64(bin*step)
+	default:
+		return force
+	}
+}
+
+// String describes the action space for display, e.g. "discrete (5 bins)"
+func (a ActionSpace) String() string {
+	switch a.Type {
+	case BangBangActions:
+		return fmt.Sprintf("bang-bang (-%g/0/+%g N)", a.MaxForce, a.MaxForce)
+	case DiscreteActions:
+		return fmt.Sprintf("discrete (%d bins)", a.Bins)
+	default:
+		return fmt.Sprintf("continuous (±%g N)", a.MaxForce)
+	}
+}
+
+// SetActionSpace sets how the network's output is mapped to a force
+func (n *Network) SetActionSpace(space ActionSpace) {
+	n.actionSpace = space
+}
+
+// GetActionSpace returns how the network's output is mapped to a force
+func (n *Network) GetActionSpace() ActionSpace {
+	return n.actionSpace
+}
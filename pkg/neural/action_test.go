@@ -0,0 +1,81 @@
+package neural
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestActionSpace(t *testing.T) {
+	t.Run("continuous passes forces through", func(t *testing.T) {
+		space := NewDefaultActionSpace()
+		for _, force := range []float64{-5, -1.234, 0, 3.3} {
+			if got := space.Map(force); got != force {
+				t.Errorf("Map(%v) = %v, want unchanged", force, got)
+			}
+		}
+		if got := space.Map(7); got != 5 {
+			t.Errorf("Map(7) = %v, want clipped to 5", got)
+		}
+	})
+
+	t.Run("bang-bang", func(t *testing.T) {
+		space, err := NewActionSpace(BangBangActions, 0)
+		if err != nil {
+			t.Fatalf("NewActionSpace failed: %v", err)
+		}
+		cases := map[float64]float64{-4: -5, -1: 0, 0: 0, 1.5: 0, 2: 5, 5: 5}
+		for force, want := range cases {
+			if got := space.Map(force); got != want {
+				t.Errorf("Map(%v) = %v, want %v", force, got, want)
+			}
+		}
+	})
+
+	t.Run("discrete bins", func(t *testing.T) {
+		space, err := NewActionSpace(DiscreteActions, 5)
+		if err != nil {
+			t.Fatalf("NewActionSpace failed: %v", err)
+		}
+		cases := map[float64]float64{-5: -5, -3.9: -5, -3: -2.5, 0.9: 0, 1.3: 2.5, 4.9: 5}
+		for force, want := range cases {
+			if got := space.Map(force); math.Abs(got-want) > 1e-12 {
+				t.Errorf("Map(%v) = %v, want %v", force, got, want)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := NewActionSpace("fuzzy", 0); err == nil {
+			t.Error("expected error for an unknown action space")
+		}
+		if _, err := NewActionSpace(DiscreteActions, 1); err == nil {
+			t.Error("expected error for a single discrete bin")
+		}
+	})
+
+	t.Run("network output and persistence", func(t *testing.T) {
+		network := NewNetwork()
+		space, _ := NewActionSpace(BangBangActions, 0)
+		network.SetActionSpace(space)
+
+		// A pendulum leaning right is pushed with the full force
+		if force := network.Forward(env.State{AngleRadians: 0.5}); math.Abs(force) != 5 {
+			t.Errorf("bang-bang Forward = %v, want ±5", force)
+		}
+
+		path := filepath.Join(t.TempDir(), "network.json")
+		if err := network.SaveToFile(path); err != nil {
+			t.Fatalf("SaveToFile failed: %v", err)
+		}
+		loaded := NewNetwork()
+		if err := loaded.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile failed: %v", err)
+		}
+		if loaded.GetActionSpace() != space {
+			t.Errorf("loaded action space = %+v, want %+v", loaded.GetActionSpace(), space)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package neural
+
+import (
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// Attribution splits the hidden node's pre-activation into what each of its
+// terms contributed, to answer why the network pushed the way it did. The
+// contributions sum to Hidden, and the force is tanh(Hidden) scaled and
+// snapped to the action space, so the term with the largest magnitude is the
+// one driving the force
+type Attribution struct {
+	Angle      float64 // -angleWeight·angle
+	AngularVel float64 // -angularVelWeight·angular velocity
+	Bias       float64
+	Features   float64 // Engineered and stacked features beyond angle and angular velocity
+	Recurrent  float64 // The recurrent cell's output, 0 without a cell
+	Hidden     float64 // Pre-activation of the hidden node
+}
+
+// AttributionTerm is one named contribution to the hidden pre-activation
+type AttributionTerm struct {
+	Name  string
+	Value float64
+}
+
+// Terms returns the contributions in a fixed order: angle, angular
+// velocity, bias, features and recurrent
+func (a Attribution) Terms() []AttributionTerm {
+	return []AttributionTerm{
+		{"angle", a.Angle},
+		{"angular_vel", a.AngularVel},
+		{"bias", a.Bias},
+		{"features", a.Features},
+		{"recurrent", a.Recurrent},
+	}
+}
+
+// Dominant returns the term with the largest magnitude, the first in Terms
+// order on ties
+func (a Attribution) Dominant() AttributionTerm {
+	terms := a.Terms()
+	dominant := terms[0]
+	for _, t := range terms[1:] {
+		if math.Abs(t.Value) > math.Abs(dominant.Value) {
+			dominant = t
+		}
+	}
+	return dominant
+}
+
+// Opposes reports whether the dominant term pushes the hidden node against
+// the sign of the overall pre-activation, i.e. the other terms outvoted it
+func (a Attribution) Opposes() bool {
+	return a.Dominant().Value*a.Hidden < 0
+}
+
+// LastAttribution decomposes the hidden pre-activation of the last recorded
+// forward pass. It returns zeros when the inputs have changed since, e.g.
+// after a new observation transformer is set
+func (n *Network) LastAttribution() Attribution {
+	if len(n.lastInputs) != 2+len(n.featureWeights) {
+		return Attribution{}
+	}
+	recurrent := 0.0
+	if n.recurrent != nil {
+		// The cell's state is the one the last pass recorded
+		recurrent = n.recurrent.weights[n.recurrent.out()] * n.recurrent.state
+	}
+	return n.attribute(n.lastInputs, recurrent)
+}
+
+// Attribute decomposes the hidden pre-activation the network would compute
+// for state, without recording the pass or updating observation statistics
+func (n *Network) Attribute(state env.State) Attribution {
+	inputs := n.inputs(nil, state, false)
+	return n.attribute(inputs, n.recurrentOutput(inputs))
+}
+
+// attribute splits the pre-activation for inputs the way output sums it, so
+// Hidden matches output's bit for bit
+func (n *Network) attribute(inputs []float64, recurrent float64) Attribution {
+	a := Attribution{
+		Angle:      -float64(n.angleWeight * inputs[0]),
+		AngularVel: -float64(n.angularVelWeight * inputs[1]),
+		Bias:       n.bias,
+		Recurrent:  recurrent,
+	}
+	a.Hidden = a.Angle + a.AngularVel + a.Bias
+	for i, w := range n.featureWeights {
+		term := float64(w * inputs[2+i])
+		a.Features -= term
+		a.Hidden -= term
+	}
+	if n.recurrent != nil {
+		a.Hidden += recurrent
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestAttribution(t *testing.T) {
+	states := make([]env.State, 5)
+	for i := range states {
+		states[i] = env.State{AngleRadians: 0.2 * math.Sin(float64(i)), AngularVel: -0.5 * math.Cos(float64(i)), CartPosition: 0.1 * float64(i)}
+	}
+
+	networks := map[string]func() *Network{
+		"plain": NewNetwork,
+		"features and recurrent": func() *Network {
+			network := NewNetwork()
+			network.SetObservationTransformer(NewObservationTransformer(FeatureConfig{SinCos: true, CartState: true, Frames: 2}))
+			network.EnableRecurrent()
+			rng := rand.New(rand.NewSource(1))
+			features := network.GetFeatureWeights()
+			for i := range features {
+				features[i] = rng.NormFloat64()
+			}
+			network.SetFeatureWeights(features)
+			recurrent := network.GetRecurrentWeights()
+			for i := range recurrent {
+				recurrent[i] = rng.NormFloat64()
+			}
+			network.SetRecurrentWeights(recurrent)
+			return network
+		},
+	}
+	for name, newNetwork := range networks {
+		t.Run(name, func(t *testing.T) {
+			network := newNetwork()
+			if a := network.LastAttribution(); a != (Attribution{}) {
+				t.Errorf("attribution before any pass = %+v, want zeros", a)
+			}
+			for i, s := range states {
+				predicted := network.Attribute(s)
+				_, hidden := network.ForwardWithActivation(s)
+				a := network.LastAttribution()
+				if a.Hidden != hidden || predicted.Hidden != hidden {
+					t.Errorf("step %d: attributed hidden %v (predicted %v), want %v bit for bit", i, a.Hidden, predicted.Hidden, hidden)
+				}
+				if sum := a.Angle + a.AngularVel + a.Bias + a.Features + a.Recurrent; math.Abs(sum-hidden) > 1e-12 {
+					t.Errorf("step %d: terms sum to %v, want %v", i, sum, hidden)
+				}
+			}
+		})
+	}
+
+	t.Run("dominant", func(t *testing.T) {
+		a := Attribution{Angle: -0.3, AngularVel: 1.2, Bias: 0.1, Hidden: 1.0}
+		if d := a.Dominant(); d.Name != "angular_vel" || d.Value != 1.2 || a.Opposes() {
+			t.Errorf("Dominant() = %+v, Opposes() = %v; want angular_vel driving the force", d, a.Opposes())
+		}
+		a = Attribution{Angle: 1.0, AngularVel: -0.8, Bias: -0.5, Hidden: -0.3}
+		if !a.Opposes() {
+			t.Error("Opposes() = false, want the angle outvoted")
+		}
+	})
+}
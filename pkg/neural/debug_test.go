@@ -1,33 +1,19 @@
 package neural
 
 import (
-	"fmt"
 	"log"
 	"math"
 	"os"
-	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
 )
 
 func TestNetworkLearningDebug(t *testing.T) {
-	// Create a test directory for metrics
-	testDir := filepath.Join(os.TempDir(), fmt.Sprintf("neural_test_%d", time.Now().UnixNano()))
-	err := os.MkdirAll(testDir, 0755)
-	if err != nil {
-		t.Fatalf("Failed to create test directory: %v", err)
-	}
-	defer os.RemoveAll(testDir)
-
-	// Create metrics database path
-	dbPath := filepath.Join(testDir, "metrics.db")
-	
-	// Create a logger for metrics
+	// Create a logger for metrics, kept in memory
 	stdLogger := log.New(os.Stdout, "[Test] ", log.LstdFlags)
-	logger, err := metrics.NewLogger(dbPath, true, stdLogger)
+	logger, err := metrics.NewStoreLogger(metrics.NewMemoryStore(), true, stdLogger)
 	if err != nil {
 		t.Fatalf("Failed to create metrics logger: %v", err)
 	}
@@ -0,0 +1,24 @@
+package neural
+
+// SetEvalMode freezes the network for evaluation, or returns it to
+// training. In evaluation mode Forward acts on the mean of a stochastic
+// policy, so no noise is drawn from the training random source, and it
+// neither stores the pass for learning, updates the observation statistics
+// nor records metrics. Update, UpdateTD and Predict leave the weights,
+// traces, success window and difficulty untouched, so evaluation runs cannot
+// leak into training statistics. Stacked frames and the recurrent state
+// still advance, as the policy needs them; switching modes resets that
+// history, so evaluation episodes start fresh and training resumes with a
+// new episode
+func (n *Network) SetEvalMode(enabled bool) {
+	if n.evalMode == enabled {
+		return
+	}
+	n.evalMode = enabled
+	n.ResetHistory()
+}
+
+// EvalMode reports whether the network is frozen for evaluation
+func (n *Network) EvalMode() bool {
+	return n.evalMode
+}
@@ -0,0 +1,92 @@
+package neural
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestEvalMode(t *testing.T) {
+	state := env.State{AngleRadians: 0.1, AngularVel: -0.2}
+
+	t.Run("acts on the mean without drawing training noise", func(t *testing.T) {
+		network := NewNetwork()
+		network.EnableStochasticPolicy(NewDefaultStochasticPolicy(), rand.New(rand.NewSource(3)))
+		twin := rand.New(rand.NewSource(3))
+
+		network.SetEvalMode(true)
+		mean := network.Policy(state)
+		for i := 0; i < 10; i++ {
+			if got := network.Forward(state); got != mean {
+				t.Fatalf("Forward in eval mode = %v, want the mean %v", got, mean)
+			}
+		}
+
+		// Training resumes with the noise it would have drawn had no evaluation run
+		network.SetEvalMode(false)
+		network.Forward(state)
+		if want := twin.NormFloat64(); network.stochastic.lastSample-network.stochastic.lastMean != want*network.stochastic.std() {
+			t.Errorf("first training sample after eval drew from a different point of the random stream")
+		}
+	})
+
+	t.Run("updates leave weights and statistics untouched", func(t *testing.T) {
+		network := NewNetwork()
+		network.SetTraceDecay(0.9)
+		network.Forward(state)
+		network.UpdateTD(1, state, false)
+		weights, traces := network.GetWeights(), append([]float64(nil), network.traces...)
+		successRate, window := network.GetSuccessRate(), len(network.successWindow)
+
+		network.SetEvalMode(true)
+		for i := 0; i < 20; i++ {
+			network.Forward(state)
+			network.Update(-1)
+			network.UpdateTD(-1, state, i == 19)
+			network.Predict(state.AngleRadians, state.AngularVel)
+		}
+
+		for i, w := range network.GetWeights() {
+			if w != weights[i] {
+				t.Fatalf("weights = %v after eval, want %v", network.GetWeights(), weights)
+			}
+		}
+		for i, trace := range network.traces {
+			if trace != traces[i] {
+				t.Fatalf("traces = %v after eval, want %v", network.traces, traces)
+			}
+		}
+		if network.GetSuccessRate() != successRate || len(network.successWindow) != window {
+			t.Errorf("success tracking changed during eval: rate %v, window %d", network.GetSuccessRate(), len(network.successWindow))
+		}
+	})
+
+	t.Run("switching modes resets the input history", func(t *testing.T) {
+		network := NewNetwork()
+		network.SetObservationTransformer(NewObservationTransformer(FeatureConfig{Frames: 2, Normalize: true}))
+		network.Forward(state)
+		count := network.observer.count
+
+		network.SetEvalMode(true)
+		if !network.EvalMode() {
+			t.Fatal("EvalMode = false after SetEvalMode(true)")
+		}
+		if n := len(network.observer.history); n != 0 {
+			t.Fatalf("eval starts with %d training frames, want none", n)
+		}
+
+		// Stacked frames still advance while evaluating
+		network.Forward(state)
+		if n := len(network.observer.history); n != 1 {
+			t.Fatalf("history holds %d frames after an eval pass, want 1", n)
+		}
+		if network.observer.count != count {
+			t.Errorf("eval pass updated the normalization statistics")
+		}
+		network.SetEvalMode(false)
+		if n := len(network.observer.history); n != 0 {
+			t.Errorf("training resumes with %d eval frames, want none", n)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+)
+
+// SetMaxGradNorm limits the L2 norm of each update's gradient; 0 disables clipping
+func (n *Network) SetMaxGradNorm(maxNorm float64) {
+	n.maxGradNorm = maxNorm
+}
+
+// GetMaxGradNorm returns the gradient norm limit, 0 if clipping is disabled
+func (n *Network) GetMaxGradNorm() float64 {
+	return n.maxGradNorm
+}
+
+// NonFiniteUpdates returns how many updates were rejected because they
+// would have left a weight NaN or Inf
+func (n *Network) NonFiniteUpdates() int {
+	return n.nonFiniteUpdates
+}
+
+// applyGradient steps [angleWeight, angularVelWeight, bias, featureWeights...]
+// along grad, after clipping grad to maxGradNorm. The step is lr*grad, or
+// whatever the network's optimizer makes of it if one is set. An update
+// that would produce a NaN or Inf weight is rolled back: the previous
+// weights are kept, traces and optimizer moments are cleared and a
+// metrics event is recorded.
+// Returns false if the update was rejected
+func (n *Network) applyGradient(grad []float64, lr float64) bool {
+	ClipGradNorm(grad, n.maxGradNorm)
+
+	var step []float64
+	if n.optimizer != nil {
+		step = n.optimizer.Step(grad, lr)
+	} else {
+		n.stepBuf = resize(n.stepBuf, len(grad))
+		step = n.stepBuf
+		for i, g := range grad {
+			step[i] = lr * g
+		}
+	}
+
+	n.nextBuf = resize(n.nextBuf, 3+len(n.featureWeights))
+	next := n.nextBuf
+	next[0] = n.angleWeight + step[0]
+	next[1] = n.angularVelWeight + step[1]
+	next[2] = n.bias + step[2]
+	for i, w := range n.featureWeights {
+		next[3+i] = w + step[3+i]
+	}
+
+	if !AllFinite(next) {
+		n.nonFiniteUpdates++
+		n.ResetTraces()
+		if n.optimizer != nil {
+			n.optimizer.Reset()
+		}
+		details := fmt.Sprintf("rejected non-finite update %v at episode %d step %d", next, n.currentEpisode, n.currentStep)
+		if n.metrics != nil {
+			n.metrics.LogNetworkOperation("non_finite_rollback", details, false)
+		}
+		n.logger.Printf("[Network] %s; keeping previous weights", details)
+		return false
+	}
+
+	n.angleWeight, n.angularVelWeight, n.bias = next[0], next[1], next[2]
+	copy(n.featureWeights, next[3:])
+	return true
+}
+
+// ClipGradNorm scales grad in place so its L2 norm is at most maxNorm.
+// A maxNorm of 0 or less disables clipping. Returns true if grad was scaled
+func ClipGradNorm(grad []float64, maxNorm float64) bool {
+	if maxNorm <= 0 {
+		return false
+	}
+
+	norm := 0.0
+	for _, g := range grad {
+		norm += g * g
+	}
+	norm = math.Sqrt(norm)
+	if norm <= maxNorm || math.IsNaN(norm) {
+		return false
+	}
+
+	scale := maxNorm / norm
+	for i := range grad {
+		grad[i] *= scale
+	}
+	return true
+}
+
+// AllFinite reports whether every value is neither NaN nor Inf
+func AllFinite(values []float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"time"
 
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
 )
 
@@ -23,13 +26,54 @@ type Network struct {
 	
 	// Bias for hidden node
 	bias float64
+
+	// Optional observation layer and weights for its engineered features
+	observer       *ObservationTransformer
+	featureWeights []float64 // Weights for features beyond angle and angular velocity
 	
 	// Learning parameters
 	learningRate float64
 	lastForce    float64 // Store last output for weight updates
 	lastInputs   []float64 // Store last inputs for weight updates
+	lastState    env.State // Store last raw state for value predictions
 	lastValue    float64 // Store last state value for TD learning
 
+	// Buffers reused by every pass and update, so the hot path does not allocate
+	evalInputs []float64 // Inputs of evaluation passes, kept apart from lastInputs
+	gradBuf    []float64 // Gradient of the current update
+	stepBuf    []float64 // Step of the current update without an optimizer
+	nextBuf    []float64 // Weights the current update proposes
+
+	// Temporal difference parameters
+	discount   float64   // Discount factor (gamma) for bootstrapped targets
+	traceDecay float64   // Eligibility trace decay (lambda)
+	traces     []float64 // Eligibility traces for [angleWeight, angularVelWeight, bias, featureWeights...]
+
+	// Update safeguards
+	maxGradNorm      float64 // L2 limit on each update's gradient; 0 disables clipping
+	nonFiniteUpdates int     // Updates rejected for producing NaN or Inf weights
+
+	// Optional optimizer for online updates; nil steps by learningRate*gradient
+	optimizer Optimizer
+
+	// Maps the continuous output to the forces the network may apply
+	actionSpace ActionSpace
+
+	// Optional Gaussian policy head; nil acts deterministically
+	stochastic *stochasticHead
+
+	// Optional recurrent cell carrying state across steps; nil is memoryless
+	recurrent *recurrentCell
+
+	// Optional running reward scaling of TD targets; nil learns from raw rewards
+	rewardScaler *RewardScaler
+
+	// Frozen for evaluation: no learning, sampling or metrics side effects
+	evalMode bool
+
+	// Name of the pendulum preset the network is trained on, if any
+	preset string
+
 	// Progressive training parameters
 	difficulty     float64  // Current difficulty level [0.0, 1.0]
 	successRate    float64  // Recent success rate
@@ -37,9 +81,10 @@ type Network struct {
 	windowSize     int      // Size of success tracking window
 	progressThresh float64  // Success rate threshold for progression
 	regressThresh  float64  // Success rate threshold for regression
+	success        env.SuccessCriteria // Decides which updates count as successes
 
-	// Debug flag
-	debug bool
+	// Decides which per-step and episode messages are logged
+	sampler *logger.Sampler
 	
 	// Logger
 	logger *log.Logger
@@ -47,6 +92,9 @@ type Network struct {
 	// Metrics logger for performance tracking
 	metrics *metrics.Logger
 	
+	// Receives how long each forward pass took, when set
+	latency env.LatencyRecorder
+	
 	// Current episode and step tracking
 	currentEpisode int
 	currentStep    int
@@ -60,13 +108,18 @@ func NewNetwork() *Network {
 		bias:            0.0,  // Start with no bias
 		learningRate:    0.05, // Learning rate for quick adaptation
 		lastInputs:      make([]float64, 2),
+		discount:        0.99, // Standard discount for future rewards
+		traceDecay:      0.0,  // One-step TD unless traces are enabled
+		traces:          make([]float64, 3),
+		actionSpace:     NewDefaultActionSpace(),
 		difficulty:      0.1,  // Start with low difficulty
 		successRate:     0.0,  // Initial success rate
 		windowSize:      100,  // Track last 100 attempts
 		successWindow:   make([]bool, 0, 100),
 		progressThresh:  0.8,  // Progress when 80% success rate
 		regressThresh:  0.2,   // Regress when 20% success rate
-		debug:           false, // Disable debug by default
+		success:         env.NewDefaultSuccessCriteria(),
+		sampler:         logger.NewSampler(quietSampling), // Errors only until debugging is enabled
 		logger:          log.Default(),
 		currentEpisode:  0,
 		currentStep:     0,
@@ -78,9 +131,26 @@ func NewNetwork() *Network {
 	return net
 }
 
-// SetDebug enables or disables debug printing
+// forceScale is the force in N at full tanh output
+const forceScale = 5.0
+
+// quietSampling is the network's default: it only logs per-step detail
+// when asked to, through SetDebug or SetLogSampling
+var quietSampling = logger.SamplingConfig{Mode: logger.SampleErrors}
+
+// SetDebug enables or disables debug printing; enabled logs every message
 func (n *Network) SetDebug(enabled bool) {
-	n.debug = enabled
+	if enabled {
+		n.SetLogSampling(logger.SamplingConfig{Mode: logger.SampleAll})
+	} else {
+		n.SetLogSampling(quietSampling)
+	}
+}
+
+// SetLogSampling controls how many per-step and episode messages are logged
+// when no metrics logger is set
+func (n *Network) SetLogSampling(config logger.SamplingConfig) {
+	n.sampler = logger.NewSampler(config)
 }
 
 // SetLogger sets the logger for this network
@@ -102,18 +172,41 @@ func (n *Network) SetMetricsLogger(metricsLogger *metrics.Logger) {
 	}
 }
 
+// SetLatencyRecorder times every forward pass into recorder, to check the
+// network fits a real-time control budget. Nil stops timing
+func (n *Network) SetLatencyRecorder(recorder env.LatencyRecorder) {
+	n.latency = recorder
+}
+
+// SetRewardScaler makes TD updates learn from rewards divided by the
+// running std of the return, so a new reward function keeps the learning
+// rate's effect. Success tracking still sees raw rewards. Nil disables it
+func (n *Network) SetRewardScaler(scaler *RewardScaler) {
+	n.rewardScaler = scaler
+}
+
+// RewardScaler returns the network's reward scaler, nil when disabled
+func (n *Network) RewardScaler() *RewardScaler {
+	return n.rewardScaler
+}
+
 // SetEpisode sets the current episode number
 func (n *Network) SetEpisode(episode int) {
 	n.currentEpisode = episode
 	n.currentStep = 0
+	n.ResetTraces()
+	n.ResetHistory()
 	
 	// Update metrics logger if available
 	if n.metrics != nil {
 		n.metrics.SetEpisode(episode)
 		// Log weights at the start of each episode to database
 		n.metrics.LogWeights(n.angleWeight, n.angularVelWeight, n.bias, n.learningRate)
-	} else if n.debug {
-		// Only log to console if no metrics logger and debug is enabled
+		if err := n.metrics.LogValueLandscape(n.Value); err != nil && n.sampler.Episode() {
+			n.logger.Printf("Failed to record value landscape: %v", err)
+		}
+	} else if n.sampler.Episode() {
+		// Only log to console if no metrics logger and sampling allows it
 		n.logger.Printf("Starting episode %d with weights: angle=%.4f, angularVelWeight=%.4f, bias=%.4f, lr=%.4f",
 			episode, n.angleWeight, n.angularVelWeight, n.bias, n.learningRate)
 	}
@@ -129,6 +222,58 @@ func (n *Network) IncrementStep() {
 	}
 }
 
+// SetObservationTransformer routes inputs through the given transformer.
+// Weights for engineered features start at zero so behaviour is unchanged
+// until they are learned; nil restores raw angle and angular velocity inputs
+func (n *Network) SetObservationTransformer(observer *ObservationTransformer) {
+	n.observer = observer
+	n.featureWeights = nil
+	if observer != nil {
+		n.featureWeights = make([]float64, observer.Size()-2)
+	}
+	n.traces = make([]float64, 3+len(n.featureWeights))
+
+	// The recurrent cell reads every input, so it starts over when they change
+	if n.recurrent != nil && n.recurrent.inputs != len(n.InputNames()) {
+		n.recurrent = newRecurrentCell(len(n.InputNames()))
+	}
+}
+
+// GetObservationTransformer returns the observation layer, or nil if unset
+func (n *Network) GetObservationTransformer() *ObservationTransformer {
+	return n.observer
+}
+
+// InputNames returns the names of the network's inputs, in the order of
+// GetLastInputs: angle and angular velocity, then any engineered or
+// stacked features
+func (n *Network) InputNames() []string {
+	if n.observer != nil {
+		return n.observer.Names()
+	}
+	return []string{"angle", "angular_vel"}
+}
+
+// GetLastInputs returns the inputs of the last forward pass, or nil before
+// the first
+func (n *Network) GetLastInputs() []float64 {
+	return append([]float64(nil), n.lastInputs...)
+}
+
+// GetFeatureWeights returns the weights of the engineered features
+func (n *Network) GetFeatureWeights() []float64 {
+	return append([]float64(nil), n.featureWeights...)
+}
+
+// SetFeatureWeights updates the weights of the engineered features
+func (n *Network) SetFeatureWeights(weights []float64) error {
+	if len(weights) != len(n.featureWeights) {
+		return fmt.Errorf("expected %d feature weights, got %d", len(n.featureWeights), len(weights))
+	}
+	copy(n.featureWeights, weights)
+	return nil
+}
+
 // Forward performs a forward pass through the network
 // Returns a force value in [-5, 5] Newtons
 func (n *Network) Forward(state env.State) float64 {
@@ -136,86 +281,213 @@ func (n *Network) Forward(state env.State) float64 {
 	return force
 }
 
+// Act returns the network's force for state, implementing agent.Controller.
+// With a stochastic policy head it acts on the mean, for evaluation
+func (n *Network) Act(state env.State) float64 {
+	if n.stochastic != nil && !n.evalMode {
+		return n.Policy(state)
+	}
+	return n.Forward(state)
+}
+
+// Policy returns the force Forward would apply in state without recording
+// the pass for learning or logging it, e.g. to map the policy for display.
+// With a stochastic policy head it returns the mean force
+func (n *Network) Policy(state env.State) float64 {
+	force, _, _ := n.evaluate(state)
+	return force
+}
+
+// timeForward records the latency of a forward pass that started at start
+func (n *Network) timeForward(start time.Time) {
+	n.latency.Record(time.Since(start))
+}
+
 // ForwardWithActivation performs a forward pass and returns both the force and hidden layer activation
 func (n *Network) ForwardWithActivation(state env.State) (float64, float64) {
-	// Normalize angle to [-π, π] range
-	angle := math.Mod(state.AngleRadians, 2*math.Pi)
-	if angle > math.Pi {
-		angle -= 2 * math.Pi
-	} else if angle < -math.Pi {
-		angle += 2 * math.Pi
+	if n.latency != nil {
+		defer n.timeForward(time.Now())
 	}
 	
-	// Get angular velocity
-	velocity := state.AngularVel
+	// Write the inputs into the stored inputs' buffer; evaluation passes
+	// leave those alone
+	var inputs []float64
+	if n.evalMode {
+		n.evalInputs = n.inputs(n.evalInputs, state, false)
+		inputs = n.evalInputs
+	} else {
+		n.lastInputs = n.inputs(n.lastInputs, state, true)
+		inputs = n.lastInputs
+	}
 	
-	// Compute hidden activation
-	// Negate angle and velocity to ensure correct force direction
-	// When pendulum falls right (positive angle), we want negative force (push left)
-	// When pendulum falls left (negative angle), we want positive force (push right)
-	hidden := -n.angleWeight*angle - n.angularVelWeight*velocity + n.bias
+	// The recurrent step feeds the output and is then recorded as is
+	var step recurrentStep
+	recurrent := 0.0
+	if n.recurrent != nil {
+		step = n.recurrent.step(inputs)
+		recurrent = n.recurrent.output(step)
+	}
+	force, hidden := n.output(inputs, recurrent)
 	
-	// Apply activation function (tanh)
-	activation := math.Tanh(hidden)
+	// Advance the input history, the only side effect of an evaluation pass
+	if n.observer != nil {
+		n.observer.remember(state)
+	}
+	if n.recurrent != nil {
+		n.recurrent.record(step)
+	}
+	if n.evalMode {
+		return force, hidden
+	}
 	
-	// Scale to force range [-5, 5] Newtons
-	force := activation * 5.0
+	// Sample around the mean when the policy head is stochastic
+	if n.stochastic != nil {
+		force = n.actionSpace.Map(n.stochastic.sample(hidden))
+	}
 	
 	// Store for learning
 	n.lastForce = force
-	n.lastInputs = []float64{angle, velocity}
+	n.lastState = state
 	
 	// Log metrics if available
 	if n.metrics != nil {
-		n.metrics.LogForwardPass(angle, velocity, force, hidden)
-	} else if n.debug {
-		// Only log to console if no metrics logger and debug is enabled
-		n.logger.Printf("Forward: angle=%.4f, velocity=%.4f → force=%.4f", angle, velocity, force)
+		n.metrics.LogForwardPass(inputs[0], inputs[1], force, hidden)
+		a := n.LastAttribution()
+		n.metrics.LogAttribution(a.Angle, a.AngularVel, a.Bias, a.Features+a.Recurrent, a.Hidden)
+	} else if n.sampler.Step() {
+		// Only log to console if no metrics logger and sampling allows it
+		n.logger.Printf("Forward: angle=%.4f, velocity=%.4f → force=%.4f", inputs[0], inputs[1], force)
+	}
+	
+	return force, hidden
+}
+
+// evaluate computes the force and hidden pre-activation for state, along
+// with the network inputs it used. It only reads the observation
+// statistics, since Policy may be called for any state, e.g. every frame
+func (n *Network) evaluate(state env.State) (float64, float64, []float64) {
+	inputs := n.inputs(nil, state, false)
+	force, hidden := n.output(inputs, n.recurrentOutput(inputs))
+	return force, hidden, inputs
+}
+
+// inputs writes the network inputs for state into dst's storage, folding
+// the state into the observation statistics if observe is set and the
+// transformer is not frozen
+func (n *Network) inputs(dst []float64, state env.State, observe bool) []float64 {
+	// Signed angle from upright, or use the observation layer's features
+	if n.observer != nil {
+		return n.observer.transformInto(dst, state, observe && !n.observer.frozen)
+	}
+	return append(dst[:0], env.UprightOffset(state.AngleRadians), state.AngularVel)
+}
+
+// recurrentOutput returns what the recurrent cell adds to the hidden node
+// for the network inputs without recording the step, 0 without a cell
+func (n *Network) recurrentOutput(inputs []float64) float64 {
+	if n.recurrent == nil {
+		return 0
+	}
+	return n.recurrent.output(n.recurrent.step(inputs))
+}
+
+// output computes the force and hidden pre-activation for the network
+// inputs, given what the recurrent cell adds to the hidden node
+func (n *Network) output(inputs []float64, recurrent float64) (float64, float64) {
+	angle, velocity := inputs[0], inputs[1]
+	
+	// Compute hidden activation
+	// Negate angle and velocity to ensure correct force direction
+	// When pendulum falls right (positive angle), we want negative force (push left)
+	// When pendulum falls left (negative angle), we want positive force (push right)
+	// Products are rounded before they are summed so the pass is identical
+	// on every architecture; see package detmath
+	hidden := -float64(n.angleWeight*angle) - float64(n.angularVelWeight*velocity) + n.bias
+	for i, w := range n.featureWeights {
+		hidden -= float64(w * inputs[2+i])
 	}
+	if n.recurrent != nil {
+		hidden += recurrent
+	}
+	
+	// Apply activation function (tanh)
+	activation := detmath.Tanh(hidden)
+	
+	// Scale to force range [-5, 5] Newtons, then snap to the action space
+	force := n.actionSpace.Map(activation * forceScale)
 	
 	return force, hidden
 }
 
+// ForwardBatch returns the force Policy gives each state, evaluating them
+// all with one input buffer, e.g. to map the policy over a grid of
+// thousands of states. Nothing is recorded or logged, and like Policy the
+// states are not folded into the observation statistics. Stacked frames
+// and the recurrent state are those of the network's current episode
+func (n *Network) ForwardBatch(states []env.State) []float64 {
+	forces := make([]float64, len(states))
+	var inputs []float64
+	for i, state := range states {
+		inputs = n.inputs(inputs, state, false)
+		forces[i], _ = n.output(inputs, n.recurrentOutput(inputs))
+	}
+	return forces
+}
+
 // Predict estimates the value of a state for temporal difference learning
 // Returns a value in [-1, 1] representing the estimated "goodness" of the state
 func (n *Network) Predict(angleRadians, angularVel float64) float64 {
-	// Normalize angle to [-π, π] range
-	angle := math.Mod(angleRadians, 2*math.Pi)
-	if angle > math.Pi {
-		angle -= 2 * math.Pi
-	} else if angle < -math.Pi {
-		angle += 2 * math.Pi
+	if n.evalMode {
+		return n.Value(angleRadians, angularVel)
 	}
-	
-	// For prediction, we want to value states closer to balance (angle and velocity near zero)
-	// So we use the negative of the absolute values
-	balanceQuality := -math.Abs(angle) - 0.5*math.Abs(angularVel)
-	
-	// Apply activation function (tanh) to normalize to [-1, 1]
-	n.lastValue = math.Tanh(balanceQuality)
+	angle := env.UprightOffset(angleRadians)
+	n.lastValue = n.Value(angleRadians, angularVel)
 	
 	// Log prediction if metrics available
 	if n.metrics != nil {
 		n.metrics.LogPrediction(angle, angularVel, n.lastValue)
-	} else if n.debug {
-		// Only log to console if no metrics logger and debug is enabled
+	} else if n.sampler.Step() {
+		// Only log to console if no metrics logger and sampling allows it
 		n.logger.Printf("Predict: angle=%.4f, velocity=%.4f → value=%.4f", angle, angularVel, n.lastValue)
 	}
 	
 	return n.lastValue
 }
 
+// Value returns the value Predict assigns a state without recording it, e.g.
+// to sample the value landscape over a grid of states
+func (n *Network) Value(angleRadians, angularVel float64) float64 {
+	// Signed angle from upright in [-π, π]
+	angle := env.UprightOffset(angleRadians)
+	
+	// For prediction, we want to value states closer to balance (angle and velocity near zero)
+	// So we use the negative of the absolute values
+	balanceQuality := -math.Abs(angle) - float64(0.5*math.Abs(angularVel))
+	
+	// Apply activation function (tanh) to normalize to [-1, 1]
+	return detmath.Tanh(balanceQuality)
+}
+
+// PredictBatch returns the value Predict assigns each state without
+// recording or logging them, e.g. to map the value landscape over a grid
+func (n *Network) PredictBatch(states []env.State) []float64 {
+	values := make([]float64, len(states))
+	for i, state := range states {
+		values[i] = n.Value(state.AngleRadians, state.AngularVel)
+	}
+	return values
+}
+
 // Update adjusts weights based on the reward received
 // reward should be in [-1, 1] range
 func (n *Network) Update(reward float64) {
-	// Ensure we have previous inputs
-	if len(n.lastInputs) != 2 {
+	// Ensure we have previous inputs, and learn nothing while evaluating
+	if len(n.lastInputs) < 2 || n.evalMode {
 		return
 	}
 
 	// Track success/failure for progressive difficulty
-	success := reward > 0.5
-	n.updateSuccessRate(success)
+	n.updateSuccessRate(n.success.Step(reward))
 	
 	// Scale reward by difficulty for more aggressive learning at higher difficulties
 	scaledReward := reward * (0.5 + 0.5*n.difficulty)
@@ -223,23 +495,160 @@ func (n *Network) Update(reward float64) {
 	// Compute error gradient
 	error := scaledReward - n.lastValue
 	
-	// Apply learning rate (increased at higher difficulties)
-	effectiveLR := n.learningRate * (1.0 + n.difficulty)
-	
-	// Update weights with momentum
-	n.angleWeight += effectiveLR * error * n.lastInputs[0]
-	n.angularVelWeight += effectiveLR * error * n.lastInputs[1]
-	n.bias += effectiveLR * error
+	// Apply the learning rate; a Schedule adapts it between episodes
+	effectiveLR := n.learningRate
+	
+	// Update weights along the clipped gradient
+	n.gradBuf = resize(n.gradBuf, 3+len(n.featureWeights))
+	grad := n.gradBuf
+	grad[0] = error * n.lastInputs[0]
+	grad[1] = error * n.lastInputs[1]
+	grad[2] = error
+	for i := range n.featureWeights {
+		grad[3+i] = error * n.lastInputs[2+i]
+	}
+	var before []float64
+	if n.metrics != nil {
+		before = n.GetWeights()
+	}
+	n.applyGradient(grad, effectiveLR)
 	
 	// Log update if metrics available
 	if n.metrics != nil {
+		n.logWeightUpdate(before, reward, effectiveLR)
 		n.metrics.LogUpdate(error, n.angleWeight, n.angularVelWeight, n.bias, n.difficulty, n.successRate)
-	} else if n.debug {
+	} else if n.sampler.Step() {
 		n.logger.Printf("Update: reward=%.4f, error=%.4f, new_weights=[%.4f, %.4f, %.4f]",
 			reward, error, n.angleWeight, n.angularVelWeight, n.bias)
 	}
 }
 
+// UpdateTD adjusts weights toward the bootstrapped target
+// reward + discount*V(nextState) using eligibility traces, so credit for
+// the TD error is shared with recently visited states (TD(λ))
+func (n *Network) UpdateTD(reward float64, nextState env.State, done bool) {
+	// Ensure we have previous inputs, and learn nothing while evaluating
+	if len(n.lastInputs) < 2 || n.evalMode {
+		return
+	}
+
+	// Track success/failure for progressive difficulty
+	n.updateSuccessRate(n.success.Step(reward))
+
+	// Learn from the reward in units of the return's running std
+	if n.rewardScaler != nil {
+		reward = n.rewardScaler.Scale(reward, done)
+	}
+
+	// Bootstrapped target, with no future value past a terminal state
+	currentValue := n.Predict(n.lastState.AngleRadians, n.lastState.AngularVel)
+	nextValue := 0.0
+	if !done {
+		nextValue = n.Predict(nextState.AngleRadians, nextState.AngularVel)
+	}
+	target := reward + n.discount*nextValue
+	tdError := target - currentValue
+
+	// Decay traces and accumulate the gradient of the current inputs, or of
+	// the sampled force's log-probability for a stochastic policy
+	decay := n.discount * n.traceDecay
+	if n.stochastic != nil {
+		for i, score := range n.stochastic.weightScore(n.lastInputs) {
+			n.traces[i] = decay*n.traces[i] + score
+		}
+	} else {
+		n.traces[0] = decay*n.traces[0] + n.lastInputs[0]
+		n.traces[1] = decay*n.traces[1] + n.lastInputs[1]
+		n.traces[2] = decay*n.traces[2] + 1.0
+		for i := range n.featureWeights {
+			n.traces[3+i] = decay*n.traces[3+i] + n.lastInputs[2+i]
+		}
+	}
+
+	// Apply the learning rate; a Schedule adapts it between episodes
+	effectiveLR := n.learningRate
+
+	n.gradBuf = resize(n.gradBuf, len(n.traces))
+	grad := n.gradBuf
+	for i, trace := range n.traces {
+		grad[i] = tdError * trace
+	}
+	var before []float64
+	if n.metrics != nil {
+		before = n.GetWeights()
+	}
+	n.applyGradient(grad, effectiveLR)
+	if n.stochastic != nil {
+		n.stochastic.updateStd(tdError, effectiveLR)
+	}
+
+	// Traces do not carry across episode boundaries
+	if done {
+		n.ResetTraces()
+	}
+
+	// Log update if metrics available
+	if n.metrics != nil {
+		n.metrics.LogTDTarget(target, tdError, n.discount, n.traceDecay)
+		if n.stochastic != nil {
+			n.metrics.LogExploration("gaussian_policy", n.stochastic.std(), n.stochastic.lastSample-n.stochastic.lastMean)
+		}
+		n.logWeightUpdate(before, reward, effectiveLR)
+		n.metrics.LogUpdate(tdError, n.angleWeight, n.angularVelWeight, n.bias, n.difficulty, n.successRate)
+	} else if n.sampler.Step() {
+		n.logger.Printf("UpdateTD: reward=%.4f, target=%.4f, error=%.4f, new_weights=[%.4f, %.4f, %.4f]",
+			reward, target, tdError, n.angleWeight, n.angularVelWeight, n.bias)
+	}
+}
+
+// logWeightUpdate records how far an update moved each weight from before,
+// with the inputs, force and reward of the step, in the metrics step trace
+func (n *Network) logWeightUpdate(before []float64, reward, lr float64) {
+	after := n.GetWeights()
+	n.metrics.LogWeightUpdateDetails(n.lastInputs[0], n.lastInputs[1], n.lastForce, reward,
+		after[0]-before[0], after[1]-before[1], after[2]-before[2], lr)
+}
+
+// ResetTraces clears the eligibility traces, e.g. at the start of an episode
+func (n *Network) ResetTraces() {
+	for i := range n.traces {
+		n.traces[i] = 0
+	}
+}
+
+// ResetHistory forgets what the network remembers of the episode: the
+// observations stacked as input frames and the recurrent cell's state, e.g.
+// at the start of an episode. SetEpisode resets it too
+func (n *Network) ResetHistory() {
+	if n.observer != nil {
+		n.observer.resetHistory()
+	}
+	if n.recurrent != nil {
+		n.recurrent.reset()
+	}
+}
+
+// SetDiscount sets the discount factor used for bootstrapped targets
+func (n *Network) SetDiscount(gamma float64) {
+	n.discount = clip(gamma, 0.0, 1.0)
+}
+
+// GetDiscount returns the discount factor
+func (n *Network) GetDiscount() float64 {
+	return n.discount
+}
+
+// SetTraceDecay sets the eligibility trace decay (lambda)
+// 0 gives one-step TD, 1 approaches Monte Carlo returns
+func (n *Network) SetTraceDecay(lambda float64) {
+	n.traceDecay = clip(lambda, 0.0, 1.0)
+}
+
+// GetTraceDecay returns the eligibility trace decay
+func (n *Network) GetTraceDecay() float64 {
+	return n.traceDecay
+}
+
 // updateSuccessRate updates the success tracking window and recalculates success rate
 func (n *Network) updateSuccessRate(success bool) {
 	// Add new result to window
@@ -276,7 +685,7 @@ func (n *Network) increaseDifficulty() {
 	
 	if n.metrics != nil {
 		n.metrics.LogDifficultyChange(oldDiff, n.difficulty, "increase")
-	} else if n.debug {
+	} else if n.sampler.Episode() {
 		n.logger.Printf("Increasing difficulty: %.4f → %.4f", oldDiff, n.difficulty)
 	}
 }
@@ -288,7 +697,7 @@ func (n *Network) decreaseDifficulty() {
 	
 	if n.metrics != nil {
 		n.metrics.LogDifficultyChange(oldDiff, n.difficulty, "decrease")
-	} else if n.debug {
+	} else if n.sampler.Episode() {
 		n.logger.Printf("Decreasing difficulty: %.4f → %.4f", oldDiff, n.difficulty)
 	}
 }
@@ -298,6 +707,12 @@ func (n *Network) GetDifficulty() float64 {
 	return n.difficulty
 }
 
+// SetSuccessCriteria decides which updates count toward the success rate
+// that drives progressive difficulty
+func (n *Network) SetSuccessCriteria(criteria env.SuccessCriteria) {
+	n.success = criteria
+}
+
 // GetSuccessRate returns the current success rate
 func (n *Network) GetSuccessRate() float64 {
 	return n.successRate
@@ -322,7 +737,7 @@ func (n *Network) SetWeights(weights []float64) error {
 	// Record new weights in metrics if available
 	if n.metrics != nil {
 		n.metrics.LogWeights(n.angleWeight, n.angularVelWeight, n.bias, n.learningRate)
-	} else if n.debug {
+	} else if n.sampler.Step() {
 		n.logger.Printf("[Network] Set weights: angle=%.4f, angularVelWeight=%.4f, bias=%.4f\n",
 			n.angleWeight, n.angularVelWeight, n.bias)
 	}
@@ -343,11 +758,23 @@ func (n *Network) SetLearningRate(rate float64) {
 		// Also log as a network operation for tracking system changes
 		metadata := fmt.Sprintf("Changed learning rate from %.4f to %.4f", oldRate, rate)
 		n.metrics.LogNetworkOperation("learning_rate_change", metadata, true)
-	} else if n.debug {
+	} else if n.sampler.Step() {
 		n.logger.Printf("[Network] Set learning rate: %.4f\n", rate)
 	}
 }
 
+// SetPreset records the name of the pendulum preset the network is trained
+// on, so saved checkpoints say which physics they belong to
+func (n *Network) SetPreset(name string) {
+	n.preset = name
+}
+
+// GetPreset returns the pendulum preset recorded with SetPreset or loaded
+// from a checkpoint, or "" if none
+func (n *Network) GetPreset() string {
+	return n.preset
+}
+
 // GetLearningRate returns the current learning rate
 func (n *Network) GetLearningRate() float64 {
 	return n.learningRate
@@ -363,6 +790,14 @@ func sign(x float64) float64 {
 	return 0.0
 }
 
+// resize returns buf with n values, reusing its storage when it is large enough
+func resize(buf []float64, n int) []float64 {
+	if cap(buf) < n {
+		return make([]float64, n)
+	}
+	return buf[:n]
+}
+
 // clip limits a value to [min, max] range
 func clip(x, min, max float64) float64 {
 	if x < min {
@@ -308,30 +308,57 @@ func BenchmarkNetwork(b *testing.B) {
 	}
 
 	b.Run("Forward", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			network.Forward(state)
 		}
 	})
 
 	b.Run("Predict", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			network.Predict(state.AngleRadians, state.AngularVel)
 		}
 	})
 
+	b.Run("ForwardBatch", func(b *testing.B) {
+		b.ReportAllocs()
+		states := make([]env.State, 1024)
+		for i := range states {
+			states[i] = env.State{AngleRadians: float64(i)/512 - 1, AngularVel: 0.2}
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i += len(states) {
+			network.ForwardBatch(states)
+		}
+	})
+
 	b.Run("Update", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			network.Update(0.5)
 		}
 	})
 
 	b.Run("FullStep", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			network.Forward(state)
 			network.Predict(state.AngleRadians, state.AngularVel)
 			network.Update(0.5)
 		}
 	})
+
+	b.Run("FullStepTD", func(b *testing.B) {
+		network.SetTraceDecay(0.9)
+		network.SetObservationTransformer(NewObservationTransformer(FeatureConfig{SinCos: true, Frames: 3, Normalize: true}))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			network.Forward(state)
+			network.UpdateTD(0.5, state, i%100 == 99)
+		}
+	})
 }
 
 // Helper functions for comparing weights
@@ -354,3 +381,146 @@ func weightsDecreased(old, new []float64) bool {
 	}
 	return decreased
 }
+
+func TestUpdateTD(t *testing.T) {
+	state := env.State{AngleRadians: 0.2, AngularVel: 0.1}
+	next := env.State{AngleRadians: 0.3, AngularVel: 0.2}
+
+	tests := []struct {
+		name       string
+		traceDecay float64
+		steps      int
+		done       bool
+	}{
+		{"one-step TD", 0.0, 3, false},
+		{"eligibility traces", 0.9, 3, false},
+		{"terminal resets traces", 0.9, 1, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			net := NewNetwork()
+			net.SetDiscount(0.9)
+			net.SetTraceDecay(tc.traceDecay)
+
+			for i := 0; i < tc.steps; i++ {
+				net.Forward(state)
+				net.UpdateTD(1.0, next, tc.done)
+			}
+
+			// The bias trace accumulates 1 per step, decayed by gamma*lambda
+			wantTrace := 0.0
+			for i := 0; i < tc.steps; i++ {
+				wantTrace = 0.9*tc.traceDecay*wantTrace + 1.0
+			}
+			if tc.done {
+				wantTrace = 0
+			}
+			if math.Abs(net.traces[2]-wantTrace) > 1e-9 {
+				t.Errorf("bias trace = %v, want %v", net.traces[2], wantTrace)
+			}
+		})
+	}
+
+	// Discount and trace decay are clamped to [0, 1]
+	net := NewNetwork()
+	net.SetDiscount(1.5)
+	net.SetTraceDecay(-0.5)
+	if net.GetDiscount() != 1.0 || net.GetTraceDecay() != 0.0 {
+		t.Errorf("got discount=%v, traceDecay=%v, want 1, 0", net.GetDiscount(), net.GetTraceDecay())
+	}
+}
+
+func TestUpdateSafeguards(t *testing.T) {
+	state := env.State{AngleRadians: 0.2, AngularVel: 0.1}
+
+	t.Run("gradient clipping", func(t *testing.T) {
+		net := NewNetwork()
+		net.SetMaxGradNorm(0.1)
+		before := net.GetWeights()
+		net.Forward(state)
+		net.Update(1.0)
+
+		// The step is at most learning rate × max norm
+		step := 0.0
+		for i, w := range net.GetWeights() {
+			step += (w - before[i]) * (w - before[i])
+		}
+		maxStep := net.GetLearningRate() * 0.1
+		if math.Sqrt(step) > maxStep+1e-12 {
+			t.Errorf("update step %.6f exceeds clipped bound %.6f", math.Sqrt(step), maxStep)
+		}
+	})
+
+	t.Run("non-finite update rejected", func(t *testing.T) {
+		net := NewNetwork()
+		before := net.GetWeights()
+		net.Forward(state)
+		net.Update(math.Inf(1))
+		net.UpdateTD(math.NaN(), state, false)
+
+		if net.NonFiniteUpdates() != 2 {
+			t.Errorf("non-finite updates = %d, want 2", net.NonFiniteUpdates())
+		}
+		for i, w := range net.GetWeights() {
+			if w != before[i] {
+				t.Errorf("weight %d = %v, want unchanged %v", i, w, before[i])
+			}
+		}
+	})
+
+	t.Run("clip helper", func(t *testing.T) {
+		grad := []float64{3, 4}
+		if !ClipGradNorm(grad, 1) || math.Abs(grad[0]-0.6) > 1e-12 || math.Abs(grad[1]-0.8) > 1e-12 {
+			t.Errorf("clipped gradient = %v, want [0.6 0.8]", grad)
+		}
+		if ClipGradNorm(grad, 0) || ClipGradNorm(grad, 2) {
+			t.Error("gradient clipped when disabled or within the limit")
+		}
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	net := NewNetwork()
+	recorded := env.State{AngleRadians: 0.1, AngularVel: -0.2}
+	probe := env.State{AngleRadians: -0.4, AngularVel: 1.5}
+
+	want := net.Forward(probe)
+	net.Forward(recorded)
+	if got := net.Policy(probe); got != want {
+		t.Errorf("Policy = %v, want Forward's %v", got, want)
+	}
+	if net.lastState != recorded {
+		t.Errorf("Policy replaced the recorded pass %+v with %+v", recorded, net.lastState)
+	}
+}
+
+func TestForwardBatch(t *testing.T) {
+	net := NewNetwork()
+	net.SetObservationTransformer(NewObservationTransformer(FeatureConfig{SinCos: true, Frames: 2, Normalize: true}))
+	net.EnableRecurrent()
+	net.SetRecurrentWeights(append(make([]float64, len(net.GetRecurrentWeights())-1), 0.5))
+	recorded := env.State{AngleRadians: 0.1, AngularVel: -0.2}
+	for i := 0; i < 3; i++ {
+		net.Forward(recorded)
+	}
+	net.GetObservationTransformer().SetFrozen(true)
+
+	states := []env.State{{AngleRadians: -0.4, AngularVel: 1.5}, {AngleRadians: 2.5}, recorded}
+	forces := net.ForwardBatch(states)
+	values := net.PredictBatch(states)
+	if len(forces) != len(states) || len(values) != len(states) {
+		t.Fatalf("got %d forces and %d values for %d states", len(forces), len(values), len(states))
+	}
+	for i, state := range states {
+		if want := net.Policy(state); forces[i] != want {
+			t.Errorf("ForwardBatch[%d] = %v, want Policy's %v", i, forces[i], want)
+		}
+		if want := net.Value(state.AngleRadians, state.AngularVel); values[i] != want {
+			t.Errorf("PredictBatch[%d] = %v, want Value's %v", i, values[i], want)
+		}
+	}
+	if net.lastState != recorded {
+		t.Errorf("ForwardBatch replaced the recorded pass %+v with %+v", recorded, net.lastState)
+	}
+}
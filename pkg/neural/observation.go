@@ -0,0 +1,217 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// FeatureConfig selects which inputs the network sees and whether they are normalized
+type FeatureConfig struct {
+	Normalize bool `json:"normalize"`  // Scale features by their running mean and std
+	SinCos    bool `json:"sin_cos"`    // Append sin and cos of the angle
+	CartState bool `json:"cart_state"` // Append cart position and velocity
+	Frames    int  `json:"frames"`     // Concatenate the features of this many recent observations, the current included (0 or 1 for none)
+}
+
+// ObservationState is the serializable state of an ObservationTransformer,
+// saved with checkpoints so loaded networks see the same inputs
+type ObservationState struct {
+	Features FeatureConfig `json:"features"`
+	Count    float64       `json:"count"`
+	Mean     []float64     `json:"mean"`
+	M2       []float64     `json:"m2"`
+}
+
+// ObservationTransformer turns a pendulum state into the network's input
+// features, appending engineered features and tracking running statistics
+// (Welford's algorithm) for normalization
+type ObservationTransformer struct {
+	features FeatureConfig
+	count    float64
+	mean     []float64
+	m2       []float64
+	frozen   bool        // Stop updating statistics, e.g. during evaluation
+	history  [][]float64 // Features of the previous observations of the episode, most recent first
+}
+
+// NewObservationTransformer creates a transformer for the given feature set
+func NewObservationTransformer(features FeatureConfig) *ObservationTransformer {
+	t := &ObservationTransformer{features: features}
+	t.mean = make([]float64, t.Size())
+	t.m2 = make([]float64, t.Size())
+	return t
+}
+
+// Size returns the number of features produced per state
+func (t *ObservationTransformer) Size() int {
+	return len(t.frameNames()) * t.frames()
+}
+
+// Names returns the feature names in the order they are produced. Features
+// of stacked frames are suffixed with how many observations back they are,
+// e.g. angle_prev1
+func (t *ObservationTransformer) Names() []string {
+	frame := t.frameNames()
+	names := append([]string(nil), frame...)
+	for i := 1; i < t.frames(); i++ {
+		for _, name := range frame {
+			names = append(names, fmt.Sprintf("%s_prev%d", name, i))
+		}
+	}
+	return names
+}
+
+// frameNames returns the names of the features of one observation
+func (t *ObservationTransformer) frameNames() []string {
+	names := []string{"angle", "angular_vel"}
+	if t.features.SinCos {
+		names = append(names, "sin_angle", "cos_angle")
+	}
+	if t.features.CartState {
+		names = append(names, "cart_position", "cart_velocity")
+	}
+	return names
+}
+
+// frames returns how many observations are concatenated, at least one
+func (t *ObservationTransformer) frames() int {
+	return max(1, t.features.Frames)
+}
+
+// Features returns the feature set of this transformer
+func (t *ObservationTransformer) Features() FeatureConfig {
+	return t.features
+}
+
+// SetFrozen stops or resumes updating the running statistics
+func (t *ObservationTransformer) SetFrozen(frozen bool) {
+	t.frozen = frozen
+}
+
+// Transform returns the network inputs for a state, updating the running
+// statistics first unless the transformer is frozen
+func (t *ObservationTransformer) Transform(state env.State) []float64 {
+	return t.transform(state, !t.frozen)
+}
+
+// transform returns the network inputs for a state, folding it into the
+// running statistics first if observe is set
+func (t *ObservationTransformer) transform(state env.State, observe bool) []float64 {
+	return t.transformInto(nil, state, observe)
+}
+
+// transformInto is transform writing the inputs into dst's storage when it
+// is large enough, e.g. to evaluate many states with one buffer
+func (t *ObservationTransformer) transformInto(dst []float64, state env.State, observe bool) []float64 {
+	features := t.appendRaw(dst[:0], state)
+	if observe {
+		t.observe(features)
+	}
+	if !t.features.Normalize || t.count < 2 {
+		return features
+	}
+
+	for i, x := range features {
+		std := math.Sqrt(t.m2[i]/t.count) + 1e-8
+		features[i] = (x - t.mean[i]) / std
+	}
+	return features
+}
+
+// appendRaw appends the unnormalized features of a state to dst, followed
+// by those of the previous observations when frames are stacked
+func (t *ObservationTransformer) appendRaw(dst []float64, state env.State) []float64 {
+	start := len(dst)
+	dst = t.appendFrame(dst, state)
+
+	// Until the episode has enough history the oldest known frame is
+	// repeated, so stacked frames suggest no motion
+	last := dst[start:len(dst):len(dst)]
+	for i := 1; i < t.frames(); i++ {
+		if i <= len(t.history) {
+			last = t.history[i-1]
+		}
+		dst = append(dst, last...)
+	}
+	return dst
+}
+
+// frame computes the unnormalized features of one observation
+func (t *ObservationTransformer) frame(state env.State) []float64 {
+	return t.appendFrame(nil, state)
+}
+
+// appendFrame appends the unnormalized features of one observation to dst
+func (t *ObservationTransformer) appendFrame(dst []float64, state env.State) []float64 {
+	angle := env.UprightOffset(state.AngleRadians)
+	dst = append(dst, angle, state.AngularVel)
+	if t.features.SinCos {
+		dst = append(dst, detmath.Sin(angle), detmath.Cos(angle))
+	}
+	if t.features.CartState {
+		dst = append(dst, state.CartPosition, state.CartVelocity)
+	}
+	return dst
+}
+
+// remember records a state the network acted on, so the next observation
+// stacks it as the previous frame
+func (t *ObservationTransformer) remember(state env.State) {
+	if t.frames() == 1 {
+		return
+	}
+
+	// Shift the frames back one, reusing the storage of the one that drops out
+	var frame []float64
+	if len(t.history) == t.frames()-1 {
+		frame = t.history[len(t.history)-1][:0]
+	} else {
+		t.history = append(t.history, nil)
+	}
+	copy(t.history[1:], t.history)
+	t.history[0] = t.appendFrame(frame, state)
+}
+
+// resetHistory forgets the stacked frames, e.g. at the start of an episode
+func (t *ObservationTransformer) resetHistory() {
+	t.history = nil
+}
+
+// observe folds a sample into the running mean and variance
+func (t *ObservationTransformer) observe(raw []float64) {
+	t.count++
+	for i, x := range raw {
+		delta := x - t.mean[i]
+		t.mean[i] += delta / t.count
+		t.m2[i] += float64(delta * (x - t.mean[i]))
+	}
+}
+
+// State returns a snapshot of the transformer for persistence
+func (t *ObservationTransformer) State() ObservationState {
+	return ObservationState{
+		Features: t.features,
+		Count:    t.count,
+		Mean:     append([]float64(nil), t.mean...),
+		M2:       append([]float64(nil), t.m2...),
+	}
+}
+
+// RestoreObservationTransformer rebuilds a transformer from a saved state
+func RestoreObservationTransformer(state ObservationState) (*ObservationTransformer, error) {
+	if state.Features.Frames < 0 {
+		return nil, fmt.Errorf("stacked frames must not be negative, got %d", state.Features.Frames)
+	}
+	t := NewObservationTransformer(state.Features)
+	if len(state.Mean) != t.Size() || len(state.M2) != t.Size() {
+		return nil, fmt.Errorf("expected %d feature statistics, got mean=%d m2=%d",
+			t.Size(), len(state.Mean), len(state.M2))
+	}
+	t.count = state.Count
+	copy(t.mean, state.Mean)
+	copy(t.m2, state.M2)
+	return t, nil
+}
@@ -0,0 +1,149 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestObservationFeatures(t *testing.T) {
+	transformer := NewObservationTransformer(FeatureConfig{SinCos: true, CartState: true})
+	state := env.State{CartPosition: 0.5, CartVelocity: -1, AngleRadians: 2*math.Pi - 0.1, AngularVel: 0.2}
+
+	features := transformer.Transform(state)
+	want := []float64{-0.1, 0.2, math.Sin(-0.1), math.Cos(-0.1), 0.5, -1}
+	if len(features) != transformer.Size() || len(transformer.Names()) != transformer.Size() {
+		t.Fatalf("got %d features and %d names, want %d", len(features), len(transformer.Names()), transformer.Size())
+	}
+	for i := range want {
+		if math.Abs(features[i]-want[i]) > 1e-9 {
+			t.Errorf("feature %s = %.4f, want %.4f", transformer.Names()[i], features[i], want[i])
+		}
+	}
+}
+
+func TestObservationNormalization(t *testing.T) {
+	transformer := NewObservationTransformer(FeatureConfig{Normalize: true})
+
+	// Alternate between two states so the mean is 0.3 and the std is 0.1
+	for i := 0; i < 100; i++ {
+		angle := 0.2
+		if i%2 == 0 {
+			angle = 0.4
+		}
+		transformer.Transform(env.State{AngleRadians: angle, AngularVel: angle})
+	}
+
+	transformer.SetFrozen(true)
+	features := transformer.Transform(env.State{AngleRadians: 0.5, AngularVel: 0.3})
+	if math.Abs(features[0]-2.0) > 1e-4 {
+		t.Errorf("normalized angle = %.4f, want 2.0", features[0])
+	}
+	if math.Abs(features[1]) > 1e-4 {
+		t.Errorf("normalized angular velocity = %.4f, want 0", features[1])
+	}
+	if state := transformer.State(); state.Count != 100 {
+		t.Errorf("frozen transformer updated statistics: count=%v", state.Count)
+	}
+}
+
+func TestPolicyLeavesObservationStatistics(t *testing.T) {
+	net := NewNetwork()
+	net.SetObservationTransformer(NewObservationTransformer(FeatureConfig{Normalize: true}))
+	net.Forward(env.State{AngleRadians: 0.2, AngularVel: 0.1})
+	net.Forward(env.State{AngleRadians: 0.4, AngularVel: -0.1})
+	before := net.GetObservationTransformer().State()
+
+	// Mapping the policy over many states must not skew normalization
+	for angle := -1.0; angle <= 1; angle += 0.1 {
+		net.Policy(env.State{AngleRadians: angle, AngularVel: 3})
+	}
+	if after := net.GetObservationTransformer().State(); after.Count != before.Count {
+		t.Errorf("Policy folded %v states into the observation statistics", after.Count-before.Count)
+	}
+}
+
+func TestObservationFrames(t *testing.T) {
+	net := NewNetwork()
+	net.SetObservationTransformer(NewObservationTransformer(FeatureConfig{Frames: 3}))
+	transformer := net.GetObservationTransformer()
+	wantNames := []string{"angle", "angular_vel", "angle_prev1", "angular_vel_prev1", "angle_prev2", "angular_vel_prev2"}
+	if got := transformer.Names(); fmt.Sprint(got) != fmt.Sprint(wantNames) || transformer.Size() != len(wantNames) {
+		t.Fatalf("feature names = %v (size %d), want %v", got, transformer.Size(), wantNames)
+	}
+
+	// Before any step the current frame stands in for the missing ones
+	if got := transformer.Transform(env.State{AngleRadians: 0.1, AngularVel: 1}); fmt.Sprint(got) != fmt.Sprint([]float64{0.1, 1, 0.1, 1, 0.1, 1}) {
+		t.Errorf("features before history = %v, want the current frame repeated", got)
+	}
+
+	for i := 1; i <= 3; i++ {
+		net.Forward(env.State{AngleRadians: 0.1 * float64(i), AngularVel: float64(i)})
+	}
+	got := transformer.Transform(env.State{AngleRadians: 0.4, AngularVel: 4})
+	want := []float64{0.4, 4, 0.3, 3, 0.2, 2}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("feature %s = %.4f, want %.4f", wantNames[i], got[i], want[i])
+		}
+	}
+
+	net.SetEpisode(1)
+	if got := transformer.Transform(env.State{AngleRadians: 0.4, AngularVel: 4}); fmt.Sprint(got) != fmt.Sprint([]float64{0.4, 4, 0.4, 4, 0.4, 4}) {
+		t.Errorf("features after a new episode = %v, want no history", got)
+	}
+	if inputs := net.GetLastInputs(); len(inputs) != transformer.Size() {
+		t.Errorf("last inputs = %v, want %d stacked features", inputs, transformer.Size())
+	}
+
+	// Stacking survives a save and load
+	path := filepath.Join(t.TempDir(), "frames.json")
+	if err := net.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+	loaded := NewNetwork()
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if frames := loaded.GetObservationTransformer().Features().Frames; frames != 3 {
+		t.Errorf("loaded network stacks %d frames, want 3", frames)
+	}
+}
+
+func TestObservationPersistence(t *testing.T) {
+	net := NewNetwork()
+	net.SetObservationTransformer(NewObservationTransformer(FeatureConfig{Normalize: true, CartState: true}))
+	if err := net.SetFeatureWeights([]float64{0.7, -0.4}); err != nil {
+		t.Fatalf("SetFeatureWeights failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		net.Forward(env.State{AngleRadians: 0.1 * float64(i), CartPosition: 0.05 * float64(i)})
+	}
+
+	path := filepath.Join(t.TempDir(), "network.json")
+	if err := net.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	loaded := NewNetwork()
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if loaded.GetObservationTransformer() == nil {
+		t.Fatal("loaded network has no observation transformer")
+	}
+	if got := loaded.GetFeatureWeights(); got[0] != 0.7 || got[1] != -0.4 {
+		t.Errorf("feature weights = %v, want [0.7 -0.4]", got)
+	}
+
+	// Both networks should now produce the same force for the same state
+	net.GetObservationTransformer().SetFrozen(true)
+	loaded.GetObservationTransformer().SetFrozen(true)
+	state := env.State{AngleRadians: 0.3, AngularVel: -0.2, CartPosition: 0.4, CartVelocity: 0.1}
+	if a, b := net.Forward(state), loaded.Forward(state); math.Abs(a-b) > 1e-12 {
+		t.Errorf("forces differ after load: %.6f vs %.6f", a, b)
+	}
+}
@@ -0,0 +1,204 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+)
+
+// Optimizer names accepted by NewOptimizer
+const (
+	SGD     = "sgd"
+	Adam    = "adam"
+	RMSProp = "rmsprop"
+)
+
+// Optimizer turns a gradient into a weight step. Gradients point in the
+// direction of improvement, so the step is added to the weights
+type Optimizer interface {
+	// Step returns the change to apply to each weight for grad at learning rate lr
+	Step(grad []float64, lr float64) []float64
+	// Reset clears accumulated moments, e.g. after a rejected update
+	Reset()
+	// State returns a serializable copy of the optimizer and its moments
+	State() OptimizerState
+}
+
+// OptimizerConfig selects an optimizer and its hyperparameters
+type OptimizerConfig struct {
+	Name     string  `json:"name"`     // "sgd", "adam" or "rmsprop"
+	Momentum float64 `json:"momentum"` // SGD momentum; 0 gives plain SGD
+	Beta1    float64 `json:"beta1"`    // Adam first moment decay
+	Beta2    float64 `json:"beta2"`    // Adam second moment decay
+	Decay    float64 `json:"decay"`    // RMSProp squared gradient decay
+	Epsilon  float64 `json:"epsilon"`  // Numerical stability term for Adam and RMSProp
+}
+
+// NewDefaultOptimizerConfig returns plain SGD with the usual defaults for
+// the other optimizers
+func NewDefaultOptimizerConfig() OptimizerConfig {
+	return OptimizerConfig{
+		Name:     SGD,
+		Momentum: 0.0,
+		Beta1:    0.9,
+		Beta2:    0.999,
+		Decay:    0.9,
+		Epsilon:  1e-8,
+	}
+}
+
+// OptimizerState is an optimizer's configuration and accumulated moments,
+// saved with checkpoints so training resumes with the same dynamics
+type OptimizerState struct {
+	Config OptimizerConfig `json:"config"`
+	Steps  int             `json:"steps"`
+	First  []float64       `json:"first,omitempty"`  // Velocity (SGD) or first moment (Adam)
+	Second []float64       `json:"second,omitempty"` // Mean squared gradient (Adam, RMSProp)
+}
+
+// NewOptimizer creates the optimizer named by config
+func NewOptimizer(config OptimizerConfig) (Optimizer, error) {
+	switch config.Name {
+	case "", SGD:
+		if config.Momentum < 0 || config.Momentum >= 1 {
+			return nil, fmt.Errorf("sgd momentum must be in [0, 1), got %v", config.Momentum)
+		}
+		config.Name = SGD
+		return &sgdOptimizer{moments: moments{config: config}}, nil
+	case Adam:
+		if config.Beta1 < 0 || config.Beta1 >= 1 || config.Beta2 < 0 || config.Beta2 >= 1 {
+			return nil, fmt.Errorf("adam betas must be in [0, 1), got %v and %v", config.Beta1, config.Beta2)
+		}
+		return &adamOptimizer{moments: moments{config: config}}, nil
+	case RMSProp:
+		if config.Decay < 0 || config.Decay >= 1 {
+			return nil, fmt.Errorf("rmsprop decay must be in [0, 1), got %v", config.Decay)
+		}
+		return &rmsPropOptimizer{moments: moments{config: config}}, nil
+	default:
+		return nil, fmt.Errorf("unknown optimizer %q", config.Name)
+	}
+}
+
+// RestoreOptimizer recreates an optimizer from a saved state
+func RestoreOptimizer(state OptimizerState) (Optimizer, error) {
+	optimizer, err := NewOptimizer(state.Config)
+	if err != nil {
+		return nil, err
+	}
+	if !AllFinite(state.First) || !AllFinite(state.Second) {
+		return nil, fmt.Errorf("optimizer state has non-finite moments")
+	}
+
+	optimizer.(interface{ restore(OptimizerState) }).restore(state)
+	return optimizer, nil
+}
+
+// SetOptimizer sets the optimizer used for online updates; nil steps
+// along the gradient scaled by the learning rate
+func (n *Network) SetOptimizer(optimizer Optimizer) {
+	n.optimizer = optimizer
+}
+
+// GetOptimizer returns the optimizer used for online updates, if any
+func (n *Network) GetOptimizer() Optimizer {
+	return n.optimizer
+}
+
+// moments holds the per-weight running averages shared by all optimizers
+type moments struct {
+	config OptimizerConfig
+	steps  int
+	first  []float64
+	second []float64
+}
+
+// resize grows the moments to n weights, e.g. when feature weights are added
+func (m *moments) resize(n int) {
+	for len(m.first) < n {
+		m.first = append(m.first, 0)
+	}
+	for len(m.second) < n {
+		m.second = append(m.second, 0)
+	}
+}
+
+func (m *moments) Reset() {
+	m.steps = 0
+	m.first = nil
+	m.second = nil
+}
+
+func (m *moments) State() OptimizerState {
+	return OptimizerState{
+		Config: m.config,
+		Steps:  m.steps,
+		First:  append([]float64(nil), m.first...),
+		Second: append([]float64(nil), m.second...),
+	}
+}
+
+func (m *moments) restore(state OptimizerState) {
+	m.steps = state.Steps
+	m.first = append([]float64(nil), state.First...)
+	m.second = append([]float64(nil), state.Second...)
+}
+
+// sgdOptimizer is stochastic gradient descent with optional momentum
+type sgdOptimizer struct {
+	moments
+}
+
+func (o *sgdOptimizer) Step(grad []float64, lr float64) []float64 {
+	o.resize(len(grad))
+	o.steps++
+
+	step := make([]float64, len(grad))
+	for i, g := range grad {
+		o.first[i] = o.config.Momentum*o.first[i] + g
+		step[i] = lr * o.first[i]
+	}
+	return step
+}
+
+// adamOptimizer scales each weight's step by bias-corrected estimates of
+// the gradient's mean and variance
+type adamOptimizer struct {
+	moments
+}
+
+func (o *adamOptimizer) Step(grad []float64, lr float64) []float64 {
+	o.resize(len(grad))
+	o.steps++
+
+	beta1, beta2 := o.config.Beta1, o.config.Beta2
+	correction1 := 1 - math.Pow(beta1, float64(o.steps))
+	correction2 := 1 - math.Pow(beta2, float64(o.steps))
+
+	step := make([]float64, len(grad))
+	for i, g := range grad {
+		o.first[i] = beta1*o.first[i] + (1-beta1)*g
+		o.second[i] = beta2*o.second[i] + (1-beta2)*g*g
+		mean := o.first[i] / correction1
+		variance := o.second[i] / correction2
+		step[i] = lr * mean / (math.Sqrt(variance) + o.config.Epsilon)
+	}
+	return step
+}
+
+// rmsPropOptimizer divides each weight's step by a running RMS of its gradient
+type rmsPropOptimizer struct {
+	moments
+}
+
+func (o *rmsPropOptimizer) Step(grad []float64, lr float64) []float64 {
+	o.resize(len(grad))
+	o.steps++
+
+	decay := o.config.Decay
+	step := make([]float64, len(grad))
+	for i, g := range grad {
+		o.second[i] = decay*o.second[i] + (1-decay)*g*g
+		step[i] = lr * g / (math.Sqrt(o.second[i]) + o.config.Epsilon)
+	}
+	return step
+}
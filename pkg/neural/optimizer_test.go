@@ -0,0 +1,98 @@
+package neural
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestOptimizers(t *testing.T) {
+	newOptimizer := func(t *testing.T, name string) Optimizer {
+		config := NewDefaultOptimizerConfig()
+		config.Name = name
+		config.Momentum = 0.9
+		optimizer, err := NewOptimizer(config)
+		if err != nil {
+			t.Fatalf("NewOptimizer(%q): %v", name, err)
+		}
+		return optimizer
+	}
+
+	t.Run("sgd momentum accumulates", func(t *testing.T) {
+		optimizer := newOptimizer(t, SGD)
+		first := optimizer.Step([]float64{1}, 0.1)
+		second := optimizer.Step([]float64{1}, 0.1)
+		if math.Abs(first[0]-0.1) > 1e-12 || math.Abs(second[0]-0.19) > 1e-12 {
+			t.Errorf("steps = %v, %v; want 0.1 then 0.19", first[0], second[0])
+		}
+	})
+
+	t.Run("adam normalizes step size", func(t *testing.T) {
+		optimizer := newOptimizer(t, Adam)
+		step := optimizer.Step([]float64{100, -0.01}, 0.1)
+		if math.Abs(step[0]-0.1) > 1e-6 || math.Abs(step[1]+0.1) > 1e-4 {
+			t.Errorf("first Adam step = %v, want about [0.1 -0.1]", step)
+		}
+	})
+
+	t.Run("rmsprop follows gradient sign", func(t *testing.T) {
+		optimizer := newOptimizer(t, RMSProp)
+		step := optimizer.Step([]float64{2, -2}, 0.01)
+		if step[0] <= 0 || step[1] >= 0 || math.Abs(step[0]+step[1]) > 1e-12 {
+			t.Errorf("RMSProp step = %v, want symmetric steps along the gradient", step)
+		}
+	})
+
+	t.Run("state round trip", func(t *testing.T) {
+		for _, name := range []string{SGD, Adam, RMSProp} {
+			original := newOptimizer(t, name)
+			original.Step([]float64{0.5, -1, 2}, 0.05)
+
+			restored, err := RestoreOptimizer(original.State())
+			if err != nil {
+				t.Fatalf("RestoreOptimizer(%s): %v", name, err)
+			}
+
+			grad := []float64{1, 1, 1}
+			want := original.Step(grad, 0.05)
+			got := restored.Step(grad, 0.05)
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("%s: restored step %d = %v, want %v", name, i, got[i], want[i])
+				}
+			}
+		}
+	})
+
+	t.Run("unknown optimizer", func(t *testing.T) {
+		if _, err := NewOptimizer(OptimizerConfig{Name: "lbfgs"}); err == nil {
+			t.Error("expected error for unknown optimizer")
+		}
+	})
+
+	t.Run("saved with network", func(t *testing.T) {
+		net := NewNetwork()
+		net.SetOptimizer(newOptimizer(t, Adam))
+		net.Forward(env.State{AngleRadians: 0.2, AngularVel: 0.1})
+		net.Update(1.0)
+
+		path := filepath.Join(t.TempDir(), "network.json")
+		if err := net.SaveToFile(path); err != nil {
+			t.Fatalf("SaveToFile: %v", err)
+		}
+		loaded := NewNetwork()
+		if err := loaded.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile: %v", err)
+		}
+
+		if loaded.GetOptimizer() == nil {
+			t.Fatal("optimizer not restored")
+		}
+		got, want := loaded.GetOptimizer().State(), net.GetOptimizer().State()
+		if got.Config.Name != Adam || got.Steps != want.Steps || got.First[0] != want.First[0] {
+			t.Errorf("restored optimizer state = %+v, want %+v", got, want)
+		}
+	})
+}
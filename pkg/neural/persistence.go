@@ -3,6 +3,7 @@ package neural
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,6 +16,26 @@ type NetworkState struct {
 	SaveTime      string    `json:"save_time"`
 	Version       string    `json:"version"`
 	MetricsData   *MetricsData `json:"metrics_data,omitempty"`
+	Observation   *ObservationState `json:"observation,omitempty"`
+	FeatureWeights []float64 `json:"feature_weights,omitempty"`
+	Optimizer     *OptimizerState `json:"optimizer,omitempty"`
+	ActionSpace   *ActionSpace `json:"action_space,omitempty"`
+	Stochastic    *StochasticState `json:"stochastic,omitempty"` // Gaussian policy head, if enabled
+	Recurrent     *RecurrentState `json:"recurrent,omitempty"` // Recurrent cell, if enabled
+	RewardScale   *RewardScaleState `json:"reward_scale,omitempty"` // Running reward scaling statistics, if enabled
+	Preset        string    `json:"preset,omitempty"` // Pendulum preset the network was trained on
+	Progress      *ProgressState `json:"progress,omitempty"`
+}
+
+// ProgressState captures the progressive difficulty schedule and the
+// eligibility traces, so a resumed session learns exactly as it would have
+type ProgressState struct {
+	Difficulty    float64   `json:"difficulty"`
+	SuccessRate   float64   `json:"success_rate"`
+	SuccessWindow []bool    `json:"success_window,omitempty"`
+	Traces        []float64 `json:"traces,omitempty"`
+	Episode       int       `json:"episode"`
+	Step          int       `json:"step"`
 }
 
 // MetricsData contains information about metrics tracking for this network
@@ -34,16 +55,148 @@ type TrainingStats struct {
 	WeightChanges map[string]float64 `json:"weight_changes,omitempty"`
 }
 
-// SaveToFile saves the network state to a JSON file
-func (n *Network) SaveToFile(path string) error {
-	// Create basic network state
+// State returns the network's parameters and learning state
+func (n *Network) State() NetworkState {
 	state := NetworkState{
 		Weights:      n.GetWeights(),
 		LearningRate: n.learningRate,
 		SaveTime:     time.Now().Format(time.RFC3339),
 		Version:      "1.0.0",
+		Preset:       n.preset,
+		Progress: &ProgressState{
+			Difficulty:    n.difficulty,
+			SuccessRate:   n.successRate,
+			SuccessWindow: append([]bool(nil), n.successWindow...),
+			Traces:        append([]float64(nil), n.traces...),
+			Episode:       n.currentEpisode,
+			Step:          n.currentStep,
+		},
+	}
+
+	// Save the observation layer so the network sees the same inputs when loaded
+	if n.observer != nil {
+		observation := n.observer.State()
+		state.Observation = &observation
+		state.FeatureWeights = n.GetFeatureWeights()
+	}
+
+	// Save optimizer moments so resumed training continues smoothly
+	if n.optimizer != nil {
+		optimizer := n.optimizer.State()
+		state.Optimizer = &optimizer
+	}
+
+	// Save the action space so the network applies the forces it trained with
+	actionSpace := n.actionSpace
+	state.ActionSpace = &actionSpace
+
+	if n.stochastic != nil {
+		stochastic := n.stochastic.state()
+		state.Stochastic = &stochastic
+	}
+
+	if n.recurrent != nil {
+		state.Recurrent = &RecurrentState{Weights: n.GetRecurrentWeights()}
+	}
+
+	// Save the return statistics so resumed updates keep the same scale
+	if n.rewardScaler != nil {
+		rewardScale := n.rewardScaler.State()
+		state.RewardScale = &rewardScale
+	}
+
+	return state
+}
+
+// RestoreState replaces the network's parameters and learning state.
+// Parts missing from older files keep their current values
+func (n *Network) RestoreState(state NetworkState) error {
+	if err := n.SetWeights(state.Weights); err != nil {
+		return fmt.Errorf("failed to set weights: %w", err)
+	}
+
+	// Restore the observation layer, or fall back to raw inputs for older files
+	if state.Observation != nil {
+		observer, err := RestoreObservationTransformer(*state.Observation)
+		if err != nil {
+			return fmt.Errorf("failed to restore observation transformer: %w", err)
+		}
+		n.SetObservationTransformer(observer)
+		if err := n.SetFeatureWeights(state.FeatureWeights); err != nil {
+			return fmt.Errorf("failed to set feature weights: %w", err)
+		}
+	} else {
+		n.SetObservationTransformer(nil)
+	}
+
+	if state.Optimizer != nil {
+		optimizer, err := RestoreOptimizer(*state.Optimizer)
+		if err != nil {
+			return fmt.Errorf("failed to restore optimizer: %w", err)
+		}
+		n.SetOptimizer(optimizer)
+	}
+
+	// Older files fall back to the continuous output they were trained with
+	if state.ActionSpace != nil {
+		n.SetActionSpace(*state.ActionSpace)
+	} else {
+		n.SetActionSpace(NewDefaultActionSpace())
+	}
+
+	// Resume the learned policy std; files without a head act deterministically
+	if state.Stochastic != nil {
+		var rng *rand.Rand
+		if n.stochastic != nil {
+			rng = n.stochastic.rng
+		}
+		n.stochastic = &stochasticHead{logStd: state.Stochastic.LogStd}
+		if err := n.EnableStochasticPolicy(state.Stochastic.Config, rng); err != nil {
+			return fmt.Errorf("failed to restore stochastic policy: %w", err)
+		}
+	} else {
+		n.DisableStochasticPolicy()
+	}
+
+	// Restore the recurrent cell after the observation layer it reads
+	if state.Recurrent != nil {
+		n.EnableRecurrent()
+		if err := n.SetRecurrentWeights(state.Recurrent.Weights); err != nil {
+			return fmt.Errorf("failed to restore recurrent cell: %w", err)
+		}
+	} else {
+		n.DisableRecurrent()
+	}
+
+	// Files without statistics keep the current scaler, so scaling can be
+	// enabled when resuming a session trained without it
+	if state.RewardScale != nil {
+		n.SetRewardScaler(RestoreRewardScaler(*state.RewardScale))
+	}
+
+	if state.Preset != "" {
+		n.preset = state.Preset
+	}
+
+	if progress := state.Progress; progress != nil {
+		n.difficulty = progress.Difficulty
+		n.successRate = progress.SuccessRate
+		n.successWindow = append(n.successWindow[:0], progress.SuccessWindow...)
+		if len(progress.Traces) == len(n.traces) {
+			copy(n.traces, progress.Traces)
+		}
+		n.currentEpisode = progress.Episode
+		n.currentStep = progress.Step
 	}
 
+	n.SetLearningRate(state.LearningRate)
+	return nil
+}
+
+// SaveToFile saves the network state to a JSON file
+func (n *Network) SaveToFile(path string) error {
+	state := n.State()
+
 	// Add metrics data if available
 	if n.metrics != nil {
 		metricsData := &MetricsData{
@@ -117,7 +270,7 @@ func (n *Network) SaveToFile(path string) error {
 	// Log the save operation to metrics database
 	if n.metrics != nil {
 		n.metrics.LogNetworkOperation("save", path, true)
-	} else if n.debug {
+	} else if n.sampler.Episode() {
 		n.logger.Printf("[Network] Saved network state to %s", path)
 	}
 	
@@ -144,18 +297,14 @@ func (n *Network) LoadFromFile(path string) error {
 		return fmt.Errorf("failed to unmarshal network state: %w", err)
 	}
 
-	// Set weights
-	if err := n.SetWeights(state.Weights); err != nil {
+	if err := n.RestoreState(state); err != nil {
 		// Log failed load operation to metrics database
 		if n.metrics != nil {
 			n.metrics.LogNetworkOperation("load", path, false)
 		}
-		return fmt.Errorf("failed to set weights: %w", err)
+		return err
 	}
 
-	// Set learning rate
-	n.SetLearningRate(state.LearningRate)
-
 	// Update metrics data if available
 	if state.MetricsData != nil && n.metrics != nil {
 		n.currentEpisode = state.MetricsData.Episode
@@ -177,7 +326,7 @@ func (n *Network) LoadFromFile(path string) error {
 	// Log successful load operation to metrics database
 	if n.metrics != nil {
 		n.metrics.LogNetworkOperation("load", path, true)
-	} else if n.debug {
+	} else if n.sampler.Episode() {
 		n.logger.Printf("[Network] Loaded network state from %s (saved at %s)", 
 			path, state.SaveTime)
 	}
@@ -204,7 +353,7 @@ func (n *Network) SaveCheckpoint(checkpointDir string) error {
 	// Log successful checkpoint operation to metrics database
 	if n.metrics != nil {
 		n.metrics.LogNetworkOperation("checkpoint", path, true)
-	} else if n.debug {
+	} else if n.sampler.Episode() {
 		n.logger.Printf("[Network] Saved checkpoint to %s", path)
 	}
 	
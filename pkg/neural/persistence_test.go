@@ -25,6 +25,7 @@ func TestNetworkPersistence(t *testing.T) {
 		t.Fatalf("Failed to set test weights: %v", err)
 	}
 	net.SetLearningRate(testLearningRate)
+	net.SetPreset("heavy-cart")
 
 	t.Run("SaveAndLoad", func(t *testing.T) {
 		savePath := filepath.Join(tmpDir, "test_network.json")
@@ -52,6 +53,10 @@ func TestNetworkPersistence(t *testing.T) {
 			t.Fatalf("Failed to load network: %v", err)
 		}
 
+		if loadedNet.GetPreset() != "heavy-cart" {
+			t.Errorf("preset = %q, want %q", loadedNet.GetPreset(), "heavy-cart")
+		}
+
 		// Compare weights
 		loadedWeights := loadedNet.GetWeights()
 		for i, w := range testWeights {
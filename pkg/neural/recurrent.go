@@ -0,0 +1,221 @@
+package neural
+
+import (
+	"fmt"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
+)
+
+// recurrentWindow is how many recent steps of an episode the recurrent cell
+// keeps for backpropagation through time
+const recurrentWindow = 1024
+
+// RecurrentState is the serializable state of the recurrent cell. The cell's
+// hidden state belongs to an episode and is not saved
+type RecurrentState struct {
+	Weights []float64 `json:"weights"` // [Wz..., Uz, Bz, Wc..., Uc, Bc, Out]
+}
+
+// recurrentCell is a single-unit gated recurrent cell, a GRU without a reset
+// gate, over the network inputs x. Each step its update gate z decides how
+// much of the state h to replace with a candidate c:
+//
+//	z  = σ(Wz·x + Uz·h + Bz)
+//	c  = tanh(Wc·x + Uc·h + Bc)
+//	h' = (1-z)·h + z·c
+//
+// and the hidden node adds Out·h'
+type recurrentCell struct {
+	inputs  int             // Size of x
+	weights []float64       // [Wz..., Uz, Bz, Wc..., Uc, Bc, Out]
+	state   float64         // h after the last recorded step
+	steps   []recurrentStep // Ring of the episode's recorded steps, see recorded
+	first   int             // Index in steps of the oldest recorded step
+}
+
+// recurrentStep is one update of the cell, kept for backpropagation
+type recurrentStep struct {
+	x    []float64
+	prev float64 // h before the step
+	z, c float64
+}
+
+// newRecurrentCell creates a cell over inputs features. The candidate starts
+// as the angle, so the state is a moving average of it, and the output weight
+// starts at zero so the network behaves as before until it learns to use it
+func newRecurrentCell(inputs int) *recurrentCell {
+	cell := &recurrentCell{inputs: inputs, weights: make([]float64, 2*inputs+5)}
+	cell.weights[cell.wc()] = 1
+	return cell
+}
+
+// Offsets of each parameter in weights
+func (r *recurrentCell) uz() int  { return r.inputs }
+func (r *recurrentCell) bz() int  { return r.inputs + 1 }
+func (r *recurrentCell) wc() int  { return r.inputs + 2 }
+func (r *recurrentCell) uc() int  { return 2*r.inputs + 2 }
+func (r *recurrentCell) bc() int  { return 2*r.inputs + 3 }
+func (r *recurrentCell) out() int { return 2*r.inputs + 4 }
+
+// step computes the cell's update for inputs x from the current state
+// without recording it
+func (r *recurrentCell) step(x []float64) recurrentStep {
+	w := r.weights
+	gate := float64(w[r.uz()]*r.state) + w[r.bz()]
+	candidate := float64(w[r.uc()]*r.state) + w[r.bc()]
+	for i, xi := range x {
+		gate += float64(w[i] * xi)
+		candidate += float64(w[r.wc()+i] * xi)
+	}
+	return recurrentStep{
+		x:    x,
+		prev: r.state,
+		z:    1 / (1 + detmath.Exp(-gate)),
+		c:    detmath.Tanh(candidate),
+	}
+}
+
+// next returns the state after the step
+func (s recurrentStep) next() float64 {
+	return float64((1-s.z)*s.prev) + float64(s.z*s.c)
+}
+
+// output returns what the step adds to the hidden node
+func (r *recurrentCell) output(s recurrentStep) float64 {
+	return r.weights[r.out()] * s.next()
+}
+
+// record advances the state with a step the network acted on. The step's
+// inputs are copied into the buffer of the slot it takes, as the network
+// reuses theirs, so recording only allocates until the window first fills
+func (r *recurrentCell) record(s recurrentStep) {
+	r.state = s.next()
+	var slot *recurrentStep
+	switch {
+	case len(r.steps) == recurrentWindow:
+		// Overwrite the oldest step
+		slot = &r.steps[r.first]
+		r.first = (r.first + 1) % recurrentWindow
+	case len(r.steps) < cap(r.steps):
+		// Take a slot, and its buffer, left by an earlier episode
+		r.steps = r.steps[:len(r.steps)+1]
+		slot = &r.steps[len(r.steps)-1]
+	default:
+		r.steps = append(r.steps, recurrentStep{})
+		slot = &r.steps[len(r.steps)-1]
+	}
+	x := append(slot.x[:0], s.x...)
+	*slot = s
+	slot.x = x
+}
+
+// recorded returns the k-th recorded step, oldest first
+func (r *recurrentCell) recorded(k int) *recurrentStep {
+	return &r.steps[(r.first+k)%len(r.steps)]
+}
+
+// reset clears the state and recorded steps, e.g. at an episode boundary.
+// The slots are kept for the next episode to reuse
+func (r *recurrentCell) reset() {
+	r.state = 0
+	r.steps = r.steps[:0]
+	r.first = 0
+}
+
+// gradient backpropagates signals through time: it returns the gradient of
+// Σ signals[t]·(Out·h_t) in the weights over the most recently recorded
+// steps, oldest signal first, unrolling each step at most window steps back
+func (r *recurrentCell) gradient(signals []float64, window int) []float64 {
+	w := r.weights
+	grad := make([]float64, len(w))
+
+	// Align the signals with the latest steps; older ones have no record
+	if len(signals) > len(r.steps) {
+		signals = signals[len(signals)-len(r.steps):]
+	}
+	offset := len(r.steps) - len(signals)
+
+	for t, signal := range signals {
+		if signal == 0 {
+			continue
+		}
+		grad[r.out()] += signal * r.recorded(offset+t).next()
+
+		dh := signal * w[r.out()]
+		for k := t; k >= 0 && k > t-window && dh != 0; k-- {
+			s := r.recorded(offset + k)
+			dGate := dh * (s.c - s.prev) * s.z * (1 - s.z)
+			dCandidate := dh * s.z * (1 - s.c*s.c)
+			for i, xi := range s.x {
+				grad[i] += dGate * xi
+				grad[r.wc()+i] += dCandidate * xi
+			}
+			grad[r.uz()] += dGate * s.prev
+			grad[r.bz()] += dGate
+			grad[r.uc()] += dCandidate * s.prev
+			grad[r.bc()] += dCandidate
+			dh = dh*(1-s.z) + dGate*w[r.uz()] + dCandidate*w[r.uc()]
+		}
+	}
+	return grad
+}
+
+// EnableRecurrent adds a recurrent cell, so the network keeps an internal
+// state across the steps of an episode. The state is reset with
+// ResetHistory and SetEpisode. An existing cell keeps its learned weights
+func (n *Network) EnableRecurrent() {
+	if n.recurrent == nil {
+		n.recurrent = newRecurrentCell(len(n.InputNames()))
+	}
+}
+
+// DisableRecurrent removes the recurrent cell
+func (n *Network) DisableRecurrent() {
+	n.recurrent = nil
+}
+
+// Recurrent reports whether the network has a recurrent cell
+func (n *Network) Recurrent() bool {
+	return n.recurrent != nil
+}
+
+// RecurrentHidden returns the recurrent cell's current state and its weight
+// into the hidden node, or zeros without a cell, e.g. for visualization
+func (n *Network) RecurrentHidden() (state, weight float64) {
+	if n.recurrent == nil {
+		return 0, 0
+	}
+	return n.recurrent.state, n.recurrent.weights[n.recurrent.out()]
+}
+
+// GetRecurrentWeights returns the recurrent cell's weights, or nil without a cell
+func (n *Network) GetRecurrentWeights() []float64 {
+	if n.recurrent == nil {
+		return nil
+	}
+	return append([]float64(nil), n.recurrent.weights...)
+}
+
+// SetRecurrentWeights updates the recurrent cell's weights
+func (n *Network) SetRecurrentWeights(weights []float64) error {
+	if n.recurrent == nil {
+		return fmt.Errorf("network has no recurrent cell")
+	}
+	if len(weights) != len(n.recurrent.weights) {
+		return fmt.Errorf("expected %d recurrent weights, got %d", len(n.recurrent.weights), len(weights))
+	}
+	copy(n.recurrent.weights, weights)
+	return nil
+}
+
+// RecurrentGradient returns the gradient of Σ signals[t]·hidden_t in the
+// recurrent weights by truncated backpropagation through time, where
+// hidden_t is the hidden node's pre-activation on each of the most recent
+// len(signals) forward passes of the episode, oldest first, and each pass is
+// unrolled at most window steps back. It returns nil without a cell
+func (n *Network) RecurrentGradient(signals []float64, window int) []float64 {
+	if n.recurrent == nil {
+		return nil
+	}
+	return n.recurrent.gradient(signals, max(1, window))
+}
@@ -0,0 +1,123 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestRecurrent(t *testing.T) {
+	states := make([]env.State, 6)
+	for i := range states {
+		states[i] = env.State{AngleRadians: 0.1 * math.Sin(float64(i)), AngularVel: 0.3 * math.Cos(float64(i))}
+	}
+
+	t.Run("starts neutral and resets", func(t *testing.T) {
+		network := NewNetwork()
+		want := network.Policy(states[1])
+		network.EnableRecurrent()
+		if got := network.Policy(states[1]); got != want {
+			t.Errorf("enabling the cell changed the force from %v to %v", want, got)
+		}
+
+		for _, s := range states {
+			network.Forward(s)
+		}
+		if memory, _ := network.RecurrentHidden(); memory == 0 {
+			t.Error("expected the cell to remember the episode's angles")
+		}
+		network.ResetHistory()
+		if memory, _ := network.RecurrentHidden(); memory != 0 {
+			t.Errorf("state after reset = %v, want 0", memory)
+		}
+	})
+
+	t.Run("gradient matches finite differences", func(t *testing.T) {
+		network := NewNetwork()
+		network.EnableRecurrent()
+		rng := rand.New(rand.NewSource(1))
+		weights := network.GetRecurrentWeights()
+		for i := range weights {
+			weights[i] = rng.NormFloat64()
+		}
+		signals := []float64{0.5, -1, 0.2, 0.8, -0.3, 1}
+
+		// Σ signals[t]·Out·h_t over the episode for the given weights
+		objective := func(weights []float64) float64 {
+			network.SetRecurrentWeights(weights)
+			network.ResetHistory()
+			total := 0.0
+			for i, s := range states {
+				network.Forward(s)
+				memory, out := network.RecurrentHidden()
+				total += signals[i] * out * memory
+			}
+			return total
+		}
+
+		objective(weights)
+		grad := network.RecurrentGradient(signals, len(states))
+		const eps = 1e-6
+		for i := range weights {
+			plus := append([]float64(nil), weights...)
+			minus := append([]float64(nil), weights...)
+			plus[i] += eps
+			minus[i] -= eps
+			numeric := (objective(plus) - objective(minus)) / (2 * eps)
+			if math.Abs(numeric-grad[i]) > 1e-5 {
+				t.Errorf("weight %d: gradient %.6f, finite difference %.6f", i, grad[i], numeric)
+			}
+		}
+	})
+
+	t.Run("reuses its window without allocating", func(t *testing.T) {
+		network := NewNetwork()
+		network.EnableRecurrent()
+		for i := 0; i < recurrentWindow+3; i++ {
+			network.Forward(states[i%len(states)])
+		}
+		if allocs := testing.AllocsPerRun(100, func() { network.Forward(states[0]) }); allocs != 0 {
+			t.Errorf("forward pass with a full window allocated %v times", allocs)
+		}
+
+		// The window holds the latest steps, oldest first
+		cell := network.recurrent
+		for i, s := range states {
+			network.Forward(s)
+			want := network.inputs(nil, s, false)
+			got := cell.recorded(len(cell.steps) - 1).x
+			if got[0] != want[0] || got[1] != want[1] {
+				t.Errorf("step %d recorded inputs %v, want %v", i, got, want)
+			}
+		}
+		if last := cell.recorded(len(cell.steps) - 1).next(); last != cell.state {
+			t.Errorf("latest recorded step leads to %v, cell state is %v", last, cell.state)
+		}
+	})
+
+	t.Run("survives save and load", func(t *testing.T) {
+		network := NewNetwork()
+		network.SetObservationTransformer(NewObservationTransformer(FeatureConfig{SinCos: true}))
+		network.EnableRecurrent()
+		weights := network.GetRecurrentWeights()
+		weights[len(weights)-1] = 0.7
+		if err := network.SetRecurrentWeights(weights); err != nil {
+			t.Fatalf("SetRecurrentWeights failed: %v", err)
+		}
+
+		path := filepath.Join(t.TempDir(), "recurrent.json")
+		if err := network.SaveToFile(path); err != nil {
+			t.Fatalf("SaveToFile failed: %v", err)
+		}
+		loaded := NewNetwork()
+		if err := loaded.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile failed: %v", err)
+		}
+		if got := loaded.GetRecurrentWeights(); len(got) != len(weights) || got[len(got)-1] != 0.7 {
+			t.Errorf("loaded recurrent weights = %v, want %v", got, weights)
+		}
+	})
+}
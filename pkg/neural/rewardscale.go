@@ -0,0 +1,69 @@
+package neural
+
+import (
+	"math"
+)
+
+// RewardScaleState is the serializable state of a RewardScaler, saved with
+// checkpoints so resumed training scales rewards exactly as before
+type RewardScaleState struct {
+	Gamma  float64 `json:"gamma"`
+	Count  float64 `json:"count"`
+	Mean   float64 `json:"mean"`
+	M2     float64 `json:"m2"`
+	Return float64 `json:"return"` // Discounted return of the running episode so far
+}
+
+// RewardScaler divides rewards by the running standard deviation of the
+// discounted return (Welford's algorithm), so TD targets keep roughly the
+// same size whatever the reward function and learning rates need no
+// re-tuning when it changes. The mean is not subtracted: the sign of a
+// reward, and so whether the agent seeks or avoids it, is kept
+type RewardScaler struct {
+	state RewardScaleState
+}
+
+// NewRewardScaler creates a scaler for returns discounted by gamma
+func NewRewardScaler(gamma float64) *RewardScaler {
+	return &RewardScaler{state: RewardScaleState{Gamma: gamma}}
+}
+
+// RestoreRewardScaler recreates a scaler from its saved state
+func RestoreRewardScaler(state RewardScaleState) *RewardScaler {
+	return &RewardScaler{state: state}
+}
+
+// State returns the scaler's statistics
+func (s *RewardScaler) State() RewardScaleState {
+	return s.state
+}
+
+// Scale records the reward of one step and returns it in units of the
+// return's running std. done ends the episode's return
+func (s *RewardScaler) Scale(reward float64, done bool) float64 {
+	st := &s.state
+	st.Return = st.Gamma*st.Return + reward
+	st.Count++
+	delta := st.Return - st.Mean
+	st.Mean += delta / st.Count
+	st.M2 += delta * (st.Return - st.Mean)
+	if done {
+		st.Return = 0
+	}
+	return reward / s.Std()
+}
+
+// EndEpisode starts a new episode's return without recording a step, for
+// episodes cut short by a step limit rather than ended by a terminal step
+func (s *RewardScaler) EndEpisode() {
+	s.state.Return = 0
+}
+
+// Std returns the running std of the discounted return, 1 until two
+// returns have been seen
+func (s *RewardScaler) Std() float64 {
+	if s.state.Count < 2 {
+		return 1
+	}
+	return math.Sqrt(s.state.M2/s.state.Count) + 1e-8
+}
@@ -0,0 +1,79 @@
+package neural
+
+import (
+	"io"
+	"log"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewardScaler(t *testing.T) {
+	// Without discounting the returns are the rewards, with a std of 1
+	scaler := NewRewardScaler(0)
+	if got := scaler.Scale(1, false); got != 1 {
+		t.Errorf("first reward scaled to %.4f, want it unscaled", got)
+	}
+	for i := 1; i < 100; i++ {
+		scaler.Scale(float64(1+2*(i%2)), false)
+	}
+	if std := scaler.Std(); math.Abs(std-1) > 1e-4 {
+		t.Errorf("std = %.4f, want 1", std)
+	}
+
+	// Multiplying every reward by a constant leaves the scaled rewards alone
+	small, large := NewRewardScaler(0.9), NewRewardScaler(0.9)
+	for i := 0; i < 200; i++ {
+		reward := math.Sin(float64(i)) + 0.5
+		done := i%50 == 49
+		a, b := small.Scale(reward, done), large.Scale(10*reward, done)
+		if i >= 2 && math.Abs(a-b) > 1e-6 {
+			t.Fatalf("step %d: scaled rewards %.6f and %.6f differ with the reward scale", i, a, b)
+		}
+	}
+	if state := small.State(); state.Return != 0 {
+		t.Errorf("return after a terminal step = %.4f, want 0", state.Return)
+	}
+
+	// A truncated episode's return does not carry into the next one
+	large.Scale(5, false)
+	before := large.State()
+	large.EndEpisode()
+	if after := large.State(); after.Return != 0 || after.Count != before.Count || after.M2 != before.M2 {
+		t.Errorf("EndEpisode left %+v from %+v, want only the return reset", after, before)
+	}
+}
+
+func TestRewardScalePersistence(t *testing.T) {
+	net := NewNetwork()
+	net.SetLogger(log.New(io.Discard, "", 0))
+	net.SetRewardScaler(NewRewardScaler(0.99))
+	for i := 0; i < 10; i++ {
+		net.RewardScaler().Scale(float64(i), false)
+	}
+	path := filepath.Join(t.TempDir(), "network.json")
+	if err := net.SaveToFile(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded := NewNetwork()
+	loaded.SetLogger(log.New(io.Discard, "", 0))
+	if err := loaded.LoadFromFile(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.RewardScaler() == nil {
+		t.Fatal("loaded network lost its reward scaler")
+	}
+	if got, want := loaded.RewardScaler().State(), net.RewardScaler().State(); got != want {
+		t.Errorf("restored statistics %+v, want %+v", got, want)
+	}
+
+	// A file saved without scaling keeps the scaler enabled on resume
+	state := NewNetwork().State()
+	if err := loaded.RestoreState(state); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if loaded.RewardScaler() == nil {
+		t.Error("restoring a file without statistics disabled reward scaling")
+	}
+}
@@ -0,0 +1,58 @@
+package neural
+
+import (
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// DefaultSaliencyDelta is the input perturbation Saliency uses when given
+// none: small next to the inputs' scale, large next to rounding
+const DefaultSaliencyDelta = 1e-4
+
+// Saliency returns how strongly the force responds to each network input
+// around state, ∂force/∂input in InputNames order, by central differences
+// of ±delta on the input. Forces are taken before snapping to the action
+// space, whose steps would hide the slope of bang-bang and discrete
+// controllers, but after clipping, so a saturated controller reads as
+// insensitive. Nothing is recorded, and the observation statistics and
+// recurrent state are left alone
+func (n *Network) Saliency(state env.State, delta float64) []float64 {
+	return n.saliency(n.inputs(nil, state, false), delta)
+}
+
+// SaliencyBatch returns Saliency for each state, evaluating them all with
+// one input buffer, e.g. to map sensitivity over a grid of states
+func (n *Network) SaliencyBatch(states []env.State, delta float64) [][]float64 {
+	gradients := make([][]float64, len(states))
+	var inputs []float64
+	for i, state := range states {
+		inputs = n.inputs(inputs, state, false)
+		gradients[i] = n.saliency(inputs, delta)
+	}
+	return gradients
+}
+
+// saliency differentiates the continuous force in each of inputs, which it
+// perturbs in place and restores
+func (n *Network) saliency(inputs []float64, delta float64) []float64 {
+	if delta <= 0 {
+		delta = DefaultSaliencyDelta
+	}
+	gradient := make([]float64, len(inputs))
+	for i, x := range inputs {
+		inputs[i] = x + delta
+		up := n.continuousForce(inputs)
+		inputs[i] = x - delta
+		down := n.continuousForce(inputs)
+		inputs[i] = x
+		gradient[i] = (up - down) / (2 * delta)
+	}
+	return gradient
+}
+
+// continuousForce is the force output computes for inputs before it is
+// snapped to the action space
+func (n *Network) continuousForce(inputs []float64) float64 {
+	_, hidden := n.output(inputs, n.recurrentOutput(inputs))
+	return ActionSpace{MaxForce: n.actionSpace.MaxForce}.Map(detmath.Tanh(hidden) * forceScale)
+}
@@ -0,0 +1,56 @@
+package neural
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestSaliency(t *testing.T) {
+	network := NewNetwork()
+	state := env.State{AngleRadians: 0.05, AngularVel: -0.1}
+
+	// force = 5·tanh(h) with h = -6·angle - 3·angular velocity
+	hidden := -6*0.05 - 3*-0.1
+	slope := forceScale * (1 - math.Pow(math.Tanh(hidden), 2))
+	gradient := network.Saliency(state, 0)
+	if len(gradient) != 2 || math.Abs(gradient[0]+6*slope) > 1e-4 || math.Abs(gradient[1]+3*slope) > 1e-4 {
+		t.Errorf("Saliency = %v, want [%v %v]", gradient, -6*slope, -3*slope)
+	}
+
+	t.Run("saturated states are insensitive", func(t *testing.T) {
+		if gradient := network.Saliency(env.State{AngleRadians: 2}, 0); math.Abs(gradient[0]) > 1e-3 {
+			t.Errorf("∂force/∂angle far from upright = %v, want about 0", gradient[0])
+		}
+	})
+
+	t.Run("sees through the action space", func(t *testing.T) {
+		bangBang := NewNetwork()
+		bangBang.SetActionSpace(ActionSpace{Type: BangBangActions, MaxForce: 5})
+		if got := bangBang.Saliency(state, 0); math.Abs(got[0]-gradient[0]) > 1e-9 {
+			t.Errorf("bang-bang ∂force/∂angle = %v, want the continuous %v", got[0], gradient[0])
+		}
+	})
+
+	t.Run("batch matches and records nothing", func(t *testing.T) {
+		network.EnableRecurrent()
+		network.Forward(state)
+		inputs := network.GetLastInputs()
+		memory, _ := network.RecurrentHidden()
+
+		states := []env.State{state, {AngleRadians: -0.2, AngularVel: 0.4}}
+		batch := network.SaliencyBatch(states, 0)
+		for i, s := range states {
+			single := network.Saliency(s, 0)
+			for j := range single {
+				if batch[i][j] != single[j] {
+					t.Errorf("state %d input %d: batch %v, single %v", i, j, batch[i][j], single[j])
+				}
+			}
+		}
+		if after, _ := network.RecurrentHidden(); after != memory || network.GetLastInputs()[0] != inputs[0] {
+			t.Error("saliency changed the recorded pass or the recurrent state")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+)
+
+// Learning rate schedule names accepted by NewSchedule
+const (
+	ConstantSchedule = "constant" // Base throughout
+	StepSchedule     = "step"     // Base multiplied by Factor every Every episodes
+	CosineSchedule   = "cosine"   // Cosine anneal from Base to Min over Episodes
+	WarmupSchedule   = "warmup"   // Linear ramp up to Base over Warmup episodes, then Base
+	AdaptiveSchedule = "adaptive" // Decay by Decay each episode, 10% faster while the success rate is above 70%
+)
+
+// Schedule sets the learning rate of each training episode
+type Schedule interface {
+	// Rate returns the learning rate for an episode, counting from 0, given
+	// the rate of the episode before and the success rate so far
+	Rate(episode int, previous, successRate float64) float64
+}
+
+// ScheduleConfig selects a learning rate schedule and its parameters
+type ScheduleConfig struct {
+	Name     string  `json:"name"`     // "constant", "step", "cosine", "warmup" or "adaptive"
+	Base     float64 `json:"base"`     // Rate of the first episode after any warmup
+	Min      float64 `json:"min"`      // Floor of the decaying schedules
+	Decay    float64 `json:"decay"`    // Per-episode factor of the adaptive schedule
+	Factor   float64 `json:"factor"`   // Multiplier of each step of the step schedule
+	Every    int     `json:"every"`    // Episodes between steps of the step schedule
+	Episodes int     `json:"episodes"` // Length of the cosine anneal
+	Warmup   int     `json:"warmup"`   // Episodes ramping linearly up to the schedule's rate, for any schedule
+}
+
+// ScheduleNames lists the schedules NewSchedule accepts
+func ScheduleNames() []string {
+	return []string{ConstantSchedule, StepSchedule, CosineSchedule, WarmupSchedule, AdaptiveSchedule}
+}
+
+// NewSchedule creates the schedule named by config
+func NewSchedule(config ScheduleConfig) (Schedule, error) {
+	if config.Base <= 0 || config.Min < 0 {
+		return nil, fmt.Errorf("learning rate schedule needs a positive base and non-negative min, got base=%g min=%g", config.Base, config.Min)
+	}
+	if config.Warmup < 0 {
+		return nil, fmt.Errorf("warmup must not be negative, got %d episodes", config.Warmup)
+	}
+
+	var schedule Schedule
+	switch config.Name {
+	case ConstantSchedule:
+		schedule = constantSchedule{config}
+	case StepSchedule:
+		if config.Every <= 0 || config.Factor <= 0 {
+			return nil, fmt.Errorf("step schedule needs positive every and factor, got every=%d factor=%g", config.Every, config.Factor)
+		}
+		schedule = stepSchedule{config}
+	case CosineSchedule:
+		if config.Episodes <= 0 {
+			return nil, fmt.Errorf("cosine schedule needs a positive number of episodes, got %d", config.Episodes)
+		}
+		schedule = cosineSchedule{config}
+	case WarmupSchedule:
+		if config.Warmup == 0 {
+			return nil, fmt.Errorf("warmup schedule needs warmup episodes")
+		}
+		schedule = constantSchedule{config}
+	case AdaptiveSchedule:
+		if config.Decay <= 0 {
+			return nil, fmt.Errorf("adaptive schedule needs a positive decay, got %g", config.Decay)
+		}
+		schedule = adaptiveSchedule{config}
+	default:
+		return nil, fmt.Errorf("unknown learning rate schedule %q", config.Name)
+	}
+
+	if config.Warmup > 0 {
+		schedule = warmupSchedule{schedule, config.Warmup}
+	}
+	return schedule, nil
+}
+
+// constantSchedule keeps the base rate
+type constantSchedule struct{ config ScheduleConfig }
+
+func (s constantSchedule) Rate(int, float64, float64) float64 {
+	return s.config.Base
+}
+
+// stepSchedule decays the rate in steps
+type stepSchedule struct{ config ScheduleConfig }
+
+func (s stepSchedule) Rate(episode int, _, _ float64) float64 {
+	rate := s.config.Base * math.Pow(s.config.Factor, float64(episode/s.config.Every))
+	return math.Max(s.config.Min, rate)
+}
+
+// cosineSchedule anneals the rate along half a cosine, then stays at Min
+type cosineSchedule struct{ config ScheduleConfig }
+
+func (s cosineSchedule) Rate(episode int, _, _ float64) float64 {
+	progress := math.Min(1, float64(episode)/float64(s.config.Episodes))
+	return s.config.Min + (s.config.Base-s.config.Min)*(1+math.Cos(math.Pi*progress))/2
+}
+
+// adaptiveSchedule decays the previous rate, faster while training succeeds
+type adaptiveSchedule struct{ config ScheduleConfig }
+
+func (s adaptiveSchedule) Rate(episode int, previous, successRate float64) float64 {
+	if episode == 0 || previous <= 0 {
+		return s.config.Base
+	}
+	decay := s.config.Decay
+	if successRate > 0.7 {
+		decay *= 0.9
+	}
+	return math.Max(s.config.Min, previous*decay)
+}
+
+// warmupSchedule ramps a schedule's rate up linearly over its first episodes
+type warmupSchedule struct {
+	schedule Schedule
+	episodes int
+}
+
+func (s warmupSchedule) Rate(episode int, previous, successRate float64) float64 {
+	// The wrapped schedule sees its own rate, not the ramp, as previous
+	if episode > 0 && episode <= s.episodes {
+		previous *= float64(s.episodes) / float64(episode)
+	}
+	rate := s.schedule.Rate(episode, previous, successRate)
+	if episode < s.episodes {
+		rate *= float64(episode+1) / float64(s.episodes)
+	}
+	return rate
+}
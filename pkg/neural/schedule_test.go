@@ -0,0 +1,101 @@
+package neural
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	newSchedule := func(t *testing.T, config ScheduleConfig) Schedule {
+		schedule, err := NewSchedule(config)
+		if err != nil {
+			t.Fatalf("NewSchedule(%+v): %v", config, err)
+		}
+		return schedule
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+
+	t.Run("constant keeps base", func(t *testing.T) {
+		schedule := newSchedule(t, ScheduleConfig{Name: ConstantSchedule, Base: 0.1})
+		for _, episode := range []int{0, 10, 1000} {
+			if rate := schedule.Rate(episode, 0.05, 1); rate != 0.1 {
+				t.Errorf("Rate(%d) = %v, want 0.1", episode, rate)
+			}
+		}
+	})
+
+	t.Run("step decays every interval down to min", func(t *testing.T) {
+		schedule := newSchedule(t, ScheduleConfig{Name: StepSchedule, Base: 0.1, Min: 0.02, Factor: 0.5, Every: 10})
+		for episode, want := range map[int]float64{0: 0.1, 9: 0.1, 10: 0.05, 25: 0.025, 30: 0.02, 100: 0.02} {
+			if rate := schedule.Rate(episode, 0, 0); !near(rate, want) {
+				t.Errorf("Rate(%d) = %v, want %v", episode, rate, want)
+			}
+		}
+	})
+
+	t.Run("cosine anneals from base to min", func(t *testing.T) {
+		schedule := newSchedule(t, ScheduleConfig{Name: CosineSchedule, Base: 0.1, Min: 0.01, Episodes: 100})
+		for episode, want := range map[int]float64{0: 0.1, 50: 0.055, 100: 0.01, 200: 0.01} {
+			if rate := schedule.Rate(episode, 0, 0); !near(rate, want) {
+				t.Errorf("Rate(%d) = %v, want %v", episode, rate, want)
+			}
+		}
+	})
+
+	t.Run("warmup ramps up to base", func(t *testing.T) {
+		schedule := newSchedule(t, ScheduleConfig{Name: WarmupSchedule, Base: 0.1, Warmup: 4})
+		for episode, want := range map[int]float64{0: 0.025, 1: 0.05, 3: 0.1, 10: 0.1} {
+			if rate := schedule.Rate(episode, 0, 0); !near(rate, want) {
+				t.Errorf("Rate(%d) = %v, want %v", episode, rate, want)
+			}
+		}
+	})
+
+	t.Run("adaptive decays faster while succeeding", func(t *testing.T) {
+		schedule := newSchedule(t, ScheduleConfig{Name: AdaptiveSchedule, Base: 0.1, Min: 0.001, Decay: 0.9})
+		if rate := schedule.Rate(0, 0, 0); rate != 0.1 {
+			t.Errorf("first rate = %v, want base 0.1", rate)
+		}
+		if rate := schedule.Rate(1, 0.1, 0.5); !near(rate, 0.09) {
+			t.Errorf("rate while failing = %v, want 0.09", rate)
+		}
+		if rate := schedule.Rate(1, 0.1, 0.8); !near(rate, 0.081) {
+			t.Errorf("rate while succeeding = %v, want 0.081", rate)
+		}
+		if rate := schedule.Rate(1, 0.001, 0); rate != 0.001 {
+			t.Errorf("rate at min = %v, want 0.001", rate)
+		}
+	})
+
+	t.Run("warmup wraps a decaying schedule", func(t *testing.T) {
+		schedule := newSchedule(t, ScheduleConfig{Name: AdaptiveSchedule, Base: 0.1, Decay: 0.5, Warmup: 2})
+		rate := 0.0
+		var rates []float64
+		for episode := 0; episode < 4; episode++ {
+			rate = schedule.Rate(episode, rate, 0)
+			rates = append(rates, rate)
+		}
+		want := []float64{0.05, 0.05, 0.025, 0.0125}
+		for i := range want {
+			if !near(rates[i], want[i]) {
+				t.Fatalf("rates = %v, want %v", rates, want)
+			}
+		}
+	})
+
+	t.Run("invalid configs are rejected", func(t *testing.T) {
+		for _, config := range []ScheduleConfig{
+			{Name: "linear", Base: 0.1},
+			{Name: ConstantSchedule},
+			{Name: StepSchedule, Base: 0.1},
+			{Name: CosineSchedule, Base: 0.1},
+			{Name: WarmupSchedule, Base: 0.1},
+			{Name: AdaptiveSchedule, Base: 0.1},
+			{Name: ConstantSchedule, Base: 0.1, Warmup: -1},
+		} {
+			if _, err := NewSchedule(config); err == nil {
+				t.Errorf("NewSchedule(%+v) succeeded, want error", config)
+			}
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
+)
+
+// StochasticPolicy configures an optional Gaussian policy head. While
+// training, Forward samples the force from a normal distribution around the
+// network's output with a learned standard deviation, and UpdateTD follows
+// the policy gradient plus an entropy bonus, as in soft actor-critic. Act and
+// Policy use the mean, so evaluation stays deterministic
+type StochasticPolicy struct {
+	InitialStd  float64 `json:"initial_std"` // Starting standard deviation of the force in N
+	MinStd      float64 `json:"min_std"`     // Smallest std learning may reach, keeping some exploration
+	MaxStd      float64 `json:"max_std"`     // Largest std learning may reach
+	Temperature float64 `json:"temperature"` // Weight α of the entropy bonus in the policy loss
+}
+
+// NewDefaultStochasticPolicy starts with 1N of noise and a small entropy bonus
+func NewDefaultStochasticPolicy() StochasticPolicy {
+	return StochasticPolicy{InitialStd: 1.0, MinStd: 0.05, MaxStd: forceScale, Temperature: 0.01}
+}
+
+// StochasticState is the serializable state of the policy head
+type StochasticState struct {
+	Config StochasticPolicy `json:"config"`
+	LogStd float64          `json:"log_std"`
+}
+
+// stochasticHead is an enabled Gaussian policy head and its last sample
+type stochasticHead struct {
+	config     StochasticPolicy
+	logStd     float64
+	rng        *rand.Rand
+	lastMean   float64 // Mean force of the last Forward, before the action space
+	lastSample float64 // Sampled force of the last Forward, before the action space
+	lastHidden float64 // Hidden pre-activation of the last Forward
+}
+
+// EnableStochasticPolicy switches the network to the Gaussian policy head,
+// drawing noise from rng (a time-seeded source if nil). If the head is
+// already enabled, e.g. by RestoreState, its learned std is kept and only the
+// limits, temperature and random source change
+func (n *Network) EnableStochasticPolicy(config StochasticPolicy, rng *rand.Rand) error {
+	if config.MinStd <= 0 || config.MaxStd < config.MinStd {
+		return fmt.Errorf("policy std limits must satisfy 0 < min <= max, got [%g, %g]", config.MinStd, config.MaxStd)
+	}
+	if config.Temperature < 0 {
+		return fmt.Errorf("entropy temperature must not be negative, got %g", config.Temperature)
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	if n.stochastic == nil {
+		n.stochastic = &stochasticHead{logStd: math.Log(config.InitialStd)}
+	}
+	n.stochastic.config = config
+	n.stochastic.rng = rng
+	n.stochastic.clampStd()
+	return nil
+}
+
+// DisableStochasticPolicy returns the network to its deterministic output
+func (n *Network) DisableStochasticPolicy() {
+	n.stochastic = nil
+}
+
+// StochasticPolicy returns the policy head's configuration and whether it is enabled
+func (n *Network) StochasticPolicy() (StochasticPolicy, bool) {
+	if n.stochastic == nil {
+		return StochasticPolicy{}, false
+	}
+	return n.stochastic.config, true
+}
+
+// PolicyStd returns the learned standard deviation of the force, or 0 for
+// the deterministic output
+func (n *Network) PolicyStd() float64 {
+	if n.stochastic == nil {
+		return 0
+	}
+	return n.stochastic.std()
+}
+
+// PolicyEntropy returns the differential entropy of the force distribution,
+// ½·ln(2πeσ²). The deterministic output has no entropy to report and gives 0
+func (n *Network) PolicyEntropy() float64 {
+	if n.stochastic == nil {
+		return 0
+	}
+	return n.stochastic.logStd + 0.5*math.Log(2*math.Pi*math.E)
+}
+
+// std returns the current standard deviation
+func (h *stochasticHead) std() float64 {
+	return math.Exp(h.logStd)
+}
+
+// clampStd keeps the learned std within the configured limits
+func (h *stochasticHead) clampStd() {
+	h.logStd = clip(h.logStd, math.Log(h.config.MinStd), math.Log(h.config.MaxStd))
+}
+
+// sample draws a force around the mean output for the hidden pre-activation
+// and remembers it for the next update
+func (h *stochasticHead) sample(hidden float64) float64 {
+	h.lastHidden = hidden
+	h.lastMean = forceScale * detmath.Tanh(hidden)
+	h.lastSample = h.lastMean + h.std()*h.rng.NormFloat64()
+	return h.lastSample
+}
+
+// weightScore returns ∇log π(a|s) for [angleWeight, angularVelWeight, bias,
+// featureWeights...] at the last sample, given the inputs it was drawn for
+func (h *stochasticHead) weightScore(inputs []float64) []float64 {
+	variance := h.std() * h.std()
+	tanh := detmath.Tanh(h.lastHidden)
+	dHidden := (h.lastSample - h.lastMean) / variance * forceScale * (1 - tanh*tanh)
+
+	// The hidden node subtracts every weighted input and adds the bias
+	score := make([]float64, len(inputs)+1)
+	score[0] = -dHidden * inputs[0]
+	score[1] = -dHidden * inputs[1]
+	score[2] = dHidden
+	for i := 2; i < len(inputs); i++ {
+		score[i+1] = -dHidden * inputs[i]
+	}
+	return score
+}
+
+// updateStd steps the log std along the policy gradient weighted by the TD
+// error, plus the entropy bonus, whose gradient in log std is 1
+func (h *stochasticHead) updateStd(tdError, lr float64) {
+	z := (h.lastSample - h.lastMean) / h.std()
+	h.logStd += lr * (tdError*(z*z-1) + h.config.Temperature)
+	h.clampStd()
+}
+
+// state returns the head's serializable state
+func (h *stochasticHead) state() StochasticState {
+	return StochasticState{Config: h.config, LogStd: h.logStd}
+}
@@ -0,0 +1,118 @@
+package neural
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestStochasticPolicy(t *testing.T) {
+	state := env.State{AngleRadians: 0.1, AngularVel: -0.2}
+
+	t.Run("samples while training, acts on the mean", func(t *testing.T) {
+		network := NewNetwork()
+		mean := network.Policy(state)
+		if err := network.EnableStochasticPolicy(NewDefaultStochasticPolicy(), rand.New(rand.NewSource(1))); err != nil {
+			t.Fatalf("EnableStochasticPolicy failed: %v", err)
+		}
+
+		var sum, sumSq float64
+		const samples = 2000
+		for i := 0; i < samples; i++ {
+			d := network.Forward(state) - mean
+			sum += d
+			sumSq += d * d
+		}
+		if bias := sum / samples; math.Abs(bias) > 0.1 {
+			t.Errorf("samples are off the mean by %.3f on average", bias)
+		}
+		if std := math.Sqrt(sumSq / samples); math.Abs(std-1) > 0.1 {
+			t.Errorf("sample std = %.3f, want about the initial 1N", std)
+		}
+		if got := network.Act(state); got != mean {
+			t.Errorf("Act = %v, want the mean force %v", got, mean)
+		}
+	})
+
+	t.Run("score is the gradient of the log-probability", func(t *testing.T) {
+		network := NewNetwork()
+		network.EnableStochasticPolicy(NewDefaultStochasticPolicy(), rand.New(rand.NewSource(2)))
+		network.Forward(state)
+		head := network.stochastic
+		score := head.weightScore(network.lastInputs)
+
+		// Numerically differentiate log π of the fixed sample in each weight
+		logProb := func(weights []float64) float64 {
+			network.SetWeights(weights)
+			_, hidden, _ := network.evaluate(state)
+			z := (head.lastSample - forceScale*math.Tanh(hidden)) / head.std()
+			return -0.5 * z * z
+		}
+		weights := network.GetWeights()
+		const h = 1e-6
+		for i := range weights {
+			up := append([]float64(nil), weights...)
+			down := append([]float64(nil), weights...)
+			up[i] += h
+			down[i] -= h
+			want := (logProb(up) - logProb(down)) / (2 * h)
+			if math.Abs(score[i]-want) > 1e-4*math.Max(1, math.Abs(want)) {
+				t.Errorf("score[%d] = %v, want %v", i, score[i], want)
+			}
+		}
+	})
+
+	t.Run("entropy bonus widens the policy within its limits", func(t *testing.T) {
+		network := NewNetwork()
+		config := NewDefaultStochasticPolicy()
+		config.Temperature = 1
+		config.MaxStd = 2
+		network.EnableStochasticPolicy(config, rand.New(rand.NewSource(3)))
+		network.SetLearningRate(0.1)
+
+		entropy := network.PolicyEntropy()
+		for i := 0; i < 100; i++ {
+			network.stochastic.updateStd(0, network.GetLearningRate())
+		}
+		if network.PolicyEntropy() <= entropy {
+			t.Errorf("entropy %.3f did not grow from %.3f with no TD error", network.PolicyEntropy(), entropy)
+		}
+		if std := network.PolicyStd(); std != 2 {
+			t.Errorf("std = %v, want it held at the limit 2", std)
+		}
+	})
+
+	t.Run("persistence", func(t *testing.T) {
+		network := NewNetwork()
+		network.EnableStochasticPolicy(NewDefaultStochasticPolicy(), nil)
+		network.stochastic.logStd = math.Log(0.5)
+
+		restored := NewNetwork()
+		if err := restored.RestoreState(network.State()); err != nil {
+			t.Fatalf("RestoreState failed: %v", err)
+		}
+		if std := restored.PolicyStd(); math.Abs(std-0.5) > 1e-12 {
+			t.Errorf("restored std = %v, want 0.5", std)
+		}
+
+		// Re-enabling a restored head keeps what it learned
+		restored.EnableStochasticPolicy(NewDefaultStochasticPolicy(), nil)
+		if std := restored.PolicyStd(); math.Abs(std-0.5) > 1e-12 {
+			t.Errorf("std after re-enabling = %v, want 0.5", std)
+		}
+
+		network.DisableStochasticPolicy()
+		if err := restored.RestoreState(network.State()); err != nil {
+			t.Fatalf("RestoreState failed: %v", err)
+		}
+		if _, ok := restored.StochasticPolicy(); ok {
+			t.Error("deterministic state left the policy head enabled")
+		}
+	})
+
+	if err := NewNetwork().EnableStochasticPolicy(StochasticPolicy{MinStd: 1, MaxStd: 0.5}, nil); err == nil {
+		t.Error("expected error for inverted std limits")
+	}
+}
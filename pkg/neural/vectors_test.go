@@ -0,0 +1,130 @@
+package neural
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+var updateVectors = flag.Bool("update", false, "rewrite test vector fixtures in testdata")
+
+// forwardVector is one canonical forward pass and its exact outputs
+type forwardVector struct {
+	State  env.State `json:"state"`
+	Force  float64   `json:"force"`
+	Hidden float64   `json:"hidden"`
+}
+
+// vectorNetworks builds one network per topology, with fixed weights
+func vectorNetworks() map[string]*Network {
+	build := func(features FeatureConfig, configure func(n *Network)) *Network {
+		n := NewNetwork()
+		n.SetLogger(log.New(io.Discard, "", 0))
+		if features != (FeatureConfig{}) {
+			n.SetObservationTransformer(NewObservationTransformer(features))
+		}
+		n.SetWeights([]float64{0.8, -0.35, 0.1})
+		if configure != nil {
+			configure(n)
+		}
+		n.SetEvalMode(true)
+		return n
+	}
+	return map[string]*Network{
+		"raw": build(FeatureConfig{}, nil),
+		"features": build(FeatureConfig{SinCos: true, CartState: true, Frames: 2}, func(n *Network) {
+			weights := make([]float64, len(n.GetFeatureWeights()))
+			for i := range weights {
+				weights[i] = 0.2 - 0.1*float64(i)
+			}
+			n.SetFeatureWeights(weights)
+		}),
+		"discrete": build(FeatureConfig{}, func(n *Network) {
+			space, _ := NewActionSpace(DiscreteActions, 7)
+			n.SetActionSpace(space)
+		}),
+		"recurrent": build(FeatureConfig{}, func(n *Network) {
+			n.EnableRecurrent()
+			weights := n.GetRecurrentWeights()
+			for i := range weights {
+				weights[i] = 0.5 - 0.1*float64(i)
+			}
+			n.SetRecurrentWeights(weights)
+		}),
+	}
+}
+
+// vectorStates is an episode-like sequence, so stacked frames and the
+// recurrent state are exercised too
+func vectorStates() []env.State {
+	var states []env.State
+	for i := 0; i < 24; i++ {
+		x := float64(i)
+		states = append(states, env.State{
+			CartPosition: 0.1*x - 1,
+			CartVelocity: math.Sin(x) * 0.8,
+			AngleRadians: 3.2 - 0.29*x,
+			AngularVel:   2.4 - 0.2*x,
+		})
+	}
+	return states
+}
+
+// recordForward runs the network over the states from a fresh episode
+func recordForward(n *Network) []forwardVector {
+	n.ResetHistory()
+	var vectors []forwardVector
+	for _, state := range vectorStates() {
+		force, hidden := n.ForwardWithActivation(state)
+		vectors = append(vectors, forwardVector{State: state, Force: force, Hidden: hidden})
+	}
+	return vectors
+}
+
+// TestForwardVectors pins Network.Forward bit for bit, so checkpoint
+// replays act the same on every platform
+func TestForwardVectors(t *testing.T) {
+	path := filepath.Join("testdata", "forward_vectors.json")
+	if *updateVectors {
+		recorded := make(map[string][]forwardVector)
+		for name, n := range vectorNetworks() {
+			recorded[name] = recordForward(n)
+		}
+		data, err := json.MarshalIndent(recorded, "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to marshal forward vectors: %v", err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("Failed to write forward vectors: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read forward vectors (run with -update to create them): %v", err)
+	}
+	var golden map[string][]forwardVector
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("Failed to unmarshal forward vectors: %v", err)
+	}
+	for name, n := range vectorNetworks() {
+		want := golden[name]
+		if len(want) == 0 {
+			t.Errorf("%s: no forward vectors", name)
+			continue
+		}
+		for i, got := range recordForward(n) {
+			if math.Float64bits(got.Force) != math.Float64bits(want[i].Force) || math.Float64bits(got.Hidden) != math.Float64bits(want[i].Hidden) {
+				t.Errorf("%s step %d from %+v: force %v, hidden %v, want exactly %v, %v",
+					name, i, got.State, got.Force, got.Hidden, want[i].Force, want[i].Hidden)
+			}
+		}
+	}
+}
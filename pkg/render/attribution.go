@@ -0,0 +1,85 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+const (
+	// Attribution panel below the strip chart, above the ensemble stats
+	attributionX      = stripChartX
+	attributionY      = stripChartY + stripChartHeight + 8
+	attributionWidth  = stripChartWidth
+	attributionHeight = 55
+)
+
+// attributionColors colors each neural.Attribution term, matching the
+// weight history where the term has a weight there
+var attributionColors = map[string]color.Color{
+	"angle":       color.RGBA{255, 100, 100, 255},
+	"angular_vel": color.RGBA{100, 255, 100, 255},
+	"bias":        color.RGBA{100, 100, 255, 255},
+	"features":    color.RGBA{255, 220, 100, 255},
+	"recurrent":   color.RGBA{200, 120, 255, 255},
+}
+
+// drawAttribution draws the terms of the hidden node's last pre-activation
+// as a stacked bar around zero, pushes toward +x to the right and toward -x
+// to the left, with a tick at their sum. A dominant term on the other side
+// of the tick from the force is the one being outvoted
+func (d *Drawer) drawAttribution(screen *ebiten.Image, network *neural.Network) {
+	ebitenutil.DrawRect(screen, float64(attributionX), float64(attributionY),
+		float64(attributionWidth), float64(attributionHeight), color.RGBA{40, 40, 40, 200})
+
+	a := network.LastAttribution()
+	dominant := a.Dominant()
+	text.Draw(screen, fmt.Sprintf("Hidden %+.2f, driven by %s", a.Hidden, inputLabel(dominant.Name)), d.font,
+		attributionX+5, attributionY+15, color.White)
+
+	// Scale so the longer side of the bar fills its half of the panel
+	terms := a.Terms()
+	positive, negative := 0.0, 0.0
+	for _, t := range terms {
+		if t.Value > 0 {
+			positive += t.Value
+		} else {
+			negative -= t.Value
+		}
+	}
+	barX, barWidth := float64(attributionX+10), float64(attributionWidth-20)
+	barY, barHeight := float64(attributionY+25), 12.0
+	mid := barX + barWidth/2
+	scale := barWidth / 2 / math.Max(1, math.Max(positive, negative))
+
+	right, left := mid, mid
+	for _, t := range terms {
+		width := math.Abs(t.Value) * scale
+		if width < 0.5 {
+			continue
+		}
+		if t.Value > 0 {
+			ebitenutil.DrawRect(screen, right, barY, width, barHeight, attributionColors[t.Name])
+			right += width
+		} else {
+			left -= width
+			ebitenutil.DrawRect(screen, left, barY, width, barHeight, attributionColors[t.Name])
+		}
+	}
+	ebitenutil.DrawLine(screen, mid, barY-3, mid, barY+barHeight+3, color.RGBA{150, 150, 150, 255})
+	sum := mid + a.Hidden*scale
+	ebitenutil.DrawLine(screen, sum, barY-4, sum, barY+barHeight+4, color.White)
+
+	// Legend
+	x := attributionX + 10
+	for _, t := range terms {
+		label := inputLabel(t.Name)
+		text.Draw(screen, label, d.font, x, attributionY+attributionHeight-5, attributionColors[t.Name])
+		x += text.BoundString(d.font, label).Dx() + 12
+	}
+}
@@ -0,0 +1,172 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	xdraw "golang.org/x/image/draw"
+)
+
+// Capture modes select which episodes are saved
+const (
+	CaptureNone  = "none"
+	CaptureBest  = "best"  // Episodes that last longer than any captured so far
+	CaptureEvery = "every" // Every Nth episode
+)
+
+// CaptureConfig controls which episodes are captured and at what quality
+type CaptureConfig struct {
+	Mode        string  // CaptureNone, CaptureBest or CaptureEvery
+	EveryN      int     // Episode interval for CaptureEvery
+	FrameStride int     // Keep one of every FrameStride drawn frames
+	Scale       float64 // Output size relative to the screen
+	MaxFrames   int     // Frames past this are dropped to bound memory
+	Dir         string  // Directory the GIFs are written to
+}
+
+// NewDefaultCaptureConfig returns a CaptureConfig with reasonable default values
+func NewDefaultCaptureConfig() CaptureConfig {
+	return CaptureConfig{
+		Mode:        CaptureNone,
+		EveryN:      10,
+		FrameStride: 3,   // 20 fps at 60 TPS
+		Scale:       0.5, // 512x384
+		MaxFrames:   300, // 15 seconds of GIF
+		Dir:         "captures",
+	}
+}
+
+// EpisodeCapture collects drawn frames for the current episode and encodes
+// the episodes selected by its mode to animated GIFs
+type EpisodeCapture struct {
+	config    CaptureConfig
+	frames    []*image.Paletted
+	drawn     int // Frames drawn this episode, including skipped ones
+	bestTicks int // Longest episode saved so far in CaptureBest mode
+	logger    *log.Logger
+	pixels    []byte
+	pending   sync.WaitGroup
+}
+
+// NewEpisodeCapture creates a capture with the given config
+func NewEpisodeCapture(config CaptureConfig, logger *log.Logger) *EpisodeCapture {
+	if logger == nil {
+		logger = log.Default()
+	}
+	config.FrameStride = max(1, config.FrameStride)
+
+	return &EpisodeCapture{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Enabled reports whether any episodes will be captured
+func (c *EpisodeCapture) Enabled() bool {
+	return c.config.Mode != CaptureNone && c.config.Mode != ""
+}
+
+// AddFrame records the screen as the next frame of the current episode.
+// Call it at the end of Draw, once the frame is complete
+func (c *EpisodeCapture) AddFrame(screen *ebiten.Image) {
+	if !c.Enabled() {
+		return
+	}
+	c.drawn++
+	if (c.drawn-1)%c.config.FrameStride != 0 || len(c.frames) >= c.config.MaxFrames {
+		return
+	}
+
+	bounds := screen.Bounds()
+	if len(c.pixels) != 4*bounds.Dx()*bounds.Dy() {
+		c.pixels = make([]byte, 4*bounds.Dx()*bounds.Dy())
+	}
+	screen.ReadPixels(c.pixels)
+	src := &image.RGBA{Pix: c.pixels, Stride: 4 * bounds.Dx(), Rect: image.Rect(0, 0, bounds.Dx(), bounds.Dy())}
+
+	w := int(math.Round(float64(bounds.Dx()) * c.config.Scale))
+	h := int(math.Round(float64(bounds.Dy()) * c.config.Scale))
+	frame := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+	xdraw.NearestNeighbor.Scale(frame, frame.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+	c.frames = append(c.frames, frame)
+}
+
+// Discard drops the frames of the current episode, e.g. when the displayed
+// network changes mid-episode
+func (c *EpisodeCapture) Discard() {
+	c.frames = nil
+	c.drawn = 0
+}
+
+// EndEpisode saves the finished episode if the mode selects it and starts
+// a new one. Encoding runs in the background; call Wait before exiting
+func (c *EpisodeCapture) EndEpisode(episode, ticks int) {
+	frames := c.frames
+	c.Discard()
+	if !c.Enabled() || len(frames) == 0 {
+		return
+	}
+
+	switch c.config.Mode {
+	case CaptureBest:
+		if ticks <= c.bestTicks {
+			return
+		}
+		c.bestTicks = ticks
+	case CaptureEvery:
+		if c.config.EveryN > 0 && episode%c.config.EveryN != 0 {
+			return
+		}
+	}
+
+	path := filepath.Join(c.config.Dir, fmt.Sprintf("episode_%05d_%dticks.gif", episode, ticks))
+	delay := int(math.Round(float64(c.config.FrameStride) * 100 / float64(ebiten.TPS())))
+
+	c.pending.Add(1)
+	go func() {
+		defer c.pending.Done()
+		if err := saveGIF(path, frames, delay); err != nil {
+			c.logger.Printf("[Capture] Failed to save episode %d: %v", episode, err)
+			return
+		}
+		c.logger.Printf("[Capture] Saved episode %d (%d ticks, %d frames) to %s", episode, ticks, len(frames), path)
+	}()
+}
+
+// Wait blocks until all pending GIFs are written
+func (c *EpisodeCapture) Wait() {
+	c.pending.Wait()
+}
+
+// saveGIF encodes frames to an animated GIF with a fixed delay in 1/100s
+func saveGIF(path string, frames []*image.Paletted, delay int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create capture directory: %w", err)
+	}
+
+	anim := &gif.GIF{
+		Image: frames,
+		Delay: make([]int, len(frames)),
+	}
+	for i := range anim.Delay {
+		anim.Delay[i] = delay
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create GIF file: %w", err)
+	}
+	if err := gif.EncodeAll(file, anim); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const (
+	// Difficulty panel centered below the label, above the pendulum
+	difficultyWidth  = 220
+	difficultyHeight = 50
+	difficultyX      = (ScreenWidth - difficultyWidth) / 2
+	difficultyY      = topPanelHeight + 35
+)
+
+var difficultyColor = color.RGBA{255, 160, 60, 255}
+
+// SetDifficulty sets the curriculum difficulty the current episodes were
+// sampled at, in [0, 1]. The panel stays hidden until the first call
+func (d *Drawer) SetDifficulty(difficulty float64) {
+	if !d.hasDifficulty || difficulty != d.difficulty {
+		d.difficultyHistory = append(d.difficultyHistory, difficulty)
+		if len(d.difficultyHistory) > maxHistoryPoints {
+			d.difficultyHistory = d.difficultyHistory[1:]
+		}
+	}
+	d.difficulty = difficulty
+	d.hasDifficulty = true
+}
+
+// drawDifficulty draws the current difficulty as a bar and its recent
+// changes as a line below it
+func (d *Drawer) drawDifficulty(screen *ebiten.Image) {
+	if !d.hasDifficulty {
+		return
+	}
+
+	ebitenutil.DrawRect(screen, difficultyX, difficultyY, difficultyWidth, difficultyHeight, color.RGBA{40, 40, 40, 200})
+	text.Draw(screen, fmt.Sprintf("Difficulty %.2f", d.difficulty), d.font, difficultyX+5, difficultyY+15, color.White)
+	d.drawProgressBar(screen, difficultyX+110, difficultyY+4, difficultyWidth-115, 14, d.difficulty, difficultyColor)
+
+	// Each point is one change of difficulty, the newest on the right
+	if len(d.difficultyHistory) < 2 {
+		return
+	}
+	graphX, graphY := float64(difficultyX+5), float64(difficultyY+24)
+	graphWidth, graphHeight := float64(difficultyWidth-10), float64(difficultyHeight-28)
+	spacing := graphWidth / float64(len(d.difficultyHistory)-1)
+	for i := 1; i < len(d.difficultyHistory); i++ {
+		x1 := graphX + float64(i-1)*spacing
+		y1 := graphY + graphHeight*(1-d.difficultyHistory[i-1])
+		x2 := graphX + float64(i)*spacing
+		y2 := graphY + graphHeight*(1-d.difficultyHistory[i])
+		ebitenutil.DrawLine(screen, x1, y1, x2, y2, difficultyColor)
+	}
+}
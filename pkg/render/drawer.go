@@ -5,6 +5,7 @@ import (
 	"image/color"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/image/font"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/replay"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/training"
 )
 
@@ -72,6 +74,33 @@ type Drawer struct {
 	// Ensemble stats
 	ensembleStats          []map[string]interface{}
 	ensembleStatsPanel     *ebiten.Image
+	
+	// Playback controls
+	paused                 bool
+	speed                  float64
+	maxSpeed               bool
+	simTime                float64 // Simulated seconds so far
+	speedup                float64 // Simulated seconds per wall-clock second
+	label                  string  // Names the configuration shown, e.g. in split-screen comparisons
+	
+	// Recent simulation steps for the strip chart
+	strip                  []stripSample
+	
+	// Phase portrait of the displayed episode
+	phase                  []phasePoint
+	phaseHeatmap           bool          // Show the policy's force behind the trail
+	heatmapImg             *ebiten.Image // Force heatmap, one pixel per policy sample
+	heatmapKey             []float64     // Weights the heatmap was computed for
+	heatmapFrame           int           // Frames since the heatmap was last refreshed
+	boundary               []boundaryPoint // Decision boundary of the heatmap's policy
+	
+	// Force pushed on the cart by hand, 0 when not dragging
+	humanForce             float64
+	
+	// Curriculum difficulty, shown once set
+	difficulty             float64
+	difficultyHistory      []float64 // Recent changes of difficulty
+	hasDifficulty          bool
 }
 
 func NewDrawer(font font.Face) *Drawer {
@@ -93,9 +122,29 @@ func NewDrawer(font font.Face) *Drawer {
 		successRateHistory: make([]float64, 0, maxHistoryPoints),
 		rewardHistory: make([]float64, 0, maxHistoryPoints),
 		episodeDurations: make([]int, 0, maxHistoryPoints),
+		speed: 1,
 	}
 }
 
+// SetPlayback updates the pause state and speed multiplier shown in the info panel
+func (d *Drawer) SetPlayback(paused bool, speed float64, maxSpeed bool) {
+	d.paused = paused
+	d.speed = speed
+	d.maxSpeed = maxSpeed
+}
+
+// SetSimClock updates the simulated time and real-time speedup shown in the info panel
+func (d *Drawer) SetSimClock(simTime, speedup float64) {
+	d.simTime = simTime
+	d.speedup = speedup
+}
+
+// SetLabel names the configuration this drawer shows above the pendulum;
+// empty hides the label
+func (d *Drawer) SetLabel(label string) {
+	d.label = label
+}
+
 func (d *Drawer) UpdateTrainingStats(trainer *training.Trainer) {
 	if trainer == nil {
 		return
@@ -105,7 +154,7 @@ func (d *Drawer) UpdateTrainingStats(trainer *training.Trainer) {
 	stats := trainer.GetTrainingStats()
 	
 	// Update learning rate
-	if lr, ok := stats["learning_rate"].(float64); ok {
+	if lr, ok := stats["learningRate"].(float64); ok {
 		d.learningRate = lr
 	}
 	
@@ -135,39 +184,9 @@ func (d *Drawer) UpdateTrainingStats(trainer *training.Trainer) {
 func (d *Drawer) Draw(screen *ebiten.Image, pendulum *env.Pendulum, network *neural.Network, trainer *training.Trainer, episodes, ticks, maxTicks int, lastHiddenActivation float64) {
 	state := pendulum.GetState()
 	
-	// Draw track
-	trackY := float64(ScreenHeight) * 0.7
-	ebitenutil.DrawLine(screen, 0, trackY, float64(ScreenWidth), trackY, color.White)
-	
-	// Calculate cart position in screen coordinates
-	cartWidth := float64(d.cartImg.Bounds().Dx())
-	cartHeight := float64(d.cartImg.Bounds().Dy())
-	cartX := float64(ScreenWidth)/2 + state.CartPosition*Scale
-	
-	// Draw cart
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(cartX-cartWidth/2, trackY-cartHeight)
-	screen.DrawImage(d.cartImg, op)
-	
-	// Calculate pendulum end point
-	pendulumLength := pendulum.GetConfig().Length * Scale
-	endX := cartX + pendulumLength*math.Sin(state.AngleRadians)
-	endY := trackY - cartHeight/2 + pendulumLength*math.Cos(state.AngleRadians)
-	
-	// Draw pendulum
-	ebitenutil.DrawLine(screen,
-		cartX,
-		trackY-cartHeight/2,
-		endX,
-		endY,
-		color.RGBA{255, 100, 100, 255})
-	
-	// Draw pendulum bob
-	bobWidth := float64(d.bobImg.Bounds().Dx())
-	bobHeight := float64(d.bobImg.Bounds().Dy())
-	op = &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(endX-bobWidth/2, endY-bobHeight/2)
-	screen.DrawImage(d.bobImg, op)
+	// Draw track, cart and pendulum
+	d.drawPendulum(screen, state, pendulum.GetConfig().Length)
+	d.drawHumanForce(screen, state, pendulum.GetConfig().MaxForce)
 	
 	// Draw debug info
 	weights := network.GetWeights()
@@ -203,10 +222,15 @@ func (d *Drawer) Draw(screen *ebiten.Image, pendulum *env.Pendulum, network *neu
 	}
 	
 	// Draw top info panel
-	d.drawTopInfoPanel(screen, episodes, ticks, maxTicks, state)
+	d.drawTopInfoPanel(screen, episodes, ticks, maxTicks, state, pendulum.GetConfig())
+	if d.label != "" {
+		bounds := text.BoundString(d.font, d.label)
+		text.Draw(screen, d.label, d.font, (ScreenWidth-bounds.Dx())/2, topPanelHeight+25, color.RGBA{255, 255, 0, 255})
+	}
+	d.drawDifficulty(screen)
 	
 	// Draw bottom info panel
-	d.drawBottomInfoPanel(screen, weights)
+	d.drawBottomInfoPanel(screen, weights, network.GetActionSpace())
 	
 	// Draw network visualization
 	d.drawNetworkVisualization(screen, state, network, lastHiddenActivation)
@@ -214,11 +238,103 @@ func (d *Drawer) Draw(screen *ebiten.Image, pendulum *env.Pendulum, network *neu
 	// Draw weight history graph
 	d.drawWeightHistoryGraph(screen)
 	
+	// Draw the episode's trajectory in phase space
+	d.drawPhasePortrait(screen, network)
+	
+	// Draw force, angle and reward over the last few seconds
+	d.drawStripChart(screen, network.GetActionSpace().MaxForce)
+	
+	// Draw which inputs added up to the hidden node's last pre-activation
+	d.drawAttribution(screen, network)
+	
 	// Draw ensemble stats
 	d.DrawEnsembleStats(screen)
 }
 
-func (d *Drawer) drawTopInfoPanel(screen *ebiten.Image, episodes, ticks, maxTicks int, state env.State) {
+// drawPendulum draws the track, cart, pendulum rod and bob for a state
+func (d *Drawer) drawPendulum(screen *ebiten.Image, state env.State, length float64) {
+	// Draw track
+	trackY := float64(ScreenHeight) * 0.7
+	ebitenutil.DrawLine(screen, 0, trackY, float64(ScreenWidth), trackY, color.White)
+	
+	// Calculate cart position in screen coordinates
+	cartWidth := float64(d.cartImg.Bounds().Dx())
+	cartHeight := float64(d.cartImg.Bounds().Dy())
+	cartX := float64(ScreenWidth)/2 + state.CartPosition*Scale
+	
+	// Draw cart
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(cartX-cartWidth/2, trackY-cartHeight)
+	screen.DrawImage(d.cartImg, op)
+	
+	// Calculate pendulum end point
+	pendulumLength := length * Scale
+	endX := cartX + pendulumLength*math.Sin(state.AngleRadians)
+	endY := trackY - cartHeight/2 + pendulumLength*math.Cos(state.AngleRadians)
+	
+	// Draw pendulum
+	ebitenutil.DrawLine(screen,
+		cartX,
+		trackY-cartHeight/2,
+		endX,
+		endY,
+		color.RGBA{255, 100, 100, 255})
+	
+	// Draw pendulum bob
+	bobWidth := float64(d.bobImg.Bounds().Dx())
+	bobHeight := float64(d.bobImg.Bounds().Dy())
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(endX-bobWidth/2, endY-bobHeight/2)
+	screen.DrawImage(d.bobImg, op)
+}
+
+// DrawReplay draws a single recorded frame with playback position and controls
+func (d *Drawer) DrawReplay(screen *ebiten.Image, frame replay.Frame, config env.Config, position, total int, paused bool) {
+	// Draw track, cart and pendulum
+	d.drawPendulum(screen, frame.State, config.Length)
+	
+	// Draw panel background
+	ebitenutil.DrawRect(screen, 0, 0, float64(ScreenWidth), float64(topPanelHeight), color.RGBA{40, 40, 40, 200})
+	
+	status := "Playing"
+	if paused {
+		status = "Paused"
+	}
+	if frame.Done {
+		status += " | Episode ended"
+	}
+	
+	// Draw playback progress
+	playbackText := fmt.Sprintf(
+		"Replay Tick: %d/%d | Force: %.2f N | Reward: %.4f | %s",
+		position+1,
+		total,
+		frame.Action,
+		frame.Reward,
+		status)
+	
+	text.Draw(screen, playbackText, d.font, 10, 25, color.White)
+	
+	// Draw state info
+	energy := env.Energy(frame.State, config)
+	stateText := fmt.Sprintf(
+		"Cart Position: %.2f m | Cart Velocity: %.2f m/s | Angle: %.2f rad (%.1f°) | Angular Velocity: %.2f rad/s | Energy: %.2f J (KE %.2f, PE %.2f)",
+		frame.State.CartPosition,
+		frame.State.CartVelocity,
+		frame.State.AngleRadians,
+		frame.State.AngleRadians*180/math.Pi,
+		frame.State.AngularVel,
+		energy.Total,
+		energy.Kinetic,
+		energy.Potential)
+	
+	text.Draw(screen, stateText, d.font, 10, 45, color.White)
+	
+	// Draw controls
+	text.Draw(screen, "Space: pause/resume | Left/Right: step | [ / ]: scrub 50 ticks", d.font, 10, ScreenHeight-20, color.White)
+}
+
+func (d *Drawer) drawTopInfoPanel(screen *ebiten.Image, episodes, ticks, maxTicks int, state env.State, config env.Config) {
 	// Draw panel background
 	ebitenutil.DrawRect(screen, 0, 0, float64(ScreenWidth), float64(topPanelHeight), color.RGBA{40, 40, 40, 200})
 	
@@ -234,18 +350,22 @@ func (d *Drawer) drawTopInfoPanel(screen *ebiten.Image, episodes, ticks, maxTick
 	text.Draw(screen, trainingText, d.font, 10, 25, color.White)
 	
 	// Draw state info
+	energy := env.Energy(state, config)
 	stateText := fmt.Sprintf(
-		"Cart Position: %.2f m | Cart Velocity: %.2f m/s | Angle: %.2f rad (%.1f°) | Angular Velocity: %.2f rad/s",
+		"Cart Position: %.2f m | Cart Velocity: %.2f m/s | Angle: %.2f rad (%.1f°) | Angular Velocity: %.2f rad/s | Energy: %.2f J (KE %.2f, PE %.2f)",
 		state.CartPosition,
 		state.CartVelocity,
 		state.AngleRadians,
 		state.AngleRadians*180/math.Pi,
-		state.AngularVel)
+		state.AngularVel,
+		energy.Total,
+		energy.Kinetic,
+		energy.Potential)
 	
 	text.Draw(screen, stateText, d.font, 10, 45, color.White)
 }
 
-func (d *Drawer) drawBottomInfoPanel(screen *ebiten.Image, weights []float64) {
+func (d *Drawer) drawBottomInfoPanel(screen *ebiten.Image, weights []float64, actionSpace neural.ActionSpace) {
 	// Draw panel background
 	ebitenutil.DrawRect(screen, 0, float64(ScreenHeight-bottomPanelHeight), float64(ScreenWidth), float64(bottomPanelHeight), color.RGBA{40, 40, 40, 200})
 	
@@ -260,11 +380,19 @@ func (d *Drawer) drawBottomInfoPanel(screen *ebiten.Image, weights []float64) {
 	text.Draw(screen, weightsText, d.font, 10, ScreenHeight-bottomPanelHeight+25, color.White)
 	
 	// Draw controls info
-	controlsText := "Controls: Left/Right Arrow = Manual Force | S = Save Network | L = Load Network"
+	status := fmt.Sprintf("%gx", d.speed)
+	switch {
+	case d.paused:
+		status = "Paused"
+	case d.maxSpeed:
+		status = "Max"
+	}
+	controlsText := fmt.Sprintf("Controls: Arrows = Force | S/L = Save/Load | Space = Pause | . = Step | +/- = Speed | M = Max (%s) | R/N/D = Reset/Random/Disturb | H = Force Map | A = Sound", status)
 	text.Draw(screen, controlsText, d.font, 10, ScreenHeight-bottomPanelHeight+45, color.White)
 	
-	// Draw performance info
-	performanceText := fmt.Sprintf("Episode Duration Trend: %d episodes tracked", len(d.episodeDurations))
+	// Draw performance info and the active action space
+	performanceText := fmt.Sprintf("Episode Duration Trend: %d episodes tracked | Action Space: %s | Sim Time: %.0fs (%.1fx real time)",
+		len(d.episodeDurations), actionSpace, d.simTime, d.speedup)
 	text.Draw(screen, performanceText, d.font, 10, ScreenHeight-bottomPanelHeight+65, color.White)
 }
 
@@ -276,40 +404,89 @@ func (d *Drawer) drawNetworkVisualization(screen *ebiten.Image, state env.State,
 		networkPanelX+5, networkPanelY+topPanelHeight+25, color.White)
 
 	// Calculate node positions
-	inputY1 := float64(networkPanelY + topPanelHeight + 10) + float64(networkPanelHeight)/3
-	inputY2 := float64(networkPanelY + topPanelHeight + 10) + 2*float64(networkPanelHeight)/3
 	hiddenY := float64(networkPanelY + topPanelHeight + 10) + float64(networkPanelHeight)/2
 	outputY := float64(networkPanelY + topPanelHeight + 10) + float64(networkPanelHeight)/2
 
-	// Draw connections with weights
+	// One input node per network input, engineered and stacked features
+	// included, showing what the network saw on its last forward pass
+	names := network.InputNames()
+	inputs := network.GetLastInputs()
+	if len(inputs) != len(names) {
+		inputs = make([]float64, len(names))
+		inputs[0], inputs[1] = state.AngleRadians, state.AngularVel
+	}
 	weights := network.GetWeights()
-	d.drawWeightedConnection(screen, inputLayerX, inputY1, hiddenLayerX, hiddenY, weights[0])
-	d.drawWeightedConnection(screen, inputLayerX, inputY2, hiddenLayerX, hiddenY, weights[1])
+	inputWeights := append([]float64{weights[0], weights[1]}, network.GetFeatureWeights()...)
+	spacing := float64(networkPanelHeight) / float64(len(names)+1)
+	radius := math.Min(nodeRadius, spacing/2-1)
+	for i, name := range names {
+		y := float64(networkPanelY + topPanelHeight + 10) + float64(i+1)*spacing
+		d.drawWeightedConnection(screen, inputLayerX, y, hiddenLayerX, hiddenY, inputWeights[i])
+		label := inputLabel(name)
+		if radius == nodeRadius {
+			d.drawNode(screen, inputLayerX, y, label, d.getActivationColor(inputs[i]))
+			text.Draw(screen, fmt.Sprintf("%.2f", inputs[i]), 
+				d.font, int(inputLayerX)+25, int(y), color.White)
+			continue
+		}
+		// Too many inputs for labelled nodes: label them alongside instead
+		ebitenutil.DrawCircle(screen, inputLayerX, y, radius, d.getActivationColor(inputs[i]))
+		if spacing >= 14 {
+			text.Draw(screen, fmt.Sprintf("%s %.2f", label, inputs[i]), 
+				d.font, int(inputLayerX+radius)+4, int(y)+5, color.White)
+		}
+	}
 	d.drawWeightedConnection(screen, hiddenLayerX, hiddenY, outputLayerX, outputY, 1.0)
 
 	// Draw bias connection
 	d.drawBiasConnection(screen, hiddenLayerX, hiddenY, weights[2])
 
+	// Draw the recurrent cell's state below the hidden node it feeds
+	if network.Recurrent() {
+		memory, weight := network.RecurrentHidden()
+		memoryY := hiddenY + 50
+		d.drawWeightedConnection(screen, hiddenLayerX, memoryY, hiddenLayerX, hiddenY, weight)
+		d.drawNode(screen, hiddenLayerX, memoryY, "M", d.getActivationColor(memory))
+		text.Draw(screen, fmt.Sprintf("%.2f", memory), 
+			d.font, int(hiddenLayerX)+25, int(memoryY), color.White)
+	}
+
 	// Draw nodes with activation colors
-	d.drawNode(screen, inputLayerX, inputY1, "θ", d.getActivationColor(state.AngleRadians))
-	d.drawNode(screen, inputLayerX, inputY2, "ω", d.getActivationColor(state.AngularVel))
 	d.drawNode(screen, hiddenLayerX, hiddenY, "H", d.getActivationColor(lastHiddenActivation))
 	
-	// Calculate network output (force)
-	force, _ := network.ForwardWithActivation(state)
+	// Calculate network output (force) without recording a forward pass,
+	// which would add a frame to stacked inputs
+	force := network.Policy(state)
 	d.drawNode(screen, outputLayerX, outputY, "F", d.getActivationColor(force/5.0)) // Normalize force to [-1,1]
 
 	// Draw node values
-	text.Draw(screen, fmt.Sprintf("%.2f", state.AngleRadians), 
-		d.font, int(inputLayerX)+25, int(inputY1), color.White)
-	text.Draw(screen, fmt.Sprintf("%.2f", state.AngularVel), 
-		d.font, int(inputLayerX)+25, int(inputY2), color.White)
 	text.Draw(screen, fmt.Sprintf("%.2f", lastHiddenActivation), 
 		d.font, int(hiddenLayerX)+25, int(hiddenY), color.White)
 	text.Draw(screen, fmt.Sprintf("%.2f", force), 
 		d.font, int(outputLayerX)+25, int(outputY), color.White)
 }
 
+// inputLabel abbreviates a network input name for its node, e.g. θ for
+// angle and θ-1 for the angle one stacked frame back
+func inputLabel(name string) string {
+	base, back, _ := strings.Cut(name, "_prev")
+	label, ok := map[string]string{
+		"angle":         "θ",
+		"angular_vel":   "ω",
+		"sin_angle":     "sinθ",
+		"cos_angle":     "cosθ",
+		"cart_position": "x",
+		"cart_velocity": "v",
+	}[base]
+	if !ok {
+		label = base
+	}
+	if back != "" {
+		label += "-" + back
+	}
+	return label
+}
+
 func (d *Drawer) drawBiasConnection(screen *ebiten.Image, x, y, weight float64) {
 	// Draw bias node
 	biasX := x - 30
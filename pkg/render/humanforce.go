@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// humanForceLength is the arrow length in pixels for the full MaxForce
+const humanForceLength = 100.0
+
+var humanForceColor = color.RGBA{255, 255, 0, 255}
+
+// SetHumanForce sets the force pushed on the cart by hand, drawn as an arrow
+// from the cart; zero hides it
+func (d *Drawer) SetHumanForce(force float64) {
+	d.humanForce = force
+}
+
+// drawHumanForce draws the force pushed by hand as an arrow from the cart,
+// scaled to MaxForce, and its value above the cart
+func (d *Drawer) drawHumanForce(screen *ebiten.Image, state env.State, maxForce float64) {
+	if d.humanForce == 0 || maxForce <= 0 {
+		return
+	}
+
+	trackY := float64(ScreenHeight) * 0.7
+	cartX := float64(ScreenWidth)/2 + state.CartPosition*Scale
+	y := trackY - float64(d.cartImg.Bounds().Dy())/2
+
+	end := cartX + d.humanForce/maxForce*humanForceLength
+	head := 8.0
+	if d.humanForce < 0 {
+		head = -head
+	}
+	ebitenutil.DrawLine(screen, cartX, y, end, y, humanForceColor)
+	ebitenutil.DrawLine(screen, end, y, end-head, y-math.Abs(head)/2, humanForceColor)
+	ebitenutil.DrawLine(screen, end, y, end-head, y+math.Abs(head)/2, humanForceColor)
+
+	label := fmt.Sprintf("Hand: %+.1f N", d.humanForce)
+	bounds := text.BoundString(d.font, label)
+	text.Draw(screen, label, d.font, int(cartX)-bounds.Dx()/2, int(trackY)-40, humanForceColor)
+}
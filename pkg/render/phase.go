@@ -0,0 +1,215 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+const (
+	// Phase portrait panel between the network and weight history panels
+	phasePanelX      = 10
+	phasePanelY      = networkPanelY + topPanelHeight + networkPanelHeight + 20
+	phasePanelWidth  = 250
+	phasePanelHeight = 210
+
+	// Angular velocity in rad/s at the top and bottom of the phase portrait;
+	// the angle spans [-π, π] left to right
+	phaseMaxAngularVel = 8.0
+
+	// Longest trail kept for one episode
+	maxPhasePoints = 3000
+
+	// Policy samples across and down the force heatmap
+	heatmapCols = 48
+	heatmapRows = 32
+
+	// Frames between heatmap refreshes, so training at speed stays smooth
+	heatmapEvery = 6
+)
+
+// phasePoint is one (θ, ω) state of the displayed episode
+type phasePoint struct {
+	angle      float64 // Signed deviation from upright in [-π, π]
+	angularVel float64
+}
+
+// ClearTrajectory forgets the phase portrait's trail, e.g. when a new
+// episode starts
+func (d *Drawer) ClearTrajectory() {
+	d.phase = d.phase[:0]
+}
+
+// TogglePhaseHeatmap shows or hides the policy's force behind the phase portrait
+func (d *Drawer) TogglePhaseHeatmap() {
+	d.phaseHeatmap = !d.phaseHeatmap
+}
+
+// boundaryPoint is where the force changes sign between two neighbouring
+// heatmap samples, as fractions of the panel's width and height
+type boundaryPoint struct {
+	x, y float64
+}
+
+// recordPhase adds a state to the phase portrait's trail
+func (d *Drawer) recordPhase(state env.State) {
+	if len(d.phase) >= maxPhasePoints {
+		d.phase = append(d.phase[:0], d.phase[len(d.phase)-maxPhasePoints/2:]...)
+	}
+	d.phase = append(d.phase, phasePoint{
+		angle:      env.UprightOffset(state.AngleRadians),
+		angularVel: state.AngularVel,
+	})
+}
+
+// drawPhasePortrait plots the episode's trajectory in (θ, ω) with older
+// points fading out, over the network's force when the heatmap is on
+func (d *Drawer) drawPhasePortrait(screen *ebiten.Image, network *neural.Network) {
+	ebitenutil.DrawRect(screen, float64(phasePanelX), float64(phasePanelY),
+		float64(phasePanelWidth), float64(phasePanelHeight), color.RGBA{40, 40, 40, 255})
+	hint := "H: force map"
+	if d.phaseHeatmap {
+		hint = "H: hide force"
+	}
+	text.Draw(screen, "Phase (θ, ω) | "+hint, d.font, phasePanelX+5, phasePanelY+15, color.White)
+
+	x0, y0 := float64(phasePanelX+5), float64(phasePanelY+20)
+	width, height := float64(phasePanelWidth-10), float64(phasePanelHeight-25)
+	if d.phaseHeatmap {
+		d.drawForceHeatmap(screen, network, x0, y0, width, height)
+	}
+
+	// Axes through upright and zero velocity
+	axis := color.RGBA{100, 100, 100, 255}
+	ebitenutil.DrawLine(screen, x0+width/2, y0, x0+width/2, y0+height, axis)
+	ebitenutil.DrawLine(screen, x0, y0+height/2, x0+width, y0+height/2, axis)
+	text.Draw(screen, "-π", d.font, int(x0)+2, int(y0+height/2)-3, axis)
+	text.Draw(screen, "π", d.font, int(x0+width)-12, int(y0+height/2)-3, axis)
+	text.Draw(screen, fmt.Sprintf("%+.0f", phaseMaxAngularVel), d.font, int(x0+width/2)+3, int(y0)+12, axis)
+
+	plot := func(p phasePoint) (float64, float64) {
+		v := math.Max(-phaseMaxAngularVel, math.Min(phaseMaxAngularVel, p.angularVel))
+		return x0 + (p.angle+math.Pi)/(2*math.Pi)*width, y0 + (1-(v+phaseMaxAngularVel)/(2*phaseMaxAngularVel))*height
+	}
+	for i := 1; i < len(d.phase); i++ {
+		// Skip the jump across the panel when the angle wraps past ±π
+		if math.Abs(d.phase[i].angle-d.phase[i-1].angle) > math.Pi {
+			continue
+		}
+		fade := uint8(40 + 215*i/len(d.phase))
+		x1, y1 := plot(d.phase[i-1])
+		x2, y2 := plot(d.phase[i])
+		ebitenutil.DrawLine(screen, x1, y1, x2, y2, color.RGBA{255, 255, 150, fade})
+	}
+	if len(d.phase) > 0 {
+		x, y := plot(d.phase[len(d.phase)-1])
+		ebitenutil.DrawRect(screen, x-2, y-2, 4, 4, color.RGBA{255, 100, 100, 255})
+	}
+}
+
+// drawForceHeatmap fills the phase portrait with the network's force at
+// each (θ, ω), red pushing right and blue pushing left, and traces the
+// decision boundary where the force changes sign. The map is recomputed
+// at most every heatmapEvery frames, and only when the weights change
+func (d *Drawer) drawForceHeatmap(screen *ebiten.Image, network *neural.Network, x0, y0, width, height float64) {
+	d.heatmapFrame++
+	key := append(network.GetWeights(), network.GetFeatureWeights()...)
+	stale := d.heatmapImg == nil || d.heatmapFrame >= heatmapEvery
+	if stale && (d.heatmapImg == nil || !equalWeights(key, d.heatmapKey)) {
+		if d.heatmapImg == nil {
+			d.heatmapImg = ebiten.NewImage(heatmapCols, heatmapRows)
+		}
+		maxForce := math.Max(network.GetActionSpace().MaxForce, 0.1)
+		states := make([]env.State, 0, heatmapCols*heatmapRows)
+		for row := 0; row < heatmapRows; row++ {
+			v := phaseMaxAngularVel * (1 - 2*(float64(row)+0.5)/heatmapRows)
+			for col := 0; col < heatmapCols; col++ {
+				angle := -math.Pi + 2*math.Pi*(float64(col)+0.5)/heatmapCols
+				states = append(states, env.State{AngleRadians: angle, AngularVel: v})
+			}
+		}
+		forces := network.ForwardBatch(states)
+		pix := make([]byte, 4*heatmapCols*heatmapRows)
+		for row := 0; row < heatmapRows; row++ {
+			for col := 0; col < heatmapCols; col++ {
+				force := forces[row*heatmapCols+col]
+				intensity := math.Min(1, math.Abs(force)/maxForce)
+
+				// Premultiplied alpha, as ebiten expects
+				alpha := 160 * intensity
+				i := 4 * (row*heatmapCols + col)
+				if force > 0 {
+					pix[i] = byte(alpha)
+				} else {
+					pix[i+2] = byte(alpha)
+				}
+				pix[i+1] = byte(alpha * 0.2)
+				pix[i+3] = byte(alpha)
+			}
+		}
+		d.heatmapImg.WritePixels(pix)
+		d.heatmapKey = key
+		d.boundary = decisionBoundary(forces, d.boundary[:0])
+	}
+	if stale {
+		d.heatmapFrame = 0
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(width/heatmapCols, height/heatmapRows)
+	op.GeoM.Translate(x0, y0)
+	screen.DrawImage(d.heatmapImg, op)
+
+	for _, p := range d.boundary {
+		ebitenutil.DrawRect(screen, x0+p.x*width-1, y0+p.y*height-1, 2, 2, color.RGBA{255, 255, 255, 200})
+	}
+}
+
+// decisionBoundary finds where the force changes sign between horizontally
+// or vertically neighbouring samples of a heatmapCols × heatmapRows grid,
+// interpolating the zero crossing, and appends the points to dst
+func decisionBoundary(forces []float64, dst []boundaryPoint) []boundaryPoint {
+	at := func(row, col int) float64 { return forces[row*heatmapCols+col] }
+	crossing := func(a, b float64) float64 {
+		if a == b {
+			return 0.5
+		}
+		return a / (a - b)
+	}
+	for row := 0; row < heatmapRows; row++ {
+		for col := 0; col < heatmapCols; col++ {
+			f := at(row, col)
+			cx, cy := (float64(col)+0.5)/heatmapCols, (float64(row)+0.5)/heatmapRows
+			if col+1 < heatmapCols {
+				if right := at(row, col+1); math.Signbit(f) != math.Signbit(right) {
+					dst = append(dst, boundaryPoint{cx + crossing(f, right)/heatmapCols, cy})
+				}
+			}
+			if row+1 < heatmapRows {
+				if below := at(row+1, col); math.Signbit(f) != math.Signbit(below) {
+					dst = append(dst, boundaryPoint{cx, cy + crossing(f, below)/heatmapRows})
+				}
+			}
+		}
+	}
+	return dst
+}
+
+// equalWeights reports whether two weight vectors are identical
+func equalWeights(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+const (
+	// Strip chart panel in the top right corner, above the ensemble stats
+	stripChartX      = ScreenWidth - 310
+	stripChartY      = topPanelHeight + 10
+	stripChartWidth  = 300
+	stripChartHeight = 215
+	stripRowHeight   = 60
+
+	// Simulated seconds of history shown in the strip chart
+	stripChartSpan = 5.0
+)
+
+// stripSample is one simulation step shown in the strip chart
+type stripSample struct {
+	time   float64 // Simulated seconds
+	force  float64
+	angle  float64 // Signed deviation from upright in [-π, π]
+	reward float64
+}
+
+// stripSeries is one row of the strip chart
+type stripSeries struct {
+	label string
+	value func(s stripSample) float64
+	scale float64 // Smallest half-height of the row's range
+	color color.Color
+}
+
+// RecordStep adds a simulation step of the displayed network to the strip
+// chart and phase portrait, dropping steps older than the chart's span.
+// Steps must be recorded in simulated-time order; going back in time clears
+// the chart
+func (d *Drawer) RecordStep(simTime float64, state env.State, force, reward float64) {
+	d.recordPhase(state)
+	if n := len(d.strip); n > 0 && simTime < d.strip[n-1].time {
+		d.strip = d.strip[:0]
+	}
+	d.strip = append(d.strip, stripSample{
+		time:   simTime,
+		force:  force,
+		angle:  env.UprightOffset(state.AngleRadians),
+		reward: reward,
+	})
+
+	// Drop expired samples in bulk so the slice is not shifted every step
+	expired := 0
+	for expired < len(d.strip) && d.strip[expired].time < simTime-stripChartSpan {
+		expired++
+	}
+	if expired > len(d.strip)/2 {
+		d.strip = append(d.strip[:0], d.strip[expired:]...)
+	}
+}
+
+// ClearStripChart forgets the recorded steps, e.g. when a different network
+// is displayed
+func (d *Drawer) ClearStripChart() {
+	d.strip = d.strip[:0]
+}
+
+// drawStripChart plots force, angle and reward over the last few simulated
+// seconds, newest on the right
+func (d *Drawer) drawStripChart(screen *ebiten.Image, maxForce float64) {
+	ebitenutil.DrawRect(screen, float64(stripChartX), float64(stripChartY),
+		float64(stripChartWidth), float64(stripChartHeight), color.RGBA{40, 40, 40, 200})
+	text.Draw(screen, fmt.Sprintf("Last %.0fs", stripChartSpan), d.font,
+		stripChartX+5, stripChartY+15, color.White)
+
+	series := []stripSeries{
+		{"Force", func(s stripSample) float64 { return s.force }, math.Max(maxForce, 0.1), color.RGBA{255, 200, 100, 255}},
+		{"Angle", func(s stripSample) float64 { return s.angle }, 0.1, color.RGBA{255, 100, 100, 255}},
+		{"Reward", func(s stripSample) float64 { return s.reward }, 1, color.RGBA{100, 255, 100, 255}},
+	}
+	for i, s := range series {
+		d.drawStripRow(screen, s, stripChartY+25+i*(stripRowHeight+3))
+	}
+}
+
+// drawStripRow draws one series of the strip chart around a zero line. The
+// row's range grows past its scale to fit the largest value shown
+func (d *Drawer) drawStripRow(screen *ebiten.Image, s stripSeries, y int) {
+	x, width := stripChartX+70, stripChartWidth-80
+	mid := float64(y + stripRowHeight/2)
+	ebitenutil.DrawLine(screen, float64(x), mid, float64(x+width), mid, color.RGBA{100, 100, 100, 255})
+
+	latest := 0.0
+	if len(d.strip) > 0 {
+		latest = s.value(d.strip[len(d.strip)-1])
+	}
+	text.Draw(screen, s.label, d.font, stripChartX+5, y+stripRowHeight/2-2, color.White)
+	text.Draw(screen, fmt.Sprintf("%+.2f", latest), d.font, stripChartX+5, y+stripRowHeight/2+12, s.color)
+	if len(d.strip) < 2 {
+		return
+	}
+
+	end := d.strip[len(d.strip)-1].time
+	scale := s.scale
+	for _, sample := range d.strip {
+		if sample.time >= end-stripChartSpan {
+			scale = math.Max(scale, math.Abs(s.value(sample)))
+		}
+	}
+
+	half := float64(stripRowHeight) / 2
+	plot := func(sample stripSample) (float64, float64) {
+		px := float64(x+width) - (end-sample.time)/stripChartSpan*float64(width)
+		return px, mid - s.value(sample)/scale*half
+	}
+	for i := 1; i < len(d.strip); i++ {
+		if d.strip[i-1].time < end-stripChartSpan {
+			continue
+		}
+		x1, y1 := plot(d.strip[i-1])
+		x2, y2 := plot(d.strip[i])
+		ebitenutil.DrawLine(screen, x1, y1, x2, y2, s.color)
+	}
+}
@@ -0,0 +1,95 @@
+package replay
+
+// Player steps through a recorded episode with pause, single-step and scrub controls
+type Player struct {
+	episode *Episode
+	pos     int
+	paused  bool
+}
+
+// NewPlayer creates a player positioned at the first frame
+func NewPlayer(episode *Episode) *Player {
+	return &Player{episode: episode}
+}
+
+// Episode returns the recording being played
+func (p *Player) Episode() *Episode {
+	return p.episode
+}
+
+// Len returns the number of frames in the recording
+func (p *Player) Len() int {
+	return len(p.episode.Frames)
+}
+
+// Position returns the index of the current frame
+func (p *Player) Position() int {
+	return p.pos
+}
+
+// Current returns the frame at the current position
+func (p *Player) Current() Frame {
+	if len(p.episode.Frames) == 0 {
+		return Frame{}
+	}
+	return p.episode.Frames[p.pos]
+}
+
+// Update advances one frame unless paused or at the end
+func (p *Player) Update() {
+	if !p.paused {
+		p.Next()
+	}
+}
+
+// Next advances one frame, returning false at the end of the recording
+func (p *Player) Next() bool {
+	if p.pos+1 >= len(p.episode.Frames) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// Prev moves back one frame, returning false at the start of the recording
+func (p *Player) Prev() bool {
+	if p.pos == 0 {
+		return false
+	}
+	p.pos--
+	return true
+}
+
+// Seek jumps to the given frame index, clamped to the recording
+func (p *Player) Seek(tick int) {
+	if tick < 0 {
+		tick = 0
+	}
+	if last := len(p.episode.Frames) - 1; tick > last {
+		tick = last
+	}
+	if tick < 0 {
+		tick = 0
+	}
+	p.pos = tick
+}
+
+// Scrub moves by delta frames, clamped to the recording
+func (p *Player) Scrub(delta int) {
+	p.Seek(p.pos + delta)
+}
+
+// TogglePause pauses or resumes playback
+func (p *Player) TogglePause() {
+	p.paused = !p.paused
+}
+
+// Paused reports whether playback is paused
+func (p *Player) Paused() bool {
+	return p.paused
+}
+
+// AtEnd reports whether the current frame is the last one
+func (p *Player) AtEnd() bool {
+	return p.pos >= len(p.episode.Frames)-1
+}
@@ -0,0 +1,150 @@
+// Package replay records full episode trajectories to JSONL files and plays
+// them back frame by frame, so failures can be inspected after the fact
+// without re-running training with logging enabled
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// FormatVersion is written to every recording header
+const FormatVersion = 1
+
+// Header describes a recorded episode and is stored as the first line of the file
+type Header struct {
+	Version  int        `json:"version"`
+	Episode  int        `json:"episode"`
+	Config   env.Config `json:"config"`
+	Recorded time.Time  `json:"recorded"`
+	Label    string     `json:"label,omitempty"` // Free-form description, e.g. network ID
+}
+
+// Frame is a single recorded step: the state observed, the action taken
+// and the reward received for it
+type Frame struct {
+	Tick   int       `json:"tick"`
+	State  env.State `json:"state"`
+	Action float64   `json:"action"`
+	Reward float64   `json:"reward"`
+	Done   bool      `json:"done,omitempty"`
+}
+
+// Episode is a fully loaded recording
+type Episode struct {
+	Header Header
+	Frames []Frame
+}
+
+// Recorder streams frames of a single episode to a JSONL file
+type Recorder struct {
+	file  *os.File
+	buf   *bufio.Writer
+	enc   *json.Encoder
+	ticks int
+}
+
+// NewRecorder creates the recording file and writes its header
+func NewRecorder(path string, header Header) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create replay directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay file: %w", err)
+	}
+
+	header.Version = FormatVersion
+	if header.Recorded.IsZero() {
+		header.Recorded = time.Now()
+	}
+
+	buf := bufio.NewWriter(file)
+	r := &Recorder{
+		file: file,
+		buf:  buf,
+		enc:  json.NewEncoder(buf),
+	}
+
+	if err := r.enc.Encode(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write replay header: %w", err)
+	}
+
+	return r, nil
+}
+
+// Record appends a frame to the recording
+func (r *Recorder) Record(state env.State, action, reward float64, done bool) error {
+	frame := Frame{
+		Tick:   r.ticks,
+		State:  state,
+		Action: action,
+		Reward: reward,
+		Done:   done,
+	}
+	r.ticks++
+
+	if err := r.enc.Encode(frame); err != nil {
+		return fmt.Errorf("failed to write replay frame: %w", err)
+	}
+	return nil
+}
+
+// Ticks returns the number of frames recorded so far
+func (r *Recorder) Ticks() int {
+	return r.ticks
+}
+
+// Close flushes and closes the recording file
+func (r *Recorder) Close() error {
+	if err := r.buf.Flush(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("failed to flush replay file: %w", err)
+	}
+	return r.file.Close()
+}
+
+// Load reads a recording from disk
+func Load(path string) (*Episode, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// Read parses a recording from any reader
+func Read(r io.Reader) (*Episode, error) {
+	dec := json.NewDecoder(r)
+
+	var episode Episode
+	if err := dec.Decode(&episode.Header); err != nil {
+		return nil, fmt.Errorf("failed to read replay header: %w", err)
+	}
+	if episode.Header.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", episode.Header.Version)
+	}
+
+	for {
+		var frame Frame
+		if err := dec.Decode(&frame); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read replay frame %d: %w", len(episode.Frames), err)
+		}
+		episode.Frames = append(episode.Frames, frame)
+	}
+
+	return &episode, nil
+}
@@ -0,0 +1,88 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "episode.jsonl")
+	config := env.NewDefaultConfig()
+
+	recorder, err := NewRecorder(path, Header{Episode: 7, Config: config})
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	// Record a real trajectory so states are exact float64 values
+	pendulum := env.NewPendulum(config, nil)
+	forces := []float64{1.5, -2.25, 3.0, 0}
+	var want []env.State
+	for i, force := range forces {
+		state := pendulum.GetState()
+		want = append(want, state)
+		if _, err := pendulum.Step(force); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+		if err := recorder.Record(state, force, float64(i)*0.1, i == len(forces)-1); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	episode, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if episode.Header.Episode != 7 || episode.Header.Config != config {
+		t.Errorf("header mismatch: %+v", episode.Header)
+	}
+	if len(episode.Frames) != len(forces) {
+		t.Fatalf("loaded %d frames, want %d", len(episode.Frames), len(forces))
+	}
+	for i, frame := range episode.Frames {
+		if frame.Tick != i || frame.State != want[i] || frame.Action != forces[i] {
+			t.Errorf("frame %d mismatch: %+v", i, frame)
+		}
+	}
+	if !episode.Frames[len(forces)-1].Done {
+		t.Error("last frame should be marked done")
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	episode := &Episode{Frames: make([]Frame, 5)}
+	for i := range episode.Frames {
+		episode.Frames[i].Tick = i
+	}
+
+	player := NewPlayer(episode)
+	player.Update()
+	if player.Position() != 1 {
+		t.Errorf("Update advanced to %d, want 1", player.Position())
+	}
+
+	player.TogglePause()
+	player.Update()
+	if player.Position() != 1 {
+		t.Errorf("paused Update moved to %d, want 1", player.Position())
+	}
+
+	player.Scrub(10)
+	if !player.AtEnd() || player.Current().Tick != 4 {
+		t.Errorf("Scrub past end landed on %d, want 4", player.Position())
+	}
+	if player.Next() {
+		t.Error("Next at end should return false")
+	}
+
+	player.Seek(-3)
+	if player.Position() != 0 || player.Prev() {
+		t.Errorf("Seek before start landed on %d, want 0", player.Position())
+	}
+}
@@ -0,0 +1,31 @@
+package reward
+
+// Components splits a reward into the terms it is built from, so their
+// trade-offs can be tracked rather than only their sum. Penalties are
+// positive and subtracted from the angle term
+type Components struct {
+	Angle    float64 // Reward for the pendulum's angle
+	Position float64 // Penalty for the cart's distance from center and the track bounds
+	Effort   float64 // Penalty for the force and force changes, from a Shaping
+}
+
+// Total returns the reward the components add up to, before any clipping
+func (c Components) Total() float64 {
+	return c.Angle - c.Position - c.Effort
+}
+
+// Add accumulates another step's components, e.g. into episode sums
+func (c *Components) Add(step Components) {
+	c.Angle += step.Angle
+	c.Position += step.Position
+	c.Effort += step.Effort
+}
+
+// Components returns the breakdown's terms; the position penalty includes
+// the bounds penalty, and Total is not clipped
+func (b RewardBreakdown) Components() Components {
+	return Components{
+		Angle:    b.Improvement,
+		Position: b.PositionPenalty + b.BoundsPenalty,
+	}
+}
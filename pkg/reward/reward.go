@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/zachbeta/go_inverted_pendulum/pkg/detmath"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 )
 
@@ -17,48 +18,56 @@ func NewRewardCalculator() *RewardCalculator {
 	return &RewardCalculator{}
 }
 
+// RewardBreakdown holds the components of a state transition reward
+type RewardBreakdown struct {
+	PrevAngleReward float64 // Angle-based reward of the previous state
+	NewAngleReward  float64 // Angle-based reward of the new state
+	Improvement     float64 // Change in angle-based reward (can be negative)
+	PositionPenalty float64 // Penalty for the cart's distance from center
+	BoundsPenalty   float64 // Penalty for getting close to the track bounds
+	Total           float64 // Combined reward clipped to [-1, 1]
+}
+
+// String formats the breakdown for callers that want to log it
+func (b RewardBreakdown) String() string {
+	return fmt.Sprintf("improvement=%.4f (%.4f → %.4f), positionPenalty=%.4f, boundsPenalty=%.4f, total=%.4f",
+		b.Improvement, b.PrevAngleReward, b.NewAngleReward, b.PositionPenalty, b.BoundsPenalty, b.Total)
+}
+
 // Calculate computes a reward based on the change in pendulum state
 // Returns a value in [-1, 1] where:
 // 1.0 = perfect improvement (moving towards upright)
 // 0.0 = no change
 // -1.0 = worst deterioration (moving towards hanging)
 func Calculate(prevState, newState env.State) float64 {
-	fmt.Printf("\n[Reward] Previous state: angle=%.4f rad (%.1f°), pos=%.4f m\n",
-		prevState.AngleRadians,
-		prevState.AngleRadians * 180 / math.Pi,
-		prevState.CartPosition)
-	fmt.Printf("[Reward] New state: angle=%.4f rad (%.1f°), pos=%.4f m\n",
-		newState.AngleRadians,
-		newState.AngleRadians * 180 / math.Pi,
-		newState.CartPosition)
+	return CalculateBreakdown(prevState, newState).Total
+}
 
+// CalculateBreakdown computes the same reward as Calculate and returns
+// each of its components. It has no side effects, so callers decide
+// whether to log the result, e.g. through metrics.Logger.LogRewardBreakdown
+func CalculateBreakdown(prevState, newState env.State) RewardBreakdown {
 	// Get angle-based rewards for both states
 	calc := NewRewardCalculator()
-	prevReward := calc.Calculate(prevState)
-	newReward := calc.Calculate(newState)
-
-	fmt.Printf("[Reward] Angle-based rewards: prev=%.4f, new=%.4f\n", prevReward, newReward)
+	b := RewardBreakdown{
+		PrevAngleReward: calc.Calculate(prevState),
+		NewAngleReward:  calc.Calculate(newState),
+	}
 
 	// Calculate improvement (can be negative)
-	improvement := newReward - prevReward
+	b.Improvement = b.NewAngleReward - b.PrevAngleReward
 
 	// Add small penalty for cart position to keep it centered
-	positionPenalty := math.Abs(newState.CartPosition) * 0.1
+	b.PositionPenalty = float64(math.Abs(newState.CartPosition) * 0.1)
 
 	// Add larger penalty if near track bounds
-	boundsPenalty := 0.0
 	if math.Abs(newState.CartPosition) > 1.5 {
-		boundsPenalty = 0.5 // Strong penalty when getting close to bounds
+		b.BoundsPenalty = 0.5 // Strong penalty when getting close to bounds
 	}
 
-	fmt.Printf("[Reward] Improvement=%.4f, PositionPenalty=%.4f, BoundsPenalty=%.4f\n",
-		improvement, positionPenalty, boundsPenalty)
-
 	// Combine rewards, ensuring output is in [-1, 1]
-	finalReward := clip(improvement - positionPenalty - boundsPenalty, -1.0, 1.0)
-	fmt.Printf("[Reward] Final reward: %.4f\n", finalReward)
-
-	return finalReward
+	b.Total = clip(b.Improvement-b.PositionPenalty-b.BoundsPenalty, -1.0, 1.0)
+	return b
 }
 
 // Calculate computes a simple reward based solely on pendulum angle
@@ -67,30 +76,14 @@ func Calculate(prevState, newState env.State) float64 {
 // 0.0 = horizontal (±π/2 radians)
 // -1.0 = hanging down (±π radians)
 func (r *RewardCalculator) Calculate(state env.State) float64 {
-	// Normalize angle to [-π, π] for reward calculation
-	angle := normalizeAngle(state.AngleRadians)
+	// Signed angle from upright in [-π, π]
+	angle := env.UprightOffset(state.AngleRadians)
 	
 	// Use cosine function to map angle to reward:
 	// cos(0) = 1.0 (upright)
 	// cos(±π/2) = 0.0 (horizontal)
 	// cos(±π) = -1.0 (hanging)
-	return math.Cos(angle)
-}
-
-// normalizeAngle converts any angle to [-π, π] range
-func normalizeAngle(angle float64) float64 {
-	// First normalize to [0, 2π)
-	angle = math.Mod(angle, 2*math.Pi)
-	if angle < 0 {
-		angle += 2 * math.Pi
-	}
-	
-	// Then convert to [-π, π]
-	if angle > math.Pi {
-		angle -= 2 * math.Pi
-	}
-	
-	return angle
+	return detmath.Cos(angle)
 }
 
 // clip limits a value to [min, max] range
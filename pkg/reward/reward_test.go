@@ -1,7 +1,9 @@
 package reward
 
 import (
+	"io"
 	"math"
+	"os"
 	"testing"
 
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
@@ -59,6 +61,62 @@ func TestBasicReward(t *testing.T) {
 	}
 }
 
+func TestCalculateBreakdown(t *testing.T) {
+	prev := env.State{AngleRadians: 0.4, CartPosition: 0.5}
+	next := env.State{AngleRadians: 0.2, CartPosition: 1.6}
+
+	b := CalculateBreakdown(prev, next)
+	if math.Abs(b.Improvement-(math.Cos(0.2)-math.Cos(0.4))) > 1e-9 {
+		t.Errorf("improvement = %.6f, want %.6f", b.Improvement, math.Cos(0.2)-math.Cos(0.4))
+	}
+	if math.Abs(b.PositionPenalty-0.16) > 1e-9 {
+		t.Errorf("position penalty = %.6f, want 0.16", b.PositionPenalty)
+	}
+	if b.BoundsPenalty != 0.5 {
+		t.Errorf("bounds penalty = %.2f, want 0.5 near the track bounds", b.BoundsPenalty)
+	}
+	if want := b.Improvement - b.PositionPenalty - b.BoundsPenalty; math.Abs(b.Total-want) > 1e-9 {
+		t.Errorf("total = %.6f, want %.6f", b.Total, want)
+	}
+	if got := Calculate(prev, next); got != b.Total {
+		t.Errorf("Calculate = %.6f, want breakdown total %.6f", got, b.Total)
+	}
+
+	// The components add up to the total before clipping
+	c := b.Components()
+	if c.Angle != b.Improvement || c.Position != b.PositionPenalty+b.BoundsPenalty || math.Abs(c.Total()-b.Total) > 1e-9 {
+		t.Errorf("components %+v do not add up to %+v", c, b)
+	}
+	var episode Components
+	episode.Add(c)
+	episode.Add(Components{Angle: 1, Effort: 0.25})
+	if want := c.Total() + 0.75; math.Abs(episode.Total()-want) > 1e-9 || episode.Effort != 0.25 {
+		t.Errorf("episode components %+v, want total %.6f", episode, want)
+	}
+
+	// Large deteriorations are clipped
+	if b := CalculateBreakdown(env.State{}, env.State{AngleRadians: math.Pi, CartPosition: 1.9}); b.Total != -1.0 {
+		t.Errorf("total = %.4f, want clipped to -1", b.Total)
+	}
+}
+
+func TestCalculateIsSilent(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	Calculate(env.State{AngleRadians: 0.4}, env.State{AngleRadians: 0.2})
+	os.Stdout = stdout
+	w.Close()
+
+	output, _ := io.ReadAll(r)
+	if len(output) > 0 {
+		t.Errorf("Calculate wrote to stdout: %q", output)
+	}
+}
+
 func BenchmarkBasicReward(b *testing.B) {
 	calculator := NewRewardCalculator()
 	state := env.State{
@@ -71,3 +129,42 @@ func BenchmarkBasicReward(b *testing.B) {
 		}
 	})
 }
+
+func TestShaping(t *testing.T) {
+	shaping, err := ParseShaping("force=0.1, jerk=0.5")
+	if err != nil {
+		t.Fatalf("ParseShaping failed: %v", err)
+	}
+	if got := shaping.String(); got != "force=0.1,jerk=0.5" {
+		t.Errorf("String = %q, want force=0.1,jerk=0.5", got)
+	}
+	for _, spec := range []string{"force", "force=x", "torque=1", "jerk=-1"} {
+		if _, err := ParseShaping(spec); err == nil {
+			t.Errorf("ParseShaping(%q) succeeded, want error", spec)
+		}
+	}
+
+	shaper, err := NewShaper(shaping, 5)
+	if err != nil {
+		t.Fatalf("NewShaper failed: %v", err)
+	}
+	// The first step has no jerk; switching from +5N to -5N is the largest
+	if got, want := shaper.Penalty(5), 0.1; math.Abs(got-want) > 1e-12 {
+		t.Errorf("first step penalty = %v, want %v", got, want)
+	}
+	if got, want := shaper.Penalty(-5), 0.1+0.5; math.Abs(got-want) > 1e-12 {
+		t.Errorf("reversal penalty = %v, want %v", got, want)
+	}
+	if got, want := shaper.Penalty(-2.5), 0.1*0.25+0.5*0.0625; math.Abs(got-want) > 1e-12 {
+		t.Errorf("penalty = %v, want %v", got, want)
+	}
+	shaper.Reset()
+	if got := shaper.Penalty(0); got != 0 {
+		t.Errorf("penalty after reset = %v, want 0 for no force and no change", got)
+	}
+
+	none, err := NewShaper(Shaping{}, 5)
+	if err != nil || none.Penalty(5) != 0 {
+		t.Errorf("empty shaping penalized a step (%v)", err)
+	}
+}
@@ -0,0 +1,143 @@
+package reward
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Term names accepted in a Shaping
+const (
+	ForceTerm = "force" // Penalizes (force/MaxForce)², favoring efficient control
+	JerkTerm  = "jerk"  // Penalizes ((force-previous)/(2·MaxForce))², favoring smooth control
+)
+
+// term computes an optional penalty in [0, 1] for a step's force, given the
+// previous step's force and the largest force the controller may apply
+type term func(force, prevForce, maxForce float64) float64
+
+// terms registers every optional reward term by name
+var terms = map[string]term{
+	ForceTerm: func(force, _, maxForce float64) float64 {
+		f := force / maxForce
+		return f * f
+	},
+	JerkTerm: func(force, prevForce, maxForce float64) float64 {
+		d := (force - prevForce) / (2 * maxForce)
+		return d * d
+	},
+}
+
+// TermNames returns the names of all optional reward terms in sorted order
+func TermNames() []string {
+	names := make([]string, 0, len(terms))
+	for name := range terms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTerm reports whether name is a registered reward term
+func IsTerm(name string) bool {
+	_, ok := terms[name]
+	return ok
+}
+
+// Shaping weighs optional reward terms by name. Each step's reward is
+// reduced by the weighted sum of the terms' penalties; an empty Shaping
+// leaves rewards unchanged
+type Shaping map[string]float64
+
+// ParseShaping reads weights written as comma-separated term=weight pairs,
+// e.g. "force=0.1,jerk=0.05". An empty string gives no shaping
+func ParseShaping(spec string) (Shaping, error) {
+	shaping := Shaping{}
+	if strings.TrimSpace(spec) == "" {
+		return shaping, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("reward term %q is not term=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for reward term %s: %w", name, err)
+		}
+		shaping[name] = weight
+	}
+	return shaping, shaping.Validate()
+}
+
+// Validate checks that every term is registered and no weight is negative
+func (s Shaping) Validate() error {
+	for name, weight := range s {
+		if !IsTerm(name) {
+			return fmt.Errorf("unknown reward term %q (want %s)", name, strings.Join(TermNames(), ", "))
+		}
+		if weight < 0 {
+			return fmt.Errorf("reward term %s weight must not be negative, got %g", name, weight)
+		}
+	}
+	return nil
+}
+
+// String formats the weights as ParseShaping reads them, in term order
+func (s Shaping) String() string {
+	var pairs []string
+	for _, name := range TermNames() {
+		if weight, ok := s[name]; ok {
+			pairs = append(pairs, fmt.Sprintf("%s=%g", name, weight))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Shaper applies a Shaping to the steps of one controller's episodes,
+// remembering the previous force for terms that compare consecutive steps
+type Shaper struct {
+	shaping   Shaping
+	names     []string // Terms of shaping in sorted order, so penalties sum in the same order every step
+	maxForce  float64
+	prevForce float64
+	started   bool // A step of the current episode has been shaped
+}
+
+// NewShaper returns a Shaper for forces in [-maxForce, maxForce]
+func NewShaper(shaping Shaping, maxForce float64) (*Shaper, error) {
+	if err := shaping.Validate(); err != nil {
+		return nil, err
+	}
+	if maxForce <= 0 {
+		return nil, fmt.Errorf("max force must be positive, got %g", maxForce)
+	}
+	names := make([]string, 0, len(shaping))
+	for name := range shaping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &Shaper{shaping: shaping, names: names, maxForce: maxForce}, nil
+}
+
+// Penalty returns the weighted penalty of the terms for the step's force,
+// to subtract from the step's reward. The first step of an episode has no
+// previous force, so it is compared with itself
+func (s *Shaper) Penalty(force float64) float64 {
+	if !s.started {
+		s.prevForce = force
+		s.started = true
+	}
+	total := 0.0
+	for _, name := range s.names {
+		total += float64(s.shaping[name] * terms[name](force, s.prevForce, s.maxForce))
+	}
+	s.prevForce = force
+	return total
+}
+
+// Reset starts a new episode
+func (s *Shaper) Reset() {
+	s.started = false
+}
@@ -0,0 +1,72 @@
+package reward
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+var updateVectors = flag.Bool("update", false, "rewrite test vector fixtures in testdata")
+
+// rewardVector is one canonical transition and its exact reward
+type rewardVector struct {
+	Prev   env.State `json:"prev"`
+	Next   env.State `json:"next"`
+	Reward float64   `json:"reward"`
+}
+
+// rewardVectors covers transitions around the circle, toward and away from
+// upright, with the cart centered, off center and near the bounds
+func rewardVectors() []rewardVector {
+	var vectors []rewardVector
+	angles := []float64{0, 0.1, -0.7, math.Pi / 2, 2.9, math.Pi, -3.0, 6.5}
+	for i, prev := range angles {
+		for j, next := range angles {
+			vectors = append(vectors, rewardVector{
+				Prev: env.State{AngleRadians: prev},
+				Next: env.State{AngleRadians: next, CartPosition: 0.45 * float64(i-j)},
+			})
+		}
+	}
+	for i := range vectors {
+		vectors[i].Reward = Calculate(vectors[i].Prev, vectors[i].Next)
+	}
+	return vectors
+}
+
+// TestRewardVectors pins Calculate bit for bit, so replays of recorded
+// episodes score the same on every platform
+func TestRewardVectors(t *testing.T) {
+	path := filepath.Join("testdata", "reward_vectors.json")
+	if *updateVectors {
+		data, err := json.MarshalIndent(rewardVectors(), "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to marshal reward vectors: %v", err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("Failed to write reward vectors: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read reward vectors (run with -update to create them): %v", err)
+	}
+	var golden []rewardVector
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("Failed to unmarshal reward vectors: %v", err)
+	}
+	if len(golden) == 0 {
+		t.Fatal("No reward vectors")
+	}
+	for _, want := range golden {
+		if got := Calculate(want.Prev, want.Next); math.Float64bits(got) != math.Float64bits(want.Reward) {
+			t.Errorf("Calculate(%+v, %+v) = %v, want exactly %v", want.Prev, want.Next, got, want.Reward)
+		}
+	}
+}
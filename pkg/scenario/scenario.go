@@ -0,0 +1,259 @@
+// Package scenario reads scenario files: an initial state, disturbances
+// scripted at fixed times and the outcome expected of a controller, in JSON
+// or YAML. A file describes a reproducible challenge, e.g. "survive a gust
+// at t=3s", that the window demo can play, the eval harness can score and
+// tests can assert on
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+)
+
+// Formats accepted by Parse
+const (
+	JSONFormat = "json"
+	YAMLFormat = "yaml"
+)
+
+// DefaultSuccessAngle is how far from upright, in degrees, the pendulum may
+// lean and still count as balanced when a file sets no expect.max_angle
+const DefaultSuccessAngle = 45.0
+
+// File is a scenario file. Angles are in degrees, times in seconds
+type File struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Preset      string  `json:"preset,omitempty"` // Physics preset, env.PezzzaPreset when empty
+	Duration    float64 `json:"duration"`         // Seconds the episode runs
+	Initial     Initial `json:"initial"`
+	Events      []Event `json:"events,omitempty"`
+	Expect      Expect  `json:"expect"`
+}
+
+// Initial is the state the episode starts in
+type Initial struct {
+	Angle        float64 `json:"angle"`            // Degrees from upright, positive to the right
+	AngularVel   float64 `json:"angular_velocity"` // Degrees per second
+	CartPosition float64 `json:"cart_position"`    // Meters from the track's center
+	CartVelocity float64 `json:"cart_velocity"`    // Meters per second
+}
+
+// Event is a disturbance at a fixed time: a force on the cart held for
+// Duration, an impulse on the bob, or both
+type Event struct {
+	At       float64 `json:"at"`                 // Seconds into the episode
+	Force    float64 `json:"force,omitempty"`    // N on the cart, positive to the right
+	Duration float64 `json:"duration,omitempty"` // Seconds Force lasts, one step when 0
+	Impulse  float64 `json:"impulse,omitempty"`  // N·s on the bob, positive to the right
+}
+
+// Expect is the outcome a controller must achieve to pass; unset fields
+// are not checked
+type Expect struct {
+	Survive        bool    `json:"survive,omitempty"`          // Run the full duration without the episode ending
+	MaxAngle       float64 `json:"max_angle,omitempty"`        // Never lean further from upright, in degrees
+	MinBalanceTime float64 `json:"min_balance_time,omitempty"` // Seconds within the success angle
+}
+
+// Load reads a scenario file, JSON or YAML by its extension
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var format string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		format = JSONFormat
+	case ".yaml", ".yml":
+		format = YAMLFormat
+	default:
+		return File{}, fmt.Errorf("unsupported scenario format %q (want .json, .yaml or .yml)", ext)
+	}
+	f, err := Parse(data, format)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return f, nil
+}
+
+// Parse decodes and validates a scenario in JSONFormat or YAMLFormat.
+// Unknown keys are errors, so a misspelled expectation is not silently
+// left unchecked
+func Parse(data []byte, format string) (File, error) {
+	switch format {
+	case JSONFormat:
+	case YAMLFormat:
+		var err error
+		if data, err = yamlToJSON(data); err != nil {
+			return File{}, err
+		}
+	default:
+		return File{}, fmt.Errorf("unknown scenario format %q", format)
+	}
+
+	var f File
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return File{}, err
+	}
+	if err := f.Validate(); err != nil {
+		return File{}, err
+	}
+	return f, nil
+}
+
+// Validate checks the preset, duration and event times
+func (f File) Validate() error {
+	if _, err := f.Config(); err != nil {
+		return err
+	}
+	if f.Duration <= 0 {
+		return fmt.Errorf("duration must be positive, got %v", f.Duration)
+	}
+	for i, event := range f.Events {
+		if event.At < 0 || event.Duration < 0 {
+			return fmt.Errorf("event %d: at and duration must not be negative", i)
+		}
+		if event.Force == 0 && event.Impulse == 0 {
+			return fmt.Errorf("event %d has neither a force nor an impulse", i)
+		}
+	}
+	if f.Expect.MaxAngle < 0 || f.Expect.MinBalanceTime < 0 {
+		return fmt.Errorf("expectations must not be negative")
+	}
+	return nil
+}
+
+// Config returns the physics the scenario runs on
+func (f File) Config() (env.Config, error) {
+	preset := f.Preset
+	if preset == "" {
+		preset = env.PezzzaPreset
+	}
+	return env.Preset(preset)
+}
+
+// State returns the initial state in the simulation's units
+func (f File) State() env.State {
+	return env.State{
+		AngleRadians: env.UprightAngle + radians(f.Initial.Angle),
+		AngularVel:   radians(f.Initial.AngularVel),
+		CartPosition: f.Initial.CartPosition,
+		CartVelocity: f.Initial.CartVelocity,
+	}
+}
+
+// Steps returns the duration in control steps at the physics DeltaTime
+func (f File) Steps(deltaTime float64) int {
+	return ticks(f.Duration, deltaTime)
+}
+
+// Schedule returns the events as control steps at the physics DeltaTime
+func (f File) Schedule(deltaTime float64) env.Schedule {
+	schedule := make(env.Schedule, len(f.Events))
+	for i, event := range f.Events {
+		schedule[i] = env.Event{
+			Tick:    ticks(event.At, deltaTime),
+			Ticks:   ticks(event.Duration, deltaTime),
+			Force:   event.Force,
+			Impulse: event.Impulse,
+		}
+	}
+	return schedule
+}
+
+// SuccessAngle returns the lean in radians within which the pendulum counts
+// as balanced: expect.max_angle, or DefaultSuccessAngle
+func (f File) SuccessAngle() float64 {
+	if f.Expect.MaxAngle > 0 {
+		return radians(f.Expect.MaxAngle)
+	}
+	return radians(DefaultSuccessAngle)
+}
+
+// Scenario returns the file as an evaluation scenario
+func (f File) Scenario() (eval.Scenario, error) {
+	config, err := f.Config()
+	if err != nil {
+		return eval.Scenario{}, err
+	}
+	return eval.Scenario{
+		Name:    f.Name,
+		Config:  config,
+		Initial: f.State(),
+		Steps:   f.Steps(config.DeltaTime),
+		Events:  f.Schedule(config.DeltaTime),
+	}, nil
+}
+
+// Check returns the expectations result fails, empty when it passes
+func (f File) Check(result eval.EpisodeResult) []string {
+	var failures []string
+	if f.Expect.Survive && result.OutOfBounds {
+		failures = append(failures, fmt.Sprintf("ended after %d steps", result.Steps))
+	}
+	if f.Expect.MaxAngle > 0 && result.MaxAngle > radians(f.Expect.MaxAngle) {
+		failures = append(failures, fmt.Sprintf("leaned %.1f°, over %.1f°", result.MaxAngle*180/math.Pi, f.Expect.MaxAngle))
+	}
+	if f.Expect.MinBalanceTime > 0 && result.BalanceTime < f.Expect.MinBalanceTime {
+		failures = append(failures, fmt.Sprintf("balanced %.2fs, under %.2fs", result.BalanceTime, f.Expect.MinBalanceTime))
+	}
+	return failures
+}
+
+// Run plays the scenario with controller and checks the outcome
+func (f File) Run(controller eval.Controller) (eval.EpisodeResult, []string, error) {
+	scenario, err := f.Scenario()
+	if err != nil {
+		return eval.EpisodeResult{}, nil, err
+	}
+	result := eval.RunScenario(scenario, controller, f.SuccessAngle())
+	return result, f.Check(result), nil
+}
+
+// LoadSuite loads scenario files as one evaluation suite. The suite's
+// success angle is the strictest of the files'
+func LoadSuite(name string, paths []string) (eval.Suite, []File, error) {
+	suite := eval.Suite{Name: name}
+	files := make([]File, 0, len(paths))
+	for _, path := range paths {
+		f, err := Load(path)
+		if err != nil {
+			return eval.Suite{}, nil, err
+		}
+		scenario, err := f.Scenario()
+		if err != nil {
+			return eval.Suite{}, nil, err
+		}
+		if suite.SuccessAngle == 0 || f.SuccessAngle() < suite.SuccessAngle {
+			suite.SuccessAngle = f.SuccessAngle()
+		}
+		suite.Scenarios = append(suite.Scenarios, scenario)
+		files = append(files, f)
+	}
+	return suite, files, nil
+}
+
+// degrees converts degrees to radians
+func radians(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+// ticks converts seconds to whole control steps, rounding to the nearest
+func ticks(seconds, deltaTime float64) int {
+	return int(math.Round(seconds / deltaTime))
+}
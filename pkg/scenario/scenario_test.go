@@ -0,0 +1,183 @@
+package scenario
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/control"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+)
+
+const gustYAML = `
+# A gust at t=3s
+name: gust
+duration: 10
+initial:
+  angle: 5
+events:
+  - at: 3
+    force: 4       # N
+    duration: 1.5
+  - {"at": 6, "impulse": -0.1}
+expect:
+  survive: true
+  max_angle: 30
+`
+
+const gustJSON = `{
+  "name": "gust",
+  "duration": 10,
+  "initial": {"angle": 5},
+  "events": [
+    {"at": 3, "force": 4, "duration": 1.5},
+    {"at": 6, "impulse": -0.1}
+  ],
+  "expect": {"survive": true, "max_angle": 30}
+}`
+
+func TestParse(t *testing.T) {
+	fromYAML, err := Parse([]byte(gustYAML), YAMLFormat)
+	if err != nil {
+		t.Fatalf("Parse YAML failed: %v", err)
+	}
+	fromJSON, err := Parse([]byte(gustJSON), JSONFormat)
+	if err != nil {
+		t.Fatalf("Parse JSON failed: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("YAML and JSON differ:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+
+	want := File{
+		Name:     "gust",
+		Duration: 10,
+		Initial:  Initial{Angle: 5},
+		Events:   []Event{{At: 3, Force: 4, Duration: 1.5}, {At: 6, Impulse: -0.1}},
+		Expect:   Expect{Survive: true, MaxAngle: 30},
+	}
+	if !reflect.DeepEqual(fromYAML, want) {
+		t.Errorf("parsed %+v, want %+v", fromYAML, want)
+	}
+
+	invalid := map[string]string{
+		"unknown key":     "duration: 5\nexpect:\n  survives: true\n",
+		"no duration":     "name: x\n",
+		"empty event":     "duration: 5\nevents:\n  - at: 1\n",
+		"negative time":   "duration: 5\nevents:\n  - at: -1\n    force: 2\n",
+		"unknown preset":  "duration: 5\npreset: moon\n",
+		"bad indentation": "duration: 5\n   initial: 3\n",
+		"list in a map":   "initial:\n  angle: 5\n  - 3\n",
+	}
+	for name, data := range invalid {
+		if _, err := Parse([]byte(data), YAMLFormat); err == nil {
+			t.Errorf("%s: Parse succeeded, want an error", name)
+		}
+	}
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	data := `
+text: 'it''s # not a comment'
+quoted: "tab\there"
+plain: hello world
+list:
+- 1
+- true
+- ~
+nested:
+  - - a
+    - b
+  -
+    key: value
+empty:
+`
+	got, err := yamlToJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("yamlToJSON failed: %v", err)
+	}
+	want := `{"empty":null,"list":[1,true,null],"nested":[["a","b"],{"key":"value"}],"plain":"hello world","quoted":"tab\there","text":"it's # not a comment"}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestScenario(t *testing.T) {
+	f, err := Parse([]byte(gustYAML), YAMLFormat)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	scenario, err := f.Scenario()
+	if err != nil {
+		t.Fatalf("Scenario failed: %v", err)
+	}
+	config, _ := env.Preset(env.PezzzaPreset)
+	steps := int(math.Round(10 / config.DeltaTime))
+	if scenario.Steps != steps || scenario.Config.DeltaTime != config.DeltaTime {
+		t.Errorf("scenario runs %d steps of %vs, want %d of the default preset's %vs",
+			scenario.Steps, scenario.Config.DeltaTime, steps, config.DeltaTime)
+	}
+	if got := env.UprightOffset(scenario.Initial.AngleRadians); math.Abs(got-5*math.Pi/180) > 1e-12 {
+		t.Errorf("initial lean = %v rad, want 5°", got)
+	}
+	gust := scenario.Events[0]
+	if gust.Tick != int(math.Round(3/config.DeltaTime)) || gust.Ticks != int(math.Round(1.5/config.DeltaTime)) || gust.Force != 4 {
+		t.Errorf("gust = %+v, want 4N from 3s for 1.5s", gust)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	f := File{Expect: Expect{Survive: true, MaxAngle: 30, MinBalanceTime: 5}}
+	pass := eval.EpisodeResult{Steps: 500, MaxAngle: 0.2, BalanceTime: 6}
+	if failures := f.Check(pass); len(failures) != 0 {
+		t.Errorf("Check(%+v) = %v, want a pass", pass, failures)
+	}
+	fail := eval.EpisodeResult{Steps: 120, MaxAngle: 1, BalanceTime: 2, OutOfBounds: true}
+	if failures := f.Check(fail); len(failures) != 3 {
+		t.Errorf("Check(%+v) = %v, want all three expectations failed", fail, failures)
+	}
+	if failures := (File{}).Check(fail); len(failures) != 0 {
+		t.Errorf("Check without expectations = %v, want a pass", failures)
+	}
+}
+
+// TestScenarios runs the shared scenario files: the planner must pass each
+// challenge and doing nothing must not
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "scenarios", "*"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no scenario files found: %v", err)
+	}
+	idle := agent.ControllerFunc(func(env.State) float64 { return 0 })
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			f, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			config, _ := f.Config()
+			planner, err := control.NewPlanner(config, control.NewDefaultPlannerConfig())
+			if err != nil {
+				t.Fatalf("NewPlanner failed: %v", err)
+			}
+			if _, failures, err := f.Run(planner); err != nil || len(failures) != 0 {
+				t.Errorf("planner failed %s: %s %v", f.Name, strings.Join(failures, "; "), err)
+			}
+			if _, failures, _ := f.Run(idle); len(failures) == 0 {
+				t.Errorf("doing nothing passed %s", f.Name)
+			}
+		})
+	}
+
+	suite, files, err := LoadSuite("shared", paths)
+	if err != nil {
+		t.Fatalf("LoadSuite failed: %v", err)
+	}
+	if len(suite.Scenarios) != len(paths) || len(files) != len(paths) {
+		t.Errorf("suite has %d scenarios, want %d", len(suite.Scenarios), len(paths))
+	}
+}
@@ -0,0 +1,220 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zachbeta/go_inverted_pendulum/internal/quoting"
+)
+
+// yamlLine is a non-blank line of a YAML document without its comment
+type yamlLine struct {
+	number  int // 1-based, for errors
+	indent  int
+	content string
+}
+
+// yamlToJSON converts the block-style subset of YAML that scenario files
+// need, nested mappings, sequences and scalars, to JSON, so both formats
+// decode into the same structs. Anchors, multi-line strings and documents
+// are not supported; flow collections must also be valid JSON
+func yamlToJSON(data []byte) ([]byte, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(quoting.StripComment(raw), " \t\r")
+		content := strings.TrimLeft(raw, " ")
+		if content == "" || content == "---" {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(raw) - len(content), content: content})
+	}
+	if len(lines) == 0 {
+		return []byte("{}"), nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[p.pos].number)
+	}
+	return json.Marshal(value)
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block parses the mapping or sequence starting at the current line, whose
+// entries are indented by indent
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].content) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func isSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	object := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+		if isSequenceItem(line.content) {
+			return nil, fmt.Errorf("line %d: expected a key, got a list item", line.number)
+		}
+		key, rest, ok := splitKey(line.content)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", line.number)
+		}
+		if _, dup := object[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		p.pos++
+
+		if rest != "" {
+			value, err := scalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+			object[key] = value
+			continue
+		}
+		// A nested block is indented further, except that a sequence may
+		// start at the key's own indentation
+		object[key] = nil
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			if next.indent > indent || (next.indent == indent && isSequenceItem(next.content)) {
+				value, err := p.block(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				object[key] = value
+			}
+		}
+	}
+	return object, nil
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent || !isSequenceItem(line.content) {
+			if line.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+			}
+			break
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(line.content, "-"), " ")
+		switch {
+		case rest == "":
+			// The item is the block on the following lines
+			p.pos++
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				items = append(items, nil)
+				continue
+			}
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		case isSequenceItem(rest) || isMappingEntry(rest):
+			// The item's first entry shares its line: parse it as if it
+			// started its own line at the column it is written in
+			column := indent + len(line.content) - len(rest)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: column, content: rest}
+			value, err := p.block(column)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		default:
+			value, err := scalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+			items = append(items, value)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+// splitKey splits "key: value" or "key:" into an unquoted key and the value
+func splitKey(content string) (key, rest string, ok bool) {
+	i := strings.Index(content, ": ")
+	switch {
+	case i >= 0:
+		key, rest = content[:i], strings.TrimSpace(content[i+2:])
+	case strings.HasSuffix(content, ":"):
+		key = content[:len(content)-1]
+	default:
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	if unquoted, err := quoting.Unquote(key); err == nil {
+		key = unquoted
+	}
+	return key, rest, key != ""
+}
+
+// isMappingEntry reports whether a sequence item's content starts a
+// mapping rather than being a scalar
+func isMappingEntry(content string) bool {
+	if strings.HasPrefix(content, "[") || strings.HasPrefix(content, "{") {
+		return false
+	}
+	if quoting.IsQuoted(content) {
+		return false
+	}
+	_, _, ok := splitKey(content)
+	return ok
+}
+
+// scalar parses a plain, quoted or flow value
+func scalar(s string) (interface{}, error) {
+	switch s {
+	case "~", "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") {
+		var value interface{}
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			return nil, fmt.Errorf("flow collections must be JSON: %w", err)
+		}
+		return value, nil
+	}
+	if strings.HasPrefix(s, "\"") || strings.HasPrefix(s, "'") {
+		if !quoting.IsQuoted(s) {
+			return nil, fmt.Errorf("unterminated quote: %s", s)
+		}
+		return quoting.Unquote(s)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
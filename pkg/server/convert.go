@@ -0,0 +1,61 @@
+package server
+
+import (
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	pb "github.com/zachbeta/go_inverted_pendulum/pkg/server/pendulumpb"
+)
+
+// stateToProto converts an env.State to its wire form
+func stateToProto(s env.State) *pb.State {
+	return &pb.State{
+		CartPosition: s.CartPosition,
+		CartVelocity: s.CartVelocity,
+		AngleRadians: s.AngleRadians,
+		AngularVel:   s.AngularVel,
+		TimeStep:     s.TimeStep,
+	}
+}
+
+// stateFromProto converts a wire state to an env.State
+func stateFromProto(s *pb.State) env.State {
+	return env.State{
+		CartPosition: s.GetCartPosition(),
+		CartVelocity: s.GetCartVelocity(),
+		AngleRadians: s.GetAngleRadians(),
+		AngularVel:   s.GetAngularVel(),
+		TimeStep:     s.GetTimeStep(),
+	}
+}
+
+// configFromProto converts a wire config to an env.Config
+func configFromProto(c *pb.Config) env.Config {
+	return env.Config{
+		CartMass:       c.GetCartMass(),
+		PendulumMass:   c.GetPendulumMass(),
+		Length:         c.GetLength(),
+		Gravity:        c.GetGravity(),
+		MaxForce:       c.GetMaxForce(),
+		DeltaTime:      c.GetDeltaTime(),
+		SubSteps:       int(c.GetSubSteps()),
+		TrackLength:    c.GetTrackLength(),
+		Integrator:     c.GetIntegrator(),
+		CartFriction:   c.GetCartFriction(),
+		AngularDamping: c.GetAngularDamping(),
+		ImpulseProb:    c.GetImpulseProb(),
+		ImpulseForce:   c.GetImpulseForce(),
+		WindForce:      c.GetWindForce(),
+		WindNoise:      c.GetWindNoise(),
+		SensorNoise:    c.GetSensorNoise(),
+		Seed:           c.GetSeed(),
+	}
+}
+
+// disturbanceToProto converts an env.Disturbance to its wire form
+func disturbanceToProto(d env.Disturbance) *pb.Disturbance {
+	return &pb.Disturbance{
+		Impulse:          d.Impulse,
+		Wind:             d.Wind,
+		SensorAngle:      d.SensorAngle,
+		SensorAngularVel: d.SensorAngularVel,
+	}
+}
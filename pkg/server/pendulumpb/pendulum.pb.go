@@ -0,0 +1,1124 @@
+// Control API for the inverted pendulum simulation and its neural policy.
+//
+// Regenerate the Go code after editing:
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//     pkg/server/pendulumpb/pendulum.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: pkg/server/pendulumpb/pendulum.proto
+
+package pendulumpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// State mirrors env.State. An angle of 0 is upright.
+type State struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CartPosition  float64                `protobuf:"fixed64,1,opt,name=cart_position,json=cartPosition,proto3" json:"cart_position,omitempty"`
+	CartVelocity  float64                `protobuf:"fixed64,2,opt,name=cart_velocity,json=cartVelocity,proto3" json:"cart_velocity,omitempty"`
+	AngleRadians  float64                `protobuf:"fixed64,3,opt,name=angle_radians,json=angleRadians,proto3" json:"angle_radians,omitempty"`
+	AngularVel    float64                `protobuf:"fixed64,4,opt,name=angular_vel,json=angularVel,proto3" json:"angular_vel,omitempty"`
+	TimeStep      uint64                 `protobuf:"varint,5,opt,name=time_step,json=timeStep,proto3" json:"time_step,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *State) Reset() {
+	*x = State{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *State) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*State) ProtoMessage() {}
+
+func (x *State) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use State.ProtoReflect.Descriptor instead.
+func (*State) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *State) GetCartPosition() float64 {
+	if x != nil {
+		return x.CartPosition
+	}
+	return 0
+}
+
+func (x *State) GetCartVelocity() float64 {
+	if x != nil {
+		return x.CartVelocity
+	}
+	return 0
+}
+
+func (x *State) GetAngleRadians() float64 {
+	if x != nil {
+		return x.AngleRadians
+	}
+	return 0
+}
+
+func (x *State) GetAngularVel() float64 {
+	if x != nil {
+		return x.AngularVel
+	}
+	return 0
+}
+
+func (x *State) GetTimeStep() uint64 {
+	if x != nil {
+		return x.TimeStep
+	}
+	return 0
+}
+
+// Config mirrors env.Config. Unset fields keep their zero value, so send a
+// complete config or omit it to use the server's defaults.
+type Config struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CartMass       float64                `protobuf:"fixed64,1,opt,name=cart_mass,json=cartMass,proto3" json:"cart_mass,omitempty"`
+	PendulumMass   float64                `protobuf:"fixed64,2,opt,name=pendulum_mass,json=pendulumMass,proto3" json:"pendulum_mass,omitempty"`
+	Length         float64                `protobuf:"fixed64,3,opt,name=length,proto3" json:"length,omitempty"`
+	Gravity        float64                `protobuf:"fixed64,4,opt,name=gravity,proto3" json:"gravity,omitempty"`
+	MaxForce       float64                `protobuf:"fixed64,5,opt,name=max_force,json=maxForce,proto3" json:"max_force,omitempty"`
+	DeltaTime      float64                `protobuf:"fixed64,6,opt,name=delta_time,json=deltaTime,proto3" json:"delta_time,omitempty"`
+	TrackLength    float64                `protobuf:"fixed64,7,opt,name=track_length,json=trackLength,proto3" json:"track_length,omitempty"`
+	Integrator     string                 `protobuf:"bytes,8,opt,name=integrator,proto3" json:"integrator,omitempty"`
+	SubSteps       int32                  `protobuf:"varint,9,opt,name=sub_steps,json=subSteps,proto3" json:"sub_steps,omitempty"`
+	CartFriction   float64                `protobuf:"fixed64,10,opt,name=cart_friction,json=cartFriction,proto3" json:"cart_friction,omitempty"`
+	AngularDamping float64                `protobuf:"fixed64,11,opt,name=angular_damping,json=angularDamping,proto3" json:"angular_damping,omitempty"`
+	ImpulseProb    float64                `protobuf:"fixed64,12,opt,name=impulse_prob,json=impulseProb,proto3" json:"impulse_prob,omitempty"`
+	ImpulseForce   float64                `protobuf:"fixed64,13,opt,name=impulse_force,json=impulseForce,proto3" json:"impulse_force,omitempty"`
+	WindForce      float64                `protobuf:"fixed64,14,opt,name=wind_force,json=windForce,proto3" json:"wind_force,omitempty"`
+	WindNoise      float64                `protobuf:"fixed64,15,opt,name=wind_noise,json=windNoise,proto3" json:"wind_noise,omitempty"`
+	SensorNoise    float64                `protobuf:"fixed64,16,opt,name=sensor_noise,json=sensorNoise,proto3" json:"sensor_noise,omitempty"`
+	Seed           int64                  `protobuf:"varint,17,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Config) GetCartMass() float64 {
+	if x != nil {
+		return x.CartMass
+	}
+	return 0
+}
+
+func (x *Config) GetPendulumMass() float64 {
+	if x != nil {
+		return x.PendulumMass
+	}
+	return 0
+}
+
+func (x *Config) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Config) GetGravity() float64 {
+	if x != nil {
+		return x.Gravity
+	}
+	return 0
+}
+
+func (x *Config) GetMaxForce() float64 {
+	if x != nil {
+		return x.MaxForce
+	}
+	return 0
+}
+
+func (x *Config) GetDeltaTime() float64 {
+	if x != nil {
+		return x.DeltaTime
+	}
+	return 0
+}
+
+func (x *Config) GetTrackLength() float64 {
+	if x != nil {
+		return x.TrackLength
+	}
+	return 0
+}
+
+func (x *Config) GetIntegrator() string {
+	if x != nil {
+		return x.Integrator
+	}
+	return ""
+}
+
+func (x *Config) GetSubSteps() int32 {
+	if x != nil {
+		return x.SubSteps
+	}
+	return 0
+}
+
+func (x *Config) GetCartFriction() float64 {
+	if x != nil {
+		return x.CartFriction
+	}
+	return 0
+}
+
+func (x *Config) GetAngularDamping() float64 {
+	if x != nil {
+		return x.AngularDamping
+	}
+	return 0
+}
+
+func (x *Config) GetImpulseProb() float64 {
+	if x != nil {
+		return x.ImpulseProb
+	}
+	return 0
+}
+
+func (x *Config) GetImpulseForce() float64 {
+	if x != nil {
+		return x.ImpulseForce
+	}
+	return 0
+}
+
+func (x *Config) GetWindForce() float64 {
+	if x != nil {
+		return x.WindForce
+	}
+	return 0
+}
+
+func (x *Config) GetWindNoise() float64 {
+	if x != nil {
+		return x.WindNoise
+	}
+	return 0
+}
+
+func (x *Config) GetSensorNoise() float64 {
+	if x != nil {
+		return x.SensorNoise
+	}
+	return 0
+}
+
+func (x *Config) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// Disturbance mirrors env.Disturbance for the last step.
+type Disturbance struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Impulse          float64                `protobuf:"fixed64,1,opt,name=impulse,proto3" json:"impulse,omitempty"`
+	Wind             float64                `protobuf:"fixed64,2,opt,name=wind,proto3" json:"wind,omitempty"`
+	SensorAngle      float64                `protobuf:"fixed64,3,opt,name=sensor_angle,json=sensorAngle,proto3" json:"sensor_angle,omitempty"`
+	SensorAngularVel float64                `protobuf:"fixed64,4,opt,name=sensor_angular_vel,json=sensorAngularVel,proto3" json:"sensor_angular_vel,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Disturbance) Reset() {
+	*x = Disturbance{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Disturbance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Disturbance) ProtoMessage() {}
+
+func (x *Disturbance) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Disturbance.ProtoReflect.Descriptor instead.
+func (*Disturbance) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Disturbance) GetImpulse() float64 {
+	if x != nil {
+		return x.Impulse
+	}
+	return 0
+}
+
+func (x *Disturbance) GetWind() float64 {
+	if x != nil {
+		return x.Wind
+	}
+	return 0
+}
+
+func (x *Disturbance) GetSensorAngle() float64 {
+	if x != nil {
+		return x.SensorAngle
+	}
+	return 0
+}
+
+func (x *Disturbance) GetSensorAngularVel() float64 {
+	if x != nil {
+		return x.SensorAngularVel
+	}
+	return 0
+}
+
+type ResetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EnvId         string                 `protobuf:"bytes,1,opt,name=env_id,json=envId,proto3" json:"env_id,omitempty"`                      // Empty to create a new environment
+	Config        *Config                `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`                                 // Omit for env.NewDefaultConfig()
+	InitialState  *State                 `protobuf:"bytes,3,opt,name=initial_state,json=initialState,proto3" json:"initial_state,omitempty"` // Omit to start hanging down
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetRequest) Reset() {
+	*x = ResetRequest{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetRequest) ProtoMessage() {}
+
+func (x *ResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetRequest.ProtoReflect.Descriptor instead.
+func (*ResetRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResetRequest) GetEnvId() string {
+	if x != nil {
+		return x.EnvId
+	}
+	return ""
+}
+
+func (x *ResetRequest) GetConfig() *Config {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *ResetRequest) GetInitialState() *State {
+	if x != nil {
+		return x.InitialState
+	}
+	return nil
+}
+
+type ResetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EnvId         string                 `protobuf:"bytes,1,opt,name=env_id,json=envId,proto3" json:"env_id,omitempty"`
+	State         *State                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetResponse) Reset() {
+	*x = ResetResponse{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetResponse) ProtoMessage() {}
+
+func (x *ResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetResponse.ProtoReflect.Descriptor instead.
+func (*ResetResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResetResponse) GetEnvId() string {
+	if x != nil {
+		return x.EnvId
+	}
+	return ""
+}
+
+func (x *ResetResponse) GetState() *State {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type StepRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EnvId         string                 `protobuf:"bytes,1,opt,name=env_id,json=envId,proto3" json:"env_id,omitempty"`
+	Force         float64                `protobuf:"fixed64,2,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepRequest) Reset() {
+	*x = StepRequest{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepRequest) ProtoMessage() {}
+
+func (x *StepRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepRequest.ProtoReflect.Descriptor instead.
+func (*StepRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StepRequest) GetEnvId() string {
+	if x != nil {
+		return x.EnvId
+	}
+	return ""
+}
+
+func (x *StepRequest) GetForce() float64 {
+	if x != nil {
+		return x.Force
+	}
+	return 0
+}
+
+type StepResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         *State                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`   // Observed state, including sensor noise
+	Done          bool                   `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`    // The cart left the track; Reset before stepping again
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // Why the episode ended, if done
+	Disturbance   *Disturbance           `protobuf:"bytes,4,opt,name=disturbance,proto3" json:"disturbance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepResponse) Reset() {
+	*x = StepResponse{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepResponse) ProtoMessage() {}
+
+func (x *StepResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepResponse.ProtoReflect.Descriptor instead.
+func (*StepResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StepResponse) GetState() *State {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *StepResponse) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *StepResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StepResponse) GetDisturbance() *Disturbance {
+	if x != nil {
+		return x.Disturbance
+	}
+	return nil
+}
+
+type CloseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EnvId         string                 `protobuf:"bytes,1,opt,name=env_id,json=envId,proto3" json:"env_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseRequest) Reset() {
+	*x = CloseRequest{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseRequest) ProtoMessage() {}
+
+func (x *CloseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseRequest.ProtoReflect.Descriptor instead.
+func (*CloseRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CloseRequest) GetEnvId() string {
+	if x != nil {
+		return x.EnvId
+	}
+	return ""
+}
+
+type CloseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseResponse) Reset() {
+	*x = CloseResponse{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseResponse) ProtoMessage() {}
+
+func (x *CloseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseResponse.ProtoReflect.Descriptor instead.
+func (*CloseResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{8}
+}
+
+type ForwardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         *State                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardRequest) Reset() {
+	*x = ForwardRequest{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardRequest) ProtoMessage() {}
+
+func (x *ForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardRequest.ProtoReflect.Descriptor instead.
+func (*ForwardRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ForwardRequest) GetState() *State {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type ForwardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Force         float64                `protobuf:"fixed64,1,opt,name=force,proto3" json:"force,omitempty"`
+	Hidden        float64                `protobuf:"fixed64,2,opt,name=hidden,proto3" json:"hidden,omitempty"` // Hidden node pre-activation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardResponse) Reset() {
+	*x = ForwardResponse{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardResponse) ProtoMessage() {}
+
+func (x *ForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardResponse.ProtoReflect.Descriptor instead.
+func (*ForwardResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ForwardResponse) GetForce() float64 {
+	if x != nil {
+		return x.Force
+	}
+	return 0
+}
+
+func (x *ForwardResponse) GetHidden() float64 {
+	if x != nil {
+		return x.Hidden
+	}
+	return 0
+}
+
+type UpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reward        float64                `protobuf:"fixed64,1,opt,name=reward,proto3" json:"reward,omitempty"`
+	NextState     *State                 `protobuf:"bytes,2,opt,name=next_state,json=nextState,proto3" json:"next_state,omitempty"`
+	Done          bool                   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateRequest) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetNextState() *State {
+	if x != nil {
+		return x.NextState
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+type UpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Weights       []float64              `protobuf:"fixed64,1,rep,packed,name=weights,proto3" json:"weights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateResponse) Reset() {
+	*x = UpdateResponse{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateResponse) ProtoMessage() {}
+
+func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateResponse) GetWeights() []float64 {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+type GetWeightsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWeightsRequest) Reset() {
+	*x = GetWeightsRequest{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWeightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWeightsRequest) ProtoMessage() {}
+
+func (x *GetWeightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWeightsRequest.ProtoReflect.Descriptor instead.
+func (*GetWeightsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{13}
+}
+
+type GetWeightsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Weights        []float64              `protobuf:"fixed64,1,rep,packed,name=weights,proto3" json:"weights,omitempty"`                                     // [angle, angular velocity, bias]
+	FeatureWeights []float64              `protobuf:"fixed64,2,rep,packed,name=feature_weights,json=featureWeights,proto3" json:"feature_weights,omitempty"` // Engineered feature weights, if any
+	LearningRate   float64                `protobuf:"fixed64,3,opt,name=learning_rate,json=learningRate,proto3" json:"learning_rate,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetWeightsResponse) Reset() {
+	*x = GetWeightsResponse{}
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWeightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWeightsResponse) ProtoMessage() {}
+
+func (x *GetWeightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_server_pendulumpb_pendulum_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWeightsResponse.ProtoReflect.Descriptor instead.
+func (*GetWeightsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetWeightsResponse) GetWeights() []float64 {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+func (x *GetWeightsResponse) GetFeatureWeights() []float64 {
+	if x != nil {
+		return x.FeatureWeights
+	}
+	return nil
+}
+
+func (x *GetWeightsResponse) GetLearningRate() float64 {
+	if x != nil {
+		return x.LearningRate
+	}
+	return 0
+}
+
+var File_pkg_server_pendulumpb_pendulum_proto protoreflect.FileDescriptor
+
+const file_pkg_server_pendulumpb_pendulum_proto_rawDesc = "" +
+	"\n" +
+	"$pkg/server/pendulumpb/pendulum.proto\x12\vpendulum.v1\"\xb4\x01\n" +
+	"\x05State\x12#\n" +
+	"\rcart_position\x18\x01 \x01(\x01R\fcartPosition\x12#\n" +
+	"\rcart_velocity\x18\x02 \x01(\x01R\fcartVelocity\x12#\n" +
+	"\rangle_radians\x18\x03 \x01(\x01R\fangleRadians\x12\x1f\n" +
+	"\vangular_vel\x18\x04 \x01(\x01R\n" +
+	"angularVel\x12\x1b\n" +
+	"\ttime_step\x18\x05 \x01(\x04R\btimeStep\"\xa3\x04\n" +
+	"\x06Config\x12\x1b\n" +
+	"\tcart_mass\x18\x01 \x01(\x01R\bcartMass\x12#\n" +
+	"\rpendulum_mass\x18\x02 \x01(\x01R\fpendulumMass\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x01R\x06length\x12\x18\n" +
+	"\agravity\x18\x04 \x01(\x01R\agravity\x12\x1b\n" +
+	"\tmax_force\x18\x05 \x01(\x01R\bmaxForce\x12\x1d\n" +
+	"\n" +
+	"delta_time\x18\x06 \x01(\x01R\tdeltaTime\x12!\n" +
+	"\ftrack_length\x18\a \x01(\x01R\vtrackLength\x12\x1e\n" +
+	"\n" +
+	"integrator\x18\b \x01(\tR\n" +
+	"integrator\x12\x1b\n" +
+	"\tsub_steps\x18\t \x01(\x05R\bsubSteps\x12#\n" +
+	"\rcart_friction\x18\n" +
+	" \x01(\x01R\fcartFriction\x12'\n" +
+	"\x0fangular_damping\x18\v \x01(\x01R\x0eangularDamping\x12!\n" +
+	"\fimpulse_prob\x18\f \x01(\x01R\vimpulseProb\x12#\n" +
+	"\rimpulse_force\x18\r \x01(\x01R\fimpulseForce\x12\x1d\n" +
+	"\n" +
+	"wind_force\x18\x0e \x01(\x01R\twindForce\x12\x1d\n" +
+	"\n" +
+	"wind_noise\x18\x0f \x01(\x01R\twindNoise\x12!\n" +
+	"\fsensor_noise\x18\x10 \x01(\x01R\vsensorNoise\x12\x12\n" +
+	"\x04seed\x18\x11 \x01(\x03R\x04seed\"\x8c\x01\n" +
+	"\vDisturbance\x12\x18\n" +
+	"\aimpulse\x18\x01 \x01(\x01R\aimpulse\x12\x12\n" +
+	"\x04wind\x18\x02 \x01(\x01R\x04wind\x12!\n" +
+	"\fsensor_angle\x18\x03 \x01(\x01R\vsensorAngle\x12,\n" +
+	"\x12sensor_angular_vel\x18\x04 \x01(\x01R\x10sensorAngularVel\"\x8b\x01\n" +
+	"\fResetRequest\x12\x15\n" +
+	"\x06env_id\x18\x01 \x01(\tR\x05envId\x12+\n" +
+	"\x06config\x18\x02 \x01(\v2\x13.pendulum.v1.ConfigR\x06config\x127\n" +
+	"\rinitial_state\x18\x03 \x01(\v2\x12.pendulum.v1.StateR\finitialState\"P\n" +
+	"\rResetResponse\x12\x15\n" +
+	"\x06env_id\x18\x01 \x01(\tR\x05envId\x12(\n" +
+	"\x05state\x18\x02 \x01(\v2\x12.pendulum.v1.StateR\x05state\":\n" +
+	"\vStepRequest\x12\x15\n" +
+	"\x06env_id\x18\x01 \x01(\tR\x05envId\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\x01R\x05force\"\xa0\x01\n" +
+	"\fStepResponse\x12(\n" +
+	"\x05state\x18\x01 \x01(\v2\x12.pendulum.v1.StateR\x05state\x12\x12\n" +
+	"\x04done\x18\x02 \x01(\bR\x04done\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12:\n" +
+	"\vdisturbance\x18\x04 \x01(\v2\x18.pendulum.v1.DisturbanceR\vdisturbance\"%\n" +
+	"\fCloseRequest\x12\x15\n" +
+	"\x06env_id\x18\x01 \x01(\tR\x05envId\"\x0f\n" +
+	"\rCloseResponse\":\n" +
+	"\x0eForwardRequest\x12(\n" +
+	"\x05state\x18\x01 \x01(\v2\x12.pendulum.v1.StateR\x05state\"?\n" +
+	"\x0fForwardResponse\x12\x14\n" +
+	"\x05force\x18\x01 \x01(\x01R\x05force\x12\x16\n" +
+	"\x06hidden\x18\x02 \x01(\x01R\x06hidden\"n\n" +
+	"\rUpdateRequest\x12\x16\n" +
+	"\x06reward\x18\x01 \x01(\x01R\x06reward\x121\n" +
+	"\n" +
+	"next_state\x18\x02 \x01(\v2\x12.pendulum.v1.StateR\tnextState\x12\x12\n" +
+	"\x04done\x18\x03 \x01(\bR\x04done\"*\n" +
+	"\x0eUpdateResponse\x12\x18\n" +
+	"\aweights\x18\x01 \x03(\x01R\aweights\"\x13\n" +
+	"\x11GetWeightsRequest\"|\n" +
+	"\x12GetWeightsResponse\x12\x18\n" +
+	"\aweights\x18\x01 \x03(\x01R\aweights\x12'\n" +
+	"\x0ffeature_weights\x18\x02 \x03(\x01R\x0efeatureWeights\x12#\n" +
+	"\rlearning_rate\x18\x03 \x01(\x01R\flearningRate2\xca\x01\n" +
+	"\vEnvironment\x12>\n" +
+	"\x05Reset\x12\x19.pendulum.v1.ResetRequest\x1a\x1a.pendulum.v1.ResetResponse\x12;\n" +
+	"\x04Step\x12\x18.pendulum.v1.StepRequest\x1a\x19.pendulum.v1.StepResponse\x12>\n" +
+	"\x05Close\x12\x19.pendulum.v1.CloseRequest\x1a\x1a.pendulum.v1.CloseResponse2\xe0\x01\n" +
+	"\x06Policy\x12D\n" +
+	"\aForward\x12\x1b.pendulum.v1.ForwardRequest\x1a\x1c.pendulum.v1.ForwardResponse\x12A\n" +
+	"\x06Update\x12\x1a.pendulum.v1.UpdateRequest\x1a\x1b.pendulum.v1.UpdateResponse\x12M\n" +
+	"\n" +
+	"GetWeights\x12\x1e.pendulum.v1.GetWeightsRequest\x1a\x1f.pendulum.v1.GetWeightsResponseB@Z>github.com/zachbeta/go_inverted_pendulum/pkg/server/pendulumpbb\x06proto3"
+
+var (
+	file_pkg_server_pendulumpb_pendulum_proto_rawDescOnce sync.Once
+	file_pkg_server_pendulumpb_pendulum_proto_rawDescData []byte
+)
+
+func file_pkg_server_pendulumpb_pendulum_proto_rawDescGZIP() []byte {
+	file_pkg_server_pendulumpb_pendulum_proto_rawDescOnce.Do(func() {
+		file_pkg_server_pendulumpb_pendulum_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_server_pendulumpb_pendulum_proto_rawDesc), len(file_pkg_server_pendulumpb_pendulum_proto_rawDesc)))
+	})
+	return file_pkg_server_pendulumpb_pendulum_proto_rawDescData
+}
+
+var file_pkg_server_pendulumpb_pendulum_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_pkg_server_pendulumpb_pendulum_proto_goTypes = []any{
+	(*State)(nil),              // 0: pendulum.v1.State
+	(*Config)(nil),             // 1: pendulum.v1.Config
+	(*Disturbance)(nil),        // 2: pendulum.v1.Disturbance
+	(*ResetRequest)(nil),       // 3: pendulum.v1.ResetRequest
+	(*ResetResponse)(nil),      // 4: pendulum.v1.ResetResponse
+	(*StepRequest)(nil),        // 5: pendulum.v1.StepRequest
+	(*StepResponse)(nil),       // 6: pendulum.v1.StepResponse
+	(*CloseRequest)(nil),       // 7: pendulum.v1.CloseRequest
+	(*CloseResponse)(nil),      // 8: pendulum.v1.CloseResponse
+	(*ForwardRequest)(nil),     // 9: pendulum.v1.ForwardRequest
+	(*ForwardResponse)(nil),    // 10: pendulum.v1.ForwardResponse
+	(*UpdateRequest)(nil),      // 11: pendulum.v1.UpdateRequest
+	(*UpdateResponse)(nil),     // 12: pendulum.v1.UpdateResponse
+	(*GetWeightsRequest)(nil),  // 13: pendulum.v1.GetWeightsRequest
+	(*GetWeightsResponse)(nil), // 14: pendulum.v1.GetWeightsResponse
+}
+var file_pkg_server_pendulumpb_pendulum_proto_depIdxs = []int32{
+	1,  // 0: pendulum.v1.ResetRequest.config:type_name -> pendulum.v1.Config
+	0,  // 1: pendulum.v1.ResetRequest.initial_state:type_name -> pendulum.v1.State
+	0,  // 2: pendulum.v1.ResetResponse.state:type_name -> pendulum.v1.State
+	0,  // 3: pendulum.v1.StepResponse.state:type_name -> pendulum.v1.State
+	2,  // 4: pendulum.v1.StepResponse.disturbance:type_name -> pendulum.v1.Disturbance
+	0,  // 5: pendulum.v1.ForwardRequest.state:type_name -> pendulum.v1.State
+	0,  // 6: pendulum.v1.UpdateRequest.next_state:type_name -> pendulum.v1.State
+	3,  // 7: pendulum.v1.Environment.Reset:input_type -> pendulum.v1.ResetRequest
+	5,  // 8: pendulum.v1.Environment.Step:input_type -> pendulum.v1.StepRequest
+	7,  // 9: pendulum.v1.Environment.Close:input_type -> pendulum.v1.CloseRequest
+	9,  // 10: pendulum.v1.Policy.Forward:input_type -> pendulum.v1.ForwardRequest
+	11, // 11: pendulum.v1.Policy.Update:input_type -> pendulum.v1.UpdateRequest
+	13, // 12: pendulum.v1.Policy.GetWeights:input_type -> pendulum.v1.GetWeightsRequest
+	4,  // 13: pendulum.v1.Environment.Reset:output_type -> pendulum.v1.ResetResponse
+	6,  // 14: pendulum.v1.Environment.Step:output_type -> pendulum.v1.StepResponse
+	8,  // 15: pendulum.v1.Environment.Close:output_type -> pendulum.v1.CloseResponse
+	10, // 16: pendulum.v1.Policy.Forward:output_type -> pendulum.v1.ForwardResponse
+	12, // 17: pendulum.v1.Policy.Update:output_type -> pendulum.v1.UpdateResponse
+	14, // 18: pendulum.v1.Policy.GetWeights:output_type -> pendulum.v1.GetWeightsResponse
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_pkg_server_pendulumpb_pendulum_proto_init() }
+func file_pkg_server_pendulumpb_pendulum_proto_init() {
+	if File_pkg_server_pendulumpb_pendulum_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_server_pendulumpb_pendulum_proto_rawDesc), len(file_pkg_server_pendulumpb_pendulum_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_pkg_server_pendulumpb_pendulum_proto_goTypes,
+		DependencyIndexes: file_pkg_server_pendulumpb_pendulum_proto_depIdxs,
+		MessageInfos:      file_pkg_server_pendulumpb_pendulum_proto_msgTypes,
+	}.Build()
+	File_pkg_server_pendulumpb_pendulum_proto = out.File
+	file_pkg_server_pendulumpb_pendulum_proto_goTypes = nil
+	file_pkg_server_pendulumpb_pendulum_proto_depIdxs = nil
+}
@@ -0,0 +1,196 @@
+// Package server exposes the pendulum environment and the neural policy
+// over gRPC, so external agents can train against the Go physics engine
+// and other processes can query a trained policy
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	pb "github.com/zachbeta/go_inverted_pendulum/pkg/server/pendulumpb"
+)
+
+// Server implements the Environment and Policy gRPC services
+type Server struct {
+	pb.UnimplementedEnvironmentServer
+	pb.UnimplementedPolicyServer
+
+	mu        sync.Mutex
+	envs      map[string]*environment
+	nextID    int
+	network   *neural.Network // Nil disables the Policy service
+	logger    *log.Logger
+	envLogger *log.Logger // Pendulums log every step, so they get a quiet logger
+	defaults  env.Config  // Config for Reset requests that omit one
+}
+
+// environment is one hosted pendulum simulation
+type environment struct {
+	mu       sync.Mutex
+	pendulum *env.Pendulum
+	done     bool
+}
+
+// New creates a server. The network may be nil to serve only environments
+func New(network *neural.Network, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Server{
+		envs:      make(map[string]*environment),
+		network:   network,
+		logger:    logger,
+		envLogger: log.New(io.Discard, "", 0),
+		defaults:  env.NewDefaultConfig(),
+	}
+}
+
+// SetDefaultConfig sets the pendulum config used by Reset requests that
+// omit one, e.g. a named preset
+func (s *Server) SetDefaultConfig(config env.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = config
+}
+
+// Register adds both services to a gRPC server
+func (s *Server) Register(g *grpc.Server) {
+	pb.RegisterEnvironmentServer(g, s)
+	pb.RegisterPolicyServer(g, s)
+}
+
+// Reset creates a new environment or restarts an existing one
+func (s *Server) Reset(ctx context.Context, req *pb.ResetRequest) (*pb.ResetResponse, error) {
+	s.mu.Lock()
+	config := s.defaults
+	s.mu.Unlock()
+	if req.GetConfig() != nil {
+		config = configFromProto(req.GetConfig())
+	}
+
+	pendulum := env.NewPendulum(config, s.envLogger)
+	if req.GetInitialState() != nil {
+		pendulum.Reset(stateFromProto(req.GetInitialState()))
+	}
+
+	s.mu.Lock()
+	id := req.GetEnvId()
+	if id == "" {
+		s.nextID++
+		id = fmt.Sprintf("env_%d", s.nextID)
+	} else if _, ok := s.envs[id]; !ok {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.NotFound, "unknown environment %q", id)
+	}
+	s.envs[id] = &environment{pendulum: pendulum}
+	s.mu.Unlock()
+
+	s.logger.Printf("[Server] Reset %s", id)
+	return &pb.ResetResponse{EnvId: id, State: stateToProto(pendulum.GetState())}, nil
+}
+
+// Step applies a force to an environment for one control period
+func (s *Server) Step(ctx context.Context, req *pb.StepRequest) (*pb.StepResponse, error) {
+	e, err := s.lookup(req.GetEnvId())
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.done {
+		return nil, status.Errorf(codes.FailedPrecondition, "environment %q is done; call Reset", req.GetEnvId())
+	}
+
+	state, done, stepErr := e.pendulum.Advance(req.GetForce())
+	resp := &pb.StepResponse{
+		State:       stateToProto(state),
+		Disturbance: disturbanceToProto(e.pendulum.GetLastDisturbance()),
+	}
+	if done {
+		e.done = true
+		resp.Done = true
+		resp.Reason = string(e.pendulum.GetTermination())
+		if stepErr != nil {
+			resp.Reason = stepErr.Error()
+		}
+	}
+	return resp, nil
+}
+
+// Close releases an environment
+func (s *Server) Close(ctx context.Context, req *pb.CloseRequest) (*pb.CloseResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.envs[req.GetEnvId()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown environment %q", req.GetEnvId())
+	}
+	delete(s.envs, req.GetEnvId())
+	return &pb.CloseResponse{}, nil
+}
+
+// Forward returns the network's force for a state
+func (s *Server) Forward(ctx context.Context, req *pb.ForwardRequest) (*pb.ForwardResponse, error) {
+	if s.network == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no policy network loaded")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	force, hidden := s.network.ForwardWithActivation(stateFromProto(req.GetState()))
+	return &pb.ForwardResponse{Force: force, Hidden: hidden}, nil
+}
+
+// Update trains the network on the reward for its last Forward
+func (s *Server) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	if s.network == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no policy network loaded")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.network.UpdateTD(req.GetReward(), stateFromProto(req.GetNextState()), req.GetDone())
+	return &pb.UpdateResponse{Weights: s.network.GetWeights()}, nil
+}
+
+// GetWeights returns the network's current weights
+func (s *Server) GetWeights(ctx context.Context, req *pb.GetWeightsRequest) (*pb.GetWeightsResponse, error) {
+	if s.network == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no policy network loaded")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &pb.GetWeightsResponse{
+		Weights:        s.network.GetWeights(),
+		FeatureWeights: s.network.GetFeatureWeights(),
+		LearningRate:   s.network.GetLearningRate(),
+	}, nil
+}
+
+// lookup finds an environment by ID
+func (s *Server) lookup(id string) (*environment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.envs[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown environment %q", id)
+	}
+	return e, nil
+}
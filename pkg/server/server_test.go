@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+	pb "github.com/zachbeta/go_inverted_pendulum/pkg/server/pendulumpb"
+)
+
+// dial starts an in-memory server and returns a connected client
+func dial(t *testing.T, network *neural.Network) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	New(network, log.New(io.Discard, "", 0)).Register(g)
+	go g.Serve(listener)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestEnvironmentService(t *testing.T) {
+	ctx := context.Background()
+	client := pb.NewEnvironmentClient(dial(t, nil))
+
+	reset, err := client.Reset(ctx, &pb.ResetRequest{InitialState: &pb.State{AngleRadians: 0.1}})
+	if err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if reset.GetState().GetAngleRadians() != 0.1 {
+		t.Errorf("initial angle = %.3f, want 0.1", reset.GetState().GetAngleRadians())
+	}
+
+	step, err := client.Step(ctx, &pb.StepRequest{EnvId: reset.GetEnvId(), Force: 1.0})
+	if err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+	if step.GetState().GetTimeStep() != 1 || step.GetDone() {
+		t.Errorf("unexpected step response: %v", step)
+	}
+
+	// Pushing hard eventually leaves the track and ends the episode
+	for i := 0; i < 1000 && !step.GetDone(); i++ {
+		step, err = client.Step(ctx, &pb.StepRequest{EnvId: reset.GetEnvId(), Force: 10.0})
+		if err != nil {
+			t.Fatalf("Step %d failed: %v", i, err)
+		}
+	}
+	if !step.GetDone() || step.GetReason() == "" {
+		t.Fatal("expected the episode to end at the track bounds")
+	}
+	_, err = client.Step(ctx, &pb.StepRequest{EnvId: reset.GetEnvId()})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Step after done: got %v, want FailedPrecondition", err)
+	}
+
+	if _, err := client.Close(ctx, &pb.CloseRequest{EnvId: reset.GetEnvId()}); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	_, err = client.Step(ctx, &pb.StepRequest{EnvId: reset.GetEnvId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Step after Close: got %v, want NotFound", err)
+	}
+}
+
+func TestPolicyService(t *testing.T) {
+	ctx := context.Background()
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(io.Discard, "", 0))
+	client := pb.NewPolicyClient(dial(t, network))
+
+	state := &pb.State{AngleRadians: 0.2, AngularVel: 0.1}
+	forward, err := client.Forward(ctx, &pb.ForwardRequest{State: state})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if forward.GetForce() >= 0 {
+		t.Errorf("force = %.3f, want negative force for a rightward tilt", forward.GetForce())
+	}
+
+	before, err := client.GetWeights(ctx, &pb.GetWeightsRequest{})
+	if err != nil {
+		t.Fatalf("GetWeights failed: %v", err)
+	}
+	update, err := client.Update(ctx, &pb.UpdateRequest{Reward: 1.0, NextState: state})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if update.GetWeights()[0] == before.GetWeights()[0] {
+		t.Error("Update did not change the weights")
+	}
+
+	// Without a network the policy service is unavailable
+	_, err = pb.NewPolicyClient(dial(t, nil)).Forward(ctx, &pb.ForwardRequest{State: state})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Forward without network: got %v, want FailedPrecondition", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package sound synthesizes the window demo's audio cues: a tone whose pitch
+// follows the pendulum's deviation from upright, a click when an episode
+// fails and a chime for a new best episode. Samples are 32-bit float stereo
+// PCM, the format ebiten's audio players take, so the cues can be tested
+// without an audio device
+package sound
+
+import (
+	"encoding/binary"
+	"math"
+	"sync/atomic"
+)
+
+// SampleRate is the sample rate the cues are meant to be played at
+const SampleRate = 44100
+
+// bytesPerFrame is the size of one stereo frame of float32 samples
+const bytesPerFrame = 8
+
+// Pitch range of the deviation tone: upright sounds MinPitch, and the pitch
+// rises by PitchOctaves up to MaxPitch at PitchRange radians from upright
+const (
+	MinPitch     = 220.0
+	PitchOctaves = 2.0
+	MaxPitch     = MinPitch * 4 // MinPitch raised by PitchOctaves
+	PitchRange   = math.Pi / 4
+)
+
+// glideTime is how many seconds the tone takes to move most of the way to a
+// new pitch, so pitch changes between frames do not click
+const glideTime = 0.02
+
+// Pitch returns the tone frequency in Hz for a deviation from upright in
+// radians, either sign, rising exponentially so equal angle changes sound
+// like equal intervals
+func Pitch(deviation float64) float64 {
+	x := math.Min(math.Abs(deviation)/PitchRange, 1)
+	return MinPitch * math.Pow(2, PitchOctaves*x)
+}
+
+// Tone is an endless sine wave whose frequency can be changed while it
+// plays, read by an audio player on its own goroutine
+type Tone struct {
+	target     atomic.Uint64 // math.Float64bits of the frequency set
+	sampleRate float64
+	frequency  float64 // Current frequency, gliding toward target
+	phase      float64 // Radians
+}
+
+// NewTone returns a tone at MinPitch for the given sample rate
+func NewTone(sampleRate int) *Tone {
+	t := &Tone{sampleRate: float64(sampleRate), frequency: MinPitch}
+	t.SetFrequency(MinPitch)
+	return t
+}
+
+// SetFrequency sets the frequency in Hz the tone glides to. It is safe to
+// call while the tone is being read
+func (t *Tone) SetFrequency(hz float64) {
+	t.target.Store(math.Float64bits(hz))
+}
+
+// Frequency returns the frequency set with SetFrequency
+func (t *Tone) Frequency() float64 {
+	return math.Float64frombits(t.target.Load())
+}
+
+// Read fills p with whole stereo frames of the tone and never ends
+func (t *Tone) Read(p []byte) (int, error) {
+	target := t.Frequency()
+	glide := 1 - math.Exp(-1/(glideTime*t.sampleRate))
+	n := len(p) / bytesPerFrame * bytesPerFrame
+	for i := 0; i < n; i += bytesPerFrame {
+		t.frequency += (target - t.frequency) * glide
+		t.phase = math.Mod(t.phase+2*math.Pi*t.frequency/t.sampleRate, 2*math.Pi)
+		putFrame(p[i:], math.Sin(t.phase))
+	}
+	return n, nil
+}
+
+// Click returns a short decaying tick, for an episode that failed
+func Click(sampleRate int) []byte {
+	const (
+		duration  = 0.03 // Seconds
+		frequency = 1800.0
+		decay     = 150.0 // Per second
+	)
+	return synthesize(sampleRate, duration, func(t float64) float64 {
+		return math.Sin(2*math.Pi*frequency*t) * math.Exp(-decay*t)
+	})
+}
+
+// Chime returns two rising bell-like notes, for a new best episode
+func Chime(sampleRate int) []byte {
+	const (
+		duration = 0.8 // Seconds
+		second   = 0.12 // Start of the second note
+		decay    = 6.0 // Per second
+	)
+	note := func(frequency, t float64) float64 {
+		if t < 0 {
+			return 0
+		}
+		// A quieter overtone an octave up makes it ring like a bell
+		return (math.Sin(2*math.Pi*frequency*t) + 0.3*math.Sin(4*math.Pi*frequency*t)) / 1.3 * math.Exp(-decay*t)
+	}
+	return synthesize(sampleRate, duration, func(t float64) float64 {
+		return 0.5*note(659.25, t) + 0.5*note(987.77, t-second) // E5, then B5
+	})
+}
+
+// synthesize samples wave, a function of time in seconds with values in
+// [-1, 1], into stereo frames, fading out over the last few milliseconds
+func synthesize(sampleRate int, duration float64, wave func(t float64) float64) []byte {
+	frames := int(duration * float64(sampleRate))
+	fade := min(frames, sampleRate/200)
+	buf := make([]byte, frames*bytesPerFrame)
+	for i := 0; i < frames; i++ {
+		v := wave(float64(i) / float64(sampleRate))
+		if left := frames - i; left < fade {
+			v *= float64(left) / float64(fade)
+		}
+		putFrame(buf[i*bytesPerFrame:], v)
+	}
+	return buf
+}
+
+// putFrame writes v to both channels of the frame at the start of p
+func putFrame(p []byte, v float64) {
+	bits := math.Float32bits(float32(v))
+	binary.LittleEndian.PutUint32(p[0:], bits)
+	binary.LittleEndian.PutUint32(p[4:], bits)
+}
@@ -0,0 +1,98 @@
+package sound
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// samples decodes the left channel of stereo float32 frames
+func samples(p []byte) []float64 {
+	s := make([]float64, len(p)/bytesPerFrame)
+	for i := range s {
+		s[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(p[i*bytesPerFrame:])))
+	}
+	return s
+}
+
+func TestPitch(t *testing.T) {
+	if got := Pitch(0); got != MinPitch {
+		t.Errorf("Pitch(0) = %v, want %v", got, MinPitch)
+	}
+	if got := Pitch(PitchRange); math.Abs(got-MaxPitch) > 1e-9 {
+		t.Errorf("Pitch(PitchRange) = %v, want %v", got, MaxPitch)
+	}
+	if got := Pitch(math.Pi); math.Abs(got-MaxPitch) > 1e-9 {
+		t.Errorf("Pitch(π) = %v, want it held at %v", got, MaxPitch)
+	}
+	if Pitch(-0.2) != Pitch(0.2) {
+		t.Errorf("Pitch(-0.2) = %v, Pitch(0.2) = %v, want the same", Pitch(-0.2), Pitch(0.2))
+	}
+	if Pitch(0.1) >= Pitch(0.2) {
+		t.Errorf("Pitch(0.1) = %v not below Pitch(0.2) = %v", Pitch(0.1), Pitch(0.2))
+	}
+}
+
+func TestTone(t *testing.T) {
+	tone := NewTone(SampleRate)
+	tone.SetFrequency(440)
+
+	// Let the glide settle, then count rising zero crossings over a second
+	settle := make([]byte, SampleRate/10*bytesPerFrame)
+	tone.Read(settle)
+	buf := make([]byte, SampleRate*bytesPerFrame+3)
+	n, err := tone.Read(buf)
+	if err != nil || n != SampleRate*bytesPerFrame {
+		t.Fatalf("Read = %d, %v; want %d whole frames and no error", n, err, SampleRate*bytesPerFrame)
+	}
+	s := samples(buf[:n])
+	crossings := 0
+	for i := 1; i < len(s); i++ {
+		if s[i-1] < 0 && s[i] >= 0 {
+			crossings++
+		}
+		if math.Abs(s[i]-s[i-1]) > 2*math.Pi*440/SampleRate+1e-6 {
+			t.Fatalf("sample %d jumps from %v to %v", i, s[i-1], s[i])
+		}
+	}
+	if crossings < 439 || crossings > 441 {
+		t.Errorf("got %d cycles in a second, want 440", crossings)
+	}
+
+	// Changing pitch glides without a jump
+	tone.SetFrequency(880)
+	n, _ = tone.Read(buf)
+	s = samples(buf[:n])
+	for i := 1; i < len(s); i++ {
+		if math.Abs(s[i]-s[i-1]) > 2*math.Pi*880/SampleRate+1e-6 {
+			t.Fatalf("sample %d jumps from %v to %v after a pitch change", i, s[i-1], s[i])
+		}
+	}
+}
+
+func TestCues(t *testing.T) {
+	for name, cue := range map[string][]byte{
+		"click": Click(SampleRate),
+		"chime": Chime(SampleRate),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if len(cue) == 0 || len(cue)%bytesPerFrame != 0 {
+				t.Fatalf("cue is %d bytes, want whole stereo frames", len(cue))
+			}
+			s := samples(cue)
+			peak := 0.0
+			for _, v := range s {
+				peak = math.Max(peak, math.Abs(v))
+			}
+			if peak == 0 || peak > 1 {
+				t.Errorf("peak = %v, want audible and within [-1, 1]", peak)
+			}
+			if last := s[len(s)-1]; math.Abs(last) > 1e-3 {
+				t.Errorf("last sample = %v, want faded out", last)
+			}
+		})
+	}
+	if len(Click(SampleRate)) >= len(Chime(SampleRate)) {
+		t.Error("click is not shorter than the chime")
+	}
+}
@@ -0,0 +1,60 @@
+package training
+
+import (
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+)
+
+// EvalResult is a greedy evaluation run between training episodes
+type EvalResult struct {
+	Episode int // Training episodes completed before the evaluation
+	eval.Result
+}
+
+// SetEvalSuite replaces the standard suite that periodic evaluations draw
+// their scenarios from, e.g. with one matching the training physics
+func (t *Trainer) SetEvalSuite(suite eval.Suite) {
+	t.evalSuite = suite
+}
+
+// OnEvaluation registers a callback run after every periodic evaluation,
+// e.g. to record it with metrics.Logger.LogEvaluation
+func (t *Trainer) OnEvaluation(handler func(EvalResult)) {
+	t.evalHandlers = append(t.evalHandlers, handler)
+}
+
+// Evaluations returns the periodic evaluations run so far, oldest first
+func (t *Trainer) Evaluations() []EvalResult {
+	return append([]EvalResult(nil), t.evaluations...)
+}
+
+// evaluate runs EvalEpisodes scenarios of the eval suite with the network's
+// greedy policy. The network is in evaluation mode meanwhile, so it neither
+// learns, samples nor records metrics, and exploration noise is applied
+// outside the trainer, so training continues exactly as if no evaluation had
+// run. Evaluation steps are not counted in the training budget
+func (t *Trainer) evaluate() {
+	if !t.network.EvalMode() {
+		t.network.SetEvalMode(true)
+		defer t.network.SetEvalMode(false)
+	}
+
+	suite := t.evalSuite
+	if n := t.config.EvalEpisodes; n > 0 && n < len(suite.Scenarios) {
+		// Spread the subset across the suite so it keeps the harder scenarios
+		scenarios := make([]eval.Scenario, n)
+		for i := range scenarios {
+			scenarios[i] = suite.Scenarios[i*len(suite.Scenarios)/n]
+		}
+		suite.Scenarios = scenarios
+	}
+
+	result := EvalResult{Episode: t.episode, Result: eval.Run(suite, t.network)}
+	t.evaluations = append(t.evaluations, result)
+	if t.sampler.Episode() {
+		t.logger.Printf("[Trainer] Eval after episode %d: success %.0f%%, reward %.3f, balance %.1fs on %d %s scenarios",
+			result.Episode, 100*result.SuccessRate, result.AvgReward, result.AvgBalanceTime, len(suite.Scenarios), suite.Name)
+	}
+	for _, handler := range t.evalHandlers {
+		handler(result)
+	}
+}
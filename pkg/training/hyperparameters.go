@@ -0,0 +1,56 @@
+package training
+
+import "math/rand"
+
+// maxGamma keeps perturbed discount factors below 1, where returns would
+// stop converging
+const maxGamma = 0.999
+
+// Hyperparameters are the settings population-based training copies from
+// better trainers and perturbs while training continues
+type Hyperparameters struct {
+	LearningRate float64 `json:"learning_rate"`
+	Gamma        float64 `json:"gamma"`
+}
+
+// Hyperparameters returns the trainer's current learning rate and discount
+func (t *Trainer) Hyperparameters() Hyperparameters {
+	return Hyperparameters{LearningRate: t.learningRate, Gamma: t.config.Gamma}
+}
+
+// SetHyperparameters replaces the learning rate, which the schedule then
+// continues from, and the discount of both batch and online updates. Zero
+// fields are left unchanged
+func (t *Trainer) SetHyperparameters(h Hyperparameters) {
+	if h.LearningRate > 0 {
+		t.setLearningRate(h.LearningRate)
+	}
+	if h.Gamma > 0 {
+		t.config.Gamma = min(h.Gamma, maxGamma)
+		t.network.SetDiscount(t.config.Gamma)
+	}
+}
+
+// ResetOptimizer clears the optimizer's momentum and moment estimates,
+// e.g. after the network's weights are replaced
+func (t *Trainer) ResetOptimizer() {
+	t.optimizer.Reset()
+}
+
+// Perturb scales each hyperparameter up or down by factor, each direction
+// with even odds, e.g. by 0.8 or 1.2 for 0.2. A nil rng uses the global source
+func (h Hyperparameters) Perturb(factor float64, rng *rand.Rand) Hyperparameters {
+	coin := rand.Float64
+	if rng != nil {
+		coin = rng.Float64
+	}
+	scale := func(v float64) float64 {
+		if coin() < 0.5 {
+			return v * (1 - factor)
+		}
+		return v * (1 + factor)
+	}
+	h.LearningRate = scale(h.LearningRate)
+	h.Gamma = min(scale(h.Gamma), maxGamma)
+	return h
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 )
 
 // MetricsCollector tracks training progress metrics
@@ -12,10 +14,15 @@ type MetricsCollector struct {
 	StartTime       time.Time
 	MaxAngle        float64
 	MinAngle        float64
+	MaxDeviation    float64 // Largest deviation from upright, wrapping across 0 and 2π
 	TotalReward     float64
 	ExperienceCount int
 	WeightUpdates   []WeightUpdate
 	BatchCount      int // Number of batches processed
+	LambdaReturns   []float64 // Average λ-return target of each batch
+	GradientClips   int // Batches whose gradient was clipped to MaxGradNorm
+	NonFiniteUpdates int // Batches rejected for producing NaN or Inf weights
+	SaturatedSteps  int // Steps whose force was pinned at the output limit
 }
 
 // WeightUpdate tracks changes in network weights
@@ -50,6 +57,7 @@ func (m *MetricsCollector) RecordExperience(exp Experience) {
 	if angle < m.MinAngle {
 		m.MinAngle = angle
 	}
+	m.MaxDeviation = math.Max(m.MaxDeviation, env.UprightDeviation(exp.State.AngleRadians))
 }
 
 // RecordBatchProcessed increments the batch counter
@@ -57,6 +65,26 @@ func (m *MetricsCollector) RecordBatchProcessed() {
 	m.BatchCount++
 }
 
+// RecordLambdaReturn records the average λ-return target of a processed batch
+func (m *MetricsCollector) RecordLambdaReturn(target float64) {
+	m.LambdaReturns = append(m.LambdaReturns, target)
+}
+
+// RecordGradientClip counts a batch whose gradient was clipped
+func (m *MetricsCollector) RecordGradientClip() {
+	m.GradientClips++
+}
+
+// RecordNonFiniteUpdate counts a batch rejected for producing NaN or Inf weights
+func (m *MetricsCollector) RecordNonFiniteUpdate() {
+	m.NonFiniteUpdates++
+}
+
+// RecordSaturatedStep counts a step whose force was pinned at the output limit
+func (m *MetricsCollector) RecordSaturatedStep() {
+	m.SaturatedSteps++
+}
+
 // RecordWeightUpdate adds a weight update to the history
 func (m *MetricsCollector) RecordWeightUpdate(angle, angularVel, bias float64) {
 	m.WeightUpdates = append(m.WeightUpdates, WeightUpdate{
@@ -0,0 +1,232 @@
+package training
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+// Anomaly kinds reported by the Monitor
+const (
+	Stagnation     = "stagnation"      // Weights have stopped changing
+	RewardCollapse = "reward_collapse" // Mean reward fell far below its best
+	Oscillation    = "oscillation"     // Weight changes keep reversing direction
+	Saturation     = "saturation"      // The tanh output is pinned at ±1
+)
+
+// Monitor actions taken when an anomaly is detected
+const (
+	MonitorLogOnly  = "none"      // Log and notify callbacks only
+	MonitorAdjustLR = "adjust-lr" // Raise the learning rate on stagnation, lower it otherwise
+	MonitorRollback = "rollback"  // Restore the best weights on a reward collapse
+)
+
+// Anomaly is a learning problem found by the Monitor
+type Anomaly struct {
+	Kind    string
+	Episode int
+	Value   float64 // The measurement that crossed the threshold
+	Message string
+}
+
+// Monitor is the online counterpart of metrics.DetectLearningIssues: it
+// keeps a sliding window of episode rewards, end-of-episode weights and
+// saturated steps so the trainer can check for problems while it runs
+type Monitor struct {
+	config     Config
+	rewards    []float64   // Rewards over the last MonitorWindow episodes
+	weights    [][]float64 // Weights at the end of each of those episodes
+	saturation []float64   // Fraction of saturated steps in each of those episodes
+	bestReward float64     // Best windowed mean reward so far
+	scored     bool        // Whether bestReward has been set
+}
+
+// NewMonitor creates a monitor with an empty window
+func NewMonitor(config Config) *Monitor {
+	return &Monitor{config: config}
+}
+
+// RecordEpisode adds an episode's total reward, final weights and fraction
+// of saturated steps to the window
+func (m *Monitor) RecordEpisode(reward float64, weights []float64, saturation float64) {
+	window := max(2, m.config.MonitorWindow)
+	m.rewards = append(m.rewards, reward)
+	m.weights = append(m.weights, append([]float64(nil), weights...))
+	m.saturation = append(m.saturation, saturation)
+	if len(m.rewards) > window {
+		m.rewards = m.rewards[1:]
+		m.weights = m.weights[1:]
+		m.saturation = m.saturation[1:]
+	}
+}
+
+// Check looks for anomalies in the current window. It needs a full window
+// of episodes before it reports anything
+func (m *Monitor) Check(episode int) []Anomaly {
+	if len(m.rewards) < max(2, m.config.MonitorWindow) {
+		return nil
+	}
+
+	var anomalies []Anomaly
+	report := func(kind string, value float64, format string, args ...interface{}) {
+		anomalies = append(anomalies, Anomaly{
+			Kind:    kind,
+			Episode: episode,
+			Value:   value,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	meanReward := mean(m.rewards)
+	if !m.scored || meanReward > m.bestReward {
+		m.bestReward = meanReward
+		m.scored = true
+	} else if m.bestReward > 0 && meanReward < m.bestReward*(1-m.config.CollapseDrop) {
+		report(RewardCollapse, meanReward, "mean reward %.4f is %.0f%% below the best %.4f",
+			meanReward, 100*(1-meanReward/m.bestReward), m.bestReward)
+	}
+
+	change, reversals := m.weightChanges()
+	switch {
+	case change < m.config.StagnationThresh:
+		report(Stagnation, change, "mean weight change %.6f per episode is below %.6f",
+			change, m.config.StagnationThresh)
+	case reversals >= m.config.OscillationRate:
+		report(Oscillation, reversals, "%.0f%% of weight changes reversed direction", reversals*100)
+	}
+
+	if saturated := mean(m.saturation); saturated >= m.config.SaturationThresh {
+		report(Saturation, saturated, "%.0f%% of steps used the full output force", saturated*100)
+	}
+
+	return anomalies
+}
+
+// weightChanges returns the mean absolute weight change per episode and the
+// fraction of consecutive changes that reversed direction
+func (m *Monitor) weightChanges() (float64, float64) {
+	total, changes := 0.0, 0
+	reversals, pairs := 0, 0
+	for i := 1; i < len(m.weights); i++ {
+		for j := range m.weights[i] {
+			delta := m.weights[i][j] - m.weights[i-1][j]
+			total += math.Abs(delta)
+			changes++
+			if i < 2 {
+				continue
+			}
+			previous := m.weights[i-1][j] - m.weights[i-2][j]
+			if delta == 0 || previous == 0 {
+				continue
+			}
+			pairs++
+			if (delta > 0) != (previous > 0) {
+				reversals++
+			}
+		}
+	}
+
+	if changes == 0 {
+		return 0, 0
+	}
+	rate := 0.0
+	if pairs > 0 {
+		rate = float64(reversals) / float64(pairs)
+	}
+	return total / float64(changes), rate
+}
+
+// MeanReward returns the mean reward over the current window
+func (m *Monitor) MeanReward() float64 {
+	return mean(m.rewards)
+}
+
+// OnAnomaly registers a callback run for every anomaly the trainer detects
+func (t *Trainer) OnAnomaly(handler func(Anomaly)) {
+	t.anomalyHandlers = append(t.anomalyHandlers, handler)
+}
+
+// saturated reports whether a continuous force is pinned at the output
+// limit. Bang-bang and discrete forces sit at the limit by design
+func (t *Trainer) saturated(force float64) bool {
+	space := t.network.GetActionSpace()
+	if space.Type != neural.ContinuousActions || space.MaxForce <= 0 {
+		return false
+	}
+	return math.Abs(force) >= 0.99*space.MaxForce
+}
+
+// monitorEpisode records the finished episode and, every MonitorInterval
+// episodes, checks for anomalies and reacts according to MonitorAction
+func (t *Trainer) monitorEpisode() {
+	saturation := 0.0
+	if t.metrics.ExperienceCount > 0 {
+		saturation = float64(t.metrics.SaturatedSteps) / float64(t.metrics.ExperienceCount)
+	}
+	t.monitor.RecordEpisode(t.metrics.TotalReward, t.network.GetWeights(), saturation)
+	if (t.episode+1)%t.config.MonitorInterval != 0 {
+		return
+	}
+
+	anomalies := t.monitor.Check(t.episode)
+	if t.config.MonitorAction == MonitorRollback && !t.config.AutoRollback && !hasAnomaly(anomalies, RewardCollapse) {
+		// Windows that have not collapsed provide the weights to roll back to
+		t.SnapshotWeights(t.monitor.MeanReward())
+	}
+
+	for _, anomaly := range anomalies {
+		t.logger.Printf("[Monitor] Episode %d: %s: %s", anomaly.Episode, anomaly.Kind, anomaly.Message)
+		for _, handler := range t.anomalyHandlers {
+			handler(anomaly)
+		}
+		t.respondToAnomaly(anomaly)
+	}
+}
+
+// respondToAnomaly applies the configured MonitorAction to an anomaly
+func (t *Trainer) respondToAnomaly(anomaly Anomaly) {
+	switch t.config.MonitorAction {
+	case MonitorAdjustLR:
+		// Scale the scheduled rate, so the adjustment lasts as the schedule moves on
+		previous := t.learningRate
+		if anomaly.Kind == Stagnation {
+			t.rateScale = math.Min(t.rateScale*2, t.config.BaseLearningRate/t.scheduledRate)
+		} else {
+			t.rateScale = math.Max(t.rateScale*0.5, t.config.MinLearningRate/t.scheduledRate)
+		}
+		t.learningRate = t.scheduledRate * t.rateScale
+		if t.learningRate != previous {
+			t.logger.Printf("[Monitor] Learning rate %.6f -> %.6f", previous, t.learningRate)
+		}
+	case MonitorRollback:
+		if anomaly.Kind != RewardCollapse || t.best == nil {
+			return
+		}
+		if err := t.RollbackToBest(); err != nil {
+			t.logger.Printf("[Monitor] Rollback failed: %v", err)
+		}
+	}
+}
+
+// hasAnomaly reports whether anomalies include one of the given kind
+func hasAnomaly(anomalies []Anomaly, kind string) bool {
+	for _, a := range anomalies {
+		if a.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// mean returns the average of values, or 0 for none
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
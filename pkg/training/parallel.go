@@ -0,0 +1,64 @@
+package training
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+)
+
+// minParallelChunk is the fewest experiences worth a goroutine of their own
+const minParallelChunk = 64
+
+// experienceGradients returns each experience's gradient of
+// [angleWeight, angularVelWeight, bias]: its TD error against its λ-return,
+// times its inputs, signed by its action. With ParallelGradients, batches of
+// at least ParallelMinBatch are split across up to GOMAXPROCS goroutines,
+// which evaluate the value estimates without recording them. The gradients
+// are valid until the next call
+func (t *Trainer) experienceGradients(experiences []Experience, targets []float64) [][3]float64 {
+	n := len(experiences)
+	if cap(t.expGrads) < n {
+		t.expGrads = make([][3]float64, n)
+	}
+	grads := t.expGrads[:n]
+
+	workers := min(runtime.GOMAXPROCS(0), n/minParallelChunk)
+	if !t.config.ParallelGradients || n < t.config.ParallelMinBatch || workers < 2 {
+		for i, exp := range experiences {
+			value := t.network.Predict(exp.State.AngleRadians, exp.State.AngularVel)
+			grads[i] = experienceGradient(exp, targets[i], value)
+		}
+		return grads
+	}
+
+	// Each worker fills a contiguous range; the caller reduces in order
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*n/workers, (w+1)*n/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				exp := experiences[i]
+				value := t.network.Value(exp.State.AngleRadians, exp.State.AngularVel)
+				grads[i] = experienceGradient(exp, targets[i], value)
+			}
+		}()
+	}
+	wg.Wait()
+	return grads
+}
+
+// experienceGradient returns one experience's contribution to the batch
+// gradient. The angle input is the signed offset from upright, so a state just
+// past the wrap, stored near 2π, counts as a small negative angle
+func experienceGradient(exp Experience, target, value float64) [3]float64 {
+	actionSign := sign(exp.Action)
+	tdError := target - value
+	return [3]float64{
+		tdError * env.UprightOffset(exp.State.AngleRadians) * actionSign,
+		tdError * exp.State.AngularVel * actionSign,
+		tdError * actionSign,
+	}
+}
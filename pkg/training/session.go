@@ -0,0 +1,218 @@
+package training
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
+)
+
+// SessionVersion is the current session checkpoint format
+const SessionVersion = 1
+
+// SessionState is everything needed to resume a training session where it
+// stopped. Weight-only checkpoints restart counters, schedules and noise;
+// a session checkpoint continues them
+type SessionState struct {
+	Version   int       `json:"version"`
+	SavedAt   time.Time `json:"saved_at"`
+	SessionID string    `json:"session_id,omitempty"` // Metrics session to keep appending to
+
+	Network         neural.NetworkState      `json:"network"`
+	Episode         int                      `json:"episode"`
+	TotalEpisodes   int                      `json:"total_episodes"`
+	SuccessCount    int                      `json:"success_count"`
+	BestDuration    float64                  `json:"best_duration"`
+	LearningRate    float64                  `json:"learning_rate"`
+	RateScale       float64                  `json:"rate_scale,omitempty"` // Monitor adjustment included in LearningRate
+	Optimizer       *neural.OptimizerState   `json:"optimizer,omitempty"`
+	RewardScale     *neural.RewardScaleState `json:"reward_scale,omitempty"` // Trainer's return statistics when RewardScaling is set
+	Pending         []Experience             `json:"pending,omitempty"`      // Experiences not yet in a processed batch
+	Best            *WeightSnapshot          `json:"best,omitempty"`
+	Rollbacks       int                      `json:"rollbacks"`
+	RecentDurations []float64                `json:"recent_durations,omitempty"`
+	Stopper         *StopperState            `json:"stopper,omitempty"`
+	RNG             *RandState               `json:"rng,omitempty"`
+	Budget          *metrics.ComputeBudget   `json:"budget,omitempty"`
+
+	// Progress holds caller-specific state, e.g. a command's checkpoint index
+	Progress json.RawMessage `json:"progress,omitempty"`
+}
+
+// SessionState captures the trainer and its network. Callers fill in the
+// metrics session ID, RNG and progress they own
+func (t *Trainer) SessionState() SessionState {
+	optimizer := t.optimizer.State()
+	stopper := t.stopper.State()
+	budget := t.Budget()
+	state := SessionState{
+		Version:         SessionVersion,
+		SavedAt:         time.Now(),
+		Network:         t.network.State(),
+		Episode:         t.episode,
+		TotalEpisodes:   t.totalEpisodes,
+		SuccessCount:    t.successCount,
+		BestDuration:    t.bestDuration,
+		LearningRate:    t.learningRate,
+		RateScale:       t.rateScale,
+		Optimizer:       &optimizer,
+		Pending:         append([]Experience(nil), t.batch.Experiences...),
+		Rollbacks:       t.rollbacks,
+		RecentDurations: append([]float64(nil), t.recentDurations...),
+		Stopper:         &stopper,
+		Budget:          &budget,
+	}
+	if t.rewardScaler != nil {
+		rewardScale := t.rewardScaler.State()
+		state.RewardScale = &rewardScale
+	}
+	if t.best != nil {
+		best := *t.best
+		state.Best = &best
+	}
+	return state
+}
+
+// RestoreSession puts the trainer and its network back into a saved state
+func (t *Trainer) RestoreSession(state SessionState) error {
+	if state.Version > SessionVersion {
+		return fmt.Errorf("session checkpoint version %d is newer than supported version %d", state.Version, SessionVersion)
+	}
+
+	if err := t.network.RestoreState(state.Network); err != nil {
+		return fmt.Errorf("failed to restore network: %w", err)
+	}
+	if state.Optimizer != nil {
+		optimizer, err := neural.RestoreOptimizer(*state.Optimizer)
+		if err != nil {
+			return fmt.Errorf("failed to restore optimizer: %w", err)
+		}
+		t.optimizer = optimizer
+	}
+	if state.RewardScale != nil && t.config.RewardScaling {
+		t.rewardScaler = neural.RestoreRewardScaler(*state.RewardScale)
+	}
+	if state.Stopper != nil {
+		t.stopper.Restore(*state.Stopper)
+	}
+	if state.Budget != nil {
+		t.budget = *state.Budget
+		t.episodeStart = time.Time{}
+	}
+
+	t.episode = state.Episode
+	t.totalEpisodes = state.TotalEpisodes
+	t.successCount = state.SuccessCount
+	t.bestDuration = state.BestDuration
+	t.setLearningRate(state.LearningRate)
+	if state.RateScale > 0 {
+		t.rateScale = state.RateScale
+		t.scheduledRate = state.LearningRate / state.RateScale
+	}
+	t.batch.Experiences = append(t.batch.Experiences[:0], state.Pending...)
+	t.best = state.Best
+	t.rollbacks = state.Rollbacks
+	t.recentDurations = append([]float64(nil), state.RecentDurations...)
+	t.metrics = NewMetricsCollector(t.episode)
+
+	t.logger.Printf("[Trainer] Resumed session at episode %d (learning rate %.4f)", t.episode, t.learningRate)
+	return nil
+}
+
+// SaveSession writes a session checkpoint, replacing any existing file atomically
+func SaveSession(path string, state SessionState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	// Write then rename so an interrupted save never leaves a torn checkpoint
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace session: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSession reads a session checkpoint written by SaveSession
+func LoadSession(path string) (SessionState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SessionState{}, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var state SessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return SessionState{}, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+	if state.Version == 0 || len(state.Network.Weights) == 0 {
+		return SessionState{}, fmt.Errorf("%s is not a session checkpoint", path)
+	}
+
+	return state, nil
+}
+
+// RandState records a RandSource's seed and how far it has advanced
+type RandState struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+}
+
+// RandSource is a rand.Source that counts its draws, so its exact position
+// in the random stream can be saved and restored
+type RandSource struct {
+	seed  int64
+	draws uint64
+	src   rand.Source64
+}
+
+// NewRandSource creates a counting source seeded with seed
+func NewRandSource(seed int64) *RandSource {
+	return &RandSource{seed: seed, src: rand.NewSource(seed).(rand.Source64)}
+}
+
+// RestoreRandSource recreates a source at the position recorded in state
+func RestoreRandSource(state RandState) *RandSource {
+	s := NewRandSource(state.Seed)
+	for s.draws < state.Draws {
+		s.Uint64()
+	}
+	return s
+}
+
+// Int63 implements rand.Source
+func (s *RandSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+// Uint64 implements rand.Source64
+func (s *RandSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+// Seed implements rand.Source, restarting the stream
+func (s *RandSource) Seed(seed int64) {
+	s.seed = seed
+	s.draws = 0
+	s.src.Seed(seed)
+}
+
+// State returns the source's seed and draw count
+func (s *RandSource) State() RandState {
+	return RandState{Seed: s.seed, Draws: s.draws}
+}
@@ -0,0 +1,111 @@
+package training
+
+import (
+	"fmt"
+)
+
+// WeightSnapshot is a copy of the network weights and the score they achieved
+type WeightSnapshot struct {
+	Episode          int
+	Score            float64
+	Weights          []float64
+	FeatureWeights   []float64
+	RecurrentWeights []float64
+	LearningRate     float64
+}
+
+// SnapshotWeights remembers the current weights if score beats the best
+// snapshot so far. The score can come from any evaluation where higher is
+// better, e.g. an eval suite's average reward. Returns true for a new best
+func (t *Trainer) SnapshotWeights(score float64) bool {
+	if t.best != nil && score <= t.best.Score {
+		return false
+	}
+
+	t.best = &WeightSnapshot{
+		Episode:          t.episode,
+		Score:            score,
+		Weights:          t.network.GetWeights(),
+		FeatureWeights:   t.network.GetFeatureWeights(),
+		RecurrentWeights: t.network.GetRecurrentWeights(),
+		LearningRate:     t.learningRate,
+	}
+	t.logger.Printf("[Trainer] Snapshot of best weights at episode %d (score %.4f)", t.episode, score)
+	return true
+}
+
+// BestSnapshot returns the best snapshot so far, if any
+func (t *Trainer) BestSnapshot() (WeightSnapshot, bool) {
+	if t.best == nil {
+		return WeightSnapshot{}, false
+	}
+	return *t.best, true
+}
+
+// RollbackToBest restores the weights and learning rate of the best snapshot
+// and clears the optimizer's moments
+func (t *Trainer) RollbackToBest() error {
+	if t.best == nil {
+		return fmt.Errorf("no weight snapshot to roll back to")
+	}
+
+	if err := t.network.SetWeights(t.best.Weights); err != nil {
+		return fmt.Errorf("failed to restore weights: %w", err)
+	}
+	if len(t.best.FeatureWeights) > 0 {
+		if err := t.network.SetFeatureWeights(t.best.FeatureWeights); err != nil {
+			return fmt.Errorf("failed to restore feature weights: %w", err)
+		}
+	}
+	if len(t.best.RecurrentWeights) > 0 {
+		if err := t.network.SetRecurrentWeights(t.best.RecurrentWeights); err != nil {
+			return fmt.Errorf("failed to restore recurrent weights: %w", err)
+		}
+	}
+	t.setLearningRate(t.best.LearningRate)
+
+	// Momentum built up on the way to the collapse would carry the restored
+	// weights straight back toward it
+	t.optimizer.Reset()
+	t.rollbacks++
+
+	t.logger.Printf("[Trainer] Rolled back to weights from episode %d (score %.4f)", t.best.Episode, t.best.Score)
+	return nil
+}
+
+// Rollbacks returns how many times the weights have been rolled back
+func (t *Trainer) Rollbacks() int {
+	return t.rollbacks
+}
+
+// autoRollback scores recent episodes by their mean duration, snapshots
+// the weights when that score is a new best, and rolls back when it
+// collapses below RollbackDrop of the best
+func (t *Trainer) autoRollback(duration float64) {
+	t.recentDurations = append(t.recentDurations, duration)
+	if len(t.recentDurations) < t.config.RollbackWindow {
+		return
+	}
+	if len(t.recentDurations) > t.config.RollbackWindow {
+		t.recentDurations = t.recentDurations[1:]
+	}
+
+	score := 0.0
+	for _, d := range t.recentDurations {
+		score += d
+	}
+	score /= float64(len(t.recentDurations))
+
+	if t.SnapshotWeights(score) {
+		return
+	}
+	if score < t.best.Score*t.config.RollbackDrop {
+		t.logger.Printf("[Trainer] Performance collapsed: mean duration %.2fs vs best %.2fs", score, t.best.Score)
+		if err := t.RollbackToBest(); err != nil {
+			t.logger.Printf("[Trainer] Rollback failed: %v", err)
+			return
+		}
+		// Judge the restored weights on fresh episodes only
+		t.recentDurations = t.recentDurations[:0]
+	}
+}
@@ -0,0 +1,112 @@
+package training
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stopper decides when training can end early: once the task is solved,
+// once reward stops improving, or once the time budget runs out
+type Stopper struct {
+	config     Config
+	start      time.Time
+	successes  []bool    // Outcomes over the last StopWindow episodes
+	rewards    []float64 // Rewards over the last StopWindow episodes
+	bestReward float64   // Best windowed mean reward so far
+	scored     bool      // Whether bestReward has been set
+	sinceBest  int       // Episodes since bestReward improved
+	reason     string
+}
+
+// NewStopper creates a stopper whose time budget starts now
+func NewStopper(config Config) *Stopper {
+	return &Stopper{
+		config: config,
+		start:  time.Now(),
+	}
+}
+
+// RecordEpisode adds an episode outcome to the stopping criteria
+func (s *Stopper) RecordEpisode(reward float64, success bool) {
+	window := max(1, s.config.StopWindow)
+	s.successes = append(s.successes, success)
+	s.rewards = append(s.rewards, reward)
+	if len(s.successes) > window {
+		s.successes = s.successes[1:]
+		s.rewards = s.rewards[1:]
+	}
+	if len(s.successes) < window {
+		return
+	}
+
+	successes, meanReward := 0, 0.0
+	for i, ok := range s.successes {
+		if ok {
+			successes++
+		}
+		meanReward += s.rewards[i] / float64(window)
+	}
+
+	if !s.scored || meanReward > s.bestReward+s.config.PlateauMinDelta {
+		s.bestReward = meanReward
+		s.sinceBest = 0
+		s.scored = true
+	} else {
+		s.sinceBest++
+	}
+
+	rate := float64(successes) / float64(window)
+	switch {
+	case s.config.StopSuccessRate > 0 && rate >= s.config.StopSuccessRate:
+		s.reason = fmt.Sprintf("success rate %.1f%% over the last %d episodes reached the %.1f%% target",
+			rate*100, window, s.config.StopSuccessRate*100)
+	case s.config.PlateauEpisodes > 0 && s.sinceBest >= s.config.PlateauEpisodes:
+		s.reason = fmt.Sprintf("mean reward plateaued at %.4f for %d episodes", s.bestReward, s.sinceBest)
+	}
+}
+
+// ShouldStop reports whether any stopping criterion has been met, and why
+func (s *Stopper) ShouldStop() (bool, string) {
+	if s.reason != "" {
+		return true, s.reason
+	}
+	if s.config.TimeBudget > 0 && time.Since(s.start) >= s.config.TimeBudget {
+		return true, fmt.Sprintf("time budget of %v exhausted", s.config.TimeBudget)
+	}
+	return false, ""
+}
+
+// StopperState is a serializable copy of a Stopper's progress
+type StopperState struct {
+	Elapsed    time.Duration `json:"elapsed"` // Time budget already spent
+	Successes  []bool        `json:"successes,omitempty"`
+	Rewards    []float64     `json:"rewards,omitempty"`
+	BestReward float64       `json:"best_reward"`
+	Scored     bool          `json:"scored"`
+	SinceBest  int           `json:"since_best"`
+}
+
+// State returns the stopper's progress for a session checkpoint
+func (s *Stopper) State() StopperState {
+	return StopperState{
+		Elapsed:    time.Since(s.start),
+		Successes:  append([]bool(nil), s.successes...),
+		Rewards:    append([]float64(nil), s.rewards...),
+		BestReward: s.bestReward,
+		Scored:     s.scored,
+		SinceBest:  s.sinceBest,
+	}
+}
+
+// Restore continues from a saved state. The time already spent still counts
+// against the budget; the criteria are checked again from the next episode
+// so a resumed run can extend them
+func (s *Stopper) Restore(state StopperState) {
+	s.start = time.Now().Add(-state.Elapsed)
+	s.successes = append([]bool(nil), state.Successes...)
+	s.rewards = append([]float64(nil), state.Rewards...)
+	s.bestReward = state.BestReward
+	s.scored = state.Scored
+	s.sinceBest = state.SinceBest
+	s.reason = ""
+}
@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
+	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/eval"
+	applog "github.com/zachbeta/go_inverted_pendulum/pkg/logger"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/metrics"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
 )
 
@@ -18,14 +22,38 @@ type Trainer struct {
 	network        *neural.Network
 	logger         *log.Logger
 	batch          *Batch
+	targets        []float64  // λ-returns of the batch, reused by every batch
+	rewards        []float64  // Rewards the batch learns from, reused by every batch
+	rewardScaler   *neural.RewardScaler // Running return statistics when RewardScaling is set
+	signals        []float64  // Recurrent TD signals of the batch, reused by every batch
+	expGrads       [][3]float64 // Per-experience gradients of the batch, reused by every batch
+	gradBuf        [3]float64 // Batch gradient of [angleWeight, angularVelWeight, bias]
+	weightBuf      [3]float64 // Weights the batch update proposes
 	metrics        *MetricsCollector
 	episode        int
-	learningRate   float64
+	learningRate   float64 // Rate of the running episode: scheduledRate scaled by rateScale
+	schedule       neural.Schedule // Sets scheduledRate at the end of each episode
+	scheduleName   string
+	scheduledRate  float64
+	rateScale      float64 // Adjustment the monitor applies on top of the schedule
 	totalEpisodes  int
 	successCount   int     // Episodes where pendulum stayed upright
 	bestDuration   float64 // Best upright duration in seconds
 	checkpointDir  string  // Directory for saving checkpoints
 	lastCheckpoint time.Time // Time of last checkpoint save
+	best           *WeightSnapshot // Best-scoring weights for rollback
+	rollbacks      int
+	recentDurations []float64 // Episode durations scored for automatic rollback
+	stopper        *Stopper  // Early stopping criteria
+	optimizer      neural.Optimizer // Turns batch gradients into weight steps
+	sampler        *applog.Sampler  // Decides which batch and episode summaries are logged
+	monitor        *Monitor         // Online anomaly detection
+	anomalyHandlers []func(Anomaly)
+	budget         metrics.ComputeBudget // Effort spent on finished episodes
+	episodeStart   time.Time             // First experience of the running episode
+	evalSuite      eval.Suite            // Scenarios for periodic greedy evaluations
+	evaluations    []EvalResult
+	evalHandlers   []func(EvalResult)
 }
 
 // NewTrainer creates a new trainer with the given config
@@ -34,6 +62,35 @@ func NewTrainer(config Config, network *neural.Network, logger *log.Logger) *Tra
 		logger = log.Default()
 	}
 
+	// Keep the network's online TD(λ) updates consistent with batch targets
+	network.SetDiscount(config.Gamma)
+	network.SetTraceDecay(config.Lambda)
+	network.SetMaxGradNorm(config.MaxGradNorm)
+	network.SetSuccessCriteria(config.Success)
+	if config.ActionSpace.Type != "" {
+		network.SetActionSpace(config.ActionSpace)
+	}
+
+	optimizer, err := neural.NewOptimizer(config.OptimizerConfig())
+	if err != nil {
+		logger.Printf("[Trainer] %v; falling back to SGD", err)
+		optimizer, _ = neural.NewOptimizer(neural.NewDefaultOptimizerConfig())
+	}
+
+	scheduleName := config.LRSchedule
+	schedule, err := neural.NewSchedule(config.ScheduleConfig())
+	if err != nil {
+		logger.Printf("[Trainer] %v; keeping the learning rate constant", err)
+		scheduleName = neural.ConstantSchedule
+		schedule, _ = neural.NewSchedule(neural.ScheduleConfig{Name: scheduleName, Base: NewDefaultConfig().BaseLearningRate})
+	}
+	learningRate := schedule.Rate(0, 0, 0)
+
+	var rewardScaler *neural.RewardScaler
+	if config.RewardScaling {
+		rewardScaler = neural.NewRewardScaler(config.Gamma)
+	}
+
 	return &Trainer{
 		config:        config,
 		network:      network,
@@ -41,9 +98,19 @@ func NewTrainer(config Config, network *neural.Network, logger *log.Logger) *Tra
 		batch:        &Batch{Experiences: make([]Experience, 0, config.BatchSize)},
 		metrics:      NewMetricsCollector(0),
 		episode:      0,
-		learningRate: config.BaseLearningRate,
+		learningRate: learningRate,
+		schedule:     schedule,
+		scheduleName: scheduleName,
+		scheduledRate: learningRate,
+		rateScale:    1,
 		checkpointDir: "checkpoints", // Default directory
 		lastCheckpoint: time.Now(),
+		stopper:       NewStopper(config),
+		optimizer:     optimizer,
+		sampler:       applog.NewSampler(config.LogSampling),
+		monitor:       NewMonitor(config),
+		evalSuite:     eval.StandardSuite(),
+		rewardScaler:  rewardScaler,
 	}
 }
 
@@ -54,8 +121,18 @@ func (t *Trainer) SetCheckpointDirectory(dir string) {
 
 // AddExperience adds a new experience to the current batch
 func (t *Trainer) AddExperience(exp Experience) {
+	// Each experience is one simulated step and one forward pass to act
+	if t.episodeStart.IsZero() {
+		t.episodeStart = time.Now()
+	}
+	t.budget.Steps++
+	t.budget.ForwardPasses++
+
 	// Record metrics
 	t.metrics.RecordExperience(exp)
+	if t.saturated(exp.Action) {
+		t.metrics.RecordSaturatedStep()
+	}
 
 	// Initialize batch if needed
 	if len(t.batch.Experiences) == 0 {
@@ -71,6 +148,17 @@ func (t *Trainer) AddExperience(exp Experience) {
 	}
 }
 
+// Act returns the trained network's force for state. With Observe it makes
+// the trainer an agent.Learner
+func (t *Trainer) Act(state env.State) float64 {
+	return t.network.Forward(state)
+}
+
+// Observe adds a transition to the current batch, implementing agent.Learner
+func (t *Trainer) Observe(exp Experience) {
+	t.AddExperience(exp)
+}
+
 // processBatch applies the batch update to the network weights using backpropagation
 func (t *Trainer) processBatch() {
 	if len(t.batch.Experiences) == 0 {
@@ -80,44 +168,33 @@ func (t *Trainer) processBatch() {
 	t.batch.EndTime = time.Now()
 
 	// Calculate average reward and gradients for the batch
-	var totalReward float64
+	var totalReward, totalTarget float64
 	angleGrad := 0.0
 	angularVelGrad := 0.0
 	biasGrad := 0.0
 
+	// Compute λ-return targets while experiences are still in time order
+	targets := t.lambdaReturns(t.batch.Experiences)
+	t.updateRecurrent(t.batch.Experiences, targets)
+
 	// Progressive learning: Focus on experiences with better rewards
-	sortExperiencesByReward(t.batch.Experiences)
+	sortExperiencesByReward(t.batch.Experiences, targets)
 	effectiveBatchSize := int(float64(len(t.batch.Experiences)) * 0.8) // Use top 80% of experiences
 	if effectiveBatchSize < 1 {
 		effectiveBatchSize = 1
 	}
 
-	// Apply backpropagation with momentum
-	momentum := 0.9
-	prevAngleGrad := 0.0
-	prevAngularVelGrad := 0.0
-	prevBiasGrad := 0.0
-
-	for i := 0; i < effectiveBatchSize; i++ {
-		exp := t.batch.Experiences[i]
-		actionSign := sign(exp.Action)
-
-		// Calculate gradients with temporal difference
-		nextValue := t.network.Predict(exp.NextState.AngleRadians, exp.NextState.AngularVel)
-		currentValue := t.network.Predict(exp.State.AngleRadians, exp.State.AngularVel)
-		tdError := exp.Reward + 0.99*nextValue - currentValue // 0.99 is discount factor
-
-		// Compute gradients with momentum
-		angleGrad = momentum*prevAngleGrad + (1-momentum)*tdError*exp.State.AngleRadians*actionSign
-		angularVelGrad = momentum*prevAngularVelGrad + (1-momentum)*tdError*exp.State.AngularVel*actionSign
-		biasGrad = momentum*prevBiasGrad + (1-momentum)*tdError*actionSign
-
-		prevAngleGrad = angleGrad
-		prevAngularVelGrad = angularVelGrad
-		prevBiasGrad = biasGrad
-
-		totalReward += exp.Reward
+	// Sum in experience order, so the parallel path gives identical results
+	grads := t.experienceGradients(t.batch.Experiences[:effectiveBatchSize], targets)
+	for i, g := range grads {
+		totalTarget += targets[i]
+		angleGrad += g[0]
+		angularVelGrad += g[1]
+		biasGrad += g[2]
+		totalReward += t.batch.Experiences[i].Reward
 	}
+	t.budget.ForwardPasses += int64(effectiveBatchSize)
+	t.budget.BackwardPasses += int64(effectiveBatchSize)
 
 	// Average the gradients
 	batchSize := float64(effectiveBatchSize)
@@ -125,24 +202,94 @@ func (t *Trainer) processBatch() {
 	angularVelGrad /= batchSize
 	biasGrad /= batchSize
 
-	// Apply gradients with adaptive learning rate
+	// Limit the size of a single step
+	t.gradBuf = [3]float64{angleGrad, angularVelGrad, biasGrad}
+	grad := t.gradBuf[:]
+	if neural.ClipGradNorm(grad, t.config.MaxGradNorm) {
+		t.metrics.RecordGradientClip()
+	}
+
+	// Apply the optimizer step with adaptive learning rate
+	step := t.optimizer.Step(grad, t.learningRate)
 	weights := t.network.GetWeights()
-	newWeights := []float64{
-		clip(weights[0]+t.learningRate*angleGrad, t.config.WeightClipMin, t.config.WeightClipMax),
-		clip(weights[1]+t.learningRate*angularVelGrad, t.config.WeightClipMin, t.config.WeightClipMax),
-		clip(weights[2]+t.learningRate*biasGrad, t.config.WeightClipMin, t.config.WeightClipMax),
+	t.weightBuf = [3]float64{
+		clip(weights[0]+step[0], t.config.WeightClipMin, t.config.WeightClipMax),
+		clip(weights[1]+step[1], t.config.WeightClipMin, t.config.WeightClipMax),
+		clip(weights[2]+step[2], t.config.WeightClipMin, t.config.WeightClipMax),
+	}
+	newWeights := t.weightBuf[:]
+
+	// Never let NaN or Inf reach the network
+	if !neural.AllFinite(newWeights) {
+		t.optimizer.Reset()
+		t.recoverFromNonFinite(newWeights)
+		t.batch.Experiences = t.batch.Experiences[:0]
+		return
 	}
 
 	// Update network weights and record metrics
 	t.network.SetWeights(newWeights)
 	t.metrics.RecordWeightUpdate(newWeights[0], newWeights[1], newWeights[2])
 	t.metrics.RecordBatchProcessed()
+	t.metrics.RecordLambdaReturn(totalTarget / float64(effectiveBatchSize))
 
 	// Log batch results with clear formatting
+	if t.sampler.Step() {
+		t.logBatchSummary(effectiveBatchSize, totalReward, totalTarget, grad, newWeights)
+	}
+
+	// Reset batch
+	t.batch.Experiences = t.batch.Experiences[:0]
+}
+
+// updateRecurrent trains the network's recurrent cell, if any, by truncated
+// backpropagation through time over the batch. Experiences must be in time
+// order and match the network's latest forward passes. Like the other
+// weights, each step is pushed toward its action in proportion to its TD
+// error against the λ-return
+func (t *Trainer) updateRecurrent(experiences []Experience, targets []float64) {
+	if !t.network.Recurrent() {
+		return
+	}
+
+	t.signals = resize(t.signals, len(experiences))
+	signals := t.signals
+	for i, exp := range experiences {
+		tdError := targets[i] - t.network.Predict(exp.State.AngleRadians, exp.State.AngularVel)
+		signals[i] = tdError * sign(exp.Action)
+	}
+	grad := t.network.RecurrentGradient(signals, t.config.BPTTWindow)
+	for i := range grad {
+		grad[i] /= float64(len(experiences))
+	}
+	t.budget.ForwardPasses += int64(len(experiences))
+	t.budget.BackwardPasses += int64(len(experiences))
+	if neural.ClipGradNorm(grad, t.config.MaxGradNorm) {
+		t.metrics.RecordGradientClip()
+	}
+
+	weights := t.network.GetRecurrentWeights()
+	for i := range weights {
+		weights[i] = clip(weights[i]+t.learningRate*grad[i], t.config.WeightClipMin, t.config.WeightClipMax)
+	}
+	if !neural.AllFinite(weights) {
+		t.metrics.RecordNonFiniteUpdate()
+		t.logger.Printf("[Trainer] Rejected non-finite recurrent update at episode %d", t.episode)
+		return
+	}
+	t.network.SetRecurrentWeights(weights)
+}
+
+// logBatchSummary prints the outcome of a batch update
+func (t *Trainer) logBatchSummary(effectiveBatchSize int, totalReward, totalTarget float64, grad, newWeights []float64) {
+	batchSize := float64(effectiveBatchSize)
+	angleGrad, angularVelGrad, biasGrad := grad[0], grad[1], grad[2]
+
 	t.logger.Printf("\n[Trainer] Batch Update Summary:")
 	t.logger.Printf("├── Episode: %d", t.episode)
 	t.logger.Printf("├── Batch Size: %d (effective: %d)", len(t.batch.Experiences), effectiveBatchSize)
 	t.logger.Printf("├── Average Reward: %.4f", totalReward/batchSize)
+	t.logger.Printf("├── Average λ-Return: %.4f (γ=%.2f, λ=%.2f)", totalTarget/batchSize, t.config.Gamma, t.config.Lambda)
 	t.logger.Printf("├── Learning Rate: %.4f", t.learningRate)
 	t.logger.Printf("├── Weight Updates")
 	t.logger.Printf("│   ├── Angle: %.4f", angleGrad)
@@ -152,43 +299,128 @@ func (t *Trainer) processBatch() {
 	t.logger.Printf("    ├── Angle: %.4f", newWeights[0])
 	t.logger.Printf("    ├── Angular Velocity: %.4f", newWeights[1])
 	t.logger.Printf("    └── Bias: %.4f", newWeights[2])
+}
 
-	// Reset batch
-	t.batch.Experiences = t.batch.Experiences[:0]
+// recoverFromNonFinite handles a batch update that produced NaN or Inf
+// weights: it records the event and rolls back to the best snapshot if
+// there is one, otherwise it keeps the current weights
+func (t *Trainer) recoverFromNonFinite(weights []float64) {
+	t.metrics.RecordNonFiniteUpdate()
+	t.logger.Printf("[Trainer] Rejected non-finite weight update %v at episode %d", weights, t.episode)
+
+	if t.best == nil {
+		return
+	}
+	if err := t.RollbackToBest(); err != nil {
+		t.logger.Printf("[Trainer] Rollback failed: %v", err)
+	}
+}
+
+// lambdaReturns computes the TD(λ) target for each experience in the batch.
+// Experiences must be in time order; the recursion restarts at terminal
+// experiences and bootstraps from the value estimate at the end of the batch.
+// The targets are valid until the next call
+func (t *Trainer) lambdaReturns(experiences []Experience) []float64 {
+	gamma, lambda := t.config.Gamma, t.config.Lambda
+	t.targets = resize(t.targets, len(experiences))
+	targets := t.targets
+	rewards := t.scaledRewards(experiences)
+
+	next := 0.0
+	for i := len(experiences) - 1; i >= 0; i-- {
+		exp := experiences[i]
+		if exp.Done {
+			targets[i] = rewards[i]
+			next = targets[i]
+			continue
+		}
+
+		// Blend the one-step bootstrap with the return of the following step
+		nextValue := t.network.Predict(exp.NextState.AngleRadians, exp.NextState.AngularVel)
+		t.budget.ForwardPasses++
+		bootstrap := nextValue
+		if i < len(experiences)-1 {
+			bootstrap = (1-lambda)*nextValue + lambda*next
+		}
+
+		targets[i] = rewards[i] + gamma*bootstrap
+		next = targets[i]
+	}
+
+	return targets
+}
+
+// scaledRewards returns the rewards the batch learns from: divided by the
+// running std of the return when reward scaling is enabled, which also
+// records them, so each experience must be scaled once and in time order
+func (t *Trainer) scaledRewards(experiences []Experience) []float64 {
+	t.rewards = resize(t.rewards, len(experiences))
+	for i, exp := range experiences {
+		t.rewards[i] = exp.Reward
+		if t.rewardScaler != nil {
+			t.rewards[i] = t.rewardScaler.Scale(exp.Reward, exp.Done)
+		}
+	}
+	return t.rewards
 }
 
-// OnEpisodeEnd handles end-of-episode processing
-func (t *Trainer) OnEpisodeEnd(episodeTicks int) {
+// RewardScale returns the running std of the return that rewards are
+// divided by, 1 when reward scaling is disabled
+func (t *Trainer) RewardScale() float64 {
+	if t.rewardScaler == nil {
+		return 1
+	}
+	return t.rewardScaler.Std()
+}
+
+// OnEpisodeEnd handles end-of-episode processing and reports whether the
+// episode succeeded by the configured success criteria
+func (t *Trainer) OnEpisodeEnd(episodeTicks int) bool {
 	// Process any remaining experiences in the batch
 	t.processBatch()
+	if !t.episodeStart.IsZero() {
+		t.budget.WallClock += time.Since(t.episodeStart)
+		t.episodeStart = time.Time{}
+	}
 
 	// Calculate episode success metrics
 	duration := float64(episodeTicks) * t.config.DeltaTime
-	if t.metrics.MaxAngle < t.config.SuccessAngleThresh {
+	success := t.config.Success.Episode(t.metrics.MaxDeviation, duration)
+	t.stopper.RecordEpisode(t.metrics.TotalReward, success)
+	if success {
 		t.successCount++
 		if duration > t.bestDuration {
 			t.bestDuration = duration
-			t.logger.Printf("\n[Trainer] New Best Duration: %.2f seconds!", duration)
+			if t.sampler.Episode() {
+				t.logger.Printf("\n[Trainer] New Best Duration: %.2f seconds!", duration)
+			}
 		}
 	}
 
 	// Log episode summary
-	t.logger.Printf("\n%s", t.metrics.String())
-	if t.successCount > 0 {
+	if t.sampler.Episode() {
+		t.logger.Printf("\n%s", t.metrics.String())
+	}
+	if t.successCount > 0 && t.sampler.Episode() {
 		t.logger.Printf("Success Rate: %.1f%% (%d/%d episodes)",
 			100*float64(t.successCount)/float64(t.totalEpisodes+1),
 			t.successCount, t.totalEpisodes+1)
 	}
 
-	// Adaptive learning rate with success-based adjustment
+	// Schedule the learning rate of the next episode
 	successRate := float64(t.successCount) / float64(t.totalEpisodes+1)
-	if successRate > 0.7 {
-		// Reduce learning rate more aggressively when performing well
-		t.learningRate *= t.config.LearningRateDecay * 0.9
-	} else {
-		t.learningRate *= t.config.LearningRateDecay
+	t.scheduledRate = t.schedule.Rate(t.totalEpisodes+1, t.scheduledRate, successRate)
+	t.learningRate = t.scheduledRate * t.rateScale
+
+	// Keep the best weights and recover them if learning diverges
+	if t.config.AutoRollback {
+		t.autoRollback(duration)
+	}
+
+	// Watch for learning problems while training runs
+	if t.config.MonitorInterval > 0 {
+		t.monitorEpisode()
 	}
-	t.learningRate = math.Max(t.config.MinLearningRate, t.learningRate)
 
 	// Save checkpoint if needed
 	if t.episode%t.config.CheckpointInterval == 0 || 
@@ -201,6 +433,36 @@ func (t *Trainer) OnEpisodeEnd(episodeTicks int) {
 	t.episode++
 	t.totalEpisodes++
 	t.metrics = NewMetricsCollector(t.episode)
+
+	// Measure the greedy policy apart from the noisy training episodes
+	if t.config.EvalInterval > 0 && t.episode%t.config.EvalInterval == 0 {
+		t.evaluate()
+	}
+	return success
+}
+
+// LearningRate returns the learning rate of the running episode
+func (t *Trainer) LearningRate() float64 {
+	return t.learningRate
+}
+
+// LearningRateSchedule returns the name of the schedule setting the learning rate
+func (t *Trainer) LearningRateSchedule() string {
+	return t.scheduleName
+}
+
+// setLearningRate replaces the learning rate, e.g. from a checkpoint, and
+// continues the schedule from it without any monitor adjustment
+func (t *Trainer) setLearningRate(rate float64) {
+	t.learningRate = rate
+	t.scheduledRate = rate
+	t.rateScale = 1
+}
+
+// ShouldStop reports whether an early stopping criterion from the config
+// has been met, and why
+func (t *Trainer) ShouldStop() (bool, string) {
+	return t.stopper.ShouldStop()
 }
 
 // GetTrainingStats returns current training statistics
@@ -212,9 +474,20 @@ func (t *Trainer) GetTrainingStats() map[string]interface{} {
 		"bestDuration":  t.bestDuration,
 		"learningRate":  t.learningRate,
 		"metrics":       t.metrics,
+		"budget":        t.Budget(),
 	}
 }
 
+// Budget returns the steps, approximate forward and backward passes and
+// wall-clock time training has used so far, including the running episode
+func (t *Trainer) Budget() metrics.ComputeBudget {
+	budget := t.budget
+	if !t.episodeStart.IsZero() {
+		budget.WallClock += time.Since(t.episodeStart)
+	}
+	return budget
+}
+
 // saveCheckpoint saves the current network state and training metrics
 func (t *Trainer) saveCheckpoint() {
 	// Create checkpoint directory if it doesn't exist
@@ -229,8 +502,13 @@ func (t *Trainer) saveCheckpoint() {
 	weightsData := map[string]interface{}{
 		"episode":    t.episode,
 		"weights":    weights,
+		"learning_rate": t.learningRate,
+		"optimizer":  t.optimizer.State(),
 		"timestamp": time.Now(),
 	}
+	if t.rewardScaler != nil {
+		weightsData["reward_scale"] = t.rewardScaler.State()
+	}
 	if data, err := json.MarshalIndent(weightsData, "", "  "); err == nil {
 		if err := os.WriteFile(weightsCheckpoint, data, 0644); err != nil {
 			t.logger.Printf("Failed to save weights checkpoint: %v", err)
@@ -250,7 +528,9 @@ func (t *Trainer) saveCheckpoint() {
 		}
 	}
 
-	t.logger.Printf("[Trainer] Saved checkpoint to %s", weightsCheckpoint)
+	if t.sampler.Episode() {
+		t.logger.Printf("[Trainer] Saved checkpoint to %s", weightsCheckpoint)
+	}
 }
 
 // LoadCheckpoint loads a checkpoint from the given file
@@ -265,6 +545,9 @@ func (t *Trainer) LoadCheckpoint(path string) error {
 	var checkpoint struct {
 		Episode    int       `json:"episode"`
 		Weights    []float64 `json:"weights"`
+		LearningRate float64 `json:"learning_rate"`
+		Optimizer  *neural.OptimizerState `json:"optimizer"`
+		RewardScale *neural.RewardScaleState `json:"reward_scale"`
 		Timestamp  time.Time `json:"timestamp"`
 	}
 	if err := json.Unmarshal(data, &checkpoint); err != nil {
@@ -276,6 +559,26 @@ func (t *Trainer) LoadCheckpoint(path string) error {
 		return fmt.Errorf("failed to restore weights: %w", err)
 	}
 
+	// Resume the optimizer's moments; older checkpoints start it fresh
+	if checkpoint.Optimizer != nil {
+		optimizer, err := neural.RestoreOptimizer(*checkpoint.Optimizer)
+		if err != nil {
+			return fmt.Errorf("failed to restore optimizer: %w", err)
+		}
+		t.optimizer = optimizer
+	} else {
+		t.optimizer.Reset()
+	}
+	if checkpoint.LearningRate > 0 {
+		t.setLearningRate(checkpoint.LearningRate)
+	}
+
+	// Keep scaling rewards by the saved return statistics, so the targets
+	// of a resumed run match the ones it was trained with
+	if checkpoint.RewardScale != nil && t.config.RewardScaling {
+		t.rewardScaler = neural.RestoreRewardScaler(*checkpoint.RewardScale)
+	}
+
 	// Update trainer state
 	t.episode = checkpoint.Episode
 	t.metrics = NewMetricsCollector(t.episode)
@@ -283,17 +586,32 @@ func (t *Trainer) LoadCheckpoint(path string) error {
 	return nil
 }
 
-// sortExperiencesByReward sorts experiences by reward in descending order
-func sortExperiencesByReward(experiences []Experience) {
-	// Simple bubble sort since batch sizes are small
-	n := len(experiences)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if experiences[j].Reward < experiences[j+1].Reward {
-				experiences[j], experiences[j+1] = experiences[j+1], experiences[j]
-			}
-		}
+// sortExperiencesByReward sorts experiences by reward in descending order,
+// keeping targets aligned with their experiences. The sort is stable, so
+// experiences with equal rewards stay in time order
+func sortExperiencesByReward(experiences []Experience, targets []float64) {
+	sort.Stable(byReward{experiences, targets})
+}
+
+// byReward orders experiences by descending reward, swapping their targets along
+type byReward struct {
+	experiences []Experience
+	targets     []float64
+}
+
+func (b byReward) Len() int           { return len(b.experiences) }
+func (b byReward) Less(i, j int) bool { return b.experiences[i].Reward > b.experiences[j].Reward }
+func (b byReward) Swap(i, j int) {
+	b.experiences[i], b.experiences[j] = b.experiences[j], b.experiences[i]
+	b.targets[i], b.targets[j] = b.targets[j], b.targets[i]
+}
+
+// resize returns buf with n values, reusing its storage when it is large enough
+func resize(buf []float64, n int) []float64 {
+	if cap(buf) < n {
+		return make([]float64, n)
 	}
+	return buf[:n]
 }
 
 // sign returns the sign of a number: 1 for positive, -1 for negative, 0 for zero
@@ -3,12 +3,16 @@ package training
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"math"
+	"math/rand"
+	"runtime"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
@@ -483,3 +487,850 @@ func TestMetricsCollection(t *testing.T) {
 		}
 	}
 }
+
+func TestLambdaReturns(t *testing.T) {
+	network := neural.NewNetwork()
+	s0 := env.State{AngleRadians: 0.1, AngularVel: 0.0}
+	s1 := env.State{AngleRadians: 0.2, AngularVel: 0.1}
+	s2 := env.State{AngleRadians: 0.3, AngularVel: 0.2}
+	v1 := network.Predict(s1.AngleRadians, s1.AngularVel)
+	v2 := network.Predict(s2.AngleRadians, s2.AngularVel)
+
+	tests := []struct {
+		name        string
+		gamma       float64
+		lambda      float64
+		experiences []Experience
+		want        []float64
+	}{
+		{
+			name:   "one-step TD",
+			gamma:  0.9,
+			lambda: 0.0,
+			experiences: []Experience{
+				{State: s0, Reward: 1.0, NextState: s1},
+				{State: s1, Reward: 0.5, NextState: s2},
+			},
+			want: []float64{1.0 + 0.9*v1, 0.5 + 0.9*v2},
+		},
+		{
+			name:   "full trace decay",
+			gamma:  0.9,
+			lambda: 1.0,
+			experiences: []Experience{
+				{State: s0, Reward: 1.0, NextState: s1},
+				{State: s1, Reward: 0.5, NextState: s2},
+			},
+			want: []float64{1.0 + 0.9*(0.5+0.9*v2), 0.5 + 0.9*v2},
+		},
+		{
+			name:   "terminal step has no bootstrap",
+			gamma:  0.9,
+			lambda: 0.5,
+			experiences: []Experience{
+				{State: s0, Reward: 1.0, NextState: s1},
+				{State: s1, Reward: -1.0, NextState: s2, Done: true},
+			},
+			want: []float64{1.0 + 0.9*(0.5*v1+0.5*-1.0), -1.0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Gamma = tc.gamma
+			config.Lambda = tc.lambda
+			trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+
+			got := trainer.lambdaReturns(tc.experiences)
+			for i := range tc.want {
+				if math.Abs(got[i]-tc.want[i]) > 1e-9 {
+					t.Errorf("target[%d] = %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWeightSnapshotRollback(t *testing.T) {
+	t.Run("manual", func(t *testing.T) {
+		network := neural.NewNetwork()
+		trainer := NewTrainer(NewDefaultConfig(), network, log.New(&bytes.Buffer{}, "", 0))
+
+		if err := trainer.RollbackToBest(); err == nil {
+			t.Error("Expected error rolling back without a snapshot")
+		}
+
+		good := network.GetWeights()
+		if !trainer.SnapshotWeights(10) {
+			t.Fatal("First snapshot should be the best")
+		}
+		network.SetWeights([]float64{-1, -1, -1})
+		if trainer.SnapshotWeights(5) {
+			t.Error("Lower score replaced the best snapshot")
+		}
+
+		if err := trainer.RollbackToBest(); err != nil {
+			t.Fatalf("RollbackToBest failed: %v", err)
+		}
+		for i, w := range network.GetWeights() {
+			if w != good[i] {
+				t.Errorf("weight %d = %.4f after rollback, want %.4f", i, w, good[i])
+			}
+		}
+		if trainer.Rollbacks() != 1 {
+			t.Errorf("rollbacks = %d, want 1", trainer.Rollbacks())
+		}
+	})
+
+	t.Run("automatic", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.AutoRollback = true
+		config.RollbackWindow = 3
+		network := neural.NewNetwork()
+		trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+		trainer.SetCheckpointDirectory(t.TempDir())
+
+		// A good stretch of episodes sets the best snapshot
+		good := network.GetWeights()
+		for i := 0; i < 3; i++ {
+			trainer.OnEpisodeEnd(500)
+		}
+		if best, ok := trainer.BestSnapshot(); !ok || best.Score != 500*config.DeltaTime {
+			t.Fatalf("best snapshot = %+v, %v; want score %.2f", best, ok, 500*config.DeltaTime)
+		}
+
+		// Then learning diverges and episodes collapse
+		network.SetWeights([]float64{-2, -2, -2})
+		for i := 0; i < 3 && trainer.Rollbacks() == 0; i++ {
+			trainer.OnEpisodeEnd(10)
+		}
+		if trainer.Rollbacks() != 1 {
+			t.Fatalf("rollbacks = %d after collapse, want 1", trainer.Rollbacks())
+		}
+		if got := network.GetWeights(); got[0] != good[0] {
+			t.Errorf("angle weight = %.4f after rollback, want %.4f", got[0], good[0])
+		}
+	})
+}
+
+func TestStopper(t *testing.T) {
+	t.Run("success_rate", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.StopSuccessRate = 0.8
+		config.StopWindow = 5
+		stopper := NewStopper(config)
+
+		outcomes := []bool{false, true, true, true, true, false, true, true, true, true}
+		for i, success := range outcomes {
+			stopper.RecordEpisode(1, success)
+			stop, reason := stopper.ShouldStop()
+			// The window first reaches 4/5 successes after the fifth episode
+			if want := i >= 4; stop != want {
+				t.Fatalf("episode %d: stop = %v (%s), want %v", i, stop, reason, want)
+			}
+		}
+	})
+
+	t.Run("plateau", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.StopWindow = 2
+		config.PlateauEpisodes = 3
+		stopper := NewStopper(config)
+
+		// Improving rewards never plateau
+		for i := 0; i < 10; i++ {
+			stopper.RecordEpisode(float64(i), false)
+		}
+		if stop, reason := stopper.ShouldStop(); stop {
+			t.Fatalf("stopped while improving: %s", reason)
+		}
+
+		// Flat rewards stop after PlateauEpisodes windows without gain
+		for i := 0; i < 4; i++ {
+			stopper.RecordEpisode(9, false)
+		}
+		if stop, reason := stopper.ShouldStop(); !stop || !strings.Contains(reason, "plateaued") {
+			t.Errorf("stop = %v (%q), want plateau", stop, reason)
+		}
+	})
+
+	t.Run("time_budget", func(t *testing.T) {
+		config := NewDefaultConfig()
+		config.TimeBudget = time.Nanosecond
+		stopper := NewStopper(config)
+		time.Sleep(time.Millisecond)
+		if stop, reason := stopper.ShouldStop(); !stop || !strings.Contains(reason, "time budget") {
+			t.Errorf("stop = %v (%q), want time budget", stop, reason)
+		}
+	})
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		trainer := NewTrainer(NewDefaultConfig(), neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+		trainer.SetCheckpointDirectory(t.TempDir())
+		for i := 0; i < 50; i++ {
+			trainer.OnEpisodeEnd(100)
+		}
+		if stop, reason := trainer.ShouldStop(); stop {
+			t.Errorf("default config stopped training: %s", reason)
+		}
+	})
+}
+
+func TestMonitor(t *testing.T) {
+	config := NewDefaultConfig()
+	config.MonitorWindow = 4
+
+	t.Run("detects anomalies", func(t *testing.T) {
+		kinds := func(anomalies []Anomaly) map[string]bool {
+			found := make(map[string]bool)
+			for _, a := range anomalies {
+				found[a.Kind] = true
+			}
+			return found
+		}
+
+		// Frozen weights with every step at full force
+		monitor := NewMonitor(config)
+		for i := 0; i < 4; i++ {
+			monitor.RecordEpisode(10, []float64{1, 1, 1}, 1)
+		}
+		if found := kinds(monitor.Check(3)); !found[Stagnation] || !found[Saturation] || found[RewardCollapse] {
+			t.Errorf("frozen, saturated run found %v", found)
+		}
+
+		// Weights flip back and forth while reward falls from its best
+		for i := 0; i < 4; i++ {
+			w := float64(i%2) - 0.5
+			monitor.RecordEpisode(2, []float64{w, -w, w}, 0)
+		}
+		found := kinds(monitor.Check(7))
+		if !found[Oscillation] || !found[RewardCollapse] || found[Stagnation] || found[Saturation] {
+			t.Errorf("oscillating, collapsed run found %v", found)
+		}
+
+		if anomalies := NewMonitor(config).Check(0); anomalies != nil {
+			t.Errorf("empty window found %v", anomalies)
+		}
+	})
+
+	t.Run("trainer adjusts learning rate", func(t *testing.T) {
+		config := config
+		config.MonitorInterval = 4
+		config.MonitorAction = MonitorAdjustLR
+		trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+		trainer.SetCheckpointDirectory(t.TempDir())
+
+		var alerts []Anomaly
+		trainer.OnAnomaly(func(a Anomaly) { alerts = append(alerts, a) })
+
+		// Episodes without experiences leave the weights untouched
+		for i := 0; i < 3; i++ {
+			trainer.OnEpisodeEnd(100)
+		}
+		if len(alerts) != 0 {
+			t.Fatalf("alerts before the check interval: %v", alerts)
+		}
+		decayed := trainer.learningRate
+		trainer.OnEpisodeEnd(100)
+
+		if len(alerts) != 1 || alerts[0].Kind != Stagnation || alerts[0].Episode != 3 {
+			t.Fatalf("alerts = %+v, want one stagnation alert at episode 3", alerts)
+		}
+		if trainer.learningRate <= decayed {
+			t.Errorf("learning rate = %.6f after stagnation, want above %.6f", trainer.learningRate, decayed)
+		}
+	})
+
+	t.Run("trainer rolls back on reward collapse", func(t *testing.T) {
+		config := config
+		config.MonitorInterval = 1
+		config.MonitorAction = MonitorRollback
+		network := neural.NewNetwork()
+		trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+		trainer.SetCheckpointDirectory(t.TempDir())
+
+		episode := func(reward float64) {
+			trainer.AddExperience(Experience{
+				State:     env.State{AngleRadians: 0.1},
+				Action:    1,
+				Reward:    reward,
+				NextState: env.State{AngleRadians: 0.1},
+			})
+			trainer.OnEpisodeEnd(1)
+		}
+
+		for i := 0; i < 4; i++ {
+			episode(10)
+		}
+		best, ok := trainer.BestSnapshot()
+		if !ok {
+			t.Fatal("no snapshot after a healthy window")
+		}
+
+		network.SetWeights([]float64{-2, -2, -2})
+		for i := 0; i < 4 && trainer.Rollbacks() == 0; i++ {
+			episode(0)
+		}
+		if trainer.Rollbacks() != 1 {
+			t.Fatalf("rollbacks = %d after collapse, want 1", trainer.Rollbacks())
+		}
+		if got := network.GetWeights(); got[0] != best.Weights[0] {
+			t.Errorf("angle weight = %.4f after rollback, want %.4f", got[0], best.Weights[0])
+		}
+	})
+}
+
+func TestNonFiniteBatchGuard(t *testing.T) {
+	network := neural.NewNetwork()
+	config := NewDefaultConfig()
+	config.BatchSize = 2
+	trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+
+	trainer.SnapshotWeights(1)
+	good := network.GetWeights()
+	network.SetWeights([]float64{0.5, 0.5, 0.5})
+
+	// A NaN reward poisons the λ-returns and so the whole batch gradient
+	for i := 0; i < config.BatchSize; i++ {
+		trainer.AddExperience(Experience{
+			State:     env.State{AngleRadians: 0.1},
+			Action:    1,
+			Reward:    math.NaN(),
+			NextState: env.State{AngleRadians: 0.1},
+		})
+	}
+
+	if trainer.metrics.NonFiniteUpdates != 1 {
+		t.Errorf("non-finite updates = %d, want 1", trainer.metrics.NonFiniteUpdates)
+	}
+	if !neural.AllFinite(network.GetWeights()) {
+		t.Fatalf("network weights became non-finite: %v", network.GetWeights())
+	}
+	if got := network.GetWeights(); got[0] != good[0] {
+		t.Errorf("angle weight = %.4f, want rollback to %.4f", got[0], good[0])
+	}
+}
+
+func TestOptimizerCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := NewDefaultConfig()
+	config.Optimizer = neural.Adam
+	config.BatchSize = 2
+
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(tmpDir)
+	for i := 0; i < 4; i++ {
+		trainer.AddExperience(Experience{
+			State:     env.State{AngleRadians: 0.1, AngularVel: 0.2},
+			Action:    -0.5,
+			Reward:    0.8,
+			NextState: env.State{AngleRadians: 0.05},
+		})
+	}
+	trainer.saveCheckpoint()
+
+	restored := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	if err := restored.LoadCheckpoint(filepath.Join(tmpDir, "weights_episode_0.json")); err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+
+	want, got := trainer.optimizer.State(), restored.optimizer.State()
+	if got.Config.Name != neural.Adam || got.Steps != 2 || got.Steps != want.Steps {
+		t.Fatalf("restored optimizer %s after %d steps, want adam after %d", got.Config.Name, got.Steps, want.Steps)
+	}
+	for i := range want.Second {
+		if got.First[i] != want.First[i] || got.Second[i] != want.Second[i] {
+			t.Errorf("moment %d = (%v, %v), want (%v, %v)", i, got.First[i], got.Second[i], want.First[i], want.Second[i])
+		}
+	}
+}
+
+func TestRewardScalingCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := NewDefaultConfig()
+	config.RewardScaling = true
+	config.BatchSize = 2
+
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(tmpDir)
+	for i := 0; i < 8; i++ {
+		trainer.AddExperience(Experience{
+			State:     env.State{AngleRadians: 0.1, AngularVel: 0.2},
+			Reward:    float64(i % 3),
+			NextState: env.State{AngleRadians: 0.05},
+			Done:      i == 3,
+		})
+	}
+	if trainer.RewardScale() == 1 {
+		t.Fatal("reward scale unchanged after training")
+	}
+	trainer.saveCheckpoint()
+
+	restored := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	if err := restored.LoadCheckpoint(filepath.Join(tmpDir, "weights_episode_0.json")); err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+	if got, want := restored.rewardScaler.State(), trainer.rewardScaler.State(); got != want {
+		t.Errorf("restored reward scale statistics %+v, want %+v", got, want)
+	}
+
+	// Targets of the resumed trainer continue on the same scale
+	experiences := []Experience{{Reward: 2, Done: true}}
+	if got, want := restored.lambdaReturns(experiences)[0], trainer.lambdaReturns(experiences)[0]; got != want || got == 2 {
+		t.Errorf("resumed target = %v, want %v scaled from 2", got, want)
+	}
+}
+
+func TestRewardScalingSession(t *testing.T) {
+	config := NewDefaultConfig()
+	config.RewardScaling = true
+	config.BatchSize = 2
+
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	for i := 0; i < 8; i++ {
+		trainer.AddExperience(Experience{
+			State:     env.State{AngleRadians: 0.1, AngularVel: 0.2},
+			Reward:    float64(i % 3),
+			NextState: env.State{AngleRadians: 0.05},
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := SaveSession(path, trainer.SessionState()); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	resumed := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	if err := resumed.RestoreSession(loaded); err != nil {
+		t.Fatalf("RestoreSession failed: %v", err)
+	}
+	if got, want := resumed.rewardScaler.State(), trainer.rewardScaler.State(); got != want {
+		t.Errorf("resumed reward scale statistics %+v, want %+v", got, want)
+	}
+
+	// Targets of the resumed trainer continue on the same scale
+	experiences := []Experience{{Reward: 2, Done: true}}
+	if got, want := resumed.lambdaReturns(experiences)[0], trainer.lambdaReturns(experiences)[0]; got != want || got == 2 {
+		t.Errorf("resumed target = %v, want %v scaled from 2", got, want)
+	}
+}
+
+func TestSessionResume(t *testing.T) {
+	config := NewDefaultConfig()
+	config.BatchSize = 4
+	config.Optimizer = neural.RMSProp
+	config.PlateauEpisodes = 50
+
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(t.TempDir())
+	for episode := 0; episode < 3; episode++ {
+		for i := 0; i < 6; i++ {
+			trainer.AddExperience(Experience{
+				State:     env.State{AngleRadians: 0.1 * float64(i), AngularVel: 0.2},
+				Action:    1,
+				Reward:    0.5,
+				NextState: env.State{AngleRadians: 0.05},
+			})
+		}
+		trainer.OnEpisodeEnd(100)
+	}
+	trainer.SnapshotWeights(2.5)
+	trainer.AddExperience(Experience{State: env.State{AngleRadians: 0.3}, Action: -1, Reward: 0.1})
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	state := trainer.SessionState()
+	state.SessionID = "session_test"
+	if err := SaveSession(path, state); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if loaded.SessionID != "session_test" {
+		t.Errorf("session ID = %q, want session_test", loaded.SessionID)
+	}
+
+	resumed := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	if err := resumed.RestoreSession(loaded); err != nil {
+		t.Fatalf("RestoreSession failed: %v", err)
+	}
+
+	if resumed.episode != trainer.episode || resumed.successCount != trainer.successCount {
+		t.Errorf("counters = (%d, %d), want (%d, %d)", resumed.episode, resumed.successCount, trainer.episode, trainer.successCount)
+	}
+	if resumed.learningRate != trainer.learningRate {
+		t.Errorf("learning rate = %v, want %v", resumed.learningRate, trainer.learningRate)
+	}
+	if len(resumed.batch.Experiences) != 1 {
+		t.Errorf("pending experiences = %d, want 1", len(resumed.batch.Experiences))
+	}
+	if best, ok := resumed.BestSnapshot(); !ok || best.Score != 2.5 {
+		t.Errorf("best snapshot = %+v, want score 2.5", best)
+	}
+
+	// Both trainers must now make identical updates
+	next := Experience{State: env.State{AngleRadians: 0.2}, Action: 1, Reward: 0.7, NextState: env.State{AngleRadians: 0.1}}
+	for i := 0; i < config.BatchSize-1; i++ {
+		trainer.AddExperience(next)
+		resumed.AddExperience(next)
+	}
+	want, got := trainer.network.GetWeights(), resumed.network.GetWeights()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("weight %d after resume = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	t.Run("rand source", func(t *testing.T) {
+		source := NewRandSource(42)
+		rng := rand.New(source)
+		rng.NormFloat64()
+		rng.Intn(10)
+
+		restored := rand.New(RestoreRandSource(source.State()))
+		if a, b := rng.Float64(), restored.Float64(); a != b {
+			t.Errorf("restored stream gave %v, want %v", b, a)
+		}
+	})
+}
+
+func TestEpisodeSuccessCriteria(t *testing.T) {
+	config := NewDefaultConfig()
+	config.DeltaTime = 0.1
+	config.CheckpointInterval = 1000
+	config.Success = env.SuccessCriteria{MaxAngle: 0.2, MinDuration: 1.0, StepReward: 0.5}
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(t.TempDir())
+
+	tests := []struct {
+		name  string
+		angle float64
+		ticks int
+		want  bool
+	}{
+		{"upright", 0.1, 20, true},
+		{"upright across the wrap", 2*math.Pi - 0.1, 20, true},
+		{"fell too far", 0.5, 20, false},
+		{"too short", 0.1, 5, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			trainer.Observe(Experience{State: env.State{AngleRadians: tc.angle}, Reward: 1})
+			if got := trainer.OnEpisodeEnd(tc.ticks); got != tc.want {
+				t.Errorf("OnEpisodeEnd(%d) = %v, want %v", tc.ticks, got, tc.want)
+			}
+		})
+	}
+	if count := trainer.GetTrainingStats()["successCount"]; count != 2 {
+		t.Errorf("successCount = %v, want 2", count)
+	}
+}
+
+func TestComputeBudget(t *testing.T) {
+	config := NewDefaultConfig()
+	config.BatchSize = 4
+	config.CheckpointInterval = 1000
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(t.TempDir())
+
+	// Five steps: a full batch of four, then one more flushed at episode end
+	for i := 0; i < 5; i++ {
+		trainer.AddExperience(Experience{
+			State:     env.State{AngleRadians: 0.1},
+			Action:    1,
+			Reward:    0.5,
+			NextState: env.State{AngleRadians: 0.1},
+			Done:      i == 4,
+		})
+	}
+	trainer.OnEpisodeEnd(5)
+
+	// Effective batches of 3 and 1; every step but the terminal one bootstraps
+	budget := trainer.Budget()
+	if budget.Steps != 5 {
+		t.Errorf("steps = %d, want 5", budget.Steps)
+	}
+	if want := int64(5 + 4 + 4); budget.ForwardPasses != want {
+		t.Errorf("forward passes = %d, want %d", budget.ForwardPasses, want)
+	}
+	if budget.BackwardPasses != 4 {
+		t.Errorf("backward passes = %d, want 4", budget.BackwardPasses)
+	}
+	if budget.WallClock <= 0 {
+		t.Errorf("wall clock = %v, want > 0", budget.WallClock)
+	}
+
+	// A resumed trainer keeps counting from the saved budget
+	resumed := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	if err := resumed.RestoreSession(trainer.SessionState()); err != nil {
+		t.Fatalf("RestoreSession failed: %v", err)
+	}
+	resumed.AddExperience(Experience{State: env.State{AngleRadians: 0.1}, Action: 1, Reward: 0.5})
+	if got := resumed.Budget().Steps; got != 6 {
+		t.Errorf("steps after resume = %d, want 6", got)
+	}
+}
+
+func TestPeriodicEvaluation(t *testing.T) {
+	config := NewDefaultConfig()
+	config.BatchSize = 4
+	config.CheckpointInterval = 1000
+	config.EvalInterval = 2
+	config.EvalEpisodes = 3
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(t.TempDir())
+
+	var handled []int
+	trainer.OnEvaluation(func(result EvalResult) { handled = append(handled, result.Episode) })
+
+	for episode := 0; episode < 5; episode++ {
+		for i := 0; i < 3; i++ {
+			trainer.AddExperience(Experience{State: env.State{AngleRadians: 0.1}, Action: 1, Reward: 0.5})
+		}
+
+		// With the batch already applied, evaluating must leave the weights
+		// and the training budget alone
+		trainer.processBatch()
+		weights, budget := trainer.network.GetWeights(), trainer.Budget().Steps
+		trainer.OnEpisodeEnd(3)
+		if got := trainer.network.GetWeights(); fmt.Sprint(got) != fmt.Sprint(weights) {
+			t.Errorf("episode %d: weights changed from %v to %v at episode end", episode, weights, got)
+		}
+		if got := trainer.Budget().Steps; got != budget {
+			t.Errorf("episode %d: budget steps = %d, want %d", episode, got, budget)
+		}
+	}
+
+	evaluations := trainer.Evaluations()
+	if len(evaluations) != 2 || evaluations[0].Episode != 2 || evaluations[1].Episode != 4 {
+		t.Fatalf("evaluations after episodes %v, want 2 and 4", handled)
+	}
+	if fmt.Sprint(handled) != "[2 4]" {
+		t.Errorf("handler saw episodes %v, want [2 4]", handled)
+	}
+
+	// The subset spans the suite rather than taking its easiest scenarios
+	episodes := evaluations[0].Episodes
+	if len(episodes) != 3 || episodes[2].Scenario != "tilt_06" {
+		t.Errorf("scenarios = %+v, want 3 spread across the suite ending at tilt_06", episodes)
+	}
+}
+
+func TestRecurrentBPTT(t *testing.T) {
+	network := neural.NewNetwork()
+	network.EnableRecurrent()
+	config := NewDefaultConfig()
+	config.BatchSize = 4
+	config.BPTTWindow = 2
+	trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+	before := network.GetRecurrentWeights()
+
+	state := env.State{AngleRadians: 0.2, AngularVel: -0.1}
+	for i := 0; i < config.BatchSize; i++ {
+		next := env.State{AngleRadians: state.AngleRadians * 1.1, AngularVel: state.AngularVel}
+		trainer.AddExperience(Experience{State: state, Action: trainer.Act(state), Reward: -0.5, NextState: next})
+		state = next
+	}
+
+	after := network.GetRecurrentWeights()
+	changed := false
+	for i := range after {
+		changed = changed || after[i] != before[i]
+	}
+	if !changed {
+		t.Errorf("recurrent weights %v did not change after a batch", after)
+	}
+	if !neural.AllFinite(after) {
+		t.Errorf("recurrent weights are not finite: %v", after)
+	}
+}
+
+func TestLearningRateSchedule(t *testing.T) {
+	config := NewDefaultConfig()
+	config.LRSchedule = neural.StepSchedule
+	config.BaseLearningRate = 0.1
+	config.MinLearningRate = 0
+	config.LRStepEvery = 2
+	config.LRStepFactor = 0.5
+	trainer := NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	trainer.SetCheckpointDirectory(t.TempDir())
+
+	var rates []float64
+	for i := 0; i < 4; i++ {
+		rates = append(rates, trainer.LearningRate())
+		trainer.OnEpisodeEnd(10)
+	}
+	want := []float64{0.1, 0.1, 0.05, 0.05}
+	for i := range want {
+		if math.Abs(rates[i]-want[i]) > 1e-12 {
+			t.Fatalf("rates = %v, want %v", rates, want)
+		}
+	}
+	if name := trainer.LearningRateSchedule(); name != neural.StepSchedule {
+		t.Errorf("schedule = %q, want %q", name, neural.StepSchedule)
+	}
+
+	config.LRSchedule = "linear"
+	trainer = NewTrainer(config, neural.NewNetwork(), log.New(&bytes.Buffer{}, "", 0))
+	if name := trainer.LearningRateSchedule(); name != neural.ConstantSchedule {
+		t.Errorf("schedule after unknown name = %q, want %q", name, neural.ConstantSchedule)
+	}
+}
+
+func BenchmarkTrainer(b *testing.B) {
+	config := NewDefaultConfig()
+	network := neural.NewNetwork()
+	network.SetLogger(log.New(&bytes.Buffer{}, "", 0))
+	trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+	state := env.State{AngleRadians: 0.1, AngularVel: 0.2}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// One training step: act, then learn from the transition, with a batch
+	// update every BatchSize steps
+	for i := 0; i < b.N; i++ {
+		force := trainer.Act(state)
+		trainer.AddExperience(Experience{State: state, Action: force, Reward: float64(i%7) / 7, NextState: state, TimeStep: uint64(i)})
+	}
+}
+
+func TestParallelGradients(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	train := func(parallel bool) ([]float64, *Trainer) {
+		config := NewDefaultConfig()
+		config.BatchSize = 512
+		config.Lambda = 0.9
+		config.ParallelGradients = parallel
+		config.ParallelMinBatch = 128
+		network := neural.NewNetwork()
+		network.SetLogger(log.New(&bytes.Buffer{}, "", 0))
+		trainer := NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+
+		rng := rand.New(rand.NewSource(7))
+		for i := 0; i < 4*config.BatchSize; i++ {
+			state := env.State{AngleRadians: rng.NormFloat64() * 0.3, AngularVel: rng.NormFloat64()}
+			next := env.State{AngleRadians: state.AngleRadians + 0.02*state.AngularVel, AngularVel: state.AngularVel}
+			trainer.AddExperience(Experience{
+				State:     state,
+				Action:    rng.Float64()*10 - 5,
+				Reward:    rng.Float64(),
+				NextState: next,
+				Done:      i%100 == 99,
+			})
+		}
+		return network.GetWeights(), trainer
+	}
+
+	serial, serialTrainer := train(false)
+	parallel, parallelTrainer := train(true)
+	for i := range serial {
+		if serial[i] != parallel[i] {
+			t.Fatalf("parallel weights = %v, want the serial path's %v", parallel, serial)
+		}
+	}
+	if serialTrainer.metrics.BatchCount != 4 || parallelTrainer.metrics.BatchCount != 4 {
+		t.Errorf("batches = %d serial, %d parallel; want 4 each", serialTrainer.metrics.BatchCount, parallelTrainer.metrics.BatchCount)
+	}
+	if serial[0] == neural.NewNetwork().GetWeights()[0] {
+		t.Error("training left the angle weight unchanged")
+	}
+}
+
+func TestExperienceGradientWrap(t *testing.T) {
+	// Angles are stored in [0, 2π), so leaning left of upright is near 2π;
+	// the gradient must see the same small offset as leaning right
+	right := Experience{State: env.State{AngleRadians: 0.1, AngularVel: 0.2}, Action: 1}
+	left := Experience{State: env.State{AngleRadians: 2*math.Pi - 0.1, AngularVel: 0.2}, Action: 1}
+	gr := experienceGradient(right, 1, 0.5)
+	gl := experienceGradient(left, 1, 0.5)
+	if math.Abs(gr[0]-0.05) > 1e-9 {
+		t.Errorf("angle gradient right of upright = %.4f, want 0.05", gr[0])
+	}
+	if math.Abs(gl[0]+0.05) > 1e-9 {
+		t.Errorf("angle gradient left of upright = %.4f, want -0.05", gl[0])
+	}
+	if gl[1] != gr[1] || gl[2] != gr[2] {
+		t.Errorf("velocity and bias gradients %v and %v differ across the wrap", gl[1:], gr[1:])
+	}
+}
+
+func TestBatchMomentum(t *testing.T) {
+	// Every experience of a batch is the same terminal transition, so each
+	// batch has the same gradient: the TD error against the reward times
+	// the inputs, signed by the positive action
+	state := env.State{AngleRadians: 0.1, AngularVel: 0.2}
+	tdError := 1 - math.Tanh(-math.Abs(state.AngleRadians)-0.5*math.Abs(state.AngularVel))
+	grad := []float64{tdError * state.AngleRadians, tdError * state.AngularVel, tdError}
+
+	newTrainer := func(momentum float64) *Trainer {
+		config := NewDefaultConfig()
+		config.BatchSize = 5
+		config.Momentum = momentum
+		config.WeightClipMin, config.WeightClipMax = -100, 100
+		network := neural.NewNetwork()
+		network.SetLogger(log.New(&bytes.Buffer{}, "", 0))
+		return NewTrainer(config, network, log.New(&bytes.Buffer{}, "", 0))
+	}
+	// step runs one batch and returns how far it moved each weight
+	step := func(trainer *Trainer) []float64 {
+		before := trainer.network.GetWeights()
+		for i := 0; i < trainer.config.BatchSize; i++ {
+			trainer.AddExperience(Experience{State: state, Action: 1, Reward: 1, NextState: state, Done: true})
+		}
+		after := trainer.network.GetWeights()
+		moved := make([]float64, len(after))
+		for i := range after {
+			moved[i] = after[i] - before[i]
+		}
+		return moved
+	}
+	expect := func(t *testing.T, name string, got []float64, scale float64) {
+		t.Helper()
+		for i := range grad {
+			if want := scale * grad[i]; math.Abs(got[i]-want) > 1e-12 {
+				t.Errorf("%s = %v, want %v·gradient = %v", name, got, scale, want)
+				return
+			}
+		}
+	}
+
+	t.Run("first batch is a plain gradient step", func(t *testing.T) {
+		for _, momentum := range []float64{0, 0.5, 0.9} {
+			trainer := newTrainer(momentum)
+			expect(t, fmt.Sprintf("first step with momentum %v", momentum), step(trainer), trainer.LearningRate())
+		}
+	})
+
+	t.Run("momentum carries across batches", func(t *testing.T) {
+		trainer := newTrainer(0.5)
+		lr := trainer.LearningRate()
+		step(trainer)
+		expect(t, "second step", step(trainer), lr*1.5)
+		expect(t, "third step", step(trainer), lr*1.75)
+	})
+
+	t.Run("zero momentum is plain SGD", func(t *testing.T) {
+		trainer := newTrainer(0)
+		lr := trainer.LearningRate()
+		step(trainer)
+		expect(t, "second step", step(trainer), lr)
+	})
+
+	t.Run("rollback clears the velocity", func(t *testing.T) {
+		trainer := newTrainer(0.9)
+		lr := trainer.LearningRate()
+		trainer.SnapshotWeights(1)
+		step(trainer)
+		step(trainer)
+		if err := trainer.RollbackToBest(); err != nil {
+			t.Fatalf("RollbackToBest: %v", err)
+		}
+		expect(t, "step after rollback", step(trainer), lr)
+	})
+}
@@ -1,21 +1,16 @@
 package training
 
 import (
-	"math"
 	"time"
 
+	"github.com/zachbeta/go_inverted_pendulum/pkg/agent"
 	"github.com/zachbeta/go_inverted_pendulum/pkg/env"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/logger"
+	"github.com/zachbeta/go_inverted_pendulum/pkg/neural"
 )
 
 // Experience represents a single training example
-type Experience struct {
-	State       env.State
-	Action      float64
-	Reward      float64
-	NextState   env.State
-	Done        bool
-	TimeStep    uint64
-}
+type Experience = agent.Experience
 
 // Batch represents a collection of experiences for batch learning
 type Batch struct {
@@ -44,15 +39,53 @@ type Config struct {
 	BatchSize           int     // Number of experiences per batch
 	BaseLearningRate    float64 // Initial learning rate
 	MinLearningRate     float64 // Minimum learning rate
-	LearningRateDecay   float64 // Learning rate decay factor
+	LearningRateDecay   float64 // Per-episode decay factor of the adaptive schedule
+	LRSchedule          string  // Learning rate schedule: "constant", "step", "cosine", "warmup" or "adaptive"
+	LRStepEvery         int     // Episodes between decays of the step schedule
+	LRStepFactor        float64 // Multiplier of each decay of the step schedule
+	LRWarmup            int     // Episodes ramping the learning rate up from zero, for any schedule
 	CheckpointInterval  int     // Episodes between checkpoints
 	MaxEpisodes         int     // Maximum number of training episodes
 	TargetEpisodeTicks  int     // Target number of ticks per episode
 	WeightClipMin       float64 // Minimum weight value
 	WeightClipMax       float64 // Maximum weight value
 	DeltaTime           float64 // Time step duration in seconds
-	SuccessAngleThresh  float64 // Maximum angle (radians) considered "upright"
-	SuccessDuration     float64 // Duration (seconds) needed for "success"
+	Success             env.SuccessCriteria // Decides which episodes count as successes, shared with the network
+	Gamma               float64 // Discount factor for future rewards
+	Lambda              float64 // TD(λ) trace decay; 0 gives one-step TD targets
+	RewardScaling       bool    // Divide rewards by the running std of the discounted return, so reward changes need no learning rate re-tuning
+	Exploration         string  // Exploration strategy: "none", "epsilon", "gaussian" or "ou"
+	ExplorationStart    float64 // Initial epsilon or noise scale
+	ExplorationEnd      float64 // Minimum epsilon or noise scale
+	ExplorationDecay    float64 // Per-episode decay factor for the exploration scale
+	AutoRollback        bool    // Snapshot the best weights and restore them when performance collapses
+	RollbackWindow      int     // Episodes averaged into the rollback score
+	RollbackDrop        float64 // Roll back when the score falls below this fraction of the best
+	StopSuccessRate     float64 // Stop once the windowed success rate reaches this (0 disables)
+	StopWindow          int     // Episodes in the sliding window for stopping criteria
+	PlateauEpisodes     int     // Stop after this many episodes without reward improvement (0 disables)
+	PlateauMinDelta     float64 // Smallest windowed mean reward gain that counts as improvement
+	TimeBudget          time.Duration // Stop after this much wall-clock time (0 disables)
+	MaxGradNorm         float64 // L2 limit on each update's gradient, batch and online (0 disables)
+	Optimizer           string  // Batch update optimizer: "sgd", "adam" or "rmsprop"
+	Momentum            float64 // SGD momentum
+	Beta1               float64 // Adam first moment decay
+	Beta2               float64 // Adam second moment decay
+	RMSDecay            float64 // RMSProp squared gradient decay
+	LogSampling         logger.SamplingConfig // Which batch and episode summaries are logged
+	ActionSpace         neural.ActionSpace    // Forces the network may apply: continuous, bang-bang or discrete bins
+	MonitorInterval     int     // Check for learning anomalies every this many episodes (0 disables)
+	MonitorWindow       int     // Episodes considered by each anomaly check
+	MonitorAction       string  // On an anomaly: "none", "adjust-lr" or "rollback"
+	StagnationThresh    float64 // Mean weight change per episode below which learning has stagnated
+	CollapseDrop        float64 // Reward collapse when the mean reward drops this fraction below its best
+	OscillationRate     float64 // Fraction of weight changes reversing direction that counts as oscillating
+	SaturationThresh    float64 // Fraction of steps at the full output force that counts as saturated
+	EvalInterval        int     // Run greedy evaluation episodes every this many training episodes (0 disables)
+	EvalEpisodes        int     // Scenarios of the eval suite each evaluation runs (0 runs all)
+	BPTTWindow          int     // Steps truncated backpropagation through time unrolls a recurrent network's cell
+	ParallelGradients   bool    // Compute the per-experience gradients of large batches on GOMAXPROCS goroutines
+	ParallelMinBatch    int     // Smallest effective batch whose gradients are computed in parallel
 }
 
 // NewDefaultConfig returns a Config with reasonable default values
@@ -62,13 +95,77 @@ func NewDefaultConfig() Config {
 		BaseLearningRate:    0.05,
 		MinLearningRate:     0.001,
 		LearningRateDecay:   0.995,
+		LRSchedule:          neural.AdaptiveSchedule,
+		LRStepEvery:         100,
+		LRStepFactor:        0.5,
+		LRWarmup:            0,
 		CheckpointInterval:  100,
 		MaxEpisodes:         10000,
 		TargetEpisodeTicks:  1000,
 		WeightClipMin:       -3.0,
 		WeightClipMax:       3.0,
 		DeltaTime:           0.02,  // 50Hz simulation
-		SuccessAngleThresh:  math.Pi / 6.0, // 30 degrees
-		SuccessDuration:     5.0,   // 5 seconds upright
+		Success:             env.NewDefaultSuccessCriteria(), // 5 seconds within 30 degrees
+		Gamma:               0.99,
+		Lambda:              0.0,   // One-step TD by default
+		RewardScaling:       false,
+		Exploration:         "none",
+		ExplorationStart:    0.2,
+		ExplorationEnd:      0.01,
+		ExplorationDecay:    0.995,
+		AutoRollback:        false,
+		RollbackWindow:      20,
+		RollbackDrop:        0.5,
+		StopSuccessRate:     0,
+		StopWindow:          20,
+		PlateauEpisodes:     0,
+		PlateauMinDelta:     0.01,
+		TimeBudget:          0,
+		MaxGradNorm:         0,
+		Optimizer:           neural.SGD,
+		Momentum:            0.9,
+		Beta1:               0.9,
+		Beta2:               0.999,
+		RMSDecay:            0.9,
+		LogSampling:         logger.DefaultSampling(),
+		ActionSpace:         neural.NewDefaultActionSpace(),
+		MonitorInterval:     0,
+		MonitorWindow:       10,
+		MonitorAction:       MonitorLogOnly,
+		StagnationThresh:    0.0001, // Same threshold as metrics.DetectLearningIssues
+		CollapseDrop:        0.5,
+		OscillationRate:     0.8,
+		SaturationThresh:    0.9,
+		EvalInterval:        0,
+		EvalEpisodes:        5,
+		BPTTWindow:          8,
+		ParallelGradients:   false,
+		ParallelMinBatch:    256, // Below this the goroutines cost more than they save
+	}
+}
+
+// ScheduleConfig returns the learning rate schedule settings for
+// neural.NewSchedule; the cosine schedule anneals over MaxEpisodes
+func (c Config) ScheduleConfig() neural.ScheduleConfig {
+	return neural.ScheduleConfig{
+		Name:     c.LRSchedule,
+		Base:     c.BaseLearningRate,
+		Min:      c.MinLearningRate,
+		Decay:    c.LearningRateDecay,
+		Factor:   c.LRStepFactor,
+		Every:    c.LRStepEvery,
+		Episodes: c.MaxEpisodes,
+		Warmup:   c.LRWarmup,
 	}
 }
+
+// OptimizerConfig returns the optimizer settings for neural.NewOptimizer
+func (c Config) OptimizerConfig() neural.OptimizerConfig {
+	optimizer := neural.NewDefaultOptimizerConfig()
+	optimizer.Name = c.Optimizer
+	optimizer.Momentum = c.Momentum
+	optimizer.Beta1 = c.Beta1
+	optimizer.Beta2 = c.Beta2
+	optimizer.Decay = c.RMSDecay
+	return optimizer
+}